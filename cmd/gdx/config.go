@@ -128,6 +128,8 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 		utils.Fatalf("failed to create the protocol stack: %v", err)
 	}
 	utils.SetEthConfig(ctx, stack, &cfg.Eth)
+	cfg.Eth.StorageClientHTTPAuthTokens = cfg.Node.HTTPAuthTokens
+	cfg.Eth.StorageClientHTTPVirtualHosts = cfg.Node.HTTPVirtualHosts
 	if ctx.GlobalIsSet(utils.EthStatsURLFlag.Name) {
 		cfg.Ethstats.URL = ctx.GlobalString(utils.EthStatsURLFlag.Name)
 	}
@@ -122,6 +122,9 @@ var (
 		utils.ConstantinopleOverrideFlag,
 		utils.RPCCORSDomainFlag,
 		utils.RPCVirtualHostsFlag,
+		utils.RPCAuthTokensFlag,
+		utils.RPCAuthApiFlag,
+		utils.RPCRateLimitFlag,
 		utils.EthStatsURLFlag,
 		utils.MetricsEnabledFlag,
 		utils.FakePoWFlag,
@@ -130,8 +133,14 @@ var (
 		utils.GpoPercentileFlag,
 		utils.EWASMInterpreterFlag,
 		utils.EVMInterpreterFlag,
+		utils.EVMCallTimeoutFlag,
 		configFileFlag,
 		utils.StorageRoleFlag,
+		utils.StorageClientCoordinationFlag,
+		utils.StorageClientReplicaFlag,
+		utils.StorageClientSpillFlag,
+		utils.StorageClientForceTakeoverFlag,
+		utils.StorageClientHTTPFlag,
 	}
 
 	rpcFlags = []cli.Flag{
@@ -196,6 +205,12 @@ func init() {
 
 		// See shostcmd.go
 		storageHostCommand,
+
+		// See stresscmd.go
+		stressTestCommand,
+
+		// See recoverycmd.go
+		recoveryCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
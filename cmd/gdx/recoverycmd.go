@@ -0,0 +1,267 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DxChainNetwork/godx/cmd/utils"
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/console"
+	"github.com/DxChainNetwork/godx/rpc"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// recoveryBackfillPollInterval is the interval polling the progress of the host announcement rescan
+const recoveryBackfillPollInterval = 5 * time.Second
+
+var recoveryCommand = cli.Command{
+	Name:      "recover",
+	Usage:     "Guided recovery of the storage client of a lost node",
+	ArgsUsage: "",
+	Category:  "STORAGE CLIENT COMMANDS",
+	Action:    utils.MigrateFlags(recoverStorageClient),
+	Flags: []cli.Flag{
+		backupTargetFlag,
+		backupNameFlag,
+	},
+	Description: `
+			gdx recover [--target arg] [--name arg]
+
+will guide through the recovery of the storage client on a fresh node, step by step:
+  1. restore the payment account from its private key, or pick an account of the local wallet
+  2. rebuild the contract set and the file metadata from the encrypted metadata backup
+  3. rescan the blockchain for the storage host announcements
+  4. re-establish the sessions with the storage hosts, and audit the contracts with them
+  5. verify the availability of the files
+The progress and the result of each step are reported. The target of the metadata backup is
+prompted if not specified.`,
+}
+
+// recoveryStep is a step of the recovery wizard
+type recoveryStep struct {
+	name string
+	run  func(w *recoveryWizard) error
+}
+
+// recoveryWizard keeps the state of the recovery across the steps
+type recoveryWizard struct {
+	ctx     *cli.Context
+	client  *rpc.Client
+	results []string
+}
+
+var recoverySteps = []recoveryStep{
+	{"Restore the payment account", (*recoveryWizard).restoreAccount},
+	{"Rebuild the contract set and file metadata", (*recoveryWizard).restoreMetadata},
+	{"Rescan the storage host announcements", (*recoveryWizard).rescanHosts},
+	{"Re-establish the sessions with the storage hosts", (*recoveryWizard).reconnectHosts},
+	{"Verify the file availability", (*recoveryWizard).verifyFiles},
+}
+
+func recoverStorageClient(ctx *cli.Context) error {
+	client, err := gdxAttach(ctx)
+	if err != nil {
+		utils.Fatalf("unable to connect to remote gdx, please start the gdx first: %s", err.Error())
+	}
+	w := &recoveryWizard{
+		ctx:    ctx,
+		client: client,
+	}
+
+	for i, step := range recoverySteps {
+		fmt.Printf("\n[%d/%d] %s\n", i+1, len(recoverySteps), step.name)
+		if err := step.run(w); err != nil {
+			w.results = append(w.results, fmt.Sprintf("%s: failed, %s", step.name, err.Error()))
+			fmt.Printf("Failed: %s\n", err.Error())
+			cont, perr := console.Stdin.PromptConfirm("Continue with the next step?")
+			if perr != nil || !cont {
+				break
+			}
+		}
+	}
+
+	fmt.Println("\nRecovery Summary:")
+	for _, result := range w.results {
+		fmt.Printf("	%s\n", result)
+	}
+	return nil
+}
+
+// restoreAccount imports the private key of the payment account into the local wallet, or
+// picks an existing account, then unlocks it and sets it as the payment address
+func (w *recoveryWizard) restoreAccount() error {
+	key, err := console.Stdin.PromptPassword("Private key of the payment account in hex (empty to use an account of the local wallet): ")
+	if err != nil {
+		return err
+	}
+
+	var address common.Address
+	var password string
+	if key = strings.TrimPrefix(strings.TrimSpace(key), "0x"); key != "" {
+		password = getPassPhrase("Please give a passphrase to protect the imported account.", true, 0, nil)
+		if err = w.client.Call(&address, "personal_importRawKey", key, password); err != nil {
+			return fmt.Errorf("failed to import the private key: %s", err.Error())
+		}
+		fmt.Printf("Imported the account %v\n", address.Hex())
+	} else {
+		var accounts []common.Address
+		if err = w.client.Call(&accounts, "personal_listAccounts"); err != nil {
+			return err
+		}
+		if len(accounts) == 0 {
+			return fmt.Errorf("no account in the local wallet")
+		}
+		for i, account := range accounts {
+			fmt.Printf("	%d. %v\n", i+1, account.Hex())
+		}
+		input, err := console.Stdin.PromptInput("Index of the payment account: ")
+		if err != nil {
+			return err
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(input))
+		if err != nil || index < 1 || index > len(accounts) {
+			return fmt.Errorf("invalid account index %v", input)
+		}
+		address = accounts[index-1]
+		password = getPassPhrase(fmt.Sprintf("Please give the passphrase of the account %v.", address.Hex()), false, 0, nil)
+	}
+
+	// the account is unlocked until the node exits, so that the contracts can be signed
+	var unlocked bool
+	if err = w.client.Call(&unlocked, "personal_unlockAccount", address, password, uint64(0)); err != nil || !unlocked {
+		return fmt.Errorf("failed to unlock the account %v: %v", address.Hex(), err)
+	}
+	var set bool
+	if err = w.client.Call(&set, "sclient_setPaymentAddress", address.Hex()); err != nil || !set {
+		return fmt.Errorf("failed to set the payment address %v: %v", address.Hex(), err)
+	}
+	w.results = append(w.results, fmt.Sprintf("Payment account: %v", address.Hex()))
+	fmt.Printf("The payment address is set to %v\n", address.Hex())
+	return nil
+}
+
+// restoreMetadata restores the contract set and the file metadata from the metadata backup.
+// The storage hosts do not serve the contracts by the client address, so the contracts can
+// only be rebuilt from the backup
+func (w *recoveryWizard) restoreMetadata() error {
+	target := w.ctx.String(backupTargetFlag.Name)
+	if target == "" {
+		input, err := console.Stdin.PromptInput("Target of the metadata backup, such as s3://bucket/prefix (empty to skip): ")
+		if err != nil {
+			return err
+		}
+		target = strings.TrimSpace(input)
+	}
+	if target == "" {
+		w.results = append(w.results, "Contract set: skipped, no metadata backup given")
+		fmt.Println("Skipped. Without the metadata backup, the contracts and files of the lost node cannot be recovered, " +
+			"and new contracts will be formed with the storage hosts")
+		return nil
+	}
+
+	passphrase := getPassPhrase("Please give the passphrase of the metadata backup.", false, 0, nil)
+	fmt.Printf("Fetching and verifying the backup from %v\n", target)
+	var manifest storageclient.BackupManifest
+	if err := w.client.Call(&manifest, "sclient_restoreBackup", target, w.ctx.String(backupNameFlag.Name), passphrase); err != nil {
+		return err
+	}
+	w.results = append(w.results, fmt.Sprintf("Contract set: restored %v contracts and %v files from %v created at %v",
+		manifest.Contracts, manifest.Files, manifest.Name, manifest.CreatedAt))
+	fmt.Printf("Restored %v contracts and %v files from the backup %v\n", manifest.Contracts, manifest.Files, manifest.Name)
+	return nil
+}
+
+// rescanHosts runs the host announcement backfill, and reports its progress until it is finished
+func (w *recoveryWizard) rescanHosts() error {
+	var progress storageclient.HostBackfillProgress
+	if err := w.client.Call(&progress, "sclient_startHostBackfill"); err != nil {
+		return err
+	}
+	for progress.Running {
+		fmt.Printf("Scanned to block %v of %v (%.1f%%), %v announcements found\n",
+			progress.NextHeight, progress.TargetHeight, progress.Progress*100, progress.Announcements)
+		time.Sleep(recoveryBackfillPollInterval)
+		if err := w.client.Call(&progress, "sclient_hostBackfillProgress"); err != nil {
+			return err
+		}
+	}
+	if progress.Error != "" {
+		return fmt.Errorf("the host announcement rescan stopped at block %v: %s", progress.NextHeight, progress.Error)
+	}
+	w.results = append(w.results, fmt.Sprintf("Host rescan: %v announcements found up to block %v",
+		progress.Announcements, progress.TargetHeight))
+	fmt.Printf("Rescanned to block %v, %v announcements found\n", progress.TargetHeight, progress.Announcements)
+	return nil
+}
+
+// reconnectHosts connects to the storage host of each contract, and audits the contract with it
+func (w *recoveryWizard) reconnectHosts() error {
+	var contracts []storageclient.ActiveContractsAPIDisplay
+	if err := w.client.Call(&contracts, "sclient_contracts"); err != nil {
+		return err
+	}
+	if len(contracts) == 0 {
+		w.results = append(w.results, "Host sessions: no contract to connect")
+		fmt.Println("No contract to connect")
+		return nil
+	}
+
+	var consistent, mismatched, unreachable int
+	for i, contract := range contracts {
+		var diff storage.ContractAuditDiff
+		err := w.client.Call(&diff, "sclient_auditContract", contract.ContractID)
+		switch {
+		case err != nil:
+			unreachable++
+			fmt.Printf("	[%d/%d] contract %v with host %v: %s\n", i+1, len(contracts), contract.ContractID, contract.HostID, err.Error())
+		case !diff.Consistent:
+			mismatched++
+			fmt.Printf("	[%d/%d] contract %v with host %v: %v mismatches\n", i+1, len(contracts), contract.ContractID, contract.HostID, len(diff.Mismatches))
+		default:
+			consistent++
+			fmt.Printf("	[%d/%d] contract %v with host %v: consistent\n", i+1, len(contracts), contract.ContractID, contract.HostID)
+		}
+	}
+	w.results = append(w.results, fmt.Sprintf("Host sessions: %v consistent, %v mismatched, %v unreachable of %v contracts",
+		consistent, mismatched, unreachable, len(contracts)))
+	return nil
+}
+
+// verifyFiles reports the files by their status
+func (w *recoveryWizard) verifyFiles() error {
+	var files []storage.FileBriefInfo
+	if err := w.client.Call(&files, "clientfiles_fileList"); err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, file := range files {
+		counts[file.Status]++
+	}
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	summary := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		summary = append(summary, fmt.Sprintf("%v %v", counts[status], status))
+		fmt.Printf("	%v: %v files\n", status, counts[status])
+	}
+	if len(files) == 0 {
+		summary = append(summary, "no file")
+		fmt.Println("No file to verify")
+	}
+	w.results = append(w.results, fmt.Sprintf("Files: %s", strings.Join(summary, ", ")))
+	return nil
+}
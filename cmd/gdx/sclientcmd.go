@@ -1,13 +1,17 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
 
 	"github.com/DxChainNetwork/godx/cmd/utils"
 	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/common/hexutil"
 	"github.com/DxChainNetwork/godx/node"
 	"github.com/DxChainNetwork/godx/rpc"
 	"github.com/DxChainNetwork/godx/storage"
@@ -18,6 +22,9 @@ import (
 	"gopkg.in/urfave/cli.v1"
 )
 
+// uploadStreamChunkSize is the size of the chunks the standard input is streamed in
+const uploadStreamChunkSize = 1 << 20
+
 var (
 	storageHostIDFlag = cli.StringFlag{
 		Name:  "hostid",
@@ -56,7 +63,7 @@ var (
 
 	fileSourceFlag = cli.StringFlag{
 		Name:  "src",
-		Usage: "Absolute path of the file that is going to be uploaded/downloaded from (source)",
+		Usage: "Absolute path of the file that is going to be uploaded/downloaded from (source), - to upload the standard input",
 	}
 
 	fileDestinationFlag = cli.StringFlag{
@@ -78,6 +85,21 @@ var (
 		Name:  "newpath",
 		Usage: "New absolute file path",
 	}
+
+	backupTargetFlag = cli.StringFlag{
+		Name:  "target",
+		Usage: "Backup target, such as scp://user@host/path, s3://bucket/prefix or a WebDAV url",
+	}
+
+	backupNameFlag = cli.StringFlag{
+		Name:  "name",
+		Usage: "Name of the backup to restore, the latest backup if not specified",
+	}
+
+	faucetFlag = cli.StringFlag{
+		Name:  "faucet",
+		Usage: "Faucet endpoint the funds are requested from, the one configured if not specified",
+	}
 )
 
 var storageClientCommand = cli.Command{
@@ -250,7 +272,29 @@ Note: without using any of those flags, default settings will be used`,
 		
 will upload the file specified by the client to the storage hosts. This command must be used along
 with two flags to specify the source of the file that is going to be uploaded, and the destination
-that the file is going to be uploaded to. Note: the src must be absolute path: /home/ubuntu/upload.file`,
+that the file is going to be uploaded to. Note: the src must be absolute path: /home/ubuntu/upload.file
+
+If the src is -, the data read from the standard input is streamed to the storage client, such as
+pg_dump mydb | gdx sclient upload --src - --dst backup/mydb.dump. The size, sha256 and merkle root of
+the data received by the storage client are reported once the input is finished.`,
+		},
+
+		{
+			Name:      "append",
+			Usage:     "Upload the data appended to the local file of an uploaded file",
+			ArgsUsage: "",
+			Action:    utils.MigrateFlags(fileAppend),
+			Flags: []cli.Flag{
+				fileSourceFlag,
+				fileDestinationFlag,
+			},
+			Description: `
+			gdx sclient append [--src arg] --dst arg
+
+will upload the data appended to the local file of the uploaded file specified by the dst flag,
+without uploading the whole file again, such as archiving a log file being written. The src flag
+specifies the local file grown from the uploaded file, whose data uploaded must be unchanged, and
+the local path of the uploaded file is used if not set. Note: the src must be absolute path.`,
 		},
 
 		{
@@ -328,6 +372,38 @@ will retrieve and display the cost that storage client needs to pay within one p
 The cost includes cost for all contracts. In addition, it also provides the contract fund left,
 fund unspent, and fund withhold, along with the withhold fund release block height`,
 		},
+		{
+			Name:      "restoreBackup",
+			Usage:     "Restore the contract set and file metadata from the encrypted backup",
+			ArgsUsage: "",
+			Action:    utils.MigrateFlags(restoreBackup),
+			Flags: []cli.Flag{
+				backupTargetFlag,
+				backupNameFlag,
+			},
+			Description: `
+			gdx sclient restoreBackup [--target arg] [--name arg]
+
+will fetch the encrypted metadata backup from the target, verify its checksum, and restore
+the contracts and the file metadata missing locally. The target flag must be used along with
+this command, and the backup passphrase will be prompted`,
+		},
+		{
+			Name:      "onboard",
+			Usage:     "Fund the payment address from the faucet and start the contract formation",
+			ArgsUsage: "",
+			Action:    utils.MigrateFlags(onboard),
+			Flags: []cli.Flag{
+				faucetFlag,
+			},
+			Description: `
+			gdx sclient onboard [--faucet arg]
+
+will set up the storage client for the first use on the test networks. If the payment address
+cannot afford the rent payment fund, the funds are requested from the faucet and waited to be
+confirmed. Then the default rent payment is set, which starts the contract formation. The faucet
+flag is saved as the faucet used afterwards`,
+		},
 	},
 }
 
@@ -672,6 +748,10 @@ func fileUpload(ctx *cli.Context) error {
 		destination = ctx.String(fileDestinationFlag.Name)
 	}
 
+	if source == "-" {
+		return streamUpload(client, os.Stdin, destination)
+	}
+
 	var resp string
 	if err = client.Call(&resp, "sclient_upload", source, destination); err != nil {
 		utils.Fatalf("failed to upload the file: %s", err.Error())
@@ -681,6 +761,77 @@ func fileUpload(ctx *cli.Context) error {
 	return nil
 }
 
+func fileAppend(ctx *cli.Context) error {
+	client, err := gdxAttach(ctx)
+	if err != nil {
+		utils.Fatalf("unable to connect to remote gdx, please start the gdx first: %s", err.Error())
+	}
+
+	if !ctx.IsSet(fileDestinationFlag.Name) {
+		utils.Fatalf("must specify the destination path of the uploaded file to append to")
+	}
+	source := ctx.String(fileSourceFlag.Name)
+	destination := ctx.String(fileDestinationFlag.Name)
+
+	var resp string
+	if err = client.Call(&resp, "sclient_append", source, destination); err != nil {
+		utils.Fatalf("failed to append to the file: %s", err.Error())
+	}
+
+	fmt.Println(resp)
+	return nil
+}
+
+// streamUpload streams the data read from r to the storage client chunk by chunk, and
+// verifies the size and sha256 of the data received by the storage client
+func streamUpload(client *rpc.Client, r io.Reader, destination string) error {
+	var id string
+	if err := client.Call(&id, "sclient_uploadStreamStart", destination); err != nil {
+		utils.Fatalf("failed to start the upload stream: %s", err.Error())
+	}
+
+	checksum := sha256.New()
+	buf := make([]byte, uploadStreamChunkSize)
+	var size uint64
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			checksum.Write(buf[:n])
+			if callErr := client.Call(&size, "sclient_uploadStreamWrite", id, hexutil.Bytes(buf[:n])); callErr != nil {
+				client.Call(nil, "sclient_uploadStreamAbort", id)
+				utils.Fatalf("failed to write the upload stream: %s", callErr.Error())
+			}
+			fmt.Fprintf(os.Stderr, "\rStreamed %v bytes", size)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			client.Call(nil, "sclient_uploadStreamAbort", id)
+			utils.Fatalf("failed to read the input: %s", err.Error())
+		}
+	}
+	fmt.Fprintln(os.Stderr)
+
+	var report storageclient.UploadStreamReport
+	if err := client.Call(&report, "sclient_uploadStreamFinish", id); err != nil {
+		utils.Fatalf("failed to finish the upload stream: %s", err.Error())
+	}
+	if report.SHA256 != hex.EncodeToString(checksum.Sum(nil)) {
+		utils.Fatalf("the sha256 %v of the data received does not match the data sent", report.SHA256)
+	}
+
+	fmt.Printf(`Stream Uploaded:
+	DxPath:          %v
+	Size:            %v bytes
+	Chunks:          %v
+	SHA256:          %v
+	MerkleRoot:      %v
+	Operation:       %v
+`, report.DxPath, report.Size, report.Chunks, report.SHA256, report.MerkleRoot.Hex(), report.OperationID)
+	return nil
+}
+
 // download remote file by sync mode
 // NOTE: RPC not support async download, because it is stateless, should block until download task done.
 func fileDownload(ctx *cli.Context) error {
@@ -824,6 +975,64 @@ func periodCost(ctx *cli.Context) error {
 	return nil
 }
 
+func restoreBackup(ctx *cli.Context) error {
+	client, err := gdxAttach(ctx)
+	if err != nil {
+		utils.Fatalf("unable to connect to remote gdx, please start the gdx first: %s", err.Error())
+	}
+
+	if !ctx.IsSet(backupTargetFlag.Name) {
+		utils.Fatalf("must specify the target the backup is shipped to")
+	}
+	target := ctx.String(backupTargetFlag.Name)
+	passphrase := getPassPhrase("Please give the backup passphrase.", false, 0, nil)
+
+	var manifest storageclient.BackupManifest
+	if err = client.Call(&manifest, "sclient_restoreBackup", target, ctx.String(backupNameFlag.Name), passphrase); err != nil {
+		utils.Fatalf("failed to restore the backup: %s", err.Error())
+	}
+
+	fmt.Printf(`Backup Restored:
+	Name:         %v
+	CreatedAt:    %v
+	Contracts:    %v
+	Files:        %v
+`, manifest.Name, manifest.CreatedAt, manifest.Contracts, manifest.Files)
+	return nil
+}
+
+func onboard(ctx *cli.Context) error {
+	client, err := gdxAttach(ctx)
+	if err != nil {
+		utils.Fatalf("unable to connect to remote gdx, please start the gdx first: %s", err.Error())
+	}
+
+	if ctx.IsSet(faucetFlag.Name) {
+		var resp string
+		if err = client.Call(&resp, "sclient_setFaucet", ctx.String(faucetFlag.Name)); err != nil {
+			utils.Fatalf("failed to set the faucet: %s", err.Error())
+		}
+	}
+
+	fmt.Println("onboarding, it may take a while for the faucet funds to be confirmed")
+	var result storageclient.OnboardResult
+	if err = client.Call(&result, "sclient_onboard"); err != nil {
+		utils.Fatalf("failed to onboard: %s", err.Error())
+	}
+
+	fmt.Printf(`Onboarded:
+	PaymentAddress:    %v
+	FundedByFaucet:    %v
+	Balance:           %v camel
+	RentFund:          %v camel
+	StorageHosts:      %v
+	Period:            %v blocks
+`, result.PaymentAddress.String(), result.Funded, result.Balance, result.RentPayment.Fund,
+		result.RentPayment.StorageHosts, result.RentPayment.Period)
+	fmt.Println("the contract formation is started, check the progress with gdx sclient contracts")
+	return nil
+}
+
 func gdxAttach(ctx *cli.Context) (*rpc.Client, error) {
 	path := node.DefaultDataDir()
 	if ctx.GlobalIsSet(utils.DataDirFlag.Name) {
@@ -868,13 +1077,14 @@ func hostRankingTable(rankings []storagehostmanager.StorageHostRank) *tablewrite
 
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetHeader([]string{"ID", "Total Evaluation", "AgeFactor", "DepositFactor",
-		"InteractionFactor", "PriceFactor", "RemainingStorageFactor", "UptimeFactor"})
+		"InteractionFactor", "PriceFactor", "RemainingStorageFactor", "UptimeFactor", "LatencyFactor", "SybilFactor"})
 
 	for _, rank := range rankings {
 		dataEntry := []string{rank.EnodeID, rank.Evaluation.String(), floatToString(rank.PresenceFactor),
 			floatToString(rank.DepositFactor),
 			floatToString(rank.InteractionFactor), floatToString(rank.ContractPriceFactor),
-			floatToString(rank.StorageRemainingFactor), floatToString(rank.UptimeFactor)}
+			floatToString(rank.StorageRemainingFactor), floatToString(rank.UptimeFactor),
+			floatToString(rank.LatencyFactor), floatToString(rank.SybilFactor)}
 
 		formattedData = append(formattedData, dataEntry)
 	}
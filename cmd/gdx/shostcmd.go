@@ -49,6 +49,26 @@ var (
 		Usage: "CURRENCY - the contract price when creating the contract",
 	}
 
+	dynamicPricingFlag = cli.StringFlag{
+		Name:  "dynamicPricing",
+		Usage: "BOOL - whether the storage price is adjusted based on the remaining capacity",
+	}
+
+	minStoragePriceFlag = cli.StringFlag{
+		Name:  "minStoragePrice",
+		Usage: "CURRENCY - the lower bound of the dynamic storage price per block per byte",
+	}
+
+	maxStoragePriceFlag = cli.StringFlag{
+		Name:  "maxStoragePrice",
+		Usage: "CURRENCY - the upper bound of the dynamic storage price per block per byte",
+	}
+
+	privateHostingFlag = cli.StringFlag{
+		Name:  "privateHosting",
+		Usage: "BOOL - whether the host only accepts contracts from the clients in its accept-list",
+	}
+
 	depositPriceFlag = cli.StringFlag{
 		Name:  "deposit",
 		Usage: "CURRENCY - deposit price per block per byte",
@@ -108,6 +128,10 @@ and etc.`,
 				downloadPriceFlag,
 				uploadPriceFlag,
 				storagePriceFlag,
+				dynamicPricingFlag,
+				minStoragePriceFlag,
+				maxStoragePriceFlag,
+				privateHostingFlag,
 				budgetPriceFlag,
 				maxDepositFlag,
 			},
@@ -179,6 +203,20 @@ If the host node has higher evaluation, client will automatically create contrac
 		`,
 		},
 
+		{
+			Name:      "revoke",
+			Usage:     "Retract the storage host announcement of the node",
+			ArgsUsage: "",
+			Action:    utils.MigrateFlags(makeRevoke),
+			Description: `
+			gdx shost revoke
+
+will stop accepting new contracts, and retract the storage host announcement of the node on chain.
+Storage client nodes drop the host once the revocation is included in a block. The existing contracts
+are not affected, and the node could be announced again later.
+		`,
+		},
+
 		{
 			Name:      "addFolder",
 			Usage:     "Allocate disk space for saving data uploaded by the storage client",
@@ -271,11 +309,17 @@ func getHostConfig(ctx *cli.Context) error {
 	SectorAccessPrice:             %v
 	StoragePrice:                  %v
 	UploadBandwidthPrice:          %v
+	DynamicPricing:                %v
+	MinStoragePrice:               %v
+	MaxStoragePrice:               %v
+	PrivateHosting:                %v
+	AcceptedClients:               %v
 `, config.AcceptingContracts, config.MaxDownloadBatchSize, config.MaxDuration,
 		config.MaxReviseBatchSize, config.WindowSize, config.PaymentAddress,
 		config.Deposit, config.DepositBudget, config.MaxDeposit, config.BaseRPCPrice,
 		config.ContractPrice, config.DownloadBandwidthPrice, config.SectorAccessPrice,
-		config.StoragePrice, config.UploadBandwidthPrice)
+		config.StoragePrice, config.UploadBandwidthPrice, config.DynamicPricing,
+		config.MinStoragePrice, config.MaxStoragePrice, config.PrivateHosting, config.AcceptedClients)
 
 	return nil
 }
@@ -322,6 +366,20 @@ func hostConfigFromFlags(ctx *cli.Context) map[string]string {
 		storagePrice := ctx.String(storagePriceFlag.Name)
 		config["storagePrice"] = storagePrice
 	}
+	// set the dynamic pricing mode and its bounds
+	if ctx.IsSet(dynamicPricingFlag.Name) {
+		config["dynamicPricing"] = ctx.String(dynamicPricingFlag.Name)
+	}
+	if ctx.IsSet(minStoragePriceFlag.Name) {
+		config["minStoragePrice"] = ctx.String(minStoragePriceFlag.Name)
+	}
+	if ctx.IsSet(maxStoragePriceFlag.Name) {
+		config["maxStoragePrice"] = ctx.String(maxStoragePriceFlag.Name)
+	}
+	// set the private hosting mode
+	if ctx.IsSet(privateHostingFlag.Name) {
+		config["privateHosting"] = ctx.String(privateHostingFlag.Name)
+	}
 	// set the upload price
 	if ctx.IsSet(uploadPriceFlag.Name) {
 		uploadPrice := ctx.String(uploadPriceFlag.Name)
@@ -450,6 +508,21 @@ func makeAnnounce(ctx *cli.Context) error {
 	return nil
 }
 
+func makeRevoke(ctx *cli.Context) error {
+	client, err := gdxAttach(ctx)
+	if err != nil {
+		utils.Fatalf("unable to connect to remote gdx, please start the gdx first: %s", err.Error())
+	}
+
+	var resp string
+	if err = client.Call(&resp, "shost_revoke"); err != nil {
+		utils.Fatalf("failed to revoke the storage host announcement: %s", err.Error())
+	}
+
+	fmt.Printf("%s \n\n", resp)
+	return nil
+}
+
 func addFolder(ctx *cli.Context) error {
 	client, err := gdxAttach(ctx)
 	if err != nil {
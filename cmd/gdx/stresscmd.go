@@ -0,0 +1,320 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DxChainNetwork/godx/cmd/utils"
+	"github.com/DxChainNetwork/godx/rpc"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	stressFilesFlag = cli.IntFlag{
+		Name:  "files",
+		Usage: "Number of files uploaded by the synthetic workload",
+		Value: 10,
+	}
+
+	stressFileSizeFlag = cli.Uint64Flag{
+		Name:  "filesize",
+		Usage: "Size of each file in bytes",
+		Value: 1 << 22,
+	}
+
+	stressDurationFlag = cli.DurationFlag{
+		Name:  "duration",
+		Usage: "Duration of the churn phase after the initial upload",
+		Value: 10 * time.Minute,
+	}
+
+	stressChurnFlag = cli.Float64Flag{
+		Name:  "churn",
+		Usage: "Number of churn operations per minute during the churn phase",
+		Value: 6,
+	}
+
+	stressDeleteRatioFlag = cli.Float64Flag{
+		Name:  "deleteratio",
+		Usage: "Ratio of the churn operations deleting and re-uploading a file, the rest download and verify a file",
+		Value: 0.5,
+	}
+
+	stressUploadTimeoutFlag = cli.DurationFlag{
+		Name:  "uploadtimeout",
+		Usage: "Maximum time waiting for a file to be fully uploaded",
+		Value: 30 * time.Minute,
+	}
+
+	stressWorkDirFlag = cli.StringFlag{
+		Name:  "workdir",
+		Usage: "Directory the synthetic files are generated in (default: temporary directory)",
+	}
+
+	stressKeepFilesFlag = cli.BoolFlag{
+		Name:  "keepfiles",
+		Usage: "Keep the uploaded files after the workload is finished",
+	}
+)
+
+var stressTestCommand = cli.Command{
+	Name:      "stresstest",
+	Usage:     "Generate synthetic storage workloads against the running storage client",
+	ArgsUsage: "",
+	Category:  "STORAGE CLIENT COMMANDS",
+	Action:    utils.MigrateFlags(stressTest),
+	Flags: []cli.Flag{
+		stressFilesFlag,
+		stressFileSizeFlag,
+		stressDurationFlag,
+		stressChurnFlag,
+		stressDeleteRatioFlag,
+		stressUploadTimeoutFlag,
+		stressWorkDirFlag,
+		stressKeepFilesFlag,
+	},
+	Description: `
+			gdx stresstest [--files arg] [--filesize arg] [--duration arg] [--churn arg] [--deleteratio arg]
+
+will generate the synthetic files and upload them through the running storage client, wait until
+they are fully uploaded, and then keep churning the files for the duration specified: each churn
+operation either deletes and re-uploads a file, or downloads a file and verifies its content. The
+latency and failures of each type of operation are reported at the end, which can be used to
+validate performance tuning and capacity planning before production use. Note, the workload
+consumes the storage client's contract fund.`,
+}
+
+// stressFile is a synthetic file of the stress test workload
+type stressFile struct {
+	localPath string
+	dxPath    string
+	checksum  [sha256.Size]byte
+}
+
+// stressOpStats records the statistics of a type of the stress test operation
+type stressOpStats struct {
+	name     string
+	count    int
+	failures int
+	bytes    uint64
+	total    time.Duration
+	max      time.Duration
+}
+
+// stressWorkload is the synthetic storage workload executed against the storage client
+type stressWorkload struct {
+	client        *rpc.Client
+	fileSize      uint64
+	uploadTimeout time.Duration
+	files         []*stressFile
+	stats         []*stressOpStats
+	rand          *rand.Rand
+}
+
+func stressTest(ctx *cli.Context) error {
+	client, err := gdxAttach(ctx)
+	if err != nil {
+		utils.Fatalf("unable to connect to remote gdx, please start the gdx first: %s", err.Error())
+	}
+
+	numFiles := ctx.Int(stressFilesFlag.Name)
+	fileSize := ctx.Uint64(stressFileSizeFlag.Name)
+	churn := ctx.Float64(stressChurnFlag.Name)
+	deleteRatio := ctx.Float64(stressDeleteRatioFlag.Name)
+	if numFiles <= 0 || fileSize == 0 {
+		utils.Fatalf("the number of files and the file size must be positive")
+	}
+	if churn < 0 || deleteRatio < 0 || deleteRatio > 1 {
+		utils.Fatalf("the churn rate must not be negative, and the delete ratio must be within [0, 1]")
+	}
+
+	workDir := ctx.String(stressWorkDirFlag.Name)
+	if workDir == "" {
+		if workDir, err = ioutil.TempDir("", "gdx-stresstest"); err != nil {
+			utils.Fatalf("failed to create the work directory: %s", err.Error())
+		}
+		defer os.RemoveAll(workDir)
+	} else if err = os.MkdirAll(workDir, 0700); err != nil {
+		utils.Fatalf("failed to create the work directory: %s", err.Error())
+	}
+
+	w := &stressWorkload{
+		client:        client,
+		fileSize:      fileSize,
+		uploadTimeout: ctx.Duration(stressUploadTimeoutFlag.Name),
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	runID := time.Now().Format("20060102150405")
+
+	// initial upload of all files
+	fmt.Printf("Uploading %v files of %v bytes\n", numFiles, fileSize)
+	for i := 0; i < numFiles; i++ {
+		file := &stressFile{
+			localPath: filepath.Join(workDir, fmt.Sprintf("file-%d", i)),
+			dxPath:    fmt.Sprintf("stresstest/%s/file-%d", runID, i),
+		}
+		w.files = append(w.files, file)
+		start := time.Now()
+		w.record("upload", start, fileSize, w.upload(file))
+	}
+
+	// churn the files
+	if churn > 0 {
+		duration := ctx.Duration(stressDurationFlag.Name)
+		interval := time.Duration(float64(time.Minute) / churn)
+		fmt.Printf("Churning the files for %v, one operation every %v\n", duration, interval)
+
+		deadline := time.Now().Add(duration)
+		for time.Now().Before(deadline) {
+			start := time.Now()
+			file := w.files[w.rand.Intn(len(w.files))]
+			if w.rand.Float64() < deleteRatio {
+				err := w.delete(file)
+				w.record("delete", start, 0, err)
+				if err == nil {
+					reupload := time.Now()
+					w.record("reupload", reupload, fileSize, w.upload(file))
+				}
+			} else {
+				w.record("download", start, fileSize, w.download(file))
+			}
+			if wait := interval - time.Since(start); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+	}
+
+	if !ctx.Bool(stressKeepFilesFlag.Name) {
+		for _, file := range w.files {
+			if err := w.delete(file); err != nil {
+				fmt.Printf("failed to delete %v: %s\n", file.dxPath, err.Error())
+			}
+		}
+	}
+
+	printStressStats(w.stats)
+	return nil
+}
+
+// upload generates the random content of the file, uploads the file, and waits
+// until the file is fully uploaded
+func (w *stressWorkload) upload(file *stressFile) (err error) {
+	data := make([]byte, w.fileSize)
+	w.rand.Read(data)
+	if err = ioutil.WriteFile(file.localPath, data, 0600); err != nil {
+		return
+	}
+	file.checksum = sha256.Sum256(data)
+
+	var resp string
+	if err = w.client.Call(&resp, "sclient_upload", file.localPath, file.dxPath); err != nil {
+		return
+	}
+
+	deadline := time.Now().Add(w.uploadTimeout)
+	for {
+		var info storage.FileInfo
+		if err = w.client.Call(&info, "clientfiles_detailedFileInfo", file.dxPath); err != nil {
+			return
+		}
+		if info.UploadProgress >= 100 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("upload progress %.2f%% after %v", info.UploadProgress, w.uploadTimeout)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// download downloads the file and verifies its content
+func (w *stressWorkload) download(file *stressFile) (err error) {
+	dst := file.localPath + ".download"
+	defer os.Remove(dst)
+
+	var resp string
+	if err = w.client.Call(&resp, "sclient_downloadSync", file.dxPath, dst); err != nil {
+		return
+	}
+	data, err := ioutil.ReadFile(dst)
+	if err != nil {
+		return
+	}
+	if checksum := sha256.Sum256(data); !bytes.Equal(checksum[:], file.checksum[:]) {
+		return fmt.Errorf("content of the downloaded file %v does not match", file.dxPath)
+	}
+	return nil
+}
+
+// delete deletes the file uploaded
+func (w *stressWorkload) delete(file *stressFile) error {
+	var resp string
+	return w.client.Call(&resp, "clientfiles_delete", file.dxPath)
+}
+
+// record updates the statistics of the operation, which started at the time provided
+func (w *stressWorkload) record(name string, start time.Time, size uint64, err error) {
+	latency := time.Since(start)
+
+	var stats *stressOpStats
+	for _, s := range w.stats {
+		if s.name == name {
+			stats = s
+		}
+	}
+	if stats == nil {
+		stats = &stressOpStats{name: name}
+		w.stats = append(w.stats, stats)
+	}
+	stats.count++
+	stats.total += latency
+	if latency > stats.max {
+		stats.max = latency
+	}
+	if err != nil {
+		stats.failures++
+		fmt.Printf("%v failed: %s\n", name, err.Error())
+	} else {
+		stats.bytes += size
+	}
+}
+
+// printStressStats prints the latency, failures, and throughput of each type of the
+// stress test operation
+func printStressStats(stats []*stressOpStats) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Operation", "Count", "Failures", "Avg Latency", "Max Latency", "Throughput"})
+	for _, s := range stats {
+		var avg time.Duration
+		var throughput float64
+		if s.count > 0 {
+			avg = s.total / time.Duration(s.count)
+		}
+		if s.total > 0 {
+			throughput = float64(s.bytes) / s.total.Seconds()
+		}
+		table.Append([]string{
+			s.name,
+			fmt.Sprintf("%d", s.count),
+			fmt.Sprintf("%d", s.failures),
+			avg.Round(time.Millisecond).String(),
+			s.max.Round(time.Millisecond).String(),
+			fmt.Sprintf("%.0f B/s", throughput),
+		})
+	}
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.Render()
+	fmt.Println()
+}
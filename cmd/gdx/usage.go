@@ -161,6 +161,9 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.IPCPathFlag,
 			utils.RPCCORSDomainFlag,
 			utils.RPCVirtualHostsFlag,
+			utils.RPCAuthTokensFlag,
+			utils.RPCAuthApiFlag,
+			utils.RPCRateLimitFlag,
 			utils.JSpathFlag,
 			utils.ExecFlag,
 			utils.PreloadJSFlag,
@@ -211,6 +214,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.VMEnableDebugFlag,
 			utils.EVMInterpreterFlag,
 			utils.EWASMInterpreterFlag,
+			utils.EVMCallTimeoutFlag,
 		},
 	},
 	{
@@ -236,6 +240,11 @@ var AppHelpFlagGroups = []flagGroup{
 		Name: "STORAGE",
 		Flags: []cli.Flag{
 			utils.StorageRoleFlag,
+			utils.StorageClientCoordinationFlag,
+			utils.StorageClientReplicaFlag,
+			utils.StorageClientSpillFlag,
+			utils.StorageClientForceTakeoverFlag,
+			utils.StorageClientHTTPFlag,
 		},
 	},
 	{
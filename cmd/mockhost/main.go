@@ -0,0 +1,131 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// mockhost runs the lightweight storage hosts for developing the storage client locally,
+// without running full nodes as the hosts. The hosts listen on consecutive ports from the
+// listening address, and are added to the storage client by the enode urls printed, with
+// the RPC method sclient_addHost.
+//
+// The contracts are signed as they are, and the sectors are kept in memory, or in a
+// temporary directory removed on exit. Faults can be injected with the flags to exercise
+// the error handling of the storage client.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/eth"
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/params"
+	"github.com/DxChainNetwork/godx/storage/mockhost"
+)
+
+func main() {
+	var (
+		listen     = flag.String("listen", "127.0.0.1:36000", "listening address of the first host, the others listen on the following ports")
+		hosts      = flag.Int("hosts", 1, "number of the hosts to run")
+		networkID  = flag.Uint64("networkid", eth.DefaultConfig.NetworkId, "network id of the storage client")
+		genesis    = flag.String("genesis", params.MainnetGenesisHash.Hex(), "genesis block hash of the storage client")
+		accountKey = flag.String("accountkey", "", "file of the payment account key shared by the hosts (default random for each host)")
+		disk       = flag.Bool("disk", false, "store the sectors in a temporary directory instead of memory")
+		capacity   = flag.Uint64("storage", mockhost.DefaultTotalStorage, "storage capacity of each host in bytes")
+		verbosity  = flag.Int("verbosity", int(log.LvlInfo), "log verbosity (0-9)")
+
+		latency    = flag.Duration("latency", 0, "delay before each message is sent")
+		reject     = flag.Float64("reject", 0, "rate of the requests rejected with the negotiate error")
+		stall      = flag.Float64("stall", 0, "rate of the requests never answered")
+		disconnect = flag.Float64("disconnect", 0, "rate of the requests answered by dropping the connection")
+		corrupt    = flag.Float64("corrupt", 0, "rate of the downloads answered with the data corrupted")
+		seed       = flag.Int64("seed", 0, "seed of the random faults")
+	)
+	flag.Parse()
+
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(*verbosity))
+	log.Root().SetHandler(glogger)
+
+	host, portStr, err := net.SplitHostPort(*listen)
+	if err != nil {
+		fatalf("invalid listening address %v: %v", *listen, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		fatalf("invalid listening port %v: %v", portStr, err)
+	}
+	if len(common.FromHex(*genesis)) != common.HashLength {
+		fatalf("invalid genesis block hash %v", *genesis)
+	}
+
+	config := mockhost.Config{
+		NetworkID:    *networkID,
+		Genesis:      common.HexToHash(*genesis),
+		TotalStorage: *capacity,
+		Faults: mockhost.Faults{
+			Latency:        *latency,
+			RejectRate:     *reject,
+			StallRate:      *stall,
+			DisconnectRate: *disconnect,
+			CorruptRate:    *corrupt,
+			Seed:           *seed,
+		},
+	}
+	if *accountKey != "" {
+		if config.AccountKey, err = crypto.LoadECDSA(*accountKey); err != nil {
+			fatalf("failed to load the account key: %v", err)
+		}
+	}
+
+	var sectorDir string
+	if *disk {
+		if sectorDir, err = ioutil.TempDir("", "mockhost"); err != nil {
+			fatalf("failed to create the sector directory: %v", err)
+		}
+		defer os.RemoveAll(sectorDir)
+	}
+
+	var started []*mockhost.MockHost
+	for i := 0; i < *hosts; i++ {
+		config.ListenAddr = net.JoinHostPort(host, strconv.Itoa(port+i))
+		if sectorDir != "" {
+			config.SectorDir = filepath.Join(sectorDir, strconv.Itoa(i))
+		}
+		mh, err := mockhost.New(config)
+		if err == nil {
+			err = mh.Start()
+		}
+		if err != nil {
+			stopAll(started)
+			os.RemoveAll(sectorDir)
+			fatalf("failed to start the mock host %d: %v", i, err)
+		}
+		started = append(started, mh)
+		fmt.Printf("%s %s\n", mh.Enode(), mh.PaymentAddress().Hex())
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	<-sigc
+	stopAll(started)
+}
+
+func stopAll(hosts []*mockhost.MockHost) {
+	for _, mh := range hosts {
+		mh.Stop()
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// storagetxschema emits the canonical RLP schema and the test vectors of the storage
+// contract transaction payloads. The output is checked in as test data, so that any
+// change of the encoding, which would split the consensus, is caught by the tests.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/DxChainNetwork/godx/core/types"
+)
+
+// output is the content emitted by the generator
+type output struct {
+	Schemas []types.StorageTxSchema     `json:"schemas"`
+	Vectors []types.StorageTxTestVector `json:"vectors"`
+}
+
+func main() {
+	out := flag.String("out", "", "output file (default stdout)")
+	flag.Parse()
+
+	vectors, err := types.StorageTxTestVectors()
+	if err != nil {
+		fatalf("failed to generate the test vectors: %v", err)
+	}
+
+	blob, err := json.MarshalIndent(output{
+		Schemas: types.StorageTxSchemas(),
+		Vectors: vectors,
+	}, "", "  ")
+	if err != nil {
+		fatalf("failed to encode the schema: %v", err)
+	}
+	blob = append(blob, '\n')
+
+	if *out == "" {
+		os.Stdout.Write(blob)
+		return
+	}
+	if err := ioutil.WriteFile(*out, blob, 0644); err != nil {
+		fatalf("failed to write the schema: %v", err)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
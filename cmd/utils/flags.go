@@ -427,6 +427,21 @@ var (
 		Usage: "API's offered over the HTTP-RPC interface",
 		Value: "",
 	}
+	RPCAuthTokensFlag = cli.StringFlag{
+		Name:  "rpcauthtokens",
+		Usage: "Comma separated list of HTTP-RPC access tokens in the format of token:tier, where the tier is read or full",
+		Value: "",
+	}
+	RPCAuthApiFlag = cli.StringFlag{
+		Name:  "rpcauthapi",
+		Usage: "API's protected by the HTTP-RPC access tokens",
+		Value: strings.Join(node.DefaultConfig.HTTPAuthNamespaces, ","),
+	}
+	RPCRateLimitFlag = cli.Float64Flag{
+		Name:  "rpcratelimit",
+		Usage: "Number of calls allowed per second for each protected HTTP-RPC method and token (0 = unlimited)",
+		Value: 0,
+	}
 	IPCDisabledFlag = cli.BoolFlag{
 		Name:  "ipcdisable",
 		Usage: "Disable the IPC-RPC server",
@@ -585,20 +600,45 @@ var (
 
 	EWASMInterpreterFlag = cli.StringFlag{
 		Name:  "vm.ewasm",
-		Usage: "External ewasm configuration (default = built-in interpreter)",
+		Usage: "Registered ewasm interpreter in the format of name[:option...] (default = built-in interpreter)",
 		Value: "",
 	}
 	EVMInterpreterFlag = cli.StringFlag{
 		Name:  "vm.evm",
-		Usage: "External EVM configuration (default = built-in interpreter)",
+		Usage: "Registered EVM interpreter in the format of name[:option...], with the built-in interpreter as failover",
 		Value: "",
 	}
+	EVMCallTimeoutFlag = cli.DurationFlag{
+		Name:  "vm.calltimeout",
+		Usage: "Execution time budget of eth_call and eth_estimateGas (0 = unlimited)",
+		Value: eth.DefaultConfig.EVMCallTimeout,
+	}
 
 	// Storage role flag
 	StorageRoleFlag = cli.StringFlag{
 		Name:  "role",
 		Usage: "Chooses which role a node can be. There are four options: all, host, client, and none",
 	}
+	StorageClientCoordinationFlag = DirectoryFlag{
+		Name:  "storageclient.coordination",
+		Usage: "Experimental: directory shared with other nodes to act as one logical storage client",
+	}
+	StorageClientReplicaFlag = DirectoryFlag{
+		Name:  "storageclient.replica",
+		Usage: "Directory of the snapshot exported by the primary storage client, serving its downloads as read-only replica",
+	}
+	StorageClientSpillFlag = DirectoryFlag{
+		Name:  "storageclient.spill",
+		Usage: "Directory to spill the encoded upload data to when the storage client memory is saturated",
+	}
+	StorageClientForceTakeoverFlag = cli.BoolFlag{
+		Name:  "force-takeover",
+		Usage: "Take over the storage client data directory locks left stale by another instance which is no longer running",
+	}
+	StorageClientHTTPFlag = cli.StringFlag{
+		Name:  "storageclient.http",
+		Usage: "Listening address of the HTTP endpoint streaming the storage client files with range requests, e.g. 127.0.0.1:8580. It requires the rpcauthtokens to listen on a non-loopback address",
+	}
 )
 
 // MakeDataDir retrieves the currently requested data directory, terminating
@@ -759,6 +799,15 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(RPCVirtualHostsFlag.Name) {
 		cfg.HTTPVirtualHosts = splitAndTrim(ctx.GlobalString(RPCVirtualHostsFlag.Name))
 	}
+	if ctx.GlobalIsSet(RPCAuthTokensFlag.Name) {
+		cfg.HTTPAuthTokens = splitAndTrim(ctx.GlobalString(RPCAuthTokensFlag.Name))
+	}
+	if ctx.GlobalIsSet(RPCAuthApiFlag.Name) {
+		cfg.HTTPAuthNamespaces = splitAndTrim(ctx.GlobalString(RPCAuthApiFlag.Name))
+	}
+	if ctx.GlobalIsSet(RPCRateLimitFlag.Name) {
+		cfg.HTTPRateLimit = ctx.GlobalFloat64(RPCRateLimitFlag.Name)
+	}
 }
 
 // setWS creates the WebSocket RPC listener interface string from the set
@@ -1196,6 +1245,10 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		cfg.EVMInterpreter = ctx.GlobalString(EVMInterpreterFlag.Name)
 	}
 
+	if ctx.GlobalIsSet(EVMCallTimeoutFlag.Name) {
+		cfg.EVMCallTimeout = ctx.GlobalDuration(EVMCallTimeoutFlag.Name)
+	}
+
 	if ctx.GlobalIsSet(StorageRoleFlag.Name) {
 		role := ctx.GlobalString(StorageRoleFlag.Name)
 		switch {
@@ -1214,6 +1267,22 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		}
 	}
 
+	if ctx.GlobalIsSet(StorageClientCoordinationFlag.Name) {
+		cfg.StorageClientCoordinationDir = ctx.GlobalString(StorageClientCoordinationFlag.Name)
+	}
+	if ctx.GlobalIsSet(StorageClientReplicaFlag.Name) {
+		cfg.StorageClientReplicaDir = ctx.GlobalString(StorageClientReplicaFlag.Name)
+	}
+	if ctx.GlobalIsSet(StorageClientSpillFlag.Name) {
+		cfg.StorageClientSpillDir = ctx.GlobalString(StorageClientSpillFlag.Name)
+	}
+	if ctx.GlobalIsSet(StorageClientForceTakeoverFlag.Name) {
+		cfg.StorageClientForceTakeover = ctx.GlobalBool(StorageClientForceTakeoverFlag.Name)
+	}
+	if ctx.GlobalIsSet(StorageClientHTTPFlag.Name) {
+		cfg.StorageClientHTTPAddr = ctx.GlobalString(StorageClientHTTPFlag.Name)
+	}
+
 	// If datadir is set, change ethash directory
 	if ctx.GlobalIsSet(DataDirFlag.Name) {
 		cfg.Ethash.DatasetDir = filepath.Join(ctx.GlobalString(DataDirFlag.Name), "Ethash")
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 
 	"github.com/DxChainNetwork/godx/rlp"
@@ -38,12 +39,15 @@ func NewBigIntUint64(x uint64) BigInt {
 	}
 }
 
-// NewBigIntFloat64 will be used to convert the float64 data type into BigInt data type
-func NewBigIntFloat64(x float64) BigInt {
-	v := uint64(x)
-	return BigInt{
-		b: *new(big.Int).SetUint64(v),
+// NewBigIntFloat64 will be used to convert the float64 data type into BigInt data type. The
+// fraction is truncated, and the value beyond uint64 is kept in full. The negative value and
+// the value that is not a number are converted to 0
+func NewBigIntFloat64(x float64) (y BigInt) {
+	if math.IsNaN(x) || math.IsInf(x, 0) || x <= 0 {
+		return
 	}
+	new(big.Float).SetFloat64(x).Int(&y.b)
+	return
 }
 
 // RandomBigIntRange will randomly return a BigInt data based on the range provided
@@ -216,17 +220,19 @@ func (x BigInt) Float64() (result float64) {
 	return
 }
 
-// BigIntPtr will return the pointer version of the big.Int
+// BigIntPtr will return the pointer version of the big.Int. The big.Int returned is a copy,
+// so that changing it in place does not change the BigInt
 func (x BigInt) BigIntPtr() *big.Int {
-	return &x.b
+	return new(big.Int).Set(&x.b)
 }
 
-// PtrBigInt convert the pointer version of big.Int to BigInt type
+// PtrBigInt convert the pointer version of big.Int to BigInt type in full precision. The
+// BigInt holds a copy, so that changing the big.Int in place does not change the BigInt.
+// The nil big.Int is converted to 0
 func PtrBigInt(x *big.Int) (y BigInt) {
-	y = BigInt{
-		b: *x,
+	if x != nil {
+		y.b.Set(x)
 	}
-
 	return
 }
 
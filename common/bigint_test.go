@@ -5,6 +5,8 @@
 package common
 
 import (
+	"math"
+	"math/big"
 	"testing"
 )
 
@@ -253,3 +255,49 @@ func TestBigInt_DivWithFloatResult(t *testing.T) {
 		}
 	}
 }
+
+func TestBigIntLargeDenominations(t *testing.T) {
+	// 10^30 is far beyond both int64 and uint64
+	large, _ := new(big.Int).SetString("1000000000000000000000000000000", 10)
+
+	x := PtrBigInt(large)
+	if x.String() != large.String() {
+		t.Fatalf("expect %v, got %v", large, x)
+	}
+	if sum := x.Add(x); sum.BigIntPtr().Cmp(new(big.Int).Mul(large, big.NewInt(2))) != 0 {
+		t.Errorf("expect %v, got %v", new(big.Int).Mul(large, big.NewInt(2)), sum)
+	}
+	if prod := x.MultUint64(math.MaxUint64); prod.BigIntPtr().Cmp(new(big.Int).Mul(large, new(big.Int).SetUint64(math.MaxUint64))) != 0 {
+		t.Errorf("the product overflows, got %v", prod)
+	}
+
+	// the conversions copy the value, so that changing the big.Int in place does not change the BigInt
+	large.Add(large, big.NewInt(1))
+	if x.BigIntPtr().Cmp(large) == 0 {
+		t.Error("PtrBigInt shall hold a copy of the big.Int")
+	}
+	ptr := x.BigIntPtr()
+	ptr.SetInt64(0)
+	if x.Sign() == 0 {
+		t.Error("BigIntPtr shall return a copy of the BigInt")
+	}
+	if PtrBigInt(nil).Sign() != 0 {
+		t.Error("the nil big.Int shall be converted to 0")
+	}
+
+	// float64 beyond uint64 is kept in full
+	tables := []struct {
+		f      float64
+		expect *big.Int
+	}{
+		{math.Exp2(80), new(big.Int).Lsh(big.NewInt(1), 80)},
+		{1234.9, big.NewInt(1234)},
+		{-1, big.NewInt(0)},
+		{math.NaN(), big.NewInt(0)},
+	}
+	for _, table := range tables {
+		if got := NewBigIntFloat64(table.f); got.BigIntPtr().Cmp(table.expect) != 0 {
+			t.Errorf("float64 %v: expect %v, got %v", table.f, table.expect, got)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package filelock provides the OS-level advisory lock on a lock file, which prevents
+// the persisted data guarded by the lock file from being opened by two processes at the
+// same time. The lock is released by the OS once the process holding it exits
+package filelock
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/prometheus/prometheus/util/flock"
+)
+
+// lockInUseErrnos are the errnos returned when the lock is held by another process, which
+// are EAGAIN on linux, EWOULDBLOCK on darwin and ERROR_SHARING_VIOLATION on windows
+var lockInUseErrnos = map[uint]bool{11: true, 32: true, 35: true}
+
+// LockedError is returned when the lock file is held by another process
+type LockedError struct {
+	Path string
+}
+
+// Error implements the error interface
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%v is locked by another running instance using the same data directory, "+
+		"stop that instance first, or restart with --force-takeover if the lock is stale", e.Path)
+}
+
+// IsLocked checks whether the error is returned because the lock is held by another process
+func IsLocked(err error) bool {
+	_, ok := err.(*LockedError)
+	return ok
+}
+
+// Lock is the advisory lock acquired on a lock file
+type Lock struct {
+	path     string
+	releaser flock.Releaser
+	once     sync.Once
+}
+
+// Acquire acquires the lock on the lock file at the path, the lock file is created if not
+// exist. LockedError is returned if the lock is held by another process
+func Acquire(path string) (*Lock, error) {
+	releaser, _, err := flock.New(path)
+	if err != nil {
+		if errno, ok := err.(syscall.Errno); ok && lockInUseErrnos[uint(errno)] {
+			return nil, &LockedError{Path: path}
+		}
+		return nil, fmt.Errorf("failed to lock %v: %v", path, err)
+	}
+	return &Lock{
+		path:     path,
+		releaser: releaser,
+	}, nil
+}
+
+// Takeover removes the lock file at the path, so that the lock could be acquired again even
+// if the lock file is still held, which happens when the lock is left stale on the network
+// file systems. The caller must make sure no running process is using the guarded data
+func Takeover(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to take over the lock %v: %v", path, err)
+	}
+	return nil
+}
+
+// Path returns the path of the lock file
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// Release releases the lock. It is safe to release the lock multiple times, or release the
+// nil lock not acquired
+func (l *Lock) Release() (err error) {
+	if l == nil {
+		return nil
+	}
+	l.once.Do(func() {
+		err = l.releaser.Release()
+	})
+	return
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package filelock
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestAcquire(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filelock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test.lock")
+
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("failed to acquire the lock: %v", err)
+	}
+	if _, err = Acquire(path); !IsLocked(err) {
+		t.Fatalf("acquiring the lock held shall fail with the locked error, got %v", err)
+	}
+
+	// the lock could be acquired again once released
+	if err = lock.Release(); err != nil {
+		t.Fatalf("failed to release the lock: %v", err)
+	}
+	if err = lock.Release(); err != nil {
+		t.Fatalf("releasing the lock twice shall not fail: %v", err)
+	}
+	if lock, err = Acquire(path); err != nil {
+		t.Fatalf("failed to acquire the lock released: %v", err)
+	}
+	defer lock.Release()
+
+	// the lock could be acquired after the takeover even if the lock file is still held. The
+	// lock file held could not be removed on windows
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if err = Takeover(path); err != nil {
+		t.Fatalf("failed to take over the lock: %v", err)
+	}
+	taken, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("failed to acquire the lock taken over: %v", err)
+	}
+	taken.Release()
+}
@@ -55,7 +55,41 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 		Difficulty:  new(big.Int).Set(header.Difficulty),
 		GasLimit:    header.GasLimit,
 		GasPrice:    new(big.Int).Set(msg.GasPrice()),
+
+		StorageContractStore: newStorageContractStore(chain),
+	}
+}
+
+// canonicalHeaderReader is implemented by the chains that can retrieve the canonical
+// block header by number
+type canonicalHeaderReader interface {
+	GetHeaderByNumber(number uint64) *types.Header
+}
+
+// chainStorageContractStore is the vm.StorageContractStore reading the chain data
+// from the canonical chain
+type chainStorageContractStore struct {
+	chain canonicalHeaderReader
+}
+
+// newStorageContractStore creates the vm.StorageContractStore based on the chain. If
+// the chain cannot provide the canonical headers, nil is returned, and the EVM will
+// fall back to read from the chain database
+func newStorageContractStore(chain ChainContext) vm.StorageContractStore {
+	reader, ok := chain.(canonicalHeaderReader)
+	if !ok {
+		return nil
+	}
+	return chainStorageContractStore{chain: reader}
+}
+
+// CanonicalHash returns the hash of the canonical block at the height
+func (s chainStorageContractStore) CanonicalHash(number uint64) common.Hash {
+	header := s.chain.GetHeaderByNumber(number)
+	if header == nil {
+		return common.Hash{}
 	}
+	return header.Hash()
 }
 
 // GetHashFn returns a GetHashFunc which retrieves header hashes by number
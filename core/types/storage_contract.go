@@ -10,6 +10,8 @@ import (
 	"github.com/DxChainNetwork/godx/common"
 )
 
+//go:generate go run ../../cmd/storagetxschema -out testdata/storagetx_schema.json
+
 type StorageContractRLPHash interface {
 	RLPHash() common.Hash
 }
@@ -20,6 +22,16 @@ type HostAnnouncement struct {
 	Signature  []byte
 }
 
+// HostRevocation retracts the host announcement of the NetAddress. It is signed by the host
+// node the same as the announcement, and is only valid within a few blocks after BlockNumber,
+// so that it could not be replayed once the host announces again
+type HostRevocation struct {
+	// host enode url
+	NetAddress  string
+	BlockNumber uint64
+	Signature   []byte
+}
+
 type UnlockConditions struct {
 	PaymentAddresses   []common.Address `json:"paymentaddress"`
 	SignaturesRequired uint64           `json:"signaturesrequired"`
@@ -70,6 +82,25 @@ type StorageContractRevision struct {
 	Signatures            [][]byte
 }
 
+// StorageContractRenewal closes the storage contract OldContractID and creates NewContract as
+// its successor storing the same file. The renewal is signed by both the client and the host,
+// which authorizes closing the old contract and creating the new one at the same time
+type StorageContractRenewal struct {
+	OldContractID common.Hash     `json:"oldcontractid"`
+	NewContract   StorageContract `json:"newcontract"`
+	Signatures    [][]byte
+}
+
+// StorageEscrow funds the escrow account of the renter from the treasury account, and sets
+// the spending cap of the renter. The renter can form the storage contracts with the
+// collateral paid by the escrow account until the total collateral reaches the spending cap
+type StorageEscrow struct {
+	Treasury    common.Address `json:"treasury"`
+	Renter      common.Address `json:"renter"`
+	Amount      *big.Int       `json:"amount"`
+	SpendingCap *big.Int       `json:"spendingcap"`
+}
+
 type StorageProof struct {
 	ParentID  common.Hash   `json:"parentid"`
 	Segment   [64]byte      `json:"segment"`
@@ -77,6 +108,12 @@ type StorageProof struct {
 	Signature []byte
 }
 
+// StorageProofBatch is the storage proofs of multiple storage contracts submitted in one
+// transaction. Each proof is signed and verified the same as submitted alone
+type StorageProofBatch struct {
+	Proofs []StorageProof `json:"proofs"`
+}
+
 // RLPHash calculate the hash of HostAnnouncement
 func (ha HostAnnouncement) RLPHash() common.Hash {
 	return rlpHash([]interface{}{
@@ -84,6 +121,16 @@ func (ha HostAnnouncement) RLPHash() common.Hash {
 	})
 }
 
+// RLPHash calculate the hash of HostRevocation. The hash is prefixed with the type name, so
+// that the signature of the host announcement could not be taken as the revocation
+func (hr HostRevocation) RLPHash() common.Hash {
+	return rlpHash([]interface{}{
+		"HostRevocation",
+		hr.NetAddress,
+		hr.BlockNumber,
+	})
+}
+
 // RLPHash calculate the hash of StorageContract
 func (sc StorageContract) RLPHash() common.Hash {
 	return rlpHash([]interface{}{
@@ -127,6 +174,16 @@ func (scr StorageContractRevision) RLPHash() common.Hash {
 	})
 }
 
+// RLPHash calculate the hash of StorageContractRenewal. The hash is prefixed with the type name,
+// so that the signatures of the new contract could not be taken as the renewal
+func (r StorageContractRenewal) RLPHash() common.Hash {
+	return rlpHash([]interface{}{
+		"StorageContractRenewal",
+		r.OldContractID,
+		r.NewContract.ID(),
+	})
+}
+
 // RLPHash calculate the hash of StorageProof
 func (sp StorageProof) RLPHash() common.Hash {
 	return rlpHash([]interface{}{
@@ -135,3 +192,13 @@ func (sp StorageProof) RLPHash() common.Hash {
 		sp.HashSet,
 	})
 }
+
+// EscrowAddress returns the address of the escrow account of the renter funded by the treasury
+func EscrowAddress(treasury, renter common.Address) common.Address {
+	h := rlpHash([]interface{}{
+		"StorageEscrow",
+		treasury,
+		renter,
+	})
+	return common.BytesToAddress(h[12:])
+}
@@ -0,0 +1,242 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/common/hexutil"
+	"github.com/DxChainNetwork/godx/rlp"
+)
+
+// ErrNonCanonicalStorageTx is returned when the storage contract transaction payload is
+// decodable, but is not the canonical encoding of the decoded value
+var ErrNonCanonicalStorageTx = errors.New("non-canonical rlp encoding of storage contract transaction")
+
+// DecodeStorageTxStrict decodes the storage contract transaction payload. Besides the
+// trailing bytes and unexpected list shapes rejected by rlp.DecodeBytes, the payload
+// must be exactly the canonical encoding of the decoded value, so that all nodes agree
+// on the payloads accepted regardless of decoder leniency
+func DecodeStorageTxStrict(b []byte, val interface{}) error {
+	if err := rlp.DecodeBytes(b, val); err != nil {
+		return err
+	}
+	canonical, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(canonical, b) {
+		return ErrNonCanonicalStorageTx
+	}
+	return nil
+}
+
+// StorageTxField describes the RLP encoding of a field of the storage contract transaction
+type StorageTxField struct {
+	Name   string           `json:"name"`
+	Type   string           `json:"type"`
+	Fields []StorageTxField `json:"fields,omitempty"`
+}
+
+// StorageTxSchema is the RLP schema of a storage contract transaction payload
+type StorageTxSchema struct {
+	Name   string           `json:"name"`
+	Fields []StorageTxField `json:"fields"`
+}
+
+// StorageTxTestVector is the canonical encoding of a sample storage contract transaction payload
+type StorageTxTestVector struct {
+	Name     string        `json:"name"`
+	Encoding hexutil.Bytes `json:"encoding"`
+}
+
+var (
+	bigIntType  = reflect.TypeOf(big.Int{})
+	hashType    = reflect.TypeOf(common.Hash{})
+	addressType = reflect.TypeOf(common.Address{})
+)
+
+// storageTxPayloads returns the sample payloads of all types of storage contract transactions
+func storageTxPayloads() []interface{} {
+	charge := DxcoinCharge{
+		Address: common.HexToAddress("0x1000000000000000000000000000000000000001"),
+		Value:   big.NewInt(1000000),
+	}
+	collateral := DxcoinCollateral{DxcoinCharge: charge}
+	signatures := [][]byte{bytes.Repeat([]byte{0x01}, 65), bytes.Repeat([]byte{0x02}, 65)}
+
+	contract := StorageContract{
+		FileSize:           1 << 22,
+		FileMerkleRoot:     common.HexToHash("0x05"),
+		WindowStart:        1000,
+		WindowEnd:          1100,
+		ClientCollateral:   collateral,
+		HostCollateral:     collateral,
+		ValidProofOutputs:  []DxcoinCharge{charge, charge},
+		MissedProofOutputs: []DxcoinCharge{charge, charge},
+		UnlockHash:         common.HexToHash("0x06"),
+		RevisionNumber:     1,
+		Signatures:         signatures,
+	}
+
+	segment := [64]byte{}
+	copy(segment[:], bytes.Repeat([]byte{0x03}, 64))
+
+	return []interface{}{
+		&HostAnnouncement{
+			NetAddress: "enode://0000000000000000000000000000000000000000000000000000000000000000" +
+				"0000000000000000000000000000000000000000000000000000000000000000@127.0.0.1:36000",
+			Signature: bytes.Repeat([]byte{0x04}, 65),
+		},
+		&contract,
+		&StorageContractRevision{
+			ParentID: common.HexToHash("0x07"),
+			UnlockConditions: UnlockConditions{
+				PaymentAddresses:   []common.Address{charge.Address, charge.Address},
+				SignaturesRequired: 2,
+			},
+			NewRevisionNumber:     2,
+			NewFileSize:           1 << 22,
+			NewFileMerkleRoot:     common.HexToHash("0x08"),
+			NewWindowStart:        1000,
+			NewWindowEnd:          1100,
+			NewValidProofOutputs:  []DxcoinCharge{charge, charge},
+			NewMissedProofOutputs: []DxcoinCharge{charge, charge},
+			NewUnlockHash:         common.HexToHash("0x06"),
+			Signatures:            signatures,
+		},
+		&StorageProof{
+			ParentID:  common.HexToHash("0x07"),
+			Segment:   segment,
+			HashSet:   []common.Hash{common.HexToHash("0x09"), common.HexToHash("0x0a")},
+			Signature: bytes.Repeat([]byte{0x0b}, 65),
+		},
+		&StorageEscrow{
+			Treasury:    charge.Address,
+			Renter:      common.HexToAddress("0x1000000000000000000000000000000000000002"),
+			Amount:      big.NewInt(1000000),
+			SpendingCap: big.NewInt(2000000),
+		},
+		&HostRevocation{
+			NetAddress: "enode://0000000000000000000000000000000000000000000000000000000000000000" +
+				"0000000000000000000000000000000000000000000000000000000000000000@127.0.0.1:36000",
+			BlockNumber: 1000,
+			Signature:   bytes.Repeat([]byte{0x0c}, 65),
+		},
+		&StorageProofBatch{
+			Proofs: []StorageProof{
+				{
+					ParentID:  common.HexToHash("0x07"),
+					Segment:   segment,
+					HashSet:   []common.Hash{common.HexToHash("0x09")},
+					Signature: bytes.Repeat([]byte{0x0b}, 65),
+				},
+				{
+					ParentID:  common.HexToHash("0x0d"),
+					Segment:   segment,
+					HashSet:   []common.Hash{common.HexToHash("0x0a")},
+					Signature: bytes.Repeat([]byte{0x0e}, 65),
+				},
+			},
+		},
+		&StorageContractRenewal{
+			OldContractID: common.HexToHash("0x07"),
+			NewContract:   contract,
+			Signatures:    signatures,
+		},
+	}
+}
+
+// StorageTxSchemas returns the RLP schemas of all types of storage contract transactions
+func StorageTxSchemas() (schemas []StorageTxSchema) {
+	for _, payload := range storageTxPayloads() {
+		typ := reflect.TypeOf(payload).Elem()
+		schemas = append(schemas, StorageTxSchema{
+			Name:   typ.Name(),
+			Fields: storageTxFields(typ),
+		})
+	}
+	return
+}
+
+// StorageTxTestVectors returns the canonical encodings of the sample payloads of all
+// types of storage contract transactions
+func StorageTxTestVectors() (vectors []StorageTxTestVector, err error) {
+	for _, payload := range storageTxPayloads() {
+		encoding, err := rlp.EncodeToBytes(payload)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, StorageTxTestVector{
+			Name:     reflect.TypeOf(payload).Elem().Name(),
+			Encoding: encoding,
+		})
+	}
+	return
+}
+
+// storageTxFields describes the RLP encoding of the fields of the struct type
+func storageTxFields(typ reflect.Type) (fields []StorageTxField) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		fieldType, nested := storageTxFieldType(f.Type)
+		fields = append(fields, StorageTxField{
+			Name:   f.Name,
+			Type:   fieldType,
+			Fields: nested,
+		})
+	}
+	return
+}
+
+// storageTxFieldType returns the RLP type description of the type. For the struct
+// types, the fields of the struct are returned as well
+func storageTxFieldType(typ reflect.Type) (string, []StorageTxField) {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == bigIntType:
+		return "bigint", nil
+	case typ == hashType:
+		return "bytes32", nil
+	case typ == addressType:
+		return "bytes20", nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8, reflect.Uint:
+		return "uint", nil
+	case reflect.Bool:
+		return "bool", nil
+	case reflect.String:
+		return "string", nil
+	case reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("bytes%d", typ.Len()), nil
+		}
+		elem, nested := storageTxFieldType(typ.Elem())
+		return fmt.Sprintf("list[%d]<%s>", typ.Len(), elem), nested
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return "bytes", nil
+		}
+		elem, nested := storageTxFieldType(typ.Elem())
+		return fmt.Sprintf("list<%s>", elem), nested
+	case reflect.Struct:
+		return typ.Name(), storageTxFields(typ)
+	default:
+		return typ.String(), nil
+	}
+}
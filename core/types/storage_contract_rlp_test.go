@@ -0,0 +1,99 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package types
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/rlp"
+)
+
+// TestStorageTxSchemaUnchanged checks the schema and the test vectors against the checked in
+// test data. If the encoding is changed deliberately, regenerate the test data with go generate
+func TestStorageTxSchemaUnchanged(t *testing.T) {
+	blob, err := ioutil.ReadFile("testdata/storagetx_schema.json")
+	if err != nil {
+		t.Fatalf("failed to read the schema: %v", err)
+	}
+	var expected struct {
+		Schemas []StorageTxSchema     `json:"schemas"`
+		Vectors []StorageTxTestVector `json:"vectors"`
+	}
+	if err := json.Unmarshal(blob, &expected); err != nil {
+		t.Fatalf("failed to decode the schema: %v", err)
+	}
+
+	if schemas := StorageTxSchemas(); !reflect.DeepEqual(schemas, expected.Schemas) {
+		t.Errorf("storage tx schema changed, got %+v", schemas)
+	}
+	vectors, err := StorageTxTestVectors()
+	if err != nil {
+		t.Fatalf("failed to generate the test vectors: %v", err)
+	}
+	if !reflect.DeepEqual(vectors, expected.Vectors) {
+		t.Errorf("storage tx encoding changed")
+	}
+
+	// the test vectors must be accepted by the strict decoding
+	for i, payload := range storageTxPayloads() {
+		decoded := reflect.New(reflect.TypeOf(payload).Elem()).Interface()
+		if err := DecodeStorageTxStrict(expected.Vectors[i].Encoding, decoded); err != nil {
+			t.Errorf("%v: failed to decode the test vector: %v", expected.Vectors[i].Name, err)
+		}
+	}
+}
+
+func TestDecodeStorageTxStrict(t *testing.T) {
+	sp := StorageProof{
+		ParentID:  common.HexToHash("0x01"),
+		HashSet:   []common.Hash{common.HexToHash("0x02")},
+		Signature: []byte{0x03},
+	}
+	encoded, err := rlp.EncodeToBytes(sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded StorageProof
+	if err := DecodeStorageTxStrict(encoded, &decoded); err != nil {
+		t.Fatalf("failed to decode the canonical encoding: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, sp) {
+		t.Errorf("decoded storage proof not match: expected %+v, got %+v", sp, decoded)
+	}
+
+	// trailing bytes
+	if err := DecodeStorageTxStrict(append(encoded, 0x80), &decoded); err == nil {
+		t.Error("expected error for the trailing bytes")
+	}
+
+	// unknown list shape with an extra element
+	extra, err := rlp.EncodeToBytes([]interface{}{sp.ParentID, sp.Segment, sp.HashSet, sp.Signature, uint64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DecodeStorageTxStrict(extra, &decoded); err == nil {
+		t.Error("expected error for the extra list element")
+	}
+
+	// payload of another storage tx type
+	ha := HostAnnouncement{NetAddress: "enode", Signature: []byte{0x01}}
+	encoded, _ = rlp.EncodeToBytes(ha)
+	var revision StorageContractRevision
+	if err := DecodeStorageTxStrict(encoded, &revision); err == nil {
+		t.Error("expected error for the mismatched list shape")
+	}
+
+	// single byte integer encoded as string
+	nonCanonical := []byte{0xc3, 0xc0, 0x81, 0x05}
+	var uc UnlockConditions
+	if err := DecodeStorageTxStrict(nonCanonical, &uc); err == nil {
+		t.Error("expected error for the non-canonical encoding")
+	}
+}
@@ -42,14 +42,30 @@ const (
 	CommitRevisionTransaction = "CommitRevision"
 	//StorageProofTransaction host storage proof  transaction tag
 	StorageProofTransaction = "StorageProof"
+	//EscrowFundTransaction treasury escrow fund transaction tag
+	EscrowFundTransaction = "EscrowFund"
+	//HostRevokeTransaction host announcement revocation transaction tag
+	HostRevokeTransaction = "HostRevoke"
+	//BatchStorageProofTransaction host batch storage proof transaction tag
+	BatchStorageProofTransaction = "BatchStorageProof"
+	//ContractRenewTransaction client and host contract renew transaction tag
+	ContractRenewTransaction = "ContractRenew"
 )
 
-//PrecompiledEVMFileContracts currently contains the transaction types required for four storage contracts
+//PrecompiledEVMFileContracts currently contains the transaction types required for four storage contracts,
+//the escrow fund transaction funding the storage contracts of the renters from a treasury account,
+//the host revoke transaction retracting the host announcement, the batch storage proof
+//transaction submitting the storage proofs of multiple contracts at once, and the contract
+//renew transaction replacing a storage contract with its successor
 var PrecompiledEVMFileContracts = map[common.Address]string{
 	common.BytesToAddress([]byte{9}):  HostAnnounceTransaction,
 	common.BytesToAddress([]byte{10}): ContractCreateTransaction,
 	common.BytesToAddress([]byte{11}): CommitRevisionTransaction,
 	common.BytesToAddress([]byte{12}): StorageProofTransaction,
+	common.BytesToAddress([]byte{13}): EscrowFundTransaction,
+	common.BytesToAddress([]byte{15}): HostRevokeTransaction,
+	common.BytesToAddress([]byte{16}): BatchStorageProofTransaction,
+	common.BytesToAddress([]byte{17}): ContractRenewTransaction,
 }
 
 type PrecompiledContract interface {
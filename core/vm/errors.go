@@ -27,4 +27,6 @@ var (
 	ErrInsufficientBalance      = errors.New("insufficient balance for transfer")
 	ErrContractAddressCollision = errors.New("contract address collision")
 	ErrNoCompatibleInterpreter  = errors.New("no compatible interpreter")
+	ErrExecutionReverted        = errors.New("evm: execution reverted")
+	ErrExecutionTimeout         = errors.New("evm: execution timeout")
 )
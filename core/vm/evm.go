@@ -19,6 +19,7 @@ package vm
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"math/big"
 	"strconv"
 	"sync/atomic"
@@ -39,6 +40,10 @@ var (
 	emptyCodeHash = crypto.Keccak256Hash(nil)
 
 	errUnknownStorageContractTx = errors.New("unknown storage contract tx")
+
+	// ErrStorageContractNotActivated is returned if the storage contract tx is applied before
+	// the storage contract fork block
+	ErrStorageContractNotActivated = errors.New("storage contract tx is not activated")
 )
 
 type (
@@ -61,6 +66,9 @@ func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, err
 		if p := precompiles[*contract.CodeAddr]; p != nil {
 			return RunPrecompiledContract(p, input, contract)
 		}
+		if p := evm.storageContractPrecompile(*contract.CodeAddr); p != nil {
+			return RunPrecompiledContract(p, input, contract)
+		}
 	}
 	for _, interpreter := range evm.interpreters {
 		if interpreter.CanRun(contract.Code) {
@@ -99,6 +107,9 @@ type Context struct {
 	BlockNumber *big.Int       // Provides information for NUMBER
 	Time        *big.Int       // Provides information for TIME
 	Difficulty  *big.Int       // Provides information for DIFFICULTY
+
+	// StorageContractStore provides the chain data for the storage contract transactions
+	StorageContractStore StorageContractStore
 }
 
 // EVM is the Ethereum Virtual Machine base object and provides
@@ -122,6 +133,9 @@ type EVM struct {
 	chainConfig *params.ChainConfig
 	// chain rules contains the chain rules for the current epoch
 	chainRules params.Rules
+	// callCreateDepth and maxCodeSize are the limits of the chain at the current block
+	callCreateDepth int
+	maxCodeSize     int
 	// virtual machine configuration options used to initialise the
 	// evm.
 	vmConfig Config
@@ -132,10 +146,19 @@ type EVM struct {
 	// abort is used to abort the EVM calling operations
 	// NOTE: must be set atomically
 	abort int32
+	// timedOut is set once the execution exceeds vmConfig.Timeout
+	// NOTE: must be set atomically
+	timedOut int32
+	// timeoutTimer cancels the execution once vmConfig.Timeout is exceeded, nil if
+	// there is no timeout
+	timeoutTimer *time.Timer
 	// callGasTemp holds the gas available for the current call. This is needed because the
 	// available gas is calculated in gasCall* according to the 63/64 rule and later
 	// applied in opCall*.
 	callGasTemp uint64
+	// storageTrace is the trace of the storage contract transaction being executed, nil
+	// if the tracer does not capture the storage contract transactions
+	storageTrace *storageTxTrace
 }
 
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
@@ -148,29 +171,33 @@ func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, vmCon
 		chainConfig:  chainConfig,
 		chainRules:   chainConfig.Rules(ctx.BlockNumber),
 		interpreters: make([]Interpreter, 0, 1),
+
+		callCreateDepth: int(chainConfig.CallCreateDepth(ctx.BlockNumber)),
+		maxCodeSize:     int(chainConfig.MaxCodeSize(ctx.BlockNumber)),
 	}
 
 	if chainConfig.IsEWASM(ctx.BlockNumber) {
-		// to be implemented by EVM-C and Wagon PRs.
-		// if vmConfig.EWASMInterpreter != "" {
-		//  extIntOpts := strings.Split(vmConfig.EWASMInterpreter, ":")
-		//  path := extIntOpts[0]
-		//  options := []string{}
-		//  if len(extIntOpts) > 1 {
-		//    options = extIntOpts[1..]
-		//  }
-		//  evm.interpreters = append(evm.interpreters, NewEVMVCInterpreter(evm, vmConfig, options))
-		// } else {
-		// 	evm.interpreters = append(evm.interpreters, NewEWASMInterpreter(evm, vmConfig))
-		// }
-		panic("No supported ewasm interpreter yet.")
-	}
-
-	// vmConfig.EVMInterpreter will be used by EVM-C, it won't be checked here
-	// as we always want to have the built-in EVM as the failover option.
+		interpreter := newRegisteredInterpreter(evm, vmConfig, vmConfig.EWASMInterpreter)
+		if interpreter == nil {
+			panic(fmt.Sprintf("No supported ewasm interpreter registered for %q", vmConfig.EWASMInterpreter))
+		}
+		evm.interpreters = append(evm.interpreters, interpreter)
+	}
+
+	// the interpreter registered for vmConfig.EVMInterpreter is tried first, and the
+	// built-in EVM is always kept as the failover option
+	if interpreter := newRegisteredInterpreter(evm, vmConfig, vmConfig.EVMInterpreter); interpreter != nil {
+		evm.interpreters = append(evm.interpreters, interpreter)
+	}
 	evm.interpreters = append(evm.interpreters, NewEVMInterpreter(evm, vmConfig))
 	evm.interpreter = evm.interpreters[0]
 
+	if vmConfig.Timeout > 0 {
+		evm.timeoutTimer = time.AfterFunc(vmConfig.Timeout, func() {
+			atomic.StoreInt32(&evm.timedOut, 1)
+			evm.Cancel()
+		})
+	}
 	return evm
 }
 
@@ -180,6 +207,19 @@ func (evm *EVM) Cancel() {
 	atomic.StoreInt32(&evm.abort, 1)
 }
 
+// Stop releases the timer of vmConfig.Timeout. It must be called once the execution
+// returns, so that the timer is not left running after the EVM is done
+func (evm *EVM) Stop() {
+	if evm.timeoutTimer != nil {
+		evm.timeoutTimer.Stop()
+	}
+}
+
+// TimedOut returns whether the execution is cancelled for exceeding vmConfig.Timeout
+func (evm *EVM) TimedOut() bool {
+	return atomic.LoadInt32(&evm.timedOut) == 1
+}
+
 // Interpreter returns the current interpreter
 func (evm *EVM) Interpreter() Interpreter {
 	return evm.interpreter
@@ -195,7 +235,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	}
 
 	// Fail if we're trying to execute above the call depth limit
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depth > evm.callCreateDepth {
 		return nil, gas, ErrDepth
 	}
 	// Fail if we're trying to transfer more than the available balance
@@ -212,11 +252,16 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 		if evm.ChainConfig().IsByzantium(evm.BlockNumber) {
 			precompiles = PrecompiledContractsByzantium
 		}
-		if precompiles[addr] == nil && evm.ChainConfig().IsEIP158(evm.BlockNumber) && value.Sign() == 0 {
+		if precompiles[addr] == nil && evm.storageContractPrecompile(addr) == nil && evm.ChainConfig().IsEIP158(evm.BlockNumber) && value.Sign() == 0 {
 			// Calling a non existing account, don't do anything, but ping the tracer
-			if evm.vmConfig.Debug && evm.depth == 0 {
-				evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
-				evm.vmConfig.Tracer.CaptureEnd(ret, 0, 0, nil)
+			if evm.vmConfig.Debug {
+				if evm.depth == 0 {
+					evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
+					evm.vmConfig.Tracer.CaptureEnd(ret, 0, 0, nil)
+				} else {
+					evm.vmConfig.Tracer.CaptureEnter(CALL, caller.Address(), addr, input, gas, value)
+					evm.vmConfig.Tracer.CaptureExit(ret, 0, nil)
+				}
 			}
 			return nil, gas, nil
 		}
@@ -231,13 +276,22 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	// Even if the account has no code, we need to continue because it might be a precompile
 	start := time.Now()
 
-	// Capture the tracer start/end events in debug mode
-	if evm.vmConfig.Debug && evm.depth == 0 {
-		evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
+	// Capture the tracer start/end events in debug mode, or the enter/exit events
+	// of the nested call frame
+	if evm.vmConfig.Debug {
+		if evm.depth == 0 {
+			evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
 
-		defer func() { // Lazy evaluation of the parameters
-			evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
-		}()
+			defer func() { // Lazy evaluation of the parameters
+				evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
+			}()
+		} else {
+			evm.vmConfig.Tracer.CaptureEnter(CALL, caller.Address(), addr, input, gas, value)
+
+			defer func() {
+				evm.vmConfig.Tracer.CaptureExit(ret, gas-contract.Gas, err)
+			}()
+		}
 	}
 	ret, err = run(evm, contract, input, false)
 
@@ -246,7 +300,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	// when we're in homestead this also counts for code storage gas errors.
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -266,7 +320,7 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	}
 
 	// Fail if we're trying to execute above the call depth limit
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depth > evm.callCreateDepth {
 		return nil, gas, ErrDepth
 	}
 	// Fail if we're trying to transfer more than the available balance
@@ -284,10 +338,18 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	contract := NewContract(caller, to, value, gas)
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
+	// Capture the tracer enter/exit events of the nested call frame in debug mode
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(CALLCODE, caller.Address(), addr, input, gas, value)
+		defer func() {
+			evm.vmConfig.Tracer.CaptureExit(ret, gas-contract.Gas, err)
+		}()
+	}
+
 	ret, err = run(evm, contract, input, false)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -304,7 +366,7 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 		return nil, gas, nil
 	}
 	// Fail if we're trying to execute above the call depth limit
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depth > evm.callCreateDepth {
 		return nil, gas, ErrDepth
 	}
 
@@ -317,10 +379,18 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	contract := NewContract(caller, to, nil, gas).AsDelegate()
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
+	// Capture the tracer enter/exit events of the nested call frame in debug mode
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(DELEGATECALL, caller.Address(), addr, input, gas, nil)
+		defer func() {
+			evm.vmConfig.Tracer.CaptureExit(ret, gas-contract.Gas, err)
+		}()
+	}
+
 	ret, err = run(evm, contract, input, false)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -336,7 +406,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 		return nil, gas, nil
 	}
 	// Fail if we're trying to execute above the call depth limit
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depth > evm.callCreateDepth {
 		return nil, gas, ErrDepth
 	}
 
@@ -356,13 +426,21 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	// future scenarios
 	evm.StateDB.AddBalance(addr, bigZero)
 
+	// Capture the tracer enter/exit events of the nested call frame in debug mode
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(STATICCALL, caller.Address(), addr, input, gas, nil)
+		defer func() {
+			evm.vmConfig.Tracer.CaptureExit(ret, gas-contract.Gas, err)
+		}()
+	}
+
 	// When an error was returned by the EVM or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
 	// when we're in Homestead this also counts for code storage gas errors.
 	ret, err = run(evm, contract, input, true)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -381,11 +459,12 @@ func (c *codeAndHash) Hash() common.Hash {
 	return c.hash
 }
 
-// create creates a new contract using code as deployment code.
-func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64, value *big.Int, address common.Address) ([]byte, common.Address, uint64, error) {
+// create creates a new contract using code as deployment code. The type is either CREATE
+// or CREATE2, which is reported to the tracer for the nested call frame.
+func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64, value *big.Int, address common.Address, typ OpCode) ([]byte, common.Address, uint64, error) {
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depth > evm.callCreateDepth {
 		return nil, common.Address{}, gas, ErrDepth
 	}
 	if !evm.CanTransfer(evm.StateDB, caller.Address(), value) {
@@ -417,15 +496,19 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 		return nil, address, gas, nil
 	}
 
-	if evm.vmConfig.Debug && evm.depth == 0 {
-		evm.vmConfig.Tracer.CaptureStart(caller.Address(), address, true, codeAndHash.code, gas, value)
+	if evm.vmConfig.Debug {
+		if evm.depth == 0 {
+			evm.vmConfig.Tracer.CaptureStart(caller.Address(), address, true, codeAndHash.code, gas, value)
+		} else {
+			evm.vmConfig.Tracer.CaptureEnter(typ, caller.Address(), address, codeAndHash.code, gas, value)
+		}
 	}
 	start := time.Now()
 
 	ret, err := run(evm, contract, nil, false)
 
 	// check whether the max code size has been exceeded
-	maxCodeSizeExceeded := evm.ChainConfig().IsEIP158(evm.BlockNumber) && len(ret) > params.MaxCodeSize
+	maxCodeSizeExceeded := evm.ChainConfig().IsEIP158(evm.BlockNumber) && len(ret) > evm.maxCodeSize
 	// if the contract creation ran successfully and no errors were returned
 	// calculate the gas required to store the code. If the code could not
 	// be stored due to not enough gas set an error and let it be handled
@@ -444,7 +527,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	// when we're in homestead this also counts for code storage gas errors.
 	if maxCodeSizeExceeded || (err != nil && (evm.ChainConfig().IsHomestead(evm.BlockNumber) || err != ErrCodeStoreOutOfGas)) {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -452,8 +535,12 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	if maxCodeSizeExceeded && err == nil {
 		err = errMaxCodeSizeExceeded
 	}
-	if evm.vmConfig.Debug && evm.depth == 0 {
-		evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
+	if evm.vmConfig.Debug {
+		if evm.depth == 0 {
+			evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
+		} else {
+			evm.vmConfig.Tracer.CaptureExit(ret, gas-contract.Gas, err)
+		}
 	}
 	return ret, address, contract.Gas, err
 
@@ -462,7 +549,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 // Create creates a new contract using code as deployment code.
 func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 	contractAddr = crypto.CreateAddress(caller.Address(), evm.StateDB.GetNonce(caller.Address()))
-	return evm.create(caller, &codeAndHash{code: code}, gas, value, contractAddr)
+	return evm.create(caller, &codeAndHash{code: code}, gas, value, contractAddr, CREATE)
 }
 
 // Create2 creates a new contract using code as deployment code.
@@ -472,14 +559,86 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *big.Int, salt *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 	codeAndHash := &codeAndHash{code: code}
 	contractAddr = crypto.CreateAddress2(caller.Address(), common.BigToHash(salt), codeAndHash.Hash().Bytes())
-	return evm.create(caller, codeAndHash, gas, endowment, contractAddr)
+	return evm.create(caller, codeAndHash, gas, endowment, contractAddr, CREATE2)
 }
 
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
 
-// ApplyStorageContractTransaction distinguish and execute transactions
+// StorageContractTxType returns the storage contract transaction type of the precompiled
+// address. The transaction types added after the storage contract fork are only routed from
+// their own fork blocks, before which the transaction is a plain call to the address
+func (evm *EVM) StorageContractTxType(addr common.Address) (string, bool) {
+	txType, ok := PrecompiledEVMFileContracts[addr]
+	if !ok {
+		return "", false
+	}
+	switch txType {
+	case EscrowFundTransaction:
+		return txType, evm.chainRules.IsEscrowFund
+	case HostRevokeTransaction:
+		return txType, evm.chainRules.IsHostRevoke
+	case BatchStorageProofTransaction:
+		return txType, evm.chainRules.IsBatchProof
+	case ContractRenewTransaction:
+		return txType, evm.chainRules.IsContractRenew
+	default:
+		return txType, true
+	}
+}
+
+// ApplyStorageContractTransaction distinguish and execute transactions. From the storage revert
+// fork, the state changes made by the failed transaction are reverted, the same as the failed
+// contract calls, so that the partial writes are never committed together with the block
 func (evm *EVM) ApplyStorageContractTransaction(caller ContractRef, txType string, data []byte, gas uint64) (ret []byte, leftOverGas uint64, err error) {
+	if !evm.chainRules.IsStorageContract {
+		return nil, gas, ErrStorageContractNotActivated
+	}
+	snapshot := evm.StateDB.Snapshot()
+	start := time.Now()
+	evm.startStorageTxTrace(txType, gas)
+
+	ret, leftOverGas, err = evm.runStorageContractTransaction(caller, txType, data, gas)
+	if err == errUnknownStorageContractTx {
+		evm.endStorageTxTrace(gas, err)
+		return nil, gas, err
+	}
+
+	if err != nil && evm.chainRules.IsStorageRevert {
+		evm.StateDB.RevertToSnapshot(snapshot)
+	}
+	evm.endStorageTxTrace(leftOverGas, err)
+	recordStorageTx(txType, start, gas-leftOverGas, err)
+	return
+}
+
+// SimulateStorageContractTransaction validates the storage contract transaction against the
+// current state without writing anything. The state changes are always reverted, and neither
+// the trace nor the metrics are recorded. The gas the transaction would consume is returned
+// together with the validation error, so that the transaction failing can be caught before
+// it is broadcast
+func (evm *EVM) SimulateStorageContractTransaction(caller ContractRef, txType string, data []byte, gas uint64) (gasUsed uint64, err error) {
+	if !evm.chainRules.IsStorageContract {
+		return 0, ErrStorageContractNotActivated
+	}
+	snapshot := evm.StateDB.Snapshot()
+	defer evm.StateDB.RevertToSnapshot(snapshot)
+
+	_, leftOverGas, err := evm.runStorageContractTransaction(caller, txType, data, gas)
+	return gas - leftOverGas, err
+}
+
+// decodeStorageTx decodes the storage contract transaction payload. From the storage strict
+// decoding fork, the payload must be the canonical rlp encoding of the decoded value
+func (evm *EVM) decodeStorageTx(b []byte, val interface{}) error {
+	if evm.chainRules.IsStorageStrictDecode {
+		return types.DecodeStorageTxStrict(b, val)
+	}
+	return rlp.DecodeBytes(b, val)
+}
+
+// runStorageContractTransaction dispatches the storage contract transaction to its handler
+func (evm *EVM) runStorageContractTransaction(caller ContractRef, txType string, data []byte, gas uint64) ([]byte, uint64, error) {
 	switch txType {
 	case HostAnnounceTransaction:
 		return evm.HostAnnounceTx(caller, data, gas)
@@ -489,6 +648,14 @@ func (evm *EVM) ApplyStorageContractTransaction(caller ContractRef, txType strin
 		return evm.CommitRevisionTx(caller, data, gas)
 	case StorageProofTransaction:
 		return evm.StorageProofTx(caller, data, gas)
+	case EscrowFundTransaction:
+		return evm.EscrowFundTx(caller, data, gas)
+	case HostRevokeTransaction:
+		return evm.HostRevokeTx(caller, data, gas)
+	case BatchStorageProofTransaction:
+		return evm.BatchStorageProofTx(caller, data, gas)
+	case ContractRenewTransaction:
+		return evm.RenewContractTx(caller, data, gas)
 	default:
 		return nil, gas, errUnknownStorageContractTx
 	}
@@ -499,124 +666,297 @@ func (evm *EVM) HostAnnounceTx(caller ContractRef, data []byte, gas uint64) ([]b
 	log.Info("enter host announce tx executing ... ")
 
 	ha := types.HostAnnouncement{}
-	gasDecode, resultDecode := RemainGas(gas, rlp.DecodeBytes, data, &ha)
+	gasDecode, resultDecode := RemainGas(gas, evm.decodeStorageTx, data, &ha)
 	errDec, _ := resultDecode[0].(error)
+	evm.traceStorageTxStep("decode", gasDecode, ha, errDec)
 	if errDec != nil {
 		return nil, gasDecode, errDec
 	}
 
 	gasCheck, resultCheck := RemainGas(gasDecode, CheckMultiSignatures, ha, [][]byte{ha.Signature})
 	errCheck, _ := resultCheck[0].(error)
+	evm.traceStorageTxStep("checkSignature", gasCheck, nil, errCheck)
 	if errCheck != nil {
 		log.Error("failed to check signature for host announce", "err", errCheck)
 		return nil, gasCheck, errCheck
 	}
 
+	// record the announcement in the announced host registry, rejecting the duplicates
+	if evm.ChainConfig().IsHostRegistry(evm.BlockNumber) {
+		hostID, err := hostNodeID(ha.NetAddress)
+		if err == nil {
+			err = CheckHostRegistry(evm.StateDB, hostID, ha, evm.BlockNumber.Uint64())
+		}
+		if err == nil {
+			err = registerHostAnnouncement(evm.StateDB, hostID, ha, evm.BlockNumber.Uint64())
+		}
+		evm.traceStorageTxStep("register", gasCheck, nil, err)
+		if err != nil {
+			log.Error("failed to register host announce", "err", err)
+			return nil, gasCheck, err
+		}
+	}
+
 	log.Info("host announce tx execution done", "remain_gas", gasCheck, "host_address", ha.NetAddress)
 
 	// return remain gas if everything is ok
 	return nil, gasCheck, nil
 }
 
-// CreateContractTx executes contract creation tx
+// HostRevokeTx host retracts its announcement on the chain. The revocation must be signed by
+// the host node announced, and be sent within HostRevocationValidity blocks after the block
+// number signed
+func (evm *EVM) HostRevokeTx(caller ContractRef, data []byte, gas uint64) ([]byte, uint64, error) {
+	log.Info("enter host revoke tx executing ... ")
+
+	hr := types.HostRevocation{}
+	gasDecode, resultDecode := RemainGas(gas, evm.decodeStorageTx, data, &hr)
+	errDec, _ := resultDecode[0].(error)
+	evm.traceStorageTxStep("decode", gasDecode, hr, errDec)
+	if errDec != nil {
+		return nil, gasDecode, errDec
+	}
+
+	if err := CheckHostRevocation(hr, evm.BlockNumber.Uint64()); err != nil {
+		evm.traceStorageTxStep("checkBlockNumber", gasDecode, nil, err)
+		return nil, gasDecode, err
+	}
+
+	gasCheck, resultCheck := RemainGas(gasDecode, CheckMultiSignatures, hr, [][]byte{hr.Signature})
+	errCheck, _ := resultCheck[0].(error)
+	evm.traceStorageTxStep("checkSignature", gasCheck, nil, errCheck)
+	if errCheck != nil {
+		log.Error("failed to check signature for host revoke", "err", errCheck)
+		return nil, gasCheck, errCheck
+	}
+
+	// remove the announcement from the announced host registry
+	if evm.ChainConfig().IsHostRegistry(evm.BlockNumber) {
+		hostID, err := hostNodeID(hr.NetAddress)
+		evm.traceStorageTxStep("unregister", gasCheck, nil, err)
+		if err != nil {
+			return nil, gasCheck, err
+		}
+		unregisterHostAnnouncement(evm.StateDB, hostID)
+	}
+
+	log.Info("host revoke tx execution done", "remain_gas", gasCheck, "host_address", hr.NetAddress)
+	return nil, gasCheck, nil
+}
+
+// CreateContractTx executes contract creation tx. The execution is staged: the contract is
+// validated first, then the state changes are staged in the journal, and committed at last.
+// Nothing is written before the commit, and the commit rolls back all changes if failed
 func (evm *EVM) CreateContractTx(caller ContractRef, data []byte, gas uint64) ([]byte, uint64, error) {
 	log.Info("enter create contract tx executing ... ")
-	var (
-		state    = evm.StateDB
-		snapshot = state.Snapshot()
-	)
+	state := evm.StateDB
 
 	// rlp decode and calculate gas used
 	sc := types.StorageContract{}
-	gasRemainDecode, resultDecode := RemainGas(gas, rlp.DecodeBytes, data, &sc)
+	gasRemainDecode, resultDecode := RemainGas(gas, evm.decodeStorageTx, data, &sc)
 	errDecode, _ := resultDecode[0].(error)
+	evm.traceStorageTxStep("decode", gasRemainDecode, sc, errDecode)
 	if errDecode != nil {
 		return nil, gasRemainDecode, errDecode
 	}
 
-	// create the expired storage contract status address (e.g. "expired_storage_contract_1500")
-	windowEndStr := strconv.FormatUint(sc.WindowEnd, 10)
-	statusAddr := common.BytesToAddress([]byte(coinchargemaintenance.StrPrefixExpSC + windowEndStr))
-
 	// create storage contract address, directly use the contract ID
 	scID := sc.ID()
 	contractAddr := common.BytesToAddress(scID[12:])
 
-	// if the account not exist, create it
-	if !state.Exist(statusAddr) {
-		state.CreateAccount(statusAddr)
-
-		// before reaching the height windowEnd, mark statusAddr as not empty account to avoid being deleted by stateDB
-		state.SetNonce(statusAddr, 1)
-	}
-
-	// check if this storage contract exist
+	// check if this storage contract exist. Before the storage atomic fork, the status account
+	// is created ahead of the check, and is kept unless the failed tx is reverted
 	if state.Exist(contractAddr) {
-		return nil, gasRemainDecode, errors.New("this storage contract already exist")
+		if !evm.chainRules.IsStorageAtomic {
+			statusAddr := common.BytesToAddress([]byte(coinchargemaintenance.StrPrefixExpSC + strconv.FormatUint(sc.WindowEnd, 10)))
+			if !state.Exist(statusAddr) {
+				state.CreateAccount(statusAddr)
+				state.SetNonce(statusAddr, 1)
+			}
+		}
+		err := errors.New("this storage contract already exist")
+		evm.traceStorageTxStep("checkContract", gasRemainDecode, nil, err)
+		return nil, gasRemainDecode, err
 	}
-	state.CreateAccount(contractAddr)
-
-	// before this contract finished, mark contractAddr as not empty account to avoid being deleted by stateDB
-	state.SetNonce(contractAddr, 1)
 
 	// check form contract and calculate gas used
 	currentHeight := evm.BlockNumber.Uint64()
-	gasRemainCheck, resultCheck := RemainGas(gasRemainDecode, CheckCreateContract, state, sc, uint64(currentHeight))
+	gasRemainCheck, resultCheck := RemainGas(gasRemainDecode, evm.checkCreateContract, state, sc, uint64(currentHeight))
 	errCheck, _ := resultCheck[0].(error)
+	evm.traceStorageTxStep("checkContract", gasRemainCheck, nil, errCheck)
 	if errCheck != nil {
-		state.RevertToSnapshot(snapshot)
 		log.Error("failed to check create contract", "err", errCheck)
 		return nil, gasRemainCheck, errCheck
 	}
 
-	// set balances
+	journal := &storageTxJournal{checkDebits: evm.chainRules.IsStorageAtomic}
+	stageCreateContract(journal, state, sc, evm.chainRules)
+	evm.traceStorageTxStep("stage", gasRemainCheck, nil, nil)
+
+	err := journal.commit(state)
+	evm.traceStorageTxStep("commit", gasRemainCheck, nil, err)
+	if err != nil {
+		log.Error("failed to commit create contract", "err", err)
+		return nil, gasRemainCheck, err
+	}
+	evm.emitContractCreated(sc)
+
+	// return remain gas if everything is ok
+	log.Info("create contract tx execution done", "remain_gas", gasRemainCheck, "storage_contract_id", scID.Hex())
+	return nil, gasRemainCheck, nil
+}
+
+// checkCreateContract checks the new StorageContract with the rules of the current block
+func (evm *EVM) checkCreateContract(state StateDB, sc types.StorageContract, currentHeight uint64) error {
+	return CheckCreateContract(state, sc, currentHeight, evm.chainRules)
+}
+
+// checkRenewContract checks the StorageContractRenewal with the rules of the current block
+func (evm *EVM) checkRenewContract(state StateDB, renewal types.StorageContractRenewal, currentHeight uint64) error {
+	return CheckRenewContract(state, renewal, currentHeight, evm.chainRules)
+}
+
+// checkRevisionContract checks the StorageContractRevision with the rules of the current block
+func (evm *EVM) checkRevisionContract(state StateDB, scr types.StorageContractRevision, currentHeight uint64, contractAddr common.Address) error {
+	return CheckRevisionContract(state, scr, currentHeight, contractAddr, evm.chainRules)
+}
+
+// stageCreateContract stages the state changes creating the storage contract validated
+func stageCreateContract(journal *storageTxJournal, state StateDB, sc types.StorageContract, rules params.Rules) {
+	// create the expired storage contract status address (e.g. "expired_storage_contract_1500"),
+	// which is kept not empty before reaching the height windowEnd
+	windowEndStr := strconv.FormatUint(sc.WindowEnd, 10)
+	statusAddr := common.BytesToAddress([]byte(coinchargemaintenance.StrPrefixExpSC + windowEndStr))
+	journal.createAccount(statusAddr)
+
+	// the contract account is kept not empty before this contract finished
+	scID := sc.ID()
+	contractAddr := common.BytesToAddress(scID[12:])
+	journal.createAccount(contractAddr)
+
+	// move the collaterals to the contract account
 	clientAddr := sc.ClientCollateral.Address
 	hostAddr := sc.HostCollateral.Address
 	clientCollateralAmount := sc.ClientCollateral.Value
 	hostCollateralAmount := sc.HostCollateral.Value
-	state.SubBalance(clientAddr, clientCollateralAmount)
-	state.SubBalance(hostAddr, hostCollateralAmount)
+	journal.subBalance(clientAddr, clientCollateralAmount)
+	journal.subBalance(hostAddr, hostCollateralAmount)
 
 	totalCollateral := new(big.Int).Add(clientCollateralAmount, hostCollateralAmount)
-	state.AddBalance(contractAddr, totalCollateral)
+	journal.addBalance(contractAddr, totalCollateral)
+
+	// record the collateral spent from the escrow account within the spending cap
+	if rules.IsEscrowFund && isEscrowAccount(state, clientAddr) {
+		spent := new(big.Int).SetBytes(state.GetState(clientAddr, coinchargemaintenance.KeyEscrowSpent).Bytes())
+		spent.Add(spent, clientCollateralAmount)
+		journal.setState(clientAddr, coinchargemaintenance.KeyEscrowSpent, common.BigToHash(spent))
+	}
 
 	// mark this new storage contract as not proofed
 	notProofedStatus := append(coinchargemaintenance.NotProofedStatus, contractAddr[:]...)
-	state.SetState(statusAddr, scID, common.BytesToHash(notProofedStatus))
+	journal.setState(statusAddr, scID, common.BytesToHash(notProofedStatus))
 
 	// store storage contract in this contractAddr's state
-	state.SetState(contractAddr, coinchargemaintenance.KeyClientAddress, common.BytesToHash(sc.ClientCollateral.Address.Bytes()))
-	state.SetState(contractAddr, coinchargemaintenance.KeyHostAddress, common.BytesToHash(sc.HostCollateral.Address.Bytes()))
+	journal.setState(contractAddr, coinchargemaintenance.KeyClientAddress, common.BytesToHash(sc.ClientCollateral.Address.Bytes()))
+	journal.setState(contractAddr, coinchargemaintenance.KeyHostAddress, common.BytesToHash(sc.HostCollateral.Address.Bytes()))
 
-	state.SetState(contractAddr, coinchargemaintenance.KeyClientCollateral, common.BytesToHash(sc.ClientCollateral.Value.Bytes()))
-	state.SetState(contractAddr, coinchargemaintenance.KeyHostCollateral, common.BytesToHash(sc.HostCollateral.Value.Bytes()))
+	journal.setState(contractAddr, coinchargemaintenance.KeyClientCollateral, common.BytesToHash(sc.ClientCollateral.Value.Bytes()))
+	journal.setState(contractAddr, coinchargemaintenance.KeyHostCollateral, common.BytesToHash(sc.HostCollateral.Value.Bytes()))
 
 	uintBytes := Uint64ToBytes(sc.FileSize)
-	state.SetState(contractAddr, coinchargemaintenance.KeyFileSize, common.BytesToHash(uintBytes))
+	journal.setState(contractAddr, coinchargemaintenance.KeyFileSize, common.BytesToHash(uintBytes))
 
-	state.SetState(contractAddr, coinchargemaintenance.KeyUnlockHash, sc.UnlockHash)
-	state.SetState(contractAddr, coinchargemaintenance.KeyFileMerkleRoot, sc.FileMerkleRoot)
+	journal.setState(contractAddr, coinchargemaintenance.KeyUnlockHash, sc.UnlockHash)
+	journal.setState(contractAddr, coinchargemaintenance.KeyFileMerkleRoot, sc.FileMerkleRoot)
 
 	uintBytes = Uint64ToBytes(sc.RevisionNumber)
-	state.SetState(contractAddr, coinchargemaintenance.KeyRevisionNumber, common.BytesToHash(uintBytes))
+	journal.setState(contractAddr, coinchargemaintenance.KeyRevisionNumber, common.BytesToHash(uintBytes))
 
 	uintBytes = Uint64ToBytes(sc.WindowStart)
-	state.SetState(contractAddr, coinchargemaintenance.KeyWindowStart, common.BytesToHash(uintBytes))
+	journal.setState(contractAddr, coinchargemaintenance.KeyWindowStart, common.BytesToHash(uintBytes))
 
 	uintBytes = Uint64ToBytes(sc.WindowEnd)
-	state.SetState(contractAddr, coinchargemaintenance.KeyWindowEnd, common.BytesToHash(uintBytes))
+	journal.setState(contractAddr, coinchargemaintenance.KeyWindowEnd, common.BytesToHash(uintBytes))
+
+	journal.setState(contractAddr, coinchargemaintenance.KeyClientValidProofOutput, common.BytesToHash(sc.ValidProofOutputs[0].Value.Bytes()))
+	journal.setState(contractAddr, coinchargemaintenance.KeyHostValidProofOutput, common.BytesToHash(sc.ValidProofOutputs[1].Value.Bytes()))
 
-	state.SetState(contractAddr, coinchargemaintenance.KeyClientValidProofOutput, common.BytesToHash(sc.ValidProofOutputs[0].Value.Bytes()))
-	state.SetState(contractAddr, coinchargemaintenance.KeyHostValidProofOutput, common.BytesToHash(sc.ValidProofOutputs[1].Value.Bytes()))
+	journal.setState(contractAddr, coinchargemaintenance.KeyClientMissedProofOutput, common.BytesToHash(sc.MissedProofOutputs[0].Value.Bytes()))
+	journal.setState(contractAddr, coinchargemaintenance.KeyHostMissedProofOutput, common.BytesToHash(sc.MissedProofOutputs[1].Value.Bytes()))
+}
 
-	state.SetState(contractAddr, coinchargemaintenance.KeyClientMissedProofOutput, common.BytesToHash(sc.MissedProofOutputs[0].Value.Bytes()))
-	state.SetState(contractAddr, coinchargemaintenance.KeyHostMissedProofOutput, common.BytesToHash(sc.MissedProofOutputs[1].Value.Bytes()))
+// RenewContractTx executes contract renew tx. The old contract is closed with the valid proof
+// outputs returned to the client and host, and the new contract is created with the collateral
+// paid from them. Both are staged in one journal, so the renewal is applied as a whole or not
+// at all, with the check charged once for the old and new contract together
+func (evm *EVM) RenewContractTx(caller ContractRef, data []byte, gas uint64) ([]byte, uint64, error) {
+	log.Info("enter renew contract tx executing ... ")
+	state := evm.StateDB
 
-	// return remain gas if everything is ok
-	log.Info("create contract tx execution done", "remain_gas", gasRemainCheck, "storage_contract_id", scID.Hex())
+	renewal := types.StorageContractRenewal{}
+	gasRemainDecode, resultDecode := RemainGas(gas, evm.decodeStorageTx, data, &renewal)
+	errDecode, _ := resultDecode[0].(error)
+	evm.traceStorageTxStep("decode", gasRemainDecode, renewal, errDecode)
+	if errDecode != nil {
+		return nil, gasRemainDecode, errDecode
+	}
+
+	scID := renewal.NewContract.ID()
+	if state.Exist(common.BytesToAddress(scID[12:])) {
+		err := errors.New("this storage contract already exist")
+		evm.traceStorageTxStep("checkRenewal", gasRemainDecode, nil, err)
+		return nil, gasRemainDecode, err
+	}
+
+	currentHeight := evm.BlockNumber.Uint64()
+	gasRemainCheck, resultCheck := RemainGas(gasRemainDecode, evm.checkRenewContract, state, renewal, uint64(currentHeight))
+	errCheck, _ := resultCheck[0].(error)
+	evm.traceStorageTxStep("checkRenewal", gasRemainCheck, nil, errCheck)
+	if errCheck != nil {
+		log.Error("failed to check renew contract", "err", errCheck)
+		return nil, gasRemainCheck, errCheck
+	}
+
+	journal := &storageTxJournal{checkDebits: evm.chainRules.IsStorageAtomic}
+	stageCloseContract(journal, state, renewal.OldContractID)
+	stageCreateContract(journal, state, renewal.NewContract, evm.chainRules)
+	evm.traceStorageTxStep("stage", gasRemainCheck, nil, nil)
+
+	err := journal.commit(state)
+	evm.traceStorageTxStep("commit", gasRemainCheck, nil, err)
+	if err != nil {
+		log.Error("failed to commit renew contract", "err", err)
+		return nil, gasRemainCheck, err
+	}
+	evm.emitContractRenewed(renewal.OldContractID, scID)
+	evm.emitContractCreated(renewal.NewContract)
+
+	log.Info("renew contract tx execution done", "remain_gas", gasRemainCheck, "old_storage_contract_id", renewal.OldContractID.Hex(), "storage_contract_id", scID.Hex())
 	return nil, gasRemainCheck, nil
 }
 
+// stageCloseContract stages the state changes closing the storage contract before its proof
+// window. The valid proof outputs are returned the same as the contract proofed
+func stageCloseContract(journal *storageTxJournal, state StateDB, scID common.Hash) {
+	contractAddr := common.BytesToAddress(scID[12:])
+	windowEnd := new(big.Int).SetBytes(state.GetState(contractAddr, coinchargemaintenance.KeyWindowEnd).Bytes()).Uint64()
+	statusAddr := common.BytesToAddress([]byte(coinchargemaintenance.StrPrefixExpSC + strconv.FormatUint(windowEnd, 10)))
+
+	clientAddr := common.BytesToAddress(state.GetState(contractAddr, coinchargemaintenance.KeyClientAddress).Bytes())
+	hostAddr := common.BytesToAddress(state.GetState(contractAddr, coinchargemaintenance.KeyHostAddress).Bytes())
+	clientValidOutput := new(big.Int).SetBytes(state.GetState(contractAddr, coinchargemaintenance.KeyClientValidProofOutput).Bytes())
+	hostValidOutput := new(big.Int).SetBytes(state.GetState(contractAddr, coinchargemaintenance.KeyHostValidProofOutput).Bytes())
+
+	journal.subBalance(contractAddr, new(big.Int).Add(clientValidOutput, hostValidOutput))
+	journal.addBalance(clientAddr, clientValidOutput)
+	journal.addBalance(hostAddr, hostValidOutput)
+
+	// mark the contract completed, and its account empty to be deleted by stateDB
+	proofedStatus := append(coinchargemaintenance.ProofedStatus, contractAddr[:]...)
+	journal.setState(statusAddr, scID, common.BytesToHash(proofedStatus))
+	journal.closeAccount(contractAddr)
+}
+
 // CommitRevisionTx host sends a revision transaction
 func (evm *EVM) CommitRevisionTx(caller ContractRef, data []byte, gas uint64) ([]byte, uint64, error) {
 	log.Info("enter storage contract revision tx executing ... ")
@@ -625,8 +965,9 @@ func (evm *EVM) CommitRevisionTx(caller ContractRef, data []byte, gas uint64) ([
 	)
 
 	scr := types.StorageContractRevision{}
-	gasRemainDecode, resultDecode := RemainGas(gas, rlp.DecodeBytes, data, &scr)
+	gasRemainDecode, resultDecode := RemainGas(gas, evm.decodeStorageTx, data, &scr)
 	errDec, _ := resultDecode[0].(error)
+	evm.traceStorageTxStep("decode", gasRemainDecode, scr, errDec)
 	if errDec != nil {
 		return nil, gasRemainDecode, errDec
 	}
@@ -639,8 +980,9 @@ func (evm *EVM) CommitRevisionTx(caller ContractRef, data []byte, gas uint64) ([
 
 	// check storage contract reversion and calculate gas used
 	currentHeight := evm.BlockNumber.Uint64()
-	gasRemainCheck, resultCheck := RemainGas(gasRemainDecode, CheckRevisionContract, state, scr, uint64(currentHeight), contractAddr)
+	gasRemainCheck, resultCheck := RemainGas(gasRemainDecode, evm.checkRevisionContract, state, scr, uint64(currentHeight), contractAddr)
 	errCheck, _ := resultCheck[0].(error)
+	evm.traceStorageTxStep("checkRevision", gasRemainCheck, nil, errCheck)
 	if errCheck != nil {
 		log.Error("failed to check storage contract revision", "err", errCheck)
 		return nil, gasRemainCheck, errCheck
@@ -660,7 +1002,9 @@ func (evm *EVM) CommitRevisionTx(caller ContractRef, data []byte, gas uint64) ([
 
 	state.SetState(contractAddr, coinchargemaintenance.KeyClientMissedProofOutput, common.BytesToHash(scr.NewMissedProofOutputs[0].Value.Bytes()))
 	state.SetState(contractAddr, coinchargemaintenance.KeyHostMissedProofOutput, common.BytesToHash(scr.NewMissedProofOutputs[1].Value.Bytes()))
+	evm.emitContractRevised(scr)
 
+	evm.traceStorageTxStep("apply", gasRemainCheck, nil, nil)
 	log.Info("storage contract reversion tx execution done", "remain_gas", gasRemainCheck, "storage_contract_id", scr.ParentID.Hex())
 	return nil, gasRemainCheck, nil
 }
@@ -668,22 +1012,61 @@ func (evm *EVM) CommitRevisionTx(caller ContractRef, data []byte, gas uint64) ([
 // StorageProofTx host send storage certificate transaction
 func (evm *EVM) StorageProofTx(caller ContractRef, data []byte, gas uint64) ([]byte, uint64, error) {
 	log.Info("enter storage proof tx executing ... ")
-	var (
-		state = evm.StateDB
-	)
-
 	sp := types.StorageProof{}
-	gasRemainDec, resultDec := RemainGas(gas, rlp.DecodeBytes, data, &sp)
+	gasRemainDec, resultDec := RemainGas(gas, evm.decodeStorageTx, data, &sp)
 	errDec, _ := resultDec[0].(error)
+	evm.traceStorageTxStep("decode", gasRemainDec, sp, errDec)
 	if errDec != nil {
 		return nil, gasRemainDec, errDec
 	}
 
+	gasRemain, err := evm.applyStorageProof(sp, gasRemainDec)
+	if err != nil {
+		return nil, gasRemain, err
+	}
+	log.Info("storage proof tx execution done", "storage_contract_id", sp.ParentID.Hex())
+	return nil, gasRemain, nil
+}
+
+// BatchStorageProofTx executes the storage proofs of multiple storage contracts. Each proof is
+// checked and charged the same as submitted alone, and the valid proof outputs are paid for
+// each contract. The batch fails as a whole if any of the proofs is invalid
+func (evm *EVM) BatchStorageProofTx(caller ContractRef, data []byte, gas uint64) ([]byte, uint64, error) {
+	log.Info("enter batch storage proof tx executing ... ")
+
+	batch := types.StorageProofBatch{}
+	gasRemain, resultDec := RemainGas(gas, evm.decodeStorageTx, data, &batch)
+	errDec, _ := resultDec[0].(error)
+	evm.traceStorageTxStep("decode", gasRemain, len(batch.Proofs), errDec)
+	if errDec != nil {
+		return nil, gasRemain, errDec
+	}
+	if len(batch.Proofs) == 0 || len(batch.Proofs) > MaxStorageProofBatchSize {
+		return nil, gasRemain, errInvalidStorageProofBatch
+	}
+
+	var err error
+	for i, sp := range batch.Proofs {
+		if gasRemain, err = evm.applyStorageProof(sp, gasRemain); err != nil {
+			return nil, gasRemain, fmt.Errorf("storage proof %d of contract %v: %v", i, sp.ParentID.Hex(), err)
+		}
+	}
+	log.Info("batch storage proof tx execution done", "proofs", len(batch.Proofs), "remain_gas", gasRemain)
+	return nil, gasRemain, nil
+}
+
+// applyStorageProof checks the storage proof, and pays the valid proof outputs of the storage
+// contract if the proof is valid. The remaining gas is returned
+func (evm *EVM) applyStorageProof(sp types.StorageProof, gas uint64) (uint64, error) {
+	var (
+		state = evm.StateDB
+	)
+
 	currentHeight := evm.BlockNumber.Uint64()
 
 	contractAddr := common.BytesToAddress(sp.ParentID[12:])
 	if !state.Exist(contractAddr) {
-		return nil, gasRemainDec, errors.New("no this storage contract account")
+		return gas, errors.New("no this storage contract account")
 	}
 
 	// retrieve origin data in storage contract
@@ -698,10 +1081,11 @@ func (evm *EVM) StorageProofTx(caller ContractRef, data []byte, gas uint64) ([]b
 	windowEndStr := strconv.FormatUint(windowEnd, 10)
 	statusAddr := common.BytesToAddress([]byte(coinchargemaintenance.StrPrefixExpSC + windowEndStr))
 
-	gasRemainCheck, resultCheck := RemainGas(gasRemainDec, CheckStorageProof, state, sp, uint64(currentHeight), statusAddr, contractAddr)
+	gasRemainCheck, resultCheck := RemainGas(gas, CheckStorageProof, state, evm.storageContractStore(), sp, uint64(currentHeight), statusAddr, contractAddr)
 	errCheck, _ := resultCheck[0].(error)
+	evm.traceStorageTxStep("checkProof", gasRemainCheck, sp.ParentID, errCheck)
 	if errCheck != nil {
-		return nil, gasRemainCheck, errCheck
+		return gasRemainCheck, errCheck
 	}
 
 	// effect valid proof outputs, first for client, second for host
@@ -723,8 +1107,52 @@ func (evm *EVM) StorageProofTx(caller ContractRef, data []byte, gas uint64) ([]b
 
 	// this contract is finished, so mark it empty account that will be deleted by stateDB
 	state.SetNonce(contractAddr, 0)
+	evm.emitContractProofed(sp.ParentID, clientValidOutput, hostValidOutput)
 
-	log.Info("storage proof tx execution done", "storage_contract_id", sp.ParentID.Hex())
+	evm.traceStorageTxStep("apply", gasRemainCheck, sp.ParentID, nil)
+	return gasRemainCheck, nil
+}
+
+// EscrowFundTx funds the escrow account of the renter from the treasury account, and sets the
+// spending cap of the renter. The escrow account is created by the first escrow fund transaction
+func (evm *EVM) EscrowFundTx(caller ContractRef, data []byte, gas uint64) ([]byte, uint64, error) {
+	log.Info("enter escrow fund tx executing ... ")
+	var (
+		state = evm.StateDB
+	)
+
+	se := types.StorageEscrow{}
+	gasRemainDec, resultDec := RemainGas(gas, evm.decodeStorageTx, data, &se)
+	errDec, _ := resultDec[0].(error)
+	evm.traceStorageTxStep("decode", gasRemainDec, se, errDec)
+	if errDec != nil {
+		return nil, gasRemainDec, errDec
+	}
+
+	gasRemainCheck, resultCheck := RemainGas(gasRemainDec, CheckEscrowFund, state, caller.Address(), se)
+	errCheck, _ := resultCheck[0].(error)
+	evm.traceStorageTxStep("checkEscrowFund", gasRemainCheck, nil, errCheck)
+	if errCheck != nil {
+		log.Error("failed to check escrow fund", "err", errCheck)
+		return nil, gasRemainCheck, errCheck
+	}
+
+	escrowAddr := types.EscrowAddress(se.Treasury, se.Renter)
+	if !state.Exist(escrowAddr) {
+		state.CreateAccount(escrowAddr)
+
+		// mark escrowAddr as not empty account to avoid being deleted by stateDB
+		state.SetNonce(escrowAddr, 1)
+		state.SetState(escrowAddr, coinchargemaintenance.KeyEscrowTreasury, common.BytesToHash(se.Treasury.Bytes()))
+		state.SetState(escrowAddr, coinchargemaintenance.KeyEscrowRenter, common.BytesToHash(se.Renter.Bytes()))
+	}
+	state.SetState(escrowAddr, coinchargemaintenance.KeyEscrowSpendingCap, common.BigToHash(se.SpendingCap))
+
+	state.SubBalance(se.Treasury, se.Amount)
+	state.AddBalance(escrowAddr, se.Amount)
+
+	evm.traceStorageTxStep("apply", gasRemainCheck, nil, nil)
+	log.Info("escrow fund tx execution done", "remain_gas", gasRemainCheck, "escrow_address", escrowAddr.Hex())
 	return nil, gasRemainCheck, nil
 }
 
@@ -90,6 +90,109 @@ func TestEVM_HostAnnounceTx(t *testing.T) {
 	}
 }
 
+func TestEVM_HostRevokeTx(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate private key,error: %v", err)
+	}
+	hostNode := enode.NewV4(&privateKey.PublicKey, net.IP{127, 0, 0, 1}, int(8888), int(8888))
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate private key,error: %v", err)
+	}
+
+	hostAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
+	stateDB := mockState(ethdb.NewMemDatabase(), mockAccountAlloc([]common.Address{hostAddress}))
+	evm := NewEVM(Context{BlockNumber: big.NewInt(1050)}, stateDB, params.MainnetChainConfig, Config{})
+
+	// the signature of the host announcement can not be taken as the revocation
+	announceSign, err := crypto.Sign(types.HostAnnouncement{NetAddress: hostNode.String()}.RLPHash().Bytes(), privateKey)
+	if err != nil {
+		t.Fatalf("failed to sign host announce,error: %v", err)
+	}
+
+	tests := []struct {
+		blockNumber uint64
+		key         *ecdsa.PrivateKey
+		signature   []byte
+		expectErr   bool
+	}{
+		{1000, privateKey, nil, false},
+		{1050, privateKey, nil, false},
+		{1050, otherKey, nil, true},
+		{1051, privateKey, nil, true},
+		{1050 - HostRevocationValidity - 1, privateKey, nil, true},
+		{1050, privateKey, announceSign, true},
+	}
+	for i, test := range tests {
+		hr := types.HostRevocation{
+			NetAddress:  hostNode.String(),
+			BlockNumber: test.blockNumber,
+		}
+		hr.Signature = test.signature
+		if hr.Signature == nil {
+			if hr.Signature, err = crypto.Sign(hr.RLPHash().Bytes(), test.key); err != nil {
+				t.Fatalf("failed to sign host revoke,error: %v", err)
+			}
+		}
+		rlpBytes, err := rlp.EncodeToBytes(hr)
+		if err != nil {
+			t.Fatalf("failed to rlp host revoke,error: %v", err)
+		}
+
+		_, gasLeft, err := evm.ApplyStorageContractTransaction(AccountRef{}, HostRevokeTransaction, rlpBytes, gasOrigin)
+		if test.expectErr != (err != nil) {
+			t.Errorf("test %d: expect error %v, got %v", i, test.expectErr, err)
+			continue
+		}
+		if !test.expectErr && gasLeft != gasOrigin-params.DecodeGas-params.CheckMultiSignaturesGas {
+			t.Errorf("test %d: gas left is not right,wanted %d,getted %d", i, gasOrigin-params.DecodeGas-params.CheckMultiSignaturesGas, gasLeft)
+		}
+	}
+}
+
+func TestEVM_StorageContractTxType(t *testing.T) {
+	evm, _, _, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := *params.MainnetChainConfig
+	evm.chainConfig = &config
+
+	tests := []struct {
+		addr   common.Address
+		txType string
+		fork   **big.Int
+	}{
+		{common.BytesToAddress([]byte{9}), HostAnnounceTransaction, nil},
+		{common.BytesToAddress([]byte{10}), ContractCreateTransaction, nil},
+		{common.BytesToAddress([]byte{11}), CommitRevisionTransaction, nil},
+		{common.BytesToAddress([]byte{12}), StorageProofTransaction, nil},
+		{common.BytesToAddress([]byte{13}), EscrowFundTransaction, &config.EscrowFundBlock},
+		{common.BytesToAddress([]byte{15}), HostRevokeTransaction, &config.HostRevokeBlock},
+		{common.BytesToAddress([]byte{16}), BatchStorageProofTransaction, &config.BatchProofBlock},
+		{common.BytesToAddress([]byte{17}), ContractRenewTransaction, &config.ContractRenewBlock},
+	}
+	for _, test := range tests {
+		// the storage contract tx added later is a plain call before its fork
+		if test.fork != nil {
+			*test.fork = big.NewInt(1001)
+			evm.chainRules = config.Rules(evm.BlockNumber)
+			if _, ok := evm.StorageContractTxType(test.addr); ok {
+				t.Errorf("%v: expect not routed before the fork", test.txType)
+			}
+			*test.fork = big.NewInt(1000)
+			evm.chainRules = config.Rules(evm.BlockNumber)
+		}
+		if txType, ok := evm.StorageContractTxType(test.addr); !ok || txType != test.txType {
+			t.Errorf("%v: expect routed, got %v %v", test.txType, txType, ok)
+		}
+	}
+	if _, ok := evm.StorageContractTxType(common.BytesToAddress([]byte{14})); ok {
+		t.Error("expect the address without storage contract tx not routed")
+	}
+}
+
 func TestEVM_CreateContractTx(t *testing.T) {
 
 	// mock evm, state, client and host address ...
@@ -237,6 +340,133 @@ func TestEVM_CreateContractTx(t *testing.T) {
 	}
 }
 
+func TestEVM_ApplyStorageContractTransactionRevert(t *testing.T) {
+	evm, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := mockStorageContract(prvAndAddresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlpBytes, err := rlp.EncodeToBytes(sc)
+	if err != nil {
+		t.Fatalf("failed to rlp storage contract,error: %v", err)
+	}
+
+	// the storage contract account already exists, the tx fails after the status account is created
+	scID := sc.ID()
+	contractAddr := common.BytesToAddress(scID[12:])
+	stateDB.CreateAccount(contractAddr)
+	stateDB.SetNonce(contractAddr, 1)
+	windowEndStr := strconv.FormatUint(sc.WindowEnd, 10)
+	statusAddr := common.BytesToAddress([]byte(coinchargemaintenance.StrPrefixExpSC + windowEndStr))
+
+	// the status account created by the failed tx is kept before the fork
+	snapshot := stateDB.Snapshot()
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err == nil {
+		t.Fatal("expected the storage contract tx to fail")
+	}
+	if !stateDB.Exist(statusAddr) {
+		t.Error("the status account created by the failed tx should be kept before the fork")
+	}
+	stateDB.RevertToSnapshot(snapshot)
+
+	config := *params.MainnetChainConfig
+	config.StorageRevertBlock = big.NewInt(1000)
+	evm.chainConfig = &config
+	evm.chainRules = config.Rules(evm.BlockNumber)
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err == nil {
+		t.Fatal("expected the storage contract tx to fail")
+	}
+	if stateDB.Exist(statusAddr) {
+		t.Error("the status account created by the failed tx should be reverted")
+	}
+}
+
+func TestEVM_SimulateStorageContractTransaction(t *testing.T) {
+	evm, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := mockStorageContract(prvAndAddresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlpBytes, err := rlp.EncodeToBytes(sc)
+	if err != nil {
+		t.Fatalf("failed to rlp storage contract,error: %v", err)
+	}
+
+	root := stateDB.IntermediateRoot(false)
+	gasUsed, err := evm.SimulateStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin)
+	if err != nil {
+		t.Fatalf("failed to simulate the storage contract tx: %v", err)
+	}
+	if gasUsed == 0 || gasUsed > gasOrigin {
+		t.Errorf("unexpected gas used: %d", gasUsed)
+	}
+	if stateDB.IntermediateRoot(false) != root {
+		t.Error("the simulated storage contract tx should not change the state")
+	}
+
+	// the simulated gas is the same as the gas consumed by the applied tx
+	_, gasLeft, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin)
+	if err != nil {
+		t.Fatalf("failed to apply the storage contract tx: %v", err)
+	}
+	if gasOrigin-gasLeft != gasUsed {
+		t.Errorf("simulated gas %d, applied gas %d", gasUsed, gasOrigin-gasLeft)
+	}
+
+	// the contract now exists, the simulation reports the validation error
+	if _, err := evm.SimulateStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err == nil {
+		t.Error("expected the simulation of the duplicated storage contract to fail")
+	}
+}
+
+func TestEVM_StorageContractFork(t *testing.T) {
+	evm, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, err := mockStorageContract(prvAndAddresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlpBytes, err := rlp.EncodeToBytes(sc)
+	if err != nil {
+		t.Fatalf("failed to rlp storage contract,error: %v", err)
+	}
+
+	// the storage contract tx is rejected before the fork without consuming gas
+	config := *params.MainnetChainConfig
+	config.StorageContractBlock = big.NewInt(1001)
+	evm.chainConfig = &config
+	evm.chainRules = config.Rules(evm.BlockNumber)
+
+	root := stateDB.IntermediateRoot(false)
+	_, gasLeft, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin)
+	if err != ErrStorageContractNotActivated || gasLeft != gasOrigin {
+		t.Fatalf("expect error %v with no gas used, got %v with %d gas left", ErrStorageContractNotActivated, err, gasLeft)
+	}
+	if stateDB.IntermediateRoot(false) != root {
+		t.Error("the storage contract tx rejected should not change the state")
+	}
+	if _, err := evm.SimulateStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err != ErrStorageContractNotActivated {
+		t.Fatalf("expect error %v, got %v", ErrStorageContractNotActivated, err)
+	}
+
+	// the storage contract tx is valid from the fork block
+	config.StorageContractBlock = big.NewInt(1000)
+	evm.chainRules = config.Rules(evm.BlockNumber)
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err != nil {
+		t.Fatalf("failed to apply the storage contract tx after the fork: %v", err)
+	}
+}
+
 func TestEVM_CommitRevisionTx(t *testing.T) {
 
 	// mock evm, state, client and host address ...
@@ -401,6 +631,284 @@ func TestEVM_StorageProofTx(t *testing.T) {
 
 }
 
+func TestEVM_BatchStorageProofTx(t *testing.T) {
+	evm, stateDB, prvAndAddresses, err := mockEvmAndState(1101)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := stateDB.Database().TrieDB().DiskDB().(ethdb.Database)
+	rawdb.WriteCanonicalHash(db, common.HexToHash("0x877c3a381d5ad88ca76a7b3e33ab1611939de59c56c0506efb9021593618f6ab"), uint64(1000))
+
+	// two storage contracts expiring at the same height
+	var proofs []types.StorageProof
+	for i := 0; i < 2; i++ {
+		sc, err := mockStorageContract(prvAndAddresses)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sc.RevisionNumber = uint64(i)
+		mockWriteStorageContractIntoState(*sc, stateDB)
+		sp, err := mockStorageProof(prvAndAddresses[1].Privkey, sc.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		proofs = append(proofs, *sp)
+	}
+	windowEndStr := strconv.FormatUint(1101, 10)
+	statusAddr := common.BytesToAddress([]byte(coinchargemaintenance.StrPrefixExpSC + windowEndStr))
+	proofed := func(sp types.StorageProof) bool {
+		return bytes.Equal(stateDB.GetState(statusAddr, sp.ParentID).Bytes()[11:12], coinchargemaintenance.ProofedStatus)
+	}
+
+	// the batch fails as a whole with the duplicated proof, and the state is reverted
+	rlpBytes, err := rlp.EncodeToBytes(types.StorageProofBatch{Proofs: []types.StorageProof{proofs[0], proofs[0]}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, BatchStorageProofTransaction, rlpBytes, gasOrigin); err == nil {
+		t.Fatal("expect error executing the batch with the duplicated proof")
+	}
+	if proofed(proofs[0]) {
+		t.Fatal("the proof of the failed batch shall be reverted")
+	}
+
+	// the empty batch is rejected
+	rlpBytes, err = rlp.EncodeToBytes(types.StorageProofBatch{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := evm.BatchStorageProofTx(AccountRef{}, rlpBytes, gasOrigin); err != errInvalidStorageProofBatch {
+		t.Errorf("expect error %v, got %v", errInvalidStorageProofBatch, err)
+	}
+
+	// each proof in the batch is charged and applied
+	hostBalance := new(big.Int).Set(stateDB.GetBalance(prvAndAddresses[1].Address))
+	rlpBytes, err = rlp.EncodeToBytes(types.StorageProofBatch{Proofs: proofs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, gasLeft, err := evm.ApplyStorageContractTransaction(AccountRef{}, BatchStorageProofTransaction, rlpBytes, gasOrigin)
+	if err != nil {
+		t.Fatalf("failed to execute batch storage proof tx,error: %v", err)
+	}
+	if expected := gasOrigin - params.DecodeGas - 2*params.CheckFileGas; gasLeft != expected {
+		t.Errorf("gas left is not right after executing batch storage proof tx,wanted %d,getted %d", expected, gasLeft)
+	}
+	for _, sp := range proofs {
+		if !proofed(sp) {
+			t.Errorf("storage contract %v is not proofed", sp.ParentID.Hex())
+		}
+	}
+	expectBalance := new(big.Int).Add(hostBalance, new(big.Int).Mul(hostCollateral, big.NewInt(2)))
+	if balance := stateDB.GetBalance(prvAndAddresses[1].Address); balance.Cmp(expectBalance) != 0 {
+		t.Errorf("host balance is not right after executing batch storage proof tx,wanted %v,getted %v", expectBalance, balance)
+	}
+}
+
+func TestEVM_RenewContractTx(t *testing.T) {
+	evm, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientAddr := prvAndAddresses[0].Address
+	hostAddr := prvAndAddresses[1].Address
+
+	// the old contract holding the collaterals
+	sc, err := mockStorageContract(prvAndAddresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockWriteStorageContractIntoState(*sc, stateDB)
+	oldAddr := common.BytesToAddress(sc.ID().Bytes()[12:])
+	stateDB.SetNonce(oldAddr, 1)
+	stateDB.AddBalance(oldAddr, new(big.Int).Add(clientCollateral, hostCollateral))
+
+	// the renewal of a different file is rejected
+	renewal, err := mockStorageRenewal(*sc, prvAndAddresses[0].Privkey, prvAndAddresses[1].Privkey, func(newSC *types.StorageContract) {
+		newSC.FileMerkleRoot = common.HexToHash("0x01")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlpBytes, err := rlp.EncodeToBytes(renewal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := evm.RenewContractTx(AccountRef{}, rlpBytes, gasOrigin); err != errRenewalFile {
+		t.Errorf("expect error %v, got %v", errRenewalFile, err)
+	}
+
+	// the renewal signed by the client only is rejected
+	renewal, err = mockStorageRenewal(*sc, prvAndAddresses[0].Privkey, prvAndAddresses[0].Privkey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlpBytes, err = rlp.EncodeToBytes(renewal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := evm.RenewContractTx(AccountRef{}, rlpBytes, gasOrigin); err != errWrongUnlockCondition {
+		t.Errorf("expect error %v, got %v", errWrongUnlockCondition, err)
+	}
+
+	// the renewal closes the old contract and creates the new one
+	renewal, err = mockStorageRenewal(*sc, prvAndAddresses[0].Privkey, prvAndAddresses[1].Privkey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlpBytes, err = rlp.EncodeToBytes(renewal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientBalance := new(big.Int).Set(stateDB.GetBalance(clientAddr))
+	hostBalance := new(big.Int).Set(stateDB.GetBalance(hostAddr))
+	_, gasLeft, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractRenewTransaction, rlpBytes, gasOrigin)
+	if err != nil {
+		t.Fatalf("failed to execute renew contract tx,error: %v", err)
+	}
+	if expected := gasOrigin - params.DecodeGas - params.CheckFileGas; gasLeft != expected {
+		t.Errorf("gas left is not right after executing renew contract tx,wanted %d,getted %d", expected, gasLeft)
+	}
+
+	oldStatusAddr := common.BytesToAddress([]byte(coinchargemaintenance.StrPrefixExpSC + strconv.FormatUint(sc.WindowEnd, 10)))
+	if flag := stateDB.GetState(oldStatusAddr, sc.ID()).Bytes()[11:12]; !bytes.Equal(flag, coinchargemaintenance.ProofedStatus) {
+		t.Error("the old storage contract is not closed")
+	}
+	if stateDB.GetNonce(oldAddr) != 0 || stateDB.GetBalance(oldAddr).Sign() != 0 {
+		t.Error("the old storage contract account is not emptied")
+	}
+	newAddr := common.BytesToAddress(renewal.NewContract.ID().Bytes()[12:])
+	if !stateDB.Exist(newAddr) || stateDB.GetState(newAddr, coinchargemaintenance.KeyFileMerkleRoot) != sc.FileMerkleRoot {
+		t.Error("the new storage contract is not created with the same file")
+	}
+	if balance := stateDB.GetBalance(newAddr); balance.Cmp(new(big.Int).Add(clientCollateral, hostCollateral)) != 0 {
+		t.Errorf("the new storage contract balance is not right,wanted %v,getted %v", new(big.Int).Add(clientCollateral, hostCollateral), balance)
+	}
+
+	// the collaterals returned are paid for the new contract
+	if balance := stateDB.GetBalance(clientAddr); balance.Cmp(clientBalance) != 0 {
+		t.Errorf("client balance is not right after executing renew contract tx,wanted %v,getted %v", clientBalance, balance)
+	}
+	if balance := stateDB.GetBalance(hostAddr); balance.Cmp(hostBalance) != 0 {
+		t.Errorf("host balance is not right after executing renew contract tx,wanted %v,getted %v", hostBalance, balance)
+	}
+
+	// the closed contract could not be renewed again
+	if _, _, err := evm.RenewContractTx(AccountRef{}, rlpBytes, gasOrigin); err == nil {
+		t.Error("expect error renewing the closed storage contract")
+	}
+}
+
+func TestEVM_EscrowFundTx(t *testing.T) {
+	evm, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientAddress := prvAndAddresses[0].Address
+	escrowFundAddress := common.BytesToAddress([]byte{13})
+
+	// the escrow fund tx is a plain call before the fork
+	config := *params.MainnetChainConfig
+	config.EscrowFundBlock = big.NewInt(1001)
+	evm.chainConfig = &config
+	evm.chainRules = config.Rules(evm.BlockNumber)
+	if _, ok := evm.StorageContractTxType(escrowFundAddress); ok {
+		t.Fatal("the escrow fund tx should not be routed before the fork")
+	}
+
+	// the escrow fund tx is routed from the fork block
+	config.EscrowFundBlock = big.NewInt(1000)
+	evm.chainRules = config.Rules(evm.BlockNumber)
+	if txType, ok := evm.StorageContractTxType(escrowFundAddress); !ok || txType != EscrowFundTransaction {
+		t.Fatalf("the escrow fund tx should be routed from the fork, got %v", txType)
+	}
+
+	// mock the treasury funding the escrow account of the client
+	treasuryKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate private key,error: %v", err)
+	}
+	treasuryAddress := crypto.PubkeyToAddress(treasuryKey.PublicKey)
+	stateDB.AddBalance(treasuryAddress, balanceOrigin)
+
+	amount := new(big.Int).Mul(clientCollateral, big.NewInt(2))
+	se := types.StorageEscrow{
+		Treasury:    treasuryAddress,
+		Renter:      clientAddress,
+		Amount:      amount,
+		SpendingCap: new(big.Int).Add(clientCollateral, big.NewInt(1)),
+	}
+	rlpBytes, err := rlp.EncodeToBytes(se)
+	if err != nil {
+		t.Fatalf("failed to rlp storage escrow,error: %v", err)
+	}
+
+	// the escrow fund must be sent by the treasury
+	if _, _, err := evm.EscrowFundTx(AccountRef(clientAddress), rlpBytes, gasOrigin); err != errEscrowNotTreasury {
+		t.Fatalf("expected error %v, got %v", errEscrowNotTreasury, err)
+	}
+	_, gasLeft, err := evm.EscrowFundTx(AccountRef(treasuryAddress), rlpBytes, gasOrigin)
+	if err != nil {
+		t.Fatalf("failed to execute escrow fund tx,error: %v", err)
+	}
+	if gasLeft != gasOrigin-params.DecodeGas-params.CheckFileGas {
+		t.Errorf("gas left is not right after executing escrow fund tx,wanted %d,getted %d", gasOrigin-params.DecodeGas-params.CheckFileGas, gasLeft)
+	}
+
+	escrowAddress := types.EscrowAddress(treasuryAddress, clientAddress)
+	if balance := stateDB.GetBalance(escrowAddress); balance.Cmp(amount) != 0 {
+		t.Errorf("escrow balance is not right,wanted %v,getted %v", amount, balance)
+	}
+	if balance := stateDB.GetBalance(treasuryAddress); balance.Cmp(new(big.Int).Sub(balanceOrigin, amount)) != 0 {
+		t.Errorf("treasury balance is not right,wanted %v,getted %v", new(big.Int).Sub(balanceOrigin, amount), balance)
+	}
+
+	// the client collateral of the storage contract is paid by the escrow account
+	sc, err := mockStorageContract(prvAndAddresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.ClientCollateral.Address = escrowAddress
+	signByClient, err := crypto.Sign(sc.RLPHash().Bytes(), prvAndAddresses[0].Privkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signByHost, err := crypto.Sign(sc.RLPHash().Bytes(), prvAndAddresses[1].Privkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.Signatures = [][]byte{signByClient, signByHost}
+
+	scBytes, err := rlp.EncodeToBytes(sc)
+	if err != nil {
+		t.Fatalf("failed to rlp storage contract,error: %v", err)
+	}
+	if _, _, err := evm.CreateContractTx(AccountRef{}, scBytes, gasOrigin); err != nil {
+		t.Fatalf("failed to execute storage contract tx,error: %v", err)
+	}
+	if balance := stateDB.GetBalance(clientAddress); balance.Cmp(balanceOrigin) != 0 {
+		t.Errorf("client balance should not be changed,wanted %v,getted %v", balanceOrigin, balance)
+	}
+	if balance := stateDB.GetBalance(escrowAddress); balance.Cmp(new(big.Int).Sub(amount, clientCollateral)) != 0 {
+		t.Errorf("escrow balance is not right,wanted %v,getted %v", new(big.Int).Sub(amount, clientCollateral), balance)
+	}
+	spent := new(big.Int).SetBytes(stateDB.GetState(escrowAddress, coinchargemaintenance.KeyEscrowSpent).Bytes())
+	if spent.Cmp(clientCollateral) != 0 {
+		t.Errorf("escrow spent is not right,wanted %v,getted %v", clientCollateral, spent)
+	}
+
+	// the spending cap is enforced even though the escrow account has enough balance
+	if err := CheckEscrowSpending(stateDB, *sc); err != errEscrowSpendingCapExceeded {
+		t.Errorf("expected error %v, got %v", errEscrowSpendingCapExceeded, err)
+	}
+
+	// the storage contract paid by the escrow account must be signed by its renter
+	sc.Signatures = [][]byte{signByHost, signByClient}
+	if err := CheckEscrowSpending(stateDB, *sc); err != errEscrowNotRenter {
+		t.Errorf("expected error %v, got %v", errEscrowNotRenter, err)
+	}
+}
+
 func mockAccountAlloc(addrs []common.Address) AccountAlloc {
 	accounts := make(AccountAlloc)
 	for _, addr := range addrs {
@@ -566,6 +1074,33 @@ func mockStorageRevision(sc types.StorageContract, cost *big.Int, prvKeyClient,
 	return scr, nil
 }
 
+func mockStorageRenewal(sc types.StorageContract, prvKeyClient, prvKeyHost *ecdsa.PrivateKey, modify func(*types.StorageContract)) (*types.StorageContractRenewal, error) {
+	newSC := sc
+	newSC.WindowStart = sc.WindowEnd
+	newSC.WindowEnd = sc.WindowEnd + 100
+	newSC.Signatures = nil
+	if modify != nil {
+		modify(&newSC)
+	}
+	renewal := &types.StorageContractRenewal{
+		OldContractID: sc.ID(),
+		NewContract:   newSC,
+	}
+
+	signByClient, err := crypto.Sign(renewal.RLPHash().Bytes(), prvKeyClient)
+	if err != nil {
+		return nil, fmt.Errorf("client failed to sign storage contract renewal,error: %v", err)
+	}
+
+	signByHost, err := crypto.Sign(renewal.RLPHash().Bytes(), prvKeyHost)
+	if err != nil {
+		return nil, fmt.Errorf("host failed to sign storage contract renewal,error: %v", err)
+	}
+
+	renewal.Signatures = [][]byte{signByClient, signByHost}
+	return renewal, nil
+}
+
 func mockWriteStorageContractIntoState(sc types.StorageContract, state *state.StateDB) {
 
 	// create the expired storage contract status address (e.g. "expired_storage_contract_1500")
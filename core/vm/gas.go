@@ -122,6 +122,28 @@ func RemainGas(args ...interface{}) (uint64, []interface{}) {
 		result = append(result, nil)
 		return gas, result
 
+		//CheckRenewContract
+	case func(StateDB, types.StorageContractRenewal, uint64) error:
+		if gas < params.CheckFileGas {
+			result = append(result, errGasCalculationInsufficient)
+			return gas, result
+		}
+		if len(args) != 5 {
+			result = append(result, errGasCalculationParamsNumberWrong)
+			return gas, result
+		}
+		state, _ := args[2].(StateDB)
+		renewal, _ := args[3].(types.StorageContractRenewal)
+		bl, _ := args[4].(uint64)
+		gas -= params.CheckFileGas
+		err := i(state, renewal, bl)
+		if err != nil {
+			result = append(result, err)
+			return gas, result
+		}
+		result = append(result, nil)
+		return gas, result
+
 		//CheckReversionContract
 	case func(StateDB, types.StorageContractRevision, uint64, common.Address) error:
 		if gas < params.CheckFileGas {
@@ -147,23 +169,46 @@ func RemainGas(args ...interface{}) (uint64, []interface{}) {
 		return gas, result
 
 		//CheckStorageProof
-	case func(StateDB, types.StorageProof, uint64, common.Address, common.Address) error:
+	case func(StateDB, StorageContractStore, types.StorageProof, uint64, common.Address, common.Address) error:
 		if gas < params.CheckFileGas {
 			result = append(result, errGasCalculationInsufficient)
 			return gas, result
 		}
 
-		if len(args) != 7 {
+		if len(args) != 8 {
 			result = append(result, errGasCalculationParamsNumberWrong)
 			return gas, result
 		}
 		state, _ := args[2].(StateDB)
-		sp, _ := args[3].(types.StorageProof)
-		bl, _ := args[4].(uint64)
-		statusAddr, _ := args[5].(common.Address)
-		contractAddr, _ := args[6].(common.Address)
+		store, _ := args[3].(StorageContractStore)
+		sp, _ := args[4].(types.StorageProof)
+		bl, _ := args[5].(uint64)
+		statusAddr, _ := args[6].(common.Address)
+		contractAddr, _ := args[7].(common.Address)
+		gas -= params.CheckFileGas
+		err := i(state, store, sp, bl, statusAddr, contractAddr)
+		if err != nil {
+			result = append(result, err)
+			return gas, result
+		}
+		result = append(result, nil)
+		return gas, result
+
+		//CheckEscrowFund
+	case func(StateDB, common.Address, types.StorageEscrow) error:
+		if gas < params.CheckFileGas {
+			result = append(result, errGasCalculationInsufficient)
+			return gas, result
+		}
+		if len(args) != 5 {
+			result = append(result, errGasCalculationParamsNumberWrong)
+			return gas, result
+		}
+		state, _ := args[2].(StateDB)
+		sender, _ := args[3].(common.Address)
+		se, _ := args[4].(types.StorageEscrow)
 		gas -= params.CheckFileGas
-		err := i(state, sp, bl, statusAddr, contractAddr)
+		err := i(state, sender, se)
 		if err != nil {
 			result = append(result, err)
 			return gas, result
@@ -0,0 +1,170 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/rlp"
+)
+
+// The announced host registry records the latest host announcement of each storage host in the
+// state, at the address derived from the node id of the host. The registry entry keeps the net
+// address and the signature announced, the block number announced at, and the number of the
+// announcements recorded, which is kept increasing even after the announcement is revoked. The
+// registry is only maintained after the HostRegistryBlock fork.
+
+var (
+	// keyHostAnnouncement is the key to store the RLP encoded host announcement into trie
+	keyHostAnnouncement = common.BytesToHash([]byte("HostAnnouncement"))
+
+	// keyHostAnnounceBlock is the key to store the block number announced at into trie
+	keyHostAnnounceBlock = common.BytesToHash([]byte("HostAnnounceBlock"))
+
+	// keyHostAnnounceNonce is the key to store the number of the announcements into trie
+	keyHostAnnounceNonce = common.BytesToHash([]byte("HostAnnounceNonce"))
+)
+
+var (
+	// ErrDuplicateHostAnnouncement is the error that the host announcement repeats the one
+	// registered for the storage host
+	ErrDuplicateHostAnnouncement = errors.New("the host announcement is already registered")
+
+	errHostAnnouncementNotIncreasing = errors.New("the host announcement is not newer than the registered one")
+)
+
+// HostRegistryEntry is the latest announcement of the storage host recorded in the registry
+type HostRegistryEntry struct {
+	NetAddress  string
+	Signature   []byte
+	BlockNumber uint64
+	Nonce       uint64
+}
+
+// HostRegistryAddress returns the address of the registry entry of the storage host
+func HostRegistryAddress(id enode.ID) common.Address {
+	h := crypto.Keccak256Hash([]byte("HostRegistry"), id[:])
+	return common.BytesToAddress(h[12:])
+}
+
+// ReadHostAnnouncement reads the registry entry of the storage host from the state. False is
+// returned if the storage host has not announced or the announcement is revoked
+func ReadHostAnnouncement(state StateDB, id enode.ID) (entry HostRegistryEntry, exists bool) {
+	addr := HostRegistryAddress(id)
+
+	// the announcement is never in the genesis block
+	blockNumber := state.GetState(addr, keyHostAnnounceBlock).Big().Uint64()
+	if blockNumber == 0 {
+		return HostRegistryEntry{}, false
+	}
+	var ha types.HostAnnouncement
+	if err := rlp.DecodeBytes(getStateBytes(state, addr, keyHostAnnouncement), &ha); err != nil {
+		return HostRegistryEntry{}, false
+	}
+	return HostRegistryEntry{
+		NetAddress:  ha.NetAddress,
+		Signature:   ha.Signature,
+		BlockNumber: blockNumber,
+		Nonce:       state.GetState(addr, keyHostAnnounceNonce).Big().Uint64(),
+	}, true
+}
+
+// CheckHostRegistry checks whether the host announcement of the node is newer than the one
+// registered. The announcement in the same block as the registered one, or repeating the net
+// address registered, is rejected
+func CheckHostRegistry(state StateDB, id enode.ID, ha types.HostAnnouncement, currentHeight uint64) error {
+	entry, exists := ReadHostAnnouncement(state, id)
+	if !exists {
+		return nil
+	}
+	if currentHeight <= entry.BlockNumber {
+		return errHostAnnouncementNotIncreasing
+	}
+	if ha.NetAddress == entry.NetAddress {
+		return ErrDuplicateHostAnnouncement
+	}
+	return nil
+}
+
+// registerHostAnnouncement records the host announcement as the latest one of the node
+func registerHostAnnouncement(state StateDB, id enode.ID, ha types.HostAnnouncement, currentHeight uint64) error {
+	data, err := rlp.EncodeToBytes(ha)
+	if err != nil {
+		return err
+	}
+	addr := HostRegistryAddress(id)
+	if !state.Exist(addr) {
+		state.CreateAccount(addr)
+
+		// mark addr as not empty account to avoid being deleted by stateDB
+		state.SetNonce(addr, 1)
+	}
+	nonce := state.GetState(addr, keyHostAnnounceNonce).Big().Uint64()
+	setStateBytes(state, addr, keyHostAnnouncement, data)
+	state.SetState(addr, keyHostAnnounceBlock, common.BigToHash(new(big.Int).SetUint64(currentHeight)))
+	state.SetState(addr, keyHostAnnounceNonce, common.BigToHash(new(big.Int).SetUint64(nonce+1)))
+	return nil
+}
+
+// unregisterHostAnnouncement removes the announcement of the node from the registry. The
+// number of the announcements is kept, so that it is still increasing after announced again
+func unregisterHostAnnouncement(state StateDB, id enode.ID) {
+	addr := HostRegistryAddress(id)
+	if !state.Exist(addr) {
+		return
+	}
+	setStateBytes(state, addr, keyHostAnnouncement, nil)
+	state.SetState(addr, keyHostAnnounceBlock, common.Hash{})
+}
+
+// hostNodeID returns the node id of the storage host announcing the net address
+func hostNodeID(netAddress string) (enode.ID, error) {
+	hostNode, err := enode.ParseV4(netAddress)
+	if err != nil {
+		return enode.ID{}, err
+	}
+	return hostNode.ID(), nil
+}
+
+// setStateBytes stores the data of arbitrary length into the state. The length is stored at the
+// key, and the data is stored in the words at the keys following the hash of the key. The words
+// of the previous data not overwritten are cleared
+func setStateBytes(state StateDB, addr common.Address, key common.Hash, data []byte) {
+	prevLen := state.GetState(addr, key).Big().Uint64()
+	state.SetState(addr, key, common.BigToHash(new(big.Int).SetUint64(uint64(len(data)))))
+
+	base := crypto.Keccak256Hash(key[:]).Big()
+	words := (uint64(len(data)) + common.HashLength - 1) / common.HashLength
+	prevWords := (prevLen + common.HashLength - 1) / common.HashLength
+	for i := uint64(0); i < words || i < prevWords; i++ {
+		var word common.Hash
+		if start := i * common.HashLength; start < uint64(len(data)) {
+			copy(word[:], data[start:])
+		}
+		state.SetState(addr, stateWordKey(base, i), word)
+	}
+}
+
+// getStateBytes reads the data stored by setStateBytes from the state
+func getStateBytes(state StateDB, addr common.Address, key common.Hash) []byte {
+	length := state.GetState(addr, key).Big().Uint64()
+	base := crypto.Keccak256Hash(key[:]).Big()
+	data := make([]byte, 0, length+common.HashLength)
+	for i := uint64(0); uint64(len(data)) < length; i++ {
+		word := state.GetState(addr, stateWordKey(base, i))
+		data = append(data, word[:]...)
+	}
+	return data[:length]
+}
+
+// stateWordKey returns the key of the ith word following the base
+func stateWordKey(base *big.Int, i uint64) common.Hash {
+	return common.BigToHash(new(big.Int).Add(base, new(big.Int).SetUint64(i)))
+}
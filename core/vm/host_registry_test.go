@@ -0,0 +1,142 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/params"
+	"github.com/DxChainNetwork/godx/rlp"
+)
+
+func TestEVM_HostRegistry(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostNode := enode.NewV4(&privateKey.PublicKey, net.IP{127, 0, 0, 1}, 8888, 8888)
+	movedNode := enode.NewV4(&privateKey.PublicKey, net.IP{127, 0, 0, 2}, 8888, 8888)
+
+	stateDB := mockState(ethdb.NewMemDatabase(), mockAccountAlloc(nil))
+	config := *params.MainnetChainConfig
+	config.HostRegistryBlock = big.NewInt(100)
+	evm := NewEVM(Context{}, stateDB, &config, Config{})
+
+	announce := func(blockNumber int64, node *enode.Node) (types.HostAnnouncement, error) {
+		ha := types.HostAnnouncement{NetAddress: node.String()}
+		sign, err := crypto.Sign(ha.RLPHash().Bytes(), privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ha.Signature = sign
+		rlpBytes, err := rlp.EncodeToBytes(ha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		evm.BlockNumber = big.NewInt(blockNumber)
+		_, _, err = evm.ApplyStorageContractTransaction(AccountRef{}, HostAnnounceTransaction, rlpBytes, gasOrigin)
+		return ha, err
+	}
+	revoke := func(blockNumber int64) error {
+		hr := types.HostRevocation{NetAddress: hostNode.String(), BlockNumber: uint64(blockNumber)}
+		sign, err := crypto.Sign(hr.RLPHash().Bytes(), privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hr.Signature = sign
+		rlpBytes, err := rlp.EncodeToBytes(hr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		evm.BlockNumber = big.NewInt(blockNumber)
+		_, _, err = evm.ApplyStorageContractTransaction(AccountRef{}, HostRevokeTransaction, rlpBytes, gasOrigin)
+		return err
+	}
+	checkEntry := func(ha types.HostAnnouncement, blockNumber, nonce uint64) {
+		t.Helper()
+		entry, exists := ReadHostAnnouncement(stateDB, hostNode.ID())
+		if !exists {
+			t.Fatal("the host announcement shall be registered")
+		}
+		if entry.NetAddress != ha.NetAddress || !bytes.Equal(entry.Signature, ha.Signature) ||
+			entry.BlockNumber != blockNumber || entry.Nonce != nonce {
+			t.Fatalf("registry entry not expected. Got %+v", entry)
+		}
+	}
+
+	// the announcements are not recorded before the fork
+	if _, err := announce(99, hostNode); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := ReadHostAnnouncement(stateDB, hostNode.ID()); exists {
+		t.Fatal("the host announcement shall not be registered before the fork")
+	}
+
+	ha, err := announce(100, hostNode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEntry(ha, 100, 1)
+
+	// the announcement repeating the registered one or in the same block is rejected
+	if _, err := announce(101, hostNode); err != ErrDuplicateHostAnnouncement {
+		t.Fatalf("expect error %v, got %v", ErrDuplicateHostAnnouncement, err)
+	}
+	if _, err := announce(100, movedNode); err != errHostAnnouncementNotIncreasing {
+		t.Fatalf("expect error %v, got %v", errHostAnnouncementNotIncreasing, err)
+	}
+	checkEntry(ha, 100, 1)
+
+	moved, err := announce(102, movedNode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEntry(moved, 102, 2)
+
+	// the host could announce the same address again once revoked
+	if err := revoke(103); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := ReadHostAnnouncement(stateDB, hostNode.ID()); exists {
+		t.Fatal("the revoked host announcement shall be removed from the registry")
+	}
+	if ha, err = announce(104, hostNode); err != nil {
+		t.Fatal(err)
+	}
+	checkEntry(ha, 104, 3)
+}
+
+func TestStateBytes(t *testing.T) {
+	stateDB := mockState(ethdb.NewMemDatabase(), mockAccountAlloc(nil))
+	addr := common.BytesToAddress([]byte{0x01})
+	key := common.BytesToHash([]byte("key"))
+
+	long := bytes.Repeat([]byte{0x02}, 3*common.HashLength+1)
+	setStateBytes(stateDB, addr, key, long)
+	if got := getStateBytes(stateDB, addr, key); !bytes.Equal(got, long) {
+		t.Fatalf("expect %x, got %x", long, got)
+	}
+
+	// the words of the longer data previously stored are cleared
+	short := []byte{0x03}
+	setStateBytes(stateDB, addr, key, short)
+	if got := getStateBytes(stateDB, addr, key); !bytes.Equal(got, short) {
+		t.Fatalf("expect %x, got %x", short, got)
+	}
+	base := crypto.Keccak256Hash(key[:]).Big()
+	for i := uint64(1); i < 4; i++ {
+		if word := stateDB.GetState(addr, stateWordKey(base, i)); word != (common.Hash{}) {
+			t.Fatalf("word %d shall be cleared, got %x", i, word)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// InterpreterFactory creates the interpreter for the evm. The options are the colon separated
+// fields following the interpreter name in Config.EVMInterpreter or Config.EWASMInterpreter
+type InterpreterFactory func(evm *EVM, cfg Config, options []string) Interpreter
+
+var (
+	interpreterFactories = make(map[string]InterpreterFactory)
+	interpreterLock      sync.RWMutex
+)
+
+// RegisterInterpreter registers the interpreter factory by name, so that the interpreter
+// configured as "name[:option...]" in Config.EVMInterpreter or Config.EWASMInterpreter is
+// created by NewEVM. It is meant to be called in the init function of the package providing
+// the interpreter, and panics if the name is empty or already registered
+func RegisterInterpreter(name string, factory InterpreterFactory) {
+	if name == "" || strings.Contains(name, ":") {
+		panic(fmt.Sprintf("vm: invalid interpreter name %q", name))
+	}
+	if factory == nil {
+		panic("vm: nil interpreter factory registered for " + name)
+	}
+	interpreterLock.Lock()
+	defer interpreterLock.Unlock()
+	if _, exists := interpreterFactories[name]; exists {
+		panic("vm: interpreter registered twice for " + name)
+	}
+	interpreterFactories[name] = factory
+}
+
+// RegisteredInterpreters returns the sorted names of the interpreters registered
+func RegisteredInterpreters() []string {
+	interpreterLock.RLock()
+	defer interpreterLock.RUnlock()
+	names := make([]string, 0, len(interpreterFactories))
+	for name := range interpreterFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckInterpreter returns an error if the interpreter configured is not registered. The
+// empty config refers to the built-in interpreter, and is always valid
+func CheckInterpreter(spec string) error {
+	if spec == "" {
+		return nil
+	}
+	if _, _, exists := lookupInterpreter(spec); !exists {
+		return fmt.Errorf("interpreter %q is not registered, registered interpreters: %v", spec, RegisteredInterpreters())
+	}
+	return nil
+}
+
+// lookupInterpreter splits the interpreter config into the name and the options, and returns
+// the factory registered by the name
+func lookupInterpreter(spec string) (InterpreterFactory, []string, bool) {
+	fields := strings.Split(spec, ":")
+	interpreterLock.RLock()
+	factory, exists := interpreterFactories[fields[0]]
+	interpreterLock.RUnlock()
+	return factory, fields[1:], exists
+}
+
+// newRegisteredInterpreter creates the interpreter configured for the evm. Nil is returned
+// if the config is empty or the interpreter is not registered
+func newRegisteredInterpreter(evm *EVM, cfg Config, spec string) Interpreter {
+	if spec == "" {
+		return nil
+	}
+	factory, options, exists := lookupInterpreter(spec)
+	if !exists {
+		return nil
+	}
+	return factory(evm, cfg, options)
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/params"
+)
+
+var wasmMagic = []byte{0x00, 0x61, 0x73, 0x6d}
+
+// wasmTestInterpreter runs the code with the wasm magic only
+type wasmTestInterpreter struct {
+	options []string
+}
+
+func (wi *wasmTestInterpreter) Run(contract *Contract, input []byte, static bool) ([]byte, error) {
+	return []byte("wasm"), nil
+}
+
+func (wi *wasmTestInterpreter) CanRun(code []byte) bool {
+	return bytes.HasPrefix(code, wasmMagic)
+}
+
+func init() {
+	RegisterInterpreter("wasmtest", func(evm *EVM, cfg Config, options []string) Interpreter {
+		return &wasmTestInterpreter{options: options}
+	})
+}
+
+func TestRegisteredInterpreter(t *testing.T) {
+	if err := CheckInterpreter("wasmtest:fast"); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckInterpreter("unknown"); err == nil {
+		t.Fatal("expect error for the interpreter not registered")
+	}
+	if err := CheckInterpreter(""); err != nil {
+		t.Fatalf("the built-in interpreter shall always be valid: %v", err)
+	}
+
+	evm := NewEVM(Context{BlockNumber: big.NewInt(0)}, nil, params.TestChainConfig, Config{EVMInterpreter: "wasmtest:fast:debug"})
+	if len(evm.interpreters) != 2 {
+		t.Fatalf("expect the registered and the built-in interpreters, got %v", len(evm.interpreters))
+	}
+	wi, ok := evm.interpreters[0].(*wasmTestInterpreter)
+	if !ok {
+		t.Fatalf("expect the registered interpreter first, got %T", evm.interpreters[0])
+	}
+	if !reflect.DeepEqual(wi.options, []string{"fast", "debug"}) {
+		t.Errorf("unexpected interpreter options %v", wi.options)
+	}
+
+	// the wasm code is run by the registered interpreter, and the other code falls over to
+	// the built-in interpreter
+	contract := NewContract(AccountRef(common.Address{1}), AccountRef(common.Address{2}), big.NewInt(0), 100000)
+	contract.Code = append(wasmMagic, 0x01)
+	ret, err := run(evm, contract, nil, false)
+	if err != nil || string(ret) != "wasm" {
+		t.Errorf("expect the wasm code run by the registered interpreter, got %s, %v", ret, err)
+	}
+	contract.Code = []byte{byte(PUSH1), 0x2a, byte(PUSH1), 0x00, byte(MSTORE), byte(PUSH1), 0x20, byte(PUSH1), 0x00, byte(RETURN)}
+	ret, err = run(evm, contract, nil, false)
+	if err != nil || new(big.Int).SetBytes(ret).Int64() != 0x2a {
+		t.Errorf("expect the evm code run by the built-in interpreter, got %x, %v", ret, err)
+	}
+}
+
+func TestRegisterInterpreterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expect panic registering the interpreter twice")
+		}
+	}()
+	RegisterInterpreter("wasmtest", func(evm *EVM, cfg Config, options []string) Interpreter {
+		return &wasmTestInterpreter{}
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/params"
+)
+
+func TestConfigurableLimits(t *testing.T) {
+	config := *params.MainnetChainConfig
+	config.LimitsBlock = big.NewInt(100)
+	config.CallDepthLimit = 2 * params.CallCreateDepth
+	config.CodeSizeLimit = 2 * params.MaxCodeSize
+
+	// the init code returning the code of 30000 zero bytes, which exceeds the default limit
+	initCode := []byte{byte(PUSH2), 0x75, 0x30, byte(PUSH1), 0x00, byte(RETURN)}
+	caller := AccountRef(common.HexToAddress("0x1"))
+
+	tests := []struct {
+		block      int64
+		depth      uint64
+		codeSizeOK bool
+	}{
+		{99, params.CallCreateDepth, false},
+		{100, 2 * params.CallCreateDepth, true},
+	}
+	for _, test := range tests {
+		ctx := Context{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: big.NewInt(test.block),
+		}
+		evm := NewEVM(ctx, mockState(ethdb.NewMemDatabase(), nil), &config, Config{})
+
+		evm.depth = int(test.depth)
+		if _, _, err := evm.Call(caller, common.HexToAddress("0x2"), nil, 100000, new(big.Int)); err != nil {
+			t.Errorf("block %d: the call at depth %d shall succeed, got %v", test.block, test.depth, err)
+		}
+		evm.depth = int(test.depth) + 1
+		if _, _, err := evm.Call(caller, common.HexToAddress("0x2"), nil, 100000, new(big.Int)); err != ErrDepth {
+			t.Errorf("block %d: expect error %v at depth %d, got %v", test.block, ErrDepth, test.depth+1, err)
+		}
+
+		evm.depth = 0
+		_, _, _, err := evm.Create(caller, initCode, 10000000, new(big.Int))
+		if test.codeSizeOK && err != nil {
+			t.Errorf("block %d: the code within the limit shall be created, got %v", test.block, err)
+		} else if !test.codeSizeOK && err != errMaxCodeSizeExceeded {
+			t.Errorf("block %d: expect error %v, got %v", test.block, errMaxCodeSizeExceeded, err)
+		}
+	}
+}
@@ -94,7 +94,9 @@ func (s *StructLog) ErrorString() string {
 
 // Tracer is used to collect execution traces from an EVM transaction
 // execution. CaptureState is called for each step of the VM with the
-// current VM state.
+// current VM state. CaptureStart and CaptureEnd are called for the call
+// frame at depth 0, while CaptureEnter and CaptureExit are called for each
+// nested call frame, so that the tracer could rebuild the full call tree.
 // Note that reference types are actual VM data structures; make copies
 // if you need to retain them beyond the current call.
 type Tracer interface {
@@ -102,6 +104,8 @@ type Tracer interface {
 	CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error
 	CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error
 	CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error
+	CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error
+	CaptureExit(output []byte, gasUsed uint64, err error) error
 }
 
 // StructLogger is an EVM state logger and implements Tracer.
@@ -113,6 +117,7 @@ type StructLogger struct {
 	cfg LogConfig
 
 	logs          []StructLog
+	storageTxLogs []StorageTxStep
 	changedValues map[common.Address]Storage
 	output        []byte
 	err           error
@@ -203,9 +208,30 @@ func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration
 	return nil
 }
 
+// CaptureEnter is called when the execution enters a nested call frame. The depth of the
+// frame is already captured in the struct logs.
+func (l *StructLogger) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureExit is called when the execution exits a nested call frame.
+func (l *StructLogger) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
+// CaptureStorageTxStep implements the StorageTxTracer interface to capture a step of the
+// storage contract transaction.
+func (l *StructLogger) CaptureStorageTxStep(step StorageTxStep) error {
+	l.storageTxLogs = append(l.storageTxLogs, step)
+	return nil
+}
+
 // StructLogs returns the captured log entries.
 func (l *StructLogger) StructLogs() []StructLog { return l.logs }
 
+// StorageTxLogs returns the captured steps of the storage contract transaction.
+func (l *StructLogger) StorageTxLogs() []StorageTxStep { return l.storageTxLogs }
+
 // Error returns the VM error captured by the trace.
 func (l *StructLogger) Error() error { return l.err }
 
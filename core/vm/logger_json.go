@@ -68,6 +68,21 @@ func (l *JSONLogger) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint
 	return nil
 }
 
+// CaptureEnter is triggered when the execution enters a nested call frame.
+func (l *JSONLogger) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureExit is triggered when the execution exits a nested call frame.
+func (l *JSONLogger) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
+// CaptureStorageTxStep outputs the step of the storage contract transaction.
+func (l *JSONLogger) CaptureStorageTxStep(step StorageTxStep) error {
+	return l.encoder.Encode(step)
+}
+
 // CaptureEnd is triggered at end of execution.
 func (l *JSONLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
 	type endLog struct {
@@ -17,11 +17,14 @@
 package vm
 
 import (
+	"fmt"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/DxChainNetwork/godx/common"
 	"github.com/DxChainNetwork/godx/core/state"
+	"github.com/DxChainNetwork/godx/ethdb"
 	"github.com/DxChainNetwork/godx/params"
 )
 
@@ -68,3 +71,53 @@ func TestStoreCapture(t *testing.T) {
 		t.Errorf("expected %x, got %x", exp, logger.changedValues[contract.Address()][index])
 	}
 }
+
+// frameTracer records the nested call frames entered and exited
+type frameTracer struct {
+	*StructLogger
+	frames []string
+}
+
+func (f *frameTracer) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	f.frames = append(f.frames, fmt.Sprintf("%v %x->%x", typ, from[18:], to[18:]))
+	return nil
+}
+
+func (f *frameTracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	f.frames = append(f.frames, "exit")
+	return nil
+}
+
+func TestCaptureCallFrames(t *testing.T) {
+	var (
+		caller = AccountRef(common.HexToAddress("0x1001"))
+		a      = common.HexToAddress("0x1002")
+		b      = common.HexToAddress("0x1003")
+	)
+	ctx := Context{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: big.NewInt(0),
+	}
+	statedb := mockState(ethdb.NewMemDatabase(), nil)
+	// a static calls b, and b calls the non existing account 0x1004
+	statedb.SetCode(a, []byte{
+		byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0,
+		byte(PUSH2), 0x10, 0x03, byte(GAS), byte(STATICCALL), byte(STOP),
+	})
+	statedb.SetCode(b, []byte{
+		byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0,
+		byte(PUSH2), 0x10, 0x04, byte(GAS), byte(CALL), byte(STOP),
+	})
+	tracer := &frameTracer{StructLogger: NewStructLogger(nil)}
+	evm := NewEVM(ctx, statedb, params.TestChainConfig, Config{Debug: true, Tracer: tracer})
+	if _, _, err := evm.Call(caller, a, nil, 1000000, new(big.Int)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the frame at depth 0 is captured by CaptureStart and CaptureEnd
+	want := []string{"STATICCALL 1002->1003", "CALL 1003->1004", "exit", "exit"}
+	if !reflect.DeepEqual(tracer.frames, want) {
+		t.Errorf("call frames not expected. Got %v, want %v", tracer.frames, want)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/DxChainNetwork/godx/crypto"
+)
+
+// revertSelector is the function selector of Error(string), which is used by the
+// solidity revert(string) and require(bool, string) to encode the revert reason
+var revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+var errInvalidRevertReason = errors.New("invalid revert reason payload")
+
+// UnpackRevertReason will ABI-decode the revert reason from the data returned by the
+// REVERT opcode, which is expected to be encoded as Error(string)
+func UnpackRevertReason(data []byte) (string, error) {
+	if len(data) < 4 || !bytes.Equal(data[:4], revertSelector) {
+		return "", errInvalidRevertReason
+	}
+	payload := data[4:]
+
+	// the payload consists of the offset of the string, followed by the
+	// length of the string and the string itself
+	offset, ok := abiWord(payload, 0)
+	if !ok {
+		return "", errInvalidRevertReason
+	}
+	length, ok := abiWord(payload, offset)
+	if !ok {
+		return "", errInvalidRevertReason
+	}
+	start := offset + 32
+	if length > uint64(len(payload)) || start+length > uint64(len(payload)) {
+		return "", errInvalidRevertReason
+	}
+	return string(payload[start : start+length]), nil
+}
+
+// abiWord reads the 32 bytes word at the position as an unsigned integer. If the
+// position is out of range or the value overflows uint64, false will be returned
+func abiWord(data []byte, pos uint64) (uint64, bool) {
+	if pos > uint64(len(data)) || pos+32 > uint64(len(data)) {
+		return 0, false
+	}
+	value := new(big.Int).SetBytes(data[pos : pos+32])
+	if !value.IsUint64() {
+		return 0, false
+	}
+	return value.Uint64(), true
+}
@@ -0,0 +1,66 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+)
+
+func TestUnpackRevertReason(t *testing.T) {
+	tests := []struct {
+		data   string
+		reason string
+		valid  bool
+	}{
+		// revert("not enough funds")
+		{
+			data: "08c379a0" +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000010" +
+				"6e6f7420656e6f7567682066756e647300000000000000000000000000000000",
+			reason: "not enough funds",
+			valid:  true,
+		},
+		// revert("")
+		{
+			data: "08c379a0" +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000000",
+			reason: "",
+			valid:  true,
+		},
+		// plain revert without reason
+		{data: "", valid: false},
+		// unknown selector
+		{data: "deadbeef0000000000000000000000000000000000000000000000000000000000000020", valid: false},
+		// string length exceeds the payload
+		{
+			data: "08c379a0" +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000040" +
+				"6e6f7420656e6f7567682066756e647300000000000000000000000000000000",
+			valid: false,
+		},
+		// offset overflows
+		{
+			data: "08c379a0" +
+				"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+			valid: false,
+		},
+	}
+
+	for i, test := range tests {
+		reason, err := UnpackRevertReason(common.Hex2Bytes(test.data))
+		if test.valid != (err == nil) {
+			t.Errorf("test %d: expected valid %v, got error %v", i, test.valid, err)
+			continue
+		}
+		if reason != test.reason {
+			t.Errorf("test %d: expected reason %q, got %q", i, test.reason, reason)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"errors"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/params"
+	"github.com/DxChainNetwork/godx/rlp"
+)
+
+// The storage contract id precompile lets the smart contracts derive the id of a storage
+// contract the same way as the clients do off-chain, so that the smart contracts can verify
+// the storage contracts provided and refer to them by id. The input is the RLP encoding of
+// the storage contract, and the output is the 32 bytes storage contract id. The precompile
+// is only available after the StorageIDBlock fork.
+
+// StorageContractIDAddress is the address of the storage contract id precompile, next to the
+// addresses of the storage contract transactions
+var StorageContractIDAddress = common.BytesToAddress([]byte{18})
+
+var errInvalidStorageContract = errors.New("the input shall be the RLP encoded storage contract")
+
+// storageContractID is the precompiled contract deriving the storage contract id
+type storageContractID struct{}
+
+// RequiredGas returns the gas required to decode the storage contract and hash it
+func (c *storageContractID) RequiredGas(input []byte) uint64 {
+	return params.StorageContractIDGas + uint64(len(input)+31)/32*params.Sha3WordGas
+}
+
+// Run returns the id of the RLP encoded storage contract
+func (c *storageContractID) Run(input []byte) ([]byte, error) {
+	var sc types.StorageContract
+	if err := rlp.DecodeBytes(input, &sc); err != nil {
+		return nil, errInvalidStorageContract
+	}
+	id := sc.ID()
+	return id[:], nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/params"
+	"github.com/DxChainNetwork/godx/rlp"
+)
+
+func TestStorageContractIDPrecompile(t *testing.T) {
+	evm, _, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, err := mockStorageContract(prvAndAddresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlpBytes, err := rlp.EncodeToBytes(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller := AccountRef(prvAndAddresses[0].Address)
+
+	// the precompile is not available before the fork
+	if evm.storageContractPrecompile(StorageContractIDAddress) != nil {
+		t.Fatal("the storage contract id precompile shall not be available before the fork")
+	}
+	config := *params.MainnetChainConfig
+	config.StorageIDBlock = big.NewInt(1000)
+	evm.chainConfig = &config
+
+	ret, leftOverGas, err := evm.StaticCall(caller, StorageContractIDAddress, rlpBytes, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scID := sc.ID()
+	if !bytes.Equal(ret, scID[:]) {
+		t.Errorf("expect storage contract id %x, got %x", scID, ret)
+	}
+	if gas := (&storageContractID{}).RequiredGas(rlpBytes); 100000-leftOverGas != gas {
+		t.Errorf("expect %v gas used, got %v", gas, 100000-leftOverGas)
+	}
+
+	// the input must be the RLP encoded storage contract
+	if _, _, err := evm.StaticCall(caller, StorageContractIDAddress, rlpBytes[:len(rlpBytes)/2], 100000); err != errInvalidStorageContract {
+		t.Errorf("expect error %v, got %v", errInvalidStorageContract, err)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/rlp"
+	"github.com/DxChainNetwork/godx/storage/coinchargemaintenance"
+)
+
+// TestStorageContractStateRevert checks that the storage contract state lives in the storage
+// slots of the contract account only, so that it is reverted along with the state snapshot
+func TestStorageContractStateRevert(t *testing.T) {
+	evm, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, err := mockStorageContract(prvAndAddresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlpBytes, err := rlp.EncodeToBytes(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scID := sc.ID()
+	contractAddr := common.BytesToAddress(scID[12:])
+
+	snapshot := stateDB.Snapshot()
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err != nil {
+		t.Fatal(err)
+	}
+	windowStart := stateDB.GetState(contractAddr, coinchargemaintenance.KeyWindowStart)
+	if windowStart.Big().Uint64() != sc.WindowStart {
+		t.Fatalf("expect the window start %v stored in the contract account, got %v", sc.WindowStart, windowStart.Big())
+	}
+
+	stateDB.RevertToSnapshot(snapshot)
+	if stateDB.Exist(contractAddr) {
+		t.Error("the contract account shall be removed with the snapshot reverted")
+	}
+	if stateDB.GetState(contractAddr, coinchargemaintenance.KeyClientCollateral) != (common.Hash{}) {
+		t.Error("the contract state shall be reverted with the snapshot")
+	}
+
+	// the contract can be created again once reverted
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err != nil {
+		t.Fatalf("failed to create the contract again after the revert: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/params"
+	"github.com/DxChainNetwork/godx/storage/coinchargemaintenance"
+)
+
+// The storage contract status precompile lets the smart contracts read the storage contract
+// fields by the contract id, so that the DApps can build the insurance or escrow logic on top
+// of the storage contracts. The input is the 32 bytes contract id, and the output is the ABI
+// encoding of (uint8 status, uint64 fileSize, bytes32 fileMerkleRoot, uint64 windowStart,
+// uint64 windowEnd, uint64 revisionNumber), which is all zero if the contract does not exist.
+// Unlike the storage contract transactions, the precompile is run by the EVM calls, and is
+// only available after the StorageStatusBlock fork.
+
+// the status of the storage contract returned by the precompile
+const (
+	StorageContractStatusNone    = 0 // the contract does not exist
+	StorageContractStatusActive  = 1 // the storage proof is not submitted yet
+	StorageContractStatusProofed = 2 // the storage proof is submitted within the proof window
+	StorageContractStatusExpired = 3 // the proof window ended and the contract is settled
+)
+
+// storageContractStatusOutputLen is the length of the output, 6 words in ABI encoding
+const storageContractStatusOutputLen = 6 * common.HashLength
+
+// StorageContractStatusAddress is the address of the storage contract status precompile,
+// next to the addresses of the storage contract transactions
+var StorageContractStatusAddress = common.BytesToAddress([]byte{14})
+
+var errInvalidStorageContractID = errors.New("the input shall be the 32 bytes storage contract id")
+
+// storageContractStatus is the precompiled contract reading the storage contract status from
+// the state
+type storageContractStatus struct {
+	state StateDB
+}
+
+// storageContractPrecompile returns the precompiled contract of the storage contracts at the
+// address, nil if there is none or it is not activated yet
+func (evm *EVM) storageContractPrecompile(addr common.Address) PrecompiledContract {
+	switch {
+	case addr == StorageContractStatusAddress && evm.ChainConfig().IsStorageStatus(evm.BlockNumber):
+		return &storageContractStatus{state: evm.StateDB}
+	case addr == StorageContractIDAddress && evm.ChainConfig().IsStorageID(evm.BlockNumber):
+		return &storageContractID{}
+	}
+	return nil
+}
+
+// RequiredGas returns the gas required to query the storage contract status
+func (c *storageContractStatus) RequiredGas(input []byte) uint64 {
+	return params.StorageContractStatusGas
+}
+
+// Run returns the status and the fields of the storage contract with the id provided
+func (c *storageContractStatus) Run(input []byte) ([]byte, error) {
+	if len(input) != common.HashLength {
+		return nil, errInvalidStorageContractID
+	}
+	scID := common.BytesToHash(input)
+	contractAddr := common.BytesToAddress(scID[12:])
+	output := make([]byte, storageContractStatusOutputLen)
+
+	// the window end of the storage contract is never zero
+	windowEnd := c.state.GetState(contractAddr, coinchargemaintenance.KeyWindowEnd)
+	if windowEnd == (common.Hash{}) {
+		return output, nil
+	}
+
+	// the status is removed along with the status account once the proof window ended
+	status := StorageContractStatusExpired
+	windowEndStr := strconv.FormatUint(windowEnd.Big().Uint64(), 10)
+	statusAddr := common.BytesToAddress([]byte(coinchargemaintenance.StrPrefixExpSC + windowEndStr))
+	switch flag := c.state.GetState(statusAddr, scID); flag[11] {
+	case coinchargemaintenance.NotProofedStatus[0]:
+		status = StorageContractStatusActive
+	case coinchargemaintenance.ProofedStatus[0]:
+		status = StorageContractStatusProofed
+	}
+
+	output[common.HashLength-1] = byte(status)
+	fields := []common.Hash{
+		c.state.GetState(contractAddr, coinchargemaintenance.KeyFileSize),
+		c.state.GetState(contractAddr, coinchargemaintenance.KeyFileMerkleRoot),
+		c.state.GetState(contractAddr, coinchargemaintenance.KeyWindowStart),
+		windowEnd,
+		c.state.GetState(contractAddr, coinchargemaintenance.KeyRevisionNumber),
+	}
+	for i, field := range fields {
+		copy(output[(i+1)*common.HashLength:], field[:])
+	}
+	return output, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/params"
+	"github.com/DxChainNetwork/godx/rlp"
+	"github.com/DxChainNetwork/godx/storage/coinchargemaintenance"
+)
+
+func TestStorageContractStatusPrecompile(t *testing.T) {
+	evm, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, err := mockStorageContract(prvAndAddresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlpBytes, err := rlp.EncodeToBytes(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err != nil {
+		t.Fatal(err)
+	}
+	scID := sc.ID()
+	caller := AccountRef(prvAndAddresses[0].Address)
+
+	// the precompile is not available before the fork
+	if evm.storageContractPrecompile(StorageContractStatusAddress) != nil {
+		t.Fatal("the storage contract status precompile shall not be available before the fork")
+	}
+	config := *params.MainnetChainConfig
+	config.StorageStatusBlock = big.NewInt(1000)
+	evm.chainConfig = &config
+
+	ret, leftOverGas, err := evm.StaticCall(caller, StorageContractStatusAddress, scID[:], 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if 100000-leftOverGas != params.StorageContractStatusGas {
+		t.Errorf("expect %v gas used, got %v", params.StorageContractStatusGas, 100000-leftOverGas)
+	}
+	expected := []uint64{StorageContractStatusActive, sc.FileSize, 0, sc.WindowStart, sc.WindowEnd, sc.RevisionNumber}
+	checkStorageContractStatus(t, ret, expected, sc.FileMerkleRoot)
+
+	// the proofed status is read from the status account of the window end
+	statusAddr := common.BytesToAddress([]byte(coinchargemaintenance.StrPrefixExpSC + strconv.FormatUint(sc.WindowEnd, 10)))
+	contractAddr := common.BytesToAddress(scID[12:])
+	stateDB.SetState(statusAddr, scID, common.BytesToHash(append(coinchargemaintenance.ProofedStatus, contractAddr[:]...)))
+	ret, _, err = evm.StaticCall(caller, StorageContractStatusAddress, scID[:], 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected[0] = StorageContractStatusProofed
+	checkStorageContractStatus(t, ret, expected, sc.FileMerkleRoot)
+
+	// the status is expired once the status account is removed
+	stateDB.SetState(statusAddr, scID, common.Hash{})
+	ret, _, _ = evm.StaticCall(caller, StorageContractStatusAddress, scID[:], 100000)
+	expected[0] = StorageContractStatusExpired
+	checkStorageContractStatus(t, ret, expected, sc.FileMerkleRoot)
+
+	// the unknown contract is all zero
+	ret, _, err = evm.StaticCall(caller, StorageContractStatusAddress, common.Hash{1}.Bytes(), 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStorageContractStatus(t, ret, make([]uint64, 6), common.Hash{})
+
+	// the input must be the contract id
+	if _, _, err := evm.StaticCall(caller, StorageContractStatusAddress, scID[:16], 100000); err != errInvalidStorageContractID {
+		t.Errorf("expect error %v, got %v", errInvalidStorageContractID, err)
+	}
+}
+
+// checkStorageContractStatus checks the output of the storage contract status precompile. The
+// merkle root is checked separately, with its expected word ignored
+func checkStorageContractStatus(t *testing.T, ret []byte, expected []uint64, root common.Hash) {
+	t.Helper()
+	if len(ret) != storageContractStatusOutputLen {
+		t.Fatalf("expect %v bytes of output, got %v", storageContractStatusOutputLen, len(ret))
+	}
+	for i, value := range expected {
+		word := ret[i*common.HashLength : (i+1)*common.HashLength]
+		if i == 2 {
+			if common.BytesToHash(word) != root {
+				t.Errorf("expect merkle root %x, got %x", root, word)
+			}
+			continue
+		}
+		if got := new(big.Int).SetBytes(word); !got.IsUint64() || got.Uint64() != value {
+			t.Errorf("word %v: expect %v, got %v", i, value, got)
+		}
+	}
+}
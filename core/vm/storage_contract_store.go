@@ -0,0 +1,45 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/rawdb"
+)
+
+// StorageContractStore provides the chain data required by the storage contract
+// transactions, so that the handlers do not need to reach through the StateDB
+// into the underlying database. Implementations must be safe for concurrent use
+type StorageContractStore interface {
+	// CanonicalHash returns the hash of the canonical block at the height. If the
+	// block is not found, empty hash will be returned
+	CanonicalHash(number uint64) common.Hash
+}
+
+// databaseStorageContractStore is the StorageContractStore reading the chain data
+// from the chain database directly
+type databaseStorageContractStore struct {
+	db rawdb.DatabaseReader
+}
+
+// NewDatabaseStorageContractStore creates the StorageContractStore reading the chain
+// data from the database provided
+func NewDatabaseStorageContractStore(db rawdb.DatabaseReader) StorageContractStore {
+	return databaseStorageContractStore{db: db}
+}
+
+// CanonicalHash returns the hash of the canonical block at the height
+func (s databaseStorageContractStore) CanonicalHash(number uint64) common.Hash {
+	return rawdb.ReadCanonicalHash(s.db, number)
+}
+
+// storageContractStore returns the StorageContractStore injected through the context.
+// If not provided, the store reading from the database of the StateDB is used
+func (evm *EVM) storageContractStore() StorageContractStore {
+	if evm.Context.StorageContractStore != nil {
+		return evm.Context.StorageContractStore
+	}
+	return NewDatabaseStorageContractStore(evm.StateDB.Database().TrieDB().DiskDB())
+}
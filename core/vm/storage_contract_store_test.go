@@ -0,0 +1,49 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/rawdb"
+	"github.com/DxChainNetwork/godx/ethdb"
+)
+
+type mockStorageContractStore map[uint64]common.Hash
+
+func (s mockStorageContractStore) CanonicalHash(number uint64) common.Hash {
+	return s[number]
+}
+
+func TestEVM_StorageContractStore(t *testing.T) {
+	evm, stateDB, _, err := mockEvmAndState(1101)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without the store injected, the hash is read from the chain database
+	db := stateDB.Database().TrieDB().DiskDB().(ethdb.Database)
+	dbHash := common.HexToHash("0x877c3a381d5ad88ca76a7b3e33ab1611939de59c56c0506efb9021593618f6ab")
+	rawdb.WriteCanonicalHash(db, dbHash, 1000)
+	if hash := evm.storageContractStore().CanonicalHash(1000); hash != dbHash {
+		t.Errorf("expected hash %x from the database, got %x", dbHash, hash)
+	}
+
+	// the store injected through the context takes precedence
+	storeHash := common.HexToHash("0x01")
+	evm.Context.StorageContractStore = mockStorageContractStore{1000: storeHash}
+	if hash := evm.storageContractStore().CanonicalHash(1000); hash != storeHash {
+		t.Errorf("expected hash %x from the injected store, got %x", storeHash, hash)
+	}
+
+	// segment index cannot be calculated without the trigger block
+	if _, err := storageProofSegment(evm.storageContractStore(), 900, 1<<22, common.Hash{}, 1101); err == nil {
+		t.Error("expected error for the missing trigger block")
+	}
+	if _, err := storageProofSegment(evm.storageContractStore(), 1001, 1<<22, common.Hash{}, 1101); err != nil {
+		t.Errorf("failed to calculate the storage proof segment: %v", err)
+	}
+}
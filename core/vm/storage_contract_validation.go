@@ -16,13 +16,12 @@ import (
 	"strconv"
 
 	"github.com/DxChainNetwork/godx/common"
-	"github.com/DxChainNetwork/godx/core/rawdb"
 	"github.com/DxChainNetwork/godx/core/types"
 	"github.com/DxChainNetwork/godx/crypto"
 	"github.com/DxChainNetwork/godx/crypto/merkle"
-	"github.com/DxChainNetwork/godx/ethdb"
 	"github.com/DxChainNetwork/godx/log"
 	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/params"
 	"github.com/DxChainNetwork/godx/storage/coinchargemaintenance"
 )
 
@@ -42,10 +41,130 @@ var (
 	errNoStorageContractType                   = errors.New("no this storage contract type")
 	errInvalidStorageProof                     = errors.New("invalid storage proof")
 	errUnfinishedStorageContract               = errors.New("storage contract has not yet opened")
+	errEscrowNotTreasury                       = errors.New("the escrow fund must be sent by the treasury")
+	errEscrowInvalidAmount                     = errors.New("the escrow fund amount and spending cap must not be negative")
+	errEscrowNotRenter                         = errors.New("the storage contract paid by the escrow account is not signed by its renter")
+	errEscrowSpendingCapExceeded               = errors.New("the storage contract collateral exceeds the spending cap of the escrow account")
+	errHostRevocationExpired                   = errors.New("the host revocation is not sent within the validity after its block number")
+	errInvalidStorageProofBatch                = errors.New("the number of storage proofs in the batch is out of range")
+	errNoRenewedContract                       = errors.New("the storage contract renewed does not exist")
+	errRenewedContractProofed                  = errors.New("can not renew the storage contract after storage proof")
+	errLateRenewal                             = errors.New("storage contract renewal submitted after the proof window opened")
+	errRenewalParties                          = errors.New("the renewed storage contract is not between the same client and host")
+	errRenewalFile                             = errors.New("the renewed storage contract does not store the same file")
+	errRenewalWindowEnd                        = errors.New("the renewed storage contract must end after the storage contract renewed")
+	errInvalidSignaturesRequired               = errors.New("the signatures required must be between 1 and the number of the payment addresses")
+	errNotEnoughSignatures                     = errors.New("the storage contract is signed by fewer parties than required")
+	errUnknownSigner                           = errors.New("the signature is not signed by the remaining parties of the unlock conditions")
 )
 
-// CheckCreateContract checks whether a new StorageContract is valid
-func CheckCreateContract(state StateDB, sc types.StorageContract, currentHeight uint64) error {
+// MaxStorageProofBatchSize is the max number of storage proofs in a batch storage proof tx
+const MaxStorageProofBatchSize = 256
+
+// HostRevocationValidity is the number of blocks the host revocation is valid after its block
+// number, which limits the replay of the revocation
+const HostRevocationValidity = 100
+
+// CheckCreateContract checks whether a new StorageContract is valid. The spending from the
+// escrow account is only checked from the escrow fund fork, and the signatures are checked
+// against the M-of-N unlock conditions from the multi-signature fork
+func CheckCreateContract(state StateDB, sc types.StorageContract, currentHeight uint64, rules params.Rules) error {
+	if err := checkContractTerms(sc, currentHeight); err != nil {
+		return err
+	}
+
+	// check if balance is enough for collateral
+	clientAddr := sc.ClientCollateral.Address
+	clientCollateralAmount := sc.ClientCollateral.Value
+	hostAddr := sc.HostCollateral.Address
+	hostCollateralAmount := sc.HostCollateral.Value
+
+	clientBalance := state.GetBalance(clientAddr)
+	if clientBalance.Cmp(clientCollateralAmount) == -1 {
+		return errors.New("client has not enough balance for storage contract collateral")
+	}
+
+	if rules.IsEscrowFund {
+		if err := CheckEscrowSpending(state, sc); err != nil {
+			return err
+		}
+	}
+
+	hostBalance := state.GetBalance(hostAddr)
+	if hostBalance.Cmp(hostCollateralAmount) == -1 {
+		return errors.New("host has not enough balance for storage contract collateral")
+	}
+
+	err := checkSignatures(sc, sc.Signatures, rules)
+	if err != nil {
+		log.Error("failed to check signature for create contract", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+// CheckRenewContract checks whether the StorageContractRenewal is valid. The old contract must
+// be still open before its proof window, and the new contract must store the same file between
+// the same client and host, ending after the old one. The renewal signatures authorize both
+// closing the old contract and creating the new one, so the signatures of the new contract are
+// not checked. The collateral balances are not checked either, as the outputs of the old
+// contract are returned before the collateral of the new contract is paid. The spending from
+// the escrow account is only checked from the escrow fund fork
+func CheckRenewContract(state StateDB, renewal types.StorageContractRenewal, currentHeight uint64, rules params.Rules) error {
+	oldAddr := common.BytesToAddress(renewal.OldContractID[12:])
+	if !state.Exist(oldAddr) {
+		return errNoRenewedContract
+	}
+
+	oldWindowStart := new(big.Int).SetBytes(state.GetState(oldAddr, coinchargemaintenance.KeyWindowStart).Bytes()).Uint64()
+	oldWindowEnd := new(big.Int).SetBytes(state.GetState(oldAddr, coinchargemaintenance.KeyWindowEnd).Bytes()).Uint64()
+	statusAddr := common.BytesToAddress([]byte(coinchargemaintenance.StrPrefixExpSC + strconv.FormatUint(oldWindowEnd, 10)))
+	flag := state.GetState(statusAddr, renewal.OldContractID).Bytes()[11:12]
+	if bytes.Equal(flag, coinchargemaintenance.ProofedStatus) {
+		return errRenewedContractProofed
+	}
+	if currentHeight >= oldWindowStart {
+		return errLateRenewal
+	}
+
+	sc := renewal.NewContract
+	clientAddr := common.BytesToAddress(state.GetState(oldAddr, coinchargemaintenance.KeyClientAddress).Bytes())
+	hostAddr := common.BytesToAddress(state.GetState(oldAddr, coinchargemaintenance.KeyHostAddress).Bytes())
+	if sc.ClientCollateral.Address != clientAddr || sc.HostCollateral.Address != hostAddr {
+		return errRenewalParties
+	}
+	if sc.UnlockHash != state.GetState(oldAddr, coinchargemaintenance.KeyUnlockHash) {
+		return errWrongUnlockCondition
+	}
+
+	fileSize := new(big.Int).SetBytes(state.GetState(oldAddr, coinchargemaintenance.KeyFileSize).Bytes()).Uint64()
+	if sc.FileSize != fileSize || sc.FileMerkleRoot != state.GetState(oldAddr, coinchargemaintenance.KeyFileMerkleRoot) {
+		return errRenewalFile
+	}
+	if sc.WindowEnd <= oldWindowEnd {
+		return errRenewalWindowEnd
+	}
+
+	if err := checkContractTerms(sc, currentHeight); err != nil {
+		return err
+	}
+	if rules.IsEscrowFund {
+		if err := checkEscrowSpending(state, sc, renewal.RLPHash(), renewal.Signatures); err != nil {
+			return err
+		}
+	}
+
+	if err := checkSignatures(renewal, renewal.Signatures, rules); err != nil {
+		log.Error("failed to check signature for renew contract", "err", err)
+		return err
+	}
+	return nil
+}
+
+// checkContractTerms checks the collaterals, the proof window and the proof outputs of the
+// storage contract
+func checkContractTerms(sc types.StorageContract, currentHeight uint64) error {
 	if sc.ClientCollateral.Value.Sign() <= 0 {
 		return errZeroCollateral
 	}
@@ -89,34 +208,65 @@ func CheckCreateContract(state StateDB, sc types.StorageContract, currentHeight
 	if missedProofOutputSum.Cmp(payout) > 0 {
 		return errStorageContractMissedOutputSumViolation
 	}
+	return nil
+}
 
-	// check if balance is enough for collateral
-	clientAddr := sc.ClientCollateral.Address
-	clientCollateralAmount := sc.ClientCollateral.Value
-	hostAddr := sc.HostCollateral.Address
-	hostCollateralAmount := sc.HostCollateral.Value
-
-	clientBalance := state.GetBalance(clientAddr)
-	if clientBalance.Cmp(clientCollateralAmount) == -1 {
-		return errors.New("client has not enough balance for storage contract collateral")
+// CheckEscrowFund checks whether the escrow fund is sent by the treasury with enough balance
+func CheckEscrowFund(state StateDB, sender common.Address, se types.StorageEscrow) error {
+	if sender != se.Treasury {
+		return errEscrowNotTreasury
+	}
+	if se.Amount == nil || se.SpendingCap == nil || se.Amount.Sign() < 0 || se.SpendingCap.Sign() < 0 {
+		return errEscrowInvalidAmount
+	}
+	if state.GetBalance(se.Treasury).Cmp(se.Amount) == -1 {
+		return errors.New("treasury has not enough balance for escrow fund")
 	}
+	return nil
+}
 
-	hostBalance := state.GetBalance(hostAddr)
-	if hostBalance.Cmp(hostCollateralAmount) == -1 {
-		return errors.New("host has not enough balance for storage contract collateral")
+// CheckEscrowSpending checks the storage contract whose client collateral is paid by an escrow
+// account. The contract must be signed by the renter of the escrow account, and the total
+// collateral spent from the escrow account must not exceed its spending cap
+func CheckEscrowSpending(state StateDB, sc types.StorageContract) error {
+	return checkEscrowSpending(state, sc, sc.RLPHash(), sc.Signatures)
+}
+
+// checkEscrowSpending checks the escrow spending of the storage contract, whose client signed
+// the hash with the first of the signatures
+func checkEscrowSpending(state StateDB, sc types.StorageContract, signedHash common.Hash, signatures [][]byte) error {
+	escrowAddr := sc.ClientCollateral.Address
+	if !isEscrowAccount(state, escrowAddr) {
+		return nil
+	}
+	if len(signatures) == 0 {
+		return errEscrowNotRenter
 	}
 
-	err := CheckMultiSignatures(sc, sc.Signatures)
+	clientPubkey, err := crypto.SigToPub(signedHash.Bytes(), signatures[0])
 	if err != nil {
-		log.Error("failed to check signature for create contract", "err", err)
 		return err
 	}
+	renter := common.BytesToAddress(state.GetState(escrowAddr, coinchargemaintenance.KeyEscrowRenter).Bytes())
+	if crypto.PubkeyToAddress(*clientPubkey) != renter {
+		return errEscrowNotRenter
+	}
 
+	spendingCap := new(big.Int).SetBytes(state.GetState(escrowAddr, coinchargemaintenance.KeyEscrowSpendingCap).Bytes())
+	spent := new(big.Int).SetBytes(state.GetState(escrowAddr, coinchargemaintenance.KeyEscrowSpent).Bytes())
+	if spent.Add(spent, sc.ClientCollateral.Value).Cmp(spendingCap) > 0 {
+		return errEscrowSpendingCapExceeded
+	}
 	return nil
 }
 
+// isEscrowAccount checks whether the address is an escrow account created by the escrow fund
+func isEscrowAccount(state StateDB, addr common.Address) bool {
+	return state.GetState(addr, coinchargemaintenance.KeyEscrowRenter) != (common.Hash{})
+}
+
 // CheckRevisionContract checks whether a new StorageContractRevision is valid
-func CheckRevisionContract(state StateDB, scr types.StorageContractRevision, currentHeight uint64, contractAddr common.Address) error {
+func CheckRevisionContract(state StateDB, scr types.StorageContractRevision, currentHeight uint64, contractAddr common.Address, rules params.Rules) error {
 
 	// check whether it has proofed
 	windowEndStr := strconv.FormatUint(scr.NewWindowEnd, 10)
@@ -157,7 +307,7 @@ func CheckRevisionContract(state StateDB, scr types.StorageContractRevision, cur
 		return errRevisionOutputSumViolation
 	}
 
-	if err := CheckMultiSignatures(scr, scr.Signatures); err != nil {
+	if err := checkSignatures(scr, scr.Signatures, rules); err != nil {
 		return err
 	}
 
@@ -216,79 +366,206 @@ func CheckRevisionContract(state StateDB, scr types.StorageContractRevision, cur
 	return nil
 }
 
-// CheckMultiSignatures checks whether a new StorageContractRevision is valid
-func CheckMultiSignatures(originalData types.StorageContractRLPHash, signatures [][]byte) error {
-	if len(signatures) == 0 {
-		return errors.New("no signatures for verification")
+// CheckHostRevocation checks whether the host revocation is sent within HostRevocationValidity
+// blocks after the block number signed
+func CheckHostRevocation(hr types.HostRevocation, currentHeight uint64) error {
+	if hr.BlockNumber > currentHeight || currentHeight-hr.BlockNumber > HostRevocationValidity {
+		return errHostRevocationExpired
 	}
+	return nil
+}
 
-	var (
-		singleSig, clientSig, hostSig []byte
-		clientPubkey, hostPubkey      *ecdsa.PublicKey
-		err                           error
-		uc                            types.UnlockConditions
-	)
+// checkHostNodeKey checks whether the host net address is generated by the node of the key
+func checkHostNodeKey(netAddress string, key *ecdsa.PublicKey) error {
+	hostNode, err := enode.ParseV4(netAddress)
+	if err != nil {
+		return fmt.Errorf("invalid host announce address: %v", err)
+	}
+	if !crypto.IsEqualPublicKey(key, hostNode.Pubkey()) {
+		return fmt.Errorf("announced host net address is not generated by self hostnode")
+	}
+	return nil
+}
+
+// checkSignatures checks the signatures of the storage contract tx with the rules of the block.
+// The M-of-N unlock conditions are only checked from the multi-signature fork
+func checkSignatures(originalData types.StorageContractRLPHash, signatures [][]byte, rules params.Rules) error {
+	if rules.IsMultiSig {
+		return CheckMultiSignatures(originalData, signatures)
+	}
+	return checkLegacySignatures(originalData, signatures)
+}
 
+// checkLegacySignatures checks the signatures of the storage contract tx before the
+// multi-signature fork. A single signature is only checked to be recoverable, and the host
+// key is checked for the host announcement and revocation. Two signatures are taken as the
+// ones of the client and the host, whose unlock conditions must match the unlock hash
+func checkLegacySignatures(originalData types.StorageContractRLPHash, signatures [][]byte) error {
+	if len(signatures) == 0 {
+		return errors.New("no signatures for verification")
+	}
 	dataHash := originalData.RLPHash()
 
 	if len(signatures) == 1 {
-		singleSig = signatures[0]
-
 		// if we can recover the public key, indicate that check sig is ok
-		recoverKey, err := crypto.SigToPub(dataHash.Bytes(), singleSig)
+		recoverKey, err := crypto.SigToPub(dataHash.Bytes(), signatures[0])
 		if err != nil {
 			return err
 		}
+		switch data := originalData.(type) {
+		case types.HostAnnouncement:
+			return checkHostNodeKey(data.NetAddress, recoverKey)
+		case types.HostRevocation:
+			return checkHostNodeKey(data.NetAddress, recoverKey)
+		}
+		return nil
+	}
+	if len(signatures) != 2 {
+		return nil
+	}
+
+	clientAddr, err := recoverSigner(dataHash, signatures[0])
+	if err != nil {
+		return err
+	}
+	hostAddr, err := recoverSigner(dataHash, signatures[1])
+	if err != nil {
+		return err
+	}
+	uc := types.UnlockConditions{
+		PaymentAddresses:   []common.Address{clientAddr, hostAddr},
+		SignaturesRequired: 2,
+	}
+
+	var unlockHash common.Hash
+	switch data := originalData.(type) {
+	case types.StorageContract:
+		unlockHash = data.UnlockHash
+	case types.StorageContractRevision:
+		unlockHash = data.NewUnlockHash
+	case types.StorageContractRenewal:
+		unlockHash = data.NewContract.UnlockHash
+	default:
+		return errNoStorageContractType
+	}
+	if uc.UnlockHash() != unlockHash {
+		return errWrongUnlockCondition
+	}
+	return nil
+}
+
+// CheckMultiSignatures checks the signatures of the storage contract tx. The host announcement,
+// the host revocation and the storage proof are signed by a single key. The storage contract
+// revision is signed by at least SignaturesRequired parties of its unlock conditions. As the
+// storage contract and the renewal only commit to the unlock hash, they are signed by every
+// party of the unlock conditions, and the threshold is the one matching the unlock hash
+func CheckMultiSignatures(originalData types.StorageContractRLPHash, signatures [][]byte) error {
+	if len(signatures) == 0 {
+		return errors.New("no signatures for verification")
+	}
+
+	dataHash := originalData.RLPHash()
+
+	switch data := originalData.(type) {
+	case types.StorageContract:
+		return checkContractSigners(dataHash, data.UnlockHash, signatures)
+	case types.StorageContractRenewal:
+		return checkContractSigners(dataHash, data.NewContract.UnlockHash, signatures)
+	case types.StorageContractRevision:
+		// the unlock conditions of the storage contract are not changed by the revision
+		if data.UnlockConditions.UnlockHash() != data.NewUnlockHash {
+			return errWrongUnlockCondition
+		}
+		return CheckUnlockConditions(dataHash, data.UnlockConditions, signatures)
+	}
 
-		// if it's a host announce, we must check the node public key is equal to the recover key
-		if ha, ok := originalData.(types.HostAnnouncement); ok {
-			hostNode, err := enode.ParseV4(ha.NetAddress)
-			if err != nil {
-				return fmt.Errorf("invalid host announce address: %v", err)
-			}
-
-			urlKey := hostNode.Pubkey()
-			if !crypto.IsEqualPublicKey(recoverKey, urlKey) {
-				return fmt.Errorf("announced host net address is not generated by self hostnode")
-			}
+	if len(signatures) != 1 {
+		return errNoStorageContractType
+	}
+
+	// if we can recover the public key, indicate that check sig is ok
+	recoverKey, err := crypto.SigToPub(dataHash.Bytes(), signatures[0])
+	if err != nil {
+		return err
+	}
+
+	// if it's a host announce or revocation, we must check the node public key is equal to the recover key
+	switch data := originalData.(type) {
+	case types.HostAnnouncement:
+		if err := checkHostNodeKey(data.NetAddress, recoverKey); err != nil {
+			return err
 		}
-	} else if len(signatures) == 2 {
-		clientSig = signatures[0]
-		hostSig = signatures[1]
-		clientPubkey, err = crypto.SigToPub(dataHash.Bytes(), clientSig)
-		if err != nil {
+	case types.HostRevocation:
+		if err := checkHostNodeKey(data.NetAddress, recoverKey); err != nil {
 			return err
 		}
-		hostPubkey, err = crypto.SigToPub(dataHash.Bytes(), hostSig)
+	}
+	return nil
+}
+
+// CheckUnlockConditions checks whether the data hash is signed by at least SignaturesRequired
+// parties of the unlock conditions. The signatures must be in the order of the payment addresses
+// of the signers, so that no party could sign twice
+func CheckUnlockConditions(dataHash common.Hash, uc types.UnlockConditions, signatures [][]byte) error {
+	if uc.SignaturesRequired == 0 || uc.SignaturesRequired > uint64(len(uc.PaymentAddresses)) {
+		return errInvalidSignaturesRequired
+	}
+	if uint64(len(signatures)) < uc.SignaturesRequired {
+		return errNotEnoughSignatures
+	}
+
+	next := 0
+	for _, sig := range signatures {
+		signer, err := recoverSigner(dataHash, sig)
 		if err != nil {
 			return err
 		}
-
-		uc = types.UnlockConditions{
-			PaymentAddresses:   []common.Address{crypto.PubkeyToAddress(*clientPubkey), crypto.PubkeyToAddress(*hostPubkey)},
-			SignaturesRequired: 2,
+		for next < len(uc.PaymentAddresses) && uc.PaymentAddresses[next] != signer {
+			next++
+		}
+		if next == len(uc.PaymentAddresses) {
+			return errUnknownSigner
 		}
+		next++
+	}
+	return nil
+}
 
-		originUnlockHash := common.Hash{}
-		switch dataType := originalData.(type) {
-		case types.StorageContract:
-			originUnlockHash = dataType.UnlockHash
-		case types.StorageContractRevision:
-			originUnlockHash = dataType.NewUnlockHash
-		default:
-			return errNoStorageContractType
+// checkContractSigners checks whether the storage contract is signed by every party of the
+// unlock conditions matching the unlock hash. The signers are the payment addresses in the
+// order of the signatures, and the threshold is searched from 1 to the number of the signers
+func checkContractSigners(dataHash common.Hash, unlockHash common.Hash, signatures [][]byte) error {
+	uc := types.UnlockConditions{
+		PaymentAddresses: make([]common.Address, 0, len(signatures)),
+	}
+	for _, sig := range signatures {
+		signer, err := recoverSigner(dataHash, sig)
+		if err != nil {
+			return err
 		}
+		uc.PaymentAddresses = append(uc.PaymentAddresses, signer)
+	}
 
-		if uc.UnlockHash() != originUnlockHash {
-			return errWrongUnlockCondition
+	for required := uint64(len(signatures)); required > 0; required-- {
+		uc.SignaturesRequired = required
+		if uc.UnlockHash() == unlockHash {
+			return CheckUnlockConditions(dataHash, uc, signatures)
 		}
 	}
+	return errWrongUnlockCondition
+}
 
-	return nil
+// recoverSigner recovers the address signing the data hash
+func recoverSigner(dataHash common.Hash, sig []byte) (common.Address, error) {
+	pubkey, err := crypto.SigToPub(dataHash.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
 }
 
 // CheckStorageProof checks whether a new StorageProof is valid
-func CheckStorageProof(state StateDB, sp types.StorageProof, currentHeight uint64, statusAddr common.Address, contractAddr common.Address) error {
+func CheckStorageProof(state StateDB, store StorageContractStore, sp types.StorageProof, currentHeight uint64, statusAddr common.Address, contractAddr common.Address) error {
 
 	// check whether it proofed repeatedly
 	statusContent := state.GetState(statusAddr, sp.ParentID)
@@ -326,7 +603,7 @@ func CheckStorageProof(state StateDB, sp types.StorageProof, currentHeight uint6
 
 	// check that the storage proof itself is valid.
 
-	segmentIndex, err := storageProofSegment(state, windowStart, fileSize, sp.ParentID, currentHeight)
+	segmentIndex, err := storageProofSegment(store, windowStart, fileSize, sp.ParentID, currentHeight)
 	if err != nil {
 		return err
 	}
@@ -372,7 +649,7 @@ func VerifySegment(segment []byte, hashSet []common.Hash, leaves, segmentIndex u
 }
 
 // get segment index by random
-func storageProofSegment(state StateDB, windowStart, fileSize uint64, scID common.Hash, currentHeight uint64) (uint64, error) {
+func storageProofSegment(store StorageContractStore, windowStart, fileSize uint64, scID common.Hash, currentHeight uint64) (uint64, error) {
 
 	// Get the trigger block id that parent of windowStart.
 	triggerHeight := windowStart - 1
@@ -380,8 +657,7 @@ func storageProofSegment(state StateDB, windowStart, fileSize uint64, scID commo
 		return 0, errUnfinishedStorageContract
 	}
 
-	db := state.Database().TrieDB().DiskDB().(ethdb.Database)
-	blockHash := rawdb.ReadCanonicalHash(db, uint64(triggerHeight))
+	blockHash := store.CanonicalHash(triggerHeight)
 	if reflect.DeepEqual(blockHash, common.Hash{}) {
 		return 0, errors.New("can not read block hash of the trigger height for storage proof seed")
 	}
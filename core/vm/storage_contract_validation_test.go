@@ -1,6 +1,7 @@
 package vm
 
 import (
+	"crypto/ecdsa"
 	"math/big"
 	"net"
 	"testing"
@@ -9,6 +10,7 @@ import (
 	"github.com/DxChainNetwork/godx/core/types"
 	"github.com/DxChainNetwork/godx/crypto"
 	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/params"
 	"github.com/magiconair/properties/assert"
 	"golang.org/x/crypto/sha3"
 )
@@ -95,6 +97,132 @@ func TestCheckMultiSignatures(t *testing.T) {
 	}
 }
 
+func TestCheckMultiSignaturesThreshold(t *testing.T) {
+	var (
+		keys  []*ecdsa.PrivateKey
+		addrs []common.Address
+	)
+	for i := 0; i < 3; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+		addrs = append(addrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	sign := func(hash common.Hash, signers ...int) [][]byte {
+		var sigs [][]byte
+		for _, i := range signers {
+			sig, err := crypto.Sign(hash.Bytes(), keys[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			sigs = append(sigs, sig)
+		}
+		return sigs
+	}
+
+	// client, host and the arbiter, any two of them could revise the storage contract
+	uc := types.UnlockConditions{
+		PaymentAddresses:   addrs,
+		SignaturesRequired: 2,
+	}
+	sc := types.StorageContract{
+		FileSize:       2048,
+		WindowStart:    234,
+		WindowEnd:      345,
+		UnlockHash:     uc.UnlockHash(),
+		RevisionNumber: 1,
+	}
+	if err := CheckMultiSignatures(sc, sign(sc.RLPHash(), 0, 1, 2)); err != nil {
+		t.Errorf("failed to check the storage contract signed by all parties: %v", err)
+	}
+	if err := CheckMultiSignatures(sc, sign(sc.RLPHash(), 0, 1)); err != errWrongUnlockCondition {
+		t.Errorf("expected error %v, got %v", errWrongUnlockCondition, err)
+	}
+
+	scr := types.StorageContractRevision{
+		ParentID:          sc.ID(),
+		UnlockConditions:  uc,
+		NewRevisionNumber: 2,
+		NewUnlockHash:     sc.UnlockHash,
+	}
+	tests := []struct {
+		signers []int
+		err     error
+	}{
+		{[]int{0, 1}, nil},
+		{[]int{0, 2}, nil},
+		{[]int{1, 2}, nil},
+		{[]int{0, 1, 2}, nil},
+		{[]int{0}, errNotEnoughSignatures},
+		{[]int{1, 0}, errUnknownSigner},
+		{[]int{1, 1}, errUnknownSigner},
+	}
+	for _, test := range tests {
+		if err := CheckMultiSignatures(scr, sign(scr.RLPHash(), test.signers...)); err != test.err {
+			t.Errorf("signers %v: expected error %v, got %v", test.signers, test.err, err)
+		}
+	}
+
+	// the signer not in the unlock conditions
+	outsider, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outsiderSig, err := crypto.Sign(scr.RLPHash().Bytes(), outsider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckMultiSignatures(scr, append(sign(scr.RLPHash(), 0), outsiderSig)); err != errUnknownSigner {
+		t.Errorf("expected error %v, got %v", errUnknownSigner, err)
+	}
+
+	// the unlock conditions changed by the revision
+	scr.UnlockConditions.SignaturesRequired = 1
+	if err := CheckMultiSignatures(scr, sign(scr.RLPHash(), 0)); err != errWrongUnlockCondition {
+		t.Errorf("expected error %v, got %v", errWrongUnlockCondition, err)
+	}
+}
+
+// TestCheckSignaturesFork test the M-of-N unlock conditions are only checked from the
+// multi-signature fork
+func TestCheckSignaturesFork(t *testing.T) {
+	var (
+		keys  []*ecdsa.PrivateKey
+		addrs []common.Address
+	)
+	for i := 0; i < 2; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+		addrs = append(addrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	uc := types.UnlockConditions{
+		PaymentAddresses:   addrs,
+		SignaturesRequired: 2,
+	}
+	scr := types.StorageContractRevision{
+		UnlockConditions:  uc,
+		NewRevisionNumber: 2,
+		NewUnlockHash:     uc.UnlockHash(),
+	}
+	sig, err := crypto.Sign(scr.RLPHash().Bytes(), keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a single signature is only checked to be recoverable before the fork
+	if err := checkSignatures(scr, [][]byte{sig}, params.Rules{}); err != nil {
+		t.Errorf("expect the single signature passed before the fork, got %v", err)
+	}
+	if err := checkSignatures(scr, [][]byte{sig}, params.Rules{IsMultiSig: true}); err != errNotEnoughSignatures {
+		t.Errorf("expected error %v, got %v", errNotEnoughSignatures, err)
+	}
+}
+
 var (
 	leaveContent = []string{"jack", "lucy", "green", "apple"}
 )
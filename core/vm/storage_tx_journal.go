@@ -0,0 +1,110 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/DxChainNetwork/godx/common"
+)
+
+var errStorageTxInsufficientBalance = errors.New("insufficient balance for the storage contract transaction")
+
+// storageTxJournal stages the state changes of the storage contract transaction, so that
+// nothing is written until the transaction is fully validated. Once committed, the accounts
+// are created, the balances are moved, and the storage slots are written. If any step fails,
+// such as a debit exceeding the balance left, the state is rolled back to the snapshot taken
+// before the first write, leaving no partial changes behind. The accounts closed are marked
+// empty at last
+type storageTxJournal struct {
+	accounts []common.Address
+	balances []balanceChange
+	writes   []stateWrite
+	closed   []common.Address
+
+	// checkDebits is whether the debits are checked against the balance left, which is
+	// enabled from the storage atomic fork. Before the fork, the debits are applied unchecked
+	checkDebits bool
+}
+
+// balanceChange is the staged balance change, debit if sub is true
+type balanceChange struct {
+	addr   common.Address
+	amount *big.Int
+	sub    bool
+}
+
+// stateWrite is the staged storage slot write
+type stateWrite struct {
+	addr       common.Address
+	key, value common.Hash
+}
+
+// createAccount stages the account to be created if not exist. The account created is marked
+// not empty with the nonce 1, to avoid being deleted by the state db
+func (j *storageTxJournal) createAccount(addr common.Address) {
+	j.accounts = append(j.accounts, addr)
+}
+
+// subBalance stages the debit of the account
+func (j *storageTxJournal) subBalance(addr common.Address, amount *big.Int) {
+	j.balances = append(j.balances, balanceChange{addr: addr, amount: amount, sub: true})
+}
+
+// addBalance stages the credit of the account
+func (j *storageTxJournal) addBalance(addr common.Address, amount *big.Int) {
+	j.balances = append(j.balances, balanceChange{addr: addr, amount: amount})
+}
+
+// setState stages the storage slot write
+func (j *storageTxJournal) setState(addr common.Address, key, value common.Hash) {
+	j.writes = append(j.writes, stateWrite{addr: addr, key: key, value: value})
+}
+
+// closeAccount stages the account to be marked empty with the nonce 0, so that the finished
+// storage contract account is deleted by the state db
+func (j *storageTxJournal) closeAccount(addr common.Address) {
+	j.closed = append(j.closed, addr)
+}
+
+// commit applies the staged changes to the state. If failed, all changes made are rolled back
+func (j *storageTxJournal) commit(state StateDB) error {
+	snapshot := state.Snapshot()
+	for _, addr := range j.accounts {
+		if !state.Exist(addr) {
+			state.CreateAccount(addr)
+			state.SetNonce(addr, 1)
+		}
+	}
+	if err := j.applyBalances(state); err != nil {
+		state.RevertToSnapshot(snapshot)
+		return err
+	}
+	for _, w := range j.writes {
+		state.SetState(w.addr, w.key, w.value)
+	}
+	for _, addr := range j.closed {
+		state.SetNonce(addr, 0)
+	}
+	return nil
+}
+
+// applyBalances moves the balances in the order staged. If checkDebits is set, each debit is
+// checked against the balance left, so that the debits of the same account never drive it
+// negative
+func (j *storageTxJournal) applyBalances(state StateDB) error {
+	for _, b := range j.balances {
+		if !b.sub {
+			state.AddBalance(b.addr, b.amount)
+			continue
+		}
+		if j.checkDebits && state.GetBalance(b.addr).Cmp(b.amount) < 0 {
+			return errStorageTxInsufficientBalance
+		}
+		state.SubBalance(b.addr, b.amount)
+	}
+	return nil
+}
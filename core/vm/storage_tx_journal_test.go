@@ -0,0 +1,128 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/params"
+	"github.com/DxChainNetwork/godx/rlp"
+	"github.com/DxChainNetwork/godx/storage/coinchargemaintenance"
+)
+
+func TestStorageTxJournalCommit(t *testing.T) {
+	_, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := prvAndAddresses[0].Address
+	to := common.HexToAddress("0x01")
+	key, value := common.HexToHash("0x02"), common.HexToHash("0x03")
+	amount := big.NewInt(100)
+
+	journal := new(storageTxJournal)
+	journal.createAccount(to)
+	journal.subBalance(from, amount)
+	journal.addBalance(to, amount)
+	journal.setState(to, key, value)
+	if stateDB.Exist(to) {
+		t.Fatal("nothing shall be written before the commit")
+	}
+
+	if err := journal.commit(stateDB); err != nil {
+		t.Fatal(err)
+	}
+	if !stateDB.Exist(to) || stateDB.GetNonce(to) != 1 {
+		t.Error("the account shall be created with the nonce 1")
+	}
+	if stateDB.GetBalance(to).Cmp(amount) != 0 {
+		t.Errorf("expect the balance %v credited, got %v", amount, stateDB.GetBalance(to))
+	}
+	if expect := new(big.Int).Sub(balanceOrigin, amount); stateDB.GetBalance(from).Cmp(expect) != 0 {
+		t.Errorf("expect the balance %v left, got %v", expect, stateDB.GetBalance(from))
+	}
+	if stateDB.GetState(to, key) != value {
+		t.Errorf("expect the state %x written, got %x", value, stateDB.GetState(to, key))
+	}
+}
+
+func TestStorageTxJournalRollback(t *testing.T) {
+	_, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := prvAndAddresses[0].Address
+	to := common.HexToAddress("0x01")
+	key := common.HexToHash("0x02")
+
+	// each debit is covered by the balance, but not both
+	journal := &storageTxJournal{checkDebits: true}
+	journal.createAccount(to)
+	journal.subBalance(from, balanceOrigin)
+	journal.subBalance(from, big.NewInt(1))
+	journal.addBalance(to, new(big.Int).Add(balanceOrigin, big.NewInt(1)))
+	journal.setState(to, key, common.HexToHash("0x03"))
+
+	if err := journal.commit(stateDB); err != errStorageTxInsufficientBalance {
+		t.Fatalf("expect error %v, got %v", errStorageTxInsufficientBalance, err)
+	}
+	if stateDB.Exist(to) {
+		t.Error("the account created shall be rolled back")
+	}
+	if stateDB.GetBalance(from).Cmp(balanceOrigin) != 0 {
+		t.Errorf("the debit shall be rolled back, got balance %v", stateDB.GetBalance(from))
+	}
+	if stateDB.GetState(to, key) != (common.Hash{}) {
+		t.Error("the state shall not be written")
+	}
+}
+
+func TestEVM_CreateContractTxRollback(t *testing.T) {
+	evm, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the client is the host as well, with the balance covering each collateral but not both
+	client := prvAndAddresses[0]
+	sc, err := mockStorageContract([]PrivkeyAddress{client, client})
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance := new(big.Int).Add(clientCollateral, big.NewInt(1))
+	stateDB.SetBalance(client.Address, balance)
+
+	rlpBytes, err := rlp.EncodeToBytes(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the collaterals are debited unchecked before the fork
+	snapshot := stateDB.Snapshot()
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err != nil {
+		t.Fatalf("expect the storage contract created before the fork, got %v", err)
+	}
+	stateDB.RevertToSnapshot(snapshot)
+
+	config := *params.MainnetChainConfig
+	config.StorageAtomicBlock = big.NewInt(1000)
+	evm.chainConfig = &config
+	evm.chainRules = config.Rules(evm.BlockNumber)
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err != errStorageTxInsufficientBalance {
+		t.Fatalf("expect error %v, got %v", errStorageTxInsufficientBalance, err)
+	}
+
+	scID := sc.ID()
+	statusAddr := common.BytesToAddress([]byte(coinchargemaintenance.StrPrefixExpSC + strconv.FormatUint(sc.WindowEnd, 10)))
+	if stateDB.Exist(common.BytesToAddress(scID[12:])) || stateDB.Exist(statusAddr) {
+		t.Error("the accounts created shall be rolled back")
+	}
+	if stateDB.GetBalance(client.Address).Cmp(balance) != 0 {
+		t.Errorf("the collateral shall be refunded, got balance %v", stateDB.GetBalance(client.Address))
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/crypto"
+)
+
+// The storage contract transactions emit the logs of the storage contract lifecycle events, so
+// that they could be filtered and subscribed the same as the logs of the smart contracts. The
+// log is emitted by the storage contract account, with the event topic first and the storage
+// contract id second. The data is the event fields each padded to 32 bytes. The logs are only
+// emitted from the storage logs fork, as they are part of the receipts.
+var (
+	// StorageContractCreatedTopic is the topic of the storage contract created, with the data of
+	// the client address, host address, client collateral, host collateral, window start and
+	// window end
+	StorageContractCreatedTopic = crypto.Keccak256Hash([]byte("StorageContractCreated(bytes32,address,address,uint256,uint256,uint64,uint64)"))
+
+	// StorageContractRevisedTopic is the topic of the storage contract revised, with the data of
+	// the revision number, file size and file merkle root
+	StorageContractRevisedTopic = crypto.Keccak256Hash([]byte("StorageContractRevised(bytes32,uint64,uint64,bytes32)"))
+
+	// StorageContractProofedTopic is the topic of the storage proof accepted, with the data of the
+	// valid proof outputs paid to the client and host
+	StorageContractProofedTopic = crypto.Keccak256Hash([]byte("StorageContractProofed(bytes32,uint256,uint256)"))
+
+	// StorageContractRenewedTopic is the topic of the storage contract closed by the renewal, with
+	// the id of the new storage contract as the third topic
+	StorageContractRenewedTopic = crypto.Keccak256Hash([]byte("StorageContractRenewed(bytes32,bytes32)"))
+)
+
+// emitStorageContractLog adds the log of the storage contract event to the state. Nothing is
+// emitted before the storage logs fork
+func (evm *EVM) emitStorageContractLog(event common.Hash, scID common.Hash, topics []common.Hash, fields ...common.Hash) {
+	if !evm.chainRules.IsStorageLogs {
+		return
+	}
+	data := make([]byte, 0, len(fields)*common.HashLength)
+	for _, field := range fields {
+		data = append(data, field.Bytes()...)
+	}
+	evm.StateDB.AddLog(&types.Log{
+		Address:     common.BytesToAddress(scID[12:]),
+		Topics:      append([]common.Hash{event, scID}, topics...),
+		Data:        data,
+		BlockNumber: evm.BlockNumber.Uint64(),
+	})
+}
+
+// emitContractCreated emits the log of the storage contract created
+func (evm *EVM) emitContractCreated(sc types.StorageContract) {
+	evm.emitStorageContractLog(StorageContractCreatedTopic, sc.ID(), nil,
+		common.BytesToHash(sc.ClientCollateral.Address.Bytes()),
+		common.BytesToHash(sc.HostCollateral.Address.Bytes()),
+		common.BigToHash(sc.ClientCollateral.Value),
+		common.BigToHash(sc.HostCollateral.Value),
+		common.BytesToHash(Uint64ToBytes(sc.WindowStart)),
+		common.BytesToHash(Uint64ToBytes(sc.WindowEnd)),
+	)
+}
+
+// emitContractRevised emits the log of the storage contract revised
+func (evm *EVM) emitContractRevised(scr types.StorageContractRevision) {
+	evm.emitStorageContractLog(StorageContractRevisedTopic, scr.ParentID, nil,
+		common.BytesToHash(Uint64ToBytes(scr.NewRevisionNumber)),
+		common.BytesToHash(Uint64ToBytes(scr.NewFileSize)),
+		scr.NewFileMerkleRoot,
+	)
+}
+
+// emitContractProofed emits the log of the storage proof accepted
+func (evm *EVM) emitContractProofed(scID common.Hash, clientOutput, hostOutput *big.Int) {
+	evm.emitStorageContractLog(StorageContractProofedTopic, scID, nil, common.BigToHash(clientOutput), common.BigToHash(hostOutput))
+}
+
+// emitContractRenewed emits the log of the storage contract closed by the renewal
+func (evm *EVM) emitContractRenewed(oldID, newID common.Hash) {
+	evm.emitStorageContractLog(StorageContractRenewedTopic, oldID, []common.Hash{newID})
+}
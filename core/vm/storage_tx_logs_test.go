@@ -0,0 +1,88 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/params"
+	"github.com/DxChainNetwork/godx/rlp"
+)
+
+func TestStorageContractLogs(t *testing.T) {
+	evm, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, err := mockStorageContract(prvAndAddresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scID := sc.ID()
+	rlpBytes, err := rlp.EncodeToBytes(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no log is emitted before the fork
+	snapshot := stateDB.Snapshot()
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err != nil {
+		t.Fatal(err)
+	}
+	if logs := stateDB.GetLogs(common.Hash{}); len(logs) != 0 {
+		t.Fatalf("expect no logs emitted before the fork, got %d", len(logs))
+	}
+	stateDB.RevertToSnapshot(snapshot)
+
+	config := *params.MainnetChainConfig
+	config.StorageLogsBlock = big.NewInt(1000)
+	evm.chainConfig = &config
+	evm.chainRules = config.Rules(evm.BlockNumber)
+
+	// the failed transaction emits no log
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, []byte{0x01}, gasOrigin); err == nil {
+		t.Fatal("expect error executing the invalid storage contract")
+	}
+	if logs := stateDB.GetLogs(common.Hash{}); len(logs) != 0 {
+		t.Fatalf("expect no logs emitted, got %d", len(logs))
+	}
+
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err != nil {
+		t.Fatal(err)
+	}
+	scr, err := mockStorageRevision(*sc, cost, prvAndAddresses[0].Privkey, prvAndAddresses[1].Privkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rlpBytes, err = rlp.EncodeToBytes(scr); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, CommitRevisionTransaction, rlpBytes, gasOrigin); err != nil {
+		t.Fatal(err)
+	}
+
+	logs := stateDB.GetLogs(common.Hash{})
+	if len(logs) != 2 {
+		t.Fatalf("expect 2 logs emitted, got %d", len(logs))
+	}
+	for i, event := range []common.Hash{StorageContractCreatedTopic, StorageContractRevisedTopic} {
+		l := logs[i]
+		if len(l.Topics) != 2 || l.Topics[0] != event || l.Topics[1] != scID {
+			t.Errorf("log %d: unexpected topics %v", i, l.Topics)
+		}
+		if l.Address != common.BytesToAddress(scID[12:]) || l.BlockNumber != 1000 {
+			t.Errorf("log %d: unexpected address %v or block number %v", i, l.Address.Hex(), l.BlockNumber)
+		}
+	}
+	if data := logs[0].Data; len(data) != 6*common.HashLength || !bytes.Equal(data[12:32], sc.ClientCollateral.Address.Bytes()) {
+		t.Errorf("unexpected data of the created log %x", data)
+	}
+	if data := logs[1].Data; len(data) != 3*common.HashLength || !bytes.Equal(data[64:], scr.NewFileMerkleRoot.Bytes()) {
+		t.Errorf("unexpected data of the revised log %x", data)
+	}
+}
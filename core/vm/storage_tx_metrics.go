@@ -0,0 +1,96 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"strings"
+	"time"
+
+	"github.com/DxChainNetwork/godx/metrics"
+)
+
+// storageTxMetricsPrefix is the prefix of the metrics names of the storage contract transactions
+const storageTxMetricsPrefix = "evm/storagetx/"
+
+// maxFailureReasonLength is the maximum length of the failure reason in the metrics name
+const maxFailureReasonLength = 64
+
+// storageTxMetrics contains the metrics of a single type of storage contract transaction
+type storageTxMetrics struct {
+	name     string
+	count    metrics.Counter
+	failures metrics.Counter
+	gasUsed  metrics.Meter
+	latency  metrics.Timer
+}
+
+// storageTxMetricsByType contains the metrics of all types of storage contract transactions
+var storageTxMetricsByType = map[string]*storageTxMetrics{
+	HostAnnounceTransaction:      newStorageTxMetrics(HostAnnounceTransaction),
+	ContractCreateTransaction:    newStorageTxMetrics(ContractCreateTransaction),
+	CommitRevisionTransaction:    newStorageTxMetrics(CommitRevisionTransaction),
+	StorageProofTransaction:      newStorageTxMetrics(StorageProofTransaction),
+	EscrowFundTransaction:        newStorageTxMetrics(EscrowFundTransaction),
+	HostRevokeTransaction:        newStorageTxMetrics(HostRevokeTransaction),
+	BatchStorageProofTransaction: newStorageTxMetrics(BatchStorageProofTransaction),
+	ContractRenewTransaction:     newStorageTxMetrics(ContractRenewTransaction),
+}
+
+// newStorageTxMetrics registers the metrics of the storage contract transaction type
+func newStorageTxMetrics(txType string) *storageTxMetrics {
+	name := storageTxMetricsPrefix + strings.ToLower(txType)
+	return &storageTxMetrics{
+		name:     name,
+		count:    metrics.NewRegisteredCounter(name+"/count", nil),
+		failures: metrics.NewRegisteredCounter(name+"/failures", nil),
+		gasUsed:  metrics.NewRegisteredMeter(name+"/gas", nil),
+		latency:  metrics.NewRegisteredTimer(name+"/latency", nil),
+	}
+}
+
+// recordStorageTx updates the metrics of the storage contract transaction after execution
+func recordStorageTx(txType string, start time.Time, gasUsed uint64, err error) {
+	m, exist := storageTxMetricsByType[txType]
+	if !exist {
+		return
+	}
+	m.count.Inc(1)
+	m.gasUsed.Mark(int64(gasUsed))
+	m.latency.UpdateSince(start)
+
+	if err == nil {
+		return
+	}
+	m.failures.Inc(1)
+	metrics.GetOrRegisterCounter(m.name+"/failures/"+storageTxFailureReason(err), nil).Inc(1)
+}
+
+// storageTxFailureReason converts the error into the failure reason used in the metrics
+// name. The details after the colon are dropped to keep the number of reasons bounded
+func storageTxFailureReason(err error) string {
+	msg := err.Error()
+	if idx := strings.Index(msg, ":"); idx >= 0 {
+		msg = msg[:idx]
+	}
+
+	reason := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '_'
+		}
+	}, strings.TrimSpace(msg))
+
+	if len(reason) > maxFailureReasonLength {
+		reason = reason[:maxFailureReasonLength]
+	}
+	if reason == "" {
+		reason = "unknown"
+	}
+	return reason
+}
@@ -0,0 +1,30 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStorageTxFailureReason(t *testing.T) {
+	tests := []struct {
+		err    error
+		reason string
+	}{
+		{errInvalidStorageProof, "invalid_storage_proof"},
+		{errors.New("Too early to submit storage proof"), "too_early_to_submit_storage_proof"},
+		{fmt.Errorf("invalid host announce address: %v", "bad enode"), "invalid_host_announce_address"},
+		{errors.New(": detail only"), "unknown"},
+		{errors.New(strings.Repeat("a", 100)), strings.Repeat("a", maxFailureReasonLength)},
+	}
+	for _, test := range tests {
+		if reason := storageTxFailureReason(test.err); reason != test.reason {
+			t.Errorf("error %q: expected reason %q, got %q", test.err, test.reason, reason)
+		}
+	}
+}
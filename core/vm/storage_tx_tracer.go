@@ -0,0 +1,141 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/DxChainNetwork/godx/common"
+)
+
+// StorageTxTracer is implemented by the tracers capturing the execution of the storage
+// contract transactions, which are not run by the interpreter and thus never reach
+// CaptureState. Each step of the transaction, such as decoding the payload or checking
+// the contract, is captured along with the gas it consumed and the state it wrote
+type StorageTxTracer interface {
+	CaptureStorageTxStep(step StorageTxStep) error
+}
+
+// StorageTxStep is a step of the storage contract transaction execution
+type StorageTxStep struct {
+	TxType  string           `json:"txType"`
+	Step    string           `json:"step"`
+	GasUsed uint64           `json:"gasUsed"`
+	Payload interface{}      `json:"payload,omitempty"`
+	Writes  []StorageTxWrite `json:"writes,omitempty"`
+	Err     string           `json:"error,omitempty"`
+}
+
+// StorageTxWrite is a state write made by the storage contract transaction
+type StorageTxWrite struct {
+	Op      string         `json:"op"`
+	Address common.Address `json:"address"`
+	Key     *common.Hash   `json:"key,omitempty"`
+	Value   string         `json:"value,omitempty"`
+}
+
+// the operations of the state writes
+const (
+	storageTxWriteCreate     = "createAccount"
+	storageTxWriteAddBalance = "addBalance"
+	storageTxWriteSubBalance = "subBalance"
+	storageTxWriteNonce      = "setNonce"
+	storageTxWriteState      = "setState"
+)
+
+// storageTxTrace keeps the trace of the storage contract transaction being executed
+type storageTxTrace struct {
+	tracer StorageTxTracer
+	txType string
+	gas    uint64
+	db     *tracingStateDB
+}
+
+// tracingStateDB records the state writes made through it
+type tracingStateDB struct {
+	StateDB
+	writes []StorageTxWrite
+}
+
+func (db *tracingStateDB) CreateAccount(addr common.Address) {
+	db.writes = append(db.writes, StorageTxWrite{Op: storageTxWriteCreate, Address: addr})
+	db.StateDB.CreateAccount(addr)
+}
+
+func (db *tracingStateDB) AddBalance(addr common.Address, amount *big.Int) {
+	db.writes = append(db.writes, StorageTxWrite{Op: storageTxWriteAddBalance, Address: addr, Value: amount.String()})
+	db.StateDB.AddBalance(addr, amount)
+}
+
+func (db *tracingStateDB) SubBalance(addr common.Address, amount *big.Int) {
+	db.writes = append(db.writes, StorageTxWrite{Op: storageTxWriteSubBalance, Address: addr, Value: amount.String()})
+	db.StateDB.SubBalance(addr, amount)
+}
+
+func (db *tracingStateDB) SetNonce(addr common.Address, nonce uint64) {
+	db.writes = append(db.writes, StorageTxWrite{Op: storageTxWriteNonce, Address: addr, Value: strconv.FormatUint(nonce, 10)})
+	db.StateDB.SetNonce(addr, nonce)
+}
+
+func (db *tracingStateDB) SetState(addr common.Address, key, value common.Hash) {
+	db.writes = append(db.writes, StorageTxWrite{Op: storageTxWriteState, Address: addr, Key: &key, Value: value.Hex()})
+	db.StateDB.SetState(addr, key, value)
+}
+
+// startStorageTxTrace starts to trace the storage contract transaction if the tracer
+// configured captures the storage contract transactions. The state writes are recorded by
+// routing them through the tracing state db until endStorageTxTrace is called
+func (evm *EVM) startStorageTxTrace(txType string, gas uint64) {
+	tracer, ok := evm.vmConfig.Tracer.(StorageTxTracer)
+	if !evm.vmConfig.Debug || !ok {
+		return
+	}
+	evm.storageTrace = &storageTxTrace{
+		tracer: tracer,
+		txType: txType,
+		gas:    gas,
+		db:     &tracingStateDB{StateDB: evm.StateDB},
+	}
+	evm.StateDB = evm.storageTrace.db
+}
+
+// endStorageTxTrace restores the state db, and captures the revert if the transaction failed
+func (evm *EVM) endStorageTxTrace(leftOverGas uint64, err error) {
+	if evm.storageTrace == nil {
+		return
+	}
+	if err != nil {
+		evm.traceStorageTxStep("revert", leftOverGas, nil, err)
+	}
+	evm.StateDB = evm.storageTrace.db.StateDB
+	evm.storageTrace = nil
+}
+
+// traceStorageTxStep captures the step of the storage contract transaction, with the gas
+// consumed and the state written since the last step. The payload is dropped if the step
+// failed, since it might be partially decoded
+func (evm *EVM) traceStorageTxStep(name string, gasRemaining uint64, payload interface{}, err error) {
+	trace := evm.storageTrace
+	if trace == nil {
+		return
+	}
+	step := StorageTxStep{
+		TxType: trace.txType,
+		Step:   name,
+		Writes: trace.db.writes,
+	}
+	if gasRemaining < trace.gas {
+		step.GasUsed = trace.gas - gasRemaining
+	}
+	if err != nil {
+		step.Err = err.Error()
+	} else {
+		step.Payload = payload
+	}
+	trace.gas = gasRemaining
+	trace.db.writes = nil
+	trace.tracer.CaptureStorageTxStep(step)
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/params"
+	"github.com/DxChainNetwork/godx/rlp"
+	"github.com/DxChainNetwork/godx/storage/coinchargemaintenance"
+)
+
+func TestStorageTxTracer(t *testing.T) {
+	evm, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewStructLogger(nil)
+	evm.vmConfig = Config{Debug: true, Tracer: logger}
+
+	sc, err := mockStorageContract(prvAndAddresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlpBytes, err := rlp.EncodeToBytes(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err != nil {
+		t.Fatal(err)
+	}
+	if evm.StateDB != stateDB || evm.storageTrace != nil {
+		t.Fatal("the state db shall be restored after the trace")
+	}
+
+	steps := logger.StorageTxLogs()
+	expected := []struct {
+		name    string
+		gasUsed uint64
+	}{
+		{"decode", params.DecodeGas},
+		{"checkContract", params.CheckFileGas},
+		{"stage", 0},
+		{"commit", 0},
+	}
+	if len(steps) != len(expected) {
+		t.Fatalf("expect %v steps, got %+v", len(expected), steps)
+	}
+	for i, e := range expected {
+		if steps[i].TxType != ContractCreateTransaction || steps[i].Step != e.name || steps[i].GasUsed != e.gasUsed || steps[i].Err != "" {
+			t.Errorf("step %v: expect %v using %v gas, got %+v", i, e.name, e.gasUsed, steps[i])
+		}
+	}
+	if steps[0].Payload == nil {
+		t.Error("the decoded storage contract shall be captured")
+	}
+	if len(steps[1].Writes) != 0 || len(steps[2].Writes) != 0 {
+		t.Errorf("nothing shall be written before the commit, got %+v and %+v", steps[1].Writes, steps[2].Writes)
+	}
+	if len(steps[3].Writes) == 0 || steps[3].Writes[0].Op != storageTxWriteCreate {
+		t.Errorf("the account creations shall be captured, got %+v", steps[3].Writes)
+	}
+
+	scID := sc.ID()
+	contractAddr := common.BytesToAddress(scID[12:])
+	var fileSizeWritten bool
+	for _, write := range steps[3].Writes {
+		if write.Op == storageTxWriteState && write.Address == contractAddr && *write.Key == coinchargemaintenance.KeyFileSize {
+			fileSizeWritten = true
+		}
+	}
+	if !fileSizeWritten {
+		t.Errorf("the file size written to the contract account shall be captured, got %+v", steps[3].Writes)
+	}
+}
+
+func TestStorageTxTracerRevert(t *testing.T) {
+	evm, stateDB, prvAndAddresses, err := mockEvmAndState(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewStructLogger(nil)
+	evm.vmConfig = Config{Debug: true, Tracer: logger}
+
+	sc, err := mockStorageContract(prvAndAddresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlpBytes, err := rlp.EncodeToBytes(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scID := sc.ID()
+	stateDB.CreateAccount(common.BytesToAddress(scID[12:]))
+
+	if _, _, err := evm.ApplyStorageContractTransaction(AccountRef{}, ContractCreateTransaction, rlpBytes, gasOrigin); err == nil {
+		t.Fatal("expected the storage contract tx to fail")
+	}
+	steps := logger.StorageTxLogs()
+	if len(steps) != 3 {
+		t.Fatalf("expect decode, checkContract and revert steps, got %+v", steps)
+	}
+	if steps[1].Step != "checkContract" || steps[1].Err == "" || steps[1].Payload != nil {
+		t.Errorf("the failed step shall be captured with the error, got %+v", steps[1])
+	}
+	if steps[2].Step != "revert" || steps[2].Err == "" {
+		t.Errorf("the revert shall be captured, got %+v", steps[2])
+	}
+}
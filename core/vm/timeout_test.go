@@ -0,0 +1,64 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/params"
+)
+
+func TestExecutionTimeout(t *testing.T) {
+	// the code jumping back to the start forever
+	loop := []byte{byte(JUMPDEST), byte(PUSH1), 0x00, byte(JUMP)}
+	caller := AccountRef(common.HexToAddress("0x1"))
+	contractAddr := common.HexToAddress("0x2")
+
+	ctx := Context{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: big.NewInt(0),
+	}
+	statedb := mockState(ethdb.NewMemDatabase(), nil)
+	statedb.SetCode(contractAddr, loop)
+	evm := NewEVM(ctx, statedb, params.TestChainConfig, Config{Timeout: 50 * time.Millisecond})
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := evm.Call(caller, contractAddr, nil, math.MaxUint64/2, new(big.Int))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != ErrExecutionTimeout {
+			t.Errorf("expect error %v, got %v", ErrExecutionTimeout, err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the execution is not cancelled after the timeout")
+	}
+	if !evm.TimedOut() {
+		t.Error("the evm shall be marked timed out")
+	}
+
+	// the evm stopped is never timed out
+	evm = NewEVM(ctx, statedb, params.TestChainConfig, Config{Timeout: 10 * time.Millisecond})
+	evm.Stop()
+	time.Sleep(50 * time.Millisecond)
+	if evm.TimedOut() {
+		t.Error("the evm stopped shall not be timed out")
+	}
+
+	// the execution cancelled by the caller is not a timeout
+	evm = NewEVM(ctx, statedb, params.TestChainConfig, Config{})
+	evm.Cancel()
+	if _, _, err := evm.Call(caller, contractAddr, nil, math.MaxUint64/2, new(big.Int)); err != nil {
+		t.Errorf("the cancelled execution shall not fail, got %v", err)
+	}
+}
@@ -754,10 +754,11 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 	switch tracer := tracer.(type) {
 	case *vm.StructLogger:
 		return &ethapi.ExecutionResult{
-			Gas:         gas,
-			Failed:      failed,
-			ReturnValue: fmt.Sprintf("%x", ret),
-			StructLogs:  ethapi.FormatLogs(tracer.StructLogs()),
+			Gas:           gas,
+			Failed:        failed,
+			ReturnValue:   fmt.Sprintf("%x", ret),
+			StructLogs:    ethapi.FormatLogs(tracer.StructLogs()),
+			StorageTxLogs: tracer.StorageTxLogs(),
 		}, nil
 
 	case *tracers.Tracer:
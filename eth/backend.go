@@ -50,6 +50,7 @@ import (
 	"github.com/DxChainNetwork/godx/node"
 	"github.com/DxChainNetwork/godx/p2p"
 	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/p2p/enr"
 	"github.com/DxChainNetwork/godx/params"
 	"github.com/DxChainNetwork/godx/rlp"
 	"github.com/DxChainNetwork/godx/rpc"
@@ -126,6 +127,12 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
+	if err := vm.CheckInterpreter(config.EVMInterpreter); err != nil {
+		return nil, err
+	}
+	if err := vm.CheckInterpreter(config.EWASMInterpreter); err != nil {
+		return nil, err
+	}
 	if config.MinerGasPrice == nil || config.MinerGasPrice.Cmp(common.Big0) <= 0 {
 		log.Warn("Sanitizing invalid miner gas price", "provided", config.MinerGasPrice, "updated", DefaultConfig.MinerGasPrice)
 		config.MinerGasPrice = new(big.Int).Set(DefaultConfig.MinerGasPrice)
@@ -209,10 +216,37 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	// Initialize StorageClient based on the configuration
 	if config.StorageClient {
 		clientPath := ctx.ResolvePath(config.StorageClientDir)
+		if config.StorageClientForceTakeover {
+			log.Warn("Taking over the storage client data directory locks", "dir", clientPath)
+			if err = storageclient.TakeoverLocks(clientPath); err != nil {
+				return nil, err
+			}
+		}
 		eth.storageClient, err = storageclient.New(clientPath)
 		if err != nil {
 			return nil, err
 		}
+		if config.StorageClientCoordinationDir != "" {
+			if err = eth.storageClient.EnableCoordination(config.StorageClientCoordinationDir); err != nil {
+				return nil, err
+			}
+		}
+		if config.StorageClientReplicaDir != "" {
+			if err = eth.storageClient.EnableReplica(config.StorageClientReplicaDir); err != nil {
+				return nil, err
+			}
+		}
+		if config.StorageClientSpillDir != "" {
+			if err = eth.storageClient.EnableSpill(config.StorageClientSpillDir); err != nil {
+				return nil, err
+			}
+		}
+		if config.StorageClientHTTPAddr != "" {
+			if err = eth.storageClient.EnableHTTPStream(config.StorageClientHTTPAddr,
+				config.StorageClientHTTPAuthTokens, config.StorageClientHTTPVirtualHosts); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Initialize StorageHost based on the configuration
@@ -559,6 +593,14 @@ func (s *Ethereum) SignWithNodeSk(hash []byte) ([]byte, error) {
 	return crypto.Sign(hash, s.server.Config.PrivateKey)
 }
 
+// SetNodeRecordEntry sets the entry in the local node record, which is advertised through
+// the discovery
+func (s *Ethereum) SetNodeRecordEntry(e enr.Entry) {
+	if ln := s.server.LocalNode(); ln != nil {
+		ln.Set(e)
+	}
+}
+
 // Get host enode url from enode object
 func (s *Ethereum) GetHostEnodeURL() string {
 	return s.server.Self().String()
@@ -766,6 +808,14 @@ func (s *Ethereum) GetStorageHostSetting(enodeID enode.ID, enodeURL string, conf
 		return fmt.Errorf("error decoding the storage configuration: %s", err.Error())
 	}
 
+	// verify the config is signed by the host recently, reject the tampered or replayed config
+	if err := storage.VerifyHostExtConfig(*config, sp.PeerNode().Pubkey()); err != nil {
+		return fmt.Errorf("failed to verify the storage host configuration: %s", err.Error())
+	}
+	if err := storage.CheckHostExtConfigFreshness(*config, time.Now()); err != nil {
+		return fmt.Errorf("failed to verify the storage host configuration: %s", err.Error())
+	}
+
 	log.Info("Successfully get the storage host settings")
 
 	// check the connection and update the connection
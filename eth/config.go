@@ -55,6 +55,7 @@ var DefaultConfig = Config{
 	MinerGasCeil:   8000000,
 	MinerGasPrice:  big.NewInt(params.GWei),
 	MinerRecommit:  3 * time.Second,
+	EVMCallTimeout: 5 * time.Second,
 
 	TxPool: core.DefaultTxPoolConfig,
 	GPO: gasprice.Config{
@@ -139,12 +140,41 @@ type Config struct {
 	// Type of the EVM interpreter ("" for default)
 	EVMInterpreter string
 
+	// Execution time budget of eth_call and eth_estimateGas (0 for unlimited)
+	EVMCallTimeout time.Duration
+
 	// Constantinople block override (TODO: remove after the fork)
 	ConstantinopleOverride *big.Int
 
 	// StorageClient Persist Directory
 	StorageClientDir string
 
+	// StorageClientCoordinationDir is the directory shared with the other nodes acting as
+	// one logical storage client. Empty disables the coordination mode (experimental)
+	StorageClientCoordinationDir string
+
+	// StorageClientReplicaDir is the directory of the snapshot exported by the primary
+	// storage client. Non-empty enables the read-only replica mode
+	StorageClientReplicaDir string
+
+	// StorageClientSpillDir is the directory to spill the encoded upload data to when the
+	// storage client memory is saturated. Empty keeps all upload data in memory
+	StorageClientSpillDir string
+
+	// StorageClientForceTakeover removes the locks on the storage client data directory left
+	// by another instance on startup, which must not be running any more
+	StorageClientForceTakeover bool
+
+	// StorageClientHTTPAddr is the listening address of the HTTP endpoint streaming the storage
+	// client files with the range requests. Empty disables the endpoint
+	StorageClientHTTPAddr string
+
+	// StorageClientHTTPAuthTokens are the access tokens required by the HTTP stream endpoint,
+	// and StorageClientHTTPVirtualHosts are the virtual hosts it allows. They follow the HTTP
+	// RPC interface of the node
+	StorageClientHTTPAuthTokens   []string `toml:"-"`
+	StorageClientHTTPVirtualHosts []string `toml:"-"`
+
 	// Role, can only be one of the two roles
 	StorageClient bool
 	StorageHost   bool
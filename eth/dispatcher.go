@@ -17,6 +17,10 @@ var hostHandlers = map[uint64]func(h *storagehost.StorageHost, sp storage.Peer,
 	storage.ContractCreateReqMsg:   storagehost.ContractCreateHandler,
 	storage.ContractUploadReqMsg:   storagehost.UploadHandler,
 	storage.ContractDownloadReqMsg: storagehost.DownloadHandler,
+	storage.ContractAuditReqMsg:    storagehost.ContractAuditHandler,
+	storage.SpeedTestReqMsg:        storagehost.SpeedTestHandler,
+	storage.PublishSectorsReqMsg:   storagehost.PublishSectorsHandler,
+	storage.PublicDownloadReqMsg:   storagehost.PublicDownloadHandler,
 }
 
 func (pm *ProtocolManager) msgDispatch(msg p2p.Msg, p *peer) error {
@@ -55,6 +59,15 @@ func (pm *ProtocolManager) ethMsgSchedule(msg p2p.Msg, p *peer) error {
 }
 
 func (pm *ProtocolManager) clientMsgSchedule(msg p2p.Msg, p *peer) error {
+	// the heartbeat messages are handled right away, regardless of
+	// the negotiation in progress
+	switch msg.Code {
+	case storage.HostPongMsg:
+		return p.handleStoragePong(msg)
+	case storage.HostPingMsg:
+		return p.handleStoragePing(msg, storage.ClientPongMsg)
+	}
+
 	// if the message is hostConfigRespMsg, try to push it to the channel
 	// if failed, discard the message right away, meaning the last config
 	// message handling is not finished yet
@@ -82,6 +95,15 @@ func (pm *ProtocolManager) clientMsgSchedule(msg p2p.Msg, p *peer) error {
 }
 
 func (pm *ProtocolManager) hostMsgSchedule(msg p2p.Msg, p *peer) error {
+	// the heartbeat messages are handled right away, regardless of
+	// the negotiation in progress
+	switch msg.Code {
+	case storage.ClientPingMsg:
+		return p.handleStoragePing(msg, storage.HostPongMsg)
+	case storage.ClientPongMsg:
+		return p.handleStoragePong(msg)
+	}
+
 	// check if the message code is HostConfigReqMsg, which needs to be handled
 	// explicitly
 	if msg.Code == storage.HostConfigReqMsg {
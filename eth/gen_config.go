@@ -46,6 +46,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		DocRoot                 string `toml:"-"`
 		EWASMInterpreter        string
 		EVMInterpreter          string
+		EVMCallTimeout          time.Duration
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -75,6 +76,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.DocRoot = c.DocRoot
 	enc.EWASMInterpreter = c.EWASMInterpreter
 	enc.EVMInterpreter = c.EVMInterpreter
+	enc.EVMCallTimeout = c.EVMCallTimeout
 	return &enc, nil
 }
 
@@ -108,6 +110,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		DocRoot                 *string `toml:"-"`
 		EWASMInterpreter        *string
 		EVMInterpreter          *string
+		EVMCallTimeout          *time.Duration
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -194,5 +197,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EVMInterpreter != nil {
 		c.EVMInterpreter = *dec.EVMInterpreter
 	}
+	if dec.EVMCallTimeout != nil {
+		c.EVMCallTimeout = *dec.EVMCallTimeout
+	}
 	return nil
 }
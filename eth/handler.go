@@ -284,6 +284,11 @@ func (pm *ProtocolManager) handle(p *peer) error {
 	}
 	defer pm.removePeer(p.id)
 
+	// learn the host record advertised by the peer, used to pre-filter the storage hosts
+	if pm.eth.config.StorageClient {
+		pm.eth.storageClient.UpdateHostRecord(p.Node())
+	}
+
 	// Register the peer in the downloader. If the downloader considers it banned, we disconnect
 	if err := pm.downloader.RegisterPeer(p.id, p.version, p); err != nil {
 		return err
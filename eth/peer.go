@@ -62,9 +62,10 @@ const (
 // PeerInfo represents a short summary of the Ethereum sub-protocol metadata known
 // about a connected peer.
 type PeerInfo struct {
-	Version    int      `json:"version"`    // Ethereum protocol version negotiated
-	Difficulty *big.Int `json:"difficulty"` // Total difficulty of the peer's blockchain
-	Head       string   `json:"head"`       // SHA3 hash of the peer's best owned block
+	Version    int      `json:"version"`              // Ethereum protocol version negotiated
+	Difficulty *big.Int `json:"difficulty"`           // Total difficulty of the peer's blockchain
+	Head       string   `json:"head"`                 // SHA3 hash of the peer's best owned block
+	StorageRTT string   `json:"storageRTT,omitempty"` // Round trip time of the storage sessions measured by the heartbeat
 }
 
 // propEvent is a block propagation, waiting for its turn in the broadcast queue.
@@ -108,6 +109,9 @@ type peer struct {
 	contractRevisingOrRenewing chan struct{}
 	hostConfigRequesting       chan struct{}
 
+	// liveness and round trip time of the storage sessions
+	heartbeat storageHeartbeat
+
 	// error channel
 	errMsg chan error
 
@@ -201,11 +205,15 @@ func (p *peer) close() {
 func (p *peer) Info() *PeerInfo {
 	hash, td := p.Head()
 
-	return &PeerInfo{
+	info := &PeerInfo{
 		Version:    p.version,
 		Difficulty: td,
 		Head:       hash.Hex(),
 	}
+	if rtt := p.StorageRTT(); rtt != 0 {
+		info.StorageRTT = rtt.String()
+	}
+	return info
 }
 
 // Head retrieves a copy of the current head hash and total difficulty of the
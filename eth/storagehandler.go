@@ -15,12 +15,21 @@ func (pm *ProtocolManager) hostConfigMsgHandler(p *peer, configMsg p2p.Msg) erro
 		return err
 	}
 
+	// the config request is subject to the host ingress policy as well,
+	// so that the hosts cannot be scraped
+	release, err := pm.eth.storageHost.AdmitSession(p.RemoteAddr())
+	if err != nil {
+		p.HostConfigProcessingDone()
+		return err
+	}
+
 	// start the go routine, handle the host config request
 	// once done, release the channel
 	go func() {
 		pm.wg.Add(1)
 		defer pm.wg.Done()
 		defer p.HostConfigProcessingDone()
+		defer release()
 		config := pm.eth.storageHost.RetrieveExternalConfig()
 		if err := p.SendStorageHostConfig(config); err != nil {
 			p.TriggerError(err)
@@ -44,9 +53,18 @@ func (pm *ProtocolManager) contractMsgHandler(p *peer, msg p2p.Msg) error {
 }
 
 func (pm *ProtocolManager) contractReqHandler(handler func(h *storagehost.StorageHost, sp storage.Peer, msg p2p.Msg), p *peer, msg p2p.Msg) error {
+	// enforce the host ingress policy before the negotiation begins,
+	// the request denied or rate limited is rejected as the host is busy
+	release, err := pm.eth.storageHost.AdmitSession(p.RemoteAddr())
+	if err != nil {
+		_ = p.SendHostBusyHandleRequestErr()
+		return err
+	}
+
 	// avoid continuously contract related requests attack
 	// generate too many go routines and used all resources
 	if err := p.HostContractProcessing(); err != nil {
+		release()
 		// error is ignored intentionally. If error occurred,
 		// the client must wait until time out
 		_ = p.SendHostBusyHandleRequestErr()
@@ -59,6 +77,7 @@ func (pm *ProtocolManager) contractReqHandler(handler func(h *storagehost.Storag
 		pm.wg.Add(1)
 		defer pm.wg.Done()
 		defer p.HostContractProcessingDone()
+		defer release()
 		handler(pm.eth.storageHost, p, msg)
 	}()
 
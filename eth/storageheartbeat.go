@@ -0,0 +1,148 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package eth
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/DxChainNetwork/godx/p2p"
+	"github.com/DxChainNetwork/godx/storage/coinchargemaintenance"
+)
+
+// While waiting for the response in the storage negotiation, the peer is pinged periodically.
+// The ping carries the time it was sent, which is echoed back by the pong to measure the round
+// trip time. If no pong is received within storageHeartbeatTimeout, the connection is taken as
+// half-open, such as dropped silently by the NAT, and is disconnected, so that the negotiation
+// fails fast and the connection is set up again instead of waiting for the response timeout.
+
+const (
+	// storageRespTimeout is the time waiting for the response in the storage negotiation
+	storageRespTimeout = 1 * time.Minute
+
+	// storageHeartbeatInterval is the interval of the pings while waiting for the response
+	storageHeartbeatInterval = 5 * time.Second
+
+	// storageHeartbeatTimeout is the time without any pong, after which the connection is
+	// taken as dead
+	storageHeartbeatTimeout = 3 * storageHeartbeatInterval
+
+	// storageRTTDecay is the weight of the latest round trip time in the smoothed one
+	storageRTTDecay = 0.2
+)
+
+// errStorageSessionDead is returned if the peer stopped answering the pings
+var errStorageSessionDead = errors.New("storage session is dead: the peer stopped answering the heartbeat")
+
+// storageHeartbeat keeps the liveness and the round trip time of the peer measured by the pings
+type storageHeartbeat struct {
+	lastPong time.Time
+	rtt      time.Duration
+	lock     sync.Mutex
+}
+
+// pong records the pong of the ping sent at the time, and updates the smoothed round trip time
+func (hb *storageHeartbeat) pong(sent, now time.Time) {
+	hb.lock.Lock()
+	defer hb.lock.Unlock()
+	hb.lastPong = now
+	rtt := now.Sub(sent)
+	if rtt < 0 {
+		return
+	}
+	if hb.rtt == 0 {
+		hb.rtt = rtt
+		return
+	}
+	hb.rtt = time.Duration(float64(hb.rtt)*(1-storageRTTDecay) + float64(rtt)*storageRTTDecay)
+}
+
+// aliveSince checks whether the peer is alive at the time, given it is known to be alive at
+// the start. The peer is alive if it answered the ping within the heartbeat timeout
+func (hb *storageHeartbeat) aliveSince(start, now time.Time) bool {
+	hb.lock.Lock()
+	defer hb.lock.Unlock()
+	last := start
+	if hb.lastPong.After(last) {
+		last = hb.lastPong
+	}
+	return now.Sub(last) <= storageHeartbeatTimeout
+}
+
+// roundTripTime returns the smoothed round trip time, zero if never measured
+func (hb *storageHeartbeat) roundTripTime() time.Duration {
+	hb.lock.Lock()
+	defer hb.lock.Unlock()
+	return hb.rtt
+}
+
+// StorageRTT returns the smoothed round trip time of the storage session measured by the
+// heartbeat, zero if never measured
+func (p *peer) StorageRTT() time.Duration {
+	return p.heartbeat.roundTripTime()
+}
+
+// sendStoragePing sends the ping with the current time
+func (p *peer) sendStoragePing(code uint64) error {
+	if err := p.checkPeerStopHook(p); err != nil {
+		return err
+	}
+	return p2p.Send(p.rw, code, uint64(time.Now().UnixNano()))
+}
+
+// handleStoragePing echoes the time carried by the ping back with the pong
+func (p *peer) handleStoragePing(msg p2p.Msg, pongCode uint64) error {
+	var sent uint64
+	if err := msg.Decode(&sent); err != nil {
+		return err
+	}
+	return p2p.Send(p.rw, pongCode, sent)
+}
+
+// handleStoragePong records the pong of the ping sent
+func (p *peer) handleStoragePong(msg p2p.Msg) error {
+	var sent uint64
+	if err := msg.Decode(&sent); err != nil {
+		return err
+	}
+	p.heartbeat.pong(time.Unix(0, int64(sent)), time.Now())
+	return nil
+}
+
+// waitStorageResp waits for the response of the storage negotiation from the channel, pinging
+// the peer with the ping code while waiting. The peer is disconnected if it stopped answering
+// the pings, and the timeout error is returned if the response is not received in time
+func (p *peer) waitStorageResp(respChan chan p2p.Msg, pingCode uint64, timeoutErr error) (msg p2p.Msg, err error) {
+	start := time.Now()
+	timeout := time.After(storageRespTimeout)
+	ticker := time.NewTicker(storageHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg = <-respChan:
+			return
+		case now := <-ticker.C:
+			if !p.heartbeat.aliveSince(start, now) {
+				// the read loop may block on the half-open connection forever,
+				// thus the peer is disconnected directly
+				p.Log().Warn("Storage session is dead", "rtt", p.StorageRTT())
+				p.Disconnect(p2p.DiscReadTimeout)
+				err = errStorageSessionDead
+				return
+			}
+			if err := p.sendStoragePing(pingCode); err != nil {
+				p.Log().Debug("Failed to send the storage ping", "err", err)
+			}
+		case <-timeout:
+			err = timeoutErr
+			return
+		case <-p.StopChan():
+			err = coinchargemaintenance.ErrProgramExit
+			return
+		}
+	}
+}
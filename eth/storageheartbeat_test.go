@@ -0,0 +1,79 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package eth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/p2p"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestStorageHeartbeat(t *testing.T) {
+	var hb storageHeartbeat
+	start := time.Unix(1000, 0)
+	if !hb.aliveSince(start, start.Add(storageHeartbeatTimeout)) {
+		t.Error("the peer shall be alive within the heartbeat timeout since the start")
+	}
+	if hb.aliveSince(start, start.Add(storageHeartbeatTimeout+time.Second)) {
+		t.Error("the peer shall be dead without any pong beyond the heartbeat timeout")
+	}
+
+	hb.pong(start.Add(10*time.Second), start.Add(10*time.Second+100*time.Millisecond))
+	if rtt := hb.roundTripTime(); rtt != 100*time.Millisecond {
+		t.Errorf("expect the first rtt taken as is, got %v", rtt)
+	}
+	if !hb.aliveSince(start, start.Add(storageHeartbeatTimeout+time.Second)) {
+		t.Error("the peer shall be alive with the pong received recently")
+	}
+	hb.pong(start.Add(20*time.Second), start.Add(20*time.Second+200*time.Millisecond))
+	if rtt := hb.roundTripTime(); rtt != 120*time.Millisecond {
+		t.Errorf("expect the smoothed rtt 120ms, got %v", rtt)
+	}
+}
+
+func TestStoragePingPong(t *testing.T) {
+	clientRW, hostRW := p2p.MsgPipe()
+	defer clientRW.Close()
+	client := newPeer(eth64, p2p.NewPeer(enode.ID{1}, "host", nil), clientRW)
+	host := newPeer(eth64, p2p.NewPeer(enode.ID{2}, "client", nil), hostRW)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- client.sendStoragePing(storage.ClientPingMsg)
+	}()
+	msg, err := hostRW.ReadMsg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Code != storage.ClientPingMsg {
+		t.Fatalf("expect the ping, got message %x", msg.Code)
+	}
+	go func() {
+		errc <- host.handleStoragePing(msg, storage.HostPongMsg)
+	}()
+	if msg, err = clientRW.ReadMsg(); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Code != storage.HostPongMsg {
+		t.Fatalf("expect the pong, got message %x", msg.Code)
+	}
+	if err := client.handleStoragePong(msg); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if client.StorageRTT() <= 0 {
+		t.Error("the rtt shall be measured by the pong")
+	}
+	if info := client.Info(); info.StorageRTT == "" {
+		t.Error("the rtt shall be reported in the peer info")
+	}
+}
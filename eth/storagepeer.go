@@ -6,12 +6,10 @@ package eth
 
 import (
 	"errors"
-	"time"
 
 	"github.com/DxChainNetwork/godx/p2p"
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage"
-	"github.com/DxChainNetwork/godx/storage/coinchargemaintenance"
 )
 
 // TriggerError is used to send the error message to the errMsg channel,
@@ -147,6 +145,76 @@ func (p *peer) SendContractDownloadData(resp storage.DownloadResponse) error {
 	return err
 }
 
+// RequestContractAudit will be used when the storage client wants to compare its
+// view of the contract with the storage host's
+func (p *peer) RequestContractAudit(req storage.ContractAuditRequest) error {
+	var err error
+	if err = p.checkPeerStopHook(p); err == nil {
+		return p2p.Send(p.rw, storage.ContractAuditReqMsg, req)
+	}
+	return err
+}
+
+// SendContractAuditState is sent by the storage host, including the host's view
+// of the contract requested by the storage client
+func (p *peer) SendContractAuditState(state storage.ContractAuditState) error {
+	var err error
+	if err = p.checkPeerStopHook(p); err == nil {
+		return p2p.Send(p.rw, storage.ContractAuditRespMsg, state)
+	}
+	return err
+}
+
+// RequestSpeedTest will be used when the storage client wants to measure the upload
+// speed of the storage host with the probe data
+func (p *peer) RequestSpeedTest(req storage.SpeedTestRequest) error {
+	var err error
+	if err = p.checkPeerStopHook(p); err == nil {
+		return p2p.Send(p.rw, storage.SpeedTestReqMsg, req)
+	}
+	return err
+}
+
+// SendSpeedTestResp is sent by the storage host once the probe data of the speed
+// test is received
+func (p *peer) SendSpeedTestResp(resp storage.SpeedTestResponse) error {
+	var err error
+	if err = p.checkPeerStopHook(p); err == nil {
+		return p2p.Send(p.rw, storage.SpeedTestRespMsg, resp)
+	}
+	return err
+}
+
+// RequestPublishSectors will be used when the storage client wants to publish the sectors
+// of the contract, which are then served by the storage host to anyone without payment
+func (p *peer) RequestPublishSectors(req storage.PublishSectorsRequest) error {
+	var err error
+	if err = p.checkPeerStopHook(p); err == nil {
+		return p2p.Send(p.rw, storage.PublishSectorsReqMsg, req)
+	}
+	return err
+}
+
+// RequestPublicDownload will be used when the storage client wants to download the data
+// of a public sector, no storage contract is needed
+func (p *peer) RequestPublicDownload(req storage.PublicDownloadRequest) error {
+	var err error
+	if err = p.checkPeerStopHook(p); err == nil {
+		return p2p.Send(p.rw, storage.PublicDownloadReqMsg, req)
+	}
+	return err
+}
+
+// SendPublicDownloadData is sent by the storage host, including the data of the public
+// sector requested
+func (p *peer) SendPublicDownloadData(resp storage.DownloadResponse) error {
+	var err error
+	if err = p.checkPeerStopHook(p); err == nil {
+		return p2p.Send(p.rw, storage.PublicDownloadRespMsg, resp)
+	}
+	return err
+}
+
 // SendHostBusyHandleRequestErr will send a error message to client, stating that
 // the host is currently busy handling the previous error message
 func (p *peer) SendHostBusyHandleRequestErr() error {
@@ -223,49 +291,19 @@ func (p *peer) SendHostNegotiateErrorMsg() error {
 // WaitConfigResp is used by the storage client, waiting from the configuration
 // response from the storage host
 func (p *peer) WaitConfigResp() (msg p2p.Msg, err error) {
-	timeout := time.After(1 * time.Minute)
-	select {
-	case msg = <-p.clientConfigMsg:
-		return
-	case <-timeout:
-		err = errors.New("timeout -> client waits too long for config response from the host")
-		return
-	case <-p.StopChan():
-		err = coinchargemaintenance.ErrProgramExit
-		return
-	}
+	return p.waitStorageResp(p.clientConfigMsg, storage.ClientPingMsg, errors.New("timeout -> client waits too long for config response from the host"))
 }
 
 // ClientWaitContractResp is used by the storage client. The method will block the current
 // process until the response was sent back from the storage host
 func (p *peer) ClientWaitContractResp() (msg p2p.Msg, err error) {
-	timeout := time.After(1 * time.Minute)
-	select {
-	case msg = <-p.clientContractMsg:
-		return
-	case <-timeout:
-		err = errors.New("timeout -> client waits too long for contract response from the host")
-		return
-	case <-p.StopChan():
-		err = coinchargemaintenance.ErrProgramExit
-		return
-	}
+	return p.waitStorageResp(p.clientContractMsg, storage.ClientPingMsg, errors.New("timeout -> client waits too long for contract response from the host"))
 }
 
 // HostWaitContractResp is used by the storage host. The method will block the current
 // process until the response was sent back from the storage client
 func (p *peer) HostWaitContractResp() (msg p2p.Msg, err error) {
-	timeout := time.After(1 * time.Minute)
-	select {
-	case msg = <-p.hostContractMsg:
-		return
-	case <-timeout:
-		err = errors.New("timeout -> host waits too long for contract response from the host")
-		return
-	case <-p.StopChan():
-		err = coinchargemaintenance.ErrProgramExit
-		return
-	}
+	return p.waitStorageResp(p.hostContractMsg, storage.HostPingMsg, errors.New("timeout -> host waits too long for contract response from the host"))
 }
 
 // HostConfigProcessing is used to indicate that the host is currently processing
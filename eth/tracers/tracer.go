@@ -300,13 +300,16 @@ type Tracer struct {
 	ctx map[string]interface{} // Transaction context gathered throughout execution
 	err error                  // Error, if one has occurred
 
+	traceCallFrames bool // Flag whether the tracer exposes the 'enter' and 'exit' functions
+
 	interrupt uint32 // Atomic flag to signal execution interruption
 	reason    error  // Textual reason for the interruption
 }
 
 // New instantiates a new tracer instance. code specifies a Javascript snippet,
 // which must evaluate to an expression returning an object with 'step', 'fault'
-// and 'result' functions.
+// and 'result' functions. The object could optionally expose the 'enter' and
+// 'exit' functions, which are called for each nested call frame.
 func New(code string) (*Tracer, error) {
 	// Resolve any tracers by name and assemble the tracer object
 	if tracer, ok := tracer(code); ok {
@@ -433,6 +436,15 @@ func New(code string) (*Tracer, error) {
 	}
 	tracer.vm.Pop()
 
+	hasEnter := tracer.vm.GetPropString(tracer.tracerObject, "enter")
+	tracer.vm.Pop()
+	hasExit := tracer.vm.GetPropString(tracer.tracerObject, "exit")
+	tracer.vm.Pop()
+	if hasEnter != hasExit {
+		return nil, fmt.Errorf("Trace object must expose either both or none of enter() and exit()")
+	}
+	tracer.traceCallFrames = hasEnter
+
 	// Tracer is valid, inject the big int library to access large numbers
 	tracer.vm.EvalString(bigIntegerJS)
 	tracer.vm.PutGlobalString("bigInt")
@@ -597,12 +609,81 @@ func (jst *Tracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, er
 	return nil
 }
 
+// CaptureEnter is called when the execution enters a nested call frame, and calls the
+// Javascript 'enter' function with the frame.
+func (jst *Tracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	if !jst.traceCallFrames || jst.err != nil {
+		return nil
+	}
+	// If tracing was interrupted, set the error and stop
+	if atomic.LoadUint32(&jst.interrupt) > 0 {
+		jst.err = jst.reason
+		return nil
+	}
+	frame := map[string]interface{}{
+		"type":  typ.String(),
+		"from":  from,
+		"to":    to,
+		"input": common.CopyBytes(input),
+		"gas":   gas,
+	}
+	if value != nil {
+		frame["value"] = new(big.Int).Set(value)
+	}
+	jst.pushValues(frame)
+	jst.vm.PutPropString(jst.stateObject, "frame")
+
+	if _, err := jst.call("enter", "frame"); err != nil {
+		jst.err = wrapError("enter", err)
+	}
+	return nil
+}
+
+// CaptureExit is called when the execution exits a nested call frame, and calls the
+// Javascript 'exit' function with the result of the frame.
+func (jst *Tracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	if !jst.traceCallFrames || jst.err != nil {
+		return nil
+	}
+	result := map[string]interface{}{
+		"output":  common.CopyBytes(output),
+		"gasUsed": gasUsed,
+	}
+	if err != nil {
+		result["error"] = err.Error()
+	}
+	jst.pushValues(result)
+	jst.vm.PutPropString(jst.stateObject, "frameResult")
+
+	if _, err := jst.call("exit", "frameResult"); err != nil {
+		jst.err = wrapError("exit", err)
+	}
+	return nil
+}
+
 // GetResult calls the Javascript 'result' function and returns its value, or any accumulated error
 func (jst *Tracer) GetResult() (json.RawMessage, error) {
 	// Transform the context into a JavaScript object and inject into the state
+	jst.pushValues(jst.ctx)
+	jst.vm.PutPropString(jst.stateObject, "ctx")
+
+	// Finalize the trace and return the results
+	result, err := jst.call("result", "ctx", "db")
+	if err != nil {
+		jst.err = wrapError("result", err)
+	}
+	// Clean up the JavaScript environment
+	jst.vm.DestroyHeap()
+	jst.vm.Destroy()
+
+	return result, jst.err
+}
+
+// pushValues transforms the values into a JavaScript object and pushes it onto the VM stack
+func (jst *Tracer) pushValues(values map[string]interface{}) {
 	obj := jst.vm.PushObject()
 
-	for key, val := range jst.ctx {
+	for key, val := range values {
 		switch val := val.(type) {
 		case uint64:
 			jst.vm.PushUint(uint(val))
@@ -626,16 +707,4 @@ func (jst *Tracer) GetResult() (json.RawMessage, error) {
 		}
 		jst.vm.PutPropString(obj, key)
 	}
-	jst.vm.PutPropString(jst.stateObject, "ctx")
-
-	// Finalize the trace and return the results
-	result, err := jst.call("result", "ctx", "db")
-	if err != nil {
-		jst.err = wrapError("result", err)
-	}
-	// Clean up the JavaScript environment
-	jst.vm.DestroyHeap()
-	jst.vm.Destroy()
-
-	return result, jst.err
 }
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 
@@ -145,3 +146,28 @@ func TestHaltBetweenSteps(t *testing.T) {
 		t.Errorf("Expected timeout error, got %v", err)
 	}
 }
+
+func TestEnterExit(t *testing.T) {
+	// the tracer must expose either both or none of the enter and exit functions
+	if _, err := New("{step: function() {}, fault: function() {}, result: function() { return null; }, enter: function() {}}"); err == nil {
+		t.Fatal("tracer creation should've failed without exit() definition")
+	}
+	tracer, err := New(`{frames: [], step: function() {}, fault: function() {}, result: function() { return this.frames; },
+		enter: function(frame) { this.frames.push(frame.type + ':' + toHex(frame.to) + ':' + frame.gas); },
+		exit: function(res) { this.frames.push('exit:' + res.gasUsed + ':' + res.error); }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := common.HexToAddress("0x2")
+	tracer.CaptureEnter(vm.STATICCALL, common.HexToAddress("0x1"), to, nil, 100, nil)
+	tracer.CaptureExit(nil, 10, errors.New("reverted"))
+
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `["STATICCALL:` + strings.ToLower(to.Hex()) + `:100","exit:10:reverted"]`
+	if !bytes.Equal(res, []byte(want)) {
+		t.Errorf("frames not expected. Got %s, want %s", res, want)
+	}
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/DxChainNetwork/godx/crypto"
 	"github.com/DxChainNetwork/godx/log"
 	"github.com/DxChainNetwork/godx/p2p"
+	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/params"
 	"github.com/DxChainNetwork/godx/rlp"
 	"github.com/DxChainNetwork/godx/rpc"
@@ -673,6 +674,35 @@ func (s *PublicBlockChainAPI) GetStorageAt(ctx context.Context, address common.A
 	return res[:], state.Error()
 }
 
+// RPCHostAnnouncement is the latest announcement of the storage host recorded in the
+// announced host registry
+type RPCHostAnnouncement struct {
+	NetAddress  string         `json:"netAddress"`
+	Signature   hexutil.Bytes  `json:"signature"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Nonce       hexutil.Uint64 `json:"nonce"`
+}
+
+// GetHostAnnouncement returns the latest announcement of the storage host with the node id
+// recorded in the announced host registry at the given block number. Nil is returned if the
+// storage host has not announced, or the announcement is revoked
+func (s *PublicBlockChainAPI) GetHostAnnouncement(ctx context.Context, hostID enode.ID, blockNr rpc.BlockNumber) (*RPCHostAnnouncement, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	entry, exists := vm.ReadHostAnnouncement(state, hostID)
+	if !exists {
+		return nil, state.Error()
+	}
+	return &RPCHostAnnouncement{
+		NetAddress:  entry.NetAddress,
+		Signature:   entry.Signature,
+		BlockNumber: hexutil.Uint64(entry.BlockNumber),
+		Nonce:       hexutil.Uint64(entry.Nonce),
+	}, state.Error()
+}
+
 // CallArgs represents the arguments for a call.
 type CallArgs struct {
 	From     common.Address  `json:"from"`
@@ -683,12 +713,12 @@ type CallArgs struct {
 	Data     hexutil.Bytes   `json:"data"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, timeout time.Duration) ([]byte, uint64, bool, error) {
+func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, timeout time.Duration) ([]byte, uint64, error, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
 	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
-		return nil, 0, false, err
+		return nil, 0, nil, err
 	}
 	// Set sender address or use a default if none specified
 	addr := args.From
@@ -726,8 +756,11 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	// Get a new instance of the EVM.
 	evm, vmError, err := s.b.GetEVM(ctx, msg, state, header)
 	if err != nil {
-		return nil, 0, false, err
+		return nil, 0, nil, err
 	}
+	// Release the timeout timer of the evm once the call has completed
+	defer evm.Stop()
+
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
 	go func() {
@@ -738,18 +771,26 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	// Setup the gas pool (also for unmetered requests)
 	// and apply the message.
 	gp := new(core.GasPool).AddGas(math.MaxUint64)
-	res, gas, failed, err := core.ApplyMessage(evm, msg, gp)
+	res, gas, vmerr, err := core.ApplyMessageWithVMError(evm, msg, gp)
 	if err := vmError(); err != nil {
-		return nil, 0, false, err
+		return nil, 0, nil, err
 	}
-	return res, gas, failed, err
+	return res, gas, vmerr, err
 }
 
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
+// If the execution failed, the decoded revert reason or the storage contract transaction
+// failure reason is returned as error
 func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
-	result, _, _, err := s.doCall(ctx, args, blockNr, 5*time.Second)
-	return (hexutil.Bytes)(result), err
+	result, _, vmerr, err := s.doCall(ctx, args, blockNr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if vmerr != nil {
+		return nil, newExecutionError(result, vmerr)
+	}
+	return (hexutil.Bytes)(result), nil
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
@@ -774,19 +815,23 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	cap = hi
 
 	// Create a helper to check if a gas allowance results in an executable transaction
-	executable := func(gas uint64) bool {
+	executable := func(gas uint64) (bool, []byte, error) {
 		args.Gas = hexutil.Uint64(gas)
 
-		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, 0)
-		if err != nil || failed {
-			return false
+		res, _, vmerr, err := s.doCall(ctx, args, rpc.PendingBlockNumber, 0)
+		if err != nil || vmerr != nil {
+			return false, res, vmerr
 		}
-		return true
+		return true, nil, nil
 	}
 	// Execute the binary search and hone in on an executable gas limit
 	for lo+1 < hi {
 		mid := (hi + lo) / 2
-		if !executable(mid) {
+		if ok, res, vmerr := executable(mid); !ok {
+			// the timeout does not tell whether the gas is enough, so the search is aborted
+			if vmerr == vm.ErrExecutionTimeout {
+				return 0, newExecutionError(res, vmerr)
+			}
 			lo = mid
 		} else {
 			hi = mid
@@ -794,7 +839,11 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	}
 	// Reject the transaction as invalid if it still fails at the highest allowance
 	if hi == cap {
-		if !executable(hi) {
+		if ok, res, vmerr := executable(hi); !ok {
+			// surface the failure reason if the execution failed for reasons other than out of gas
+			if vmerr != nil && vmerr != vm.ErrOutOfGas && vmerr != vm.ErrCodeStoreOutOfGas {
+				return 0, newExecutionError(res, vmerr)
+			}
 			return 0, fmt.Errorf("gas required exceeds allowance or always failing transaction")
 		}
 	}
@@ -805,10 +854,11 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 // while replaying a transaction in debug mode as well as transaction
 // execution status, the amount of gas used and the return value
 type ExecutionResult struct {
-	Gas         uint64         `json:"gas"`
-	Failed      bool           `json:"failed"`
-	ReturnValue string         `json:"returnValue"`
-	StructLogs  []StructLogRes `json:"structLogs"`
+	Gas           uint64             `json:"gas"`
+	Failed        bool               `json:"failed"`
+	ReturnValue   string             `json:"returnValue"`
+	StructLogs    []StructLogRes     `json:"structLogs"`
+	StorageTxLogs []vm.StorageTxStep `json:"storageTxLogs,omitempty"`
 }
 
 // StructLogRes stores a structured log emitted by the EVM while replaying a
@@ -1164,6 +1214,15 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if receipt.Logs == nil {
 		fields["logs"] = [][]*types.Log{}
 	}
+	// Replay the failed transaction to surface the failure reason
+	if len(receipt.PostState) == 0 && receipt.Status == types.ReceiptStatusFailed {
+		reason, err := s.transactionFailureReason(ctx, blockHash, index)
+		if err != nil {
+			log.Debug("Failed to retrieve the transaction failure reason", "hash", hash, "err", err)
+		} else if reason != "" {
+			fields["failureReason"] = reason
+		}
+	}
 	// If the ContractAddress is 20 0x0 bytes, assume it is not a contract creation
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
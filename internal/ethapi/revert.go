@@ -0,0 +1,129 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/common/hexutil"
+	"github.com/DxChainNetwork/godx/core"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/core/vm"
+	"github.com/DxChainNetwork/godx/rpc"
+)
+
+// revertErrorCode is the RPC error code of the reverted execution
+const revertErrorCode = 3
+
+// revertError is the error returned when the execution is reverted. The raw data
+// returned by the REVERT opcode is attached as the RPC error data
+type revertError struct {
+	error
+	data string
+}
+
+// ErrorCode returns the RPC error code of the reverted execution
+func (e *revertError) ErrorCode() int {
+	return revertErrorCode
+}
+
+// ErrorData returns the hex encoded data returned by the REVERT opcode
+func (e *revertError) ErrorData() interface{} {
+	return e.data
+}
+
+// timeoutErrorCode is the RPC error code of the execution exceeding the time budget,
+// which is the limit exceeded code of EIP-1474
+const timeoutErrorCode = -32005
+
+// timeoutError is the error returned when the execution exceeds the time budget of
+// the node, so that the timeout is told apart from the reverted execution
+type timeoutError struct {
+	error
+}
+
+// ErrorCode returns the RPC error code of the execution timeout
+func (e *timeoutError) ErrorCode() int {
+	return timeoutErrorCode
+}
+
+// newExecutionError converts the error of the EVM execution into the error returned to
+// the RPC caller. For the reverted execution, the Error(string) revert reason is decoded
+// from the returned data. For the storage contract transactions, the error returned by
+// the validation is used as the failure reason
+func newExecutionError(ret []byte, vmerr error) error {
+	if vmerr == vm.ErrExecutionTimeout {
+		return &timeoutError{vmerr}
+	}
+	if vmerr != vm.ErrExecutionReverted {
+		return fmt.Errorf("execution failed: %v", vmerr)
+	}
+
+	err := errors.New("execution reverted")
+	if reason, errUnpack := vm.UnpackRevertReason(ret); errUnpack == nil {
+		err = fmt.Errorf("execution reverted: %v", reason)
+	}
+	return &revertError{
+		error: err,
+		data:  hexutil.Encode(ret),
+	}
+}
+
+// transactionFailureReason replays the failed transaction on top of the state of the
+// parent block to recover the reason why the transaction failed
+func (s *PublicTransactionPoolAPI) transactionFailureReason(ctx context.Context, blockHash common.Hash, index uint64) (string, error) {
+	block, err := s.b.GetBlock(ctx, blockHash)
+	if err != nil {
+		return "", err
+	}
+	if block == nil || block.NumberU64() == 0 {
+		return "", fmt.Errorf("block %x not found", blockHash)
+	}
+	if index >= uint64(len(block.Transactions())) {
+		return "", fmt.Errorf("transaction index %v out of range", index)
+	}
+
+	statedb, _, err := s.b.StateAndHeaderByNumber(ctx, rpc.BlockNumber(block.NumberU64()-1))
+	if statedb == nil || err != nil {
+		return "", fmt.Errorf("state of the parent block %v not available: %v", block.NumberU64()-1, err)
+	}
+
+	config := s.b.ChainConfig()
+	signer := types.MakeSigner(config, block.Number())
+	gp := new(core.GasPool).AddGas(block.GasLimit())
+	for i, tx := range block.Transactions() {
+		msg, err := tx.AsMessage(signer)
+		if err != nil {
+			return "", err
+		}
+		statedb.Prepare(tx.Hash(), blockHash, i)
+
+		evm, vmError, err := s.b.GetEVM(ctx, msg, statedb, block.Header())
+		if err != nil {
+			return "", err
+		}
+		ret, _, vmerr, err := core.ApplyMessageWithVMError(evm, msg, gp)
+		evm.Stop()
+		if err := vmError(); err != nil {
+			return "", err
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to replay transaction %x: %v", tx.Hash(), err)
+		}
+
+		if uint64(i) < index {
+			statedb.Finalise(config.IsEIP158(block.Number()))
+			continue
+		}
+		if vmerr == nil {
+			return "", nil
+		}
+		return newExecutionError(ret, vmerr).Error(), nil
+	}
+	return "", nil
+}
@@ -10,11 +10,23 @@ import (
 	"github.com/DxChainNetwork/godx/accounts"
 	"github.com/DxChainNetwork/godx/common"
 	"github.com/DxChainNetwork/godx/common/hexutil"
+	"github.com/DxChainNetwork/godx/core"
 	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/core/vm"
+	"github.com/DxChainNetwork/godx/params"
 	"github.com/DxChainNetwork/godx/rlp"
+	"github.com/DxChainNetwork/godx/rpc"
 	"math/big"
 )
 
+// ErrStorageTxNotPending is the error that the storage contract tx to replace is no longer
+// pending in the tx pool, either mined or dropped
+var ErrStorageTxNotPending = errors.New("storage contract tx is not pending in the tx pool")
+
+// ErrStorageTxFeeCeiling is the error that the gas price of the storage contract tx cannot be
+// raised any further without exceeding the gas price ceiling
+var ErrStorageTxFeeCeiling = errors.New("storage contract tx gas price reaches the ceiling")
+
 // PrivateStorageContractTxAPI exposes the SendHostAnnounceTx methods for the RPC interface
 type PrivateStorageContractTxAPI struct {
 	b         Backend
@@ -56,11 +68,59 @@ func (psc *PrivateStorageContractTxAPI) SendHostAnnounceTX(from common.Address)
 	return txHash, nil
 }
 
-// send form contract tx, generally triggered in ContractCreate, not for outer request
+// send host revoke tx, retract the host announcement of the node
+func (psc *PrivateStorageContractTxAPI) SendHostRevokeTX(from common.Address) (common.Hash, error) {
+	hostRevocation := types.HostRevocation{
+		NetAddress:  psc.b.GetHostEnodeURL(),
+		BlockNumber: psc.b.CurrentBlock().NumberU64(),
+	}
+
+	hash := hostRevocation.RLPHash()
+	sign, err := psc.b.SignByNode(hash.Bytes())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hostRevocation.Signature = sign
+
+	payload, err := rlp.EncodeToBytes(hostRevocation)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	to := common.Address{}
+	to.SetBytes([]byte{15})
+
+	ctx := context.Background()
+	txHash, err := sendStorageContractTX(ctx, psc.b, psc.nonceLock, from, to, payload)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return txHash, nil
+}
+
+// send form contract tx, generally triggered in ContractCreate, not for outer request. The tx is
+// simulated against the pending state first, so that the storage contract failing the validation
+// is not broadcast to waste the gas
 func (psc *PrivateStorageContractTxAPI) SendContractCreateTX(from common.Address, input []byte) (common.Hash, error) {
 	to := common.Address{}
 	to.SetBytes([]byte{10})
 	ctx := context.Background()
+	if _, err := simulateStorageContractTX(ctx, psc.b, from, to, input); err != nil {
+		return common.Hash{}, err
+	}
+	txHash, err := sendStorageContractTX(ctx, psc.b, psc.nonceLock, from, to, input)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return txHash, nil
+}
+
+// send contract renew tx, the input is the rlp encoded storage contract renewal signed by both
+// the client and the host, not for outer request
+func (psc *PrivateStorageContractTxAPI) SendContractRenewTX(from common.Address, input []byte) (common.Hash, error) {
+	to := common.Address{}
+	to.SetBytes([]byte{17})
+	ctx := context.Background()
 	txHash, err := sendStorageContractTX(ctx, psc.b, psc.nonceLock, from, to, input)
 	if err != nil {
 		return common.Hash{}, err
@@ -92,6 +152,145 @@ func (psc *PrivateStorageContractTxAPI) SendStorageProofTX(from common.Address,
 	return txHash, nil
 }
 
+// send batch storage proof tx, the input is the rlp encoded storage proof batch. The gas is
+// set to cover the check of every proof in the batch. Only triggered when host received
+// consensus change, not for outer request
+func (psc *PrivateStorageContractTxAPI) SendBatchStorageProofTX(from common.Address, input []byte) (common.Hash, error) {
+	var batch types.StorageProofBatch
+	if err := rlp.DecodeBytes(input, &batch); err != nil {
+		return common.Hash{}, err
+	}
+	gas, err := core.IntrinsicGas(input, false, true)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	gas += params.DecodeGas + uint64(len(batch.Proofs))*params.CheckFileGas
+
+	to := common.Address{}
+	to.SetBytes([]byte{16})
+	args := SendStorageContractTxArgs{
+		From:  from,
+		To:    to,
+		Gas:   (*hexutil.Uint64)(&gas),
+		Input: (*hexutil.Bytes)(&input),
+	}
+	return signAndSendStorageContractTX(context.Background(), psc.b, psc.nonceLock, args)
+}
+
+// send escrow fund tx, fund the escrow account of the renter from the treasury account from,
+// and set the spending cap of the renter
+func (psc *PrivateStorageContractTxAPI) SendEscrowFundTX(from common.Address, renter common.Address, amount *hexutil.Big, spendingCap *hexutil.Big) (common.Hash, error) {
+	if amount == nil || spendingCap == nil {
+		return common.Hash{}, errors.New("the escrow fund amount and spending cap must be specified")
+	}
+	escrow := types.StorageEscrow{
+		Treasury:    from,
+		Renter:      renter,
+		Amount:      amount.ToInt(),
+		SpendingCap: spendingCap.ToInt(),
+	}
+	payload, err := rlp.EncodeToBytes(escrow)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	to := common.Address{}
+	to.SetBytes([]byte{13})
+	ctx := context.Background()
+	txHash, err := sendStorageContractTX(ctx, psc.b, psc.nonceLock, from, to, payload)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return txHash, nil
+}
+
+// BumpStorageContractTX replaces the pending storage contract tx with the same tx paying a
+// higher gas price, through the replacement mechanism of the tx pool. The gas price is raised
+// by the price bump percentage, and at least to the suggested gas price, but no more than the
+// max gas price if specified
+func (psc *PrivateStorageContractTxAPI) BumpStorageContractTX(from common.Address, txHash common.Hash, priceBump uint64, maxGasPrice *big.Int) (common.Hash, error) {
+	tx := psc.b.GetPoolTransaction(txHash)
+	if tx == nil || tx.To() == nil {
+		return common.Hash{}, ErrStorageTxNotPending
+	}
+	ctx := context.Background()
+	suggested, err := psc.b.SuggestPrice(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	gasPrice := new(big.Int).Div(new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(100+priceBump)), big.NewInt(100))
+	if gasPrice.Cmp(tx.GasPrice()) <= 0 {
+		gasPrice = new(big.Int).Add(tx.GasPrice(), big.NewInt(1))
+	}
+	if gasPrice.Cmp(suggested) < 0 {
+		gasPrice = suggested
+	}
+	if maxGasPrice != nil && gasPrice.Cmp(maxGasPrice) > 0 {
+		// the tx pool rejects the replacement not paying the full price bump
+		minPrice := new(big.Int).Div(new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(100+priceBump)), big.NewInt(100))
+		if maxGasPrice.Cmp(minPrice) < 0 || maxGasPrice.Cmp(tx.GasPrice()) <= 0 {
+			return common.Hash{}, ErrStorageTxFeeCeiling
+		}
+		gasPrice = new(big.Int).Set(maxGasPrice)
+	}
+
+	gas, nonce, input := tx.Gas(), tx.Nonce(), tx.Data()
+	args := SendStorageContractTxArgs{
+		From:     from,
+		To:       *tx.To(),
+		Gas:      (*hexutil.Uint64)(&gas),
+		GasPrice: (*hexutil.Big)(gasPrice),
+		Nonce:    (*hexutil.Uint64)(&nonce),
+		Input:    (*hexutil.Bytes)(&input),
+	}
+	return signAndSendStorageContractTX(ctx, psc.b, psc.nonceLock, args)
+}
+
+// SimulateStorageContractTX runs the storage contract tx against the pending state without
+// writing anything, and returns the gas the tx would consume, including the intrinsic gas.
+// The validation error is returned if the tx would fail
+func (psc *PrivateStorageContractTxAPI) SimulateStorageContractTX(ctx context.Context, from common.Address, to common.Address, input hexutil.Bytes) (hexutil.Uint64, error) {
+	gas, err := simulateStorageContractTX(ctx, psc.b, from, to, input)
+	return hexutil.Uint64(gas), err
+}
+
+// simulateStorageContractTX simulates the storage contract tx sent to the precompiled address to
+// against the pending state, with the gas limit of the pending block
+func simulateStorageContractTX(ctx context.Context, b Backend, from, to common.Address, input []byte) (uint64, error) {
+	txType, ok := vm.PrecompiledEVMFileContracts[to]
+	if !ok {
+		return 0, errors.New("not a storage contract tx address")
+	}
+	intrinsicGas, err := core.IntrinsicGas(input, false, true)
+	if err != nil {
+		return 0, err
+	}
+
+	state, header, err := b.StateAndHeaderByNumber(ctx, rpc.PendingBlockNumber)
+	if state == nil || err != nil {
+		return 0, err
+	}
+	if header.GasLimit < intrinsicGas {
+		return 0, vm.ErrOutOfGas
+	}
+	gas := header.GasLimit - intrinsicGas
+
+	msg := types.NewMessage(from, &to, 0, new(big.Int), header.GasLimit, new(big.Int), input, false)
+	evm, vmError, err := b.GetEVM(ctx, msg, state, header)
+	if err != nil {
+		return 0, err
+	}
+	defer evm.Stop()
+	if _, ok := evm.StorageContractTxType(to); !ok {
+		return 0, vm.ErrStorageContractNotActivated
+	}
+	gasUsed, err := evm.SimulateStorageContractTransaction(vm.AccountRef(from), txType, input, gas)
+	if vmErr := vmError(); vmErr != nil {
+		return 0, vmErr
+	}
+	return intrinsicGas + gasUsed, err
+}
+
 // send storage contract tx，only need from、to、input（rlp encoded）
 //
 // NOTE: this is general func, you can construct different args to send 4 type txs, like host announce、form contract、contract revision、storage proof.
@@ -104,7 +303,13 @@ func sendStorageContractTX(ctx context.Context, b Backend, nonceLock *AddrLocker
 		To:   to,
 	}
 	args.Input = (*hexutil.Bytes)(&input)
+	return signAndSendStorageContractTX(ctx, b, nonceLock, args)
+}
 
+// signAndSendStorageContractTX signs the storage contract tx constructed with the args, and
+// sends it to the tx pool. The gas, gas price and nonce not specified in the args are filled
+// with the default values
+func signAndSendStorageContractTX(ctx context.Context, b Backend, nonceLock *AddrLocker, args SendStorageContractTxArgs) (common.Hash, error) {
 	// find the account of the address from
 	account := accounts.Account{Address: args.From}
 	wallet, err := b.AccountManager().Find(account)
@@ -153,20 +358,26 @@ type SendStorageContractTxArgs struct {
 
 // construct tx with args
 func (args *SendStorageContractTxArgs) setDefaultsTX(ctx context.Context, b Backend) (*types.Transaction, error) {
-	args.Gas = new(hexutil.Uint64)
-	*(*uint64)(args.Gas) = 90000
+	if args.Gas == nil {
+		args.Gas = new(hexutil.Uint64)
+		*(*uint64)(args.Gas) = 90000
+	}
 
-	price, err := b.SuggestPrice(ctx)
-	if err != nil {
-		return nil, err
+	if args.GasPrice == nil {
+		price, err := b.SuggestPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		args.GasPrice = (*hexutil.Big)(price)
 	}
-	args.GasPrice = (*hexutil.Big)(price)
 
-	nonce, err := b.GetPoolNonce(ctx, args.From)
-	if err != nil {
-		return nil, err
+	if args.Nonce == nil {
+		nonce, err := b.GetPoolNonce(ctx, args.From)
+		if err != nil {
+			return nil, err
+		}
+		args.Nonce = (*hexutil.Uint64)(&nonce)
 	}
-	args.Nonce = (*hexutil.Uint64)(&nonce)
 
 	if args.To == (common.Address{}) || args.Input == nil {
 		return nil, errors.New(`storage contract tx without to or input`)
@@ -126,6 +126,30 @@ type Config struct {
 	// interface.
 	HTTPTimeouts rpc.HTTPTimeouts
 
+	// HTTPAuthTokens is a list of access tokens in the format of token:tier, where the tier
+	// is either read or full. If set, the calls to the HTTPAuthNamespaces over HTTP must
+	// carry one of the tokens as the bearer token, and the read only tokens can only query
+	// the status.
+	HTTPAuthTokens []string `toml:",omitempty"`
+
+	// HTTPAuthNamespaces is a list of API namespaces protected by the HTTPAuthTokens.
+	HTTPAuthNamespaces []string `toml:",omitempty"`
+
+	// HTTPAuthReadOnlyMethods is a list of methods of the HTTPAuthNamespaces the read
+	// only tokens can call, such as sclient_hosts. The other methods are denied for the
+	// read only tokens.
+	HTTPAuthReadOnlyMethods []string `toml:",omitempty"`
+
+	// HTTPRateLimit is the number of calls allowed per second for each method of the
+	// protected namespaces and each token, and HTTPRateBurst is the number of calls
+	// allowed at once. Zero HTTPRateLimit disables the rate limit.
+	HTTPRateLimit float64 `toml:",omitempty"`
+	HTTPRateBurst int     `toml:",omitempty"`
+
+	// HTTPMethodRateLimits overrides the HTTPRateLimit of the methods, keyed by the
+	// method name such as sclient_upload.
+	HTTPMethodRateLimits map[string]float64 `toml:",omitempty"`
+
 	// WSHost is the host interface on which to start the websocket RPC server. If
 	// this field is empty, no websocket API endpoint will be started.
 	WSHost string `toml:",omitempty"`
@@ -214,6 +238,26 @@ func (c *Config) HTTPEndpoint() string {
 	return fmt.Sprintf("%s:%d", c.HTTPHost, c.HTTPPort)
 }
 
+// HTTPAccessConfig returns the access control of the HTTP RPC interface, nil if no access
+// token is configured
+func (c *Config) HTTPAccessConfig() (*rpc.AccessConfig, error) {
+	if len(c.HTTPAuthTokens) == 0 {
+		return nil, nil
+	}
+	tokens, err := rpc.ParseAccessTokens(c.HTTPAuthTokens)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.AccessConfig{
+		Namespaces:       c.HTTPAuthNamespaces,
+		Tokens:           tokens,
+		ReadOnlyMethods:  c.HTTPAuthReadOnlyMethods,
+		RateLimit:        c.HTTPRateLimit,
+		RateBurst:        c.HTTPRateBurst,
+		MethodRateLimits: c.HTTPMethodRateLimits,
+	}, nil
+}
+
 // DefaultHTTPEndpoint returns the HTTP endpoint used by default.
 func DefaultHTTPEndpoint() string {
 	config := &Config{HTTPHost: DefaultHTTPHost, HTTPPort: DefaultHTTPPort}
@@ -34,15 +34,42 @@ const (
 	DefaultWSPort   = 11689       // Default TCP port for the websocket RPC server
 )
 
+// DefaultHTTPAuthReadOnlyMethods are the methods of the protected namespaces querying the
+// status only, which the read only tokens can call by default. The methods reading the local
+// files or contacting the hosts are not included
+var DefaultHTTPAuthReadOnlyMethods = []string{
+	"sclient_config", "sclient_hosts", "sclient_host", "sclient_hostRank", "sclient_contracts",
+	"sclient_contract", "sclient_archivedContracts", "sclient_archivedContract",
+	"sclient_contractFundHistory", "sclient_contractAuditState", "sclient_paymentAddress",
+	"sclient_pausedHosts", "sclient_slowHosts", "sclient_workerPool", "sclient_bandwidth",
+	"sclient_downloadBudget", "sclient_hostSnapshot", "sclient_hostScores",
+	"sclient_uploadReceipt", "sclient_fileHistory", "sclient_lastTriage",
+	"sclient_hostBackfillProgress", "sclient_coordinationStatus", "sclient_replicaStatus",
+	"sclient_operations", "sclient_periodCost", "sclient_maintenanceResult", "sclient_pendingTxs",
+	"sclient_backup",
+	"clientfiles_rootDir", "clientfiles_persistDir", "clientfiles_detailedFileInfo",
+	"clientfiles_fileList", "clientfiles_uploads",
+	"shost_version", "shost_sectorSize", "shost_persistDir", "shost_folders",
+	"shost_availableSpace", "shost_getHostConfig", "shost_getFinancialMetrics",
+	"shost_getPaymentAddress", "shost_sectorStore", "shost_compressionStats",
+	"shost_sectorCompression", "shost_proofAlerts", "shost_pendingPayouts", "shost_maintenance",
+	"shost_pendingTxs", "shost_ingressPolicy", "shost_contractAuditState",
+	"shost_archivedResponsibility",
+	"storagetx_simulateStorageContractTX",
+}
+
 // DefaultConfig contains reasonable default settings.
 var DefaultConfig = Config{
-	DataDir:          DefaultDataDir(),
-	HTTPPort:         DefaultHTTPPort,
-	HTTPModules:      []string{"net", "web3"},
-	HTTPVirtualHosts: []string{"localhost"},
-	HTTPTimeouts:     rpc.DefaultHTTPTimeouts,
-	WSPort:           DefaultWSPort,
-	WSModules:        []string{"net", "web3"},
+	DataDir:                 DefaultDataDir(),
+	HTTPPort:                DefaultHTTPPort,
+	HTTPModules:             []string{"net", "web3"},
+	HTTPVirtualHosts:        []string{"localhost"},
+	HTTPTimeouts:            rpc.DefaultHTTPTimeouts,
+	HTTPAuthNamespaces:      []string{"sclient", "shost", "clientfiles", "storagetx"},
+	HTTPAuthReadOnlyMethods: DefaultHTTPAuthReadOnlyMethods,
+	HTTPRateBurst:           10,
+	WSPort:                  DefaultWSPort,
+	WSModules:               []string{"net", "web3"},
 	P2P: p2p.Config{
 		ListenAddr: ":36000",
 		MaxPeers:   25,
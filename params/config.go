@@ -111,16 +111,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), 0, 0, new(EthashConfig), nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), 0, 0, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), 0, 0, new(EthashConfig), nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -159,6 +159,57 @@ type ChainConfig struct {
 	ByzantiumBlock      *big.Int `json:"byzantiumBlock,omitempty"`      // Byzantium switch block (nil = no fork, 0 = already on byzantium)
 	ConstantinopleBlock *big.Int `json:"constantinopleBlock,omitempty"` // Constantinople switch block (nil = no fork, 0 = already activated)
 	EWASMBlock          *big.Int `json:"ewasmBlock,omitempty"`          // EWASM switch block (nil = no fork, 0 = already activated)
+	StorageStatusBlock  *big.Int `json:"storageStatusBlock,omitempty"`  // Storage contract status precompile switch block (nil = no fork, 0 = already activated)
+	StorageIDBlock      *big.Int `json:"storageIDBlock,omitempty"`      // Storage contract id precompile switch block (nil = no fork, 0 = already activated)
+
+	// The storage contract txs are valid from StorageContractBlock. Unlike the other forks, nil
+	// means the storage contract txs are valid since the genesis, which keeps the chains created
+	// before the fork valid. The chains activating the DX storage later set the fork block
+	StorageContractBlock *big.Int `json:"storageContractBlock,omitempty"` // Storage contract tx switch block (nil = activated since genesis)
+
+	// The host announcements are recorded in the announced host registry from HostRegistryBlock,
+	// and the announcement repeating the registered one is rejected
+	HostRegistryBlock *big.Int `json:"hostRegistryBlock,omitempty"` // Announced host registry switch block (nil = no fork, 0 = already activated)
+
+	// The state changes made by the failed storage contract txs are reverted from StorageRevertBlock,
+	// the same as the failed contract calls
+	StorageRevertBlock *big.Int `json:"storageRevertBlock,omitempty"` // Failed storage contract tx revert switch block (nil = no fork, 0 = already activated)
+
+	// The storage contract tx payloads must be the canonical rlp encoding from StorageStrictDecodeBlock
+	StorageStrictDecodeBlock *big.Int `json:"storageStrictDecodeBlock,omitempty"` // Storage contract tx strict decoding switch block (nil = no fork, 0 = already activated)
+
+	// The escrow fund txs are valid from EscrowFundBlock, and the storage contracts paid by the
+	// escrow accounts are checked against the spending cap
+	EscrowFundBlock *big.Int `json:"escrowFundBlock,omitempty"` // Escrow fund tx switch block (nil = no fork, 0 = already activated)
+
+	// The host revoke txs retracting the host announcements are valid from HostRevokeBlock
+	HostRevokeBlock *big.Int `json:"hostRevokeBlock,omitempty"` // Host revoke tx switch block (nil = no fork, 0 = already activated)
+
+	// The batch storage proof txs submitting the storage proofs of multiple contracts at once are
+	// valid from BatchProofBlock
+	BatchProofBlock *big.Int `json:"batchProofBlock,omitempty"` // Batch storage proof tx switch block (nil = no fork, 0 = already activated)
+
+	// From StorageAtomicBlock, the debits of the storage contract txs are checked against the
+	// balance left, and the status account is not created for the storage contract already existing
+	StorageAtomicBlock *big.Int `json:"storageAtomicBlock,omitempty"` // Storage contract tx balance check switch block (nil = no fork, 0 = already activated)
+
+	// The contract renew txs replacing the storage contracts with their successors are valid from
+	// ContractRenewBlock
+	ContractRenewBlock *big.Int `json:"contractRenewBlock,omitempty"` // Contract renew tx switch block (nil = no fork, 0 = already activated)
+
+	// The storage contract txs emit the logs of the storage contract lifecycle events from
+	// StorageLogsBlock
+	StorageLogsBlock *big.Int `json:"storageLogsBlock,omitempty"` // Storage contract logs switch block (nil = no fork, 0 = already activated)
+
+	// From MultiSigBlock, the storage contracts and revisions are checked against the M-of-N unlock
+	// conditions, with the signatures in the order of the payment addresses
+	MultiSigBlock *big.Int `json:"multiSigBlock,omitempty"` // Storage contract multi-signature switch block (nil = no fork, 0 = already activated)
+
+	// The call depth and code size limits of the private deployments, which take effect
+	// from LimitsBlock. The zero limit keeps the default value
+	LimitsBlock    *big.Int `json:"limitsBlock,omitempty"`    // Configurable limits switch block (nil = no fork, 0 = already activated)
+	CallDepthLimit uint64   `json:"callDepthLimit,omitempty"` // Maximum depth of call/create stack (0 = CallCreateDepth)
+	CodeSizeLimit  uint64   `json:"codeSizeLimit,omitempty"`  // Maximum bytecode to permit for a contract (0 = MaxCodeSize)
 
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
@@ -249,6 +300,110 @@ func (c *ChainConfig) IsEWASM(num *big.Int) bool {
 	return isForked(c.EWASMBlock, num)
 }
 
+// IsStorageStatus returns whether num is either equal to the storage contract status fork block
+// or greater, from which the storage contract status can be queried by the smart contracts
+func (c *ChainConfig) IsStorageStatus(num *big.Int) bool {
+	return isForked(c.StorageStatusBlock, num)
+}
+
+// IsStorageID returns whether num is either equal to the storage contract id fork block or
+// greater, from which the storage contract id can be derived by the smart contracts
+func (c *ChainConfig) IsStorageID(num *big.Int) bool {
+	return isForked(c.StorageIDBlock, num)
+}
+
+// IsStorageContract returns whether num is either equal to the storage contract fork block or
+// greater, from which the storage contract txs are valid. The storage contract txs are valid
+// since the genesis if the fork block is not set
+func (c *ChainConfig) IsStorageContract(num *big.Int) bool {
+	if c.StorageContractBlock == nil {
+		return true
+	}
+	return isForked(c.StorageContractBlock, num)
+}
+
+// IsHostRegistry returns whether num is either equal to the announced host registry fork block
+// or greater, from which the host announcements are recorded and the duplicates are rejected
+func (c *ChainConfig) IsHostRegistry(num *big.Int) bool {
+	return isForked(c.HostRegistryBlock, num)
+}
+
+// IsStorageRevert returns whether num is either equal to the storage revert fork block or greater,
+// from which the state changes made by the failed storage contract txs are reverted
+func (c *ChainConfig) IsStorageRevert(num *big.Int) bool {
+	return isForked(c.StorageRevertBlock, num)
+}
+
+// IsStorageStrictDecode returns whether num is either equal to the storage strict decoding fork block
+// or greater, from which the non-canonical storage contract tx payloads are rejected
+func (c *ChainConfig) IsStorageStrictDecode(num *big.Int) bool {
+	return isForked(c.StorageStrictDecodeBlock, num)
+}
+
+// IsEscrowFund returns whether num is either equal to the escrow fund fork block or greater, from
+// which the escrow fund txs are valid and the escrow spending is checked
+func (c *ChainConfig) IsEscrowFund(num *big.Int) bool {
+	return isForked(c.EscrowFundBlock, num)
+}
+
+// IsHostRevoke returns whether num is either equal to the host revoke fork block or greater, from
+// which the host revoke txs are valid
+func (c *ChainConfig) IsHostRevoke(num *big.Int) bool {
+	return isForked(c.HostRevokeBlock, num)
+}
+
+// IsBatchProof returns whether num is either equal to the batch proof fork block or greater, from
+// which the batch storage proof txs are valid
+func (c *ChainConfig) IsBatchProof(num *big.Int) bool {
+	return isForked(c.BatchProofBlock, num)
+}
+
+// IsStorageAtomic returns whether num is either equal to the storage atomic fork block or greater,
+// from which the debits of the storage contract txs are checked against the balance left
+func (c *ChainConfig) IsStorageAtomic(num *big.Int) bool {
+	return isForked(c.StorageAtomicBlock, num)
+}
+
+// IsContractRenew returns whether num is either equal to the contract renew fork block or greater,
+// from which the contract renew txs are valid
+func (c *ChainConfig) IsContractRenew(num *big.Int) bool {
+	return isForked(c.ContractRenewBlock, num)
+}
+
+// IsStorageLogs returns whether num is either equal to the storage logs fork block or greater, from
+// which the storage contract txs emit the logs of the storage contract events
+func (c *ChainConfig) IsStorageLogs(num *big.Int) bool {
+	return isForked(c.StorageLogsBlock, num)
+}
+
+// IsMultiSig returns whether num is either equal to the multi-signature fork block or greater,
+// from which the storage contract signatures are checked against the M-of-N unlock conditions
+func (c *ChainConfig) IsMultiSig(num *big.Int) bool {
+	return isForked(c.MultiSigBlock, num)
+}
+
+// IsLimits returns whether num is either equal to the configurable limits fork block or greater,
+// from which the configured call depth and code size limits take effect
+func (c *ChainConfig) IsLimits(num *big.Int) bool {
+	return isForked(c.LimitsBlock, num)
+}
+
+// CallCreateDepth returns the maximum depth of the call/create stack at the block num
+func (c *ChainConfig) CallCreateDepth(num *big.Int) uint64 {
+	if c.IsLimits(num) && c.CallDepthLimit != 0 {
+		return c.CallDepthLimit
+	}
+	return CallCreateDepth
+}
+
+// MaxCodeSize returns the maximum bytecode size permitted for a contract at the block num
+func (c *ChainConfig) MaxCodeSize(num *big.Int) uint64 {
+	if c.IsLimits(num) && c.CodeSizeLimit != 0 {
+		return c.CodeSizeLimit
+	}
+	return MaxCodeSize
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -317,6 +472,51 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if isForkIncompatible(c.StorageStatusBlock, newcfg.StorageStatusBlock, head) {
+		return newCompatError("storage status fork block", c.StorageStatusBlock, newcfg.StorageStatusBlock)
+	}
+	if isForkIncompatible(c.StorageIDBlock, newcfg.StorageIDBlock, head) {
+		return newCompatError("storage id fork block", c.StorageIDBlock, newcfg.StorageIDBlock)
+	}
+	if isStorageContractIncompatible(c.StorageContractBlock, newcfg.StorageContractBlock, head) {
+		return newCompatError("storage contract fork block", c.StorageContractBlock, newcfg.StorageContractBlock)
+	}
+	if isForkIncompatible(c.HostRegistryBlock, newcfg.HostRegistryBlock, head) {
+		return newCompatError("host registry fork block", c.HostRegistryBlock, newcfg.HostRegistryBlock)
+	}
+	if isForkIncompatible(c.StorageRevertBlock, newcfg.StorageRevertBlock, head) {
+		return newCompatError("storage revert fork block", c.StorageRevertBlock, newcfg.StorageRevertBlock)
+	}
+	if isForkIncompatible(c.StorageStrictDecodeBlock, newcfg.StorageStrictDecodeBlock, head) {
+		return newCompatError("storage strict decoding fork block", c.StorageStrictDecodeBlock, newcfg.StorageStrictDecodeBlock)
+	}
+	if isForkIncompatible(c.EscrowFundBlock, newcfg.EscrowFundBlock, head) {
+		return newCompatError("escrow fund fork block", c.EscrowFundBlock, newcfg.EscrowFundBlock)
+	}
+	if isForkIncompatible(c.HostRevokeBlock, newcfg.HostRevokeBlock, head) {
+		return newCompatError("host revoke fork block", c.HostRevokeBlock, newcfg.HostRevokeBlock)
+	}
+	if isForkIncompatible(c.BatchProofBlock, newcfg.BatchProofBlock, head) {
+		return newCompatError("batch proof fork block", c.BatchProofBlock, newcfg.BatchProofBlock)
+	}
+	if isForkIncompatible(c.StorageAtomicBlock, newcfg.StorageAtomicBlock, head) {
+		return newCompatError("storage atomic fork block", c.StorageAtomicBlock, newcfg.StorageAtomicBlock)
+	}
+	if isForkIncompatible(c.ContractRenewBlock, newcfg.ContractRenewBlock, head) {
+		return newCompatError("contract renew fork block", c.ContractRenewBlock, newcfg.ContractRenewBlock)
+	}
+	if isForkIncompatible(c.StorageLogsBlock, newcfg.StorageLogsBlock, head) {
+		return newCompatError("storage logs fork block", c.StorageLogsBlock, newcfg.StorageLogsBlock)
+	}
+	if isForkIncompatible(c.MultiSigBlock, newcfg.MultiSigBlock, head) {
+		return newCompatError("multi-signature fork block", c.MultiSigBlock, newcfg.MultiSigBlock)
+	}
+	if isForkIncompatible(c.LimitsBlock, newcfg.LimitsBlock, head) {
+		return newCompatError("limits fork block", c.LimitsBlock, newcfg.LimitsBlock)
+	}
+	if c.IsLimits(head) && (c.CallDepthLimit != newcfg.CallDepthLimit || c.CodeSizeLimit != newcfg.CodeSizeLimit) {
+		return newCompatError("call depth and code size limits", c.LimitsBlock, newcfg.LimitsBlock)
+	}
 	return nil
 }
 
@@ -326,6 +526,19 @@ func isForkIncompatible(s1, s2, head *big.Int) bool {
 	return (isForked(s1, head) || isForked(s2, head)) && !configNumEqual(s1, s2)
 }
 
+// isStorageContractIncompatible returns true if the storage contract fork scheduled at s1 cannot
+// be rescheduled to block s2 because head is already past the fork. The nil fork block is
+// regarded as the fork at the genesis
+func isStorageContractIncompatible(s1, s2, head *big.Int) bool {
+	if s1 == nil {
+		s1 = new(big.Int)
+	}
+	if s2 == nil {
+		s2 = new(big.Int)
+	}
+	return isForkIncompatible(s1, s2, head)
+}
+
 // isForked returns whether a fork scheduled at block s is active at the given head block.
 func isForked(s, head *big.Int) bool {
 	if s == nil || head == nil {
@@ -384,6 +597,16 @@ type Rules struct {
 	ChainID                                   *big.Int
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158 bool
 	IsByzantium, IsConstantinople             bool
+	IsStorageContract                         bool
+	IsStorageRevert                           bool
+	IsStorageStrictDecode                     bool
+	IsEscrowFund                              bool
+	IsHostRevoke                              bool
+	IsBatchProof                              bool
+	IsStorageAtomic                           bool
+	IsContractRenew                           bool
+	IsStorageLogs                             bool
+	IsMultiSig                                bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -393,12 +616,22 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		chainID = new(big.Int)
 	}
 	return Rules{
-		ChainID:          new(big.Int).Set(chainID),
-		IsHomestead:      c.IsHomestead(num),
-		IsEIP150:         c.IsEIP150(num),
-		IsEIP155:         c.IsEIP155(num),
-		IsEIP158:         c.IsEIP158(num),
-		IsByzantium:      c.IsByzantium(num),
-		IsConstantinople: c.IsConstantinople(num),
+		ChainID:               new(big.Int).Set(chainID),
+		IsHomestead:           c.IsHomestead(num),
+		IsEIP150:              c.IsEIP150(num),
+		IsEIP155:              c.IsEIP155(num),
+		IsEIP158:              c.IsEIP158(num),
+		IsByzantium:           c.IsByzantium(num),
+		IsConstantinople:      c.IsConstantinople(num),
+		IsStorageContract:     c.IsStorageContract(num),
+		IsStorageRevert:       c.IsStorageRevert(num),
+		IsStorageStrictDecode: c.IsStorageStrictDecode(num),
+		IsEscrowFund:          c.IsEscrowFund(num),
+		IsHostRevoke:          c.IsHostRevoke(num),
+		IsBatchProof:          c.IsBatchProof(num),
+		IsStorageAtomic:       c.IsStorageAtomic(num),
+		IsContractRenew:       c.IsContractRenew(num),
+		IsStorageLogs:         c.IsStorageLogs(num),
+		IsMultiSig:            c.IsMultiSig(num),
 	}
 }
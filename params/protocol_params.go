@@ -87,9 +87,11 @@ const (
 	Bn256PairingPerPointGas uint64 = 80000  // Per-point price for an elliptic curve pairing check
 
 	// storage contract gas
-	CheckFileGas            uint64 = 10000 // the gas for checking storage contract content
-	CheckMultiSignaturesGas uint64 = 3000  // the gas for verifying multi-signature
-	DecodeGas               uint64 = 1000  // the gas for rlp decoding
+	CheckFileGas             uint64 = 10000 // the gas for checking storage contract content
+	CheckMultiSignaturesGas  uint64 = 3000  // the gas for verifying multi-signature
+	DecodeGas                uint64 = 1000  // the gas for rlp decoding
+	StorageContractStatusGas uint64 = 1600  // the gas for querying the storage contract status from the smart contracts
+	StorageContractIDGas     uint64 = 1000  // the base gas for deriving the storage contract id from the smart contracts
 )
 
 var (
@@ -0,0 +1,225 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The access control protects the namespaces exposed over HTTP, such as the storage client
+// and host namespaces. A request to a protected namespace must carry a bearer token in the
+// Authorization header. A token of the read only tier can only call the methods listed as
+// read only, which query the status without changing the node state, while a token of the
+// full tier can call all methods. Each method is also rate limited for each token.
+
+// AccessTier is the permission tier of the access token
+type AccessTier int
+
+const (
+	// AccessReadOnly permits the read only methods only
+	AccessReadOnly AccessTier = iota
+
+	// AccessFull permits all methods
+	AccessFull
+)
+
+// authorizationKey is the context key of the bearer token of the request
+const authorizationKey = "authorization"
+
+// AccessConfig is the configuration of the access control
+type AccessConfig struct {
+	// Namespaces are the protected namespaces, the other namespaces are not affected
+	Namespaces []string
+
+	// Tokens is the mapping from the access token to its tier
+	Tokens map[string]AccessTier
+
+	// ReadOnlyMethods are the methods permitted for the read only tier, in the format of
+	// namespace_method. The other methods of the protected namespaces are denied for the
+	// read only tier
+	ReadOnlyMethods []string
+
+	// RateLimit is the number of calls allowed per second for each method and token, and
+	// RateBurst is the number of calls allowed at once. Zero RateLimit disables the limit
+	RateLimit float64
+	RateBurst int
+
+	// MethodRateLimits overrides the rate limit of the methods, in the format of
+	// namespace_method
+	MethodRateLimits map[string]float64
+}
+
+// ParseAccessTokens parses the access tokens in the format of token:tier, where the tier is
+// either read or full. The token without the tier is of the full tier
+func ParseAccessTokens(specs []string) (map[string]AccessTier, error) {
+	tokens := make(map[string]AccessTier)
+	for _, spec := range specs {
+		token, tier := spec, "full"
+		if i := strings.LastIndex(spec, ":"); i >= 0 {
+			token, tier = spec[:i], spec[i+1:]
+		}
+		if token == "" {
+			return nil, fmt.Errorf("empty access token in %q", spec)
+		}
+		switch strings.ToLower(tier) {
+		case "full":
+			tokens[token] = AccessFull
+		case "read", "readonly":
+			tokens[token] = AccessReadOnly
+		default:
+			return nil, fmt.Errorf("unknown access tier %q, expect read or full", tier)
+		}
+	}
+	return tokens, nil
+}
+
+// accessControl authorizes and rate limits the calls to the protected namespaces
+type accessControl struct {
+	config          AccessConfig
+	namespaces      map[string]struct{}
+	readOnlyMethods map[string]struct{}
+
+	limiters map[string]*rateLimiter
+	lock     sync.Mutex
+}
+
+// newAccessControl creates the access control with the config
+func newAccessControl(config AccessConfig) *accessControl {
+	ac := &accessControl{
+		config:          config,
+		namespaces:      make(map[string]struct{}),
+		readOnlyMethods: make(map[string]struct{}),
+		limiters:        make(map[string]*rateLimiter),
+	}
+	for _, namespace := range config.Namespaces {
+		ac.namespaces[namespace] = struct{}{}
+	}
+	for _, method := range config.ReadOnlyMethods {
+		ac.readOnlyMethods[method] = struct{}{}
+	}
+	return ac
+}
+
+// SetAccessControl protects the namespaces of the server with the access tokens and rate
+// limits in the config
+func (s *Server) SetAccessControl(config AccessConfig) {
+	s.access = newAccessControl(config)
+}
+
+// authorize checks the permission and the rate limit of the requests. The requests denied
+// are marked with the error
+func (s *Server) authorize(ctx context.Context, reqs []*serverRequest) {
+	if s.access == nil {
+		return
+	}
+	token, _ := ctx.Value(authorizationKey).(string)
+	for _, req := range reqs {
+		if req.err != nil || req.callb == nil {
+			continue
+		}
+		if err := s.access.check(token, req.svcname, req.method); err != nil {
+			req.err = err
+		}
+	}
+}
+
+// check checks whether the token is allowed to call the method of the namespace
+func (ac *accessControl) check(token, namespace, method string) Error {
+	if _, protected := ac.namespaces[namespace]; !protected {
+		return nil
+	}
+	tier, exists := ac.lookupToken(token)
+	if !exists {
+		return &accessDeniedError{"missing or invalid access token"}
+	}
+	name := namespace + serviceMethodSeparator + method
+	if _, readOnly := ac.readOnlyMethods[name]; tier == AccessReadOnly && !readOnly {
+		return &accessDeniedError{fmt.Sprintf("the method %s is not permitted for the read only token", name)}
+	}
+
+	limit := ac.config.RateLimit
+	if l, exists := ac.config.MethodRateLimits[name]; exists {
+		limit = l
+	}
+	if limit <= 0 {
+		return nil
+	}
+	ac.lock.Lock()
+	key := token + "/" + name
+	limiter, exists := ac.limiters[key]
+	if !exists {
+		limiter = newRateLimiter(limit, ac.config.RateBurst)
+		ac.limiters[key] = limiter
+	}
+	allowed := limiter.allow(time.Now())
+	ac.lock.Unlock()
+	if !allowed {
+		return &rateLimitedError{name}
+	}
+	return nil
+}
+
+// lookupToken returns the tier of the token, comparing the tokens in constant time
+func (ac *accessControl) lookupToken(token string) (AccessTier, bool) {
+	if token == "" {
+		return 0, false
+	}
+	for t, tier := range ac.config.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return tier, true
+		}
+	}
+	return 0, false
+}
+
+// bearerToken extracts the token from the Authorization header value
+func bearerToken(header string) string {
+	const prefix = "bearer "
+	if len(header) > len(prefix) && strings.ToLower(header[:len(prefix)]) == prefix {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	return ""
+}
+
+// rateLimiter is the token bucket refilled at the rate per second, up to the burst
+type rateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a full token bucket. The burst is at least 1
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// allow takes a token from the bucket if there is any
+func (rl *rateLimiter) allow(now time.Time) bool {
+	if !rl.last.IsZero() {
+		rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+		if rl.tokens > rl.burst {
+			rl.tokens = rl.burst
+		}
+	}
+	rl.last = now
+	if rl.tokens < 1 {
+		return false
+	}
+	rl.tokens--
+	return true
+}
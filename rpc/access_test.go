@@ -0,0 +1,103 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessControl_HTTP(t *testing.T) {
+	server := NewServer()
+	if err := server.RegisterName("calc", new(CalculatorService)); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("open", new(CalculatorService)); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := ParseAccessTokens([]string{"admin", "operator", "dashboard:read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.SetAccessControl(AccessConfig{
+		Namespaces:       []string{"calc"},
+		Tokens:           tokens,
+		ReadOnlyMethods:  []string{"calc_div"},
+		MethodRateLimits: map[string]float64{"calc_mult": 0.001},
+	})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	call := func(token, method string) *jsonError {
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":[2,3]}`
+		req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var msg jsonErrResponse
+		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Error.Code == 0 {
+			return nil
+		}
+		return &msg.Error
+	}
+
+	tests := []struct {
+		token  string
+		method string
+		code   int
+	}{
+		{"", "open_div", 0},
+		{"", "calc_div", -32001},
+		{"wrong", "calc_div", -32001},
+		{"dashboard", "calc_div", 0},
+		{"admin", "calc_div", 0},
+		// the first call is within the burst, and the second exceeds the rate limit
+		{"admin", "calc_mult", 0},
+		{"admin", "calc_mult", -32005},
+		// the rate limit is applied for each token
+		{"operator", "calc_mult", 0},
+		// the methods not listed as read only are denied for the read only token
+		{"dashboard", "calc_mult", -32001},
+	}
+	for _, test := range tests {
+		var code int
+		if err := call(test.token, test.method); err != nil {
+			code = err.Code
+		}
+		if code != test.code {
+			t.Errorf("token %q method %v: error code %v, expected %v", test.token, test.method, code, test.code)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	rl := newRateLimiter(2, 2)
+	now := time.Now()
+	for i, expect := range []bool{true, true, false} {
+		if allowed := rl.allow(now); allowed != expect {
+			t.Fatalf("call %v: allowed %v, expected %v", i, allowed, expect)
+		}
+	}
+	// refilled by one token after half a second
+	if !rl.allow(now.Add(500 * time.Millisecond)) {
+		t.Fatal("expect the call allowed after the refill")
+	}
+	if rl.allow(now.Add(500 * time.Millisecond)) {
+		t.Fatal("expect the call denied before the refill")
+	}
+}
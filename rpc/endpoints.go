@@ -23,8 +23,9 @@ import (
 )
 
 // StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-// Register allowed API Services
-func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts) (net.Listener, *Server, error) {
+// Register allowed API Services. If access is not nil, the namespaces in it are protected
+// by the access tokens and rate limits
+func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts, access *AccessConfig) (net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	// modules contained a list of API.NameSpace
 	whitelist := make(map[string]bool)
@@ -44,6 +45,9 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 			log.Debug("HTTP registered", "namespace", api.Namespace)
 		}
 	}
+	if access != nil {
+		handler.SetAccessControl(*access)
+	}
 
 	// All APIs registered, start the HTTP listener
 	var (
@@ -320,6 +320,9 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if origin := r.Header.Get("Origin"); origin != "" {
 		ctx = context.WithValue(ctx, "Origin", origin)
 	}
+	if token := bearerToken(r.Header.Get("Authorization")); token != "" {
+		ctx = context.WithValue(ctx, authorizationKey, token)
+	}
 
 	// set up reading limit be 512 bytes because the max request content length
 	// allowed to be passed into a single request is 512 bytes
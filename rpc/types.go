@@ -59,6 +59,7 @@ type service struct {
 type serverRequest struct {
 	id            interface{}
 	svcname       string
+	method        string // method name requested
 	callb         *callback
 	args          []reflect.Value // passed in arguments with its' originally type
 	isUnsubscribe bool            // if the request is used for unsubscription
@@ -78,6 +79,8 @@ type Server struct {
 	run      int32 // if 1, indicates the server is running
 	codecsMu sync.Mutex
 	codecs   mapset.Set // unordered and unique
+
+	access *accessControl // authorizes the calls to the protected namespaces, nil if disabled
 }
 
 // rpcRequest represents a raw incoming RPC request
@@ -96,6 +99,13 @@ type Error interface {
 	ErrorCode() int // returns the code
 }
 
+// DataError contains extra data to explain the error, which will be returned in the
+// data field of the RPC error
+type DataError interface {
+	Error() string          // returns the message
+	ErrorData() interface{} // returns the error data
+}
+
 // ServerCodec implements reading, parsing and writing RPC messages for the server side of
 // a RPC session. Implementations must be go-routine safe since the codec can be called in
 // multiple go-routines concurrently.
@@ -0,0 +1,225 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+)
+
+// The accounting export converts the spending of the storage client and the revenue of the
+// storage host into CSV or OFX, which can be imported by the bookkeeping software. The entries
+// can be grouped into periods of blocks, so that a single entry is written for each category
+// within each period.
+
+// Accounting export formats
+const (
+	AccountingFormatCSV = "csv"
+	AccountingFormatOFX = "ofx"
+)
+
+// accountingPeriods is the mapping from the period name to the number of blocks in the period
+var accountingPeriods = map[string]uint64{
+	"":      0,
+	"none":  0,
+	"day":   BlocksPerDay,
+	"week":  BlocksPerWeek,
+	"month": BlocksPerMonth,
+	"year":  BlocksPerYear,
+}
+
+// dxUnit is the number of camel in one DX
+var dxUnit = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// AccountingEntry is a single income or expense record. Amount is in camel, positive for the
+// income and negative for the expense
+type AccountingEntry struct {
+	BlockHeight  uint64        `json:"blockHeight"`
+	Time         time.Time     `json:"time"`
+	ContractID   common.Hash   `json:"contractID"`
+	Counterparty string        `json:"counterparty"`
+	Category     string        `json:"category"`
+	Amount       common.BigInt `json:"amount"`
+}
+
+// ParseAccountingPeriod parses the period name to the number of blocks in the period. The
+// empty name or none means the entries are not grouped
+func ParseAccountingPeriod(period string) (uint64, error) {
+	blocks, exists := accountingPeriods[strings.ToLower(strings.TrimSpace(period))]
+	if !exists {
+		return 0, fmt.Errorf("unknown accounting period %v, expect one of none, day, week, month, year", period)
+	}
+	return blocks, nil
+}
+
+// GroupAccountingEntries sums up the entries of the same category within each period of
+// blocks. The grouped entry takes the block height and time of the earliest entry in the
+// group. The entries are returned in the order of block height, and ungrouped if period is 0
+func GroupAccountingEntries(entries []AccountingEntry, period uint64) []AccountingEntry {
+	sorted := make([]AccountingEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].BlockHeight < sorted[j].BlockHeight
+	})
+	if period == 0 {
+		return sorted
+	}
+
+	type groupKey struct {
+		period   uint64
+		category string
+	}
+	var grouped []AccountingEntry
+	index := make(map[groupKey]int)
+	for _, entry := range sorted {
+		key := groupKey{entry.BlockHeight / period, entry.Category}
+		i, exists := index[key]
+		if !exists {
+			index[key] = len(grouped)
+			grouped = append(grouped, AccountingEntry{
+				BlockHeight: entry.BlockHeight,
+				Time:        entry.Time,
+				Category:    entry.Category,
+				Amount:      entry.Amount,
+			})
+			continue
+		}
+		grouped[i].Amount = grouped[i].Amount.Add(entry.Amount)
+	}
+	return grouped
+}
+
+// ExportAccounting writes the entries grouped by the period to the file path in the format
+// provided. The account is the local account the entries belong to, used by OFX
+func ExportAccounting(path, format, account string, entries []AccountingEntry, period uint64) error {
+	var write func(io.Writer, string, []AccountingEntry) error
+	switch strings.ToLower(format) {
+	case AccountingFormatCSV:
+		write = WriteAccountingCSV
+	case AccountingFormatOFX:
+		write = WriteAccountingOFX
+	default:
+		return fmt.Errorf("unknown accounting format %v, expect %v or %v", format, AccountingFormatCSV, AccountingFormatOFX)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err = write(f, account, GroupAccountingEntries(entries, period)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteAccountingCSV writes the entries as CSV, with the amount in both camel and DX
+func WriteAccountingCSV(w io.Writer, account string, entries []AccountingEntry) error {
+	cw := csv.NewWriter(w)
+	header := []string{"date", "block", "account", "category", "contract", "counterparty", "amount_camel", "amount_dx"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		var contractID string
+		if entry.ContractID != (common.Hash{}) {
+			contractID = entry.ContractID.String()
+		}
+		record := []string{
+			entry.Time.UTC().Format(time.RFC3339),
+			strconv.FormatUint(entry.BlockHeight, 10),
+			account,
+			entry.Category,
+			contractID,
+			entry.Counterparty,
+			entry.Amount.String(),
+			formatDX(entry.Amount),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteAccountingOFX writes the entries as an OFX 2 bank statement of the account, with the
+// amount in DX
+func WriteAccountingOFX(w io.Writer, account string, entries []AccountingEntry) error {
+	const ofxTime = "20060102150405"
+
+	var start, end time.Time
+	for _, entry := range entries {
+		if start.IsZero() || entry.Time.Before(start) {
+			start = entry.Time
+		}
+		if entry.Time.After(end) {
+			end = entry.Time
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"no\"?>\n")
+	b.WriteString("<?OFX OFXHEADER=\"200\" VERSION=\"211\" SECURITY=\"NONE\" OLDFILEUID=\"NONE\" NEWFILEUID=\"NONE\"?>\n")
+	b.WriteString("<OFX>\n<BANKMSGSRSV1>\n<STMTTRNRS>\n<TRNUID>0</TRNUID>\n")
+	b.WriteString("<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	b.WriteString("<STMTRS>\n<CURDEF>DX</CURDEF>\n")
+	fmt.Fprintf(&b, "<BANKACCTFROM><BANKID>DX</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>\n", ofxEscape(account))
+	fmt.Fprintf(&b, "<BANKTRANLIST>\n<DTSTART>%s</DTSTART>\n<DTEND>%s</DTEND>\n", start.UTC().Format(ofxTime), end.UTC().Format(ofxTime))
+	for i, entry := range entries {
+		trnType := "CREDIT"
+		if entry.Amount.Sign() < 0 {
+			trnType = "DEBIT"
+		}
+		memo := entry.Category
+		if entry.ContractID != (common.Hash{}) {
+			memo = fmt.Sprintf("%s %s", entry.Category, entry.ContractID.String())
+		}
+		b.WriteString("<STMTTRN>\n")
+		fmt.Fprintf(&b, "<TRNTYPE>%s</TRNTYPE>\n", trnType)
+		fmt.Fprintf(&b, "<DTPOSTED>%s</DTPOSTED>\n", entry.Time.UTC().Format(ofxTime))
+		fmt.Fprintf(&b, "<TRNAMT>%s</TRNAMT>\n", formatDX(entry.Amount))
+		fmt.Fprintf(&b, "<FITID>%d-%d</FITID>\n", entry.BlockHeight, i)
+		if entry.Counterparty != "" {
+			fmt.Fprintf(&b, "<NAME>%s</NAME>\n", ofxEscape(entry.Counterparty))
+		}
+		fmt.Fprintf(&b, "<MEMO>%s</MEMO>\n", ofxEscape(memo))
+		b.WriteString("</STMTTRN>\n")
+	}
+	b.WriteString("</BANKTRANLIST>\n</STMTRS>\n</STMTTRNRS>\n</BANKMSGSRSV1>\n</OFX>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatDX formats the amount in camel as the exact decimal amount in DX
+func formatDX(amount common.BigInt) string {
+	abs := new(big.Int).Abs(amount.BigIntPtr())
+	quo, rem := new(big.Int).QuoRem(abs, dxUnit, new(big.Int))
+
+	formatted := quo.String()
+	if rem.Sign() != 0 {
+		frac := fmt.Sprintf("%018s", rem.String())
+		formatted += "." + strings.TrimRight(frac, "0")
+	}
+	if amount.Sign() < 0 {
+		formatted = "-" + formatted
+	}
+	return formatted
+}
+
+// ofxEscape escapes the special characters of the OFX text
+func ofxEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+)
+
+func TestGroupAccountingEntries(t *testing.T) {
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	entry := func(height uint64, category string, amount int64) AccountingEntry {
+		return AccountingEntry{
+			BlockHeight: height,
+			Time:        start.Add(time.Duration(height) * 15 * time.Second),
+			ContractID:  common.Hash{byte(height)},
+			Category:    category,
+			Amount:      common.NewBigInt(amount),
+		}
+	}
+	entries := []AccountingEntry{
+		entry(BlocksPerDay+1, "storage", -5),
+		entry(10, "storage", -1),
+		entry(20, "gas", -2),
+		entry(30, "storage", -3),
+	}
+
+	if ungrouped := GroupAccountingEntries(entries, 0); len(ungrouped) != 4 || ungrouped[0].BlockHeight != 10 {
+		t.Fatalf("unexpected ungrouped entries %+v", ungrouped)
+	}
+
+	period, err := ParseAccountingPeriod("Day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grouped := GroupAccountingEntries(entries, period)
+	expected := []struct {
+		height   uint64
+		category string
+		amount   int64
+	}{
+		{10, "storage", -4},
+		{20, "gas", -2},
+		{BlocksPerDay + 1, "storage", -5},
+	}
+	if len(grouped) != len(expected) {
+		t.Fatalf("expected %v grouped entries, got %+v", len(expected), grouped)
+	}
+	for i, e := range expected {
+		g := grouped[i]
+		if g.BlockHeight != e.height || g.Category != e.category || !g.Amount.IsEqual(common.NewBigInt(e.amount)) {
+			t.Errorf("entry %v: expected %+v, got %+v", i, e, g)
+		}
+		if g.ContractID != (common.Hash{}) {
+			t.Errorf("entry %v: the grouped entry shall not refer to a contract", i)
+		}
+	}
+
+	if _, err := ParseAccountingPeriod("fortnight"); err == nil {
+		t.Error("expect error for the unknown period")
+	}
+}
+
+func TestWriteAccounting(t *testing.T) {
+	amount := common.NewBigIntUint64(1e18).MultInt(3).Add(common.NewBigIntUint64(5e17))
+	entries := []AccountingEntry{
+		{
+			BlockHeight:  100,
+			Time:         time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC),
+			ContractID:   common.Hash{1},
+			Counterparty: "host<1>",
+			Category:     "storage",
+			Amount:       common.BigInt0.Sub(amount),
+		},
+		{
+			BlockHeight: 200,
+			Time:        time.Date(2019, 6, 2, 12, 0, 0, 0, time.UTC),
+			Category:    "refund",
+			Amount:      common.NewBigInt(1),
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAccountingCSV(&buf, "0xabc", entries); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 csv records, got %v", len(records))
+	}
+	if got := records[1]; got[0] != "2019-06-01T12:00:00Z" || got[6] != "-3500000000000000000" || got[7] != "-3.5" {
+		t.Errorf("unexpected csv record %v", got)
+	}
+	if got := records[2]; got[4] != "" || got[7] != "0.000000000000000001" {
+		t.Errorf("unexpected csv record %v", got)
+	}
+
+	buf.Reset()
+	if err := WriteAccountingOFX(&buf, "0xabc", entries); err != nil {
+		t.Fatal(err)
+	}
+	ofx := buf.String()
+	for _, expect := range []string{
+		"<ACCTID>0xabc</ACCTID>",
+		"<DTSTART>20190601120000</DTSTART>",
+		"<DTEND>20190602120000</DTEND>",
+		"<TRNTYPE>DEBIT</TRNTYPE>\n<DTPOSTED>20190601120000</DTPOSTED>\n<TRNAMT>-3.5</TRNAMT>",
+		"<TRNTYPE>CREDIT</TRNTYPE>",
+		"<NAME>host&lt;1&gt;</NAME>",
+	} {
+		if !strings.Contains(ofx, expect) {
+			t.Errorf("ofx does not contain %q:\n%s", expect, ofx)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package chrono is the shared clock and height source of the storage modules. The wall-clock
+// time is read from the Clock, so that the timers can be faked in tests, and the block heights
+// are converted to durations using the same expected block interval everywhere.
+package chrono
+
+import (
+	"time"
+)
+
+// Clock is the source of the current time used by the storage modules
+type Clock interface {
+	// Now returns the current time, which carries the monotonic clock reading
+	Now() time.Time
+
+	// Since returns the time elapsed since t
+	Since(t time.Time) time.Duration
+
+	// After waits for the duration to elapse and then sends the current time on the channel
+	After(d time.Duration) <-chan time.Time
+}
+
+// System is the Clock backed by the system time
+var System Clock = systemClock{}
+
+// systemClock implements Clock using the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Deadline is a point of time after which an action is regarded as timed out. The deadline is
+// compared using the monotonic clock reading, thus not affected by the wall clock changes
+type Deadline struct {
+	clock Clock
+	at    time.Time
+}
+
+// NewDeadline creates the deadline the duration after the current time of the clock
+func NewDeadline(clock Clock, d time.Duration) Deadline {
+	return Deadline{
+		clock: clock,
+		at:    clock.Now().Add(d),
+	}
+}
+
+// At returns the time of the deadline
+func (d Deadline) At() time.Time {
+	return d.at
+}
+
+// Expired checks if the deadline has passed
+func (d Deadline) Expired() bool {
+	return !d.clock.Now().Before(d.at)
+}
+
+// Remaining returns the duration left before the deadline, zero if expired
+func (d Deadline) Remaining() time.Duration {
+	if remaining := d.at.Sub(d.clock.Now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package chrono
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeadline(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	d := NewDeadline(clock, time.Minute)
+	if d.Expired() || d.Remaining() != time.Minute {
+		t.Fatalf("the deadline shall not expire before the clock advances")
+	}
+	clock.Advance(59 * time.Second)
+	if d.Expired() || d.Remaining() != time.Second {
+		t.Fatalf("remaining time not expected. Got %v", d.Remaining())
+	}
+	clock.Advance(time.Second)
+	if !d.Expired() || d.Remaining() != 0 {
+		t.Fatalf("the deadline shall expire once reached")
+	}
+}
+
+func TestFakeClock_After(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	short, long := clock.After(time.Second), clock.After(time.Hour)
+	clock.Advance(time.Second)
+	select {
+	case <-short:
+	default:
+		t.Fatalf("the timer shall fire after the duration elapsed")
+	}
+	select {
+	case <-long:
+		t.Fatalf("the timer shall not fire before the duration elapsed")
+	default:
+	}
+	if elapsed := clock.Since(time.Unix(1000, 0)); elapsed != time.Second {
+		t.Errorf("elapsed time not expected. Got %v", elapsed)
+	}
+}
+
+func TestHeightConversion(t *testing.T) {
+	tests := []struct {
+		blocks   uint64
+		duration time.Duration
+	}{
+		{0, 0},
+		{1, BlockInterval},
+		{240, time.Hour},
+	}
+	for _, test := range tests {
+		if d := HeightToDuration(test.blocks); d != test.duration {
+			t.Errorf("duration of %v blocks not expected. Expect %v, Got %v", test.blocks, test.duration, d)
+		}
+		if blocks := DurationToHeight(test.duration); blocks != test.blocks {
+			t.Errorf("blocks of %v not expected. Expect %v, Got %v", test.duration, test.blocks, blocks)
+		}
+	}
+	if blocks := DurationToHeight(BlockInterval + time.Second); blocks != 2 {
+		t.Errorf("the partial block shall be rounded up. Got %v", blocks)
+	}
+
+	now := time.Unix(10000, 0)
+	if at := HeightTime(now, 100, 104); !at.Equal(now.Add(time.Minute)) {
+		t.Errorf("estimated time of the future block not expected. Got %v", at)
+	}
+	if at := HeightTime(now, 104, 100); !at.Equal(now.Add(-time.Minute)) {
+		t.Errorf("estimated time of the past block not expected. Got %v", at)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package chrono
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is the Clock whose time only moves when advanced, used in tests
+type FakeClock struct {
+	now     time.Time
+	waiters []fakeWaiter
+	lock    sync.Mutex
+}
+
+// fakeWaiter is the channel returned by After waiting for the fake time to reach the deadline
+type fakeWaiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// NewFakeClock creates the FakeClock starting at the time provided
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the current fake time
+func (fc *FakeClock) Now() time.Time {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+	return fc.now
+}
+
+// Since returns the fake time elapsed since t
+func (fc *FakeClock) Since(t time.Time) time.Duration {
+	return fc.Now().Sub(t)
+}
+
+// After returns the channel which receives the fake time once the clock is advanced by the
+// duration
+func (fc *FakeClock) After(d time.Duration) <-chan time.Time {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+	c := make(chan time.Time, 1)
+	if d <= 0 {
+		c <- fc.now
+		return c
+	}
+	fc.waiters = append(fc.waiters, fakeWaiter{at: fc.now.Add(d), c: c})
+	return c
+}
+
+// Advance moves the fake time forward, and fires the After channels whose duration elapsed
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+	fc.now = fc.now.Add(d)
+	var waiters []fakeWaiter
+	for _, w := range fc.waiters {
+		if fc.now.Before(w.at) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.c <- fc.now
+	}
+	fc.waiters = waiters
+}
@@ -0,0 +1,36 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package chrono
+
+import (
+	"time"
+)
+
+// BlockInterval is the expected time between two blocks, in accordance with
+// storage.BlockPerMin
+const BlockInterval = 15 * time.Second
+
+// HeightToDuration returns the expected time for the number of blocks to be mined
+func HeightToDuration(blocks uint64) time.Duration {
+	return time.Duration(blocks) * BlockInterval
+}
+
+// DurationToHeight returns the number of blocks expected to be mined within the duration,
+// rounded up
+func DurationToHeight(d time.Duration) uint64 {
+	if d <= 0 {
+		return 0
+	}
+	return uint64((d + BlockInterval - 1) / BlockInterval)
+}
+
+// HeightTime estimates the time the block at the target height is mined, given the current
+// block height and time
+func HeightTime(now time.Time, current, target uint64) time.Time {
+	if target >= current {
+		return now.Add(HeightToDuration(target - current))
+	}
+	return now.Add(-HeightToDuration(current - target))
+}
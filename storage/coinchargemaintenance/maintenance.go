@@ -65,6 +65,18 @@ var (
 
 	// KeyHostMissedProofOutput is the key to store host missed proof output into trie
 	KeyHostMissedProofOutput = common.BytesToHash([]byte("HostMissedProofOutput"))
+
+	// KeyEscrowTreasury is the key to store the treasury address of the escrow account into trie
+	KeyEscrowTreasury = common.BytesToHash([]byte("EscrowTreasury"))
+
+	// KeyEscrowRenter is the key to store the renter address of the escrow account into trie
+	KeyEscrowRenter = common.BytesToHash([]byte("EscrowRenter"))
+
+	// KeyEscrowSpendingCap is the key to store the spending cap of the escrow account into trie
+	KeyEscrowSpendingCap = common.BytesToHash([]byte("EscrowSpendingCap"))
+
+	// KeyEscrowSpent is the key to store the collateral spent from the escrow account into trie
+	KeyEscrowSpent = common.BytesToHash([]byte("EscrowSpent"))
 )
 
 // MaintenanceMissedProof maintains missed storage proof
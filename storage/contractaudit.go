@@ -0,0 +1,179 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/rlp"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	// ErrAuditRequestNotSigned is the error returned when the contract audit request carries
+	// no signature
+	ErrAuditRequestNotSigned = errors.New("contract audit request is not signed")
+
+	// ErrInvalidAuditSignature is the error returned when the contract audit request is not
+	// signed by the storage client of the contract
+	ErrInvalidAuditSignature = errors.New("contract audit request is not signed by the storage client")
+)
+
+type (
+	// ContractAuditState is the view of the contract from one side of the contract, including
+	// the latest revision, the remaining funds and the merkle roots of the stored sectors
+	ContractAuditState struct {
+		ContractID           common.Hash   `json:"contractID"`
+		RevisionNumber       uint64        `json:"revisionNumber"`
+		FileSize             uint64        `json:"fileSize"`
+		FileMerkleRoot       common.Hash   `json:"fileMerkleRoot"`
+		NewValidProofValues  []*big.Int    `json:"newValidProofValues"`
+		NewMissedProofValues []*big.Int    `json:"newMissedProofValues"`
+		MerkleRoots          []common.Hash `json:"merkleRoots"`
+	}
+
+	// ContractAuditMismatch is a single field that differs between the local and remote view
+	ContractAuditMismatch struct {
+		Field  string `json:"field"`
+		Local  string `json:"local"`
+		Remote string `json:"remote"`
+	}
+
+	// ContractAuditDiff is the structured difference between the local and remote view of
+	// the contract. FirstRootMismatch is the index of the first different merkle root, -1 if
+	// the root lists are identical
+	ContractAuditDiff struct {
+		ContractID        common.Hash             `json:"contractID"`
+		Consistent        bool                    `json:"consistent"`
+		Mismatches        []ContractAuditMismatch `json:"mismatches"`
+		LocalRootCount    int                     `json:"localRootCount"`
+		RemoteRootCount   int                     `json:"remoteRootCount"`
+		FirstRootMismatch int                     `json:"firstRootMismatch"`
+	}
+)
+
+// NewContractAuditState creates the audit state based on the latest contract revision
+// and the merkle roots of the contract
+func NewContractAuditState(id common.Hash, rev types.StorageContractRevision, roots []common.Hash) ContractAuditState {
+	state := ContractAuditState{
+		ContractID:     id,
+		RevisionNumber: rev.NewRevisionNumber,
+		FileSize:       rev.NewFileSize,
+		FileMerkleRoot: rev.NewFileMerkleRoot,
+		MerkleRoots:    roots,
+	}
+	for _, output := range rev.NewValidProofOutputs {
+		state.NewValidProofValues = append(state.NewValidProofValues, output.Value)
+	}
+	for _, output := range rev.NewMissedProofOutputs {
+		state.NewMissedProofValues = append(state.NewMissedProofValues, output.Value)
+	}
+	return state
+}
+
+// DiffContractAuditStates compares the local and remote view of the contract field by field
+func DiffContractAuditStates(local, remote ContractAuditState) ContractAuditDiff {
+	diff := ContractAuditDiff{
+		ContractID:        local.ContractID,
+		LocalRootCount:    len(local.MerkleRoots),
+		RemoteRootCount:   len(remote.MerkleRoots),
+		FirstRootMismatch: -1,
+	}
+	addMismatch := func(field string, l, r interface{}) {
+		diff.Mismatches = append(diff.Mismatches, ContractAuditMismatch{
+			Field:  field,
+			Local:  fmt.Sprint(l),
+			Remote: fmt.Sprint(r),
+		})
+	}
+
+	if local.ContractID != remote.ContractID {
+		addMismatch("contractID", local.ContractID.String(), remote.ContractID.String())
+	}
+	if local.RevisionNumber != remote.RevisionNumber {
+		addMismatch("revisionNumber", local.RevisionNumber, remote.RevisionNumber)
+	}
+	if local.FileSize != remote.FileSize {
+		addMismatch("fileSize", local.FileSize, remote.FileSize)
+	}
+	if local.FileMerkleRoot != remote.FileMerkleRoot {
+		addMismatch("fileMerkleRoot", local.FileMerkleRoot.String(), remote.FileMerkleRoot.String())
+	}
+	diffProofValues("newValidProofValues", local.NewValidProofValues, remote.NewValidProofValues, addMismatch)
+	diffProofValues("newMissedProofValues", local.NewMissedProofValues, remote.NewMissedProofValues, addMismatch)
+
+	// find the first merkle root that differs
+	for i := 0; i < len(local.MerkleRoots) || i < len(remote.MerkleRoots); i++ {
+		if i >= len(local.MerkleRoots) || i >= len(remote.MerkleRoots) || local.MerkleRoots[i] != remote.MerkleRoots[i] {
+			diff.FirstRootMismatch = i
+			addMismatch(fmt.Sprintf("merkleRoots[%d]", i), rootAt(local.MerkleRoots, i), rootAt(remote.MerkleRoots, i))
+			break
+		}
+	}
+
+	diff.Consistent = len(diff.Mismatches) == 0
+	return diff
+}
+
+// diffProofValues compares the proof output values one by one
+func diffProofValues(field string, local, remote []*big.Int, addMismatch func(string, interface{}, interface{})) {
+	if len(local) != len(remote) {
+		addMismatch(field+".length", len(local), len(remote))
+		return
+	}
+	for i := range local {
+		if local[i] == nil || remote[i] == nil {
+			if local[i] != remote[i] {
+				addMismatch(fmt.Sprintf("%s[%d]", field, i), local[i], remote[i])
+			}
+			continue
+		}
+		if local[i].Cmp(remote[i]) != 0 {
+			addMismatch(fmt.Sprintf("%s[%d]", field, i), local[i], remote[i])
+		}
+	}
+}
+
+// rootAt returns the string of the merkle root at the index, or "none" if out of range
+func rootAt(roots []common.Hash, index int) string {
+	if index >= len(roots) {
+		return "none"
+	}
+	return roots[index].String()
+}
+
+// SigHash returns the hash of the contract audit request to be signed by the storage client.
+// The hash is prefixed with the type name, so that the signature could not be taken as the
+// signature of other messages
+func (req ContractAuditRequest) SigHash() (h common.Hash) {
+	hw := sha3.NewLegacyKeccak256()
+	rlp.Encode(hw, []interface{}{
+		"ContractAuditRequest",
+		req.StorageContractID,
+	})
+	hw.Sum(h[:0])
+	return h
+}
+
+// VerifyContractAuditRequest checks that the contract audit request is signed by the client
+// address of the storage contract
+func VerifyContractAuditRequest(req ContractAuditRequest, client common.Address) error {
+	if len(req.Signature) == 0 {
+		return ErrAuditRequestNotSigned
+	}
+	pubKey, err := crypto.SigToPub(req.SigHash().Bytes(), req.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to recover the contract audit request signer: %s", err.Error())
+	}
+	if crypto.PubkeyToAddress(*pubKey) != client {
+		return ErrInvalidAuditSignature
+	}
+	return nil
+}
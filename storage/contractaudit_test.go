@@ -0,0 +1,83 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+)
+
+func TestDiffContractAuditStates(t *testing.T) {
+	local := ContractAuditState{
+		ContractID:           common.HexToHash("0x1"),
+		RevisionNumber:       10,
+		FileSize:             SectorSize * 2,
+		FileMerkleRoot:       common.HexToHash("0x2"),
+		NewValidProofValues:  []*big.Int{big.NewInt(100), big.NewInt(200)},
+		NewMissedProofValues: []*big.Int{big.NewInt(100), big.NewInt(200)},
+		MerkleRoots:          []common.Hash{common.HexToHash("0x3"), common.HexToHash("0x4")},
+	}
+	if diff := DiffContractAuditStates(local, local); !diff.Consistent || diff.FirstRootMismatch != -1 {
+		t.Fatalf("identical states should be consistent: %+v", diff)
+	}
+
+	remote := local
+	remote.RevisionNumber = 11
+	remote.NewValidProofValues = []*big.Int{big.NewInt(90), big.NewInt(200)}
+	remote.MerkleRoots = []common.Hash{common.HexToHash("0x3"), common.HexToHash("0x4"), common.HexToHash("0x5")}
+
+	diff := DiffContractAuditStates(local, remote)
+	if diff.Consistent {
+		t.Fatal("different states should not be consistent")
+	}
+	expectFields := []string{"revisionNumber", "newValidProofValues[0]", "merkleRoots[2]"}
+	if len(diff.Mismatches) != len(expectFields) {
+		t.Fatalf("mismatches not expected. Expect %v, Got %+v", expectFields, diff.Mismatches)
+	}
+	for i, field := range expectFields {
+		if diff.Mismatches[i].Field != field {
+			t.Errorf("mismatch %d not expected. Expect %v, Got %v", i, field, diff.Mismatches[i].Field)
+		}
+	}
+	if diff.FirstRootMismatch != 2 || diff.LocalRootCount != 2 || diff.RemoteRootCount != 3 {
+		t.Errorf("root mismatch not expected: %+v", diff)
+	}
+}
+
+func TestVerifyContractAuditRequest(t *testing.T) {
+	sk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := crypto.PubkeyToAddress(sk.PublicKey)
+
+	req := ContractAuditRequest{StorageContractID: common.HexToHash("0x1")}
+	if err := VerifyContractAuditRequest(req, client); err != ErrAuditRequestNotSigned {
+		t.Fatalf("unsigned request: expect error %v, got %v", ErrAuditRequestNotSigned, err)
+	}
+	if req.Signature, err = crypto.Sign(req.SigHash().Bytes(), sk); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyContractAuditRequest(req, client); err != nil {
+		t.Fatalf("signed request: %v", err)
+	}
+	if err := VerifyContractAuditRequest(req, crypto.PubkeyToAddress(otherSk.PublicKey)); err != ErrInvalidAuditSignature {
+		t.Fatalf("wrong client: expect error %v, got %v", ErrInvalidAuditSignature, err)
+	}
+
+	// the signature of the audit request of one contract does not apply to another
+	other := req
+	other.StorageContractID = common.HexToHash("0x2")
+	if err := VerifyContractAuditRequest(other, client); err == nil {
+		t.Fatal("the request of another contract passed the verification")
+	}
+}
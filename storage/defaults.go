@@ -28,6 +28,11 @@ var (
 
 	// ErrHostCommit defines that host occurs error while commit(finalize)
 	ErrHostCommit = errors.New("host commit error")
+
+	// ErrDownloadBudgetExhausted defines that the download budget of the current period is used up,
+	// and the downloads are stopped until the next period or the budget is raised. If this error is
+	// occurred the host's evaluation will not be deducted
+	ErrDownloadBudgetExhausted = errors.New("the download budget of the current period is exhausted")
 )
 
 // Negotiation related messages
@@ -43,6 +48,11 @@ const (
 	HostCommitFailedMsg          = 0x27
 	HostAckMsg                   = 0x28
 	HostNegotiateErrorMsg        = 0x29
+	ContractAuditRespMsg         = 0x2a
+	HostPongMsg                  = 0x2b
+	HostPingMsg                  = 0x2c
+	SpeedTestRespMsg             = 0x2d
+	PublicDownloadRespMsg        = 0x2e
 
 	// Host Handle Message Set
 	HostConfigReqMsg                 = 0x30
@@ -55,8 +65,22 @@ const (
 	ClientCommitFailedMsg            = 0x37
 	ClientAckMsg                     = 0x38
 	ClientNegotiateErrorMsg          = 0x39
+	ContractAuditReqMsg              = 0x3a
+	ClientPingMsg                    = 0x3b
+	ClientPongMsg                    = 0x3c
+	SpeedTestReqMsg                  = 0x3d
+	PublishSectorsReqMsg             = 0x3e
+	PublicDownloadReqMsg             = 0x3f
 )
 
+// MaxSpeedTestSize is the max size of the probe data the storage host accepts in a speed
+// test, which keeps the speed test cheap for the storage host
+const MaxSpeedTestSize = 1 << 20
+
+// MaxPublishSectors is the max number of sectors published to the storage host in a single
+// publish request
+const MaxPublishSectors = 256
+
 // The block generation rate for Ethereum is 15s/block. Therefore, 240 blocks
 // can be generated in an hour
 var (
@@ -70,6 +94,22 @@ var (
 	ResponsibilityLockTimeout = 60 * time.Second
 )
 
+// Host config signature validity, used by the storage client to reject the
+// replayed or cached host config
+var (
+	HostConfigSignatureTimeout = 10 * time.Minute
+	HostConfigClockDrift       = 1 * time.Minute
+)
+
+// Storage contract tx fee bumping. The storage contract tx not mined within the confirm blocks
+// is replaced with the gas price raised by the price bump percentage, which shall be no less than
+// the price bump required by the tx pool, and no more than the max gas price
+var (
+	StorageTxConfirmBlocks = uint64(10)
+	StorageTxPriceBump     = uint64(10)
+	DefaultMaxTxGasPrice   = common.NewBigIntUint64(50e9) // 50 Gcamel
+)
+
 // Default rentPayment values
 var (
 	DefaultRentPayment = RentPayment{
@@ -0,0 +1,168 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DxChainNetwork/godx/metrics"
+)
+
+// The new protocol features of the storage module are rolled out gradually with the feature
+// flags. Each node enables a feature from an activation block height of its own, so that the
+// operators could stage the rollout across the network. The storage host advertises the
+// features active in the host config, and the storage client only uses a feature in the
+// session if both sides have it active.
+
+// Feature is the name of a protocol feature gated by the feature flags
+type Feature string
+
+const (
+	// FeatureBatchUpload allows uploading more than one sector in a single upload request
+	FeatureBatchUpload Feature = "batchupload"
+
+	// FeatureBatchProof allows submitting the storage proofs of multiple storage contracts in
+	// a single batch storage proof transaction
+	FeatureBatchProof Feature = "batchproof"
+
+	// FeaturePublicRead allows the storage host to serve the sectors published by the storage
+	// client to anyone, without a storage contract or payment
+	FeaturePublicRead Feature = "publicread"
+)
+
+// knownFeatures is the features understood by this node
+var knownFeatures = map[Feature]struct{}{
+	FeatureBatchUpload: {},
+	FeatureBatchProof:  {},
+	FeaturePublicRead:  {},
+}
+
+// ErrFeatureNotNegotiated is the error that the feature required is not active on both sides
+// of the session
+var ErrFeatureNotNegotiated = errors.New("the feature is not negotiated with the storage host")
+
+// FeatureFlags maps the enabled features to the block height they are active from. The
+// feature not in the flags is disabled
+type FeatureFlags map[Feature]uint64
+
+// DefaultFeatureFlags returns the feature flags used if not configured. Only the features
+// already rolled out to the network are enabled by default
+func DefaultFeatureFlags() FeatureFlags {
+	return FeatureFlags{
+		FeatureBatchProof: 0,
+	}
+}
+
+// Enabled returns whether the feature is active at the block height
+func (flags FeatureFlags) Enabled(feature Feature, height uint64) bool {
+	activation, exists := flags[feature]
+	return exists && height >= activation
+}
+
+// Active returns the features active at the block height, sorted by name
+func (flags FeatureFlags) Active(height uint64) []Feature {
+	var features []Feature
+	for feature := range flags {
+		if flags.Enabled(feature, height) {
+			features = append(features, feature)
+		}
+	}
+	sortFeatures(features)
+	return features
+}
+
+// String returns the feature flags in the format parsed by ParseFeatureFlags
+func (flags FeatureFlags) String() string {
+	features := make([]Feature, 0, len(flags))
+	for feature := range flags {
+		features = append(features, feature)
+	}
+	sortFeatures(features)
+
+	entries := make([]string, 0, len(features))
+	for _, feature := range features {
+		if activation := flags[feature]; activation != 0 {
+			entries = append(entries, fmt.Sprintf("%s@%d", feature, activation))
+		} else {
+			entries = append(entries, string(feature))
+		}
+	}
+	return strings.Join(entries, ",")
+}
+
+// ParseFeatureFlags parses the comma separated features to be enabled. Each feature could be
+// followed by @height, the block height the feature is active from. The feature without
+// height is active immediately. The empty string disables all features
+func ParseFeatureFlags(str string) (FeatureFlags, error) {
+	flags := make(FeatureFlags)
+	for _, entry := range strings.Split(str, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		var activation uint64
+		name := entry
+		if i := strings.Index(entry, "@"); i >= 0 {
+			height, err := strconv.ParseUint(entry[i+1:], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid activation height of %s: %v", entry[:i], err)
+			}
+			name, activation = entry[:i], height
+		}
+		feature := Feature(strings.ToLower(name))
+		if _, known := knownFeatures[feature]; !known {
+			return nil, fmt.Errorf("unknown feature: %s", name)
+		}
+		flags[feature] = activation
+	}
+	return flags, nil
+}
+
+// NegotiateFeatures returns the features active on both sides of the session, and records the
+// adoption of the features offered by the remote node in the metrics
+func NegotiateFeatures(local, remote []Feature) []Feature {
+	localSet := make(map[Feature]struct{}, len(local))
+	for _, feature := range local {
+		localSet[feature] = struct{}{}
+	}
+
+	var negotiated []Feature
+	for _, feature := range remote {
+		if _, known := knownFeatures[feature]; !known {
+			continue
+		}
+		metrics.GetOrRegisterCounter(featureMetricName(feature, "offered"), nil).Inc(1)
+		if _, exists := localSet[feature]; exists {
+			metrics.GetOrRegisterCounter(featureMetricName(feature, "negotiated"), nil).Inc(1)
+			negotiated = append(negotiated, feature)
+		}
+	}
+	sortFeatures(negotiated)
+	return negotiated
+}
+
+// HasFeature returns whether the feature is in the features
+func HasFeature(features []Feature, feature Feature) bool {
+	for _, f := range features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// featureMetricName returns the name of the metric counting the sessions of the feature
+func featureMetricName(feature Feature, kind string) string {
+	return fmt.Sprintf("storage/feature/%s/%s", feature, kind)
+}
+
+// sortFeatures sorts the features by name
+func sortFeatures(features []Feature) {
+	sort.Slice(features, func(i, j int) bool { return features[i] < features[j] })
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/metrics"
+)
+
+func TestParseFeatureFlags(t *testing.T) {
+	tests := []struct {
+		str       string
+		flags     FeatureFlags
+		expectErr bool
+	}{
+		{"", FeatureFlags{}, false},
+		{"batchproof", FeatureFlags{FeatureBatchProof: 0}, false},
+		{" BatchUpload@1000 , batchproof", FeatureFlags{FeatureBatchUpload: 1000, FeatureBatchProof: 0}, false},
+		{"batchupload@", nil, true},
+		{"newfeature", nil, true},
+	}
+	for i, test := range tests {
+		flags, err := ParseFeatureFlags(test.str)
+		if test.expectErr != (err != nil) {
+			t.Errorf("test %d: expect error %v, got %v", i, test.expectErr, err)
+			continue
+		}
+		if !test.expectErr && !reflect.DeepEqual(flags, test.flags) {
+			t.Errorf("test %d: expect %v, got %v", i, test.flags, flags)
+		}
+	}
+
+	// the string of the feature flags is parsed back to the same
+	flags := FeatureFlags{FeatureBatchUpload: 1000, FeatureBatchProof: 0}
+	if str := flags.String(); str != "batchproof,batchupload@1000" {
+		t.Errorf("unexpected feature flags string %v", str)
+	}
+	if parsed, err := ParseFeatureFlags(flags.String()); err != nil || !reflect.DeepEqual(parsed, flags) {
+		t.Errorf("expect %v, got %v, %v", flags, parsed, err)
+	}
+}
+
+func TestFeatureFlagsActive(t *testing.T) {
+	flags := FeatureFlags{FeatureBatchUpload: 1000, FeatureBatchProof: 0}
+	if flags.Enabled(FeatureBatchUpload, 999) || !flags.Enabled(FeatureBatchUpload, 1000) {
+		t.Error("the feature shall be active from the activation height")
+	}
+	if active := flags.Active(999); !reflect.DeepEqual(active, []Feature{FeatureBatchProof}) {
+		t.Errorf("unexpected active features %v", active)
+	}
+	if active := flags.Active(1000); !reflect.DeepEqual(active, []Feature{FeatureBatchProof, FeatureBatchUpload}) {
+		t.Errorf("unexpected active features %v", active)
+	}
+	if (FeatureFlags{}).Enabled(FeatureBatchProof, 1000) {
+		t.Error("the feature not in the flags shall be disabled")
+	}
+}
+
+func TestNegotiateFeatures(t *testing.T) {
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = false }()
+
+	offered := metrics.GetOrRegisterCounter(featureMetricName(FeatureBatchUpload, "offered"), nil)
+	negotiated := metrics.GetOrRegisterCounter(featureMetricName(FeatureBatchUpload, "negotiated"), nil)
+	offeredBefore, negotiatedBefore := offered.Count(), negotiated.Count()
+
+	// the unknown features offered by the remote node are ignored
+	local := []Feature{FeatureBatchProof}
+	remote := []Feature{FeatureBatchUpload, FeatureBatchProof, Feature("newfeature")}
+	if features := NegotiateFeatures(local, remote); !reflect.DeepEqual(features, []Feature{FeatureBatchProof}) {
+		t.Errorf("unexpected negotiated features %v", features)
+	}
+	if offered.Count()-offeredBefore != 1 || negotiated.Count()-negotiatedBefore != 0 {
+		t.Errorf("unexpected adoption metrics, offered %v, negotiated %v", offered.Count(), negotiated.Count())
+	}
+	if !HasFeature(remote, FeatureBatchUpload) || HasFeature(local, FeatureBatchUpload) {
+		t.Error("unexpected feature lookup")
+	}
+}
@@ -5,7 +5,11 @@
 package storage
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -14,6 +18,14 @@ import (
 	"github.com/DxChainNetwork/godx/rlp"
 )
 
+var (
+	// ErrNoChecksum is returned when no checksum is recorded for the file
+	ErrNoChecksum = errors.New("no checksum recorded for the file")
+
+	// ErrChecksumMismatch is returned when the file does not match the checksum recorded
+	ErrChecksumMismatch = errors.New("the file does not match the checksum recorded")
+)
+
 const (
 	// OpInsertFile is the operation name for an InsertUpdate
 	OpInsertFile = "insert_file"
@@ -166,6 +178,139 @@ func ApplyOperations(ops []writeaheadlog.Operation) error {
 	return fullErr
 }
 
+// MirrorPath returns the path of the mirror of the file
+func MirrorPath(fileName string) string {
+	return fileName + MirrorExt
+}
+
+// ChecksumPath returns the path of the file recording the checksum of the file
+func ChecksumPath(fileName string) string {
+	return fileName + ChecksumExt
+}
+
+// Checksum returns the checksum of the file content
+func Checksum(content []byte) []byte {
+	sum := sha256.Sum256(content)
+	return sum[:]
+}
+
+// VerifyChecksum checks the content of the file against the checksum recorded for it.
+// ErrNoChecksum is returned if no checksum is recorded
+func VerifyChecksum(fileName string) error {
+	sum, err := ioutil.ReadFile(ChecksumPath(fileName))
+	if os.IsNotExist(err) {
+		return ErrNoChecksum
+	}
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(Checksum(content), sum) {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// MirrorUpdates returns the updates along with the same updates to the mirror files. Applied in
+// the same transaction, the mirror is always the same as the file, so that the file could be
+// recovered from the mirror once it is corrupted. The checksum of the file content after the
+// updates is recorded for both the file and the mirror in the same transaction, which tells
+// the valid copy once the file and the mirror differ
+func MirrorUpdates(updates []FileUpdate) ([]FileUpdate, error) {
+	type fileState struct {
+		content []byte
+		deleted bool
+	}
+	states := make(map[string]*fileState)
+	var fileNames []string
+
+	mirrored := make([]FileUpdate, 0, 2*len(updates))
+	for _, update := range updates {
+		mirrored = append(mirrored, update)
+		switch up := update.(type) {
+		case *InsertUpdate:
+			mirrored = append(mirrored, &InsertUpdate{
+				FileName: MirrorPath(up.FileName),
+				Offset:   up.Offset,
+				Data:     up.Data,
+			})
+			state, exists := states[up.FileName]
+			if !exists {
+				content, err := currentContent(up.FileName)
+				if err != nil {
+					return nil, err
+				}
+				state = &fileState{content: content}
+				states[up.FileName] = state
+				fileNames = append(fileNames, up.FileName)
+			}
+			state.content = writeAt(state.content, up.Offset, up.Data)
+			state.deleted = false
+		case *DeleteUpdate:
+			mirrored = append(mirrored, &DeleteUpdate{
+				FileName: MirrorPath(up.FileName),
+			})
+			if _, exists := states[up.FileName]; !exists {
+				fileNames = append(fileNames, up.FileName)
+			}
+			states[up.FileName] = &fileState{deleted: true}
+		}
+	}
+
+	// record the checksum of the files updated, for both the file and the mirror
+	for _, fileName := range fileNames {
+		state := states[fileName]
+		for _, path := range []string{fileName, MirrorPath(fileName)} {
+			if state.deleted {
+				mirrored = append(mirrored, &DeleteUpdate{FileName: ChecksumPath(path)})
+			} else {
+				mirrored = append(mirrored, &InsertUpdate{FileName: ChecksumPath(path), Data: Checksum(state.content)})
+			}
+		}
+	}
+	return mirrored, nil
+}
+
+// currentContent returns the content of the file the updates are applied on. The copy matching
+// its checksum, either the file or the mirror, is used, so that the checksum recorded after the
+// updates is not derived from a corrupted copy. The file is used if neither is verified
+func currentContent(fileName string) ([]byte, error) {
+	for _, path := range []string{fileName, MirrorPath(fileName)} {
+		if VerifyChecksum(path) == nil {
+			return ioutil.ReadFile(path)
+		}
+	}
+	content, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return content, err
+}
+
+// writeAt writes the data to the content at the offset, the same as the InsertUpdate writes
+// the data to the file
+func writeAt(content []byte, offset uint64, data []byte) []byte {
+	if end := offset + uint64(len(data)); end > uint64(len(content)) {
+		extended := make([]byte, end)
+		copy(extended, content)
+		content = extended
+	}
+	copy(content[offset:], data)
+	return content
+}
+
+// ApplyMirroredUpdates apply the updates along with the updates to the mirror files on the wal
+func ApplyMirroredUpdates(wal *writeaheadlog.Wal, updates []FileUpdate) error {
+	mirrored, err := MirrorUpdates(updates)
+	if err != nil {
+		return fmt.Errorf("failed to create the mirror updates: %v", err)
+	}
+	return ApplyUpdates(wal, mirrored)
+}
+
 // ApplyUpdates apply the updates on the wal
 func ApplyUpdates(wal *writeaheadlog.Wal, updates []FileUpdate) error {
 	// Decode the updates to Operations
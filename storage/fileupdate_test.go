@@ -7,6 +7,7 @@ package storage
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -129,6 +130,77 @@ func TestDxFileUpdate_Encode_Decode(t *testing.T) {
 	}
 }
 
+// TestMirrorUpdates test MirrorUpdates applying the same updates to the mirror file
+func TestMirrorUpdates(t *testing.T) {
+	filename := filepath.Join(testDir, t.Name())
+	updates, err := MirrorUpdates([]FileUpdate{
+		&InsertUpdate{filename, 0, randomBytes(128)},
+		&InsertUpdate{filename, 64, randomBytes(512)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 6 {
+		t.Fatalf("expect 6 updates, got %d", len(updates))
+	}
+	for _, update := range updates {
+		if err := update.Apply(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mirror, err := ioutil.ReadFile(MirrorPath(filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, mirror) {
+		t.Errorf("mirror not equal: \n\tExpect %x\n\tGot %x", content, mirror)
+	}
+	for _, path := range []string{filename, MirrorPath(filename)} {
+		if err := VerifyChecksum(path); err != nil {
+			t.Errorf("checksum of %v not verified: %v", path, err)
+		}
+	}
+
+	// the checksum after the updates is derived from the copy verified, even if the file
+	// is corrupted before the updates
+	if err = ioutil.WriteFile(filename, make([]byte, len(content)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data := randomBytes(32)
+	if updates, err = MirrorUpdates([]FileUpdate{&InsertUpdate{filename, 0, data}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, update := range updates {
+		if err := update.Apply(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := VerifyChecksum(filename); err != ErrChecksumMismatch {
+		t.Errorf("expect the corrupted file not verified, got %v", err)
+	}
+	if err := VerifyChecksum(MirrorPath(filename)); err != nil {
+		t.Errorf("checksum of the mirror not verified: %v", err)
+	}
+
+	if updates, err = MirrorUpdates([]FileUpdate{&DeleteUpdate{filename}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, update := range updates {
+		if err := update.Apply(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range []string{MirrorPath(filename), ChecksumPath(filename), ChecksumPath(MirrorPath(filename))} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%v not deleted", path)
+		}
+	}
+}
+
 // randomBytes create a random bytes of size input num
 func randomBytes(num int) []byte {
 	b := make([]byte, num)
@@ -0,0 +1,84 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/rlp"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	// ErrHostConfigNotSigned is the error returned when the host config carries no signature
+	ErrHostConfigNotSigned = errors.New("host config is not signed")
+
+	// ErrInvalidHostConfigSignature is the error returned when the host config signature
+	// is not signed by the expected host node key
+	ErrInvalidHostConfigSignature = errors.New("host config signature does not match the host node key")
+
+	// ErrStaleHostConfig is the error returned when the signed host config is too old, or
+	// is signed in the future
+	ErrStaleHostConfig = errors.New("host config signature is stale")
+)
+
+// SigHash returns the hash of the host config to be signed by the storage host.
+// The signature field is excluded from the hash
+func (config HostExtConfig) SigHash() (h common.Hash) {
+	config.Signature = nil
+	hw := sha3.NewLegacyKeccak256()
+	rlp.Encode(hw, config)
+	hw.Sum(h[:0])
+	return h
+}
+
+// SignHostExtConfig stamps the host config with the current time and signs it with the
+// sign function provided, which is expected to sign with the host's node private key
+func SignHostExtConfig(config *HostExtConfig, sign func(hash []byte) ([]byte, error)) error {
+	config.Timestamp = uint64(time.Now().Unix())
+	config.Signature = nil
+
+	sig, err := sign(config.SigHash().Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to sign the host config: %s", err.Error())
+	}
+	config.Signature = sig
+	return nil
+}
+
+// VerifyHostExtConfig checks that the host config is signed by the owner of the public key
+func VerifyHostExtConfig(config HostExtConfig, pubKey *ecdsa.PublicKey) error {
+	if len(config.Signature) == 0 {
+		return ErrHostConfigNotSigned
+	}
+	if pubKey == nil {
+		return errors.New("host public key is not provided")
+	}
+
+	signer, err := crypto.SigToPub(config.SigHash().Bytes(), config.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to recover the host config signer: %s", err.Error())
+	}
+	if !bytes.Equal(crypto.FromECDSAPub(signer), crypto.FromECDSAPub(pubKey)) {
+		return ErrInvalidHostConfigSignature
+	}
+	return nil
+}
+
+// CheckHostExtConfigFreshness checks that the host config is signed within the
+// HostConfigSignatureTimeout before now
+func CheckHostExtConfigFreshness(config HostExtConfig, now time.Time) error {
+	signedAt := time.Unix(int64(config.Timestamp), 0)
+	if signedAt.After(now.Add(HostConfigClockDrift)) || now.Sub(signedAt) > HostConfigSignatureTimeout {
+		return ErrStaleHostConfig
+	}
+	return nil
+}
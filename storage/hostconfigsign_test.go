@@ -0,0 +1,72 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+)
+
+func TestSignHostExtConfig(t *testing.T) {
+	sk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, sk)
+	}
+
+	config := HostExtConfig{
+		AcceptingContracts: true,
+		MaxDuration:        100,
+		StoragePrice:       common.NewBigInt(10),
+		Version:            ConfigVersion,
+	}
+	if err := VerifyHostExtConfig(config, &sk.PublicKey); err != ErrHostConfigNotSigned {
+		t.Fatalf("unsigned config: expect error %v, got %v", ErrHostConfigNotSigned, err)
+	}
+	if err := SignHostExtConfig(&config, sign); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyHostExtConfig(config, &sk.PublicKey); err != nil {
+		t.Fatalf("signed config: %v", err)
+	}
+	if err := VerifyHostExtConfig(config, &otherSk.PublicKey); err != ErrInvalidHostConfigSignature {
+		t.Fatalf("wrong key: expect error %v, got %v", ErrInvalidHostConfigSignature, err)
+	}
+
+	// tamper the price, the signature should not match anymore
+	tampered := config
+	tampered.StoragePrice = common.NewBigInt(1)
+	if err := VerifyHostExtConfig(tampered, &sk.PublicKey); err == nil {
+		t.Fatal("tampered config passed the verification")
+	}
+}
+
+func TestCheckHostExtConfigFreshness(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		signedAt time.Time
+		err      error
+	}{
+		{now, nil},
+		{now.Add(-HostConfigSignatureTimeout / 2), nil},
+		{now.Add(-2 * HostConfigSignatureTimeout), ErrStaleHostConfig},
+		{now.Add(2 * HostConfigClockDrift), ErrStaleHostConfig},
+	}
+	for i, test := range tests {
+		config := HostExtConfig{Timestamp: uint64(test.signedAt.Unix())}
+		if err := CheckHostExtConfigFreshness(config, now); err != test.err {
+			t.Errorf("test %d: expect error %v, got %v", i, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"math/bits"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/p2p/enr"
+)
+
+// HostRecord is the compact digest of the storage host config advertised in the node record,
+// with which the storage client could pre-filter the storage host candidates before dialing.
+// The record is only a hint, the storage host config retrieved from the storage host is
+// still the one used in the contract negotiation
+type HostRecord struct {
+	AcceptingContracts bool
+
+	// PriceBand is the number of decimal digits of the storage price, 0 if free
+	PriceBand uint8
+
+	// CapacityClass is the bit length of the number of free sectors, 0 if the storage host is full
+	CapacityClass uint8
+}
+
+// ENRKey implements enr.Entry
+func (HostRecord) ENRKey() string { return "dxhost" }
+
+// NewHostRecord creates the host record advertised from the storage host config
+func NewHostRecord(config HostExtConfig) HostRecord {
+	return HostRecord{
+		AcceptingContracts: config.AcceptingContracts,
+		PriceBand:          PriceBand(config.StoragePrice),
+		CapacityClass:      uint8(bits.Len64(config.RemainingStorage / SectorSize)),
+	}
+}
+
+// PriceBand returns the price band of the price, which is the number of its decimal digits
+func PriceBand(price common.BigInt) uint8 {
+	if price.Sign() <= 0 {
+		return 0
+	}
+	return uint8(len(price.String()))
+}
+
+// LoadHostRecord loads the host record from the node record. False is returned if the node
+// does not advertise the host record
+func LoadHostRecord(node *enode.Node) (record HostRecord, exists bool) {
+	if node == nil {
+		return HostRecord{}, false
+	}
+	if err := node.Load(&record); err != nil {
+		return HostRecord{}, false
+	}
+	return record, true
+}
+
+// Acceptable returns whether the storage host advertising the record could form a new contract
+func (r HostRecord) Acceptable() bool {
+	return r.AcceptingContracts && r.CapacityClass > 0
+}
+
+var _ enr.Entry = HostRecord{}
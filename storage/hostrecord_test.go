@@ -0,0 +1,51 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/p2p/enr"
+)
+
+func TestHostRecord(t *testing.T) {
+	config := HostExtConfig{
+		AcceptingContracts: true,
+		StoragePrice:       common.NewBigIntUint64(12345),
+		RemainingStorage:   5 * SectorSize,
+	}
+	record := NewHostRecord(config)
+	if record.PriceBand != 5 || record.CapacityClass != 3 || !record.Acceptable() {
+		t.Fatalf("host record not expected. Got %+v", record)
+	}
+	if full := NewHostRecord(HostExtConfig{AcceptingContracts: true}); full.Acceptable() {
+		t.Fatal("the storage host without remaining storage shall not be acceptable")
+	}
+
+	// the host record is carried in the signed node record
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r enr.Record
+	r.Set(record)
+	if err := enode.SignV4(&r, key); err != nil {
+		t.Fatal(err)
+	}
+	node, err := enode.New(enode.ValidSchemes, &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, exists := LoadHostRecord(node)
+	if !exists || loaded != record {
+		t.Fatalf("loaded host record not expected. Got %+v, %v", loaded, exists)
+	}
+	if _, exists := LoadHostRecord(enode.NewV4(&key.PublicKey, nil, 0, 0)); exists {
+		t.Fatal("the node without host record shall not advertise the host record")
+	}
+}
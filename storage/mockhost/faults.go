@@ -0,0 +1,124 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package mockhost
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var errInjectedDisconnect = errors.New("mock host disconnected by the fault injected")
+
+// Faults configures the faults injected into the negotiations with the storage clients. The
+// rates are the probabilities in the range [0, 1], drawn once for each request. A request is
+// either rejected, stalled or disconnected, so the sum of the three rates cannot exceed 1
+type Faults struct {
+	// Latency is the delay before each message is sent
+	Latency time.Duration
+
+	// RejectRate is the rate of the requests answered with the host negotiate error
+	RejectRate float64
+
+	// StallRate is the rate of the requests never answered, which is timed out by the client
+	StallRate float64
+
+	// DisconnectRate is the rate of the requests answered by dropping the connection
+	DisconnectRate float64
+
+	// CorruptRate is the rate of the downloads answered with the data corrupted
+	CorruptRate float64
+
+	// Seed is the seed of the random faults, so that a failed run can be reproduced
+	Seed int64
+}
+
+// fault is the fault drawn for a request
+type fault int
+
+const (
+	faultNone fault = iota
+	faultReject
+	faultStall
+	faultDisconnect
+)
+
+// validate checks the rates of the faults
+func (f Faults) validate() error {
+	rates := map[string]float64{
+		"reject":     f.RejectRate,
+		"stall":      f.StallRate,
+		"disconnect": f.DisconnectRate,
+		"corrupt":    f.CorruptRate,
+	}
+	for name, rate := range rates {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("the %s rate %v is out of range [0, 1]", name, rate)
+		}
+	}
+	if f.RejectRate+f.StallRate+f.DisconnectRate > 1 {
+		return errors.New("the sum of the reject, stall and disconnect rates exceeds 1")
+	}
+	if f.Latency < 0 {
+		return errors.New("the latency cannot be negative")
+	}
+	return nil
+}
+
+// faultInjector draws the faults from the seeded random source, which is shared by all
+// connections of the mock host
+type faultInjector struct {
+	faults Faults
+	rand   *rand.Rand
+	lock   sync.Mutex
+}
+
+func newFaultInjector(faults Faults) *faultInjector {
+	return &faultInjector{
+		faults: faults,
+		rand:   rand.New(rand.NewSource(faults.Seed)),
+	}
+}
+
+// request draws the fault of the request received
+func (fi *faultInjector) request() fault {
+	fi.lock.Lock()
+	defer fi.lock.Unlock()
+
+	r := fi.rand.Float64()
+	switch {
+	case r < fi.faults.DisconnectRate:
+		return faultDisconnect
+	case r < fi.faults.DisconnectRate+fi.faults.StallRate:
+		return faultStall
+	case r < fi.faults.DisconnectRate+fi.faults.StallRate+fi.faults.RejectRate:
+		return faultReject
+	default:
+		return faultNone
+	}
+}
+
+// corrupt returns the data with a random byte flipped if the corruption is drawn. Otherwise
+// the data is returned as it is
+func (fi *faultInjector) corrupt(data []byte) []byte {
+	fi.lock.Lock()
+	defer fi.lock.Unlock()
+
+	if len(data) == 0 || fi.rand.Float64() >= fi.faults.CorruptRate {
+		return data
+	}
+	corrupted := append([]byte(nil), data...)
+	corrupted[fi.rand.Intn(len(corrupted))] ^= 0xff
+	return corrupted
+}
+
+// delay sleeps for the latency configured
+func (fi *faultInjector) delay() {
+	if fi.faults.Latency > 0 {
+		time.Sleep(fi.faults.Latency)
+	}
+}
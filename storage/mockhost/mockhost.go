@@ -0,0 +1,303 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package mockhost implements a lightweight storage host speaking the storage negotiation
+// protocol, for developing and testing the storage client without running a full node as
+// the host. The mock host does not sync the chain, nor submit any transaction: the contracts
+// are accepted as they are signed, and the sectors are kept in memory or in a directory.
+// Faults can be injected into the negotiations to exercise the error handling of the client.
+package mockhost
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/p2p"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storagehost"
+)
+
+// the eth protocol the storage negotiation runs on, which must match the storage client's
+const (
+	protocolName    = "eth"
+	protocolVersion = 64
+	protocolLength  = 100
+	statusMsg       = 0x00
+
+	handshakeTimeout = 5 * time.Second
+	maxPeers         = 50
+)
+
+// DefaultTotalStorage is the storage capacity of the mock host by default
+const DefaultTotalStorage = 1 << 30
+
+// statusData is the eth handshake message
+type statusData struct {
+	ProtocolVersion uint32
+	NetworkId       uint64
+	TD              *big.Int
+	CurrentBlock    common.Hash
+	GenesisBlock    common.Hash
+}
+
+// Config is the config of the mock host
+type Config struct {
+	// ListenAddr is the p2p listening address
+	ListenAddr string
+
+	// NetworkID and Genesis must match the storage client's for the handshake
+	NetworkID uint64
+	Genesis   common.Hash
+
+	// NodeKey is the p2p node key, and AccountKey is the key of the payment address signing
+	// the contracts. Both are generated if not provided
+	NodeKey    *ecdsa.PrivateKey
+	AccountKey *ecdsa.PrivateKey
+
+	// SectorDir is the directory the sectors are stored in. The sectors are kept in memory
+	// if not provided
+	SectorDir string
+
+	// TotalStorage is the storage capacity in bytes
+	TotalStorage uint64
+
+	// Faults is the faults injected into the negotiations
+	Faults Faults
+}
+
+// MockHost is the mock storage host
+type MockHost struct {
+	config     Config
+	hostConfig storage.HostIntConfig
+
+	server *p2p.Server
+	store  sectorStore
+	faults *faultInjector
+
+	contracts   map[common.Hash]*contract
+	usedSectors uint64
+	lock        sync.Mutex
+
+	log log.Logger
+}
+
+// contract is the mock host's view of the storage contract
+type contract struct {
+	revision types.StorageContractRevision
+	roots    []common.Hash
+}
+
+// New creates the mock host with the config
+func New(config Config) (*MockHost, error) {
+	if err := config.Faults.validate(); err != nil {
+		return nil, err
+	}
+	var err error
+	if config.NodeKey == nil {
+		if config.NodeKey, err = crypto.GenerateKey(); err != nil {
+			return nil, err
+		}
+	}
+	if config.AccountKey == nil {
+		if config.AccountKey, err = crypto.GenerateKey(); err != nil {
+			return nil, err
+		}
+	}
+	if config.TotalStorage == 0 {
+		config.TotalStorage = DefaultTotalStorage
+	}
+
+	var store sectorStore = newMemoryStore()
+	if config.SectorDir != "" {
+		if store, err = newDirStore(config.SectorDir); err != nil {
+			return nil, err
+		}
+	}
+
+	mh := &MockHost{
+		config:     config,
+		hostConfig: storagehost.DefaultConfig(),
+		store:      store,
+		faults:     newFaultInjector(config.Faults),
+		contracts:  make(map[common.Hash]*contract),
+		log:        log.New("module", "mockhost"),
+	}
+	mh.server = &p2p.Server{Config: p2p.Config{
+		PrivateKey:  config.NodeKey,
+		MaxPeers:    maxPeers,
+		ListenAddr:  config.ListenAddr,
+		NoDiscovery: true,
+		Name:        "mockhost",
+		Protocols: []p2p.Protocol{{
+			Name:    protocolName,
+			Version: protocolVersion,
+			Length:  protocolLength,
+			Run:     mh.runPeer,
+		}},
+	}}
+	return mh, nil
+}
+
+// Start starts listening for the storage clients
+func (mh *MockHost) Start() error {
+	return mh.server.Start()
+}
+
+// Stop disconnects all storage clients and stops listening
+func (mh *MockHost) Stop() {
+	mh.server.Stop()
+}
+
+// Enode returns the enode url of the mock host, which the storage client adds the host by
+func (mh *MockHost) Enode() string {
+	return mh.server.Self().String()
+}
+
+// PaymentAddress returns the payment address of the mock host
+func (mh *MockHost) PaymentAddress() common.Address {
+	return crypto.PubkeyToAddress(mh.config.AccountKey.PublicKey)
+}
+
+// runPeer runs the protocol with the connected storage client
+func (mh *MockHost) runPeer(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+	if err := mh.handshake(rw); err != nil {
+		mh.log.Debug("mock host handshake failed", "peer", p.ID(), "err", err)
+		return err
+	}
+	mh.log.Debug("storage client connected", "peer", p.ID())
+	return mh.serve(rw)
+}
+
+// handshake exchanges the eth status with the storage client. The total difficulty sent is
+// zero, so that the client never syncs from the mock host
+func (mh *MockHost) handshake(rw p2p.MsgReadWriter) error {
+	errc := make(chan error, 2)
+	go func() {
+		errc <- p2p.Send(rw, statusMsg, &statusData{
+			ProtocolVersion: protocolVersion,
+			NetworkId:       mh.config.NetworkID,
+			TD:              new(big.Int),
+			CurrentBlock:    mh.config.Genesis,
+			GenesisBlock:    mh.config.Genesis,
+		})
+	}()
+	go func() {
+		errc <- mh.readStatus(rw)
+	}()
+	timeout := time.NewTimer(handshakeTimeout)
+	defer timeout.Stop()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errc:
+			if err != nil {
+				return err
+			}
+		case <-timeout.C:
+			return p2p.DiscReadTimeout
+		}
+	}
+	return nil
+}
+
+// readStatus reads the status of the storage client, and checks it matches the mock host's
+func (mh *MockHost) readStatus(rw p2p.MsgReadWriter) error {
+	msg, err := rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Code != statusMsg {
+		msg.Discard()
+		return fmt.Errorf("first msg has code %x (!= %x)", msg.Code, statusMsg)
+	}
+	var status statusData
+	if err := msg.Decode(&status); err != nil {
+		return err
+	}
+	switch {
+	case status.GenesisBlock != mh.config.Genesis:
+		return fmt.Errorf("genesis block mismatch: %x (!= %x)", status.GenesisBlock, mh.config.Genesis)
+	case status.NetworkId != mh.config.NetworkID:
+		return fmt.Errorf("network id mismatch: %d (!= %d)", status.NetworkId, mh.config.NetworkID)
+	case status.ProtocolVersion != protocolVersion:
+		return fmt.Errorf("protocol version mismatch: %d (!= %d)", status.ProtocolVersion, protocolVersion)
+	}
+	return nil
+}
+
+// externalConfig returns the external config of the mock host signed by the node key. The
+// prices are the storage host's defaults, without the dynamic pricing
+func (mh *MockHost) externalConfig() storage.HostExtConfig {
+	mh.lock.Lock()
+	remaining := mh.config.TotalStorage - mh.usedSectors*storage.SectorSize
+	mh.lock.Unlock()
+
+	config := storage.HostExtConfig{
+		AcceptingContracts:     true,
+		MaxDownloadBatchSize:   mh.hostConfig.MaxDownloadBatchSize,
+		MaxDuration:            mh.hostConfig.MaxDuration,
+		MaxReviseBatchSize:     mh.hostConfig.MaxReviseBatchSize,
+		SectorSize:             storage.SectorSize,
+		WindowSize:             mh.hostConfig.WindowSize,
+		PaymentAddress:         mh.PaymentAddress(),
+		TotalStorage:           mh.config.TotalStorage,
+		RemainingStorage:       remaining,
+		Deposit:                mh.hostConfig.Deposit,
+		MaxDeposit:             mh.hostConfig.MaxDeposit,
+		BaseRPCPrice:           mh.hostConfig.BaseRPCPrice,
+		ContractPrice:          mh.hostConfig.ContractPrice,
+		DownloadBandwidthPrice: mh.hostConfig.DownloadBandwidthPrice,
+		SectorAccessPrice:      mh.hostConfig.SectorAccessPrice,
+		StoragePrice:           mh.hostConfig.StoragePrice,
+		UploadBandwidthPrice:   mh.hostConfig.UploadBandwidthPrice,
+		Version:                storage.ConfigVersion,
+	}
+	if err := storage.SignHostExtConfig(&config, func(hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, mh.config.NodeKey)
+	}); err != nil {
+		mh.log.Warn("failed to sign the host external config", "err", err)
+	}
+	return config
+}
+
+// sign signs the hash with the account key
+func (mh *MockHost) sign(hash common.Hash) ([]byte, error) {
+	return crypto.Sign(hash.Bytes(), mh.config.AccountKey)
+}
+
+// getContract returns the copy of the contract
+func (mh *MockHost) getContract(id common.Hash) (contract, error) {
+	mh.lock.Lock()
+	defer mh.lock.Unlock()
+	c, exist := mh.contracts[id]
+	if !exist {
+		return contract{}, errors.New("contract not found")
+	}
+	return contract{revision: c.revision, roots: append([]common.Hash(nil), c.roots...)}, nil
+}
+
+// setContract saves the contract, counting the sectors newly stored
+func (mh *MockHost) setContract(id common.Hash, c contract, sectorsGained int) {
+	mh.lock.Lock()
+	defer mh.lock.Unlock()
+	mh.contracts[id] = &c
+	mh.usedSectors += uint64(sectorsGained)
+}
+
+// reserve checks there is enough storage left for the sectors
+func (mh *MockHost) reserve(sectors int) error {
+	mh.lock.Lock()
+	defer mh.lock.Unlock()
+	if (mh.usedSectors+uint64(sectors))*storage.SectorSize > mh.config.TotalStorage {
+		return errors.New("not enough storage left")
+	}
+	return nil
+}
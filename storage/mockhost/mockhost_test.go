@@ -0,0 +1,246 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package mockhost
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/p2p"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestFaultsValidate(t *testing.T) {
+	tests := []struct {
+		faults Faults
+		valid  bool
+	}{
+		{Faults{}, true},
+		{Faults{RejectRate: 0.5, StallRate: 0.3, DisconnectRate: 0.2, CorruptRate: 1}, true},
+		{Faults{RejectRate: 0.5, StallRate: 0.3, DisconnectRate: 0.3}, false},
+		{Faults{CorruptRate: 1.1}, false},
+		{Faults{RejectRate: -0.1}, false},
+		{Faults{Latency: -1}, false},
+	}
+	for i, test := range tests {
+		if err := test.faults.validate(); (err == nil) != test.valid {
+			t.Errorf("test %d: expect valid %v, got error %v", i, test.valid, err)
+		}
+	}
+}
+
+func TestSectorStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mockhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ds, err := newDirStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, data := common.HexToHash("0x1"), []byte("sector")
+	for _, store := range []sectorStore{newMemoryStore(), ds} {
+		if _, err := store.get(root); err != errSectorNotFound {
+			t.Errorf("expect error %v, got %v", errSectorNotFound, err)
+		}
+		if err := store.put(root, data); err != nil {
+			t.Fatal(err)
+		}
+		got, err := store.get(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("expect sector %x, got %x", data, got)
+		}
+	}
+}
+
+func TestMockHost_Negotiation(t *testing.T) {
+	mh, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw, hostRW := p2p.MsgPipe()
+	defer rw.Close()
+	go mh.serve(hostRW)
+
+	// host config
+	send(t, rw, storage.HostConfigReqMsg, struct{}{})
+	var config storage.HostExtConfig
+	expectMsg(t, rw, storage.HostConfigRespMsg, &config)
+	if config.PaymentAddress != mh.PaymentAddress() || !config.AcceptingContracts {
+		t.Fatalf("unexpected host config %+v", config)
+	}
+
+	// contract create
+	clientKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := types.StorageContract{
+		WindowStart:        100,
+		WindowEnd:          200,
+		ValidProofOutputs:  testOutputs(crypto.PubkeyToAddress(clientKey.PublicKey), mh.PaymentAddress()),
+		MissedProofOutputs: testOutputs(crypto.PubkeyToAddress(clientKey.PublicKey), mh.PaymentAddress()),
+	}
+	sign, err := crypto.Sign(sc.RLPHash().Bytes(), clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send(t, rw, storage.ContractCreateReqMsg, storage.ContractCreateRequest{StorageContract: sc, Sign: sign})
+	expectMsg(t, rw, storage.ContractCreateHostSign, nil)
+	// the ping is answered in the middle of the negotiation
+	send(t, rw, storage.ClientPingMsg, uint64(1))
+	var pong uint64
+	expectMsg(t, rw, storage.HostPongMsg, &pong)
+	if pong != 1 {
+		t.Errorf("expect pong 1, got %v", pong)
+	}
+	send(t, rw, storage.ContractCreateClientRevisionSign, []byte("client sign"))
+	expectMsg(t, rw, storage.ContractCreateRevisionSign, nil)
+	send(t, rw, storage.ClientCommitSuccessMsg, "success")
+	expectMsg(t, rw, storage.HostAckMsg, nil)
+
+	// upload
+	data := make([]byte, storage.SectorSize)
+	copy(data, "mock host")
+	root := merkle.Sha256MerkleTreeRoot(data)
+	send(t, rw, storage.ContractUploadReqMsg, storage.UploadRequest{
+		StorageContractID:    sc.ID(),
+		Actions:              []storage.UploadAction{{Type: storage.UploadActionAppend, Data: data}},
+		NewRevisionNumber:    2,
+		NewValidProofValues:  []*big.Int{big.NewInt(90), big.NewInt(110)},
+		NewMissedProofValues: []*big.Int{big.NewInt(90), big.NewInt(110)},
+	})
+	var proof storage.UploadMerkleProof
+	expectMsg(t, rw, storage.ContractUploadMerkleProofMsg, &proof)
+	if expect := merkle.Sha256CachedTreeRoot2([]common.Hash{root}); proof.NewMerkleRoot != expect {
+		t.Errorf("expect merkle root %x, got %x", expect, proof.NewMerkleRoot)
+	}
+	send(t, rw, storage.ContractUploadClientRevisionSign, []byte("client sign"))
+	expectMsg(t, rw, storage.ContractUploadRevisionSign, nil)
+	send(t, rw, storage.ClientCommitSuccessMsg, "success")
+	expectMsg(t, rw, storage.HostAckMsg, nil)
+
+	// download
+	send(t, rw, storage.ContractDownloadReqMsg, storage.DownloadRequest{
+		StorageContractID:    sc.ID(),
+		Sector:               storage.DownloadRequestSector{MerkleRoot: root, Length: storage.SegmentSize},
+		MerkleProof:          true,
+		NewRevisionNumber:    3,
+		NewValidProofValues:  []*big.Int{big.NewInt(80), big.NewInt(120)},
+		NewMissedProofValues: []*big.Int{big.NewInt(80), big.NewInt(120)},
+	})
+	var resp storage.DownloadResponse
+	expectMsg(t, rw, storage.ContractDownloadDataMsg, &resp)
+	if !bytes.Equal(resp.Data, data[:storage.SegmentSize]) || len(resp.MerkleProof) == 0 {
+		t.Errorf("unexpected download response %x with proof %v", resp.Data, resp.MerkleProof)
+	}
+	send(t, rw, storage.ClientCommitSuccessMsg, "success")
+	expectMsg(t, rw, storage.HostAckMsg, nil)
+
+	// audit, the unsigned request is rejected
+	send(t, rw, storage.ContractAuditReqMsg, storage.ContractAuditRequest{StorageContractID: sc.ID()})
+	expectMsg(t, rw, storage.HostNegotiateErrorMsg, nil)
+	auditReq := storage.ContractAuditRequest{StorageContractID: sc.ID()}
+	if auditReq.Signature, err = crypto.Sign(auditReq.SigHash().Bytes(), clientKey); err != nil {
+		t.Fatal(err)
+	}
+	send(t, rw, storage.ContractAuditReqMsg, auditReq)
+	var state storage.ContractAuditState
+	expectMsg(t, rw, storage.ContractAuditRespMsg, &state)
+	if state.RevisionNumber != 3 || state.FileSize != storage.SectorSize || len(state.MerkleRoots) != 1 || state.MerkleRoots[0] != root {
+		t.Errorf("unexpected contract audit state %+v", state)
+	}
+}
+
+func TestMockHost_SpeedTest(t *testing.T) {
+	mh, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw, hostRW := p2p.MsgPipe()
+	defer rw.Close()
+	go mh.serve(hostRW)
+
+	send(t, rw, storage.SpeedTestReqMsg, storage.SpeedTestRequest{Data: make([]byte, 1024)})
+	var resp storage.SpeedTestResponse
+	expectMsg(t, rw, storage.SpeedTestRespMsg, &resp)
+	if resp.Received != 1024 {
+		t.Errorf("expected 1024 bytes received, got %v", resp.Received)
+	}
+}
+
+func TestMockHost_Faults(t *testing.T) {
+	mh, err := New(Config{Faults: Faults{RejectRate: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw, hostRW := p2p.MsgPipe()
+	defer rw.Close()
+	go mh.serve(hostRW)
+
+	send(t, rw, storage.ContractAuditReqMsg, storage.ContractAuditRequest{})
+	expectMsg(t, rw, storage.HostNegotiateErrorMsg, nil)
+
+	// the disconnection drops the connection
+	mh.faults = newFaultInjector(Faults{DisconnectRate: 1})
+	rw, hostRW = p2p.MsgPipe()
+	defer rw.Close()
+	errc := make(chan error, 1)
+	go func() { errc <- mh.serve(hostRW) }()
+	send(t, rw, storage.ContractAuditReqMsg, storage.ContractAuditRequest{})
+	if err := <-errc; err != errInjectedDisconnect {
+		t.Errorf("expect error %v, got %v", errInjectedDisconnect, err)
+	}
+
+	fi := newFaultInjector(Faults{CorruptRate: 1})
+	data := []byte("sector data")
+	if bytes.Equal(fi.corrupt(data), data) {
+		t.Error("the data shall be corrupted")
+	}
+	if !bytes.Equal(data, []byte("sector data")) {
+		t.Error("the original data shall not be changed")
+	}
+}
+
+func testOutputs(client, host common.Address) []types.DxcoinCharge {
+	return []types.DxcoinCharge{
+		{Address: client, Value: big.NewInt(100)},
+		{Address: host, Value: big.NewInt(100)},
+	}
+}
+
+func send(t *testing.T, rw p2p.MsgWriter, code uint64, data interface{}) {
+	if err := p2p.Send(rw, code, data); err != nil {
+		t.Fatalf("failed to send message %x: %v", code, err)
+	}
+}
+
+func expectMsg(t *testing.T, rw p2p.MsgReader, code uint64, val interface{}) {
+	msg, err := rw.ReadMsg()
+	if err != nil {
+		t.Fatalf("failed to read message %x: %v", code, err)
+	}
+	defer msg.Discard()
+	if msg.Code != code {
+		t.Fatalf("expect message %x, got %x", code, msg.Code)
+	}
+	if val != nil {
+		if err := msg.Decode(val); err != nil {
+			t.Fatalf("failed to decode message %x: %v", code, err)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package mockhost
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/p2p"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// requestHandler handles the request of the storage client. The negotiation errors are sent
+// to the client by the handler, and the error returned drops the connection
+type requestHandler func(mh *MockHost, s *session, msg p2p.Msg) error
+
+var requestHandlers = map[uint64]requestHandler{
+	storage.ContractCreateReqMsg:   (*MockHost).contractCreate,
+	storage.ContractUploadReqMsg:   (*MockHost).upload,
+	storage.ContractDownloadReqMsg: (*MockHost).download,
+	storage.ContractAuditReqMsg:    (*MockHost).contractAudit,
+	storage.SpeedTestReqMsg:        (*MockHost).speedTest,
+}
+
+// session is the negotiation with the storage client
+type session struct {
+	rw     p2p.MsgReadWriter
+	faults *faultInjector
+}
+
+// serve handles the messages of the storage client one by one until the connection is closed
+func (mh *MockHost) serve(rw p2p.MsgReadWriter) error {
+	s := &session{rw: rw, faults: mh.faults}
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		if err := mh.handleMsg(s, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handleMsg handles the message out of the negotiations. The eth messages are discarded, as
+// well as the dialogue messages of the negotiations stalled or failed
+func (mh *MockHost) handleMsg(s *session, msg p2p.Msg) error {
+	if handled, err := s.heartbeat(msg); handled {
+		return err
+	}
+	if msg.Code == storage.HostConfigReqMsg {
+		msg.Discard()
+		return s.send(storage.HostConfigRespMsg, mh.externalConfig())
+	}
+	handler, exist := requestHandlers[msg.Code]
+	if !exist {
+		return msg.Discard()
+	}
+
+	switch mh.faults.request() {
+	case faultDisconnect:
+		msg.Discard()
+		return errInjectedDisconnect
+	case faultStall:
+		return msg.Discard()
+	case faultReject:
+		msg.Discard()
+		return s.negotiateError()
+	}
+	return handler(mh, s, msg)
+}
+
+// heartbeat answers the ping of the storage client, and discards the pong and eth messages
+func (s *session) heartbeat(msg p2p.Msg) (bool, error) {
+	switch {
+	case msg.Code == storage.ClientPingMsg:
+		var sent uint64
+		if err := msg.Decode(&sent); err != nil {
+			return true, err
+		}
+		return true, p2p.Send(s.rw, storage.HostPongMsg, sent)
+	case msg.Code == storage.ClientPongMsg || msg.Code < storage.HostConfigRespMsg:
+		return true, msg.Discard()
+	}
+	return false, nil
+}
+
+// wait waits for the next dialogue message of the negotiation
+func (s *session) wait() (p2p.Msg, error) {
+	for {
+		msg, err := s.rw.ReadMsg()
+		if err != nil {
+			return msg, err
+		}
+		if handled, err := s.heartbeat(msg); handled {
+			if err != nil {
+				return msg, err
+			}
+			continue
+		}
+		return msg, nil
+	}
+}
+
+// send sends the message after the latency injected
+func (s *session) send(code uint64, data interface{}) error {
+	s.faults.delay()
+	return p2p.Send(s.rw, code, data)
+}
+
+func (s *session) hostAck() error {
+	return s.send(storage.HostAckMsg, "host ack")
+}
+
+func (s *session) negotiateError() error {
+	return s.send(storage.HostNegotiateErrorMsg, storage.ErrHostNegotiate.Error())
+}
+
+// finish ends the negotiation by the error. The client negotiate error is acknowledged, and
+// the host negotiate error is sent to the client
+func (s *session) finish(mh *MockHost, hostNegotiateErr, clientNegotiateErr error) error {
+	switch {
+	case clientNegotiateErr != nil:
+		mh.log.Debug("storage client failed the negotiation", "err", clientNegotiateErr)
+		return s.hostAck()
+	case hostNegotiateErr != nil:
+		mh.log.Debug("mock host failed the negotiation", "err", hostNegotiateErr)
+		return s.negotiateError()
+	}
+	return nil
+}
+
+// waitCommit waits for the commit of the storage client. The commit is true if the client
+// committed successfully, otherwise the client negotiate error is returned
+func (s *session) waitCommit() (bool, error, error) {
+	msg, err := s.wait()
+	if err != nil {
+		return false, nil, err
+	}
+	msg.Discard()
+	switch msg.Code {
+	case storage.ClientCommitSuccessMsg:
+		return true, nil, nil
+	case storage.ClientCommitFailedMsg:
+		return false, storage.ErrClientCommit, nil
+	case storage.ClientNegotiateErrorMsg:
+		return false, storage.ErrClientNegotiate, nil
+	default:
+		return false, fmt.Errorf("unexpected message %x while waiting for the commit", msg.Code), nil
+	}
+}
+
+// waitRevisionSign waits for the revision signed by the storage client
+func (s *session) waitRevisionSign() (sign []byte, clientNegotiateErr, err error) {
+	msg, err := s.wait()
+	if err != nil {
+		return nil, nil, err
+	}
+	if msg.Code == storage.ClientNegotiateErrorMsg {
+		msg.Discard()
+		return nil, storage.ErrClientNegotiate, nil
+	}
+	if err := msg.Decode(&sign); err != nil {
+		return nil, fmt.Errorf("failed to decode the client revision sign: %s", err.Error()), nil
+	}
+	return sign, nil, nil
+}
+
+// commitFailed tells the storage client the mock host failed to commit, and acknowledges the
+// client's ack
+func (s *session) commitFailed() error {
+	if err := s.send(storage.HostCommitFailedMsg, storage.ErrHostCommit.Error()); err != nil {
+		return err
+	}
+	msg, err := s.wait()
+	if err != nil {
+		return err
+	}
+	msg.Discard()
+	return s.hostAck()
+}
+
+// contractCreate handles the contract create request. The contract is signed as it is, the
+// contract create tx is submitted by the storage client
+func (mh *MockHost) contractCreate(s *session, reqMsg p2p.Msg) error {
+	var req storage.ContractCreateRequest
+	if err := reqMsg.Decode(&req); err != nil {
+		return s.finish(mh, nil, fmt.Errorf("failed to decode the contract create request: %s", err.Error()))
+	}
+	sc := req.StorageContract
+	clientPK, err := crypto.SigToPub(sc.RLPHash().Bytes(), req.Sign)
+	if err != nil {
+		return s.finish(mh, nil, fmt.Errorf("failed to recover the client public key: %s", err.Error()))
+	}
+	if len(sc.ValidProofOutputs) != 2 || len(sc.MissedProofOutputs) != 2 {
+		return s.finish(mh, errors.New("the contract outputs are malformed"), nil)
+	}
+	if sc.ValidProofOutputs[1].Address != mh.PaymentAddress() {
+		return s.finish(mh, errors.New("the contract is not paying the mock host"), nil)
+	}
+
+	hostContractSign, err := mh.sign(sc.RLPHash())
+	if err != nil {
+		return s.finish(mh, err, nil)
+	}
+	if err := s.send(storage.ContractCreateHostSign, hostContractSign); err != nil {
+		return err
+	}
+
+	clientRevisionSign, clientNegotiateErr, err := s.waitRevisionSign()
+	if err != nil {
+		return err
+	}
+	if clientNegotiateErr != nil {
+		return s.finish(mh, nil, clientNegotiateErr)
+	}
+	revision := types.StorageContractRevision{
+		ParentID: sc.RLPHash(),
+		UnlockConditions: types.UnlockConditions{
+			PaymentAddresses: []common.Address{
+				crypto.PubkeyToAddress(*clientPK),
+				mh.PaymentAddress(),
+			},
+			SignaturesRequired: 2,
+		},
+		NewRevisionNumber:     1,
+		NewFileSize:           sc.FileSize,
+		NewFileMerkleRoot:     sc.FileMerkleRoot,
+		NewWindowStart:        sc.WindowStart,
+		NewWindowEnd:          sc.WindowEnd,
+		NewValidProofOutputs:  sc.ValidProofOutputs,
+		NewMissedProofOutputs: sc.MissedProofOutputs,
+		NewUnlockHash:         sc.UnlockHash,
+	}
+	hostRevisionSign, err := mh.sign(revision.RLPHash())
+	if err != nil {
+		return s.finish(mh, err, nil)
+	}
+	revision.Signatures = [][]byte{clientRevisionSign, hostRevisionSign}
+	if err := s.send(storage.ContractCreateRevisionSign, hostRevisionSign); err != nil {
+		return err
+	}
+
+	committed, clientNegotiateErr, err := s.waitCommit()
+	if err != nil {
+		return err
+	}
+	if !committed {
+		return s.finish(mh, nil, clientNegotiateErr)
+	}
+	c := contract{revision: revision}
+	if req.Renew {
+		if old, err := mh.getContract(req.OldContractID); err == nil {
+			c.roots = old.roots
+		}
+	}
+	mh.setContract(sc.ID(), c, 0)
+	return s.hostAck()
+}
+
+// upload handles the upload request, appending the sectors to the contract
+func (mh *MockHost) upload(s *session, reqMsg p2p.Msg) error {
+	var req storage.UploadRequest
+	if err := reqMsg.Decode(&req); err != nil {
+		return s.finish(mh, nil, fmt.Errorf("failed to decode the upload request: %s", err.Error()))
+	}
+	c, err := mh.getContract(req.StorageContractID)
+	if err != nil {
+		return s.finish(mh, err, nil)
+	}
+
+	newRoots := append([]common.Hash(nil), c.roots...)
+	var sectorsGained [][]byte
+	for _, action := range req.Actions {
+		if action.Type != storage.UploadActionAppend {
+			return s.finish(mh, fmt.Errorf("unknown upload action type: %s", action.Type), nil)
+		}
+		newRoots = append(newRoots, merkle.Sha256MerkleTreeRoot(action.Data))
+		sectorsGained = append(sectorsGained, action.Data)
+	}
+	if err := mh.reserve(len(sectorsGained)); err != nil {
+		return s.finish(mh, err, nil)
+	}
+
+	newRevision, err := paymentRevision(c.revision, req.NewRevisionNumber, req.NewValidProofValues, req.NewMissedProofValues)
+	if err != nil {
+		return s.finish(mh, err, nil)
+	}
+	newRevision.NewFileSize += storage.SectorSize * uint64(len(sectorsGained))
+	newRevision.NewFileMerkleRoot = merkle.Sha256CachedTreeRoot2(newRoots)
+
+	// only the appends are supported, so no old leaf is changed
+	oldHashSet, err := merkle.Sha256DiffProof(c.roots, nil, uint64(len(c.roots)))
+	if err != nil {
+		return s.finish(mh, fmt.Errorf("error construct the merkle proof: %s", err.Error()), nil)
+	}
+	if err := s.send(storage.ContractUploadMerkleProofMsg, storage.UploadMerkleProof{
+		OldSubtreeHashes: oldHashSet,
+		NewMerkleRoot:    newRevision.NewFileMerkleRoot,
+	}); err != nil {
+		return err
+	}
+
+	clientRevisionSign, clientNegotiateErr, err := s.waitRevisionSign()
+	if err != nil {
+		return err
+	}
+	if clientNegotiateErr != nil {
+		return s.finish(mh, nil, clientNegotiateErr)
+	}
+	hostSig, err := mh.sign(newRevision.RLPHash())
+	if err != nil {
+		return s.finish(mh, err, nil)
+	}
+	newRevision.Signatures = [][]byte{clientRevisionSign, hostSig}
+	if err := s.send(storage.ContractUploadRevisionSign, hostSig); err != nil {
+		return err
+	}
+
+	committed, clientNegotiateErr, err := s.waitCommit()
+	if err != nil {
+		return err
+	}
+	if !committed {
+		return s.finish(mh, nil, clientNegotiateErr)
+	}
+	for i, data := range sectorsGained {
+		if err := mh.store.put(newRoots[len(c.roots)+i], data); err != nil {
+			mh.log.Warn("mock host failed to store the sector", "err", err)
+			return s.commitFailed()
+		}
+	}
+	mh.setContract(req.StorageContractID, contract{revision: newRevision, roots: newRoots}, len(sectorsGained))
+	return s.hostAck()
+}
+
+// download handles the download request of a sector. The data sent may be corrupted by the
+// fault injected
+func (mh *MockHost) download(s *session, reqMsg p2p.Msg) error {
+	var req storage.DownloadRequest
+	if err := reqMsg.Decode(&req); err != nil {
+		return s.finish(mh, nil, fmt.Errorf("failed to decode the download request: %s", err.Error()))
+	}
+	c, err := mh.getContract(req.StorageContractID)
+	if err != nil {
+		return s.finish(mh, err, nil)
+	}
+
+	sec := req.Sector
+	switch {
+	case uint64(sec.Offset)+uint64(sec.Length) > storage.SectorSize:
+		err = errors.New("download out boundary of sector")
+	case sec.Length == 0:
+		err = errors.New("length cannot be 0")
+	case req.MerkleProof && (sec.Offset%storage.SegmentSize != 0 || sec.Length%storage.SegmentSize != 0):
+		err = errors.New("offset and length must be multiples of SegmentSize when requesting a Merkle proof")
+	}
+	if err != nil {
+		return s.finish(mh, fmt.Errorf("download request validation failed: %s", err.Error()), nil)
+	}
+
+	newRevision, err := paymentRevision(c.revision, req.NewRevisionNumber, req.NewValidProofValues, req.NewMissedProofValues)
+	if err != nil {
+		return s.finish(mh, err, nil)
+	}
+	hostSig, err := mh.sign(newRevision.RLPHash())
+	if err != nil {
+		return s.finish(mh, err, nil)
+	}
+	newRevision.Signatures = [][]byte{req.Signature, hostSig}
+
+	sectorData, err := mh.store.get(sec.MerkleRoot)
+	if err != nil {
+		return s.finish(mh, fmt.Errorf("mock host failed to read sector: %s", err.Error()), nil)
+	}
+	var proof []common.Hash
+	if req.MerkleProof {
+		proofStart := int(sec.Offset) / merkle.LeafSize
+		proofEnd := int(sec.Offset+sec.Length) / merkle.LeafSize
+		if proof, err = merkle.Sha256RangeProof(sectorData, proofStart, proofEnd); err != nil {
+			return s.finish(mh, fmt.Errorf("mock host failed to generate the merkle proof: %s", err.Error()), nil)
+		}
+	}
+	if err := s.send(storage.ContractDownloadDataMsg, storage.DownloadResponse{
+		Signature:   hostSig,
+		Data:        mh.faults.corrupt(sectorData[sec.Offset : sec.Offset+sec.Length]),
+		MerkleProof: proof,
+	}); err != nil {
+		return err
+	}
+
+	committed, clientNegotiateErr, err := s.waitCommit()
+	if err != nil {
+		return err
+	}
+	if !committed {
+		return s.finish(mh, nil, clientNegotiateErr)
+	}
+	c.revision = newRevision
+	mh.setContract(req.StorageContractID, c, 0)
+	return s.hostAck()
+}
+
+// contractAudit handles the contract audit request, responding with the mock host's view of
+// the contract
+func (mh *MockHost) contractAudit(s *session, reqMsg p2p.Msg) error {
+	var req storage.ContractAuditRequest
+	if err := reqMsg.Decode(&req); err != nil {
+		return s.finish(mh, fmt.Errorf("failed to decode the contract audit request: %s", err.Error()), nil)
+	}
+	c, err := mh.getContract(req.StorageContractID)
+	if err != nil {
+		return s.finish(mh, err, nil)
+	}
+	if err := storage.VerifyContractAuditRequest(req, c.revision.NewValidProofOutputs[0].Address); err != nil {
+		return s.finish(mh, err, nil)
+	}
+	return s.send(storage.ContractAuditRespMsg, storage.NewContractAuditState(req.StorageContractID, c.revision, c.roots))
+}
+
+// speedTest handles the speed test request, responding with the number of probe bytes received
+func (mh *MockHost) speedTest(s *session, reqMsg p2p.Msg) error {
+	var req storage.SpeedTestRequest
+	if err := reqMsg.Decode(&req); err != nil {
+		return s.finish(mh, fmt.Errorf("failed to decode the speed test request: %s", err.Error()), nil)
+	}
+	return s.send(storage.SpeedTestRespMsg, storage.SpeedTestResponse{Received: uint64(len(req.Data))})
+}
+
+// paymentRevision constructs the new revision with the proof values of the storage client.
+// The payments are not verified by the mock host
+func paymentRevision(current types.StorageContractRevision, revisionNumber uint64, validValues, missedValues []*big.Int) (types.StorageContractRevision, error) {
+	if len(validValues) != len(current.NewValidProofOutputs) || len(missedValues) != len(current.NewMissedProofOutputs) {
+		return current, errors.New("the number of proof values not match the old")
+	}
+	if revisionNumber <= current.NewRevisionNumber {
+		return current, errors.New("the revision number must increase")
+	}
+	revision := current
+	revision.NewRevisionNumber = revisionNumber
+	revision.NewValidProofOutputs = make([]types.DxcoinCharge, len(validValues))
+	for i, value := range validValues {
+		revision.NewValidProofOutputs[i] = types.DxcoinCharge{Value: value, Address: current.NewValidProofOutputs[i].Address}
+	}
+	revision.NewMissedProofOutputs = make([]types.DxcoinCharge, len(missedValues))
+	for i, value := range missedValues {
+		revision.NewMissedProofOutputs[i] = types.DxcoinCharge{Value: value, Address: current.NewMissedProofOutputs[i].Address}
+	}
+	revision.Signatures = nil
+	return revision, nil
+}
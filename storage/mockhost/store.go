@@ -0,0 +1,78 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package mockhost
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/DxChainNetwork/godx/common"
+)
+
+var errSectorNotFound = errors.New("sector not found")
+
+// sectorStore stores the sectors uploaded by the merkle root
+type sectorStore interface {
+	put(root common.Hash, data []byte) error
+	get(root common.Hash) ([]byte, error)
+}
+
+// memoryStore keeps the sectors in memory, which is lost once the mock host stops
+type memoryStore struct {
+	sectors map[common.Hash][]byte
+	lock    sync.RWMutex
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{sectors: make(map[common.Hash][]byte)}
+}
+
+func (ms *memoryStore) put(root common.Hash, data []byte) error {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	ms.sectors[root] = append([]byte(nil), data...)
+	return nil
+}
+
+func (ms *memoryStore) get(root common.Hash) ([]byte, error) {
+	ms.lock.RLock()
+	defer ms.lock.RUnlock()
+	data, exist := ms.sectors[root]
+	if !exist {
+		return nil, errSectorNotFound
+	}
+	return data, nil
+}
+
+// dirStore keeps each sector in a file named by the merkle root under the directory
+type dirStore struct {
+	dir string
+}
+
+func newDirStore(dir string) (*dirStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &dirStore{dir: dir}, nil
+}
+
+func (ds *dirStore) put(root common.Hash, data []byte) error {
+	return ioutil.WriteFile(ds.path(root), data, 0600)
+}
+
+func (ds *dirStore) get(root common.Hash) ([]byte, error) {
+	data, err := ioutil.ReadFile(ds.path(root))
+	if os.IsNotExist(err) {
+		return nil, errSectorNotFound
+	}
+	return data, err
+}
+
+func (ds *dirStore) path(root common.Hash) string {
+	return filepath.Join(ds.dir, root.Hex()[2:])
+}
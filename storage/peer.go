@@ -6,6 +6,7 @@ package storage
 
 import (
 	"errors"
+	"time"
 
 	"github.com/DxChainNetwork/godx/p2p"
 	"github.com/DxChainNetwork/godx/p2p/enode"
@@ -33,6 +34,13 @@ type Peer interface {
 	SendUploadHostRevisionSign(revisionSign []byte) error
 	RequestContractDownload(req DownloadRequest) error
 	SendContractDownloadData(resp DownloadResponse) error
+	RequestContractAudit(req ContractAuditRequest) error
+	SendContractAuditState(state ContractAuditState) error
+	RequestSpeedTest(req SpeedTestRequest) error
+	SendSpeedTestResp(resp SpeedTestResponse) error
+	RequestPublishSectors(req PublishSectorsRequest) error
+	RequestPublicDownload(req PublicDownloadRequest) error
+	SendPublicDownloadData(resp DownloadResponse) error
 	SendHostBusyHandleRequestErr() error
 	SendClientNegotiateErrorMsg() error
 	SendClientCommitFailedMsg() error
@@ -50,4 +58,5 @@ type Peer interface {
 	RequestHostConfigDone()
 	PeerNode() *enode.Node
 	IsStaticConn() bool
+	StorageRTT() time.Duration
 }
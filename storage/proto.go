@@ -69,6 +69,40 @@ type (
 		Length     uint32
 	}
 
+	// ContractAuditRequest contains the request parameters for RPCContractAudit. The request
+	// is signed by the storage client of the contract
+	ContractAuditRequest struct {
+		StorageContractID common.Hash
+		Signature         []byte
+	}
+
+	// SpeedTestRequest contains the probe data sent to measure the upload speed of the
+	// storage host. The data is discarded by the storage host
+	SpeedTestRequest struct {
+		Data []byte
+	}
+
+	// SpeedTestResponse contains the number of probe bytes received by the storage host
+	SpeedTestResponse struct {
+		Received uint64
+	}
+
+	// PublishSectorsRequest contains the sectors of the storage contract published by the
+	// storage client, which the storage host serves to anyone without payment. The request
+	// is signed by the storage client of the contract
+	PublishSectorsRequest struct {
+		StorageContractID common.Hash
+		Roots             []common.Hash
+		Signature         []byte
+	}
+
+	// PublicDownloadRequest contains the request parameters for the download of a public
+	// sector. No storage contract or payment revision is needed
+	PublicDownloadRequest struct {
+		Sector      DownloadRequestSector
+		MerkleProof bool
+	}
+
 	// DownloadResponse contains the response data for RPCDownload.
 	DownloadResponse struct {
 		Signature   []byte
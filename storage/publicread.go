@@ -0,0 +1,55 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/rlp"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	// ErrPublishRequestNotSigned is the error returned when the publish sectors request carries
+	// no signature
+	ErrPublishRequestNotSigned = errors.New("publish sectors request is not signed")
+
+	// ErrInvalidPublishSignature is the error returned when the publish sectors request is not
+	// signed by the storage client of the contract
+	ErrInvalidPublishSignature = errors.New("publish sectors request is not signed by the storage client")
+)
+
+// SigHash returns the hash of the publish sectors request to be signed by the storage client.
+// The hash is prefixed with the type name, so that the signature could not be taken as the
+// signature of other messages
+func (req PublishSectorsRequest) SigHash() (h common.Hash) {
+	hw := sha3.NewLegacyKeccak256()
+	rlp.Encode(hw, []interface{}{
+		"PublishSectorsRequest",
+		req.StorageContractID,
+		req.Roots,
+	})
+	hw.Sum(h[:0])
+	return h
+}
+
+// VerifyPublishSectorsRequest checks that the publish sectors request is signed by the client
+// address of the storage contract
+func VerifyPublishSectorsRequest(req PublishSectorsRequest, client common.Address) error {
+	if len(req.Signature) == 0 {
+		return ErrPublishRequestNotSigned
+	}
+	pubKey, err := crypto.SigToPub(req.SigHash().Bytes(), req.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to recover the publish sectors request signer: %s", err.Error())
+	}
+	if crypto.PubkeyToAddress(*pubKey) != client {
+		return ErrInvalidPublishSignature
+	}
+	return nil
+}
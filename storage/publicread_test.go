@@ -0,0 +1,48 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+)
+
+func TestVerifyPublishSectorsRequest(t *testing.T) {
+	sk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := crypto.PubkeyToAddress(sk.PublicKey)
+
+	req := PublishSectorsRequest{
+		StorageContractID: common.HexToHash("0x1"),
+		Roots:             []common.Hash{common.HexToHash("0x2"), common.HexToHash("0x3")},
+	}
+	if err := VerifyPublishSectorsRequest(req, client); err != ErrPublishRequestNotSigned {
+		t.Fatalf("unsigned request: expect error %v, got %v", ErrPublishRequestNotSigned, err)
+	}
+	if req.Signature, err = crypto.Sign(req.SigHash().Bytes(), sk); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPublishSectorsRequest(req, client); err != nil {
+		t.Fatalf("signed request: %v", err)
+	}
+	if err := VerifyPublishSectorsRequest(req, crypto.PubkeyToAddress(otherSk.PublicKey)); err != ErrInvalidPublishSignature {
+		t.Fatalf("wrong client: expect error %v, got %v", ErrInvalidPublishSignature, err)
+	}
+
+	// publish one more sector with the same signature, the signature should not match anymore
+	tampered := req
+	tampered.Roots = append([]common.Hash{common.HexToHash("0x4")}, req.Roots...)
+	if err := VerifyPublishSectorsRequest(tampered, client); err == nil {
+		t.Fatal("tampered request passed the verification")
+	}
+}
@@ -7,6 +7,7 @@ package storage
 import (
 	"context"
 	"math/big"
+	"time"
 
 	"github.com/DxChainNetwork/godx/accounts"
 	"github.com/DxChainNetwork/godx/common"
@@ -57,7 +58,9 @@ type ClientBackend interface {
 	SuggestPrice(ctx context.Context) (*big.Int, error)
 	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
 	SendStorageContractCreateTx(clientAddr common.Address, input []byte) (common.Hash, error)
+	BumpStorageContractTx(from common.Address, txHash common.Hash, priceBump uint64, maxGasPrice *big.Int) (common.Hash, error)
 	GetHostAnnouncementWithBlockHash(blockHash common.Hash) (hostAnnouncements []types.HostAnnouncement, number uint64, errGet error)
+	GetHostRevocationWithBlockHash(blockHash common.Hash) ([]types.HostRevocation, error)
 	GetPaymentAddress() (common.Address, error)
 	TryToRenewOrRevise(hostID enode.ID) bool
 	RevisionOrRenewingDone(hostID enode.ID)
@@ -69,4 +72,30 @@ type ClientBackend interface {
 type DownloadParameters struct {
 	RemoteFilePath   string
 	WriteToLocalPath string
+
+	// Offset and Length specify the byte range of the file to download. If Length
+	// is zero, the file is downloaded from Offset to the end
+	Offset uint64
+	Length uint64
+
+	// Options tunes the cost and latency of the download. If nil, the default
+	// options are used
+	Options *DownloadOptions
+}
+
+// DownloadOptions is the knobs trading the cost for the latency of a download
+type DownloadOptions struct {
+	// LatencyTarget is the expected latency of the hosts. The hosts slower than the
+	// target are put standby, and the segment after the first is allowed 25ms more
+	LatencyTarget time.Duration
+
+	// Overdrive is the number of extra sectors downloaded for each segment beyond the
+	// minimum needed to recover it. The slowest hosts are not waited for, at the cost
+	// of more bandwidth paid and more memory used. The overdrive beyond the redundant
+	// sectors of the file has no effect
+	Overdrive int
+
+	// Priority decides the order the queued segments are downloaded. The higher the
+	// priority, the earlier the download. The repair downloads have priority 0
+	Priority uint64
 }
@@ -0,0 +1,67 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/chrono"
+)
+
+// ExportAccounting writes the spending of the storage client on all contracts, active, expired
+// and archived, to the file path in CSV or OFX, grouped by the period provided
+func (client *StorageClient) ExportAccounting(path, format, period string) error {
+	blocks, err := storage.ParseAccountingPeriod(period)
+	if err != nil {
+		return err
+	}
+	var account string
+	if address, err := client.GetPaymentAddress(); err == nil {
+		account = address.String()
+	}
+
+	contracts := append(client.contractManager.RetrieveActiveContracts(), client.contractManager.RetrieveInactiveContracts()...)
+	entries := spendingEntries(contracts, client.ethBackend.GetCurrentBlockHeight(), client.clock.Now())
+	return storage.ExportAccounting(path, format, account, entries, blocks)
+}
+
+// spendingEntries converts the costs of the contracts into the expense entries. The contract
+// fee and the gas are spent when the contract is formed, and the storage and bandwidth costs
+// are accounted up to the end of the contract or the current block height, whichever earlier
+func spendingEntries(contracts []storage.ContractMetaData, height uint64, now time.Time) (entries []storage.AccountingEntry) {
+	for _, contract := range contracts {
+		accrued := contract.EndHeight
+		if accrued > height {
+			accrued = height
+		}
+		costs := []struct {
+			category string
+			height   uint64
+			cost     common.BigInt
+		}{
+			{"contract fee", contract.StartHeight, contract.ContractFee},
+			{"gas", contract.StartHeight, contract.GasCost},
+			{"storage", accrued, contract.StorageCost},
+			{"upload bandwidth", accrued, contract.UploadCost},
+			{"download bandwidth", accrued, contract.DownloadCost},
+		}
+		for _, c := range costs {
+			if c.cost.Sign() == 0 {
+				continue
+			}
+			entries = append(entries, storage.AccountingEntry{
+				BlockHeight:  c.height,
+				Time:         chrono.HeightTime(now, height, c.height),
+				ContractID:   common.Hash(contract.ID),
+				Counterparty: contract.EnodeID.String(),
+				Category:     c.category,
+				Amount:       common.BigInt0.Sub(c.cost),
+			})
+		}
+	}
+	return
+}
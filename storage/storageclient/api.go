@@ -5,14 +5,23 @@
 package storageclient
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/DxChainNetwork/godx/accounts"
 	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/common/hexutil"
+	"github.com/DxChainNetwork/godx/common/unit"
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/contractmanager"
+	"github.com/DxChainNetwork/godx/storage/storageclient/contractset"
+	"github.com/DxChainNetwork/godx/storage/storageclient/coordinator"
+	"github.com/DxChainNetwork/godx/storage/storageclient/erasurecode"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
 	"github.com/DxChainNetwork/godx/storage/storageclient/storagehostmanager"
 )
 
@@ -24,6 +33,7 @@ type ActiveContractsAPIDisplay struct {
 	AbleToUpload bool
 	AbleToRenew  bool
 	Canceled     bool
+	Pending      bool
 }
 
 // PublicStorageClientAPI defines the object used to call eligible public APIs
@@ -99,10 +109,68 @@ func (api *PublicStorageClientAPI) Contract(contractID string) (detail ContractM
 
 	// format the contract meta data
 	detail = formatContractMetaData(contract)
+	pc, pending := api.sc.contractManager.RetrievePendingContract(convertContractID)
+	detail.Confirmation = formatConfirmation(pc, pending, api.sc.ethBackend.GetCurrentBlockHeight())
 
 	return
 }
 
+// ArchivedContracts will retrieve the contracts of the previous periods moved into the archive
+func (api *PublicStorageClientAPI) ArchivedContracts() (contracts []ContractMetaDataAPIDisplay) {
+	for _, contract := range api.sc.contractManager.RetrieveArchivedContracts() {
+		contracts = append(contracts, formatContractMetaData(contract))
+	}
+	return
+}
+
+// ArchivedContract will retrieve the complete record of the archived contract, including the
+// header, the latest revision and the roots. The private key of the contract is not returned
+func (api *PublicStorageClientAPI) ArchivedContract(contractID string) (contract contractset.ArchivedContract, err error) {
+	var convertContractID storage.ContractID
+	if convertContractID, err = storage.StringToContractID(contractID); err != nil {
+		err = fmt.Errorf("the contract id provided is invalid: %s", err.Error())
+		return
+	}
+	if contract, err = api.sc.contractManager.RetrieveArchivedContract(convertContractID); err != nil {
+		return
+	}
+	contract.Header.PrivateKey = ""
+	return
+}
+
+// ContractFundHistory will retrieve the fund consumption history of the contract, which can
+// be used to chart the burn-down and project when the contract fund will be exhausted
+func (api *PublicStorageClientAPI) ContractFundHistory(contractID string) (history ContractFundHistory, err error) {
+	var convertContractID storage.ContractID
+	if convertContractID, err = storage.StringToContractID(contractID); err != nil {
+		err = fmt.Errorf("the contract id provided is invalid: %s", err.Error())
+		return
+	}
+	return api.sc.ContractFundHistory(convertContractID)
+}
+
+// ContractAuditState will retrieve the client's view of the contract, including the latest
+// revision, the remaining funds, and the merkle roots
+func (api *PublicStorageClientAPI) ContractAuditState(contractID string) (state storage.ContractAuditState, err error) {
+	var convertContractID storage.ContractID
+	if convertContractID, err = storage.StringToContractID(contractID); err != nil {
+		err = fmt.Errorf("the contract id provided is invalid: %s", err.Error())
+		return
+	}
+	return api.sc.ContractAuditState(convertContractID)
+}
+
+// AuditContract will compare the client's view of the contract with the storage host's,
+// and return the structured difference
+func (api *PublicStorageClientAPI) AuditContract(ctx context.Context, contractID string) (diff storage.ContractAuditDiff, err error) {
+	var convertContractID storage.ContractID
+	if convertContractID, err = storage.StringToContractID(contractID); err != nil {
+		err = fmt.Errorf("the contract id provided is invalid: %s", err.Error())
+		return
+	}
+	return api.sc.AuditContract(ctx, convertContractID)
+}
+
 // PaymentAddress get the account address used to sign the storage contract. If not configured, the first address in the local wallet will be used as the paymentAddress by default.
 func (api *PublicStorageClientAPI) PaymentAddress() (common.Address, error) {
 	return api.sc.GetPaymentAddress()
@@ -110,7 +178,7 @@ func (api *PublicStorageClientAPI) PaymentAddress() (common.Address, error) {
 
 // DownloadSync is used to download remote file by sync mode
 // NOTE: RPC not support async download, because it is stateless, should block until download task done.
-func (api *PublicStorageClientAPI) DownloadSync(remoteFilePath, localPath string) (string, error) {
+func (api *PublicStorageClientAPI) DownloadSync(ctx context.Context, remoteFilePath, localPath string) (string, error) {
 	p := storage.DownloadParameters{
 		// where to write the downloaded files
 		WriteToLocalPath: localPath,
@@ -118,15 +186,76 @@ func (api *PublicStorageClientAPI) DownloadSync(remoteFilePath, localPath string
 		// where to download the remote file
 		RemoteFilePath: remoteFilePath,
 	}
-	err := api.sc.DownloadSync(p)
+	err := api.sc.DownloadSync(ctx, p)
 	if err != nil {
 		return "【ERROR】failed to download", err
 	}
 	return "File downloaded successfully", nil
 }
 
+// DownloadRange downloads the byte range of the remote file specified by the offset and
+// length to the local path. Only the segments covering the range are downloaded, so the
+// file headers or indexes could be fetched without downloading the whole file
+func (api *PublicStorageClientAPI) DownloadRange(ctx context.Context, remoteFilePath string, offset, length uint64, localPath string) (string, error) {
+	if length == 0 {
+		return "", errors.New("the length of the range must be positive")
+	}
+	p := storage.DownloadParameters{
+		WriteToLocalPath: localPath,
+		RemoteFilePath:   remoteFilePath,
+		Offset:           offset,
+		Length:           length,
+	}
+	if err := api.sc.DownloadSync(ctx, p); err != nil {
+		return "【ERROR】failed to download the range", err
+	}
+	return fmt.Sprintf("%v bytes downloaded successfully", length), nil
+}
+
+// DownloadWithOptions downloads the remote file to the local path with the options trading
+// the cost for the latency. The latencytarget option, like 5s, is the expected host latency,
+// and the slower hosts are put standby. The overdrive option is the number of extra sectors
+// downloaded per segment, so the slowest hosts are not waited for, at the cost of more
+// bandwidth and memory. The download with the higher priority option is served first, and
+// the repair downloads have priority 0. The offset and length options specify the byte range
+// to download. The options not specified take the default values
+func (api *PublicStorageClientAPI) DownloadWithOptions(ctx context.Context, remoteFilePath, localPath string, options map[string]string) (string, error) {
+	p := storage.DownloadParameters{
+		WriteToLocalPath: localPath,
+		RemoteFilePath:   remoteFilePath,
+	}
+	downloadOptions := DefaultDownloadOptions
+	for key, value := range options {
+		var err error
+		switch key {
+		case "latencytarget":
+			downloadOptions.LatencyTarget, err = time.ParseDuration(value)
+		case "overdrive":
+			var overdrive uint64
+			overdrive, err = unit.ParseUint64(value, 1, "")
+			downloadOptions.Overdrive = int(overdrive)
+		case "priority":
+			downloadOptions.Priority, err = unit.ParseUint64(value, 1, "")
+		case "offset":
+			p.Offset, err = unit.ParseUint64(value, 1, "")
+		case "length":
+			p.Length, err = unit.ParseUint64(value, 1, "")
+		default:
+			err = fmt.Errorf("unknown download option: %v", key)
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid download option %v: %v", key, err)
+		}
+	}
+	p.Options = &downloadOptions
+	if err := api.sc.DownloadSync(ctx, p); err != nil {
+		return "【ERROR】failed to download", err
+	}
+	return "File downloaded successfully", nil
+}
+
 // Upload their local files to hosts made contract with
-func (api *PublicStorageClientAPI) Upload(source string, dxPath string) (string, error) {
+func (api *PublicStorageClientAPI) Upload(ctx context.Context, source string, dxPath string) (string, error) {
 	path, err := storage.NewDxPath(dxPath)
 	if err != nil {
 		return "", err
@@ -136,10 +265,111 @@ func (api *PublicStorageClientAPI) Upload(source string, dxPath string) (string,
 		DxPath: path,
 		Mode:   storage.Override,
 	}
-	if err := api.sc.Upload(param); err != nil {
+	id, err := api.sc.startUpload(ctx, param)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("success, operation id: %v", id), nil
+}
+
+// UploadPublic uploads the local file unencrypted as the public content, whose sectors are
+// published to the hosts serving the public reads
+func (api *PublicStorageClientAPI) UploadPublic(ctx context.Context, source string, dxPath string) (string, error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return "", err
+	}
+	param := storage.FileUploadParams{
+		Source: source,
+		DxPath: path,
+		Mode:   storage.Override,
+		Public: true,
+	}
+	id, err := api.sc.startUpload(ctx, param)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("success, operation id: %v", id), nil
+}
+
+// UploadWithRedundancy uploads the local file with the redundancy given, where minSectors is the
+// number of sectors needed to recover a segment, and numSectors is the number of total sectors
+func (api *PublicStorageClientAPI) UploadWithRedundancy(ctx context.Context, source string, dxPath string, minSectors, numSectors uint32) (string, error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return "", err
+	}
+	ec, err := erasurecode.New(erasurecode.ECTypeStandard, minSectors, numSectors)
+	if err != nil {
+		return "", err
+	}
+	param := storage.FileUploadParams{
+		Source:      source,
+		DxPath:      path,
+		ErasureCode: ec,
+		Mode:        storage.Override,
+	}
+	id, err := api.sc.startUpload(ctx, param)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("success, operation id: %v", id), nil
+}
+
+// ReadPublicSector reads a section of the public sector from the storage host, without any
+// storage contract with the host
+func (api *PublicStorageClientAPI) ReadPublicSector(ctx context.Context, id string, root common.Hash, offset, length uint32) (hexutil.Bytes, error) {
+	var enodeID enode.ID
+	idSlice, err := hex.DecodeString(id)
+	if err != nil {
+		return nil, errors.New("the hostID provided is not valid")
+	}
+	copy(enodeID[:], idSlice)
+
+	return api.sc.ReadPublicSector(ctx, enodeID, root, offset, length)
+}
+
+// Append uploads the data appended to the local file of the uploaded file, without uploading
+// the whole file again. The source could be empty to use the local path of the file
+func (api *PublicStorageClientAPI) Append(ctx context.Context, source string, dxPath string) (string, error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
 		return "", err
 	}
-	return "success", nil
+	id, err := api.sc.AppendFile(ctx, path, source)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("success, operation id: %v", id), nil
+}
+
+// UploadStreamStart starts the upload stream to the dxPath, whose size is not known in
+// advance, and returns the stream id
+func (api *PublicStorageClientAPI) UploadStreamStart(dxPath string) (string, error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return "", err
+	}
+	return api.sc.StartUploadStream(path)
+}
+
+// UploadStreamWrite appends the chunk to the upload stream, and returns the size written so far
+func (api *PublicStorageClientAPI) UploadStreamWrite(id string, chunk hexutil.Bytes) (uint64, error) {
+	return api.sc.WriteUploadStream(id, chunk)
+}
+
+// UploadStreamFinish finishes the upload stream and starts to upload it, returning the size,
+// sha256 and merkle root of the data received
+func (api *PublicStorageClientAPI) UploadStreamFinish(ctx context.Context, id string) (UploadStreamReport, error) {
+	return api.sc.FinishUploadStream(ctx, id)
+}
+
+// UploadStreamAbort aborts the upload stream, and removes the data received
+func (api *PublicStorageClientAPI) UploadStreamAbort(id string) (string, error) {
+	if err := api.sc.AbortUploadStream(id); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("the upload stream %v is aborted", id), nil
 }
 
 // PrivateStorageClientAPI defines the object used to call eligible APIs
@@ -196,12 +426,515 @@ func (api *PrivateStorageClientAPI) SetPaymentAddress(addrStr string) bool {
 	return true
 }
 
+// PausedHosts will return the storage hosts that uploads are paused due to
+// abnormal contract revisions detected
+func (api *PrivateStorageClientAPI) PausedHosts() []PausedHost {
+	return api.sc.revisionMonitor.pausedHosts()
+}
+
+// SlowHosts will return the upload time statistics of the storage hosts, with the storage
+// hosts responsible for the tail latency of the uploads sorted first
+func (api *PrivateStorageClientAPI) SlowHosts() SlowHostReport {
+	return api.sc.uploadTimings.slowHostReport()
+}
+
+// WorkerPool will return the current worker pool size, which is the number of workers
+// transferring sectors at the same time, along with the resources it was tuned by
+func (api *PrivateStorageClientAPI) WorkerPool() WorkerPoolStatus {
+	return api.sc.workerPoolTuner.poolStatus()
+}
+
+// Bandwidth will return the upload and download speed limits shared by all workers, along with
+// the data transferred and the time throttled, broken down by the storage hosts
+func (api *PrivateStorageClientAPI) Bandwidth() BandwidthStats {
+	return api.sc.bandwidth.stats()
+}
+
+// SetBandwidthLimits will set the upload and download speed limits shared by all workers at
+// runtime, for example "1mbps". The speed limit 0bps means unlimited
+func (api *PrivateStorageClientAPI) SetBandwidthLimits(uploadSpeed, downloadSpeed string) (resp string, err error) {
+	maxUploadSpeed, err := unit.ParseSpeed(uploadSpeed)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the upload speed: %s", err.Error())
+	}
+	maxDownloadSpeed, err := unit.ParseSpeed(downloadSpeed)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the download speed: %s", err.Error())
+	}
+	err = api.sc.settings.update(func(s *Settings) error {
+		s.MaxUploadSpeed = maxUploadSpeed
+		s.MaxDownloadSpeed = maxDownloadSpeed
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	return fmt.Sprintf("Successfully set the upload speed limit to %v and the download speed limit to %v",
+		unit.FormatSpeed(maxUploadSpeed), unit.FormatSpeed(maxDownloadSpeed)), nil
+}
+
+// DownloadBudget will return the download spending of the current period against the download
+// budget, the downloads are stopped once the budget is exhausted
+func (api *PrivateStorageClientAPI) DownloadBudget() DownloadBudgetStatus {
+	return api.sc.DownloadBudgetStatus()
+}
+
+// ResumeHost will resume the uploads to the storage host that paused due to
+// abnormal contract revisions detected
+func (api *PrivateStorageClientAPI) ResumeHost(id string) (resp string, err error) {
+	var enodeID enode.ID
+	idSlice, err := hex.DecodeString(id)
+	if err != nil {
+		return "", errors.New("the hostID provided is not valid")
+	}
+	copy(enodeID[:], idSlice)
+
+	api.sc.revisionMonitor.resume(enodeID)
+	return fmt.Sprintf("Successfully resumed the uploads to the host %v", id), nil
+}
+
+// AddHost will add the storage host by its enode url without the host announcement on chain,
+// which is meant for the local testing against the mock hosts
+func (api *PrivateStorageClientAPI) AddHost(enodeURL string) (resp string, err error) {
+	if err = api.sc.storageHostManager.AddHost(enodeURL); err != nil {
+		return
+	}
+	return fmt.Sprintf("the storage host %s is added", enodeURL), nil
+}
+
+// HostSnapshot will create the snapshot of the storage host manager database, signed by
+// the payment address. It is requested by the nodes that trust the local node to bootstrap
+// their storage host manager
+func (api *PrivateStorageClientAPI) HostSnapshot() (storagehostmanager.HostDBSnapshot, error) {
+	return api.sc.storageHostManager.CreateSnapshot()
+}
+
+// ExportHostSnapshot will export the signed snapshot of the storage host manager database to the file
+func (api *PrivateStorageClientAPI) ExportHostSnapshot(path string) (resp string, err error) {
+	if err = api.sc.storageHostManager.ExportSnapshot(path); err != nil {
+		return
+	}
+	return fmt.Sprintf("Successfully exported the storage host snapshot to %v", path), nil
+}
+
+// ImportHostSnapshot will import the storage hosts from the snapshot file, which must be
+// signed by the trusted signer address
+func (api *PrivateStorageClientAPI) ImportHostSnapshot(path string, signer string) (resp string, err error) {
+	if !common.IsHexAddress(signer) {
+		return "", errors.New("the signer address provided is not valid")
+	}
+	imported, err := api.sc.storageHostManager.ImportSnapshotFile(path, common.HexToAddress(signer))
+	if err != nil {
+		return
+	}
+	return fmt.Sprintf("Successfully imported %v storage hosts from the snapshot", imported), nil
+}
+
+// SyncHostSnapshot will request the storage host snapshot from the trusted peer through its RPC
+// endpoint, and import the storage hosts from it. The snapshot must be signed by the trusted signer
+func (api *PrivateStorageClientAPI) SyncHostSnapshot(ctx context.Context, rpcURL string, signer string) (resp string, err error) {
+	if !common.IsHexAddress(signer) {
+		return "", errors.New("the signer address provided is not valid")
+	}
+	imported, err := api.sc.storageHostManager.SyncSnapshot(ctx, rpcURL, common.HexToAddress(signer))
+	if err != nil {
+		return
+	}
+	return fmt.Sprintf("Successfully synced %v storage hosts from %v", imported, rpcURL), nil
+}
+
+// HostScores will dump the scoring state of all storage hosts known, including the evaluation
+// details, which is meant for the offline analysis of the storage host selection
+func (api *PrivateStorageClientAPI) HostScores() storagehostmanager.HostScoringState {
+	return api.sc.storageHostManager.DumpScoringState()
+}
+
+// ExportHostScores will export the scoring state of all storage hosts known to the file
+func (api *PrivateStorageClientAPI) ExportHostScores(path string) (resp string, err error) {
+	if err = api.sc.storageHostManager.ExportScoringState(path); err != nil {
+		return
+	}
+	return fmt.Sprintf("Successfully exported the storage host scores to %v", path), nil
+}
+
+// ImportHostScores will import the scoring state from the file exported by another node of the
+// same operator. The scores of the storage hosts already known are replaced
+func (api *PrivateStorageClientAPI) ImportHostScores(path string) (resp string, err error) {
+	imported, err := api.sc.storageHostManager.ImportScoringStateFile(path)
+	if err != nil {
+		return
+	}
+	return fmt.Sprintf("Successfully imported the scores of %v storage hosts", imported), nil
+}
+
+// ExportProfile will export the complete storage client configuration to the file as a
+// profile signed by the payment address. The keys are not included in the profile
+func (api *PrivateStorageClientAPI) ExportProfile(path string) (resp string, err error) {
+	if err = api.sc.ExportProfile(path); err != nil {
+		return
+	}
+	return fmt.Sprintf("Successfully exported the storage client profile to %v", path), nil
+}
+
+// ImportProfile will apply the storage client configuration from the profile file, which
+// must be signed by the trusted signer address
+func (api *PrivateStorageClientAPI) ImportProfile(path string, signer string) (resp string, err error) {
+	if !common.IsHexAddress(signer) {
+		return "", errors.New("the signer address provided is not valid")
+	}
+	if err = api.sc.ImportProfileFile(path, common.HexToAddress(signer)); err != nil {
+		return
+	}
+	return fmt.Sprintf("Successfully imported the storage client profile from %v", path), nil
+}
+
+// ProfileDrift will return the fields of the storage client configuration that drift from
+// the profile file, which must be signed by the trusted signer address
+func (api *PrivateStorageClientAPI) ProfileDrift(path string, signer string) ([]ProfileDrift, error) {
+	if !common.IsHexAddress(signer) {
+		return nil, errors.New("the signer address provided is not valid")
+	}
+	return api.sc.ProfileDriftFile(path, common.HexToAddress(signer))
+}
+
+// PinFile will constrain the sectors of the file to the storage hosts specified, which are
+// given as either the host ids or the contract ids. The sectors stored on other hosts are
+// moved to the pinned hosts during repair, and reported as pin violations in the file info
+func (api *PrivateStorageClientAPI) PinFile(dxPath string, ids []string) (resp string, err error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return
+	}
+	hosts, err := api.sc.PinFile(path, ids)
+	if err != nil {
+		return
+	}
+	return fmt.Sprintf("File %v pinned to %v hosts", dxPath, len(hosts)), nil
+}
+
+// UnpinFile will remove the storage host constraint of the file
+func (api *PrivateStorageClientAPI) UnpinFile(dxPath string) (resp string, err error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return
+	}
+	if err = api.sc.UnpinFile(path); err != nil {
+		return
+	}
+	return fmt.Sprintf("File %v unpinned", dxPath), nil
+}
+
+// SetFilePriority will set the upload priority class of the file, which is one of high, normal
+// and background. The segments of the files in the higher class are uploaded and repaired first
+func (api *PrivateStorageClientAPI) SetFilePriority(dxPath string, priority string) (resp string, err error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return
+	}
+	class, err := dxfile.ParseUploadPriority(priority)
+	if err != nil {
+		return
+	}
+	if err = api.sc.SetFilePriority(path, class); err != nil {
+		return
+	}
+	return fmt.Sprintf("File %v upload priority set to %v", dxPath, class), nil
+}
+
+// SetFileRedundancy will change the number of total sectors of the uploaded file. The min sectors
+// of the file cannot be changed. The sectors added are uploaded by the repair loop
+func (api *PrivateStorageClientAPI) SetFileRedundancy(dxPath string, numSectors uint32) (resp string, err error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return
+	}
+	if err = api.sc.SetFileRedundancy(path, numSectors); err != nil {
+		return
+	}
+	return fmt.Sprintf("File %v number of total sectors set to %v", dxPath, numSectors), nil
+}
+
+// UploadReceipt will return the signed upload receipt of the file, which is issued once
+// the upload is completed
+func (api *PrivateStorageClientAPI) UploadReceipt(dxPath string) (UploadReceipt, error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return UploadReceipt{}, err
+	}
+	return api.sc.UploadReceipt(path)
+}
+
+// FileHistory will return the operation history of the file, including the uploads, repairs,
+// migrations and the contract renewals with the storage hosts storing the file
+func (api *PrivateStorageClientAPI) FileHistory(dxPath string) ([]FileEvent, error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return nil, err
+	}
+	return api.sc.FileHistory(path)
+}
+
+// ExportUploadReceipt will export the signed upload receipt of the file to the path
+func (api *PrivateStorageClientAPI) ExportUploadReceipt(dxPath string, path string) (resp string, err error) {
+	dp, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return
+	}
+	if err = api.sc.ExportUploadReceipt(dp, path); err != nil {
+		return
+	}
+	return fmt.Sprintf("Successfully exported the upload receipt to %v", path), nil
+}
+
+// VerifyUploadReceipt will verify the upload receipt file against its roots and signature,
+// and return the receipt if valid
+func (api *PrivateStorageClientAPI) VerifyUploadReceipt(path string) (UploadReceipt, error) {
+	return VerifyUploadReceiptFile(path)
+}
+
+// VerifyLocalCopy will verify that the file could be fully reconstructed from the storage hosts,
+// by checking the file health against the threshold and comparing a random segment downloaded
+// from the storage hosts with the local copy. If verified, the local copy is marked safe to
+// delete. The threshold is within [100, 200], and the default is used if zero
+func (api *PrivateStorageClientAPI) VerifyLocalCopy(ctx context.Context, dxPath string, threshold uint32) (LocalCopyVerification, error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return LocalCopyVerification{}, err
+	}
+	if threshold == 0 {
+		threshold = LocalCopyHealthThreshold
+	}
+	return api.sc.VerifyLocalCopy(ctx, path, threshold)
+}
+
+// DeleteLocalCopy will delete the local copy of the file, which must be verified safe to
+// delete by VerifyLocalCopy and not changed since
+func (api *PrivateStorageClientAPI) DeleteLocalCopy(dxPath string) (resp string, err error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return
+	}
+	if err = api.sc.DeleteLocalCopy(path); err != nil {
+		return
+	}
+	return fmt.Sprintf("The local copy of %v is deleted", dxPath), nil
+}
+
+// VerifyAndDeleteLocalCopy will verify the remote reconstructability of the file, and delete
+// the local copy only if verified, which is meant for the backup workflows. The threshold is
+// within [100, 200], and the default is used if zero
+func (api *PrivateStorageClientAPI) VerifyAndDeleteLocalCopy(ctx context.Context, dxPath string, threshold uint32) (LocalCopyVerification, error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return LocalCopyVerification{}, err
+	}
+	if threshold == 0 {
+		threshold = LocalCopyHealthThreshold
+	}
+	return api.sc.VerifyAndDeleteLocalCopy(ctx, path, threshold)
+}
+
+// Triage will pause the repairs after the mass loss of storage hosts, classify the files into
+// recoverable, at-risk and lost ones, and download the at-risk files into the recovery directory
+// before the repairs resume. The report lists the unrecoverable paths as lost
+func (api *PrivateStorageClientAPI) Triage(ctx context.Context, recoveryDir string) (TriageReport, error) {
+	return api.sc.Triage(ctx, recoveryDir)
+}
+
+// LastTriage will return the report of the last triage
+func (api *PrivateStorageClientAPI) LastTriage() (TriageReport, error) {
+	report, exist := api.sc.LastTriage()
+	if !exist {
+		return TriageReport{}, errors.New("no triage has been run")
+	}
+	return report, nil
+}
+
+// StartHostBackfill will start to scan the historical blocks for the storage host announcements,
+// continuing from the last checkpoint
+func (api *PrivateStorageClientAPI) StartHostBackfill() (HostBackfillProgress, error) {
+	return api.sc.StartHostBackfill()
+}
+
+// StopHostBackfill will stop the running host announcement backfill
+func (api *PrivateStorageClientAPI) StopHostBackfill() (resp string, err error) {
+	if err = api.sc.StopHostBackfill(); err != nil {
+		return
+	}
+	return "Successfully stopped the host announcement backfill", nil
+}
+
+// HostBackfillProgress will return the progress of the host announcement backfill
+func (api *PrivateStorageClientAPI) HostBackfillProgress() HostBackfillProgress {
+	return api.sc.HostBackfillProgress()
+}
+
+// CoordinationStatus will return the status of the experimental coordination mode, where
+// multiple nodes act as one logical storage client
+func (api *PrivateStorageClientAPI) CoordinationStatus() (coordinator.Status, error) {
+	return api.sc.CoordinationStatus()
+}
+
+// ExportReplicaSnapshot will export the replica snapshot and the file metadata to the
+// directory, which is synced by the read-only replicas
+func (api *PrivateStorageClientAPI) ExportReplicaSnapshot(dir string) (resp string, err error) {
+	if err = api.sc.ExportReplicaSnapshot(dir); err != nil {
+		return
+	}
+	return fmt.Sprintf("Successfully exported the replica snapshot to %v", dir), nil
+}
+
+// ReplicaStatus will return the sync status of the read-only replica
+func (api *PrivateStorageClientAPI) ReplicaStatus() (ReplicaStatus, error) {
+	return api.sc.ReplicaStatus()
+}
+
+// Operations will return the status of the uploads and downloads in flight, and the most
+// recent finished ones
+func (api *PrivateStorageClientAPI) Operations() []OperationStatus {
+	return api.sc.Operations()
+}
+
+// CancelOperation will cancel the upload or download in flight with the operation id, and
+// return its final status
+func (api *PrivateStorageClientAPI) CancelOperation(id string) (OperationStatus, error) {
+	return api.sc.CancelOperation(id)
+}
+
 // PeriodCost will get the client's period cost which specifies cost that storage
 // client needs to pay within one period cycle. It includes cost for all contracts
 func (api *PrivateStorageClientAPI) PeriodCost() storage.PeriodCost {
 	return api.sc.contractManager.RetrievePeriodCost()
 }
 
+// ExportAccounting will export the spending of the storage client on all contracts to the
+// file path in the format csv or ofx. The entries of the same category are summed up within
+// each period, which is one of none, day, week, month and year
+func (api *PrivateStorageClientAPI) ExportAccounting(path string, format string, period string) (resp string, err error) {
+	if err = api.sc.ExportAccounting(path, format, period); err != nil {
+		return
+	}
+	return fmt.Sprintf("successfully exported the accounting to %v", path), nil
+}
+
+// SetFileLimits will set the maximum number of files stored by the storage client, and the
+// maximum number of file metadata kept in memory. Zero maxFiles means unlimited, and zero
+// maxCachedFiles loads the file metadata on demand only
+func (api *PrivateStorageClientAPI) SetFileLimits(maxFiles uint64, maxCachedFiles int) (resp string, err error) {
+	if err = api.sc.SetFileLimits(maxFiles, maxCachedFiles); err != nil {
+		return
+	}
+	return fmt.Sprintf("the max files is set to %v, and the max cached files is set to %v", maxFiles, maxCachedFiles), nil
+}
+
+// SetContractCreateRetryBudget will set the maximum number of failed contract formations
+// allowed in one contract maintenance run
+func (api *PrivateStorageClientAPI) SetContractCreateRetryBudget(budget int) (resp string, err error) {
+	if err = api.sc.contractManager.SetCreateRetryBudget(budget); err != nil {
+		return
+	}
+	return fmt.Sprintf("contract create retry budget is set to %v", budget), nil
+}
+
+// MaintenanceResult will return the contract formation result of the latest contract
+// maintenance run, including the failure of each storage host tried
+func (api *PrivateStorageClientAPI) MaintenanceResult() contractmanager.MaintenanceResult {
+	return api.sc.contractManager.RetrieveMaintenanceResult()
+}
+
+// SetEscrowTreasury will set the treasury account funding the client collateral of the
+// storage contracts through the escrow account. The empty treasury pays the client collateral
+// from the payment address again
+func (api *PrivateStorageClientAPI) SetEscrowTreasury(treasury string) (resp string, err error) {
+	var treasuryAddr common.Address
+	if treasury != "" {
+		if !common.IsHexAddress(treasury) {
+			return "", fmt.Errorf("invalid treasury address: %s", treasury)
+		}
+		treasuryAddr = common.HexToAddress(treasury)
+	}
+	if err = api.sc.contractManager.SetEscrowTreasury(treasuryAddr); err != nil {
+		return
+	}
+	if treasuryAddr == (common.Address{}) {
+		return "the client collateral is paid by the payment address", nil
+	}
+	return fmt.Sprintf("the client collateral is paid by the escrow account funded by the treasury %s", treasuryAddr.String()), nil
+}
+
+// SetMaxTxGasPrice will set the gas price ceiling of the contract create transaction. The
+// transaction not mined in time is replaced with a higher fee under the ceiling
+func (api *PrivateStorageClientAPI) SetMaxTxGasPrice(price string) (resp string, err error) {
+	maxGasPrice, err := unit.ParseCurrency(price)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the max tx gas price: %s", err.Error())
+	}
+	if err = api.sc.contractManager.SetMaxTxGasPrice(maxGasPrice); err != nil {
+		return
+	}
+	return fmt.Sprintf("the max tx gas price is set to %s", unit.FormatCurrency(maxGasPrice)), nil
+}
+
+// PendingTxs will return the contract create transactions sent and not mined yet, along
+// with the number of times the fee is bumped
+func (api *PrivateStorageClientAPI) PendingTxs() []storage.PendingStorageTx {
+	return api.sc.contractManager.RetrievePendingTxs()
+}
+
+// SetBackup will set the target the encrypted contract set and file metadata are shipped to
+// every interval, such as scp://user@host/backup, s3://bucket/prefix or a WebDAV url. Empty
+// target disables the backup
+func (api *PrivateStorageClientAPI) SetBackup(target string, passphrase string, interval string) (resp string, err error) {
+	var duration time.Duration
+	if target != "" {
+		if duration, err = time.ParseDuration(interval); err != nil {
+			return "", fmt.Errorf("invalid backup interval %v: %s", interval, err.Error())
+		}
+	}
+	if err = api.sc.SetBackup(target, passphrase, duration); err != nil {
+		return
+	}
+	if target == "" {
+		return "the metadata backup is disabled", nil
+	}
+	return fmt.Sprintf("the metadata backup is shipped to %v every %v", redactBackupTarget(target), duration), nil
+}
+
+// Backup will return the status of the metadata backup
+func (api *PrivateStorageClientAPI) Backup() BackupStatus {
+	return api.sc.BackupStatus()
+}
+
+// BackupNow will ship the metadata backup to the target at once
+func (api *PrivateStorageClientAPI) BackupNow() (BackupManifest, error) {
+	return api.sc.BackupNow()
+}
+
+// RestoreBackup will restore the metadata backup with the name from the target, the latest
+// backup if the name is empty
+func (api *PrivateStorageClientAPI) RestoreBackup(target string, name string, passphrase string) (BackupManifest, error) {
+	return api.sc.RestoreBackup(target, name, passphrase)
+}
+
+// SetFaucet will set the faucet endpoint the funds are requested from by the onboarding on the
+// test networks. Empty faucet removes the faucet configured
+func (api *PrivateStorageClientAPI) SetFaucet(faucet string) (resp string, err error) {
+	if err = api.sc.SetFaucet(faucet); err != nil {
+		return
+	}
+	if faucet == "" {
+		return "the faucet is removed", nil
+	}
+	return fmt.Sprintf("the faucet is set to %v", faucet), nil
+}
+
+// Onboard will set up the storage client for the first use on the test networks. The funds are
+// requested from the faucet if needed, and the default rent payment is set once the funds are
+// confirmed, which starts the contract formation
+func (api *PrivateStorageClientAPI) Onboard(ctx context.Context) (OnboardResult, error) {
+	return api.sc.Onboard(ctx)
+}
+
 // CancelAllContracts will cancel all contracts signed with storage client by
 // marking all active contracts as canceled, not good for uploading, and not good
 // for renewing
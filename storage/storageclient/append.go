@@ -0,0 +1,100 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
+)
+
+var (
+	errAppendUploading = errors.New("the file is being uploaded, append after the upload is finished")
+	errAppendNoSource  = errors.New("no local file to append from")
+)
+
+// AppendFile appends the data to the end of the uploaded file without uploading the whole file
+// again, e.g. archiving a log file being written. The source is the local file grown from the
+// file uploaded, whose data before the current file size must be unchanged. If the source is
+// empty, the local path of the file is used. Only the segments of the data appended are uploaded,
+// along with the last segment of the file if it was not full, and the contract revisions are made
+// for these sectors only. The id of the upload operation is returned
+func (client *StorageClient) AppendFile(ctx context.Context, dxPath storage.DxPath, source string) (string, error) {
+	if err := client.tm.Add(); err != nil {
+		return "", err
+	}
+	defer client.tm.Done()
+
+	if client.operations.runningUpload(dxPath.Path) != nil {
+		return "", errAppendUploading
+	}
+	entry, err := client.fileSystem.OpenDxFile(dxPath)
+	if err != nil {
+		return "", err
+	}
+	defer entry.Close()
+
+	if source == "" {
+		source = string(entry.LocalPath())
+	}
+	if source == "" {
+		return "", errAppendNoSource
+	}
+	sourceInfo, err := os.Stat(source)
+	if err != nil {
+		return "", fmt.Errorf("unable to stat the source file, error: %v", err)
+	}
+	if sourceInfo.IsDir() {
+		return "", fmt.Errorf("the source %v is a directory", source)
+	}
+	if uint64(sourceInfo.Size()) <= entry.FileSize() {
+		return "", fmt.Errorf("nothing to append, the source file size %v is not larger than the file size %v", sourceInfo.Size(), entry.FileSize())
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	// the repair reads the data of the segments from the local path
+	if storage.SysPath(source) != entry.LocalPath() {
+		if err := entry.SetLocalPath(storage.SysPath(source)); err != nil {
+			return "", err
+		}
+	}
+	indexes, err := entry.Append(uint64(sourceInfo.Size()))
+	if err != nil {
+		return "", fmt.Errorf("could not append to the dx file, error: %v", err)
+	}
+	client.log.Info("appending to the file", "dxpath", dxPath.Path, "size", sourceInfo.Size(), "segments", len(indexes))
+	client.setFileChecksum(entry, source)
+	go client.fileSystem.InitAndUpdateDirMetadata(dxPath)
+
+	// the receipt is issued again covering the whole file
+	op := client.operations.add(OperationUpload, dxPath.Path)
+	op.setComplete(func(status OperationStatus) {
+		go func() {
+			if _, err := client.issueUploadReceipt(dxPath, status); err != nil {
+				client.log.Warn("failed to issue the upload receipt", "dxpath", dxPath.Path, "err", err)
+			}
+			client.recordFileEvent(dxPath, FileEvent{Type: FileEventUpload})
+		}()
+	})
+
+	hosts := client.refreshHostsAndWorkers()
+	if err := client.createAndPushSegments([]*dxfile.FileSetEntryWithID{entry}, hosts, targetUnstuckSegments, make(storage.HostHealthInfoTable)); err != nil {
+		op.finish(err)
+		return "", err
+	}
+	op.markSegmentsCreated()
+
+	select {
+	case client.uploadHeap.segmentComing <- struct{}{}:
+	default:
+	}
+	return op.status.ID, nil
+}
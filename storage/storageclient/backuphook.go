@@ -0,0 +1,302 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// BackupHook ships the backup objects to the storage off the node, and fetches them back
+type BackupHook interface {
+	// Put stores the object read from r with the name
+	Put(name string, r io.Reader) error
+
+	// Get writes the object with the name to w
+	Get(name string, w io.Writer) error
+}
+
+// BackupHookFactory creates the backup hook for the target url
+type BackupHookFactory func(target *url.URL) (BackupHook, error)
+
+var (
+	// backupHooks is the mapping from the url scheme of the backup target to the factory
+	backupHooks = map[string]BackupHookFactory{
+		"file":  newFileBackupHook,
+		"http":  newWebDAVBackupHook,
+		"https": newWebDAVBackupHook,
+		"scp":   newSCPBackupHook,
+		"s3":    newS3BackupHook,
+	}
+	backupHooksLock sync.RWMutex
+)
+
+// RegisterBackupHook registers the backup hook factory for the url scheme, which replaces
+// the factory registered before
+func RegisterBackupHook(scheme string, factory BackupHookFactory) {
+	backupHooksLock.Lock()
+	defer backupHooksLock.Unlock()
+	backupHooks[strings.ToLower(scheme)] = factory
+}
+
+// newBackupHook creates the backup hook for the backup target url. The target without the
+// scheme is a local directory
+func newBackupHook(target string) (BackupHook, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup target %v: %s", target, err.Error())
+	}
+	if u.Scheme == "" {
+		u = &url.URL{Scheme: "file", Path: target}
+	}
+
+	backupHooksLock.RLock()
+	factory, exists := backupHooks[strings.ToLower(u.Scheme)]
+	backupHooksLock.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unsupported backup target scheme %v", u.Scheme)
+	}
+	return factory(u)
+}
+
+// checkBackupName checks that the name is a backup object name created by BackupNow, in
+// the form of backup-*.dxbk. The name is used in the local and remote paths, so the name
+// with any path element is rejected
+func checkBackupName(name string) error {
+	if filepath.Base(name) != name || path.Base(name) != name || strings.Contains(name, "..") ||
+		!strings.HasPrefix(name, "backup-") || !strings.HasSuffix(name, backupExt) {
+		return fmt.Errorf("invalid backup name %v", name)
+	}
+	return nil
+}
+
+// checkBackupObjectName checks that the name is a backup object or its manifest, which
+// are the only objects the backup hooks store and fetch
+func checkBackupObjectName(name string) error {
+	if name == latestBackupManifest {
+		return nil
+	}
+	return checkBackupName(strings.TrimSuffix(name, backupManifestExt))
+}
+
+// fileBackupHook stores the backup objects in a local directory, such as a mounted network drive
+type fileBackupHook struct {
+	dir string
+}
+
+func newFileBackupHook(target *url.URL) (BackupHook, error) {
+	if target.Path == "" {
+		return nil, fmt.Errorf("empty backup directory")
+	}
+	if err := os.MkdirAll(target.Path, 0700); err != nil {
+		return nil, err
+	}
+	return &fileBackupHook{dir: target.Path}, nil
+}
+
+// Put writes the object to a temporary file and renames it, so that the object is never
+// partially written
+func (fh *fileBackupHook) Put(name string, r io.Reader) error {
+	if err := checkBackupObjectName(name); err != nil {
+		return err
+	}
+	target := filepath.Join(fh.dir, name)
+	tmp := filepath.Join(fh.dir, backupTempFile)
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, target)
+}
+
+// Get copies the object file to w
+func (fh *fileBackupHook) Get(name string, w io.Writer) error {
+	if err := checkBackupObjectName(name); err != nil {
+		return err
+	}
+	f, err := os.Open(filepath.Join(fh.dir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// webDAVBackupHook stores the backup objects in a WebDAV collection with PUT and GET. The
+// user info of the url is used as the basic auth
+type webDAVBackupHook struct {
+	base   url.URL
+	client *http.Client
+}
+
+func newWebDAVBackupHook(target *url.URL) (BackupHook, error) {
+	return &webDAVBackupHook{
+		base:   *target,
+		client: &http.Client{Timeout: BackupTransferTimeout},
+	}, nil
+}
+
+// Put uploads the object with the HTTP PUT request
+func (wh *webDAVBackupHook) Put(name string, r io.Reader) error {
+	resp, err := wh.do(http.MethodPut, name, r)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get downloads the object with the HTTP GET request
+func (wh *webDAVBackupHook) Get(name string, w io.Writer) error {
+	resp, err := wh.do(http.MethodGet, name, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// do sends the request for the object, and returns the error if the status is not 2xx
+func (wh *webDAVBackupHook) do(method, name string, body io.Reader) (*http.Response, error) {
+	if err := checkBackupObjectName(name); err != nil {
+		return nil, err
+	}
+	u := wh.base
+	u.User = nil
+	u.Path = path.Join(u.Path, name)
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if wh.base.User != nil {
+		password, _ := wh.base.User.Password()
+		req.SetBasicAuth(wh.base.User.Username(), password)
+	}
+	resp, err := wh.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%v %v failed: %v", method, u.String(), resp.Status)
+	}
+	return resp, nil
+}
+
+// commandBackupHook transfers the backup objects with an external command, such as scp or
+// the aws cli. The object is staged in a temporary file, whose path is passed to the command
+type commandBackupHook struct {
+	put func(local, name string) *exec.Cmd
+	get func(local, name string) *exec.Cmd
+}
+
+// newSCPBackupHook creates the hook for the target like scp://user@host/path, with the ssh
+// keys of the node user
+func newSCPBackupHook(target *url.URL) (BackupHook, error) {
+	host := target.Host
+	if target.User != nil {
+		host = target.User.Username() + "@" + host
+	}
+	remote := func(name string) string {
+		return fmt.Sprintf("%s:%s", host, path.Join(target.Path, name))
+	}
+	return &commandBackupHook{
+		put: func(local, name string) *exec.Cmd {
+			return exec.Command("scp", "-q", "-B", local, remote(name))
+		},
+		get: func(local, name string) *exec.Cmd {
+			return exec.Command("scp", "-q", "-B", remote(name), local)
+		},
+	}, nil
+}
+
+// newS3BackupHook creates the hook for the target like s3://bucket/prefix, with the aws cli
+// and its credentials configured for the node user
+func newS3BackupHook(target *url.URL) (BackupHook, error) {
+	remote := func(name string) string {
+		return fmt.Sprintf("s3://%s/%s", target.Host, strings.TrimPrefix(path.Join(target.Path, name), "/"))
+	}
+	return &commandBackupHook{
+		put: func(local, name string) *exec.Cmd {
+			return exec.Command("aws", "s3", "cp", "--only-show-errors", local, remote(name))
+		},
+		get: func(local, name string) *exec.Cmd {
+			return exec.Command("aws", "s3", "cp", "--only-show-errors", remote(name), local)
+		},
+	}, nil
+}
+
+// Put stages the object in a temporary file and runs the put command
+func (ch *commandBackupHook) Put(name string, r io.Reader) error {
+	if err := checkBackupObjectName(name); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile("", "dxbackup")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return runBackupCommand(ch.put(f.Name(), name))
+}
+
+// Get runs the get command to a temporary file, and copies it to w
+func (ch *commandBackupHook) Get(name string, w io.Writer) error {
+	if err := checkBackupObjectName(name); err != nil {
+		return err
+	}
+	dir, err := ioutil.TempDir("", "dxbackup")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	local := filepath.Join(dir, backupTempFile)
+	if err = runBackupCommand(ch.get(local, name)); err != nil {
+		return err
+	}
+	f, err := os.Open(local)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// runBackupCommand runs the command, and returns the error along with the command output
+func runBackupCommand(cmd *exec.Cmd) error {
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v failed: %s: %s", cmd.Args[0], err.Error(), strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage/chrono"
+)
+
+// errBandwidthWaitCanceled is the error that the transfer is canceled while waiting for the
+// bandwidth limit
+var errBandwidthWaitCanceled = errors.New("the transfer is canceled while waiting for the bandwidth limit")
+
+// bandwidthLimiter limits the upload and the download speed of all workers with a token bucket
+// for each direction, and records the data transferred broken down by the storage hosts. The
+// bucket holds at most a second of tokens. The transfer larger than the tokens available takes
+// the tokens in advance, and waits until the bucket is refilled, so that the sectors larger than
+// the speed limit could still be transferred
+type bandwidthLimiter struct {
+	upload   bandwidthBucket
+	download bandwidthBucket
+	hosts    map[enode.ID]*HostBandwidth
+	clock    chrono.Clock
+	lock     sync.Mutex
+}
+
+// bandwidthBucket is the token bucket of a transfer direction, with the rate in bytes per
+// second. The rate 0 means unlimited
+type bandwidthBucket struct {
+	rate   int64
+	tokens float64
+	last   time.Time
+}
+
+// HostBandwidth is the data transferred with a storage host, and the time the transfers
+// waited for the bandwidth limit
+type HostBandwidth struct {
+	HostID            enode.ID      `json:"hostid"`
+	Uploaded          uint64        `json:"uploaded"`
+	Downloaded        uint64        `json:"downloaded"`
+	UploadThrottled   time.Duration `json:"uploadthrottled"`
+	DownloadThrottled time.Duration `json:"downloadthrottled"`
+}
+
+// BandwidthStats is the bandwidth limits and the data transferred with all storage hosts, with
+// the storage hosts transferred the most data sorted first
+type BandwidthStats struct {
+	MaxUploadSpeed   int64           `json:"maxuploadspeed"`
+	MaxDownloadSpeed int64           `json:"maxdownloadspeed"`
+	Total            HostBandwidth   `json:"total"`
+	Hosts            []HostBandwidth `json:"hosts"`
+}
+
+// newBandwidthLimiter creates a bandwidth limiter without limits
+func newBandwidthLimiter(clock chrono.Clock) *bandwidthLimiter {
+	return &bandwidthLimiter{
+		hosts: make(map[enode.ID]*HostBandwidth),
+		clock: clock,
+	}
+}
+
+// setLimits sets the download and the upload speed limits in bytes per second, where 0 means
+// unlimited. The buckets start full with the new limits
+func (bl *bandwidthLimiter) setLimits(downloadSpeedLimit, uploadSpeedLimit int64) {
+	bl.lock.Lock()
+	defer bl.lock.Unlock()
+
+	now := bl.clock.Now()
+	bl.download = bandwidthBucket{rate: downloadSpeedLimit, tokens: float64(downloadSpeedLimit), last: now}
+	bl.upload = bandwidthBucket{rate: uploadSpeedLimit, tokens: float64(uploadSpeedLimit), last: now}
+}
+
+// waitUpload waits until the data of the size could be uploaded to the storage host within the
+// upload speed limit
+func (bl *bandwidthLimiter) waitUpload(hostID enode.ID, size uint64, cancel <-chan struct{}) error {
+	return bl.wait(hostID, size, true, cancel)
+}
+
+// waitDownload waits until the data of the size could be downloaded from the storage host
+// within the download speed limit
+func (bl *bandwidthLimiter) waitDownload(hostID enode.ID, size uint64, cancel <-chan struct{}) error {
+	return bl.wait(hostID, size, false, cancel)
+}
+
+// wait takes the tokens of the size from the bucket of the direction, and waits until the
+// bucket is refilled if the tokens are taken in advance. The tokens are returned if canceled
+func (bl *bandwidthLimiter) wait(hostID enode.ID, size uint64, upload bool, cancel <-chan struct{}) error {
+	bl.lock.Lock()
+	bucket := &bl.download
+	if upload {
+		bucket = &bl.upload
+	}
+	delay := bucket.take(bl.clock.Now(), size)
+	host := bl.host(hostID)
+	if upload {
+		host.Uploaded += size
+		host.UploadThrottled += delay
+	} else {
+		host.Downloaded += size
+		host.DownloadThrottled += delay
+	}
+	bl.lock.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-bl.clock.After(delay):
+		return nil
+	case <-cancel:
+	}
+
+	bl.lock.Lock()
+	defer bl.lock.Unlock()
+	bucket.tokens += float64(size)
+	if upload {
+		host.Uploaded -= size
+	} else {
+		host.Downloaded -= size
+	}
+	return errBandwidthWaitCanceled
+}
+
+// host returns the bandwidth record of the storage host, created if not exists
+//
+// NOTE: bl.lock should be held when calling this function
+func (bl *bandwidthLimiter) host(hostID enode.ID) *HostBandwidth {
+	host, exists := bl.hosts[hostID]
+	if !exists {
+		host = &HostBandwidth{HostID: hostID}
+		bl.hosts[hostID] = host
+	}
+	return host
+}
+
+// stats returns the bandwidth limits and the data transferred with the storage hosts
+func (bl *bandwidthLimiter) stats() BandwidthStats {
+	bl.lock.Lock()
+	defer bl.lock.Unlock()
+
+	stats := BandwidthStats{
+		MaxUploadSpeed:   bl.upload.rate,
+		MaxDownloadSpeed: bl.download.rate,
+		Hosts:            make([]HostBandwidth, 0, len(bl.hosts)),
+	}
+	for _, host := range bl.hosts {
+		stats.Hosts = append(stats.Hosts, *host)
+		stats.Total.Uploaded += host.Uploaded
+		stats.Total.Downloaded += host.Downloaded
+		stats.Total.UploadThrottled += host.UploadThrottled
+		stats.Total.DownloadThrottled += host.DownloadThrottled
+	}
+	sort.Slice(stats.Hosts, func(i, j int) bool {
+		return stats.Hosts[i].Uploaded+stats.Hosts[i].Downloaded > stats.Hosts[j].Uploaded+stats.Hosts[j].Downloaded
+	})
+	return stats
+}
+
+// take refills the bucket at the rate up to a second of tokens, takes the tokens of the size,
+// and returns the time to wait until the tokens taken in advance are refilled
+func (b *bandwidthBucket) take(now time.Time, size uint64) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * float64(b.rate)
+		b.last = now
+	}
+	if b.tokens > float64(b.rate) {
+		b.tokens = float64(b.rate)
+	}
+	b.tokens -= float64(size)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / float64(b.rate) * float64(time.Second))
+}
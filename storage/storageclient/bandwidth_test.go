@@ -0,0 +1,88 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage/chrono"
+)
+
+func TestBandwidthBucket_Take(t *testing.T) {
+	now := time.Now()
+	b := bandwidthBucket{rate: 100, tokens: 100, last: now}
+
+	if delay := b.take(now, 60); delay != 0 {
+		t.Errorf("the transfer within the tokens shall not wait, got %v", delay)
+	}
+	// the transfer larger than the tokens left takes the tokens in advance
+	if delay := b.take(now, 90); delay != 500*time.Millisecond {
+		t.Errorf("expect delay %v, got %v", 500*time.Millisecond, delay)
+	}
+	// the tokens are refilled at the rate, and capped at a second of tokens
+	if delay := b.take(now.Add(10*time.Second), 100); delay != 0 {
+		t.Errorf("the transfer within the refilled tokens shall not wait, got %v", delay)
+	}
+	if delay := b.take(now.Add(10*time.Second), 1); delay != 10*time.Millisecond {
+		t.Errorf("expect delay %v, got %v", 10*time.Millisecond, delay)
+	}
+
+	unlimited := bandwidthBucket{}
+	if delay := unlimited.take(now, 1<<30); delay != 0 {
+		t.Errorf("the unlimited bucket shall not wait, got %v", delay)
+	}
+}
+
+func TestBandwidthLimiter_Wait(t *testing.T) {
+	clock := chrono.NewFakeClock(time.Now())
+	bl := newBandwidthLimiter(clock)
+	bl.setLimits(0, 100)
+	fast, slow := enode.ID{1}, enode.ID{2}
+
+	if err := bl.waitUpload(fast, 100, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := bl.waitDownload(fast, 1000, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the upload waits until the bucket shared by all hosts is refilled
+	done := make(chan error, 1)
+	go func() { done <- bl.waitUpload(slow, 200, nil) }()
+	for waiting := true; waiting; {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+			waiting = false
+		case <-time.After(time.Millisecond):
+			clock.Advance(100 * time.Millisecond)
+		}
+	}
+
+	// the upload canceled returns the tokens taken
+	cancel := make(chan struct{})
+	close(cancel)
+	if err := bl.waitUpload(slow, 300, cancel); err != errBandwidthWaitCanceled {
+		t.Fatalf("expect error %v, got %v", errBandwidthWaitCanceled, err)
+	}
+
+	stats := bl.stats()
+	if stats.MaxUploadSpeed != 100 || stats.MaxDownloadSpeed != 0 {
+		t.Errorf("unexpected speed limits %v, %v", stats.MaxUploadSpeed, stats.MaxDownloadSpeed)
+	}
+	if stats.Total.Uploaded != 300 || stats.Total.Downloaded != 1000 {
+		t.Errorf("unexpected total transferred %+v", stats.Total)
+	}
+	if len(stats.Hosts) != 2 || stats.Hosts[0].HostID != fast || stats.Hosts[1].HostID != slow {
+		t.Fatalf("unexpected host breakdown %+v", stats.Hosts)
+	}
+	if stats.Hosts[0].UploadThrottled != 0 || stats.Hosts[1].UploadThrottled == 0 {
+		t.Errorf("unexpected throttled time %+v", stats.Hosts)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Checksum verification results of the downloads
+const (
+	ChecksumVerified    = "verified"
+	ChecksumMismatch    = "mismatch"
+	ChecksumUnavailable = "unavailable"
+	ChecksumSkipped     = "skipped"
+)
+
+var errChecksumMismatch = errors.New("the checksum of the downloaded file does not match the file uploaded")
+
+// fileChecksum returns the BLAKE2b-256 hash of the file content
+func fileChecksum(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h, err := blake2b.New256(nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// setFileChecksum computes the hash of the source file, and stores it in the DxFile to verify the
+// downloads. The checksum is optional, so the file is uploaded without it if the hash fails
+func (client *StorageClient) setFileChecksum(entry *dxfile.FileSetEntryWithID, source string) {
+	checksum, err := fileChecksum(source)
+	if err == nil {
+		err = entry.SetChecksum(checksum)
+	}
+	if err != nil {
+		client.log.Warn("failed to set the file checksum", "dxpath", entry.DxPath().Path, "err", err)
+		entry.SetChecksum(nil)
+	}
+}
+
+// verifyDownloadChecksum verifies the file downloaded to the path against the checksum computed
+// at upload, and records the result in the download operation. Only the download of the whole
+// file is verified
+func verifyDownloadChecksum(op *operation, path string, checksum []byte, wholeFile bool) error {
+	if !wholeFile {
+		op.setVerification(ChecksumSkipped)
+		return nil
+	}
+	if len(checksum) == 0 {
+		op.setVerification(ChecksumUnavailable)
+		return nil
+	}
+	downloaded, err := fileChecksum(path)
+	if err != nil {
+		return fmt.Errorf("failed to hash the downloaded file: %v", err)
+	}
+	if !bytes.Equal(downloaded, checksum) {
+		op.setVerification(ChecksumMismatch)
+		return errChecksumMismatch
+	}
+	op.setVerification(ChecksumVerified)
+	return nil
+}
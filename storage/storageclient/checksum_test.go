@@ -0,0 +1,57 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyDownloadChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checksum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "file")
+	if err = ioutil.WriteFile(path, []byte("uploaded file"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checksum) != 32 {
+		t.Fatalf("expect checksum of 32 bytes, got %x", checksum)
+	}
+
+	ops := newOperationSet()
+	tests := []struct {
+		data      string
+		checksum  []byte
+		wholeFile bool
+		expect    string
+		err       error
+	}{
+		{"uploaded file", checksum, true, ChecksumVerified, nil},
+		{"corrupted file", checksum, true, ChecksumMismatch, errChecksumMismatch},
+		{"corrupted file", checksum, false, ChecksumSkipped, nil},
+		{"uploaded file", nil, true, ChecksumUnavailable, nil},
+	}
+	for i, test := range tests {
+		if err = ioutil.WriteFile(path, []byte(test.data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		op := ops.add(OperationDownload, "file")
+		if err = verifyDownloadChecksum(op, path, test.checksum, test.wholeFile); err != test.err {
+			t.Errorf("test %d: expect error %v, got %v", i, test.err, err)
+		}
+		if status := op.snapshot(); status.Verification != test.expect {
+			t.Errorf("test %d: expect verification %v, got %v", i, test.expect, status.Verification)
+		}
+	}
+}
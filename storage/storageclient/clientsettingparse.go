@@ -93,6 +93,15 @@ func parseClientSetting(settings map[string]string, prevSetting storage.ClientSe
 			}
 			clientSetting.RentPayment.ExpectedRedundancy = redundancy
 
+		case key == "downloadbudget":
+			var budget common.BigInt
+			budget, err = unit.ParseCurrency(value)
+			if err != nil {
+				err = fmt.Errorf("failed to parse the download budget: %s", err.Error())
+				break
+			}
+			clientSetting.RentPayment.DownloadBudget = budget
+
 		case key == "violation":
 			var status bool
 			status, err = unit.ParseBool(value)
@@ -120,6 +129,15 @@ func parseClientSetting(settings map[string]string, prevSetting storage.ClientSe
 			}
 			clientSetting.MaxDownloadSpeed = downloadSpeed
 
+		case key == "features":
+			var featureFlags storage.FeatureFlags
+			featureFlags, err = storage.ParseFeatureFlags(value)
+			if err != nil {
+				err = fmt.Errorf("failed to parse the feature flags: %s", err.Error())
+				break
+			}
+			clientSetting.FeatureFlags = featureFlags
+
 		default:
 			err = fmt.Errorf("the key entered: %s is not valid. Here is a list of available keys: %+v",
 				key, keys)
@@ -172,7 +172,7 @@ func randomValue(selectedKeys map[string]string) (settings map[string]string, er
 
 	for key := range selectedKeys {
 		switch {
-		case key == "fund":
+		case key == "fund" || key == "downloadbudget":
 			value = common.RandomBigInt()
 			granularity = unit.CurrencyUnit[rand.Intn(len(unit.CurrencyUnit))]
 			break
@@ -200,6 +200,11 @@ func randomValue(selectedKeys map[string]string) (settings map[string]string, er
 			value = rand.Int63()
 			granularity = unit.SpeedUnit[rand.Intn(len(unit.SpeedUnit))]
 			break
+		case key == "features":
+			features := []string{"", "batchproof", "batchupload@1000,batchproof"}
+			value = features[rand.Intn(len(features))]
+			granularity = ""
+			break
 		default:
 			err = fmt.Errorf("the key received is not valid: %s", key)
 			return
@@ -245,6 +250,12 @@ func clientSettingValidation(key string, prevSetting storage.ClientSetting, curr
 	case "downloadspeed":
 		valid = currentSetting.MaxDownloadSpeed == prevSetting.MaxDownloadSpeed
 		return
+	case "features":
+		valid = currentSetting.FeatureFlags.String() == prevSetting.FeatureFlags.String()
+		return
+	case "downloadbudget":
+		valid = currentSetting.RentPayment.DownloadBudget.IsEqual(prevSetting.RentPayment.DownloadBudget)
+		return
 	default:
 		err = fmt.Errorf("the provided key is invalid: %s", key)
 		return
@@ -0,0 +1,143 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DxChainNetwork/godx/accounts"
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/contractset"
+)
+
+// ContractAuditState will return the client's view of the contract, including the latest
+// revision, the remaining funds, and the merkle roots
+func (client *StorageClient) ContractAuditState(id storage.ContractID) (state storage.ContractAuditState, err error) {
+	scs := client.contractManager.GetStorageContractSet()
+	contract, exist := scs.Acquire(id)
+	if !exist {
+		err = fmt.Errorf("the contract with %v does not exist", id)
+		return
+	}
+	defer scs.Return(contract)
+
+	return contractAuditState(contract)
+}
+
+// AuditContract will request the storage host's view of the contract, and compare it with
+// the client's view. The structured difference helps diagnosing the revision mismatch. The
+// audit is abandoned once the context is done, while the negotiation already started is left
+// to finish in the background, so that the connection is released properly
+func (client *StorageClient) AuditContract(ctx context.Context, id storage.ContractID) (diff storage.ContractAuditDiff, err error) {
+	scs := client.contractManager.GetStorageContractSet()
+	contract, exist := scs.Acquire(id)
+	if !exist {
+		err = fmt.Errorf("the contract with %v does not exist", id)
+		return
+	}
+	// hold the contract, so that the local view will not be revised during the audit
+	defer scs.Return(contract)
+
+	local, err := contractAuditState(contract)
+	if err != nil {
+		return
+	}
+	req, err := client.signContractAuditRequest(contract.Header())
+	if err != nil {
+		return
+	}
+
+	hostInfo, exist := client.storageHostManager.RetrieveHostInfo(contract.Header().EnodeID)
+	if !exist {
+		err = errors.New("the storage host of the contract cannot be found")
+		return
+	}
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	sp, err := client.SetupConnection(hostInfo.EnodeURL)
+	if err != nil {
+		return
+	}
+	if !sp.TryToRenewOrRevise() {
+		err = errors.New("the contract is currently renewing or revising")
+		return
+	}
+
+	type auditResult struct {
+		remote storage.ContractAuditState
+		err    error
+	}
+	resultChan := make(chan auditResult, 1)
+	go func() {
+		defer sp.RevisionOrRenewingDone()
+		remote, err := requestContractAudit(sp, req)
+		resultChan <- auditResult{remote, err}
+	}()
+
+	select {
+	case result := <-resultChan:
+		if result.err != nil {
+			return diff, result.err
+		}
+		return storage.DiffContractAuditStates(local, result.remote), nil
+	case <-ctx.Done():
+		return diff, ctx.Err()
+	}
+}
+
+// signContractAuditRequest creates the contract audit request signed by the client address
+// of the contract, which the storage host requires before revealing its view of the contract
+func (client *StorageClient) signContractAuditRequest(header contractset.ContractHeader) (req storage.ContractAuditRequest, err error) {
+	req.StorageContractID = common.Hash(header.ID)
+	account := accounts.Account{Address: header.LatestContractRevision.NewValidProofOutputs[0].Address}
+	wallet, err := client.ethBackend.AccountManager().Find(account)
+	if err != nil {
+		return
+	}
+	req.Signature, err = wallet.SignHash(account, req.SigHash().Bytes())
+	return
+}
+
+// requestContractAudit requests the storage host's view of the contract
+func requestContractAudit(sp storage.Peer, req storage.ContractAuditRequest) (remote storage.ContractAuditState, err error) {
+	if err = sp.RequestContractAudit(req); err != nil {
+		return
+	}
+	msg, err := sp.ClientWaitContractResp()
+	if err != nil {
+		return
+	}
+
+	switch msg.Code {
+	case storage.HostBusyHandleReqMsg:
+		err = storage.ErrHostBusyHandleReq
+		return
+	case storage.HostNegotiateErrorMsg:
+		err = errors.New("the storage host failed to provide its view of the contract")
+		return
+	case storage.ContractAuditRespMsg:
+	default:
+		err = fmt.Errorf("unexpected message code %v for the contract audit", msg.Code)
+		return
+	}
+
+	err = msg.Decode(&remote)
+	return
+}
+
+// contractAuditState returns the audit state of the contract based on its header and merkle roots
+func contractAuditState(contract *contractset.Contract) (state storage.ContractAuditState, err error) {
+	header := contract.Header()
+	roots, err := contract.MerkleRoots()
+	if err != nil {
+		return
+	}
+	return storage.NewContractAuditState(common.Hash(header.ID), header.LatestContractRevision, roots), nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/contractset"
+)
+
+// ContractFundHistory is the time series of the remaining fund of a contract, with
+// the burn rate and the projected exhaustion time derived from it
+type ContractFundHistory struct {
+	ContractID string                   `json:"contractid"`
+	Records    []contractset.FundRecord `json:"records"`
+
+	// BurnRate is the average fund consumed per hour over the history
+	BurnRate common.BigInt `json:"burnrate"`
+
+	// ProjectedExhaustion is the time the contract fund is expected to run out with
+	// the current burn rate. It is zero if no fund has been consumed
+	ProjectedExhaustion time.Time `json:"projectedexhaustion"`
+}
+
+// ContractFundHistory will return the fund consumption history of the contract
+func (client *StorageClient) ContractFundHistory(id storage.ContractID) (history ContractFundHistory, err error) {
+	records, exist, err := client.contractManager.GetStorageContractSet().RetrieveFundHistory(id)
+	if err != nil {
+		return
+	}
+	if !exist {
+		err = fmt.Errorf("the contract with %v does not exist", id)
+		return
+	}
+
+	history = ContractFundHistory{
+		ContractID: id.String(),
+		Records:    records,
+		BurnRate:   common.BigInt0,
+	}
+	if len(records) < 2 {
+		return
+	}
+
+	// estimate the burn rate from the first and the last record
+	first, last := records[0], records[len(records)-1]
+	spent := first.Remaining.Sub(last.Remaining)
+	elapsed := last.Timestamp.Sub(first.Timestamp)
+	if spent.Sign() <= 0 || elapsed <= 0 {
+		return
+	}
+	history.BurnRate = spent.MultFloat64(float64(time.Hour) / float64(elapsed))
+
+	// project when the remaining fund will be consumed
+	remaining := time.Duration(last.Remaining.Float64() / spent.Float64() * float64(elapsed))
+	history.ProjectedExhaustion = last.Timestamp.Add(remaining)
+	return
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractmanager
+
+import (
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/contractset"
+)
+
+// The expired contracts are moved out of the active contract set into the contract archive,
+// with the headers, the revisions and the roots compressed. The metadata of the expired
+// contracts is still kept in the settings for the cost calculation of the current period,
+// and is dropped from the settings at the period rollover once the contracts belong to the
+// previous periods, from then on the contracts are only queried from the archive.
+
+// archiveFromContractSet will move a list of contract from the activeContracts list into
+// the contract archive based on the list of contract id provided
+func (cm *ContractManager) archiveFromContractSet(ids []storage.ContractID) {
+	for _, id := range ids {
+		// acquire the contract that is trying to archive
+		contract, exists := cm.activeContracts.Acquire(id)
+
+		// if the contract does not exist, meaning somehow it has been deleted, log a warning
+		if !exists {
+			cm.log.Warn("the expired contract that is trying to be archived does not exist")
+			continue
+		}
+
+		// archive the contract
+		if err := cm.activeContracts.ArchiveContract(contract); err != nil {
+			cm.log.Error("failed to archive the contract from the active contract list", "err", err.Error())
+		}
+	}
+}
+
+// archiveExpiredContracts drops the expired contracts of the previous periods from the settings
+// at the period rollover. The contracts whose fund is still withheld by the storage proof are
+// kept until the next rollover. The contracts expired before the archive was introduced are
+// archived with the metadata only
+func (cm *ContractManager) archiveExpiredContracts() {
+	cm.lock.RLock()
+	currentPeriod, blockHeight := cm.currentPeriod, cm.blockHeight
+	cm.lock.RUnlock()
+
+	archive := cm.activeContracts.Archive()
+	var archived []storage.ContractMetaData
+	for _, contract := range cm.RetrieveExpiredContracts() {
+		if !cm.archivable(contract, currentPeriod, blockHeight) {
+			continue
+		}
+		if _, exists := archive.Contract(contract.ID); !exists {
+			if err := archive.Store(contractset.ArchivedContract{Metadata: contract}); err != nil {
+				cm.log.Warn("failed to archive the expired contract", "id", contract.ID, "err", err)
+				continue
+			}
+		}
+		archived = append(archived, contract)
+	}
+	if len(archived) == 0 {
+		return
+	}
+
+	cm.contractLock.Lock()
+	for _, contract := range archived {
+		delete(cm.expiredContracts, contract.ID)
+		if cm.hostToContract[contract.EnodeID] == contract.ID {
+			delete(cm.hostToContract, contract.EnodeID)
+		}
+	}
+	cm.contractLock.Unlock()
+
+	if err := cm.saveSettings(); err != nil {
+		cm.log.Warn("failed to save the settings after archiving the expired contracts", "err", err)
+	}
+	cm.log.Info("archived the expired contracts of the previous periods", "contracts", len(archived))
+}
+
+// archivable checks if the expired contract could be dropped from the settings, which is not
+// used by the cost calculation of the current period
+func (cm *ContractManager) archivable(contract storage.ContractMetaData, currentPeriod, blockHeight uint64) bool {
+	if contract.StartHeight >= currentPeriod {
+		return false
+	}
+	host, exists := cm.hostManager.RetrieveHostInfo(contract.EnodeID)
+	return !exists || contract.EndHeight+host.WindowSize+maturityDelay <= blockHeight
+}
+
+// RetrieveArchivedContracts will return the metadata of all archived contracts
+func (cm *ContractManager) RetrieveArchivedContracts() []storage.ContractMetaData {
+	return cm.activeContracts.Archive().Contracts()
+}
+
+// RetrieveInactiveContracts will return all the contracts no longer active, which are the
+// expired contracts along with the contracts of the previous periods only kept in the archive
+func (cm *ContractManager) RetrieveInactiveContracts() (cms []storage.ContractMetaData) {
+	cms = cm.RetrieveExpiredContracts()
+	expired := make(map[storage.ContractID]struct{}, len(cms))
+	for _, contract := range cms {
+		expired[contract.ID] = struct{}{}
+	}
+	for _, contract := range cm.RetrieveArchivedContracts() {
+		if _, exists := expired[contract.ID]; !exists {
+			cms = append(cms, contract)
+		}
+	}
+	return
+}
+
+// RetrieveArchivedContract will return the complete record of the archived contract
+func (cm *ContractManager) RetrieveArchivedContract(id storage.ContractID) (contractset.ArchivedContract, error) {
+	return cm.activeContracts.Archive().Fetch(id)
+}
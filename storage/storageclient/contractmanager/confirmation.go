@@ -0,0 +1,194 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractmanager
+
+import (
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/core/vm"
+	"github.com/DxChainNetwork/godx/rlp"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// PendingContract is the contract formed or renewed whose contract create transaction has not
+// got enough confirmations yet. The pending contract cannot be used for uploading or renewing
+type PendingContract struct {
+	ID          storage.ContractID `json:"id"`
+	TxHash      common.Hash        `json:"txhash"`
+	SentHeight  uint64             `json:"sentheight"`
+	MinedHeight uint64             `json:"minedheight"`
+	BlockHash   common.Hash        `json:"blockhash"`
+}
+
+// mined checks if the contract create transaction has been mined in the canonical chain
+func (pc PendingContract) mined() bool {
+	return pc.BlockHash != (common.Hash{})
+}
+
+// Confirmations returns the number of confirmations of the contract create transaction at the
+// block height
+func (pc PendingContract) Confirmations(height uint64) uint64 {
+	if !pc.mined() || height < pc.MinedHeight {
+		return 0
+	}
+	return height - pc.MinedHeight + 1
+}
+
+// trackContractConfirmation keeps the contract pending until the contract create transaction
+// gets enough confirmations
+func (cm *ContractManager) trackContractConfirmation(id storage.ContractID, txHash common.Hash) {
+	cm.lock.RLock()
+	height := cm.blockHeight
+	cm.lock.RUnlock()
+
+	cm.contractLock.Lock()
+	defer cm.contractLock.Unlock()
+	cm.pendingContracts[id] = &PendingContract{
+		ID:         id,
+		TxHash:     txHash,
+		SentHeight: height,
+	}
+}
+
+// RetrievePendingContracts returns the contracts whose contract create transactions are not
+// confirmed yet
+func (cm *ContractManager) RetrievePendingContracts() (pcs []PendingContract) {
+	cm.contractLock.RLock()
+	defer cm.contractLock.RUnlock()
+	for _, pc := range cm.pendingContracts {
+		pcs = append(pcs, *pc)
+	}
+	return
+}
+
+// RetrievePendingContract returns the pending contract with the contract id provided
+func (cm *ContractManager) RetrievePendingContract(id storage.ContractID) (pc PendingContract, pending bool) {
+	cm.contractLock.RLock()
+	defer cm.contractLock.RUnlock()
+	p, pending := cm.pendingContracts[id]
+	if pending {
+		pc = *p
+	}
+	return
+}
+
+// pendingContractStatus marks the pending contract as not good for uploading and renewing
+func (cm *ContractManager) pendingContractStatus(contract storage.ContractMetaData) storage.ContractMetaData {
+	if _, pending := cm.RetrievePendingContract(contract.ID); pending {
+		contract.Status.UploadAbility = false
+		contract.Status.RenewAbility = false
+	}
+	return contract
+}
+
+// updateContractConfirmations updates the confirmations of the pending contracts with the block
+// chain change. The contract whose create transaction got enough confirmations becomes active,
+// and the contract whose create transaction is not mined within the timeout, which means the
+// transaction is dropped or reorged out, is reverted
+func (cm *ContractManager) updateContractConfirmations(change core.ChainChangeEvent) {
+	cm.lock.RLock()
+	height := cm.blockHeight
+	cm.lock.RUnlock()
+
+	cm.contractLock.Lock()
+	if len(cm.pendingContracts) == 0 {
+		cm.contractLock.Unlock()
+		return
+	}
+	// the contract create transactions in the reverted blocks are no longer mined
+	for _, blockHash := range change.RevertedBlockHashes {
+		for _, pc := range cm.pendingContracts {
+			if pc.BlockHash == blockHash {
+				pc.BlockHash, pc.MinedHeight = common.Hash{}, 0
+			}
+		}
+	}
+	cm.contractLock.Unlock()
+
+	// the applied blocks end with the current block
+	for i, blockHash := range change.AppliedBlockHashes {
+		ids, err := cm.contractCreateIDs(blockHash)
+		if err != nil {
+			cm.log.Warn("failed to get the contract create transactions of the block", "block", blockHash, "err", err)
+			continue
+		}
+		blockHeight := height + uint64(i+1) - uint64(len(change.AppliedBlockHashes))
+		cm.contractLock.Lock()
+		for _, id := range ids {
+			if pc, exists := cm.pendingContracts[id]; exists {
+				pc.MinedHeight, pc.BlockHash = blockHeight, blockHash
+			}
+		}
+		cm.contractLock.Unlock()
+	}
+
+	var confirmed, reverted []storage.ContractID
+	cm.contractLock.Lock()
+	for id, pc := range cm.pendingContracts {
+		switch {
+		case pc.Confirmations(height) >= contractConfirmations:
+			confirmed = append(confirmed, id)
+			delete(cm.pendingContracts, id)
+		case !pc.mined() && height >= pc.SentHeight+contractConfirmTimeout:
+			reverted = append(reverted, id)
+			delete(cm.pendingContracts, id)
+		}
+	}
+	cm.contractLock.Unlock()
+
+	for _, id := range confirmed {
+		cm.log.Info("the contract create transaction is confirmed", "contractID", id)
+	}
+	for _, id := range reverted {
+		cm.log.Warn("the contract create transaction is not mined in time, revert the contract", "contractID", id)
+		cm.revertPendingContract(id)
+	}
+}
+
+// contractCreateIDs returns the ids of the storage contracts created in the block
+func (cm *ContractManager) contractCreateIDs(blockHash common.Hash) (ids []storage.ContractID, err error) {
+	txs, err := cm.b.GetTxByBlockHash(blockHash)
+	if err != nil {
+		return
+	}
+	for _, tx := range txs {
+		if tx.To() == nil {
+			continue
+		}
+		if p, ok := vm.PrecompiledEVMFileContracts[*tx.To()]; !ok || p != vm.ContractCreateTransaction {
+			continue
+		}
+		var sc types.StorageContract
+		if err := rlp.DecodeBytes(tx.Data(), &sc); err != nil {
+			continue
+		}
+		ids = append(ids, storage.ContractID(sc.ID()))
+	}
+	return
+}
+
+// revertPendingContract removes the contract whose create transaction never made it into the
+// block chain from the active contracts
+func (cm *ContractManager) revertPendingContract(id storage.ContractID) {
+	meta, exists := cm.activeContracts.RetrieveContractMetaData(id)
+	if !exists {
+		return
+	}
+	if err := rollbackContractSet(cm.activeContracts, id); err != nil {
+		cm.log.Error("failed to revert the pending contract", "contractID", id, "err", err)
+		return
+	}
+
+	cm.contractLock.Lock()
+	if cm.hostToContract[meta.EnodeID] == id {
+		delete(cm.hostToContract, meta.EnodeID)
+	}
+	if oldID, renewed := cm.renewedFrom[id]; renewed {
+		delete(cm.renewedFrom, id)
+		delete(cm.renewedTo, oldID)
+	}
+	cm.contractLock.Unlock()
+}
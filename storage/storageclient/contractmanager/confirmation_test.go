@@ -0,0 +1,114 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractmanager
+
+import (
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/rlp"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// storageClientBackendConfirmation is the client backend serving the transactions of the blocks
+type storageClientBackendConfirmation struct {
+	storageClientBackendContractManager
+	blockTxs map[common.Hash]types.Transactions
+}
+
+func (st *storageClientBackendConfirmation) GetTxByBlockHash(blockHash common.Hash) (types.Transactions, error) {
+	return st.blockTxs[blockHash], nil
+}
+
+func TestContractManager_UpdateContractConfirmations(t *testing.T) {
+	cm, err := createNewContractManager()
+	if err != nil {
+		t.Fatalf("failed to create contract manager: %s", err.Error())
+	}
+	defer os.RemoveAll("test")
+	defer cm.activeContracts.Close()
+	defer cm.activeContracts.EmptyDB()
+
+	// insert the contracts formed, whose create transactions are sent
+	minedContract := types.StorageContract{FileSize: 1, WindowStart: 100, WindowEnd: 200}
+	droppedContract := types.StorageContract{FileSize: 2, WindowStart: 100, WindowEnd: 200}
+	var ids []storage.ContractID
+	for _, sc := range []types.StorageContract{minedContract, droppedContract} {
+		header := randomCanceledContractGenerator()
+		header.ID = storage.ContractID(sc.ID())
+		header.Status = storage.ContractStatus{UploadAbility: true, RenewAbility: true}
+		if _, err := cm.activeContracts.InsertContract(header, nil); err != nil {
+			t.Fatalf("failed to insert contract: %s", err.Error())
+		}
+		cm.trackContractConfirmation(header.ID, sc.RLPHash())
+		ids = append(ids, header.ID)
+	}
+	minedID, droppedID := ids[0], ids[1]
+
+	// the pending contract cannot be used for uploading or renewing
+	if meta, _ := cm.RetrieveActiveContract(minedID); meta.Status.UploadAbility || meta.Status.RenewAbility {
+		t.Fatalf("the pending contract shall not be good for uploading or renewing")
+	}
+
+	data, err := rlp.EncodeToBytes(minedContract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := types.NewTransaction(0, common.BytesToAddress([]byte{10}), new(big.Int), 90000, new(big.Int), data)
+	forkBlock, canonicalBlock := common.HexToHash("0x1"), common.HexToHash("0x2")
+	backend := &storageClientBackendConfirmation{blockTxs: map[common.Hash]types.Transactions{
+		forkBlock:      {tx},
+		canonicalBlock: {tx},
+	}}
+	cm.b = backend
+
+	applyChange := func(change core.ChainChangeEvent) {
+		cm.blockHeight = cm.blockHeight + uint64(len(change.AppliedBlockHashes)) - uint64(len(change.RevertedBlockHashes))
+		cm.updateContractConfirmations(change)
+	}
+
+	// the transaction mined in the fork is no longer mined after the reorg
+	applyChange(core.ChainChangeEvent{AppliedBlockHashes: []common.Hash{forkBlock}})
+	if pc, _ := cm.RetrievePendingContract(minedID); pc.Confirmations(cm.blockHeight) != 1 {
+		t.Fatalf("confirmations not expected. Expect 1, Got %v", pc.Confirmations(cm.blockHeight))
+	}
+	applyChange(core.ChainChangeEvent{RevertedBlockHashes: []common.Hash{forkBlock}})
+	if pc, _ := cm.RetrievePendingContract(minedID); pc.Confirmations(cm.blockHeight) != 0 {
+		t.Fatalf("the transaction reorged out shall have no confirmation")
+	}
+
+	// the contract becomes active after enough confirmations
+	applyChange(core.ChainChangeEvent{AppliedBlockHashes: []common.Hash{canonicalBlock}})
+	for i := uint64(1); i < contractConfirmations; i++ {
+		if _, pending := cm.RetrievePendingContract(minedID); !pending {
+			t.Fatalf("the contract shall be pending with %v confirmations", i)
+		}
+		applyChange(core.ChainChangeEvent{AppliedBlockHashes: []common.Hash{randomHashGenerator()}})
+	}
+	if _, pending := cm.RetrievePendingContract(minedID); pending {
+		t.Fatalf("the contract shall be confirmed")
+	}
+	if meta, _ := cm.RetrieveActiveContract(minedID); !meta.Status.UploadAbility || !meta.Status.RenewAbility {
+		t.Fatalf("the confirmed contract shall be good for uploading and renewing")
+	}
+
+	// the contract whose create transaction is not mined within the timeout is reverted
+	for cm.blockHeight < contractConfirmTimeout {
+		applyChange(core.ChainChangeEvent{AppliedBlockHashes: []common.Hash{randomHashGenerator()}})
+	}
+	if _, pending := cm.RetrievePendingContract(droppedID); pending {
+		t.Fatalf("the dropped contract shall no longer be pending")
+	}
+	if _, exists := cm.RetrieveActiveContract(droppedID); exists {
+		t.Fatalf("the dropped contract shall be reverted")
+	}
+	if _, exists := cm.RetrieveActiveContract(minedID); !exists {
+		t.Fatalf("the confirmed contract shall not be reverted")
+	}
+}
@@ -131,9 +131,9 @@ func (cm *ContractManager) maintainExpiration() {
 	// loop through all active contracts
 	for _, contract := range cm.activeContracts.RetrieveAllContractsMetaData() {
 		// check if the contract is expired or renewed
-		cm.lock.RLock()
+		cm.contractLock.RLock()
 		_, renewed := cm.renewedTo[contract.ID]
-		cm.lock.RUnlock()
+		cm.contractLock.RUnlock()
 		expired := currentBh > contract.EndHeight
 		// update the expired contract list
 		if expired || renewed {
@@ -148,8 +148,8 @@ func (cm *ContractManager) maintainExpiration() {
 		cm.log.Error("failed to save the expired contracts updates while checking the expired contract", "err", err.Error())
 	}
 
-	// delete the expired contract from the contract set
-	cm.delFromContractSet(expiredContractsIDs)
+	// move the expired contract from the contract set into the archive
+	cm.archiveFromContractSet(expiredContractsIDs)
 
 	// check and update the connection
 	cm.checkAndUpdateConnection(expiredContracts)
@@ -234,16 +234,16 @@ func (cm *ContractManager) removeDuplications() {
 		cm.log.Error("failed to save the expired contracts updates persistently", "err", err.Error())
 	}
 
-	// delete the duplicated contracts from the contract set
-	cm.delFromContractSet(duplicatedContractIDs)
+	// move the duplicated contracts from the contract set into the archive
+	cm.archiveFromContractSet(duplicatedContractIDs)
 }
 
 // maintainHostToContractIDMapping will remove storage host with non-active contracts
 func (cm *ContractManager) maintainHostToContractIDMapping() {
 	cm.log.Debug("Maintain hostToContract mapping started")
 
-	cm.lock.Lock()
-	defer cm.lock.Unlock()
+	cm.contractLock.Lock()
+	defer cm.contractLock.Unlock()
 
 	// clear all entries from hostToContract
 	cm.hostToContract = make(map[enode.ID]storage.ContractID)
@@ -336,24 +336,24 @@ func (cm *ContractManager) updateContractRenew(hostToContracts map[enode.ID][]st
 
 		// update the renewedFrom (new contract renewed from the old contract)
 		for i := 0; i < len(contracts)-1; i++ {
-			cm.lock.Lock()
+			cm.contractLock.Lock()
 			cm.renewedFrom[contracts[i].ID] = contracts[i+1].ID
-			cm.lock.Unlock()
+			cm.contractLock.Unlock()
 		}
 
 		// update the renewedTo (old contract renewed to new contract)
 		for i := len(contracts) - 1; i > 1; i-- {
-			cm.lock.Lock()
+			cm.contractLock.Lock()
 			cm.renewedTo[contracts[i].ID] = contracts[i-1].ID
-			cm.lock.Unlock()
+			cm.contractLock.Unlock()
 		}
 	}
 }
 
 // updateExpireContracts will place the contract into expired contracts list
 func (cm *ContractManager) updateExpiredContracts(contract storage.ContractMetaData) {
-	cm.lock.Lock()
-	defer cm.lock.Unlock()
+	cm.contractLock.Lock()
+	defer cm.contractLock.Unlock()
 	cm.expiredContracts[contract.ID] = contract
 }
 
@@ -361,31 +361,11 @@ func (cm *ContractManager) updateExpiredContracts(contract storage.ContractMetaD
 // the contract mapped to the enodeID is the newest contract, meaning the contract
 // with greater start height, if there are multiple of them
 func (cm *ContractManager) updateHostToContractID(contract storage.ContractMetaData) {
-	cm.lock.Lock()
-	defer cm.lock.Unlock()
+	cm.contractLock.Lock()
+	defer cm.contractLock.Unlock()
 	cm.hostToContract[contract.EnodeID] = contract.ID
 }
 
-// delFromContractSet will delete a list of contract from the activeContracts list
-// based on the list of contract id provided
-func (cm *ContractManager) delFromContractSet(ids []storage.ContractID) {
-	for _, id := range ids {
-		// acquire the contract that is trying to delete
-		contract, exists := cm.activeContracts.Acquire(id)
-
-		// if the contract does not exist, meaning somehow it has been deleted, log a warning
-		if !exists {
-			cm.log.Warn("the expired contract that is trying to be deleted does not exist")
-			continue
-		}
-
-		// delete the contract
-		if err := cm.activeContracts.Delete(contract); err != nil {
-			cm.log.Error("failed to delete the contract from the active contract list", "err", err.Error())
-		}
-	}
-}
-
 // markContractCancel will modify the contract status by marking
 // 		1. UploadAbility: false
 // 		2. RenewAbility: false
@@ -522,7 +502,7 @@ func (cm *ContractManager) checkContractStatus(contract storage.ContractMetaData
 	// check if the contract has enough funding for upload payment
 	// each contract is in charge of a data sector, sectorStorageCost specifies the storage price
 	// needed for storing a data sector in a certain period time
-	sectorStorageCost := host.StoragePrice.MultUint64(contractset.SectorSize * period)
+	sectorStorageCost := host.StoragePrice.MultUint64(contractset.SectorSize).MultUint64(period)
 
 	// upload cost for uploading a sector
 	sectorUploadBandwidthCost := host.UploadBandwidthPrice.MultUint64(contractset.SectorSize)
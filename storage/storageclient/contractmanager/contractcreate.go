@@ -20,19 +20,35 @@ import (
 	"github.com/DxChainNetwork/godx/storage/storagehost"
 )
 
-// prepareCreateContract refers that client will sign some contracts with hosts, which satisfies the upload/download demand
-func (cm *ContractManager) prepareCreateContract(neededContracts int, clientRemainingFund common.BigInt, rentPayment storage.RentPayment) (terminated bool, err error) {
+// prepareCreateContract refers that client will sign some contracts with hosts, which satisfies the upload/download demand.
+// The contract formation stops once the failed attempts reach the retry budget, and the result of each attempt is
+// recorded in the maintenance result
+func (cm *ContractManager) prepareCreateContract(neededContracts int, clientRemainingFund common.BigInt, rentPayment storage.RentPayment) (result MaintenanceResult, terminated bool, err error) {
+	retryBudget := cm.RetrieveCreateRetryBudget()
+	cm.lock.RLock()
+	result = MaintenanceResult{
+		BlockHeight:     cm.blockHeight,
+		ContractsNeeded: neededContracts,
+		RetryBudget:     retryBudget,
+	}
+	contractFund := rentPayment.Fund.DivUint64(rentPayment.StorageHosts).DivUint64(3)
+	contractEndHeight := cm.currentPeriod + rentPayment.Period + rentPayment.RenewWindow
+	cm.lock.RUnlock()
+
+	// update the unmet contracts and the error before return
+	defer func() {
+		result.UnmetContracts = neededContracts - result.ContractsCreated
+		if err != nil {
+			result.Error = err.Error()
+		}
+	}()
+
 	// get some random hosts for contract formation
 	randomHosts, err := cm.randomHostsForContractForm(neededContracts)
 	if err != nil {
 		return
 	}
 
-	cm.lock.RLock()
-	contractFund := rentPayment.Fund.DivUint64(rentPayment.StorageHosts).DivUint64(3)
-	contractEndHeight := cm.currentPeriod + rentPayment.Period + rentPayment.RenewWindow
-	cm.lock.RUnlock()
-
 	// loop through each host and try to form contract with them
 	for _, host := range randomHosts {
 		// check if the client has enough fund for forming contract
@@ -43,16 +59,26 @@ func (cm *ContractManager) prepareCreateContract(neededContracts int, clientRema
 		}
 
 		// start to form contract
+		result.Attempts++
 		formCost, contract, errFormContract := cm.createContract(host, contractFund, contractEndHeight, rentPayment)
 		// if contract formation failed, the error do not need to be returned, just try to form the
-		// contract with another storage host
+		// contract with another storage host until the retry budget is used up. The contract fund
+		// is still spent if the contract has been formed with the host
 		if errFormContract != nil {
-			cm.log.Warn("failed to create the contract", "err", errFormContract.Error())
+			failure := newContractCreateFailure(host, errFormContract)
+			cm.log.Warn("failed to create the contract", "host", failure.HostID, "ip", failure.IP, "stage", failure.Stage, "err", failure.Error)
+			clientRemainingFund = clientRemainingFund.Sub(formCost)
+			result.Failures = append(result.Failures, failure)
+			if len(result.Failures) >= result.RetryBudget {
+				cm.log.Warn("contract create retry budget used up", "budget", result.RetryBudget)
+				return
+			}
 			continue
 		}
 
 		// update the client remaining fund, and try to change the newly formed contract's status
 		clientRemainingFund = clientRemainingFund.Sub(formCost)
+		result.ContractsCreated++
 		if err = cm.markNewlyFormedContractStats(contract.ID); err != nil {
 			return
 		}
@@ -62,18 +88,19 @@ func (cm *ContractManager) prepareCreateContract(neededContracts int, clientRema
 			cm.log.Warn("after created the contract, failed to save the contract manager settings")
 		}
 
-		// update the number of needed contracts
-		neededContracts--
-		if neededContracts <= 0 {
-			break
+		// check if the needed contracts are all formed
+		if result.ContractsCreated >= neededContracts {
+			return
 		}
 
 		// check if the maintenance termination signal was sent
 		if terminated = cm.checkMaintenanceTermination(); terminated {
-			break
+			return
 		}
 	}
 
+	// all the random hosts are tried, but the contract needs are still not met
+	result.HostsExhausted = true
 	return
 }
 
@@ -84,10 +111,17 @@ func (cm *ContractManager) prepareCreateContract(neededContracts int, clientRema
 // 		4. update the contract manager fields
 func (cm *ContractManager) createContract(host storage.HostInfo, contractFund common.BigInt, contractEndHeight uint64, rentPayment storage.RentPayment) (formCost common.BigInt, newlyCreatedContract storage.ContractMetaData, err error) {
 	// 1. storage host validation
+	// validate the host config is signed by the host, in case the cached config is tampered
+	if err = verifyHostConfigSignature(host); err != nil {
+		formCost = common.BigInt0
+		err = newContractCreateError(CreateStageValidation, fmt.Errorf("failed to create the contract with host: %v, %s", host.EnodeID, err.Error()))
+		return
+	}
+
 	// validate the storage price
 	if host.StoragePrice.Cmp(maxHostStoragePrice) > 0 {
 		formCost = common.BigInt0
-		err = fmt.Errorf("failed to create the contract with host: %v, the storage price is too high", host.EnodeID)
+		err = newContractCreateError(CreateStageValidation, fmt.Errorf("failed to create the contract with host: %v, the storage price is too high", host.EnodeID))
 		return
 	}
 
@@ -99,7 +133,7 @@ func (cm *ContractManager) createContract(host storage.HostInfo, contractFund co
 	// validate the storage host max duration
 	if host.MaxDuration < rentPayment.Period {
 		formCost = common.BigInt0
-		err = fmt.Errorf("failed to create the contract with host: %v, the max duration is smaller than period", host.EnodeID)
+		err = newContractCreateError(CreateStageValidation, fmt.Errorf("failed to create the contract with host: %v, the max duration is smaller than period", host.EnodeID))
 		return
 	}
 
@@ -115,7 +149,7 @@ func (cm *ContractManager) createContract(host storage.HostInfo, contractFund co
 	var clientPaymentAddress common.Address
 	if clientPaymentAddress, err = cm.b.GetPaymentAddress(); err != nil {
 		formCost = common.BigInt0
-		err = fmt.Errorf("failed to create the contract with host: %v, failed to get the clientPayment address: %s", host.EnodeID, err.Error())
+		err = newContractCreateError(CreateStagePreparation, fmt.Errorf("failed to create the contract with host: %v, failed to get the clientPayment address: %s", host.EnodeID, err.Error()))
 		return
 	}
 
@@ -133,23 +167,23 @@ func (cm *ContractManager) createContract(host storage.HostInfo, contractFund co
 	// 3. create the contract
 	if newlyCreatedContract, err = cm.ContractCreate(params); err != nil {
 		formCost = common.BigInt0
-		err = fmt.Errorf("failed to create the contract: %s", err.Error())
+		err = newContractCreateError(CreateStageNegotiation, fmt.Errorf("failed to create the contract with host: %v, %s", host.EnodeID, err.Error()))
 		return
 	}
 
 	// 4. update the contract manager fields
-	cm.lock.Lock()
+	cm.contractLock.Lock()
 	// check if the storage client have created another contract with the same storage host
 	if _, exists := cm.hostToContract[newlyCreatedContract.EnodeID]; exists {
-		cm.lock.Unlock()
+		cm.contractLock.Unlock()
 		formCost = contractFund
-		err = fmt.Errorf("client already formed a contract with the same storage host %v", newlyCreatedContract.EnodeID)
+		err = newContractCreateError(CreateStageRegistration, fmt.Errorf("client already formed a contract with the same storage host %v", newlyCreatedContract.EnodeID))
 		return
 	}
 
 	// if not exists, update the host to contract mapping
 	cm.hostToContract[newlyCreatedContract.EnodeID] = newlyCreatedContract.ID
-	cm.lock.Unlock()
+	cm.contractLock.Unlock()
 
 	formCost = contractFund
 	return
@@ -164,7 +198,6 @@ func (cm *ContractManager) randomHostsForContractForm(neededContracts int) (rand
 	var addressBlackList []enode.ID
 	activeContracts := cm.activeContracts.RetrieveAllContractsMetaData()
 
-	cm.lock.RLock()
 	for _, contract := range activeContracts {
 		blackList = append(blackList, contract.EnodeID)
 
@@ -173,7 +206,6 @@ func (cm *ContractManager) randomHostsForContractForm(neededContracts int) (rand
 			addressBlackList = append(addressBlackList, contract.EnodeID)
 		}
 	}
-	cm.lock.RUnlock()
 
 	// randomly retrieve some hosts
 	return cm.hostManager.RetrieveRandomHosts(neededContracts*randomStorageHostsFactor+randomStorageHostsBackup, blackList, addressBlackList)
@@ -205,7 +237,7 @@ func (cm *ContractManager) ContractCreate(params storage.ContractParams) (md sto
 		FileMerkleRoot:   common.Hash{}, // no proof possible without data
 		WindowStart:      endHeight,
 		WindowEnd:        endHeight + host.WindowSize,
-		ClientCollateral: types.DxcoinCollateral{DxcoinCharge: types.DxcoinCharge{Value: clientPayout.BigIntPtr(), Address: clientPaymentAddress}},
+		ClientCollateral: types.DxcoinCollateral{DxcoinCharge: types.DxcoinCharge{Value: clientPayout.BigIntPtr(), Address: cm.collateralAddress(clientPaymentAddress)}},
 		HostCollateral:   types.DxcoinCollateral{DxcoinCharge: types.DxcoinCharge{Value: hostPayout.BigIntPtr(), Address: host.PaymentAddress}},
 		UnlockHash:       uc.UnlockHash(),
 		RevisionNumber:   0,
@@ -353,7 +385,7 @@ func (cm *ContractManager) ContractCreate(params storage.ContractParams) (md sto
 		return storage.ContractMetaData{}, clientNegotiateErr
 	}
 
-	if _, err := cm.b.SendStorageContractCreateTx(clientPaymentAddress, scBytes); err != nil {
+	if err := cm.sendContractCreateTx(clientPaymentAddress, storage.ContractID(storageContract.ID()), scBytes); err != nil {
 		clientNegotiateErr = storagehost.ExtendErr("Send storage contract creation transaction error", err)
 		return storage.ContractMetaData{}, clientNegotiateErr
 	}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"sync"
 
+	"github.com/DxChainNetwork/godx/common"
 	"github.com/DxChainNetwork/godx/log"
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage"
@@ -18,6 +19,10 @@ import (
 
 // ContractManager is a data structure that is used to keep track of all contracts, including
 // both signed contracts and expired contracts
+//
+// The fields are guarded by the locks of their concerns, so that the contract formation, the
+// contract renew and the queries do not serialize on one lock. When more than one lock is
+// needed, they are acquired in the order of maintenanceLock, lock and contractLock
 type ContractManager struct {
 	// storage client backend
 	b storage.ClientBackend
@@ -25,57 +30,78 @@ type ContractManager struct {
 	// persistent directory
 	persistDir string
 
-	// expected payment from the storage client
-	rentPayment storage.RentPayment
-
 	// storage host manager
 	hostManager *storagehostmanager.StorageHostManager
 
-	// contract maintenance related
-	maintenanceStop    chan struct{}
-	maintenanceRunning bool
-	maintenanceWg      sync.WaitGroup
-
 	// contract related
-	activeContracts  *contractset.StorageContractSet
-	expiredContracts map[storage.ContractID]storage.ContractMetaData
+	activeContracts *contractset.StorageContractSet
+
+	// the contract create transactions sent and not mined yet, whose fee is bumped under the
+	// gas price ceiling
+	txMonitor *storage.StorageTxMonitor
 
+	// period and configuration related, guarded by lock
+	// expected payment from the storage client
+	rentPayment storage.RentPayment
+	// used to acquire storage contract
+	blockHeight   uint64
+	currentPeriod uint64
+	// storage client period cost
+	periodCost storage.PeriodCost
+	// the treasury account funding the client collateral through the escrow account
+	escrowTreasury common.Address
+	maxTxGasPrice  common.BigInt
+
+	// contract bookkeeping related, guarded by contractLock
+	expiredContracts map[storage.ContractID]storage.ContractMetaData
 	// hostID to contractID mapping
 	hostToContract map[enode.ID]storage.ContractID
-
 	// contract renew related, where renewed from connect [new] -> old
 	// and renewed to connect [old] -> new
 	renewedFrom      map[storage.ContractID]storage.ContractID
 	renewedTo        map[storage.ContractID]storage.ContractID
 	failedRenewCount map[storage.ContractID]uint64
+	// the contracts whose contract create transactions are not confirmed yet
+	pendingContracts map[storage.ContractID]*PendingContract
 
-	// used to acquire storage contract
-	blockHeight   uint64
-	currentPeriod uint64
-
-	// storage client period cost
-	periodCost storage.PeriodCost
+	// contract maintenance related, guarded by maintenanceLock
+	maintenanceStop    chan struct{}
+	maintenanceRunning bool
+	maintenanceWg      sync.WaitGroup
+	maintenanceGate    func() bool
+	// contract create related, the contract needs unmet are carried over to the
+	// maintenance run after the backoff
+	createRetryBudget int
+	createBackoff     uint64
+	nextCreateHeight  uint64
+	maintenanceResult MaintenanceResult
 
 	// utils
-	log  log.Logger
-	lock sync.RWMutex
-	wg   sync.WaitGroup
-	quit chan struct{}
+	log             log.Logger
+	lock            sync.RWMutex
+	contractLock    sync.RWMutex
+	maintenanceLock sync.RWMutex
+	persistLock     sync.Mutex
+	wg              sync.WaitGroup
+	quit            chan struct{}
 }
 
 // New will initialize the ContractManager object, which is used for contract maintenance
 func New(persistDir string, hm *storagehostmanager.StorageHostManager) (cm *ContractManager, err error) {
 	// contract manager initialization
 	cm = &ContractManager{
-		persistDir:       persistDir,
-		hostManager:      hm,
-		maintenanceStop:  make(chan struct{}),
-		expiredContracts: make(map[storage.ContractID]storage.ContractMetaData),
-		renewedFrom:      make(map[storage.ContractID]storage.ContractID),
-		renewedTo:        make(map[storage.ContractID]storage.ContractID),
-		failedRenewCount: make(map[storage.ContractID]uint64),
-		hostToContract:   make(map[enode.ID]storage.ContractID),
-		quit:             make(chan struct{}),
+		persistDir:        persistDir,
+		hostManager:       hm,
+		maintenanceStop:   make(chan struct{}, 1),
+		expiredContracts:  make(map[storage.ContractID]storage.ContractMetaData),
+		renewedFrom:       make(map[storage.ContractID]storage.ContractID),
+		renewedTo:         make(map[storage.ContractID]storage.ContractID),
+		failedRenewCount:  make(map[storage.ContractID]uint64),
+		hostToContract:    make(map[enode.ID]storage.ContractID),
+		createRetryBudget: defaultCreateRetryBudget,
+		txMonitor:         storage.NewStorageTxMonitor(storage.StorageTxConfirmBlocks),
+		pendingContracts:  make(map[storage.ContractID]*PendingContract),
+		quit:              make(chan struct{}),
 	}
 
 	// initialize log
@@ -156,6 +182,15 @@ func (cm *ContractManager) RetrieveRateLimit() (readBPS, writeBPS int64, packetS
 	return cm.activeContracts.RetrieveRateLimit()
 }
 
+// SetMaintenanceGate sets the function deciding whether the contract manager is allowed
+// to form and renew the contracts. It is used by the coordination mode, where only the
+// leader node maintains the contracts shared by all the nodes
+func (cm *ContractManager) SetMaintenanceGate(gate func() bool) {
+	cm.maintenanceLock.Lock()
+	defer cm.maintenanceLock.Unlock()
+	cm.maintenanceGate = gate
+}
+
 // GetStorageContractSet will be used to get the contract set stored with active contracts
 func (cm *ContractManager) GetStorageContractSet() (contractSet *contractset.StorageContractSet) {
 	return cm.activeContracts
@@ -163,12 +198,72 @@ func (cm *ContractManager) GetStorageContractSet() (contractSet *contractset.Sto
 
 // RetrieveActiveContracts will be used to retrieve all the signed contracts
 func (cm *ContractManager) RetrieveActiveContracts() (cms []storage.ContractMetaData) {
-	return cm.activeContracts.RetrieveAllContractsMetaData()
+	for _, contract := range cm.activeContracts.RetrieveAllContractsMetaData() {
+		cms = append(cms, cm.pendingContractStatus(contract))
+	}
+	return
 }
 
 // RetrieveActiveContract will return the contract meta data based on the contract id provided
 func (cm *ContractManager) RetrieveActiveContract(contractID storage.ContractID) (contract storage.ContractMetaData, exists bool) {
-	return cm.activeContracts.RetrieveContractMetaData(contractID)
+	if contract, exists = cm.activeContracts.RetrieveContractMetaData(contractID); exists {
+		contract = cm.pendingContractStatus(contract)
+	}
+	return
+}
+
+// RetrieveExpiredContracts will return all the contracts expired or renewed
+func (cm *ContractManager) RetrieveExpiredContracts() (cms []storage.ContractMetaData) {
+	cm.contractLock.RLock()
+	defer cm.contractLock.RUnlock()
+	for _, contract := range cm.expiredContracts {
+		cms = append(cms, contract)
+	}
+	return
+}
+
+// ContractRenewal is the renew of the contract with a storage host, where the new contract
+// was signed at the block height
+type ContractRenewal struct {
+	HostID      enode.ID           `json:"hostid"`
+	OldContract storage.ContractID `json:"oldcontract"`
+	NewContract storage.ContractID `json:"newcontract"`
+	BlockHeight uint64             `json:"blockheight"`
+}
+
+// RetrieveRenewals returns the renewals of the contracts with the storage host, from the latest
+// to the earliest, by following the renewed from chain of the current contract
+func (cm *ContractManager) RetrieveRenewals(hostID enode.ID) (renewals []ContractRenewal) {
+	cm.contractLock.RLock()
+	defer cm.contractLock.RUnlock()
+
+	currentID, exists := cm.hostToContract[hostID]
+	if !exists {
+		return
+	}
+	current, exists := cm.activeContracts.RetrieveContractMetaData(currentID)
+	if !exists {
+		return
+	}
+	// prevent loop from running forever
+	for i := 0; i <= len(cm.renewedFrom); i++ {
+		prevID, exists := cm.renewedFrom[current.ID]
+		if !exists {
+			break
+		}
+		renewals = append(renewals, ContractRenewal{
+			HostID:      hostID,
+			OldContract: prevID,
+			NewContract: current.ID,
+			BlockHeight: current.StartHeight,
+		})
+		if current, exists = cm.expiredContracts[prevID]; !exists {
+			if current, exists = cm.activeContracts.Archive().Contract(prevID); !exists {
+				break
+			}
+		}
+	}
+	return
 }
 
 // RetrievePeriodCost will get the client's period cost which specifies cost that storage
@@ -191,9 +286,9 @@ func (cm *ContractManager) HostHealthMapByID(hostIDs []enode.ID) (infoTable stor
 		}
 
 		// get the contract id signed with that storage host
-		cm.lock.RLock()
+		cm.contractLock.RLock()
 		contractID, exists := cm.hostToContract[id]
-		cm.lock.RUnlock()
+		cm.contractLock.RUnlock()
 		if !exists {
 			continue
 		}
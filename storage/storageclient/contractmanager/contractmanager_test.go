@@ -75,17 +75,19 @@ var testRentPayment = storage.RentPayment{
 func newContractManagerTest(hm *storagehostmanager.StorageHostManager) (cm *ContractManager, err error) {
 	// create and initialize host manager
 	cm = &ContractManager{
-		b:                &storageClientBackendContractManager{},
-		persistDir:       "test",
-		hostManager:      hm,
-		maintenanceStop:  make(chan struct{}),
-		expiredContracts: make(map[storage.ContractID]storage.ContractMetaData),
-		renewedFrom:      make(map[storage.ContractID]storage.ContractID),
-		renewedTo:        make(map[storage.ContractID]storage.ContractID),
-		failedRenewCount: make(map[storage.ContractID]uint64),
-		hostToContract:   make(map[enode.ID]storage.ContractID),
-		quit:             make(chan struct{}),
-		log:              log.New(),
+		b:                 &storageClientBackendContractManager{},
+		persistDir:        "test",
+		hostManager:       hm,
+		maintenanceStop:   make(chan struct{}, 1),
+		expiredContracts:  make(map[storage.ContractID]storage.ContractMetaData),
+		renewedFrom:       make(map[storage.ContractID]storage.ContractID),
+		renewedTo:         make(map[storage.ContractID]storage.ContractID),
+		failedRenewCount:  make(map[storage.ContractID]uint64),
+		hostToContract:    make(map[enode.ID]storage.ContractID),
+		pendingContracts:  make(map[storage.ContractID]*PendingContract),
+		createRetryBudget: defaultCreateRetryBudget,
+		quit:              make(chan struct{}),
+		log:               log.New(),
 	}
 	cs, err := contractset.New("test")
 	if err != nil {
@@ -178,10 +180,18 @@ func (st *storageClientBackendContractManager) SendStorageContractCreateTx(clien
 	return common.Hash{}, nil
 }
 
+func (st *storageClientBackendContractManager) BumpStorageContractTx(from common.Address, txHash common.Hash, priceBump uint64, maxGasPrice *big.Int) (common.Hash, error) {
+	return common.Hash{}, nil
+}
+
 func (st *storageClientBackendContractManager) GetHostAnnouncementWithBlockHash(blockHash common.Hash) (hostAnnouncements []types.HostAnnouncement, number uint64, errGet error) {
 	return
 }
 
+func (st *storageClientBackendContractManager) GetHostRevocationWithBlockHash(blockHash common.Hash) ([]types.HostRevocation, error) {
+	return nil, nil
+}
+
 func (st *storageClientBackendContractManager) GetPaymentAddress() (address common.Address, err error) {
 	return
 }
@@ -59,7 +59,7 @@ func (cm *ContractManager) checkForContractRenew(rentPayment storage.RentPayment
 
 		// for those contracts has insufficient funding, they should be renewed because otherwise
 		// after a while, they will be marked as not good for upload
-		sectorStorageCost := host.StoragePrice.MultUint64(contractset.SectorSize * rentPayment.Period)
+		sectorStorageCost := host.StoragePrice.MultUint64(contractset.SectorSize).MultUint64(rentPayment.Period)
 		sectorUploadBandwidthCost := host.UploadBandwidthPrice.MultUint64(contractset.SectorSize)
 		sectorDownloadBandwidthCost := host.DownloadBandwidthPrice.MultUint64(contractset.SectorSize)
 		totalSectorCost := sectorUploadBandwidthCost.Add(sectorDownloadBandwidthCost).Add(sectorStorageCost)
@@ -79,8 +79,8 @@ func (cm *ContractManager) checkForContractRenew(rentPayment storage.RentPayment
 // resetFailedRenews will update the failedRenewCount list, which only includes the failedRenewCount
 // in the current renew lists
 func (cm *ContractManager) resetFailedRenews(closeToExpireRenews []contractRenewRecord, insufficientFundingRenews []contractRenewRecord) {
-	cm.lock.Lock()
-	defer cm.lock.Unlock()
+	cm.contractLock.Lock()
+	defer cm.contractLock.Unlock()
 
 	filteredFailedRenews := make(map[storage.ContractID]uint64)
 
@@ -237,20 +237,20 @@ func (cm *ContractManager) contractRenewStart(record contractRenewRecord, curren
 	}
 
 	// update the renewedFrom, renewedTo, expiredContract field
-	cm.lock.Lock()
+	cm.contractLock.Lock()
 	cm.renewedFrom[renewedContract.ID] = oldContract.Metadata().ID
 	cm.renewedTo[oldContract.Metadata().ID] = renewedContract.ID
 	cm.expiredContracts[oldContract.Metadata().ID] = oldContract.Metadata()
-	cm.lock.Unlock()
+	cm.contractLock.Unlock()
 
 	// save the information persistently
 	if err = cm.saveSettings(); err != nil {
 		cm.log.Error("failed to save the settings persistently", "err", err.Error())
 	}
 
-	// delete the old oldContract from the active oldContract list
-	if err = cm.activeContracts.Delete(oldContract); err != nil {
-		cm.log.Error("failed to delete the contract from the active oldContract list after renew", "err", err.Error())
+	// move the old oldContract from the active oldContract list into the archive
+	if err = cm.activeContracts.ArchiveContract(oldContract); err != nil {
+		cm.log.Error("failed to archive the contract from the active oldContract list after renew", "err", err.Error())
 	}
 
 	err = nil
@@ -326,9 +326,9 @@ func (cm *ContractManager) renew(renewContract *contractset.Contract, rentPaymen
 	}
 
 	// 5. update the storage host to contract id mapping
-	cm.lock.Lock()
+	cm.contractLock.Lock()
 	cm.hostToContract[renewedContract.EnodeID] = renewedContract.ID
-	cm.lock.Unlock()
+	cm.contractLock.Unlock()
 
 	return
 }
@@ -341,17 +341,19 @@ func (cm *ContractManager) renew(renewContract *contractset.Contract, rentPaymen
 func (cm *ContractManager) handleRenewFailed(failedContract *contractset.Contract, renewError error, rentPayment storage.RentPayment, contractStatus storage.ContractStatus) (err error) {
 	// if renew failed is caused by the storage host, update the the failedRenewsCount
 	if common.ErrContains(renewError, ErrHostFault) {
-		cm.lock.Lock()
+		cm.contractLock.Lock()
 		cm.failedRenewCount[failedContract.Metadata().ID]++
-		cm.lock.Unlock()
+		cm.contractLock.Unlock()
 	}
 
 	// get the number of failed renews, to check if the contract needs to be replaced
 	// get the newest block height as well
 	cm.lock.RLock()
-	numFailed, _ := cm.failedRenewCount[failedContract.Metadata().ID]
 	blockHeight := cm.blockHeight
 	cm.lock.RUnlock()
+	cm.contractLock.RLock()
+	numFailed, _ := cm.failedRenewCount[failedContract.Metadata().ID]
+	cm.contractLock.RUnlock()
 
 	secondHalfRenewWindow := blockHeight+rentPayment.RenewWindow/2 >= failedContract.Metadata().EndHeight
 	contractReplace := numFailed >= consecutiveRenewFailsBeforeReplacement
@@ -414,7 +416,7 @@ func (cm *ContractManager) ContractRenew(oldContract *contractset.Contract, para
 		FileMerkleRoot:   lastRev.NewFileMerkleRoot, // no proof possible without data
 		WindowStart:      endHeight,
 		WindowEnd:        endHeight + host.WindowSize,
-		ClientCollateral: types.DxcoinCollateral{DxcoinCharge: types.DxcoinCharge{Value: clientPayout.BigIntPtr(), Address: clientAddr}},
+		ClientCollateral: types.DxcoinCollateral{DxcoinCharge: types.DxcoinCharge{Value: clientPayout.BigIntPtr(), Address: cm.collateralAddress(clientAddr)}},
 		HostCollateral:   types.DxcoinCollateral{DxcoinCharge: types.DxcoinCharge{Value: hostPayout.BigIntPtr(), Address: hostAddr}},
 		UnlockHash:       lastRev.NewUnlockHash,
 		RevisionNumber:   0,
@@ -555,7 +557,7 @@ func (cm *ContractManager) ContractRenew(oldContract *contractset.Contract, para
 		return storage.ContractMetaData{}, err
 	}
 
-	if _, err := cm.b.SendStorageContractCreateTx(clientAddr, scBytes); err != nil {
+	if err := cm.sendContractCreateTx(clientAddr, storage.ContractID(storageContract.ID()), scBytes); err != nil {
 		clientNegotiateErr = storagehost.ExtendErr("Send storage contract creation transaction error", err)
 		return storage.ContractMetaData{}, clientNegotiateErr
 	}
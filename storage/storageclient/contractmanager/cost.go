@@ -29,6 +29,10 @@ func (cm *ContractManager) renewCostEstimation(host storage.HostInfo, contract s
 	prevContractTotalDownloadCost := contract.DownloadCost
 
 	cm.lock.RLock()
+	currentPeriod := cm.currentPeriod
+	cm.lock.RUnlock()
+
+	cm.contractLock.RLock()
 	// prevent loop from running forever
 	for i := 0; i < 10e5; i++ {
 		// get the previous contractID
@@ -47,7 +51,7 @@ func (cm *ContractManager) renewCostEstimation(host storage.HostInfo, contract s
 		// current period will be changed in two places:
 		// 		1. SetRentPayment
 		//		2. ChainChanges
-		if prevContract.StartHeight < cm.currentPeriod {
+		if prevContract.StartHeight < currentPeriod {
 			break
 		}
 
@@ -56,7 +60,7 @@ func (cm *ContractManager) renewCostEstimation(host storage.HostInfo, contract s
 		prevContractTotalDownloadCost = prevContractTotalDownloadCost.Add(prevContract.DownloadCost)
 		currentID = prevContractID
 	}
-	cm.lock.RUnlock()
+	cm.contractLock.RUnlock()
 
 	// amount of data uploaded = total amount of data stored in the contract / uploadBandwidthPrice
 	prevDataUploaded := common.NewBigIntUint64(amountDataStored)
@@ -101,7 +105,11 @@ func (cm *ContractManager) CalculatePeriodCost(rentPayment storage.RentPayment)
 	activeContracts := cm.activeContracts.RetrieveAllContractsMetaData()
 
 	cm.lock.RLock()
-	defer cm.lock.RUnlock()
+	currentPeriod, blockHeight := cm.currentPeriod, cm.blockHeight
+	cm.lock.RUnlock()
+
+	cm.contractLock.RLock()
+	defer cm.contractLock.RUnlock()
 
 	// loop through all signed contract, get the total cost spent
 	// by the storage client
@@ -116,9 +124,9 @@ func (cm *ContractManager) CalculatePeriodCost(rentPayment storage.RentPayment)
 		host, exists := cm.hostManager.RetrieveHostInfo(contract.EnodeID)
 		// it is possible that the expiredContract (got renewed but still not expired, old contract)
 		// started within the current period. Therefore, add cost for that contract as well
-		if contract.StartHeight >= cm.currentPeriod {
+		if contract.StartHeight >= currentPeriod {
 			updatePrevContractCost(&periodCost, contract)
-		} else if exists && contract.EndHeight+host.WindowSize+maturityDelay > cm.blockHeight {
+		} else if exists && contract.EndHeight+host.WindowSize+maturityDelay > blockHeight {
 			// if the host exists, and the contract is still waiting for the storage proof
 			// then it means the balance left in the contract is still withHeld and not
 			// give back to the client yet
@@ -137,6 +145,14 @@ func (cm *ContractManager) CalculatePeriodCost(rentPayment storage.RentPayment)
 		}
 	}
 
+	// the contracts of the previous periods dropped from the expiredContracts list are
+	// only kept in the archive
+	for _, contract := range cm.activeContracts.Archive().Contracts() {
+		if _, exists := cm.expiredContracts[contract.ID]; !exists {
+			calculatePrevContractCost(&periodCost, contract)
+		}
+	}
+
 	// calculate the unspent fund based on the cost spent by the storage client already
 	calculateContractUnspentFund(&periodCost, rentPayment.Fund)
 
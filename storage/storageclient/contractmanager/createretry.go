@@ -0,0 +1,157 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractmanager
+
+import (
+	"errors"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// The stages of the contract formation, at which the contract formation with the storage host failed
+const (
+	CreateStageValidation   = "validation"
+	CreateStagePreparation  = "preparation"
+	CreateStageNegotiation  = "negotiation"
+	CreateStageRegistration = "registration"
+)
+
+// ContractCreateFailure records the failed contract formation with a storage host, and
+// the stage the contract formation failed at
+type ContractCreateFailure struct {
+	HostID   enode.ID `json:"hostid"`
+	EnodeURL string   `json:"enodeurl"`
+	IP       string   `json:"ip"`
+	Stage    string   `json:"stage"`
+	Error    string   `json:"error"`
+}
+
+// contractCreateError is the error of the contract formation at the stage
+type contractCreateError struct {
+	stage string
+	err   error
+}
+
+// newContractCreateError wraps the error of the contract formation at the stage
+func newContractCreateError(stage string, err error) error {
+	return &contractCreateError{stage: stage, err: err}
+}
+
+// Error implements the error interface
+func (e *contractCreateError) Error() string {
+	return e.err.Error()
+}
+
+// newContractCreateFailure creates the failure record of the contract formation with the host
+func newContractCreateFailure(host storage.HostInfo, err error) ContractCreateFailure {
+	failure := ContractCreateFailure{
+		HostID:   host.EnodeID,
+		EnodeURL: host.EnodeURL,
+		IP:       host.IP,
+		Error:    err.Error(),
+	}
+	if ce, ok := err.(*contractCreateError); ok {
+		failure.Stage = ce.stage
+	}
+	return failure
+}
+
+// MaintenanceResult is the contract formation result of the latest contract maintenance run.
+// The contracts not formed within the retry budget are carried over to the next run, which
+// is postponed until NextCreateHeight
+type MaintenanceResult struct {
+	BlockHeight      uint64                  `json:"blockheight"`
+	ContractsNeeded  int                     `json:"contractsneeded"`
+	ContractsCreated int                     `json:"contractscreated"`
+	Attempts         int                     `json:"attempts"`
+	RetryBudget      int                     `json:"retrybudget"`
+	HostsExhausted   bool                    `json:"hostsexhausted"`
+	Failures         []ContractCreateFailure `json:"failures"`
+	UnmetContracts   int                     `json:"unmetcontracts"`
+	NextCreateHeight uint64                  `json:"nextcreateheight"`
+	Error            string                  `json:"error"`
+}
+
+// SetCreateRetryBudget sets the maximum number of failed contract formations allowed
+// in one contract maintenance run
+func (cm *ContractManager) SetCreateRetryBudget(budget int) (err error) {
+	if budget <= 0 {
+		return errors.New("the contract create retry budget must be positive")
+	}
+
+	cm.maintenanceLock.Lock()
+	cm.createRetryBudget = budget
+	cm.maintenanceLock.Unlock()
+
+	return cm.saveSettings()
+}
+
+// RetrieveCreateRetryBudget returns the maximum number of failed contract formations
+// allowed in one contract maintenance run
+func (cm *ContractManager) RetrieveCreateRetryBudget() int {
+	cm.maintenanceLock.RLock()
+	defer cm.maintenanceLock.RUnlock()
+	return cm.createRetryBudget
+}
+
+// RetrieveMaintenanceResult returns the contract formation result of the latest
+// contract maintenance run
+func (cm *ContractManager) RetrieveMaintenanceResult() (result MaintenanceResult) {
+	cm.maintenanceLock.RLock()
+	defer cm.maintenanceLock.RUnlock()
+	result = cm.maintenanceResult
+	result.Failures = append([]ContractCreateFailure{}, cm.maintenanceResult.Failures...)
+	return
+}
+
+// contractCreatePostponed checks if the contract formation is postponed by the backoff
+// after the unmet contract needs of the previous run
+func (cm *ContractManager) contractCreatePostponed() (postponed bool) {
+	cm.lock.RLock()
+	blockHeight := cm.blockHeight
+	cm.lock.RUnlock()
+
+	cm.maintenanceLock.RLock()
+	defer cm.maintenanceLock.RUnlock()
+	return blockHeight < cm.nextCreateHeight
+}
+
+// resetCreateBackoff clears the backoff once the contract needs are met
+func (cm *ContractManager) resetCreateBackoff() {
+	cm.maintenanceLock.Lock()
+	defer cm.maintenanceLock.Unlock()
+	cm.createBackoff = 0
+	cm.nextCreateHeight = 0
+}
+
+// finishContractCreate records the result of the contract formation. If some contracts
+// are not formed, the next contract formation is postponed, and the backoff is doubled
+// for each consecutive run with the unmet contract needs
+func (cm *ContractManager) finishContractCreate(result MaintenanceResult) {
+	cm.lock.RLock()
+	blockHeight := cm.blockHeight
+	cm.lock.RUnlock()
+
+	cm.maintenanceLock.Lock()
+	defer cm.maintenanceLock.Unlock()
+
+	if result.UnmetContracts > 0 {
+		cm.createBackoff *= 2
+		if cm.createBackoff == 0 {
+			cm.createBackoff = minCreateBackoff
+		}
+		if cm.createBackoff > maxCreateBackoff {
+			cm.createBackoff = maxCreateBackoff
+		}
+		cm.nextCreateHeight = blockHeight + cm.createBackoff
+	} else {
+		cm.createBackoff = 0
+		cm.nextCreateHeight = 0
+	}
+
+	result.NextCreateHeight = cm.nextCreateHeight
+	cm.maintenanceResult = result
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractmanager
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestContractManager_CreateBackoff(t *testing.T) {
+	cm := &ContractManager{log: log.New()}
+	cm.blockHeight = 100
+
+	// the backoff is doubled for each run with the unmet contract needs
+	expected := []uint64{2, 4, 8, 16, 32, 64, 64}
+	for _, backoff := range expected {
+		cm.finishContractCreate(MaintenanceResult{UnmetContracts: 1})
+		if !cm.contractCreatePostponed() {
+			t.Fatalf("contract creation should be postponed")
+		}
+		if result := cm.RetrieveMaintenanceResult(); result.NextCreateHeight != cm.blockHeight+backoff {
+			t.Fatalf("next create height not expected. Expect %v, Got %v", cm.blockHeight+backoff, result.NextCreateHeight)
+		}
+		cm.blockHeight += backoff
+		if cm.contractCreatePostponed() {
+			t.Fatalf("contract creation should not be postponed after the backoff")
+		}
+	}
+
+	// the backoff is reset once the contract needs are met
+	cm.finishContractCreate(MaintenanceResult{UnmetContracts: 1})
+	cm.resetCreateBackoff()
+	if cm.contractCreatePostponed() || cm.createBackoff != 0 {
+		t.Fatalf("the backoff should be reset")
+	}
+	cm.finishContractCreate(MaintenanceResult{})
+	if result := cm.RetrieveMaintenanceResult(); result.NextCreateHeight != 0 || cm.contractCreatePostponed() {
+		t.Fatalf("contract creation should not be postponed if the contract needs are met")
+	}
+}
+
+func TestContractManager_SetCreateRetryBudget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "contractmanager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	newManager := func() *ContractManager {
+		return &ContractManager{
+			persistDir:        dir,
+			createRetryBudget: defaultCreateRetryBudget,
+			expiredContracts:  make(map[storage.ContractID]storage.ContractMetaData),
+			renewedFrom:       make(map[storage.ContractID]storage.ContractID),
+			renewedTo:         make(map[storage.ContractID]storage.ContractID),
+			hostToContract:    make(map[enode.ID]storage.ContractID),
+			log:               log.New(),
+		}
+	}
+	cm := newManager()
+	if err := cm.SetCreateRetryBudget(0); err == nil {
+		t.Fatalf("non-positive retry budget should not be allowed")
+	}
+	if err := cm.SetCreateRetryBudget(3); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := newManager()
+	if err := loaded.loadSettings(); err != nil {
+		t.Fatal(err)
+	}
+	if budget := loaded.RetrieveCreateRetryBudget(); budget != 3 {
+		t.Errorf("retry budget not persisted. Expect %v, Got %v", 3, budget)
+	}
+}
+
+func TestNewContractCreateFailure(t *testing.T) {
+	host := storage.HostInfo{EnodeID: enode.ID{1}, EnodeURL: "enode://host", IP: "127.0.0.1"}
+	failure := newContractCreateFailure(host, newContractCreateError(CreateStageNegotiation, errors.New("rejected")))
+	expected := ContractCreateFailure{
+		HostID:   host.EnodeID,
+		EnodeURL: host.EnodeURL,
+		IP:       host.IP,
+		Stage:    CreateStageNegotiation,
+		Error:    "rejected",
+	}
+	if failure != expected {
+		t.Errorf("failure not expected. Expect %+v, Got %+v", expected, failure)
+	}
+
+	// the stage is left empty for the error not from the contract formation stages
+	if failure := newContractCreateFailure(host, errors.New("unknown")); failure.Stage != "" || failure.Error != "unknown" {
+		t.Errorf("failure not expected: %+v", failure)
+	}
+}
@@ -35,6 +35,21 @@ const (
 
 	// if a contract failed to renew for 12 times, consider to replace the contract
 	consecutiveRenewFailsBeforeReplacement = 12
+
+	// defaultCreateRetryBudget is the default number of failed contract formations
+	// allowed in one maintenance run
+	defaultCreateRetryBudget = 10
+
+	// the contract formation with unmet contract needs is postponed for the number of
+	// blocks, which is doubled for each consecutive run until the max backoff
+	minCreateBackoff = uint64(2)
+	maxCreateBackoff = uint64(64)
+
+	// the newly formed contract is pending until the contract create transaction gets the
+	// number of confirmations, and reverted if the transaction is not mined within the timeout
+	// blocks, after which the storage host drops the contract as well
+	contractConfirmations  = uint64(6)
+	contractConfirmTimeout = uint64(40)
 )
 
 // variables below are used to calculate the maxHostStoragePrice and maxHostDeposit, which set
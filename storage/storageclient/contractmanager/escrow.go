@@ -0,0 +1,39 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractmanager
+
+import (
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+)
+
+// SetEscrowTreasury sets the treasury account funding the storage contracts of the client.
+// Once set, the client collateral of the contracts formed or renewed is paid by the escrow
+// account funded by the treasury, within the spending cap set by the treasury on-chain.
+// Setting the empty address pays the client collateral from the client payment address again
+func (cm *ContractManager) SetEscrowTreasury(treasury common.Address) (err error) {
+	cm.lock.Lock()
+	cm.escrowTreasury = treasury
+	cm.lock.Unlock()
+
+	return cm.saveSettings()
+}
+
+// RetrieveEscrowTreasury returns the treasury account funding the storage contracts of the client
+func (cm *ContractManager) RetrieveEscrowTreasury() common.Address {
+	cm.lock.RLock()
+	defer cm.lock.RUnlock()
+	return cm.escrowTreasury
+}
+
+// collateralAddress returns the address paying the client collateral of the storage contract.
+// It is the escrow account of the client if the treasury is set, otherwise the client address
+func (cm *ContractManager) collateralAddress(clientAddr common.Address) common.Address {
+	treasury := cm.RetrieveEscrowTreasury()
+	if treasury == (common.Address{}) {
+		return clientAddr
+	}
+	return types.EscrowAddress(treasury, clientAddr)
+}
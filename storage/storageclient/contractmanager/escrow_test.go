@@ -0,0 +1,59 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestContractManager_SetEscrowTreasury(t *testing.T) {
+	dir, err := ioutil.TempDir("", "contractmanager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	newManager := func() *ContractManager {
+		return &ContractManager{
+			persistDir:        dir,
+			createRetryBudget: defaultCreateRetryBudget,
+			expiredContracts:  make(map[storage.ContractID]storage.ContractMetaData),
+			renewedFrom:       make(map[storage.ContractID]storage.ContractID),
+			renewedTo:         make(map[storage.ContractID]storage.ContractID),
+			hostToContract:    make(map[enode.ID]storage.ContractID),
+			log:               log.New(),
+		}
+	}
+	clientAddr := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	treasury := common.HexToAddress("0x1000000000000000000000000000000000000002")
+
+	// the client collateral is paid by the client address without the treasury
+	cm := newManager()
+	if addr := cm.collateralAddress(clientAddr); addr != clientAddr {
+		t.Errorf("collateral address not expected. Expect %v, Got %v", clientAddr, addr)
+	}
+	if err := cm.SetEscrowTreasury(treasury); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := newManager()
+	if err := loaded.loadSettings(); err != nil {
+		t.Fatal(err)
+	}
+	if addr := loaded.RetrieveEscrowTreasury(); addr != treasury {
+		t.Errorf("escrow treasury not persisted. Expect %v, Got %v", treasury, addr)
+	}
+	if addr, expect := loaded.collateralAddress(clientAddr), types.EscrowAddress(treasury, clientAddr); addr != expect {
+		t.Errorf("collateral address not expected. Expect %v, Got %v", expect, addr)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractmanager
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// TestContractManager_ConcurrentAccess runs the contract formation and renew bookkeeping,
+// the chain changes and the status queries at the same time. It is meant to be run with
+// the race detector
+func TestContractManager_ConcurrentAccess(t *testing.T) {
+	cm, err := createNewContractManager()
+	if err != nil {
+		t.Fatalf("failed to create contract manager: %s", err.Error())
+	}
+	defer os.RemoveAll("test")
+	defer cm.activeContracts.Close()
+	defer cm.activeContracts.EmptyDB()
+
+	amount := 100
+	if testing.Short() {
+		amount = 10
+	}
+	cm.blockHeight = 100
+	endHeight := cm.blockHeight * 2
+
+	var wg sync.WaitGroup
+	formed := make(chan storage.ContractMetaData, amount)
+	done := make(chan struct{})
+
+	// contract formation
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(formed)
+		for i := 0; i < amount; i++ {
+			meta, err := cm.activeContracts.InsertContract(randomContractGenerator(endHeight), randomRootsGenerator(1))
+			if err != nil {
+				t.Errorf("failed to insert the contract: %s", err.Error())
+				return
+			}
+			cm.updateHostToContractID(meta)
+			cm.trackContractConfirmation(meta.ID, randomHashGenerator())
+			cm.finishContractCreate(MaintenanceResult{ContractsNeeded: 1, ContractsCreated: 1})
+			formed <- meta
+		}
+	}()
+
+	// contract renew bookkeeping on the contracts formed
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var prev storage.ContractMetaData
+		for meta := range formed {
+			if prev.ID != (storage.ContractID{}) {
+				cm.updateContractRenew(map[enode.ID][]storage.ContractMetaData{meta.EnodeID: {meta, prev}})
+				cm.updateExpiredContracts(prev)
+			}
+			if err := cm.saveSettings(); err != nil {
+				t.Errorf("failed to save the settings: %s", err.Error())
+			}
+			prev = meta
+		}
+	}()
+
+	// chain changes, the pending contracts are not reverted within the confirm timeout
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := uint64(1); i < contractConfirmTimeout; i++ {
+			cm.lock.Lock()
+			cm.blockHeight++
+			cm.lock.Unlock()
+			cm.updateContractConfirmations(core.ChainChangeEvent{AppliedBlockHashes: []common.Hash{randomHashGenerator()}})
+		}
+	}()
+
+	// status queries
+	for i := 0; i < 4; i++ {
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				cm.RetrieveActiveContracts()
+				cm.RetrieveExpiredContracts()
+				cm.RetrievePendingContracts()
+				cm.RetrievePeriodCost()
+				cm.RetrieveMaintenanceResult()
+				cm.CalculatePeriodCost(testRentPayment)
+				cm.contractCreatePostponed()
+				cm.maintainHostToContractIDMapping()
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+
+	if contracts := cm.RetrieveActiveContracts(); len(contracts) != amount {
+		t.Fatalf("expect %v active contracts, got %v", amount, len(contracts))
+	}
+	cm.contractLock.RLock()
+	mapped := len(cm.hostToContract)
+	cm.contractLock.RUnlock()
+	if mapped != amount {
+		t.Errorf("expect %v hosts mapped to the contracts, got %v", amount, mapped)
+	}
+	if pending := cm.RetrievePendingContracts(); len(pending) != amount {
+		t.Errorf("expect %v pending contracts, got %v", amount, len(pending))
+	}
+	if expired := cm.RetrieveExpiredContracts(); len(expired) != amount-1 {
+		t.Errorf("expect %v expired contracts, got %v", amount-1, len(expired))
+	}
+}
@@ -31,18 +31,27 @@ type contractRenewRecord struct {
 func (cm *ContractManager) contractMaintenance() {
 	// if the maintenance is running, return directly
 	// otherwise, start the maintaining job
-	cm.lock.Lock()
+	cm.maintenanceLock.Lock()
 	if cm.maintenanceRunning {
-		cm.lock.Unlock()
+		cm.maintenanceLock.Unlock()
 		return
 	}
 	cm.maintenanceRunning = true
-	cm.lock.Unlock()
-
 	// add wait group function, register defer function
 	cm.maintenanceWg.Add(1)
+	gate := cm.maintenanceGate
+	cm.maintenanceLock.Unlock()
+
+	// drop the stop signal sent to the previous maintenance after it finished
+	select {
+	case <-cm.maintenanceStop:
+	default:
+	}
+
 	defer func() {
+		cm.maintenanceLock.Lock()
 		cm.maintenanceRunning = false
+		cm.maintenanceLock.Unlock()
 		cm.maintenanceWg.Done()
 	}()
 
@@ -58,6 +67,11 @@ func (cm *ContractManager) contractMaintenance() {
 	rentPayment := cm.rentPayment
 	cm.lock.RUnlock()
 
+	// in the coordination mode, only the leader node forms and renews the contracts
+	if gate != nil && !gate() {
+		return
+	}
+
 	// when RentPayment is empty, meaning that the storage client does
 	// not want to sign contract with anyone
 	if reflect.DeepEqual(rentPayment, storage.RentPayment{}) {
@@ -119,21 +133,35 @@ func (cm *ContractManager) contractMaintenance() {
 	// get the number of contracts that needed to be formed
 	neededContracts := int(rentPayment.StorageHosts - uploadableContracts)
 	if neededContracts <= 0 {
+		cm.resetCreateBackoff()
 		return
 	}
 
-	// prepare to for forming contract based on the number of extract contracts needed
-	terminated, err := cm.prepareCreateContract(neededContracts, clientRemainingFund, rentPayment)
-	if err != nil {
-		cm.log.Error("failed to create the contract", "err", err.Error())
+	// the contract needs unmet in the previous run are carried over, and the
+	// formation is postponed until the backoff expires
+	if cm.contractCreatePostponed() {
+		cm.log.Debug("contract creation postponed by backoff", "neededContracts", neededContracts)
 		return
 	}
 
+	// prepare to for forming contract based on the number of extract contracts needed
+	result, terminated, err := cm.prepareCreateContract(neededContracts, clientRemainingFund, rentPayment)
+
 	// why terminated is checked explicitly?
 	// in case more codes need to be added in the future after this function
 	if terminated {
 		return
 	}
+
+	cm.finishContractCreate(result)
+	if err != nil {
+		cm.log.Error("failed to create the contract", "err", err.Error())
+		return
+	}
+	if result.UnmetContracts > 0 {
+		cm.log.Warn("contract needs not met, carried over to the next maintenance", "unmet", result.UnmetContracts,
+			"failures", len(result.Failures), "nextCreateHeight", result.NextCreateHeight)
+	}
 }
 
 // checkMaintenanceTermination will check if the maintenanceStop signal has been sent
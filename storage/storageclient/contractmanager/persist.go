@@ -17,21 +17,28 @@ var settingsMetadata = common.Metadata{
 }
 
 type persistence struct {
-	Rent             storage.RentPayment           `json:"rentPayment"`
-	BlockHeight      uint64                        `json:"blockheight"`
-	CurrentPeriod    uint64                        `json:"currentperiod"`
-	ExpiredContracts []storage.ContractMetaData    `json:"expiredcontracts"`
-	RenewedFrom      map[string]storage.ContractID `json:"renewedfrom"`
-	RenewedTo        map[string]storage.ContractID `json:"renewedto"`
+	Rent              storage.RentPayment           `json:"rentPayment"`
+	BlockHeight       uint64                        `json:"blockheight"`
+	CurrentPeriod     uint64                        `json:"currentperiod"`
+	ExpiredContracts  []storage.ContractMetaData    `json:"expiredcontracts"`
+	RenewedFrom       map[string]storage.ContractID `json:"renewedfrom"`
+	RenewedTo         map[string]storage.ContractID `json:"renewedto"`
+	CreateRetryBudget int                           `json:"createretrybudget"`
+	EscrowTreasury    common.Address                `json:"escrowtreasury"`
+	MaxTxGasPrice     common.BigInt                 `json:"maxtxgasprice"`
+	PendingContracts  []PendingContract             `json:"pendingcontracts"`
 }
 
 func (cm *ContractManager) persistUpdate() (persist persistence) {
 	persist = persistence{
-		Rent:          cm.rentPayment,
-		BlockHeight:   cm.blockHeight,
-		CurrentPeriod: cm.currentPeriod,
-		RenewedFrom:   make(map[string]storage.ContractID),
-		RenewedTo:     make(map[string]storage.ContractID),
+		Rent:              cm.rentPayment,
+		BlockHeight:       cm.blockHeight,
+		CurrentPeriod:     cm.currentPeriod,
+		CreateRetryBudget: cm.createRetryBudget,
+		EscrowTreasury:    cm.escrowTreasury,
+		MaxTxGasPrice:     cm.maxTxGasPrice,
+		RenewedFrom:       make(map[string]storage.ContractID),
+		RenewedTo:         make(map[string]storage.ContractID),
 	}
 
 	// update the renewedFrom
@@ -44,6 +51,11 @@ func (cm *ContractManager) persistUpdate() (persist persistence) {
 		persist.RenewedTo[key.String()] = value
 	}
 
+	// update the pendingContracts
+	for _, pc := range cm.pendingContracts {
+		persist.PendingContracts = append(persist.PendingContracts, *pc)
+	}
+
 	// update the expiredContracts
 	for _, ec := range cm.expiredContracts {
 		persist.ExpiredContracts = append(persist.ExpiredContracts, ec)
@@ -52,12 +64,20 @@ func (cm *ContractManager) persistUpdate() (persist persistence) {
 	return
 }
 
-// saveSettings will store all the persistence data into the JSON file
+// saveSettings will store all the persistence data into the JSON file. The saves are
+// serialized by the persistLock, so that the settings file is written by one at a time
 func (cm *ContractManager) saveSettings() (err error) {
-	cm.lock.Lock()
-	defer cm.lock.Unlock()
+	cm.persistLock.Lock()
+	defer cm.persistLock.Unlock()
 
+	cm.maintenanceLock.RLock()
+	cm.lock.RLock()
+	cm.contractLock.RLock()
 	data := cm.persistUpdate()
+	cm.contractLock.RUnlock()
+	cm.lock.RUnlock()
+	cm.maintenanceLock.RUnlock()
+
 	return common.SaveDxJSON(settingsMetadata, filepath.Join(cm.persistDir, PersistFileName), data)
 }
 
@@ -77,10 +97,24 @@ func (cm *ContractManager) loadSettings() (err error) {
 	}
 
 	// data initialization
+	cm.maintenanceLock.Lock()
+	defer cm.maintenanceLock.Unlock()
 	cm.lock.Lock()
+	defer cm.lock.Unlock()
+	cm.contractLock.Lock()
+	defer cm.contractLock.Unlock()
+
 	cm.rentPayment = data.Rent
 	cm.blockHeight = data.BlockHeight
 	cm.currentPeriod = data.CurrentPeriod
+	if data.CreateRetryBudget > 0 {
+		cm.createRetryBudget = data.CreateRetryBudget
+	}
+	cm.escrowTreasury = data.EscrowTreasury
+	cm.maxTxGasPrice = data.MaxTxGasPrice
+	for i := range data.PendingContracts {
+		cm.pendingContracts[data.PendingContracts[i].ID] = &data.PendingContracts[i]
+	}
 
 	// update the RenewedFrom
 	for key, value := range data.RenewedFrom {
@@ -113,7 +147,6 @@ func (cm *ContractManager) loadSettings() (err error) {
 		cm.expiredContracts[ec.ID] = ec
 		cm.hostToContract[ec.EnodeID] = ec.ID
 	}
-	cm.lock.Unlock()
 
 	return
 }
@@ -71,12 +71,16 @@ func (cm *ContractManager) SetRentPayment(rent storage.RentPayment) (err error)
 		return fmt.Errorf("failed to save settings persistently: %s", err.Error())
 	}
 
-	// if the maintenance process is running, stop it
-	cm.lock.Lock()
+	// if the maintenance process is running, stop it. The stop signal is buffered, so that
+	// the maintenance is not blocked on it
+	cm.maintenanceLock.RLock()
 	if cm.maintenanceRunning {
-		cm.maintenanceStop <- struct{}{}
+		select {
+		case cm.maintenanceStop <- struct{}{}:
+		default:
+		}
 	}
-	cm.lock.Unlock()
+	cm.maintenanceLock.RUnlock()
 
 	// wait util the current maintenance finished execution, and start new maintenance
 	go func() {
@@ -112,6 +116,8 @@ func RentPaymentValidation(rent storage.RentPayment) (err error) {
 		return errors.New("expectedRedundancy cannot be set to 0")
 	case rent.RenewWindow > rent.Period:
 		return errors.New("renew window cannot be larger than the period")
+	case rent.DownloadBudget.Cmp(rent.Fund) > 0:
+		return errors.New("download budget cannot be larger than the fund")
 	default:
 		return
 	}
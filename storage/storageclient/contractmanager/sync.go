@@ -47,11 +47,23 @@ func (cm *ContractManager) analyzeChainEventChange(change core.ChainChangeEvent)
 		cm.blockHeight++
 	}
 
+	var rollover bool
 	if cm.blockHeight >= cm.currentPeriod+cm.rentPayment.Period {
 		cm.currentPeriod += cm.rentPayment.Period
+		rollover = cm.rentPayment.Period != 0
 	}
 	cm.lock.Unlock()
 
+	// archive the expired contracts of the previous periods
+	if rollover {
+		cm.archiveExpiredContracts()
+	}
+
+	// update the confirmations of the pending contracts, and bump the fee of the contract
+	// create transactions not mined in time
+	cm.updateContractConfirmations(change)
+	cm.bumpPendingTxs()
+
 	// save the newest settings (blockHeight) persistently
 	if err := cm.saveSettings(); err != nil {
 		cm.log.Warn("failed to save the current contract manager settings while analyzing the chain change event", "err", err.Error())
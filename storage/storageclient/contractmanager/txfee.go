@@ -0,0 +1,73 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractmanager
+
+import (
+	"errors"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// SetMaxTxGasPrice sets the gas price ceiling of the contract create transaction. The contract
+// create transaction not mined within the confirm blocks is replaced with a higher fee, until
+// the gas price reaches the ceiling
+func (cm *ContractManager) SetMaxTxGasPrice(price common.BigInt) (err error) {
+	if price.Sign() <= 0 {
+		return errors.New("the max tx gas price must be positive")
+	}
+	cm.lock.Lock()
+	cm.maxTxGasPrice = price
+	cm.lock.Unlock()
+
+	return cm.saveSettings()
+}
+
+// RetrieveMaxTxGasPrice returns the gas price ceiling of the contract create transaction. The
+// default value is returned if not set
+func (cm *ContractManager) RetrieveMaxTxGasPrice() common.BigInt {
+	cm.lock.RLock()
+	defer cm.lock.RUnlock()
+	if cm.maxTxGasPrice.Sign() <= 0 {
+		return storage.DefaultMaxTxGasPrice
+	}
+	return cm.maxTxGasPrice
+}
+
+// RetrievePendingTxs returns the contract create transactions sent and not mined yet
+func (cm *ContractManager) RetrievePendingTxs() []storage.PendingStorageTx {
+	return cm.txMonitor.PendingTxs()
+}
+
+// sendContractCreateTx sends the contract create transaction, and monitors it until mined. The
+// contract is pending until the transaction is confirmed
+func (cm *ContractManager) sendContractCreateTx(clientAddr common.Address, id storage.ContractID, scBytes []byte) error {
+	txHash, err := cm.b.SendStorageContractCreateTx(clientAddr, scBytes)
+	if err != nil {
+		return err
+	}
+	cm.lock.RLock()
+	height := cm.blockHeight
+	cm.lock.RUnlock()
+	cm.txMonitor.Track(storage.StorageTxContractCreate, clientAddr, txHash, height)
+	cm.trackContractConfirmation(id, txHash)
+	return nil
+}
+
+// bumpPendingTxs replaces the contract create transactions not mined within the confirm blocks
+// with a higher fee
+func (cm *ContractManager) bumpPendingTxs() {
+	cm.lock.RLock()
+	height := cm.blockHeight
+	cm.lock.RUnlock()
+	maxGasPrice := cm.RetrieveMaxTxGasPrice().BigIntPtr()
+
+	errs := cm.txMonitor.BumpOverdue(height, func(tx storage.PendingStorageTx) (common.Hash, error) {
+		return cm.b.BumpStorageContractTx(tx.From, tx.TxHash, storage.StorageTxPriceBump, maxGasPrice)
+	})
+	for _, err := range errs {
+		cm.log.Warn("contract create transaction not mined within the confirm blocks", "err", err)
+	}
+}
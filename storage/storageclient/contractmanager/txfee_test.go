@@ -0,0 +1,57 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestContractManager_SetMaxTxGasPrice(t *testing.T) {
+	dir, err := ioutil.TempDir("", "contractmanager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	newManager := func() *ContractManager {
+		return &ContractManager{
+			persistDir:        dir,
+			createRetryBudget: defaultCreateRetryBudget,
+			expiredContracts:  make(map[storage.ContractID]storage.ContractMetaData),
+			renewedFrom:       make(map[storage.ContractID]storage.ContractID),
+			renewedTo:         make(map[storage.ContractID]storage.ContractID),
+			hostToContract:    make(map[enode.ID]storage.ContractID),
+			log:               log.New(),
+		}
+	}
+
+	// the default ceiling is used if not set
+	cm := newManager()
+	if price := cm.RetrieveMaxTxGasPrice(); !price.IsEqual(storage.DefaultMaxTxGasPrice) {
+		t.Errorf("max tx gas price not expected. Expect %v, Got %v", storage.DefaultMaxTxGasPrice, price)
+	}
+	if err := cm.SetMaxTxGasPrice(common.NewBigInt(0)); err == nil {
+		t.Errorf("setting the zero max tx gas price shall return error")
+	}
+	price := common.NewBigIntUint64(20e9)
+	if err := cm.SetMaxTxGasPrice(price); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := newManager()
+	if err := loaded.loadSettings(); err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.RetrieveMaxTxGasPrice(); !got.IsEqual(price) {
+		t.Errorf("max tx gas price not persisted. Expect %v, Got %v", price, got)
+	}
+}
@@ -4,7 +4,12 @@
 
 package contractmanager
 
-import "github.com/DxChainNetwork/godx/storage"
+import (
+	"fmt"
+
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/storage"
+)
 
 // isOffline will check if a storage host is online or not based on the number of scanRecords
 // and the successful rate of the records
@@ -20,3 +25,13 @@ func isOffline(host storage.HostInfo) (offline bool) {
 	offline = !(host.ScanRecords[len(host.ScanRecords)-1].Success || host.ScanRecords[len(host.ScanRecords)-2].Success)
 	return
 }
+
+// verifyHostConfigSignature will check if the host config stored in the host info
+// is signed by the host's node key
+func verifyHostConfigSignature(host storage.HostInfo) error {
+	pubKey, err := crypto.UnmarshalPubkey(host.NodePubKey)
+	if err != nil {
+		return fmt.Errorf("failed to convert the NodePubKey: %s", err.Error())
+	}
+	return storage.VerifyHostExtConfig(host.HostExtConfig, pubKey)
+}
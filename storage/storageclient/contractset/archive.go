@@ -0,0 +1,202 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractset
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// archiveIndexMetadata contains the header and version of the contract archive index file
+var archiveIndexMetadata = common.Metadata{
+	Header:  ArchiveIndexHeader,
+	Version: ArchiveIndexVersion,
+}
+
+// ArchivedContract is the complete record of an expired contract moved out of the contract
+// set. The header and the roots are empty for the contracts expired before the archive was
+// introduced, of which only the metadata was kept
+type ArchivedContract struct {
+	Metadata    storage.ContractMetaData `json:"metadata"`
+	Header      ContractHeader           `json:"header"`
+	Roots       []common.Hash            `json:"roots"`
+	FundHistory []FundRecord             `json:"fundhistory"`
+}
+
+// ContractArchive is the store of the expired contracts. Each contract is saved to a gzip
+// compressed JSON file, and the metadata of all contracts is indexed in memory for the
+// accounting and the queries
+type ContractArchive struct {
+	dir   string
+	index map[storage.ContractID]storage.ContractMetaData
+	lock  sync.RWMutex
+}
+
+// openContractArchive opens the contract archive in the directory, the index will be loaded
+// if the archive already existed
+func openContractArchive(dir string) (ca *ContractArchive, err error) {
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
+	ca = &ContractArchive{
+		dir:   dir,
+		index: make(map[storage.ContractID]storage.ContractMetaData),
+	}
+
+	var contracts []storage.ContractMetaData
+	err = common.LoadDxJSON(archiveIndexMetadata, filepath.Join(dir, archiveIndexName), &contracts)
+	if os.IsNotExist(err) {
+		return ca, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the contract archive index: %s", err.Error())
+	}
+	for _, contract := range contracts {
+		ca.index[contract.ID] = contract
+	}
+	return
+}
+
+// Store saves the contract to the archive, and adds its metadata to the index
+func (ca *ContractArchive) Store(ac ArchivedContract) (err error) {
+	if err = ca.writeContract(ac); err != nil {
+		return
+	}
+
+	ca.lock.Lock()
+	defer ca.lock.Unlock()
+	ca.index[ac.Metadata.ID] = ac.Metadata
+	return ca.saveIndex()
+}
+
+// Contracts returns the metadata of all archived contracts, sorted by the start height
+func (ca *ContractArchive) Contracts() (contracts []storage.ContractMetaData) {
+	ca.lock.RLock()
+	defer ca.lock.RUnlock()
+
+	for _, contract := range ca.index {
+		contracts = append(contracts, contract)
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		return contracts[i].StartHeight < contracts[j].StartHeight
+	})
+	return
+}
+
+// Contract returns the metadata of the archived contract
+func (ca *ContractArchive) Contract(id storage.ContractID) (contract storage.ContractMetaData, exists bool) {
+	ca.lock.RLock()
+	defer ca.lock.RUnlock()
+	contract, exists = ca.index[id]
+	return
+}
+
+// Fetch reads the complete record of the archived contract, including the header, the roots
+// and the fund history
+func (ca *ContractArchive) Fetch(id storage.ContractID) (ac ArchivedContract, err error) {
+	if _, exists := ca.Contract(id); !exists {
+		err = fmt.Errorf("the contract %v is not archived", id)
+		return
+	}
+
+	file, err := os.Open(ca.contractPath(id))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	err = json.NewDecoder(reader).Decode(&ac)
+	return
+}
+
+// writeContract writes the gzip compressed contract record to a temporary file, which is
+// renamed to the contract file once completely written
+func (ca *ContractArchive) writeContract(ac ArchivedContract) (err error) {
+	path := ca.contractPath(ac.Metadata.ID)
+	tmpPath := path + archiveTmpSuffix
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	writer := gzip.NewWriter(file)
+	if err = json.NewEncoder(writer).Encode(ac); err != nil {
+		file.Close()
+		return
+	}
+	if err = writer.Close(); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// saveIndex saves the metadata of all archived contracts
+//
+// NOTE: ca.lock should be held when calling this function
+func (ca *ContractArchive) saveIndex() error {
+	contracts := make([]storage.ContractMetaData, 0, len(ca.index))
+	for _, contract := range ca.index {
+		contracts = append(contracts, contract)
+	}
+	return common.SaveDxJSON(archiveIndexMetadata, filepath.Join(ca.dir, archiveIndexName), contracts)
+}
+
+// contractPath returns the path of the archived contract file
+func (ca *ContractArchive) contractPath(id storage.ContractID) string {
+	return filepath.Join(ca.dir, id.String()+archiveExt)
+}
+
+// Archive returns the archive of the expired contracts
+func (scs *StorageContractSet) Archive() *ContractArchive {
+	return scs.archive
+}
+
+// ArchiveContract moves the contract out of the contract set into the archive. The contract
+// must be acquired using the Acquire function first, and is released once archived
+func (scs *StorageContractSet) ArchiveContract(c *Contract) (err error) {
+	roots, err := c.MerkleRoots()
+	if err != nil {
+		c.lock.Unlock()
+		return
+	}
+	history, err := c.FundHistory()
+	if err != nil {
+		c.lock.Unlock()
+		return
+	}
+	ac := ArchivedContract{
+		Metadata:    c.Metadata(),
+		Header:      c.Header(),
+		Roots:       roots,
+		FundHistory: history,
+	}
+	if err = scs.archive.Store(ac); err != nil {
+		c.lock.Unlock()
+		return
+	}
+	return scs.Delete(c)
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package contractset
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestStorageContractSet_ArchiveContract(t *testing.T) {
+	dir, err := ioutil.TempDir("", "contractarchive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	scs, err := New(dir)
+	if err != nil {
+		t.Fatalf("failed to initialize storage contract set: %s", err.Error())
+	}
+
+	ch := contractHeaderGenerator()
+	rts := rootsGenerator(10)
+	if _, err := scs.InsertContract(ch, rts); err != nil {
+		t.Fatalf("failed to insert the contract: %s", err.Error())
+	}
+	c, exists := scs.Acquire(ch.ID)
+	if !exists {
+		t.Fatal("the contract inserted does not exist")
+	}
+	if err := scs.ArchiveContract(c); err != nil {
+		t.Fatalf("failed to archive the contract: %s", err.Error())
+	}
+
+	// the contract is moved out of the contract set
+	if _, exists := scs.RetrieveContractMetaData(ch.ID); exists {
+		t.Fatal("the archived contract shall be removed from the contract set")
+	}
+	if _, err := scs.db.FetchContractHeader(ch.ID); err == nil {
+		t.Fatal("the archived contract header shall be removed from the database")
+	}
+
+	// the archive is reloaded once the contract set is opened again
+	scs.Close()
+	if scs, err = New(dir); err != nil {
+		t.Fatalf("failed to initialize storage contract set: %s", err.Error())
+	}
+	defer scs.Close()
+
+	if contracts := scs.Archive().Contracts(); len(contracts) != 1 || contracts[0].ID != ch.ID {
+		t.Fatalf("unexpected archived contracts %v", contracts)
+	}
+	ac, err := scs.Archive().Fetch(ch.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch the archived contract: %s", err.Error())
+	}
+	if err := contractHeaderComparator(ac.Header, ch); err != nil {
+		t.Fatal(err)
+	}
+	if !hashSliceComparator(ac.Roots, rts) {
+		t.Fatalf("the archived roots do not match the roots inserted. Expected %v, got %v", rts, ac.Roots)
+	}
+	if _, err := scs.Archive().Fetch(storageContractIDGenerator()); err == nil {
+		t.Fatal("fetching the contract not archived shall fail")
+	}
+}
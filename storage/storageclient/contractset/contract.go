@@ -318,9 +318,25 @@ func (c *Contract) contractHeaderUpdate(newHeader ContractHeader) (err error) {
 
 	// update the contract in memory
 	c.headerLock.Lock()
+	oldRevisionNumber := c.header.LatestContractRevision.NewRevisionNumber
 	c.header = newHeader
 	c.headerLock.Unlock()
 
+	// record the remaining fund once the contract is revised, and drop the records
+	// of the revisions rolled back
+	newRevisionNumber := newHeader.LatestContractRevision.NewRevisionNumber
+	if newRevisionNumber > oldRevisionNumber {
+		if record, ok := newFundRecord(newHeader); ok {
+			if errRecord := c.db.StoreFundRecord(newHeader.ID, record); errRecord != nil {
+				log.Warn("failed to save the contract fund record", "id", newHeader.ID, "err", errRecord)
+			}
+		}
+	} else if newRevisionNumber < oldRevisionNumber {
+		if errRecord := c.db.DropFundRecordsAfter(newHeader.ID, newRevisionNumber); errRecord != nil {
+			log.Warn("failed to drop the rolled back fund records", "id", newHeader.ID, "err", errRecord)
+		}
+	}
+
 	return
 }
 
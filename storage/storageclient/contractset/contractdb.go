@@ -81,6 +81,11 @@ func (db *DB) DeleteHeaderAndRoots(id storage.ContractID) (err error) {
 		return
 	}
 
+	// delete contract fund history from the database
+	if err = db.DeleteFundHistory(id); err != nil {
+		return
+	}
+
 	return
 }
 
@@ -88,7 +93,7 @@ func (db *DB) DeleteHeaderAndRoots(id storage.ContractID) (err error) {
 func (db *DB) FetchAllContractID() (ids []storage.ContractID) {
 	iter := db.lvl.NewIterator(nil, nil)
 	for iter.Next() {
-		if bytes.HasSuffix(iter.Key(), []byte(dbMerkleRoot)) {
+		if !bytes.HasSuffix(iter.Key(), []byte(dbContractHeader)) {
 			continue
 		}
 
@@ -166,10 +171,13 @@ func (db *DB) FetchAllRoots() (allRoots [][]common.Hash, err error) {
 	return
 }
 
-// EmptyDB will clear all db entries
+// EmptyDB will clear all db entries except for the schema version
 func (db *DB) EmptyDB() (err error) {
 	iter := db.lvl.NewIterator(nil, nil)
 	for iter.Next() {
+		if bytes.Equal(iter.Key(), []byte(dbSchemaVersionKey)) {
+			continue
+		}
 		if err = db.lvl.Delete(iter.Key(), nil); err != nil {
 			return
 		}
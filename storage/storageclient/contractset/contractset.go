@@ -5,6 +5,7 @@
 package contractset
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -12,7 +13,9 @@ import (
 	"sync"
 
 	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/common/filelock"
 	"github.com/DxChainNetwork/godx/common/writeaheadlog"
+	"github.com/DxChainNetwork/godx/log"
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage"
 	dberrors "github.com/syndtr/goleveldb/leveldb/errors"
@@ -27,6 +30,15 @@ type StorageContractSet struct {
 	lock             sync.Mutex
 	rl               *RateLimit
 	wal              *writeaheadlog.Wal
+	archive          *ContractArchive
+	fileLock         *filelock.Lock
+}
+
+// walInsertContractEntry is the wal entry used to insert the contract
+// header and merkle roots atomically
+type walInsertContractEntry struct {
+	Header ContractHeader
+	Roots  []common.Hash
 }
 
 // New will initialize the StorageContractSet object, as well as
@@ -38,8 +50,19 @@ func New(persistDir string) (scs *StorageContractSet, err error) {
 		return
 	}
 
-	// initialize DB
-	db, err := OpenDB(filepath.Join(persistDir, persistDBName))
+	// lock the database and the wal, so that they are not opened by another process
+	fileLock, err := filelock.Acquire(filepath.Join(persistDir, persistLockName))
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			fileLock.Release()
+		}
+	}()
+
+	// initialize DB, migrate the database to the latest schema version if needed
+	db, err := openAndMigrateDB(filepath.Join(persistDir, persistDBName))
 	if err != nil {
 		err = fmt.Errorf("error initializing database: %s", err.Error())
 		return
@@ -58,11 +81,18 @@ func New(persistDir string) (scs *StorageContractSet, err error) {
 		persistDir:       persistDir,
 		db:               db,
 		wal:              wal,
+		fileLock:         fileLock,
 	}
 
 	// initialize rateLimit object
 	scs.rl = NewRateLimit(0, 0, 0)
 
+	// open the archive of the expired contracts
+	if scs.archive, err = openContractArchive(filepath.Join(persistDir, persistArchiveDirName)); err != nil {
+		err = fmt.Errorf("error initializing contract archive: %s", err.Error())
+		return
+	}
+
 	// load the contracts from the database
 	if err = scs.loadContract(walTxns); err != nil {
 		err = fmt.Errorf("error loading contracts from the database: %s", err.Error())
@@ -72,13 +102,22 @@ func New(persistDir string) (scs *StorageContractSet, err error) {
 	return
 }
 
-// Close will close the database and closing the writeaheadlog
+// Close will close the database and closing the writeaheadlog, and release the lock
 func (scs *StorageContractSet) Close() (err error) {
 	scs.db.Close()
 	_, err = scs.wal.CloseIncomplete()
+	if errLock := scs.fileLock.Release(); err == nil {
+		err = errLock
+	}
 	return
 }
 
+// TakeoverLock removes the lock of the contract set left by another process, which must
+// not be running any more
+func TakeoverLock(persistDir string) error {
+	return filelock.Takeover(filepath.Join(persistDir, persistLockName))
+}
+
 // EmptyDB will clear all data stored in the contractset database
 func (scs *StorageContractSet) EmptyDB() (err error) {
 	err = scs.db.EmptyDB()
@@ -93,21 +132,49 @@ func (scs *StorageContractSet) InsertContract(ch ContractHeader, roots []common.
 		return
 	}
 
+	// record the insertion in the wal first, so that the contract header and the roots
+	// are either both saved or both recovered after the crash
+	txn, err := scs.insertContractTxn(ch, roots)
+	if err != nil {
+		err = fmt.Errorf("failed to record the contract insertion into wal: %s", err.Error())
+		return
+	}
+
 	// save the contract header and roots information
-	if err = scs.db.StoreContractHeader(ch); err != nil {
-		err = fmt.Errorf("failed to store contract header information into database: %s",
+	if err = scs.db.StoreHeaderAndRoots(ch, roots); err != nil {
+		err = fmt.Errorf("failed to store contract header and roots information into database: %s",
 			err.Error())
+		// the insertion failed, remove the partially saved data and release the transaction,
+		// so that the failed insertion is not replayed after restart
+		if errDelete := scs.db.DeleteHeaderAndRoots(ch.ID); errDelete != nil {
+			log.Warn("failed to remove the partially saved contract", "id", ch.ID, "err", errDelete)
+		}
+		if errRelease := txn.Release(); errRelease != nil {
+			err = fmt.Errorf("%s; failed to release the contract insertion transaction: %s",
+				err.Error(), errRelease.Error())
+		}
 		return
 	}
 
-	// add the root to db as well as memory merkle tree
-	merkleRoots := newMerkleRoots(scs.db, ch.ID)
-	for _, root := range roots {
-		if err = merkleRoots.push(root); err != nil {
-			return
+	// the insertion is fully applied, release the transaction
+	if err = txn.Release(); err != nil {
+		err = fmt.Errorf("failed to release the contract insertion transaction: %s", err.Error())
+		return
+	}
+
+	// record the initial fund of the contract
+	if record, ok := newFundRecord(ch); ok {
+		if errRecord := scs.db.StoreFundRecord(ch.ID, record); errRecord != nil {
+			log.Warn("failed to save the contract fund record", "id", ch.ID, "err", errRecord)
 		}
 	}
 
+	// add the root to memory merkle tree
+	merkleRoots, err := loadMerkleRoots(scs.db, ch.ID, roots)
+	if err != nil {
+		return
+	}
+
 	// initialize contract
 	c := &Contract{
 		header:      ch,
@@ -233,9 +300,76 @@ func (scs *StorageContractSet) RetrieveAllContractsMetaData() (cms []storage.Con
 	return
 }
 
+// insertContractTxn will create and commit the wal transaction that records
+// the contract header and roots to be inserted
+func (scs *StorageContractSet) insertContractTxn(ch ContractHeader, roots []common.Hash) (txn *writeaheadlog.Transaction, err error) {
+	data, err := json.Marshal(walInsertContractEntry{
+		Header: ch,
+		Roots:  roots,
+	})
+	if err != nil {
+		return
+	}
+
+	op := writeaheadlog.Operation{
+		Name: walInsertContract,
+		Data: data,
+	}
+	if txn, err = scs.wal.NewTransaction([]writeaheadlog.Operation{op}); err != nil {
+		return
+	}
+	if err = <-txn.Commit(); err != nil {
+		return
+	}
+	return
+}
+
+// recoverInsertContract will re-apply the contract insertion recorded in the wal
+// transactions, which is not finished before the program stopped. The insertion
+// will then be verified against the database before the transaction released.
+// Transactions not related to contract insertion will be returned
+func (scs *StorageContractSet) recoverInsertContract(walTxns []*writeaheadlog.Transaction) (remain []*writeaheadlog.Transaction, err error) {
+	for _, txn := range walTxns {
+		if len(txn.Operations) != 1 || txn.Operations[0].Name != walInsertContract {
+			remain = append(remain, txn)
+			continue
+		}
+
+		var entry walInsertContractEntry
+		if err = json.Unmarshal(txn.Operations[0].Data, &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode the contract insertion entry: %s", err.Error())
+		}
+
+		// the operation is idempotent, header and roots will be overwritten
+		if err = scs.db.StoreHeaderAndRoots(entry.Header, entry.Roots); err != nil {
+			return nil, fmt.Errorf("failed to recover the contract insertion: %s", err.Error())
+		}
+
+		// verify the recovered contract
+		ch, roots, err := scs.db.FetchHeaderAndRoots(entry.Header.ID)
+		if err != nil && err != dberrors.ErrNotFound {
+			return nil, fmt.Errorf("failed to verify the recovered contract %v: %s", entry.Header.ID, err.Error())
+		}
+		if ch.ID != entry.Header.ID || len(roots) != len(entry.Roots) {
+			return nil, fmt.Errorf("recovered contract %v does not match with the wal record", entry.Header.ID)
+		}
+
+		if err = txn.Release(); err != nil {
+			return nil, fmt.Errorf("failed to release the recovered contract insertion transaction: %s", err.Error())
+		}
+		log.Info("Recovered the interrupted contract insertion", "contractID", entry.Header.ID)
+	}
+	return
+}
+
 // loadContract will load contracts information from the database, it will also
 // filter out the un-applied transaction for the particular contract
 func (scs *StorageContractSet) loadContract(walTxns []*writeaheadlog.Transaction) (err error) {
+	// finish the contract insertion interrupted before loading the contracts
+	if walTxns, err = scs.recoverInsertContract(walTxns); err != nil {
+		return
+	}
+
 	// get all the contract id
 	ids := scs.db.FetchAllContractID()
 
@@ -5,6 +5,7 @@
 package contractset
 
 import (
+	"os"
 	"path/filepath"
 	"sync"
 	"testing"
@@ -88,7 +89,7 @@ func TestStorageContractSet_New(t *testing.T) {
 
 		// emptyDB
 		scs.db.EmptyDB()
-		scs.db.Close()
+		scs.Close()
 
 	}
 }
@@ -139,6 +140,81 @@ func TestStorageContractSet_InsertContract(t *testing.T) {
 
 }
 
+func TestStorageContractSet_InsertContractRecover(t *testing.T) {
+	dir := filepath.Join(persistDir, "recover")
+	defer os.RemoveAll(dir)
+
+	scs, err := New(dir)
+	if err != nil {
+		t.Fatalf("failed to initialize storage contract set: %s", err.Error())
+	}
+
+	// record the insertion in the wal, but crash before it is applied
+	ch := contractHeaderGenerator()
+	rts := rootsGenerator(158)
+	if _, err := scs.insertContractTxn(ch, rts); err != nil {
+		t.Fatalf("failed to record the contract insertion: %s", err.Error())
+	}
+	if _, err := scs.db.FetchContractHeader(ch.ID); err == nil {
+		t.Fatalf("contract header should not be stored before the insertion applied")
+	}
+	scs.Close()
+
+	// reopen the contract set, the insertion should be recovered
+	if scs, err = New(dir); err != nil {
+		t.Fatalf("failed to reopen storage contract set: %s", err.Error())
+	}
+	defer scs.Close()
+
+	if _, exists := scs.contracts[ch.ID]; !exists {
+		t.Fatalf("the contract %v is not recovered", ch.ID)
+	}
+	fetchedRoot, err := scs.db.FetchMerkleRoots(ch.ID)
+	if err != nil {
+		t.Fatalf("failed to get the contract merkle root information from the db %s", err.Error())
+	}
+	if !hashSliceComparator(fetchedRoot, rts) {
+		t.Fatalf("the recovered root does not match the root inserted. Expected %v, got %v",
+			rts, fetchedRoot)
+	}
+	if scs.contracts[ch.ID].merkleRoots.len() != len(rts) {
+		t.Fatalf("the number of recovered merkle roots expected %v, got %v", len(rts),
+			scs.contracts[ch.ID].merkleRoots.len())
+	}
+}
+
+func TestStorageContractSet_InsertContractFailed(t *testing.T) {
+	dir := filepath.Join(persistDir, "insertfail")
+	defer os.RemoveAll(dir)
+
+	scs, err := New(dir)
+	if err != nil {
+		t.Fatalf("failed to initialize storage contract set: %s", err.Error())
+	}
+
+	// close the database, so that saving the contract fails
+	ch := contractHeaderGenerator()
+	rts := rootsGenerator(158)
+	scs.db.lvl.Close()
+	if _, err := scs.InsertContract(ch, rts); err == nil {
+		t.Fatalf("the contract insertion is expected to fail")
+	}
+	scs.Close()
+
+	// reopen the contract set, the failed insertion should not be replayed
+	if scs, err = New(dir); err != nil {
+		t.Fatalf("failed to reopen storage contract set: %s", err.Error())
+	}
+	defer scs.Close()
+
+	if _, exists := scs.contracts[ch.ID]; exists {
+		t.Fatalf("the failed insertion of contract %v is replayed", ch.ID)
+	}
+	if _, err := scs.db.FetchContractHeader(ch.ID); err == nil {
+		t.Fatalf("the contract header of the failed insertion should not be saved")
+	}
+}
+
 func TestStorageContractSet_InsertContractMultiRoutines(t *testing.T) {
 	var wg sync.WaitGroup
 
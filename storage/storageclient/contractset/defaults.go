@@ -10,13 +10,44 @@ import (
 
 // defines the database and file related constants
 const (
-	persistDBName  = "contractsetdb"
-	persistWalName = "contractset.wal"
+	persistDBName   = "contractsetdb"
+	persistWalName  = "contractset.wal"
+	persistLockName = "contractset.lock"
 
 	dbContractHeader = ":contractheader"
 	dbMerkleRoot     = ":roots"
+	dbFundRecord     = ":fundrecord"
+
+	// walInsertContract is the wal operation name for inserting the contract
+	// header and merkle roots as a whole
+	walInsertContract = ":insertcontract"
+
+	// dbSchemaVersionKey is the key of the contract set database schema version
+	dbSchemaVersionKey = "contractset:schemaversion"
+
+	// dbHostBackfillKey is the key of the host announcement backfill checkpoint
+	dbHostBackfillKey = "contractset:hostbackfill"
+
+	// persistDBBackupSuffix is the suffix of the database backup made before migration
+	persistDBBackupSuffix = ".bak"
+)
+
+// defines the contract archive related constants
+const (
+	ArchiveIndexHeader  = "Storage Contract Archive Index"
+	ArchiveIndexVersion = "1.0"
+
+	persistArchiveDirName = "archive"
+	archiveIndexName      = "index.json"
+	archiveExt            = ".json.gz"
+	archiveTmpSuffix      = ".tmp"
 )
 
+// dbSchemaVersion is the current contract set database schema version. Each
+// time the persisted format is changed, the version must be increased, and a
+// migration routine from the previous version must be registered in migrations
+const dbSchemaVersion uint32 = 1
+
 const (
 	// the height of the merkle tree is 7, meaning it can store
 	// 128 merkle roots
@@ -25,6 +56,9 @@ const (
 	// number of merkle roots in a cached tree is 128
 	merkleRootsPerCache = 1 << merkleRootsCacheHeight
 
+	// maxFundRecords is the maximum number of fund records kept for a contract
+	maxFundRecords = 1024
+
 	// SectorSize is used to define the size of data sector, which is 4 MiB
 	SectorSize    = uint64(1 << 22)
 	remainingFile = -1
@@ -0,0 +1,176 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractset
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// FundRecord records the remaining fund of the storage client in the contract
+// after a contract revision is committed
+type FundRecord struct {
+	Timestamp      time.Time     `json:"timestamp"`
+	RevisionNumber uint64        `json:"revisionnumber"`
+	Remaining      common.BigInt `json:"remaining"`
+}
+
+// newFundRecord creates the fund record based on the latest contract revision
+// of the contract header. If the revision has no valid proof outputs, false is returned
+func newFundRecord(ch ContractHeader) (record FundRecord, ok bool) {
+	rev := ch.LatestContractRevision
+	if len(rev.NewValidProofOutputs) == 0 || rev.NewValidProofOutputs[0].Value == nil {
+		return
+	}
+	record = FundRecord{
+		Timestamp:      time.Now(),
+		RevisionNumber: rev.NewRevisionNumber,
+		Remaining:      common.PtrBigInt(rev.NewValidProofOutputs[0].Value),
+	}
+	return record, true
+}
+
+// FundHistory will return the fund records of the contract in chronological order
+func (c *Contract) FundHistory() (records []FundRecord, err error) {
+	c.headerLock.Lock()
+	id := c.header.ID
+	c.headerLock.Unlock()
+
+	return c.db.FetchFundHistory(id)
+}
+
+// RetrieveFundHistory will return the fund records of the contract with the id provided
+func (scs *StorageContractSet) RetrieveFundHistory(id storage.ContractID) (records []FundRecord, exist bool, err error) {
+	scs.lock.Lock()
+	contract, exist := scs.contracts[id]
+	scs.lock.Unlock()
+
+	if !exist {
+		return
+	}
+	records, err = contract.FundHistory()
+	return
+}
+
+// StoreFundRecord will append the fund record to the fund history of the contract. Each
+// record is stored under its own key with the sequence number following the last record,
+// and once the number of records exceeds maxFundRecords, the oldest records are dropped
+func (db *DB) StoreFundRecord(id storage.ContractID, record FundRecord) (err error) {
+	prefix, err := makeKey(id, dbFundRecord)
+	if err != nil {
+		return
+	}
+	blob, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	iter := db.lvl.NewIterator(util.BytesPrefix(prefix), nil)
+	var first, seq uint64
+	if iter.Last() {
+		seq = fundRecordSeq(iter.Key()) + 1
+		iter.First()
+		first = fundRecordSeq(iter.Key())
+	}
+	iter.Release()
+	if err = iter.Error(); err != nil {
+		return
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Put(fundRecordKey(prefix, seq), blob)
+	for ; first+maxFundRecords <= seq; first++ {
+		batch.Delete(fundRecordKey(prefix, first))
+	}
+	return db.lvl.Write(batch, nil)
+}
+
+// DropFundRecordsAfter will remove the fund records with revision number larger
+// than the revision number provided
+func (db *DB) DropFundRecordsAfter(id storage.ContractID, revisionNumber uint64) (err error) {
+	prefix, err := makeKey(id, dbFundRecord)
+	if err != nil {
+		return
+	}
+
+	iter := db.lvl.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+	batch := new(leveldb.Batch)
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		var record FundRecord
+		if err = json.Unmarshal(iter.Value(), &record); err != nil {
+			return
+		}
+		if record.RevisionNumber <= revisionNumber {
+			break
+		}
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+	if err = iter.Error(); err != nil || batch.Len() == 0 {
+		return
+	}
+	return db.lvl.Write(batch, nil)
+}
+
+// FetchFundHistory will retrieve the fund records of the contract. If no records
+// were stored, an empty list will be returned
+func (db *DB) FetchFundHistory(id storage.ContractID) (records []FundRecord, err error) {
+	prefix, err := makeKey(id, dbFundRecord)
+	if err != nil {
+		return
+	}
+
+	iter := db.lvl.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+	for iter.Next() {
+		var record FundRecord
+		if err = json.Unmarshal(iter.Value(), &record); err != nil {
+			return
+		}
+		records = append(records, record)
+	}
+	err = iter.Error()
+	return
+}
+
+// DeleteFundHistory will delete the fund records of the contract
+func (db *DB) DeleteFundHistory(id storage.ContractID) (err error) {
+	prefix, err := makeKey(id, dbFundRecord)
+	if err != nil {
+		return
+	}
+
+	iter := db.lvl.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+	if err = iter.Error(); err != nil {
+		return
+	}
+	return db.lvl.Write(batch, nil)
+}
+
+// fundRecordKey makes the key of the fund record with the sequence number, which is the
+// fund record prefix of the contract followed by the big endian sequence number, so that
+// the records are iterated in the order they are stored
+func fundRecordKey(prefix []byte, seq uint64) []byte {
+	key := make([]byte, len(prefix)+8)
+	copy(key, prefix)
+	binary.BigEndian.PutUint64(key[len(prefix):], seq)
+	return key
+}
+
+// fundRecordSeq returns the sequence number of the fund record key
+func fundRecordSeq(key []byte) uint64 {
+	return binary.BigEndian.Uint64(key[len(key)-8:])
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+)
+
+func TestDB_StoreFetchDropFundHistory(t *testing.T) {
+	db, err := OpenDB(testDB)
+	if err != nil {
+		t.Fatalf("failed to open / create a contractset database: %s", err.Error())
+	}
+	defer db.Close()
+	defer db.EmptyDB()
+
+	id := storageContractIDGenerator()
+
+	// no fund history stored yet
+	records, err := db.FetchFundHistory(id)
+	if err != nil {
+		t.Fatalf("failed to fetch the empty fund history: %s", err.Error())
+	}
+	if len(records) != 0 {
+		t.Fatalf("expected no fund records, got %v", len(records))
+	}
+
+	// store the fund records
+	for i := 0; i < 10; i++ {
+		record := FundRecord{
+			Timestamp:      time.Now(),
+			RevisionNumber: uint64(i + 1),
+			Remaining:      common.NewBigInt(int64(1000 - i*100)),
+		}
+		if err := db.StoreFundRecord(id, record); err != nil {
+			t.Fatalf("failed to store the fund record: %s", err.Error())
+		}
+	}
+	if records, err = db.FetchFundHistory(id); err != nil {
+		t.Fatalf("failed to fetch the fund history: %s", err.Error())
+	}
+	if len(records) != 10 {
+		t.Fatalf("expected 10 fund records, got %v", len(records))
+	}
+	if !records[9].Remaining.IsEqual(common.NewBigInt(100)) {
+		t.Errorf("expected the last remaining fund 100, got %v", records[9].Remaining)
+	}
+
+	// drop the records of the rolled back revisions
+	if err := db.DropFundRecordsAfter(id, 6); err != nil {
+		t.Fatalf("failed to drop the fund records: %s", err.Error())
+	}
+	if records, err = db.FetchFundHistory(id); err != nil {
+		t.Fatalf("failed to fetch the fund history: %s", err.Error())
+	}
+	if len(records) != 6 || records[5].RevisionNumber != 6 {
+		t.Fatalf("expected 6 fund records after dropping, got %v", len(records))
+	}
+
+	// delete the fund history
+	if err := db.DeleteFundHistory(id); err != nil {
+		t.Fatalf("failed to delete the fund history: %s", err.Error())
+	}
+	if records, err = db.FetchFundHistory(id); err != nil || len(records) != 0 {
+		t.Fatalf("the fund history should be deleted, got %v records, err %v", len(records), err)
+	}
+}
+
+func TestDB_StoreFundRecordPrune(t *testing.T) {
+	db, err := OpenDB(testDB)
+	if err != nil {
+		t.Fatalf("failed to open / create a contractset database: %s", err.Error())
+	}
+	defer db.Close()
+	defer db.EmptyDB()
+
+	id := storageContractIDGenerator()
+	for i := 0; i < maxFundRecords+5; i++ {
+		record := FundRecord{
+			Timestamp:      time.Now(),
+			RevisionNumber: uint64(i + 1),
+			Remaining:      common.NewBigInt(int64(i)),
+		}
+		if err := db.StoreFundRecord(id, record); err != nil {
+			t.Fatalf("failed to store the fund record: %s", err.Error())
+		}
+	}
+	records, err := db.FetchFundHistory(id)
+	if err != nil {
+		t.Fatalf("failed to fetch the fund history: %s", err.Error())
+	}
+	if len(records) != maxFundRecords {
+		t.Fatalf("expected %v fund records, got %v", maxFundRecords, len(records))
+	}
+	if records[0].RevisionNumber != 6 || records[maxFundRecords-1].RevisionNumber != maxFundRecords+5 {
+		t.Errorf("unexpected revision range %v - %v", records[0].RevisionNumber, records[maxFundRecords-1].RevisionNumber)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractset
+
+import (
+	"encoding/json"
+
+	"github.com/syndtr/goleveldb/leveldb/errors"
+)
+
+// HostBackfillCheckpoint records the progress of scanning the historical blocks
+// for the storage host announcements, so that the scan can be resumed after restart
+type HostBackfillCheckpoint struct {
+	// NextHeight is the height of the next block to be scanned
+	NextHeight uint64 `json:"nextheight"`
+
+	// TargetHeight is the height of the last block to be scanned
+	TargetHeight uint64 `json:"targetheight"`
+
+	// Announcements is the number of host announcements found so far
+	Announcements uint64 `json:"announcements"`
+}
+
+// Finished checks if all the blocks up to the target height have been scanned
+func (cp HostBackfillCheckpoint) Finished() bool {
+	return cp.NextHeight > cp.TargetHeight
+}
+
+// StoreHostBackfillCheckpoint will store the host announcement backfill checkpoint
+func (scs *StorageContractSet) StoreHostBackfillCheckpoint(cp HostBackfillCheckpoint) (err error) {
+	return scs.db.StoreHostBackfillCheckpoint(cp)
+}
+
+// FetchHostBackfillCheckpoint will retrieve the host announcement backfill checkpoint.
+// If no checkpoint was stored, false will be returned
+func (scs *StorageContractSet) FetchHostBackfillCheckpoint() (cp HostBackfillCheckpoint, exist bool, err error) {
+	return scs.db.FetchHostBackfillCheckpoint()
+}
+
+// StoreHostBackfillCheckpoint will store the host announcement backfill checkpoint into the database
+func (db *DB) StoreHostBackfillCheckpoint(cp HostBackfillCheckpoint) (err error) {
+	blob, err := json.Marshal(cp)
+	if err != nil {
+		return
+	}
+	return db.lvl.Put([]byte(dbHostBackfillKey), blob, nil)
+}
+
+// FetchHostBackfillCheckpoint will retrieve the host announcement backfill checkpoint
+// from the database. If no checkpoint was stored, false will be returned
+func (db *DB) FetchHostBackfillCheckpoint() (cp HostBackfillCheckpoint, exist bool, err error) {
+	blob, err := db.lvl.Get([]byte(dbHostBackfillKey), nil)
+	if err == errors.ErrNotFound {
+		return cp, false, nil
+	} else if err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(blob, &cp); err != nil {
+		return
+	}
+	return cp, true, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractset
+
+import (
+	"testing"
+)
+
+func TestDB_StoreFetchHostBackfillCheckpoint(t *testing.T) {
+	db, err := OpenDB(testDB)
+	if err != nil {
+		t.Fatalf("failed to open / create a contractset database: %s", err.Error())
+	}
+	defer db.Close()
+	defer db.EmptyDB()
+
+	// no checkpoint stored yet
+	if _, exist, err := db.FetchHostBackfillCheckpoint(); err != nil || exist {
+		t.Fatalf("expected no checkpoint, got exist %v, err %v", exist, err)
+	}
+
+	cp := HostBackfillCheckpoint{
+		NextHeight:    101,
+		TargetHeight:  500,
+		Announcements: 3,
+	}
+	if err := db.StoreHostBackfillCheckpoint(cp); err != nil {
+		t.Fatalf("failed to store the checkpoint: %s", err.Error())
+	}
+
+	fetched, exist, err := db.FetchHostBackfillCheckpoint()
+	if err != nil || !exist {
+		t.Fatalf("failed to fetch the checkpoint: exist %v, err %v", exist, err)
+	}
+	if fetched != cp {
+		t.Fatalf("checkpoint not match: expected %+v, got %+v", cp, fetched)
+	}
+	if fetched.Finished() {
+		t.Fatalf("the checkpoint should not be finished")
+	}
+
+	// the checkpoint should not be treated as contract entries
+	if ids := db.FetchAllContractID(); len(ids) != 0 {
+		t.Fatalf("expected no contract ids, got %v", len(ids))
+	}
+
+	cp.NextHeight = cp.TargetHeight + 1
+	if err := db.StoreHostBackfillCheckpoint(cp); err != nil {
+		t.Fatalf("failed to update the checkpoint: %s", err.Error())
+	}
+	if fetched, _, _ = db.FetchHostBackfillCheckpoint(); !fetched.Finished() {
+		t.Fatalf("the checkpoint should be finished")
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package contractset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/syndtr/goleveldb/leveldb/errors"
+)
+
+// migration defines the routine used to migrate the contract set database
+// from the schema version to the next version
+type migration struct {
+	from    uint32
+	migrate func(db *DB) error
+}
+
+// migrations contains all migration routines, ordered by the version migrated from.
+// Version 0 is the legacy database which does not have the schema version recorded
+var migrations = []migration{
+	{from: 0, migrate: migrateV0ToV1},
+}
+
+// SchemaVersion will return the schema version of the contract set database. If no
+// schema version is recorded, the database is treated as version 0
+func (db *DB) SchemaVersion() (version uint32, err error) {
+	blob, err := db.lvl.Get([]byte(dbSchemaVersionKey), nil)
+	if err == errors.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return
+	}
+
+	if len(blob) != 4 {
+		err = fmt.Errorf("invalid schema version length %v", len(blob))
+		return
+	}
+	version = binary.BigEndian.Uint32(blob)
+	return
+}
+
+// storeSchemaVersion will record the schema version into the contract set database
+func (db *DB) storeSchemaVersion(version uint32) (err error) {
+	blob := make([]byte, 4)
+	binary.BigEndian.PutUint32(blob, version)
+	return db.lvl.Put([]byte(dbSchemaVersionKey), blob, nil)
+}
+
+// openAndMigrateDB will open the contract set database, and migrate it to the
+// latest schema version. Before the migration, the database will be backed up
+func openAndMigrateDB(path string) (db *DB, err error) {
+	if db, err = OpenDB(path); err != nil {
+		return
+	}
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read the schema version: %s", err.Error())
+	}
+
+	// newer database cannot be handled by the current program
+	if version > dbSchemaVersion {
+		db.Close()
+		return nil, fmt.Errorf("contract set database schema version %v is newer than the supported version %v",
+			version, dbSchemaVersion)
+	}
+
+	if version == dbSchemaVersion {
+		return
+	}
+
+	// empty database does not need to be migrated or backed up
+	if db.isEmpty() {
+		if err = db.storeSchemaVersion(dbSchemaVersion); err != nil {
+			db.Close()
+			return nil, err
+		}
+		return
+	}
+
+	// close the database and back it up before migration
+	db.Close()
+	backupPath := fmt.Sprintf("%s.v%d%s", path, version, persistDBBackupSuffix)
+	if err = backupDB(path, backupPath); err != nil {
+		return nil, fmt.Errorf("failed to back up the contract set database before migration: %s", err.Error())
+	}
+	log.Info("Contract set database backed up before migration", "backup", backupPath)
+
+	if db, err = OpenDB(path); err != nil {
+		return
+	}
+
+	// run the migration routines one by one. The schema version will be updated after
+	// each migration, so that interrupted migration can be resumed from where it stopped
+	for _, m := range migrations {
+		if m.from < version {
+			continue
+		}
+		if err = m.migrate(db); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to migrate the contract set database from version %v: %s", m.from, err.Error())
+		}
+		if err = db.storeSchemaVersion(m.from + 1); err != nil {
+			db.Close()
+			return nil, err
+		}
+		log.Info("Contract set database migrated", "from", m.from, "to", m.from+1)
+	}
+
+	return
+}
+
+// isEmpty checks if there is no entry other than the schema version stored in the database
+func (db *DB) isEmpty() bool {
+	iter := db.lvl.NewIterator(nil, nil)
+	defer iter.Release()
+	for iter.Next() {
+		if !bytes.Equal(iter.Key(), []byte(dbSchemaVersionKey)) {
+			return false
+		}
+	}
+	return true
+}
+
+// migrateV0ToV1 migrates the legacy database to version 1. Every contract header will
+// be validated to be decodable, and merkle roots entries that do not belong to any
+// contract header, which are left behind by interrupted contract insertion or deletion,
+// will be removed
+func migrateV0ToV1(db *DB) (err error) {
+	if _, err = db.FetchAllHeader(); err != nil {
+		return fmt.Errorf("failed to decode the contract header: %s", err.Error())
+	}
+
+	headers := make(map[string]struct{})
+	for _, id := range db.FetchAllContractID() {
+		headers[string(id[:])] = struct{}{}
+	}
+
+	iter := db.lvl.NewIterator(nil, nil)
+	defer iter.Release()
+	for iter.Next() {
+		if !bytes.HasSuffix(iter.Key(), []byte(dbMerkleRoot)) {
+			continue
+		}
+		id, _ := splitKey(iter.Key())
+		if _, exists := headers[string(id[:])]; exists {
+			continue
+		}
+		if err = db.lvl.Delete(iter.Key(), nil); err != nil {
+			return
+		}
+	}
+	return iter.Error()
+}
+
+// backupDB copies the database directory to the backup directory. If the backup
+// directory already exists, it will be replaced
+func backupDB(src, dst string) (err error) {
+	if err = os.RemoveAll(dst); err != nil {
+		return
+	}
+	if err = os.MkdirAll(dst, 0700); err != nil {
+		return
+	}
+
+	files, err := filepath.Glob(filepath.Join(src, "*"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		if err = copyFile(file, filepath.Join(dst, filepath.Base(file))); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// copyFile copies the file from src to dst
+func copyFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return
+	}
+	if err = out.Sync(); err != nil {
+		out.Close()
+		return
+	}
+	return out.Close()
+}
@@ -0,0 +1,146 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package contractset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestOpenAndMigrateDB_V0 creates a legacy version 0 database, and checks that it
+// is migrated to the latest version with data kept and the backup created
+func TestOpenAndMigrateDB_V0(t *testing.T) {
+	dir := filepath.Join(persistDir, "migration")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, persistDBName)
+
+	// create the version 0 database, which has no schema version recorded
+	db, err := OpenDB(path)
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err.Error())
+	}
+	chs := contractHeaderBatchGenerator(10)
+	allRoots := contractRootBatchGenerator(10)
+	for i := range chs {
+		if err := db.StoreHeaderAndRoots(chs[i], allRoots[i]); err != nil {
+			t.Fatalf("failed to store the contract: %s", err.Error())
+		}
+	}
+	orphanID := storageContractIDGenerator()
+	if err := db.StoreMerkleRoots(orphanID, contractRootsGenerator()); err != nil {
+		t.Fatalf("failed to store the orphan roots: %s", err.Error())
+	}
+	db.Close()
+
+	// open with migration
+	if db, err = openAndMigrateDB(path); err != nil {
+		t.Fatalf("failed to migrate the database: %s", err.Error())
+	}
+	defer db.Close()
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("failed to get the schema version: %s", err.Error())
+	}
+	if version != dbSchemaVersion {
+		t.Fatalf("schema version not match, expected %v, got %v", dbSchemaVersion, version)
+	}
+
+	// contract information must be kept after the migration
+	fetched, err := db.FetchAllHeader()
+	if err != nil {
+		t.Fatalf("failed to fetch the headers: %s", err.Error())
+	}
+	if err := headerSliceValidation(chs, fetched); err != nil {
+		t.Fatalf("header validation failed after migration: %s", err.Error())
+	}
+	for i, ch := range chs {
+		roots, err := db.FetchMerkleRoots(ch.ID)
+		if err != nil {
+			t.Fatalf("failed to fetch the roots: %s", err.Error())
+		}
+		if !hashSliceComparator(roots, allRoots[i]) {
+			t.Fatalf("roots of contract %v not match after migration", ch.ID)
+		}
+	}
+
+	// orphan roots must be removed
+	if _, err := db.FetchMerkleRoots(orphanID); err == nil {
+		t.Fatalf("orphan merkle roots are not removed during the migration")
+	}
+
+	// the pre-migration backup must be created, and still be a version 0 database
+	backup, err := OpenDB(fmt.Sprintf("%s.v%d%s", path, 0, persistDBBackupSuffix))
+	if err != nil {
+		t.Fatalf("failed to open the backup database: %s", err.Error())
+	}
+	defer backup.Close()
+	if version, err := backup.SchemaVersion(); err != nil || version != 0 {
+		t.Fatalf("backup database version expected 0, got %v, err %v", version, err)
+	}
+	if ids := backup.FetchAllContractID(); len(ids) != len(chs) {
+		t.Fatalf("backup database expected %v contracts, got %v", len(chs), len(ids))
+	}
+}
+
+// TestOpenAndMigrateDB_RoundTrip checks that the latest version database can be
+// reopened without migration, and the empty database is stamped directly
+func TestOpenAndMigrateDB_RoundTrip(t *testing.T) {
+	dir := filepath.Join(persistDir, "roundtrip")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, persistDBName)
+
+	db, err := openAndMigrateDB(path)
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err.Error())
+	}
+	ch := contractHeaderGenerator()
+	if err := db.StoreContractHeader(ch); err != nil {
+		t.Fatalf("failed to store the header: %s", err.Error())
+	}
+	db.Close()
+
+	if db, err = openAndMigrateDB(path); err != nil {
+		t.Fatalf("failed to reopen the database: %s", err.Error())
+	}
+	if version, err := db.SchemaVersion(); err != nil || version != dbSchemaVersion {
+		t.Fatalf("schema version expected %v, got %v, err %v", dbSchemaVersion, version, err)
+	}
+	if _, err := db.FetchContractHeader(ch.ID); err != nil {
+		t.Fatalf("failed to fetch the header after reopen: %s", err.Error())
+	}
+	db.Close()
+
+	// empty database should not be backed up
+	if _, err := os.Stat(fmt.Sprintf("%s.v%d%s", path, 0, persistDBBackupSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("empty database should not be backed up")
+	}
+}
+
+// TestOpenAndMigrateDB_Newer checks that database with newer schema version is refused
+func TestOpenAndMigrateDB_Newer(t *testing.T) {
+	dir := filepath.Join(persistDir, "newer")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, persistDBName)
+
+	db, err := OpenDB(path)
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err.Error())
+	}
+	if err := db.storeSchemaVersion(dbSchemaVersion + 1); err != nil {
+		t.Fatalf("failed to store the schema version: %s", err.Error())
+	}
+	db.Close()
+
+	if db, err = openAndMigrateDB(path); err == nil {
+		db.Close()
+		t.Fatalf("database with newer schema version should not be opened")
+	}
+}
+
+// TestMigrateV1ToV2 checks that the fund history stored as a whole is split into the
+// fund records by the migration
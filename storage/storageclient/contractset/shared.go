@@ -0,0 +1,137 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package contractset
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+var (
+	// errSharedRevisionSignature is the error that the latest revision of the shared contract
+	// is not signed by both the storage client and the storage host
+	errSharedRevisionSignature = errors.New("the shared contract revision is not signed by both the client and the host")
+
+	// errSharedMerkleRoot is the error that the merkle roots shared do not match the file
+	// merkle root of the latest revision
+	errSharedMerkleRoot = errors.New("the shared merkle roots do not match the file merkle root of the revision")
+)
+
+// SharedContract is the contract header and merkle roots shared between the nodes
+// coordinated as one logical storage client. Note that the contract header contains
+// the private key of the contract, which should be removed before the contract is
+// shared through the channel not encrypted
+type SharedContract struct {
+	Header ContractHeader `json:"header"`
+	Roots  []common.Hash  `json:"roots"`
+}
+
+// ChangedContracts returns the contracts whose latest revision number differs from the
+// revision number recorded in the known map. Contracts not in the known map are
+// returned as well
+func (scs *StorageContractSet) ChangedContracts(known map[storage.ContractID]uint64) (changed []SharedContract, err error) {
+	scs.lock.Lock()
+	var contracts []*Contract
+	for id, c := range scs.contracts {
+		revision, exists := known[id]
+		if !exists || revision != c.Header().LatestContractRevision.NewRevisionNumber {
+			contracts = append(contracts, c)
+		}
+	}
+	scs.lock.Unlock()
+
+	for _, c := range contracts {
+		roots, err := c.MerkleRoots()
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, SharedContract{
+			Header: c.Header(),
+			Roots:  roots,
+		})
+	}
+	return
+}
+
+// WithoutPrivateKey returns the copy of the shared contract with the private key removed
+func (shared SharedContract) WithoutPrivateKey() SharedContract {
+	shared.Header.PrivateKey = ""
+	return shared
+}
+
+// ApplySharedContract merges the contract shared by another node into the contract set.
+// The latest revision shared must be signed by both the storage client and the storage
+// host, and the merkle roots shared must match its file merkle root. The contract not
+// known is inserted, and the contract known is updated only if the shared contract has
+// a higher revision number. The merkle roots uploaded by the other node are appended to
+// the local merkle roots, and the local private key of the contract is kept
+func (scs *StorageContractSet) ApplySharedContract(shared SharedContract) (applied bool, err error) {
+	if err = verifySharedContract(shared); err != nil {
+		return
+	}
+
+	c, exists := scs.Acquire(shared.Header.ID)
+	if !exists {
+		if _, err = scs.InsertContract(shared.Header, shared.Roots); err != nil {
+			return
+		}
+		return true, nil
+	}
+	defer scs.Return(c)
+
+	local := c.Header()
+	if shared.Header.LatestContractRevision.NewRevisionNumber <= local.LatestContractRevision.NewRevisionNumber {
+		return
+	}
+
+	numRoots := c.merkleRoots.len()
+	if len(shared.Roots) < numRoots {
+		err = fmt.Errorf("shared contract %v has %v merkle roots, less than the %v local roots",
+			shared.Header.ID, len(shared.Roots), numRoots)
+		return
+	}
+	for _, root := range shared.Roots[numRoots:] {
+		if err = c.merkleRoots.push(root); err != nil {
+			return
+		}
+	}
+	if shared.Header.PrivateKey == "" {
+		shared.Header.PrivateKey = local.PrivateKey
+	}
+	if err = c.contractHeaderUpdate(shared.Header); err != nil {
+		return
+	}
+	return true, nil
+}
+
+// verifySharedContract checks the signatures of the latest revision of the shared contract
+// against the payment addresses of its unlock conditions, and the merkle roots shared
+// against its file merkle root
+func verifySharedContract(shared SharedContract) error {
+	if err := shared.Header.validation(); err != nil {
+		return err
+	}
+	rev := shared.Header.LatestContractRevision
+	addresses := rev.UnlockConditions.PaymentAddresses
+	if len(rev.Signatures) != len(addresses) {
+		return errSharedRevisionSignature
+	}
+	hash := rev.RLPHash()
+	for i, addr := range addresses {
+		pubKey, err := crypto.SigToPub(hash.Bytes(), rev.Signatures[i])
+		if err != nil || crypto.PubkeyToAddress(*pubKey) != addr {
+			return errSharedRevisionSignature
+		}
+	}
+	if merkle.Sha256CachedTreeRoot(shared.Roots, sectorHeight) != rev.NewFileMerkleRoot {
+		return errSharedMerkleRoot
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package contractset
+
+import (
+	"crypto/ecdsa"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestStorageContractSet_ApplySharedContract(t *testing.T) {
+	dir := filepath.Join(persistDir, "shared")
+	defer os.RemoveAll(dir)
+
+	scs, err := New(dir)
+	if err != nil {
+		t.Fatalf("failed to initialize storage contract set: %s", err.Error())
+	}
+	defer scs.Close()
+
+	keys := sharedContractKeys(t)
+
+	// unknown contract is inserted
+	shared := SharedContract{Header: contractHeaderGenerator(), Roots: rootsGenerator(10)}
+	shared.Header.LatestContractRevision.NewRevisionNumber = 1
+	signSharedContract(t, &shared, keys)
+	if applied, err := scs.ApplySharedContract(shared); err != nil || !applied {
+		t.Fatalf("failed to apply the unknown shared contract: %v, %v", applied, err)
+	}
+
+	changed, err := scs.ChangedContracts(make(map[storage.ContractID]uint64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0].Header.ID != shared.Header.ID {
+		t.Fatalf("changed contracts not expected: %v", changed)
+	}
+	known := map[storage.ContractID]uint64{shared.Header.ID: 1}
+	if changed, _ := scs.ChangedContracts(known); len(changed) != 0 {
+		t.Fatalf("no changed contract expected, got %v", len(changed))
+	}
+
+	// stale revision is ignored
+	if applied, err := scs.ApplySharedContract(shared); err != nil || applied {
+		t.Fatalf("shared contract with stale revision should be ignored: %v, %v", applied, err)
+	}
+
+	// newer revision updates the header and appends the new roots, keeping the local private key
+	shared.Header.LatestContractRevision.NewRevisionNumber = 2
+	shared.Roots = append(shared.Roots, rootsGenerator(5)...)
+	signSharedContract(t, &shared, keys)
+	shared = shared.WithoutPrivateKey()
+	if applied, err := scs.ApplySharedContract(shared); err != nil || !applied {
+		t.Fatalf("failed to apply the shared contract with newer revision: %v, %v", applied, err)
+	}
+	c := scs.contracts[shared.Header.ID]
+	if c.Header().LatestContractRevision.NewRevisionNumber != 2 {
+		t.Errorf("revision number not updated")
+	}
+	if c.Header().PrivateKey == "" {
+		t.Errorf("the local private key should be kept")
+	}
+	roots, err := scs.db.FetchMerkleRoots(shared.Header.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hashSliceComparator(roots, shared.Roots) {
+		t.Errorf("merkle roots not expected. Expected %v, got %v", len(shared.Roots), len(roots))
+	}
+	if changed, _ := scs.ChangedContracts(known); len(changed) != 1 {
+		t.Fatalf("one changed contract expected, got %v", len(changed))
+	}
+
+	// shared contract with less roots is rejected
+	shared.Header.LatestContractRevision.NewRevisionNumber = 3
+	shared.Roots = shared.Roots[:1]
+	signSharedContract(t, &shared, keys)
+	if _, err := scs.ApplySharedContract(shared); err == nil {
+		t.Fatal("shared contract with less merkle roots should be rejected")
+	}
+}
+
+func TestVerifySharedContract(t *testing.T) {
+	keys := sharedContractKeys(t)
+	shared := SharedContract{Header: contractHeaderGenerator(), Roots: rootsGenerator(3)}
+	signSharedContract(t, &shared, keys)
+	if err := verifySharedContract(shared); err != nil {
+		t.Fatalf("the signed shared contract should pass the verification: %v", err)
+	}
+
+	// the roots not matching the file merkle root
+	tampered := shared
+	tampered.Roots = rootsGenerator(3)
+	if err := verifySharedContract(tampered); err != errSharedMerkleRoot {
+		t.Errorf("expect error %v, got %v", errSharedMerkleRoot, err)
+	}
+
+	// the revision changed after signed
+	tampered = shared
+	tampered.Header.LatestContractRevision.NewRevisionNumber++
+	if err := verifySharedContract(tampered); err != errSharedRevisionSignature {
+		t.Errorf("expect error %v, got %v", errSharedRevisionSignature, err)
+	}
+
+	// the revision signed by the client only
+	tampered = shared
+	tampered.Header.LatestContractRevision.Signatures = tampered.Header.LatestContractRevision.Signatures[:1]
+	if err := verifySharedContract(tampered); err != errSharedRevisionSignature {
+		t.Errorf("expect error %v, got %v", errSharedRevisionSignature, err)
+	}
+}
+
+// sharedContractKeys generates the keys of the storage client and the storage host
+func sharedContractKeys(t *testing.T) []*ecdsa.PrivateKey {
+	var keys []*ecdsa.PrivateKey
+	for i := 0; i < 2; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// signSharedContract sets the file merkle root of the latest revision based on the roots
+// shared, and signs the revision with the keys of the storage client and the storage host
+func signSharedContract(t *testing.T, shared *SharedContract, keys []*ecdsa.PrivateKey) {
+	rev := &shared.Header.LatestContractRevision
+	rev.NewFileMerkleRoot = merkle.Sha256CachedTreeRoot(shared.Roots, sectorHeight)
+	rev.UnlockConditions.PaymentAddresses = []common.Address{
+		crypto.PubkeyToAddress(keys[0].PublicKey),
+		crypto.PubkeyToAddress(keys[1].PublicKey),
+	}
+	rev.Signatures = nil
+	for _, key := range keys {
+		sig, err := crypto.Sign(rev.RLPHash().Bytes(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rev.Signatures = append(rev.Signatures, sig)
+	}
+}
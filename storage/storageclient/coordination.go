@@ -0,0 +1,112 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/contractset"
+	"github.com/DxChainNetwork/godx/storage/storageclient/coordinator"
+)
+
+var errCoordinationDisabled = errors.New("the storage client coordination mode is not enabled")
+
+// coordinationHandler shares the contracts of the storage client through the shared
+// journal of the coordination backend, and merges the contracts shared by the other nodes
+type coordinationHandler struct {
+	client *StorageClient
+
+	// revision numbers of the contracts already shared or merged
+	shared map[storage.ContractID]uint64
+	lock   sync.Mutex
+}
+
+// EnableCoordination enables the experimental coordination mode, where the storage
+// client shares one contract set with the other nodes using the same coordination
+// directory. Only the leader node forms and renews the contracts, while all nodes can
+// upload and download with the contracts shared. It must be called before Start
+func (client *StorageClient) EnableCoordination(dir string) error {
+	if client.replica != nil {
+		return errors.New("the coordination mode cannot be enabled along with the read-only replica mode")
+	}
+	backend, err := coordinator.NewFileBackend(dir)
+	if err != nil {
+		return err
+	}
+	client.coordinationBackend = backend
+	return nil
+}
+
+// CoordinationStatus returns the coordination status of the storage client
+func (client *StorageClient) CoordinationStatus() (coordinator.Status, error) {
+	if client.coordinator == nil {
+		return coordinator.Status{}, errCoordinationDisabled
+	}
+	return client.coordinator.Status(), nil
+}
+
+// startCoordination starts the coordinator if the coordination mode is enabled. The
+// node is identified by its enode URL
+func (client *StorageClient) startCoordination() {
+	if client.coordinationBackend == nil {
+		return
+	}
+	handler := &coordinationHandler{
+		client: client,
+		shared: make(map[storage.ContractID]uint64),
+	}
+	client.coordinator = coordinator.New(client.coordinationBackend, handler, client.ethBackend.SelfEnodeURL(), coordinator.DefaultLeaseTTL)
+	client.contractManager.SetMaintenanceGate(client.coordinator.IsLeader)
+	client.coordinator.Start()
+}
+
+// stopCoordination stops the coordinator and releases the leader lease
+func (client *StorageClient) stopCoordination() error {
+	if client.coordinator == nil {
+		return nil
+	}
+	return client.coordinator.Stop()
+}
+
+// PendingChanges returns the contracts revised locally since last shared. The shared journal
+// is stored in plain text, so the private keys of the contracts are never shared
+func (h *coordinationHandler) PendingChanges() (changes []interface{}, err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	changed, err := h.client.contractManager.GetStorageContractSet().ChangedContracts(h.shared)
+	if err != nil {
+		return
+	}
+	for _, contract := range changed {
+		h.shared[contract.Header.ID] = contract.Header.LatestContractRevision.NewRevisionNumber
+		changes = append(changes, contract.WithoutPrivateKey())
+	}
+	return
+}
+
+// ApplyChange merges the contract shared by another node into the local contract set,
+// and activates the worker for the contract. The contract whose revision signatures or
+// merkle roots fail the verification is rejected
+func (h *coordinationHandler) ApplyChange(data json.RawMessage) error {
+	var contract contractset.SharedContract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		return err
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	applied, err := h.client.contractManager.GetStorageContractSet().ApplySharedContract(contract)
+	if err != nil || !applied {
+		return err
+	}
+	h.shared[contract.Header.ID] = contract.Header.LatestContractRevision.NewRevisionNumber
+	h.client.activateWorkerPool()
+	return nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package coordinator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var errLockBusy = errors.New("the coordination directory is locked by another node")
+
+// Lease is the leader lease held by one of the coordinated nodes
+type Lease struct {
+	Leader string    `json:"leader"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// JournalEntry is an entry of the shared journal, which is appended by one of the
+// coordinated nodes and replayed by all the others
+type JournalEntry struct {
+	Seq  uint64          `json:"seq"`
+	Node string          `json:"node"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Backend is the coordination backend shared by the nodes coordinated as one logical
+// storage client. It provides the leader lease and the shared journal
+type Backend interface {
+	// TryLease acquires or renews the leader lease for the node. The lease currently
+	// in effect is returned, which is held by another node if not acquired
+	TryLease(node string, ttl time.Duration) (Lease, error)
+
+	// ReleaseLease releases the leader lease if it is held by the node
+	ReleaseLease(node string) error
+
+	// Append appends the data to the shared journal, and returns the sequence number
+	Append(node string, data json.RawMessage) (uint64, error)
+
+	// Read returns the journal entries starting from the sequence number provided
+	Read(from uint64) ([]JournalEntry, error)
+}
+
+// FileBackend is the coordination backend built on a directory shared by all the
+// coordinated nodes, e.g. a network file system mount. The mutual exclusion between
+// the nodes is achieved by the exclusively created lock file
+type FileBackend struct {
+	dir string
+}
+
+// NewFileBackend creates the coordination backend on the shared directory
+func NewFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(filepath.Join(dir, journalDirName), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the coordination directory: %s", err.Error())
+	}
+	return &FileBackend{dir: dir}, nil
+}
+
+// TryLease acquires the leader lease if it is not held by any other node or expired,
+// or renews it if held by the node
+func (fb *FileBackend) TryLease(node string, ttl time.Duration) (lease Lease, err error) {
+	err = fb.withLock(func() error {
+		if err := fb.readJSON(leaseFileName, &lease); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		now := time.Now()
+		if lease.Leader != "" && lease.Leader != node && now.Before(lease.Expiry) {
+			return nil
+		}
+		lease = Lease{Leader: node, Expiry: now.Add(ttl)}
+		return fb.writeJSON(leaseFileName, lease)
+	})
+	return
+}
+
+// ReleaseLease releases the leader lease held by the node, so that the other nodes can
+// take over without waiting for the lease to expire
+func (fb *FileBackend) ReleaseLease(node string) error {
+	return fb.withLock(func() error {
+		var lease Lease
+		if err := fb.readJSON(leaseFileName, &lease); err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if lease.Leader != node {
+			return nil
+		}
+		return fb.writeJSON(leaseFileName, Lease{})
+	})
+}
+
+// Append writes the data as the next entry of the shared journal. The entry is stored in
+// plain text, readable by anyone with the access to the shared directory
+func (fb *FileBackend) Append(node string, data json.RawMessage) (seq uint64, err error) {
+	err = fb.withLock(func() error {
+		head, err := fb.head()
+		if err != nil {
+			return err
+		}
+		seq = head + 1
+		entry := JournalEntry{Seq: seq, Node: node, Data: data}
+		if err := fb.writeJSON(journalEntryName(seq), entry); err != nil {
+			return err
+		}
+		return fb.writeJSON(journalHeadName, seq)
+	})
+	return
+}
+
+// Read returns the journal entries from the sequence number provided to the head
+func (fb *FileBackend) Read(from uint64) (entries []JournalEntry, err error) {
+	head, err := fb.head()
+	if err != nil {
+		return
+	}
+	if from == 0 {
+		from = 1
+	}
+	for seq := from; seq <= head; seq++ {
+		var entry JournalEntry
+		if err = fb.readJSON(journalEntryName(seq), &entry); err != nil {
+			return
+		}
+		entries = append(entries, entry)
+	}
+	return
+}
+
+// head returns the sequence number of the last journal entry
+func (fb *FileBackend) head() (seq uint64, err error) {
+	if err = fb.readJSON(journalHeadName, &seq); os.IsNotExist(err) {
+		err = nil
+	}
+	return
+}
+
+// withLock runs the function while holding the lock file of the coordination directory.
+// The lock file left by the crashed node is removed once it becomes stale
+func (fb *FileBackend) withLock(fn func() error) error {
+	path := filepath.Join(fb.dir, lockFileName)
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			break
+		}
+		if !os.IsExist(err) {
+			return err
+		}
+		if info, errStat := os.Stat(path); errStat == nil && time.Since(info.ModTime()) > staleLockTimeout {
+			os.Remove(path)
+			continue
+		}
+		if attempt >= lockRetries {
+			return errLockBusy
+		}
+		time.Sleep(lockRetryInterval)
+	}
+	defer os.Remove(path)
+	return fn()
+}
+
+// readJSON decodes the json file in the coordination directory
+func (fb *FileBackend) readJSON(name string, v interface{}) error {
+	blob, err := ioutil.ReadFile(filepath.Join(fb.dir, name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(blob, v)
+}
+
+// writeJSON atomically replaces the json file in the coordination directory
+func (fb *FileBackend) writeJSON(name string, v interface{}) error {
+	blob, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(fb.dir, name)
+	if err := ioutil.WriteFile(path+".tmp", blob, 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// journalEntryName returns the file name of the journal entry with the sequence number
+func journalEntryName(seq uint64) string {
+	return filepath.Join(journalDirName, fmt.Sprintf("%020d.json", seq))
+}
@@ -0,0 +1,181 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package coordinator implements the experimental coordination mode, where multiple
+// nodes act as one logical storage client. The nodes elect a leader through the lease
+// of the coordination backend, and share their changes through the shared journal
+package coordinator
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/DxChainNetwork/godx/log"
+)
+
+// Handler provides the local changes to be shared with the other nodes, and applies the
+// changes shared by them
+type Handler interface {
+	// PendingChanges returns the local changes not shared yet
+	PendingChanges() ([]interface{}, error)
+
+	// ApplyChange applies the change shared by another node
+	ApplyChange(data json.RawMessage) error
+}
+
+// Status is the coordination status of the node
+type Status struct {
+	Node        string    `json:"node"`
+	Leader      string    `json:"leader"`
+	IsLeader    bool      `json:"isleader"`
+	LeaseExpiry time.Time `json:"leaseexpiry"`
+	AppliedSeq  uint64    `json:"appliedseq"`
+	Error       string    `json:"error"`
+}
+
+// Coordinator coordinates the node with the other nodes sharing the same backend
+type Coordinator struct {
+	backend Backend
+	handler Handler
+	node    string
+	ttl     time.Duration
+
+	lease      Lease
+	appliedSeq uint64
+	err        error
+	lock       sync.RWMutex
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	log  log.Logger
+}
+
+// New creates the coordinator of the node, where the leader lease is valid for the ttl
+func New(backend Backend, handler Handler, node string, ttl time.Duration) *Coordinator {
+	return &Coordinator{
+		backend: backend,
+		handler: handler,
+		node:    node,
+		ttl:     ttl,
+		stop:    make(chan struct{}),
+		log:     log.New("module", "coordinator"),
+	}
+}
+
+// Start starts the go routine renewing the leader lease and syncing the shared journal
+func (c *Coordinator) Start() {
+	c.sync()
+	c.wg.Add(1)
+	go c.loop()
+}
+
+// Stop stops the coordinator and releases the leader lease held by the node
+func (c *Coordinator) Stop() error {
+	close(c.stop)
+	c.wg.Wait()
+	return c.backend.ReleaseLease(c.node)
+}
+
+// IsLeader returns whether the node holds the unexpired leader lease
+func (c *Coordinator) IsLeader() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lease.Leader == c.node && time.Now().Before(c.lease.Expiry)
+}
+
+// Status returns the coordination status of the node
+func (c *Coordinator) Status() (status Status) {
+	isLeader := c.IsLeader()
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	status = Status{
+		Node:        c.node,
+		Leader:      c.lease.Leader,
+		IsLeader:    isLeader,
+		LeaseExpiry: c.lease.Expiry,
+		AppliedSeq:  c.appliedSeq,
+	}
+	if c.err != nil {
+		status.Error = c.err.Error()
+	}
+	return
+}
+
+// loop syncs with the backend three times within the lease ttl, so that the leader
+// renews the lease before it expires
+func (c *Coordinator) loop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.sync()
+		}
+	}
+}
+
+// sync renews the leader lease, shares the local changes, and applies the changes
+// shared by the other nodes
+func (c *Coordinator) sync() {
+	err := c.syncOnce()
+	if err != nil {
+		c.log.Warn("failed to sync with the coordination backend", "err", err)
+	}
+	c.lock.Lock()
+	c.err = err
+	c.lock.Unlock()
+}
+
+// syncOnce runs one round of the synchronization with the backend
+func (c *Coordinator) syncOnce() error {
+	lease, err := c.backend.TryLease(c.node, c.ttl)
+	if err != nil {
+		return err
+	}
+	c.lock.Lock()
+	if lease.Leader != c.lease.Leader {
+		c.log.Info("Storage client coordination leader changed", "leader", lease.Leader)
+	}
+	c.lease = lease
+	from := c.appliedSeq + 1
+	c.lock.Unlock()
+
+	// apply the changes of the other nodes first, so that the local changes shared
+	// are based on the latest state
+	entries, err := c.backend.Read(from)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Node != c.node {
+			if err := c.handler.ApplyChange(entry.Data); err != nil {
+				return err
+			}
+		}
+		c.lock.Lock()
+		c.appliedSeq = entry.Seq
+		c.lock.Unlock()
+	}
+
+	changes, err := c.handler.PendingChanges()
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		data, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+		if _, err := c.backend.Append(c.node, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
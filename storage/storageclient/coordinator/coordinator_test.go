@@ -0,0 +1,132 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package coordinator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// testHandler records the changes applied, and shares the pending changes once
+type testHandler struct {
+	pending []interface{}
+	applied []string
+}
+
+func (h *testHandler) PendingChanges() (changes []interface{}, err error) {
+	changes, h.pending = h.pending, nil
+	return
+}
+
+func (h *testHandler) ApplyChange(data json.RawMessage) error {
+	var change string
+	if err := json.Unmarshal(data, &change); err != nil {
+		return err
+	}
+	h.applied = append(h.applied, change)
+	return nil
+}
+
+func newTestBackend(t *testing.T) (*FileBackend, func()) {
+	dir, err := ioutil.TempDir("", "coordinator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fb, err := NewFileBackend(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fb, func() { os.RemoveAll(dir) }
+}
+
+func TestFileBackend_Lease(t *testing.T) {
+	fb, cleanup := newTestBackend(t)
+	defer cleanup()
+
+	lease, err := fb.TryLease("node1", time.Minute)
+	if err != nil || lease.Leader != "node1" {
+		t.Fatalf("node1 should acquire the lease: %v, %v", lease, err)
+	}
+	if lease, err = fb.TryLease("node2", time.Minute); err != nil || lease.Leader != "node1" {
+		t.Fatalf("node2 should not acquire the lease held by node1: %v, %v", lease, err)
+	}
+
+	// the released lease can be acquired by the other node
+	if err := fb.ReleaseLease("node1"); err != nil {
+		t.Fatal(err)
+	}
+	if lease, err = fb.TryLease("node2", time.Millisecond); err != nil || lease.Leader != "node2" {
+		t.Fatalf("node2 should acquire the released lease: %v, %v", lease, err)
+	}
+
+	// the expired lease can be acquired by the other node
+	time.Sleep(10 * time.Millisecond)
+	if lease, err = fb.TryLease("node1", time.Minute); err != nil || lease.Leader != "node1" {
+		t.Fatalf("node1 should acquire the expired lease: %v, %v", lease, err)
+	}
+}
+
+func TestFileBackend_Journal(t *testing.T) {
+	fb, cleanup := newTestBackend(t)
+	defer cleanup()
+
+	for i := 1; i <= 3; i++ {
+		seq, err := fb.Append("node1", json.RawMessage(`"change"`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seq != uint64(i) {
+			t.Fatalf("sequence number not expected. Expect %v, Got %v", i, seq)
+		}
+	}
+	entries, err := fb.Read(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Seq != 2 || entries[1].Seq != 3 {
+		t.Fatalf("journal entries not expected: %v", entries)
+	}
+}
+
+func TestCoordinator_Sync(t *testing.T) {
+	fb, cleanup := newTestBackend(t)
+	defer cleanup()
+
+	h1 := &testHandler{pending: []interface{}{"a", "b"}}
+	h2 := &testHandler{pending: []interface{}{"c"}}
+	c1 := New(fb, h1, "node1", time.Minute)
+	c2 := New(fb, h2, "node2", time.Minute)
+
+	c1.sync()
+	c2.sync()
+	c1.sync()
+
+	if !c1.IsLeader() || c2.IsLeader() {
+		t.Fatalf("node1 should be the only leader")
+	}
+	if len(h1.applied) != 1 || h1.applied[0] != "c" {
+		t.Errorf("changes applied by node1 not expected: %v", h1.applied)
+	}
+	if len(h2.applied) != 2 || h2.applied[0] != "a" || h2.applied[1] != "b" {
+		t.Errorf("changes applied by node2 not expected: %v", h2.applied)
+	}
+	if status := c2.Status(); status.Leader != "node1" || status.AppliedSeq != 2 {
+		t.Errorf("status of node2 not expected: %+v", status)
+	}
+
+	// node2 takes over once node1 stops
+	c1.wg.Add(1)
+	go c1.loop()
+	if err := c1.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	c2.sync()
+	if !c2.IsLeader() {
+		t.Fatalf("node2 should take over the leader lease")
+	}
+}
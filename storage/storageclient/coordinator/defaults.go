@@ -0,0 +1,27 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package coordinator
+
+import "time"
+
+// Files of the coordination directory
+const (
+	lockFileName    = "coordination.lock"
+	leaseFileName   = "lease.json"
+	journalDirName  = "journal"
+	journalHeadName = "journal/head.json"
+)
+
+var (
+	// DefaultLeaseTTL is the duration the leader lease is valid for without renewal
+	DefaultLeaseTTL = 30 * time.Second
+
+	// staleLockTimeout is the age after which the lock file is considered left by a crashed node
+	staleLockTimeout = 10 * time.Second
+
+	// lockRetries and lockRetryInterval control how the lock file is waited for
+	lockRetries       = 100
+	lockRetryInterval = 20 * time.Millisecond
+)
@@ -6,6 +6,9 @@ package storageclient
 
 import (
 	"time"
+
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
 )
 
 // Files and directories related constant
@@ -16,12 +19,113 @@ const (
 	DxPathRoot                  = "dxfiles"
 )
 
+// Read-only replica related constant
+const (
+	ReplicaSnapshotFilename = "replica.json"
+	ReplicaSnapshotHeader   = "Storage Client Replica Snapshot"
+	ReplicaSnapshotVersion  = "1.0"
+	replicaFilesDir         = "files"
+	mirrorTempSuffix        = ".mirrortmp"
+)
+
+// ReplicaSyncInterval is the interval the read-only replica syncs the snapshot of the primary
+var ReplicaSyncInterval = 5 * time.Minute
+
+// Upload stream related constant
+const (
+	// UploadStreamDir is the directory under the persist directory the upload streams are staged in
+	UploadStreamDir = "uploadstreams"
+
+	// UploadStreamLeafSize is the size of the merkle leaves of the upload stream report
+	UploadStreamLeafSize = 1 << 16
+
+	// the suffix of the upload streams not finished
+	uploadStreamPartSuffix = ".part"
+)
+
+// Metadata backup related constant
+const (
+	backupMagic          = "DXBACKUP1"
+	backupExt            = ".dxbk"
+	backupManifestExt    = ".json"
+	latestBackupManifest = "latest.json"
+	backupSnapshotEntry  = "snapshot.json"
+	backupFilesDir       = "files"
+	backupSaltLength     = 32
+
+	// the fixed name of the temporary file the backup object is staged in
+	backupTempFile = "dxbackup.tmp"
+
+	// the name of the file keeping the backup passphrase in the persist directory
+	backupSecretFile = "backup.secret"
+
+	// the scrypt params deriving the backup cipher key from the passphrase
+	backupScryptN = 1 << 15
+	backupScryptR = 8
+	backupScryptP = 1
+)
+
+// Metadata backup related params
+var (
+	// MinBackupInterval is the minimum interval between two scheduled metadata backups
+	MinBackupInterval = 10 * time.Minute
+
+	// BackupCheckInterval is the interval to check whether the scheduled backup is due
+	BackupCheckInterval = time.Minute
+
+	// BackupTransferTimeout is the timeout to transfer a backup object over http
+	BackupTransferTimeout = 10 * time.Minute
+)
+
+// Onboarding related params
+var (
+	// FaucetRequestTimeout is the timeout of the funding request sent to the faucet
+	FaucetRequestTimeout = 30 * time.Second
+
+	// OnboardFundTimeout is the maximum amount of time to wait for the faucet funds confirmed
+	OnboardFundTimeout = 10 * time.Minute
+
+	// OnboardCheckInterval is the interval to check whether the faucet funds are confirmed
+	OnboardCheckInterval = 5 * time.Second
+)
+
+// Client profile related constant
+const (
+	ProfileHeader  = "Storage Client Profile"
+	ProfileVersion = "1.0"
+)
+
+// Upload receipt related constant
+const (
+	ReceiptHeader  = "Storage Client Upload Receipt"
+	ReceiptVersion = "1.0"
+
+	// receiptExt is the extension of the upload receipt stored alongside the dxfile
+	receiptExt = ".receipt"
+)
+
+// File history related constant
+const (
+	FileHistoryHeader  = "Storage Client File History"
+	FileHistoryVersion = "1.0"
+
+	// fileHistoryExt is the extension of the file history stored alongside the dxfile
+	fileHistoryExt = ".history"
+
+	// maxFileHistoryEvents is the number of the most recent events kept in the file history
+	maxFileHistoryEvents = 256
+)
+
 // StorageClient Settings, where 0 means unlimited
 const (
 	DefaultMaxDownloadSpeed = 0
 	DefaultMaxUploadSpeed   = 0
 	DefaultPacketSize       = 4 * 4096
 
+	// the maximum number of files, and the maximum number of file metadata kept in memory
+	DefaultMaxFiles       = 0
+	DefaultMaxCachedFiles = 1000
+
 	// frequency to check whether storage client is online
 	OnlineCheckFrequency = time.Second * 10
 
@@ -69,7 +173,157 @@ var (
 	// UploadFailureCoolDown is the initial time of punishment while upload consecutive fails
 	// the punishment time shows exponential growth
 	UploadFailureCoolDown = 3 * time.Second
+
+	// RepairHotFileWindow is the time window within which a file accessed is considered
+	// as frequently used, and its segments are repaired before the cold files
+	RepairHotFileWindow = 7 * 24 * time.Hour
 )
 
+// Revision monitor related params
+var (
+	// RevisionMonitorWindow is the time window the revision signing rate and spending
+	// with a storage host are measured
+	RevisionMonitorWindow = 10 * time.Minute
+
+	// MaxRevisionsPerWindow is the maximum number of revisions that can be signed
+	// with a storage host within the RevisionMonitorWindow
+	MaxRevisionsPerWindow = 3000
+
+	// MaxSpendRatioPerWindow is the maximum ratio of the contract fund that can be
+	// spent with a storage host within the RevisionMonitorWindow
+	MaxSpendRatioPerWindow = 0.25
+
+	// MaxIdenticalRevisions is the maximum number of identical revisions that can be
+	// signed in a row with a storage host
+	MaxIdenticalRevisions = 3
+
+	// AbnormalRevisionPauseDuration is the time uploads to a storage host will be paused
+	// once abnormal revisions are detected
+	AbnormalRevisionPauseDuration = 6 * time.Hour
+)
+
+// Stream reader read-ahead related params
+var (
+	// StreamSequentialThreshold is the number of sequential reads in a row
+	// before the stream reader starts prefetching segments
+	StreamSequentialThreshold = 2
+
+	// StreamPrefetchMinSegments is the initial and minimum number of segments
+	// to be read ahead
+	StreamPrefetchMinSegments = uint64(1)
+
+	// StreamPrefetchMaxSegments is the maximum number of segments to be read ahead
+	StreamPrefetchMaxSegments = uint64(16)
+
+	// StreamPrefetchIdleThreshold is the time a prefetched segment can stay unread
+	// before the read ahead window is shrunk
+	StreamPrefetchIdleThreshold = 5 * time.Second
+
+	// HTTPStreamReadTimeout is the timeout of reading the request of the HTTP stream endpoint.
+	// No write timeout is set since the file streamed could be large
+	HTTPStreamReadTimeout = 30 * time.Second
+)
+
+// LocalCopyHealthThreshold is the default file health required to verify the local copy
+// safe to delete
+var LocalCopyHealthThreshold = uint32(dxfile.RepairHealthThreshold)
+
+// Download source selection related params
+var (
+	// DownloadSourcePriceWeight is the weight of the download bandwidth price when
+	// ranking the hosts to download a segment from
+	DownloadSourcePriceWeight = 0.6
+
+	// DownloadSourceLatencyWeight is the weight of the observed sector download latency
+	// when ranking the hosts to download a segment from
+	DownloadSourceLatencyWeight = 0.4
+
+	// RepairDownloadLatencyTarget is the latency target of the downloads fetching the
+	// segment data for repair, which favors the cheap hosts over the fast ones
+	RepairDownloadLatencyTarget = time.Minute
+)
+
+// Upload timing related params
+var (
+	// UploadTimingSamples is the number of recent sector upload time kept for each storage
+	// host, and the number of recent segment upload time kept
+	UploadTimingSamples = 256
+
+	// SlowHostMinSamples is the minimum number of sectors uploaded to a storage host
+	// before it can be considered slow
+	SlowHostMinSamples = 20
+
+	// SlowHostTailShare is the share of the sectors uploaded to a storage host slower than
+	// the p95 of all storage hosts, above which the storage host is considered slow
+	SlowHostTailShare = 0.2
+
+	// SlowHostRescoreInterval is the number of sectors uploaded to a storage host between
+	// two updates of its upload slowdown used for the storage host evaluation
+	SlowHostRescoreInterval = 16
+)
+
+// Host upload probing related params
+var (
+	// HostProbeSize is the size of the probe data sent to a storage host to measure its
+	// upload speed before the first sector is uploaded
+	HostProbeSize = uint64(256 * 1024)
+
+	// HostProbeSlowFactor is the ratio of the sector upload time estimated by the probe to
+	// the median sector upload time of all storage hosts, above which the worker is marked
+	// slow and leaves the sectors to the faster workers
+	HostProbeSlowFactor = 3.0
+)
+
+// Worker pool tuning related params
+var (
+	// WorkerPoolTuneInterval is the interval the worker pool size is tuned by the resources
+	WorkerPoolTuneInterval = time.Minute
+
+	// MinWorkerPoolSize is the minimum number of workers transferring sectors at the same time
+	MinWorkerPoolSize = 4
+
+	// WorkerPoolTransfersPerCPU is the number of concurrent sector transfers a CPU can serve
+	WorkerPoolTransfersPerCPU = 8
+
+	// WorkerPoolLinkHeadroom is the ratio of the storage hosts used over the number needed to
+	// saturate the bandwidth limit, which covers the fluctuation of the host throughput
+	WorkerPoolLinkHeadroom = 1.5
+)
+
+// Host announcement backfill related params
+var (
+	// HostBackfillCheckpointInterval is the number of blocks scanned between two
+	// checkpoints of the host announcement backfill
+	HostBackfillCheckpointInterval = uint64(1000)
+)
+
+// the suffix of the files of the segment data spilled to disk
+const spillFileSuffix = ".spill"
+
+// Operation related params
+var (
+	// MaxOperationHistory is the maximum number of finished upload and download operations
+	// kept for querying their final status
+	MaxOperationHistory = 100
+)
+
+const (
+	// the weight of the newly observed latency in the average download latency of a worker
+	downloadLatencyDecay = 0.2
+
+	// the lower bound of the reliability of a download source, to avoid dividing by zero
+	minDownloadSourceReliability = 0.01
+)
+
+// the number of extra sectors to download for a streamed segment
+const streamDownloadOverdrive = 3
+
+// DefaultDownloadOptions is the options of the download requested without options
+var DefaultDownloadOptions = storage.DownloadOptions{
+	LatencyTarget: 25e3 * time.Millisecond,
+	Overdrive:     3,
+	Priority:      5,
+}
+
 var keys = []string{"fund", "hosts", "period", "renew", "storage", "upload", "download",
-	"redundancy", "violation", "uploadspeed", "downloadspeed"}
+	"redundancy", "violation", "uploadspeed", "downloadspeed", "features", "downloadbudget"}
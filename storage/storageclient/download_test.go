@@ -0,0 +1,104 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// TestStorageClient_DownloadRange test that only the segments covering the range are queued
+// for download, and each segment fetches only the bytes within the range
+func TestStorageClient_DownloadRange(t *testing.T) {
+	storage.ENV = storage.EnvTest
+
+	sct := newStorageClientTester(t)
+	defer sct.Client.Close()
+
+	entry := newFileEntry(t, sct.Client)
+	defer func() {
+		os.Remove(string(entry.LocalPath()))
+		os.Remove(string(entry.FilePath()))
+		entry.Close()
+	}()
+
+	// the range out of the file is rejected before the destination is created
+	dst := filepath.Join(homeDir(), "downloadrange")
+	p := storage.DownloadParameters{
+		RemoteFilePath:   entry.DxPath().Path,
+		WriteToLocalPath: dst,
+		Offset:           entry.FileSize(),
+		Length:           1,
+	}
+	if _, err := sct.Client.createDownload(p); err == nil {
+		t.Fatalf("downloading the range out of the file should return error")
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Fatalf("the destination should not be created")
+	}
+
+	snap, err := entry.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	segmentSize := snap.SegmentSize()
+	offset, length := segmentSize+10, segmentSize
+	d, err := sct.Client.newDownload(downloadParams{
+		destination: newDownloadBuffer(length, snap.SectorSize()),
+		file:        snap,
+		length:      length,
+		offset:      offset,
+		needsMemory: true,
+		operation:   sct.Client.operations.add(OperationDownload, entry.DxPath().Path),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.segmentsRemaining != 2 {
+		t.Fatalf("segments to download not expected. Expect 2, Got %v", d.segmentsRemaining)
+	}
+	segments := []*unfinishedDownloadSegment(*sct.Client.downloadHeap)
+	sort.Slice(segments, func(i, j int) bool { return segments[i].segmentIndex < segments[j].segmentIndex })
+	expects := []struct {
+		segmentIndex uint64
+		fetchOffset  uint64
+		fetchLength  uint64
+		writeOffset  int64
+	}{
+		{1, 10, segmentSize - 10, 0},
+		{2, 0, 10, int64(segmentSize - 10)},
+	}
+	for i, expect := range expects {
+		uds := segments[i]
+		if uds.segmentIndex != expect.segmentIndex || uds.fetchOffset != expect.fetchOffset ||
+			uds.fetchLength != expect.fetchLength || uds.writeOffset != expect.writeOffset {
+			t.Errorf("segment %v not expected. Expect %+v, Got index %v, fetch [%v, %v), write at %v", i, expect,
+				uds.segmentIndex, uds.fetchOffset, uds.fetchOffset+uds.fetchLength, uds.writeOffset)
+		}
+	}
+}
+
+func TestValidateDownloadOptions(t *testing.T) {
+	tests := []struct {
+		options storage.DownloadOptions
+		valid   bool
+	}{
+		{DefaultDownloadOptions, true},
+		{storage.DownloadOptions{LatencyTarget: time.Second, Overdrive: 2, Priority: 10}, true},
+		{storage.DownloadOptions{LatencyTarget: time.Second, Overdrive: 0}, true},
+		{storage.DownloadOptions{LatencyTarget: 0, Overdrive: 1}, false},
+		{storage.DownloadOptions{LatencyTarget: time.Second, Overdrive: -1}, false},
+	}
+	for i, test := range tests {
+		if err := validateDownloadOptions(test.options); (err == nil) != test.valid {
+			t.Errorf("test %v: validity not expected. Expect %v, Got error %v", i, test.valid, err)
+		}
+	}
+}
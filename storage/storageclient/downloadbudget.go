@@ -0,0 +1,84 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"sync"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// downloadBudget keeps the download spending of the current period within the download budget
+// of the rent payment. The price of a download is reserved before the request is sent to the
+// storage host, so that the concurrent downloads could not overspend the budget together. The
+// reservation is released once the download is done, which is either committed to the contract
+// and counted in the period cost, or failed
+type downloadBudget struct {
+	reserved  common.BigInt
+	exhausted bool
+	lock      sync.Mutex
+}
+
+// DownloadBudgetStatus is the download spending of the current period against the download budget
+type DownloadBudgetStatus struct {
+	Budget    common.BigInt `json:"budget"`
+	Spent     common.BigInt `json:"spent"`
+	Reserved  common.BigInt `json:"reserved"`
+	Remaining common.BigInt `json:"remaining"`
+	Unlimited bool          `json:"unlimited"`
+	Exhausted bool          `json:"exhausted"`
+}
+
+// reserveDownload reserves the price of a download from the download budget, and returns the
+// function releasing the reservation. storage.ErrDownloadBudgetExhausted is returned if the
+// budget left could not afford the download
+func (client *StorageClient) reserveDownload(price common.BigInt) (release func(), err error) {
+	rent := client.contractManager.AcquireRentPayment()
+	if rent.DownloadBudget.Sign() == 0 {
+		return func() {}, nil
+	}
+	spent := client.contractManager.CalculatePeriodCost(rent).DownloadCost
+
+	db := &client.downloadBudget
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	if spent.Add(db.reserved).Add(price).Cmp(rent.DownloadBudget) > 0 {
+		if !db.exhausted {
+			client.log.Warn("download budget exhausted, downloads are stopped", "budget", rent.DownloadBudget, "spent", spent)
+		}
+		db.exhausted = true
+		return nil, storage.ErrDownloadBudgetExhausted
+	}
+	db.exhausted = false
+	db.reserved = db.reserved.Add(price)
+
+	return func() {
+		db.lock.Lock()
+		defer db.lock.Unlock()
+		db.reserved = db.reserved.Sub(price)
+	}, nil
+}
+
+// DownloadBudgetStatus returns the download spending of the current period against the budget
+func (client *StorageClient) DownloadBudgetStatus() DownloadBudgetStatus {
+	rent := client.contractManager.AcquireRentPayment()
+	status := DownloadBudgetStatus{
+		Budget:    rent.DownloadBudget,
+		Spent:     client.contractManager.CalculatePeriodCost(rent).DownloadCost,
+		Unlimited: rent.DownloadBudget.Sign() == 0,
+	}
+
+	db := &client.downloadBudget
+	db.lock.Lock()
+	status.Reserved = db.reserved
+	status.Exhausted = db.exhausted && !status.Unlimited
+	db.lock.Unlock()
+
+	if remaining := status.Budget.Sub(status.Spent).Sub(status.Reserved); !status.Unlimited && remaining.Sign() > 0 {
+		status.Remaining = remaining
+	}
+	return status
+}
@@ -6,9 +6,9 @@ package storageclient
 
 import (
 	"container/heap"
-	"time"
 
 	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/storage/chrono"
 )
 
 // download tasks are added to the downloadSegmentHeap.
@@ -70,13 +70,13 @@ LOOP:
 		}
 
 		client.activateWorkerPool()
-		workerActivateTime := time.Now()
+		activateDeadline := chrono.NewDeadline(client.clock, WorkerActivateTimeout)
 
 		// pull downloads out of the heap
 		for {
 
 			// if client is offline, or timeout for activating worker pool, will reset to loop
-			if !client.Online() || time.Now().After(workerActivateTime.Add(WorkerActivateTimeout)) {
+			if !client.Online() || activateDeadline.Expired() {
 				continue LOOP
 			}
 
@@ -109,7 +109,7 @@ func (client *StorageClient) blockUntilOnline() bool {
 		select {
 		case <-client.tm.StopChan():
 			return false
-		case <-time.After(OnlineCheckFrequency):
+		case <-client.clock.After(OnlineCheckFrequency):
 		}
 	}
 	return true
@@ -144,11 +144,15 @@ func (client *StorageClient) acquireMemoryForDownloadSegment(uds *unfinishedDown
 func (client *StorageClient) distributeDownloadSegmentToWorkers(uds *unfinishedDownloadSegment) {
 
 	// distribute the segment to workers, marking the number of workers that have received the work.
+	// the workers are ranked by price, latency and reliability, only the best ones
+	// are invited to download, and the others are put on standby.
 	client.lock.Lock()
 	uds.mu.Lock()
+	workers := client.rankDownloadWorkers(uds)
+	uds.selectPreferredWorkers(workers)
 	uds.workersRemaining = uint32(len(client.workerPool))
 	uds.mu.Unlock()
-	for _, worker := range client.workerPool {
+	for _, worker := range workers {
 		worker.queueDownloadSegment(uds)
 	}
 	client.lock.Unlock()
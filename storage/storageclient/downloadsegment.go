@@ -83,6 +83,12 @@ type unfinishedDownloadSegment struct {
 	// backup workers that can be used to download when other workers fail
 	workersStandby []*worker
 
+	// the rank of the workers holding a sector of the segment, lower is better
+	sourceRank map[string]int
+
+	// the workers invited to download the segment, which have not processed it yet
+	preferredWorkers map[string]struct{}
+
 	// record how much memory allocated
 	memoryAllocated uint64
 
@@ -113,6 +119,10 @@ func (uds *unfinishedDownloadSegment) cleanUp() {
 	if uds.workersRemaining+uds.sectorsCompleted < uds.erasureCode.MinSectors() && !uds.failed {
 		uds.fail(errors.New("not enough workers to continue download"))
 	}
+	// the download is cancelled or failed by another segment
+	if !uds.failed && !uds.recoveryComplete && uds.download.isComplete() {
+		uds.fail(errOperationCancelled)
+	}
 	// return any excess memory.
 	uds.returnMemory()
 
@@ -124,18 +134,17 @@ func (uds *unfinishedDownloadSegment) cleanUp() {
 
 	// check whether standby workers are required.
 	segmentComplete := uds.sectorsCompleted >= uds.erasureCode.MinSectors()
+	// the invited workers which have not processed the segment yet are counted as registered.
 	desiredSectorsRegistered := uds.erasureCode.MinSectors() + uds.overdrive - uds.sectorsCompleted
-	standbyWorkersRequired := !segmentComplete && uds.sectorsRegistered < desiredSectorsRegistered
+	sectorsPending := uds.sectorsRegistered + uint32(len(uds.preferredWorkers))
+	standbyWorkersRequired := !segmentComplete && sectorsPending < desiredSectorsRegistered
 	if !standbyWorkersRequired {
 		uds.mu.Unlock()
 		return
 	}
 
-	var standbyWorkers []*worker
-	for i := 0; i < len(uds.workersStandby); i++ {
-		standbyWorkers = append(standbyWorkers, uds.workersStandby[i])
-	}
-	uds.workersStandby = uds.workersStandby[:0]
+	// promote the best ranked standby workers to fill the gap
+	standbyWorkers := uds.promoteStandbyWorkers(int(desiredSectorsRegistered - sectorsPending))
 	uds.mu.Unlock()
 	for i := 0; i < len(standbyWorkers); i++ {
 		standbyWorkers[i].queueDownloadSegment(uds)
@@ -241,6 +250,8 @@ func (uds *unfinishedDownloadSegment) recoverLogicalData() error {
 	// update the download and signal completion of this segment.
 	uds.download.mu.Lock()
 	defer uds.download.mu.Unlock()
+	uds.download.dataReceived += uds.fetchLength
+	uds.download.operation.addProgress(uds.fetchLength, 0)
 	uds.download.segmentsRemaining--
 	if uds.download.segmentsRemaining == 0 {
 		uds.download.markComplete()
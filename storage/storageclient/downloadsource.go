@@ -0,0 +1,186 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package storageclient
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// downloadSource is a worker able to serve a sector of the segment, with the
+// measurements used to rank it against the other workers
+type downloadSource struct {
+	worker      *worker
+	price       float64
+	latency     time.Duration
+	reliability float64
+	score       float64
+}
+
+// rankDownloadWorkers sorts all workers in the worker pool by how suitable they are
+// to download a sector of the segment. Workers within the latency target come first,
+// and they are sorted by the cost per byte weighted by latency and recent reliability.
+// Workers not holding a sector of the segment come last. The rank of each worker is
+// recorded in the segment so that standby workers can be promoted in order.
+//
+// NOTE: client.lock should be held when calling this function
+func (client *StorageClient) rankDownloadWorkers(uds *unfinishedDownloadSegment) []*worker {
+	var sources []*downloadSource
+	var others []*worker
+	var maxPrice float64
+
+	for _, w := range client.workerPool {
+		if _, exists := uds.segmentMap[w.hostID.String()]; !exists {
+			others = append(others, w)
+			continue
+		}
+		source := &downloadSource{
+			worker:      w,
+			latency:     w.downloadLatency(),
+			reliability: w.downloadReliability(),
+		}
+		if hostInfo, ok := client.storageHostManager.RetrieveHostInfo(w.hostID); ok {
+			source.price = hostInfo.DownloadBandwidthPrice.Float64()
+			total := hostInfo.RecentSuccessfulInteractions + hostInfo.RecentFailedInteractions
+			source.reliability *= (hostInfo.RecentSuccessfulInteractions + 1) / (total + 2)
+		}
+		maxPrice = math.Max(maxPrice, source.price)
+		sources = append(sources, source)
+	}
+
+	for _, source := range sources {
+		source.score = downloadSourceScore(source, maxPrice, uds.latencyTarget)
+	}
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].score < sources[j].score
+	})
+
+	workers := make([]*worker, 0, len(sources)+len(others))
+	uds.sourceRank = make(map[string]int)
+	for i, source := range sources {
+		workers = append(workers, source.worker)
+		uds.sourceRank[source.worker.hostID.String()] = i
+	}
+	return append(workers, others...)
+}
+
+// downloadSourceScore calculates the score of a download source, where lower is better.
+// The price is normalized by the highest price among the sources, and the latency is
+// normalized by the latency target. Sources exceeding the latency target are always
+// scored worse than the sources within the target, and still ordered among themselves
+func downloadSourceScore(source *downloadSource, maxPrice float64, latencyTarget time.Duration) float64 {
+	var priceRatio, latencyRatio float64
+	if maxPrice > 0 {
+		priceRatio = source.price / maxPrice
+	}
+	if latencyTarget > 0 {
+		latencyRatio = float64(source.latency) / float64(latencyTarget)
+	}
+
+	score := DownloadSourcePriceWeight*priceRatio + DownloadSourceLatencyWeight*math.Min(latencyRatio, 1)
+	score /= math.Max(source.reliability, minDownloadSourceReliability)
+	if latencyRatio > 1 {
+		// the highest score possible for the sources within the latency target
+		score += (DownloadSourcePriceWeight + DownloadSourceLatencyWeight) / minDownloadSourceReliability
+	}
+	return score
+}
+
+// selectPreferredWorkers invites the workers of the minimal download set, followed by the
+// overdrive workers, to download the segment. The rest of the workers holding a sector will
+// be put on standby
+//
+// NOTE: uds.mu should be held when calling this function
+func (uds *unfinishedDownloadSegment) selectPreferredWorkers(workers []*worker) {
+	minimal, extra := uds.minimalSourceSet(workers)
+	desired := int(uds.erasureCode.MinSectors() + uds.overdrive)
+	uds.preferredWorkers = make(map[string]struct{})
+	for _, w := range append(minimal, extra...) {
+		if len(uds.preferredWorkers) >= desired {
+			return
+		}
+		uds.preferredWorkers[w.hostID.String()] = struct{}{}
+	}
+}
+
+// minimalSourceSet solves the lowest scored set of workers holding enough distinct sectors
+// to recover the segment. Workers holding the same sector are redundant, as the erasure code
+// requires MinSectors distinct sectors. Since a host holds at most one sector of a segment,
+// the worker sets holding distinct sectors form a partition matroid, thus picking the best
+// ranked worker of each sector not yet covered gives the optimal set. The workers holding the
+// other distinct sectors are returned in rank order as the extra sources for overdrive
+//
+// NOTE: the workers should be sorted by rankDownloadWorkers
+func (uds *unfinishedDownloadSegment) minimalSourceSet(workers []*worker) (minimal, extra []*worker) {
+	minSectors := int(uds.erasureCode.MinSectors())
+	covered := make(map[uint64]struct{})
+	for _, w := range workers {
+		if _, ranked := uds.sourceRank[w.hostID.String()]; !ranked {
+			continue
+		}
+		index := uds.segmentMap[w.hostID.String()].index
+		if _, exists := covered[index]; exists {
+			continue
+		}
+		covered[index] = struct{}{}
+		if len(minimal) < minSectors {
+			minimal = append(minimal, w)
+		} else {
+			extra = append(extra, w)
+		}
+	}
+	return
+}
+
+// promoteStandbyWorkers removes the best ranked standby workers from the standby list
+// and invites them to download the segment
+//
+// NOTE: uds.mu should be held when calling this function
+func (uds *unfinishedDownloadSegment) promoteStandbyWorkers(needed int) []*worker {
+	sort.SliceStable(uds.workersStandby, func(i, j int) bool {
+		return uds.sourceRank[uds.workersStandby[i].hostID.String()] < uds.sourceRank[uds.workersStandby[j].hostID.String()]
+	})
+	if needed > len(uds.workersStandby) {
+		needed = len(uds.workersStandby)
+	}
+
+	promoted := make([]*worker, needed)
+	copy(promoted, uds.workersStandby[:needed])
+	uds.workersStandby = append(uds.workersStandby[:0], uds.workersStandby[needed:]...)
+	if uds.preferredWorkers != nil {
+		for _, w := range promoted {
+			uds.preferredWorkers[w.hostID.String()] = struct{}{}
+		}
+	}
+	return promoted
+}
+
+// downloadLatency returns the average latency observed for the worker to download a sector
+func (w *worker) downloadLatency() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ownedDownloadLatency
+}
+
+// updateDownloadLatency updates the average sector download latency of the worker
+// with the newly observed latency
+func (w *worker) updateDownloadLatency(latency time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ownedDownloadLatency == 0 {
+		w.ownedDownloadLatency = latency
+		return
+	}
+	w.ownedDownloadLatency = time.Duration(float64(w.ownedDownloadLatency)*(1-downloadLatencyDecay) + float64(latency)*downloadLatencyDecay)
+}
+
+// downloadReliability returns the reliability of the worker, which is halved for
+// every consecutive download failure
+func (w *worker) downloadReliability() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return math.Pow(0.5, float64(w.ownedDownloadConsecutiveFailures))
+}
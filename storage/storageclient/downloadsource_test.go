@@ -0,0 +1,112 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage/storageclient/erasurecode"
+)
+
+func TestDownloadSourceScore(t *testing.T) {
+	latencyTarget := 10 * time.Second
+	maxPrice := float64(100)
+
+	cheap := &downloadSource{price: 10, latency: time.Second, reliability: 1}
+	expensive := &downloadSource{price: 100, latency: time.Second, reliability: 1}
+	unreliable := &downloadSource{price: 10, latency: time.Second, reliability: 0.1}
+	slow := &downloadSource{price: 1, latency: 20 * time.Second, reliability: 1}
+
+	cheapScore := downloadSourceScore(cheap, maxPrice, latencyTarget)
+	expensiveScore := downloadSourceScore(expensive, maxPrice, latencyTarget)
+	unreliableScore := downloadSourceScore(unreliable, maxPrice, latencyTarget)
+	slowScore := downloadSourceScore(slow, maxPrice, latencyTarget)
+
+	if cheapScore >= expensiveScore {
+		t.Errorf("cheap host should be scored better than expensive host: %v >= %v", cheapScore, expensiveScore)
+	}
+	if cheapScore >= unreliableScore {
+		t.Errorf("reliable host should be scored better than unreliable host: %v >= %v", cheapScore, unreliableScore)
+	}
+	if slowScore <= expensiveScore || slowScore <= unreliableScore {
+		t.Errorf("host exceeding the latency target should be scored worst: %v", slowScore)
+	}
+	slowExpensive := &downloadSource{price: 100, latency: 20 * time.Second, reliability: 1}
+	if slowScore >= downloadSourceScore(slowExpensive, maxPrice, latencyTarget) {
+		t.Errorf("hosts exceeding the latency target should still be ordered by price")
+	}
+}
+
+func TestUnfinishedDownloadSegment_MinimalSourceSet(t *testing.T) {
+	ec, err := erasurecode.New(erasurecode.ECTypeStandard, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uds := &unfinishedDownloadSegment{
+		erasureCode: ec,
+		overdrive:   1,
+		segmentMap:  make(map[string]downloadSectorInfo),
+		sourceRank:  make(map[string]int),
+	}
+	// ranked workers holding the sectors 0, 0, 1, 1, 2, and a worker holding no sector
+	var workers []*worker
+	for i, index := range []uint64{0, 0, 1, 1, 2} {
+		w := &worker{hostID: enode.ID{byte(i)}}
+		uds.segmentMap[w.hostID.String()] = downloadSectorInfo{index: index}
+		uds.sourceRank[w.hostID.String()] = i
+		workers = append(workers, w)
+	}
+	workers = append(workers, &worker{hostID: enode.ID{byte(5)}})
+
+	minimal, extra := uds.minimalSourceSet(workers)
+	if len(minimal) != 2 || minimal[0] != workers[0] || minimal[1] != workers[2] {
+		t.Fatalf("minimal set should be the best ranked worker of distinct sectors, got %v", minimal)
+	}
+	if len(extra) != 1 || extra[0] != workers[4] {
+		t.Fatalf("extra sources should hold the other distinct sectors, got %v", extra)
+	}
+
+	uds.selectPreferredWorkers(workers)
+	for _, i := range []int{0, 2, 4} {
+		if _, invited := uds.preferredWorkers[workers[i].hostID.String()]; !invited {
+			t.Errorf("worker %v should be invited", i)
+		}
+	}
+	if len(uds.preferredWorkers) != 3 {
+		t.Errorf("expect 3 invited workers, got %v", len(uds.preferredWorkers))
+	}
+}
+
+func TestUnfinishedDownloadSegment_PromoteStandbyWorkers(t *testing.T) {
+	uds := &unfinishedDownloadSegment{
+		sourceRank:       make(map[string]int),
+		preferredWorkers: make(map[string]struct{}),
+	}
+	for i := 0; i < 5; i++ {
+		w := &worker{hostID: enode.ID{byte(i)}}
+		uds.sourceRank[w.hostID.String()] = 4 - i
+		uds.workersStandby = append(uds.workersStandby, w)
+	}
+
+	promoted := uds.promoteStandbyWorkers(2)
+	if len(promoted) != 2 || len(uds.workersStandby) != 3 {
+		t.Fatalf("expect 2 promoted and 3 standby, got %v and %v", len(promoted), len(uds.workersStandby))
+	}
+	for i, w := range promoted {
+		if uds.sourceRank[w.hostID.String()] != i {
+			t.Errorf("promoted worker %v has rank %v", i, uds.sourceRank[w.hostID.String()])
+		}
+		if _, invited := uds.preferredWorkers[w.hostID.String()]; !invited {
+			t.Errorf("promoted worker %v is not invited", i)
+		}
+	}
+
+	promoted = uds.promoteStandbyWorkers(10)
+	if len(promoted) != 3 || len(uds.workersStandby) != 0 {
+		t.Fatalf("expect 3 promoted and 0 standby, got %v and %v", len(promoted), len(uds.workersStandby))
+	}
+}
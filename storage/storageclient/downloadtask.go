@@ -67,6 +67,9 @@ type (
 		// higher priority will complete first.
 		priority uint64
 
+		// the download operation, which can be cancelled
+		operation *operation
+
 		// Utilities.
 		log           log.Logger
 		memoryManager *memorymanager.MemoryManager
@@ -107,6 +110,9 @@ type (
 
 		// higher priority download first
 		priority uint64
+
+		// the download operation, nil if the download is not registered as an operation
+		operation *operation
 	}
 
 	// a function type that is called when the download completed.
@@ -68,6 +68,35 @@ func TestStandardErasureCode_Encode_Recover(t *testing.T) {
 	}
 }
 
+// TestStandardErasureCode_Encode_NumSectors test the sectors encoded with the same minSectors
+// are the same regardless of numSectors, which the re-encode of the dx file relies on
+func TestStandardErasureCode_Encode_NumSectors(t *testing.T) {
+	data := randomBytes(4096)
+	base, err := newStandardErasureCode(10, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := base.Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, numSectors := range []uint32{11, 30, 40} {
+		sec, err := newStandardErasureCode(10, numSectors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := sec.Encode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < len(expect) && i < len(encoded); i++ {
+			if !bytes.Equal(encoded[i], expect[i]) {
+				t.Errorf("numSectors %d: sector %d not equal", numSectors, i)
+			}
+		}
+	}
+}
+
 func BenchmarkStandardErasureCode_Encode(b *testing.B) {
 	rsc, err := newStandardErasureCode(80, 100)
 	if err != nil {
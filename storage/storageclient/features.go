@@ -0,0 +1,26 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// featureFlags returns the feature flags of the storage client. The feature flags persisted
+// are validated when loaded, so that they are always parsed
+func (client *StorageClient) featureFlags() storage.FeatureFlags {
+	flags, err := storage.ParseFeatureFlags(client.settings.get().FeatureFlags)
+	if err != nil {
+		return storage.DefaultFeatureFlags()
+	}
+	return flags
+}
+
+// sessionFeatures returns the features negotiated with the storage host for the session,
+// which are the features active on both the storage client and the storage host
+func (client *StorageClient) sessionFeatures(hostInfo *storage.HostInfo) []storage.Feature {
+	local := client.featureFlags().Active(client.ethBackend.GetCurrentBlockHeight())
+	return storage.NegotiateFeatures(local, hostInfo.Features)
+}
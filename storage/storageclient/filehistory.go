@@ -0,0 +1,188 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// The operation history of a file records the uploads, repairs and migrations of the file, and
+// is stored alongside the dxfile. The contract renewals with the storage hosts of the file are
+// not recorded, but derived from the renew chain of the contracts when the history is queried
+
+// File event types
+const (
+	FileEventUpload  = "upload"
+	FileEventRepair  = "repair"
+	FileEventMigrate = "migrate"
+	FileEventRenew   = "renew"
+)
+
+// fileHistoryMetadata contains the header and version of the file history
+var fileHistoryMetadata = common.Metadata{
+	Header:  FileHistoryHeader,
+	Version: FileHistoryVersion,
+}
+
+// FileEvent is an operation affecting where the file is stored. For the repair and the
+// migration, the hosts are the storage hosts the sectors of the segment were uploaded to, and
+// the replaced hosts are the storage hosts whose sectors were no longer counted. For the
+// renewal, the hosts is the storage host the contract was renewed with
+type FileEvent struct {
+	Type          string             `json:"type"`
+	Time          time.Time          `json:"time"`
+	BlockHeight   uint64             `json:"blockheight"`
+	Segment       uint64             `json:"segment"`
+	Hosts         []enode.ID         `json:"hosts"`
+	ReplacedHosts []enode.ID         `json:"replacedhosts,omitempty"`
+	OldContract   storage.ContractID `json:"oldcontract,omitempty"`
+	NewContract   storage.ContractID `json:"newcontract,omitempty"`
+}
+
+// fileHistoryPath returns the path of the file history stored alongside the dxfile
+func fileHistoryPath(dxFilePath string) string {
+	return strings.TrimSuffix(dxFilePath, storage.DxFileExt) + fileHistoryExt
+}
+
+// loadFileHistory loads the events from the file history. The file without history has no events
+func loadFileHistory(path string) (events []FileEvent, err error) {
+	if _, err = os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	err = common.LoadDxJSON(fileHistoryMetadata, path, &events)
+	return
+}
+
+// appendFileHistory appends the event to the file history, keeping the most recent
+// maxFileHistoryEvents events
+func appendFileHistory(path string, event FileEvent) error {
+	events, err := loadFileHistory(path)
+	if err != nil {
+		return err
+	}
+	events = append(events, event)
+	if len(events) > maxFileHistoryEvents {
+		events = events[len(events)-maxFileHistoryEvents:]
+	}
+	return common.SaveDxJSON(fileHistoryMetadata, path, events)
+}
+
+// removeFileHistory removes the file history stored alongside the dxfile if exists
+func removeFileHistory(dxFilePath string) error {
+	path := fileHistoryPath(dxFilePath)
+	for _, p := range []string{path, path + "_temp"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordFileEvent records the event in the history of the file, at the current time and
+// block height
+func (client *StorageClient) recordFileEvent(dxPath storage.DxPath, event FileEvent) {
+	if err := client.tm.Add(); err != nil {
+		return
+	}
+	defer client.tm.Done()
+
+	entry, err := client.fileSystem.OpenDxFile(dxPath)
+	if err != nil {
+		client.log.Warn("failed to open the file to record its history", "dxpath", dxPath.Path, "err", err)
+		return
+	}
+	if len(event.Hosts) == 0 {
+		event.Hosts = entry.HostIDs()
+	}
+	path := fileHistoryPath(entry.FilePath())
+	entry.Close()
+
+	event.Time = client.clock.Now()
+	event.BlockHeight = client.ethBackend.GetCurrentBlockHeight()
+
+	client.fileHistoryLock.Lock()
+	defer client.fileHistoryLock.Unlock()
+	if err := appendFileHistory(path, event); err != nil {
+		client.log.Warn("failed to record the file history", "dxpath", dxPath.Path, "type", event.Type, "err", err)
+	}
+}
+
+// recordSegmentRepair records the repair of the segment completed by the repair loops. The
+// repair replacing the sectors of the storage hosts no longer used is recorded as migration
+func (client *StorageClient) recordSegmentRepair(uc *unfinishedUploadSegment) {
+	uc.mu.Lock()
+	event := FileEvent{
+		Type:          FileEventRepair,
+		Segment:       uc.index,
+		Hosts:         append([]enode.ID{}, uc.uploadedHosts...),
+		ReplacedHosts: append([]enode.ID{}, uc.replacedHosts...),
+	}
+	uc.mu.Unlock()
+
+	if len(event.Hosts) == 0 {
+		return
+	}
+	if len(event.ReplacedHosts) != 0 {
+		event.Type = FileEventMigrate
+	}
+	client.recordFileEvent(uc.fileEntry.DxPath(), event)
+}
+
+// FileHistory returns the operation history of the file ordered by the block height, including
+// the renewals of the contracts with the storage hosts currently storing the file
+func (client *StorageClient) FileHistory(dxPath storage.DxPath) ([]FileEvent, error) {
+	entry, err := client.fileSystem.OpenDxFile(dxPath)
+	if err != nil {
+		return nil, err
+	}
+	hosts := entry.HostIDs()
+	path := fileHistoryPath(entry.FilePath())
+	entry.Close()
+
+	client.fileHistoryLock.Lock()
+	events, err := loadFileHistory(path)
+	client.fileHistoryLock.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the history of %v: %v", dxPath.Path, err)
+	}
+
+	var renewals []FileEvent
+	for _, host := range hosts {
+		for _, renewal := range client.contractManager.RetrieveRenewals(host) {
+			renewals = append(renewals, FileEvent{
+				Type:        FileEventRenew,
+				BlockHeight: renewal.BlockHeight,
+				Hosts:       []enode.ID{host},
+				OldContract: renewal.OldContract,
+				NewContract: renewal.NewContract,
+			})
+		}
+	}
+	return mergeFileHistory(events, renewals), nil
+}
+
+// mergeFileHistory merges the contract renewals into the recorded events, ordered by the block
+// height. The renewals before the earliest event recorded do not affect the file
+func mergeFileHistory(events, renewals []FileEvent) []FileEvent {
+	merged := append([]FileEvent{}, events...)
+	for _, renewal := range renewals {
+		if len(events) != 0 && renewal.BlockHeight < events[0].BlockHeight {
+			continue
+		}
+		merged = append(merged, renewal)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].BlockHeight < merged[j].BlockHeight
+	})
+	return merged
+}
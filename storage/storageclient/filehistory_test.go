@@ -0,0 +1,61 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestFileHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filehistory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dxFilePath := filepath.Join(dir, "file"+storage.DxFileExt)
+	path := fileHistoryPath(dxFilePath)
+	if events, err := loadFileHistory(path); err != nil || len(events) != 0 {
+		t.Fatalf("the file without history shall have no events. Got %v, %v", events, err)
+	}
+
+	// only the most recent events are kept
+	for i := 0; i != maxFileHistoryEvents+2; i++ {
+		event := FileEvent{Type: FileEventRepair, BlockHeight: uint64(10 + i), Hosts: []enode.ID{{0x01}}}
+		if err := appendFileHistory(path, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events, err := loadFileHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != maxFileHistoryEvents || events[0].BlockHeight != 12 {
+		t.Fatalf("file history not expected. Got %v events from height %v", len(events), events[0].BlockHeight)
+	}
+
+	// the renewals before the earliest event are dropped, and the rest are ordered by height
+	renewals := []FileEvent{
+		{Type: FileEventRenew, BlockHeight: 5},
+		{Type: FileEventRenew, BlockHeight: 20},
+	}
+	merged := mergeFileHistory(events[:10], renewals)
+	if len(merged) != 11 || merged[9].Type != FileEventRenew || merged[9].BlockHeight != 20 {
+		t.Fatalf("merged file history not expected. Got %+v", merged)
+	}
+
+	if err := removeFileHistory(dxFilePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("the file history shall be removed")
+	}
+}
@@ -146,3 +146,22 @@ func (api *PublicFileSystemAPI) Delete(path string) string {
 	}
 	return fmt.Sprintf("File %v deleted", path)
 }
+
+// SetPriority set the repair priority of a file specified by the path. Files with higher
+// priority are repaired before the others
+func (api *PublicFileSystemAPI) SetPriority(path string, priority uint32) string {
+	dxPath, err := storage.NewDxPath(path)
+	if err != nil {
+		return fmt.Sprintf("Path not valid: %v", path)
+	}
+	entry, err := api.fs.OpenDxFile(dxPath)
+	if err != nil {
+		return fmt.Sprintf("Cannot open file %v: %v", path, err)
+	}
+	defer entry.Close()
+
+	if err = entry.SetPriority(priority); err != nil {
+		return fmt.Sprintf("Cannot set priority of file %v: %v", path, err)
+	}
+	return fmt.Sprintf("File %v priority set to %v", path, priority)
+}
@@ -35,6 +35,14 @@ const (
 
 	// updateWalName is the fileName for the updateWal
 	updateWalName = "update.wal"
+
+	// lockName is the fileName for the lock preventing the file system from being opened
+	// by multiple processes
+	lockName = "filesystem.lock"
+
+	// repairTempSuffix is the suffix of the temporary file written when a DxFile or DxDir
+	// is repaired from its mirror
+	repairTempSuffix = ".repair"
 )
 
 const (
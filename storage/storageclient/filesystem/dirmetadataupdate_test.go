@@ -535,7 +535,11 @@ func TestFileSystem_FailedRecover(t *testing.T) {
 	// Check that the disrupter has been accessed twice
 	fs.postTestCheck(t, true, false, defaultMd)
 
-	// Restart the filesystem with always success contractManager. The metadata should be updated as expected
+	// Restart the filesystem with always success contractManager. The metadata should be updated as expected.
+	// The lock is released as the previous process exited
+	if err = fs.fileLock.Release(); err != nil {
+		t.Fatal(err)
+	}
 	newFs := newFileSystem(string(persistDir), &AlwaysSuccessContractManager{}, newStandardDisrupter())
 	if err = newFs.Start(); err != nil {
 		t.Fatal(err)
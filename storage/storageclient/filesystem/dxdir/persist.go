@@ -76,7 +76,7 @@ func (d *DxDir) save() error {
 	if err != nil {
 		return err
 	}
-	return storage.ApplyUpdates(d.wal, []storage.FileUpdate{fu})
+	return storage.ApplyMirroredUpdates(d.wal, []storage.FileUpdate{fu})
 }
 
 // delete create and apply the delete update
@@ -89,7 +89,7 @@ func (d *DxDir) delete() error {
 	if err != nil {
 		return err
 	}
-	return storage.ApplyUpdates(d.wal, []storage.FileUpdate{fu})
+	return storage.ApplyMirroredUpdates(d.wal, []storage.FileUpdate{fu})
 }
 
 // load load the DxDir metadata.
@@ -111,3 +111,9 @@ func load(dirFilePath storage.SysPath, wal *writeaheadlog.Wal) (*DxDir, error) {
 	d.dirFilePath = dirFilePath
 	return d, nil
 }
+
+// Check checks whether the DxDir persisted at the path could be loaded
+func Check(dirFilePath storage.SysPath) error {
+	_, err := load(dirFilePath, nil)
+	return err
+}
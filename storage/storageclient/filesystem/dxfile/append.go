@@ -0,0 +1,46 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dxfile
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrAppendSize is the error for appending to a file with the size not larger than the file
+var ErrAppendSize = errors.New("the file size after append must be larger than the current file size")
+
+// Append extends the DxFile to newFileSize with the data appended to the end of the file, and
+// returns the indexes of the segments to upload. The segments added have no sectors, and the
+// segments full of data are kept untouched. The last segment not full of data is erasure coded
+// again along with the data appended to it, thus its sectors are dropped to be uploaded again,
+// and it is no longer a hole
+func (df *DxFile) Append(newFileSize uint64) ([]int, error) {
+	df.lock.Lock()
+	defer df.lock.Unlock()
+
+	if df.deleted {
+		return nil, fmt.Errorf("file already deleted")
+	}
+	if newFileSize <= df.metadata.FileSize {
+		return nil, ErrAppendSize
+	}
+
+	var indexes []int
+	if last := len(df.segments) - 1; df.metadata.FileSize == 0 || df.metadata.FileSize%df.metadata.segmentSize() != 0 {
+		df.segments[last].Sectors = make([][]*Sector, df.metadata.NumSectors)
+		df.segments[last].Stuck = false
+		df.unmarkHole(last)
+		indexes = append(indexes, last)
+	}
+
+	df.metadata.FileSize = newFileSize
+	for i := uint64(len(df.segments)); i < df.metadata.numSegments(); i++ {
+		df.segments = append(df.segments, &Segment{Sectors: make([][]*Sector, df.metadata.NumSectors), Index: i})
+		indexes = append(indexes, int(i))
+	}
+	df.metadata.TimeModify = unixNow()
+	return indexes, df.saveAll()
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dxfile
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/erasurecode"
+)
+
+// TestAppend test DxFile.Append function
+func TestAppend(t *testing.T) {
+	tests := []struct {
+		numSegments   float64
+		newSegments   float64
+		expectIndexes []int
+	}{
+		{2.5, 4.5, []int{2, 3, 4}},
+		{2, 3.5, []int{2, 3}},
+		{2.5, 2.75, []int{2}},
+	}
+	for i, test := range tests {
+		df, err := newTestDxFile(t, 1, 10, 30, erasurecode.ECTypeStandard)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segmentSize := float64(df.metadata.segmentSize())
+		df.metadata.FileSize = uint64(test.numSegments * segmentSize)
+		df.segments = nil
+		for j := 0; uint64(j) != df.metadata.numSegments(); j++ {
+			seg := randomSegment(df.metadata.NumSectors)
+			seg.Index = uint64(j)
+			df.segments = append(df.segments, seg)
+			for _, sectors := range seg.Sectors {
+				for _, sector := range sectors {
+					df.hostTable[sector.HostID] = true
+				}
+			}
+		}
+		if err = df.saveAll(); err != nil {
+			t.Fatal(err)
+		}
+		full := make([]Segment, int(test.numSegments))
+		for j := range full {
+			full[j] = copySegment(df.segments[j])
+		}
+
+		indexes, err := df.Append(uint64(test.newSegments * segmentSize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(indexes, test.expectIndexes) {
+			t.Errorf("test %d: expect indexes %v, got %v", i, test.expectIndexes, indexes)
+		}
+
+		path, err := storage.NewDxPath(t.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		recoveredDF, err := readDxFile(testDir.Join(path), df.wal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = checkDxFileEqual(df, recoveredDF); err != nil {
+			t.Errorf("test %d: %v", i, err)
+		}
+		for j, seg := range full {
+			if !reflect.DeepEqual(copySectors(recoveredDF.segments[j]), seg.Sectors) {
+				t.Errorf("test %d: the full segment %d is changed", i, j)
+			}
+		}
+		for _, index := range indexes {
+			for _, sectors := range recoveredDF.segments[index].Sectors {
+				if len(sectors) != 0 {
+					t.Errorf("test %d: the segment %d to upload has sectors", i, index)
+				}
+			}
+		}
+	}
+}
+
+// TestAppendSize test DxFile.Append with the size not larger than the file
+func TestAppendSize(t *testing.T) {
+	df, err := newTestDxFileWithSegments(t, sectorSize*64, 10, 30, erasurecode.ECTypeStandard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := df.Append(sectorSize * 64); err != ErrAppendSize {
+		t.Errorf("expect error %v, got %v", ErrAppendSize, err)
+	}
+	if _, err := df.Append(sectorSize); err != ErrAppendSize {
+		t.Errorf("expect error %v, got %v", ErrAppendSize, err)
+	}
+}
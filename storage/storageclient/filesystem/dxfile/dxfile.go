@@ -29,7 +29,11 @@ const (
 	SectorSize = uint64(1 << 22)
 
 	// Version is the version of dxfile
-	Version = "1.0.0"
+	Version = "1.0.6"
+
+	// MaxPinnedHosts is the maximum number of hosts a DxFile could be pinned to, which keeps
+	// the metadata within a single page
+	MaxPinnedHosts = 32
 )
 
 type (
@@ -53,6 +57,11 @@ type (
 		// filePath is full file path
 		filePath storage.SysPath
 
+		// dirtyStuck is the indexes of the segments with the stuck flag journaled but not
+		// persisted yet, and stuckFlushTimer is the timer to persist them
+		dirtyStuck      map[int]struct{}
+		stuckFlushTimer *time.Timer
+
 		//cached field
 		erasureCode erasurecode.ErasureCoder
 		cipherKey   crypto.CipherKey
@@ -188,10 +197,10 @@ func (df *DxFile) AddSector(address enode.ID, merkleRoot common.Hash, segmentInd
 	// Update the hostTable
 	df.hostTable[address] = true
 	// Params validation
-	if segmentIndex > len(df.segments) {
+	if segmentIndex >= len(df.segments) {
 		return fmt.Errorf("segment Index %d out of bound %d", segmentIndex, len(df.segments))
 	}
-	if uint32(sectorIndex) > df.metadata.NumSectors {
+	if uint32(sectorIndex) >= df.metadata.NumSectors {
 		return fmt.Errorf("sector Index %d out of bound %d", sectorIndex, df.metadata.NumSectors)
 	}
 	df.segments[segmentIndex].Sectors[sectorIndex] = append(df.segments[segmentIndex].Sectors[sectorIndex],
@@ -217,7 +226,7 @@ func (df *DxFile) Delete() error {
 		return err
 	}
 	df.deleted = true
-	return nil
+	return df.clearStuckJournal()
 }
 
 // Deleted return the dxfile status of whether it is deleted
@@ -399,38 +408,6 @@ func (df *DxFile) GetRedundancy() uint32 {
 	return df.metadata.LastRedundancy
 }
 
-// SetStuckByIndex set a Segment of Index to the value of Stuck.
-func (df *DxFile) SetStuckByIndex(index int, stuck bool) (err error) {
-	df.lock.Lock()
-	defer df.lock.Unlock()
-
-	if df.deleted {
-		return fmt.Errorf("file %v is deleted", df.metadata.DxPath)
-	}
-
-	if stuck == df.segments[index].Stuck {
-		return nil
-	}
-	// if error happens, revert the change
-	prevNumStuckSegments := df.metadata.NumStuckSegments
-	prevStuck := df.segments[index].Stuck
-	defer func() {
-		if err != nil {
-			df.metadata.NumStuckSegments = prevNumStuckSegments
-			df.segments[index].Stuck = prevStuck
-		}
-	}()
-	df.segments[index].Stuck = stuck
-	if stuck {
-		df.metadata.NumStuckSegments++
-	} else {
-		df.metadata.NumStuckSegments--
-	}
-
-	err = df.saveSegments([]int{index})
-	return
-}
-
 // GetStuckByIndex get the Stuck status of the indexed Segment
 func (df *DxFile) GetStuckByIndex(index int) bool {
 	df.lock.Lock()
@@ -445,11 +422,11 @@ func (df *DxFile) UID() FileID {
 }
 
 // UploadedBytes return the uploaded bytes. The uploaded bytes is calculated by number of
-// sectors in df.segments
+// sectors in df.segments, and the holes are counted as fully uploaded
 func (df *DxFile) UploadedBytes() uint64 {
 	df.lock.RLock()
 	defer df.lock.RUnlock()
-	var uploaded uint64
+	uploaded := numSegmentsInRanges(df.metadata.Holes) * SectorSize * uint64(df.metadata.NumSectors)
 	for _, segment := range df.segments {
 		for _, sectors := range segment.Sectors {
 			uploaded += SectorSize * uint64(len(sectors))
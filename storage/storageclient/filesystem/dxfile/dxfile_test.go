@@ -270,6 +270,9 @@ func TestSetStuckByIndex(t *testing.T) {
 		if df.GetStuckByIndex(0) != test.setStuck {
 			t.Errorf("test %d: Stuck not expected. Expect %v, Got %v", i, test.setStuck, df.segments[0].Stuck)
 		}
+		if err = df.FlushStuck(); err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
 		path, err := storage.NewDxPath(t.Name())
 		if err != nil {
 			t.Fatal(err)
@@ -285,6 +288,66 @@ func TestSetStuckByIndex(t *testing.T) {
 	}
 }
 
+// TestStuckJournal test the stuck flags not persisted are replayed from the stuck journal
+// once the DxFile is read, and the stuck flags are persisted in a batch
+func TestStuckJournal(t *testing.T) {
+	df, err := newTestDxFile(t, sectorSize*10*4, 10, 30, erasurecode.ECTypeStandard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = df.SetStuckByIndex(1, true); err != nil {
+		t.Fatal(err)
+	}
+	if err = df.SetStuckByIndex(2, true); err != nil {
+		t.Fatal(err)
+	}
+	if err = df.SetStuckByIndex(2, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(df.dirtyStuck) != 2 || df.stuckFlushTimer == nil {
+		t.Fatalf("the stuck flags should be journaled, got %v dirty segments", len(df.dirtyStuck))
+	}
+	// drop the pending flush, as if the node crashed
+	df.stuckFlushTimer.Stop()
+
+	recovered, err := readDxFile(df.filePath, df.wal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range df.segments {
+		if recovered.segments[i].Stuck != df.segments[i].Stuck {
+			t.Errorf("segment %d: Stuck not expected. Expect %v, Got %v", i, df.segments[i].Stuck, recovered.segments[i].Stuck)
+		}
+	}
+	if recovered.metadata.NumStuckSegments != 1 {
+		t.Errorf("NumStuckSegments not expected. Expect 1, Got %v", recovered.metadata.NumStuckSegments)
+	}
+	if _, err = os.Stat(recovered.stuckJournalPath()); !os.IsNotExist(err) {
+		t.Errorf("the stuck journal should be removed after replayed: %v", err)
+	}
+
+	// reaching the batch size persists the stuck flags immediately
+	prevBatchSize := StuckFlushBatchSize
+	StuckFlushBatchSize = 2
+	defer func() { StuckFlushBatchSize = prevBatchSize }()
+	if err = recovered.SetStuckByIndex(0, true); err != nil {
+		t.Fatal(err)
+	}
+	if err = recovered.SetStuckByIndex(3, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(recovered.dirtyStuck) != 0 || recovered.stuckFlushTimer != nil {
+		t.Errorf("the stuck flags should be persisted after reaching the batch size")
+	}
+	persisted, err := readDxFile(df.filePath, df.wal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !persisted.segments[0].Stuck || !persisted.segments[3].Stuck || persisted.metadata.NumStuckSegments != 3 {
+		t.Errorf("the stuck flags are not persisted")
+	}
+}
+
 // TestUploadProgress test the DxFile.UploadProgress
 func TestUploadProgress(t *testing.T) {
 	fileSegments := uint64(10)
@@ -371,6 +434,59 @@ func TestHostIDs(t *testing.T) {
 	}
 }
 
+// TestPinnedHosts test DxFile.SetPinnedHosts and DxFile.PinViolations
+func TestPinnedHosts(t *testing.T) {
+	df, err := newTestDxFileWithSegments(t, sectorSize*10*10, 10, 30, erasurecode.ECTypeStandard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = df.saveAll(); err != nil {
+		t.Fatal(err)
+	}
+	if df.PinViolations() != 0 || !df.IsPinnedHost(enode.ID{}) {
+		t.Fatal("file not pinned should accept any host")
+	}
+	tooMany := make([]enode.ID, MaxPinnedHosts+1)
+	for i := range tooMany {
+		tooMany[i] = enode.ID{byte(i), 1}
+	}
+	if err = df.SetPinnedHosts(tooMany); err == nil {
+		t.Fatal("pinning too many hosts should return error")
+	}
+
+	// pin the file to the host of the first sector, the others are violations
+	pinned := df.segments[0].Sectors[0][0].HostID
+	var numSectors, numPinned uint32
+	for _, seg := range df.segments {
+		for _, sectors := range seg.Sectors {
+			for _, sector := range sectors {
+				numSectors++
+				if sector.HostID == pinned {
+					numPinned++
+				}
+			}
+		}
+	}
+	if err = df.SetPinnedHosts([]enode.ID{pinned, pinned}); err != nil {
+		t.Fatal(err)
+	}
+	if hosts := df.PinnedHosts(); len(hosts) != 1 || hosts[0] != pinned {
+		t.Fatalf("duplicate pinned hosts should be removed, got %v", hosts)
+	}
+	if violations := df.PinViolations(); violations != numSectors-numPinned {
+		t.Errorf("pin violations not expected. Expect %v, Got %v", numSectors-numPinned, violations)
+	}
+
+	// the pinned hosts are persisted
+	newDF, err := readDxFile(df.filePath, df.wal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !newDF.IsPinnedHost(pinned) || newDF.IsPinnedHost(enode.ID{}) {
+		t.Errorf("pinned hosts not persisted, got %v", newDF.PinnedHosts())
+	}
+}
+
 // TestRename test DxFile.Rename
 func TestRename(t *testing.T) {
 	fileSegments := uint64(10)
@@ -5,6 +5,7 @@
 package dxfile
 
 import (
+	"container/list"
 	"crypto/rand"
 	"encoding/binary"
 	"errors"
@@ -15,12 +16,20 @@ import (
 
 	"github.com/DxChainNetwork/godx/common/writeaheadlog"
 	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/metrics"
 	"github.com/DxChainNetwork/godx/storage"
 	"github.com/DxChainNetwork/godx/storage/storageclient/erasurecode"
 )
 
 const threadDepth = 3
 
+var (
+	metadataCacheHitMeter   = metrics.NewRegisteredMeter("storage/client/filesystem/metadata/hit", nil)
+	metadataCacheMissMeter  = metrics.NewRegisteredMeter("storage/client/filesystem/metadata/miss", nil)
+	metadataCacheEvictMeter = metrics.NewRegisteredMeter("storage/client/filesystem/metadata/evict", nil)
+	metadataResidentGauge   = metrics.NewRegisteredGauge("storage/client/filesystem/metadata/resident", nil)
+)
+
 var (
 	// ErrUnknownFile is the error for opening a file that not exists on disk
 	ErrUnknownFile = errors.New("file not known")
@@ -39,6 +48,12 @@ type (
 		// filesMap is the mapping from dxPath to contents
 		filesMap map[storage.DxPath]*fileSetEntry
 
+		// idleEntries is the list of the entries not opened by any thread, with the least
+		// recently used entry at the back. cacheSize is the maximum number of entries kept
+		// in filesMap, 0 means the entries are released once not opened by any thread
+		idleEntries *list.List
+		cacheSize   int
+
 		lock sync.Mutex
 		wal  *writeaheadlog.Wal
 	}
@@ -52,6 +67,9 @@ type (
 
 		threadMap     map[uint64]threadInfo
 		threadMapLock sync.Mutex
+
+		// idleElement is the element in FileSet.idleEntries, nil if the entry is in use
+		idleElement *list.Element
 	}
 
 	// FileSetEntryWithID is a fileSetEntry with the threadID. FileSetEntryWithID extends DxFile
@@ -71,12 +89,26 @@ type (
 // NewFileSet create a new DxFileSet with provided rootDir and wal.
 func NewFileSet(rootDir storage.SysPath, wal *writeaheadlog.Wal) *FileSet {
 	return &FileSet{
-		rootDir:  rootDir,
-		filesMap: make(map[storage.DxPath]*fileSetEntry),
-		wal:      wal,
+		rootDir:     rootDir,
+		filesMap:    make(map[storage.DxPath]*fileSetEntry),
+		idleEntries: list.New(),
+		wal:         wal,
 	}
 }
 
+// SetCacheSize sets the maximum number of DxFile entries kept in memory. The least recently
+// used entries not opened by any thread are released first, and the entries in use are never
+// released. Size 0 loads the DxFile from disk on demand, and releases it once closed
+func (fs *FileSet) SetCacheSize(size int) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	if size < 0 {
+		size = 0
+	}
+	fs.cacheSize = size
+	fs.evictIdleEntries()
+}
+
 // NewDxFile create a DxFile based on the params given. Return a FileSetEntryWithID that has been
 // registered with threadID in FileSetEntry
 func (fs *FileSet) NewDxFile(dxPath storage.DxPath, sourcePath storage.SysPath, force bool, erasureCode erasurecode.ErasureCoder, cipherKey crypto.CipherKey, fileSize uint64, fileMode os.FileMode) (*FileSetEntryWithID, error) {
@@ -95,7 +127,11 @@ func (fs *FileSet) NewDxFile(dxPath storage.DxPath, sourcePath storage.SysPath,
 	entry := fs.newFileSetEntry(df)
 	threadID := randomThreadID()
 	entry.threadMap[threadID] = newThreadInfo()
+	if prevEntry, exists := fs.filesMap[dxPath]; exists {
+		fs.removeIdleEntry(prevEntry)
+	}
 	fs.filesMap[dxPath] = entry
+	metadataResidentGauge.Update(int64(len(fs.filesMap)))
 	return &FileSetEntryWithID{
 		fileSetEntry: entry,
 		threadID:     threadID,
@@ -129,6 +165,7 @@ func (fs *FileSet) open(dxPath storage.DxPath) (*FileSetEntryWithID, error) {
 	entry, exist := fs.filesMap[dxPath]
 	if !exist {
 		// file not loaded or not exist. Try to read DxFile from disk.
+		metadataCacheMissMeter.Mark(1)
 		df, err := readDxFile(fs.filepath(dxPath), fs.wal)
 		if os.IsNotExist(err) {
 			return nil, ErrUnknownFile
@@ -138,10 +175,14 @@ func (fs *FileSet) open(dxPath storage.DxPath) (*FileSetEntryWithID, error) {
 		}
 		entry = fs.newFileSetEntry(df)
 		fs.filesMap[dxPath] = entry
+		metadataResidentGauge.Update(int64(len(fs.filesMap)))
+	} else {
+		metadataCacheHitMeter.Mark(1)
 	}
 	if entry.Deleted() {
 		return nil, ErrUnknownFile
 	}
+	fs.removeIdleEntry(entry)
 	// Register the threadID
 	threadID := randomThreadID()
 	entry.threadMapLock.Lock()
@@ -174,6 +215,7 @@ func (fs *FileSet) Delete(dxPath storage.DxPath) error {
 	}
 
 	delete(fs.filesMap, entry.metadata.DxPath)
+	metadataResidentGauge.Update(int64(len(fs.filesMap)))
 	return nil
 }
 
@@ -243,9 +285,40 @@ func (fs *FileSet) closeEntry(entry *FileSetEntryWithID) {
 	if currentEntry != entry.fileSetEntry {
 		return
 	}
-	if len(currentEntry.threadMap) == 0 {
+	if len(currentEntry.threadMap) != 0 {
+		return
+	}
+	if fs.cacheSize == 0 || currentEntry.Deleted() {
+		// the stuck flags not persisted stay in the stuck journal if the flush failed
+		currentEntry.FlushStuck()
+		delete(fs.filesMap, entry.metadata.DxPath)
+		metadataResidentGauge.Update(int64(len(fs.filesMap)))
+		return
+	}
+	currentEntry.idleElement = fs.idleEntries.PushFront(currentEntry)
+	fs.evictIdleEntries()
+}
+
+// removeIdleEntry removes the entry from the idle entries, if the entry is not in use
+func (fs *FileSet) removeIdleEntry(entry *fileSetEntry) {
+	if entry.idleElement == nil {
+		return
+	}
+	fs.idleEntries.Remove(entry.idleElement)
+	entry.idleElement = nil
+}
+
+// evictIdleEntries releases the least recently used idle entries until the number of entries
+// kept in filesMap is no more than the cache size, or there are no idle entries left
+func (fs *FileSet) evictIdleEntries() {
+	for len(fs.filesMap) > fs.cacheSize && fs.idleEntries.Len() > 0 {
+		entry := fs.idleEntries.Remove(fs.idleEntries.Back()).(*fileSetEntry)
+		entry.idleElement = nil
+		entry.FlushStuck()
 		delete(fs.filesMap, entry.metadata.DxPath)
+		metadataCacheEvictMeter.Mark(1)
 	}
+	metadataResidentGauge.Update(int64(len(fs.filesMap)))
 }
 
 func (fs *FileSet) filepath(path storage.DxPath) storage.SysPath {
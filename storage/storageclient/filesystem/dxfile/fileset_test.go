@@ -123,6 +123,82 @@ func TestFileSet_NewDxFile(t *testing.T) {
 	}
 }
 
+// TestFileSet_CacheSize test the idle entries are kept in FileSet up to the cache size,
+// with the least recently used entry released first
+func TestFileSet_CacheSize(t *testing.T) {
+	first, fs := newTestFileSet(t)
+	fs.SetCacheSize(2)
+	ec, err := erasurecode.New(erasurecode.ECTypeStandard, 10, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ck, err := crypto.GenerateCipherKey(crypto.GCMCipherCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := fs.NewDxFile(randomDxPath(), "", false, ec, ck, 1<<24, 0777)
+	if err != nil {
+		t.Fatal(err)
+	}
+	third, err := fs.NewDxFile(randomDxPath(), "", false, ec, ck, 1<<24, 0777)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstPath, secondPath, thirdPath := first.metadata.DxPath, second.metadata.DxPath, third.metadata.DxPath
+
+	// the entries in use are never released
+	if len(fs.filesMap) != 3 {
+		t.Fatalf("filesMap size Expect: 3, Got: %d", len(fs.filesMap))
+	}
+	for _, entry := range []*FileSetEntryWithID{first, second, third} {
+		if err = entry.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(fs.filesMap) != 2 || fs.idleEntries.Len() != 2 {
+		t.Fatalf("After close, filesMap size Expect: 2, Got: %d", len(fs.filesMap))
+	}
+	if _, exists := fs.filesMap[firstPath]; exists {
+		t.Errorf("the least recently used entry is not released")
+	}
+
+	// opening the cached entry reuses it, and makes it the most recently used
+	cached := fs.filesMap[secondPath]
+	reopened, err := fs.Open(secondPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.fileSetEntry != cached || fs.idleEntries.Len() != 1 {
+		t.Errorf("the cached entry is not reused")
+	}
+	if err = reopened.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err = fs.Open(firstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = reopened.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := fs.filesMap[thirdPath]; exists {
+		t.Errorf("the least recently used entry is not released")
+	}
+
+	// the deleted entry is not cached
+	if err = fs.Delete(secondPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := fs.filesMap[secondPath]; exists || fs.idleEntries.Len() != 1 {
+		t.Errorf("the deleted entry is still cached")
+	}
+
+	fs.SetCacheSize(0)
+	if len(fs.filesMap) != 0 || fs.idleEntries.Len() != 0 {
+		t.Errorf("After disabling the cache, filesMap size Expect: 0, Got: %d", len(fs.filesMap))
+	}
+}
+
 // TestFileSet_CloseOpen test the FileSet Close and Open process.
 // First close the file, and then open the file. The process should not give error.
 func TestFileSet_CloseOpen(t *testing.T) {
@@ -86,8 +86,11 @@ func (df *DxFile) segmentHealth(segmentIndex int, table storage.HostHealthInfoTa
 }
 
 // goodSectors return the number of Sectors goodForRenew and numSectorsGoodForUpload with the
-// given offlineMap and goodForRenewMap
+// given offlineMap and goodForRenewMap. The hole is regarded as all sectors good
 func (df *DxFile) goodSectors(segmentIndex int, table storage.HostHealthInfoTable) (uint32, uint32) {
+	if segmentRangesContain(df.metadata.Holes, uint64(segmentIndex)) {
+		return df.metadata.NumSectors, df.metadata.NumSectors
+	}
 	numSectorsGoodForRenew := uint64(0)
 	numSectorsGoodForUpload := uint64(0)
 
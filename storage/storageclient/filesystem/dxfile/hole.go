@@ -0,0 +1,143 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dxfile
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxHoleRanges is the maximum number of hole ranges recorded in the metadata, which keeps
+// the metadata within a single page
+const MaxHoleRanges = 64
+
+// ErrTooManyHoles is the error for marking a hole not fitting in the metadata, in which case
+// the segment is uploaded as it is
+var ErrTooManyHoles = errors.New("too many holes in the file")
+
+// SegmentRange is the range of the segment indexes [Start, End)
+type SegmentRange struct {
+	Start uint64
+	End   uint64
+}
+
+// IsHole checks whether the segment is a hole, which is the segment of a sparse file full of
+// zeros. The hole has no sectors uploaded and is filled with zeros on download
+func (df *DxFile) IsHole(segmentIndex int) bool {
+	df.lock.RLock()
+	defer df.lock.RUnlock()
+	return segmentRangesContain(df.metadata.Holes, uint64(segmentIndex))
+}
+
+// NumHoles return the number of the hole segments of the DxFile
+func (df *DxFile) NumHoles() uint64 {
+	df.lock.RLock()
+	defer df.lock.RUnlock()
+	return numSegmentsInRanges(df.metadata.Holes)
+}
+
+// MarkHole marks the segment as a hole. The sectors of the segment are dropped, and the
+// segment is no longer stuck
+func (df *DxFile) MarkHole(segmentIndex int) error {
+	df.lock.Lock()
+	defer df.lock.Unlock()
+
+	if df.deleted {
+		return fmt.Errorf("file already deleted")
+	}
+	if segmentIndex < 0 || segmentIndex >= len(df.segments) {
+		return fmt.Errorf("segment Index %d out of bound %d", segmentIndex, len(df.segments))
+	}
+	index := uint64(segmentIndex)
+	if segmentRangesContain(df.metadata.Holes, index) {
+		return nil
+	}
+	holes := addSegmentRange(df.metadata.Holes, index)
+	if len(holes) > MaxHoleRanges {
+		return ErrTooManyHoles
+	}
+	df.metadata.Holes = holes
+
+	seg := df.segments[segmentIndex]
+	seg.Sectors = make([][]*Sector, df.metadata.NumSectors)
+	if seg.Stuck {
+		seg.Stuck = false
+		df.metadata.NumStuckSegments--
+	}
+	df.metadata.TimeUpdate = unixNow()
+	return df.saveSegments([]int{segmentIndex})
+}
+
+// unmarkHole removes the segment from the holes. The caller must hold the lock
+func (df *DxFile) unmarkHole(segmentIndex int) {
+	df.metadata.Holes = removeSegmentRange(df.metadata.Holes, uint64(segmentIndex))
+}
+
+// segmentRangesContain checks whether the index is within the sorted ranges
+func segmentRangesContain(ranges []SegmentRange, index uint64) bool {
+	for _, r := range ranges {
+		if index < r.Start {
+			return false
+		}
+		if index < r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// numSegmentsInRanges return the number of segment indexes within the ranges
+func numSegmentsInRanges(ranges []SegmentRange) uint64 {
+	var num uint64
+	for _, r := range ranges {
+		num += r.End - r.Start
+	}
+	return num
+}
+
+// addSegmentRange adds the index not within the sorted ranges, and merges the ranges adjacent
+// to the index
+func addSegmentRange(ranges []SegmentRange, index uint64) []SegmentRange {
+	i := 0
+	for i < len(ranges) && ranges[i].End < index {
+		i++
+	}
+	merged := make([]SegmentRange, 0, len(ranges)+1)
+	merged = append(merged, ranges[:i]...)
+	switch {
+	case i < len(ranges) && ranges[i].End == index:
+		r := SegmentRange{Start: ranges[i].Start, End: index + 1}
+		if i+1 < len(ranges) && ranges[i+1].Start == index+1 {
+			r.End = ranges[i+1].End
+			i++
+		}
+		merged = append(merged, r)
+		i++
+	case i < len(ranges) && ranges[i].Start == index+1:
+		merged = append(merged, SegmentRange{Start: index, End: ranges[i].End})
+		i++
+	default:
+		merged = append(merged, SegmentRange{Start: index, End: index + 1})
+	}
+	return append(merged, ranges[i:]...)
+}
+
+// removeSegmentRange removes the index from the sorted ranges, and splits the range containing it
+func removeSegmentRange(ranges []SegmentRange, index uint64) []SegmentRange {
+	var removed []SegmentRange
+	for _, r := range ranges {
+		if index < r.Start || index >= r.End {
+			removed = append(removed, r)
+			continue
+		}
+		if r.Start < index {
+			removed = append(removed, SegmentRange{Start: r.Start, End: index})
+		}
+		if index+1 < r.End {
+			removed = append(removed, SegmentRange{Start: index + 1, End: r.End})
+		}
+	}
+	return removed
+}
@@ -0,0 +1,116 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dxfile
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/erasurecode"
+)
+
+// TestSegmentRanges test addSegmentRange and removeSegmentRange
+func TestSegmentRanges(t *testing.T) {
+	var ranges []SegmentRange
+	for _, index := range []uint64{5, 1, 3, 2, 6, 9} {
+		ranges = addSegmentRange(ranges, index)
+	}
+	expect := []SegmentRange{{1, 4}, {5, 7}, {9, 10}}
+	if !reflect.DeepEqual(ranges, expect) {
+		t.Fatalf("expect ranges %v, got %v", expect, ranges)
+	}
+	ranges = addSegmentRange(ranges, 4)
+	expect = []SegmentRange{{1, 7}, {9, 10}}
+	if !reflect.DeepEqual(ranges, expect) {
+		t.Fatalf("expect ranges %v, got %v", expect, ranges)
+	}
+	if num := numSegmentsInRanges(ranges); num != 7 {
+		t.Errorf("expect 7 segments in ranges, got %v", num)
+	}
+	for index := uint64(0); index != 11; index++ {
+		expectContain := (index >= 1 && index < 7) || index == 9
+		if segmentRangesContain(ranges, index) != expectContain {
+			t.Errorf("segment %d: expect contained %v", index, expectContain)
+		}
+	}
+
+	ranges = removeSegmentRange(ranges, 3)
+	ranges = removeSegmentRange(ranges, 9)
+	ranges = removeSegmentRange(ranges, 1)
+	expect = []SegmentRange{{2, 3}, {4, 7}}
+	if !reflect.DeepEqual(ranges, expect) {
+		t.Errorf("expect ranges %v, got %v", expect, ranges)
+	}
+}
+
+// TestMarkHole test DxFile.MarkHole, and the health and persistence of the holes
+func TestMarkHole(t *testing.T) {
+	df, err := newTestDxFileWithSegments(t, sectorSize*64, 10, 30, erasurecode.ECTypeStandard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	df.segments[1].Stuck = true
+	df.metadata.NumStuckSegments = 1
+	if err = df.MarkHole(1); err != nil {
+		t.Fatal(err)
+	}
+	if !df.IsHole(1) || df.IsHole(0) || df.NumHoles() != 1 {
+		t.Fatalf("unexpected holes %v", df.metadata.Holes)
+	}
+	if df.segments[1].Stuck || df.metadata.NumStuckSegments != 0 {
+		t.Errorf("the hole shall not be stuck")
+	}
+	for _, sectors := range df.segments[1].Sectors {
+		if len(sectors) != 0 {
+			t.Errorf("the hole shall have no sectors")
+		}
+	}
+	if health := df.SegmentHealth(1, storage.HostHealthInfoTable{}); health != CompleteHealthThreshold {
+		t.Errorf("expect the hole health %v, got %v", CompleteHealthThreshold, health)
+	}
+	if err = df.MarkHole(len(df.segments)); err == nil {
+		t.Errorf("marking the segment out of bound shall fail")
+	}
+
+	path, err := storage.NewDxPath(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoveredDF, err := readDxFile(testDir.Join(path), df.wal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recoveredDF.metadata.Holes, df.metadata.Holes) {
+		t.Errorf("holes not persisted, expect %v, got %v", df.metadata.Holes, recoveredDF.metadata.Holes)
+	}
+	snap, err := recoveredDF.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snap.IsHole(1) || snap.IsHole(0) {
+		t.Errorf("unexpected holes of the snapshot")
+	}
+}
+
+// TestMarkHoleLimit test marking the holes more than MaxHoleRanges
+func TestMarkHoleLimit(t *testing.T) {
+	df, err := newTestDxFileWithSegments(t, sectorSize*10*(2*MaxHoleRanges+2), 10, 30, erasurecode.ECTypeStandard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < MaxHoleRanges; i++ {
+		if err = df.MarkHole(2 * i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = df.MarkHole(2 * MaxHoleRanges); err != ErrTooManyHoles {
+		t.Errorf("expect error %v, got %v", ErrTooManyHoles, err)
+	}
+	// the hole adjacent to the existing range is merged
+	if err = df.MarkHole(1); err != nil {
+		t.Errorf("the adjacent hole shall be merged: %v", err)
+	}
+}
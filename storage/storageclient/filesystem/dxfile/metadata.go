@@ -9,8 +9,10 @@ import (
 	"os"
 	"time"
 
+	"github.com/DxChainNetwork/godx/common"
 	"github.com/DxChainNetwork/godx/crypto"
 	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage"
 	"github.com/DxChainNetwork/godx/storage/storageclient/erasurecode"
 )
@@ -61,6 +63,29 @@ type (
 
 		// Version control for fork
 		Version string
+
+		// Priority is the user set repair priority, higher priority files are repaired first
+		Priority uint32
+
+		// PinnedHosts is the user set hosts the sectors are constrained to. Empty if the
+		// sectors could be stored on any host
+		PinnedHosts []enode.ID
+
+		// Holes is the sorted ranges of the segments full of zeros in the sparse file, which
+		// are not uploaded and filled with zeros on download
+		Holes []SegmentRange
+
+		// Checksum is the BLAKE2b-256 hash of the whole file at upload, which verifies the
+		// downloaded file. Empty if the hash is not computed
+		Checksum []byte
+
+		// UploadPriority is the user set upload priority class, the segments of the files
+		// in the higher class are uploaded before the others
+		UploadPriority UploadPriority
+
+		// Public marks the file uploaded unencrypted and published to the hosts, which serve
+		// the sectors to anyone without a storage contract
+		Public bool
 	}
 
 	// UpdateMetaData is the Metadata to be updated
@@ -151,6 +176,141 @@ func (df *DxFile) SetTimeAccess(t time.Time) error {
 	return df.saveMetadata()
 }
 
+// Priority return the user set repair priority of a DxFile
+func (df *DxFile) Priority() uint32 {
+	df.lock.RLock()
+	defer df.lock.RUnlock()
+	return df.metadata.Priority
+}
+
+// SetPriority set and save df.metadata.Priority
+func (df *DxFile) SetPriority(priority uint32) error {
+	df.lock.Lock()
+	defer df.lock.Unlock()
+	df.metadata.Priority = priority
+	return df.saveMetadata()
+}
+
+// UploadPriority return the user set upload priority class of a DxFile
+func (df *DxFile) UploadPriority() UploadPriority {
+	df.lock.RLock()
+	defer df.lock.RUnlock()
+	return df.metadata.UploadPriority
+}
+
+// SetUploadPriority set and save df.metadata.UploadPriority
+func (df *DxFile) SetUploadPriority(priority UploadPriority) error {
+	if !priority.IsValid() {
+		return fmt.Errorf("invalid upload priority %v", priority)
+	}
+	df.lock.Lock()
+	defer df.lock.Unlock()
+	df.metadata.UploadPriority = priority
+	return df.saveMetadata()
+}
+
+// Public return whether the DxFile is uploaded as the public content
+func (df *DxFile) Public() bool {
+	df.lock.RLock()
+	defer df.lock.RUnlock()
+	return df.metadata.Public
+}
+
+// SetPublic set and save df.metadata.Public. Only the file not encrypted could be public
+func (df *DxFile) SetPublic(public bool) error {
+	df.lock.Lock()
+	defer df.lock.Unlock()
+	if public && df.metadata.CipherKeyCode != crypto.PlainCipherCode {
+		return fmt.Errorf("the encrypted file could not be public")
+	}
+	df.metadata.Public = public
+	return df.saveMetadata()
+}
+
+// PinnedHosts return the hosts the sectors of a DxFile are constrained to
+func (df *DxFile) PinnedHosts() []enode.ID {
+	df.lock.RLock()
+	defer df.lock.RUnlock()
+	hosts := make([]enode.ID, len(df.metadata.PinnedHosts))
+	copy(hosts, df.metadata.PinnedHosts)
+	return hosts
+}
+
+// SetPinnedHosts set and save df.metadata.PinnedHosts. Empty hosts remove the constraint
+func (df *DxFile) SetPinnedHosts(hosts []enode.ID) error {
+	df.lock.Lock()
+	defer df.lock.Unlock()
+	var pinned []enode.ID
+	seen := make(map[enode.ID]struct{})
+	for _, host := range hosts {
+		if _, exist := seen[host]; exist {
+			continue
+		}
+		seen[host] = struct{}{}
+		pinned = append(pinned, host)
+	}
+	if len(pinned) > MaxPinnedHosts {
+		return fmt.Errorf("cannot pin the file to more than %v hosts", MaxPinnedHosts)
+	}
+	df.metadata.PinnedHosts = pinned
+	return df.saveMetadata()
+}
+
+// IsPinnedHost checks whether the sectors of a DxFile could be stored on the host
+func (df *DxFile) IsPinnedHost(host enode.ID) bool {
+	df.lock.RLock()
+	defer df.lock.RUnlock()
+	return df.isPinnedHost(host)
+}
+
+// isPinnedHost checks whether the host is allowed by df.metadata.PinnedHosts
+func (df *DxFile) isPinnedHost(host enode.ID) bool {
+	if len(df.metadata.PinnedHosts) == 0 {
+		return true
+	}
+	for _, pinned := range df.metadata.PinnedHosts {
+		if pinned == host {
+			return true
+		}
+	}
+	return false
+}
+
+// PinViolations return the number of sectors stored on the hosts the DxFile is not pinned to
+func (df *DxFile) PinViolations() uint32 {
+	df.lock.RLock()
+	defer df.lock.RUnlock()
+	if len(df.metadata.PinnedHosts) == 0 {
+		return 0
+	}
+	var violations uint32
+	for _, seg := range df.segments {
+		for _, sectors := range seg.Sectors {
+			for _, sector := range sectors {
+				if !df.isPinnedHost(sector.HostID) {
+					violations++
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// Checksum return the hash of the whole file computed at upload
+func (df *DxFile) Checksum() []byte {
+	df.lock.RLock()
+	defer df.lock.RUnlock()
+	return common.CopyBytes(df.metadata.Checksum)
+}
+
+// SetChecksum set and save df.metadata.Checksum
+func (df *DxFile) SetChecksum(checksum []byte) error {
+	df.lock.Lock()
+	defer df.lock.Unlock()
+	df.metadata.Checksum = common.CopyBytes(checksum)
+	return df.saveMetadata()
+}
+
 // TimeUpdate return the last update time of a DxFile
 func (df *DxFile) TimeUpdate() time.Time {
 	df.lock.RLock()
@@ -148,6 +148,12 @@ func (md Metadata) validate() error {
 	if md.MinSectors == 0 || md.NumSectors <= md.MinSectors {
 		return fmt.Errorf("MinSectors/NumSectors unexpected: %d / %d", md.MinSectors, md.NumSectors)
 	}
+	if !md.UploadPriority.IsValid() {
+		return fmt.Errorf("UploadPriority unexpected: %d", md.UploadPriority)
+	}
+	if md.Public && md.CipherKeyCode != crypto.PlainCipherCode {
+		return fmt.Errorf("public file unexpectedly encrypted with cipher code %d", md.CipherKeyCode)
+	}
 	return nil
 }
 
@@ -11,6 +11,7 @@ import (
 
 	"github.com/DxChainNetwork/godx/crypto"
 	"github.com/DxChainNetwork/godx/crypto/twofishgcm"
+	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/rlp"
 	"github.com/DxChainNetwork/godx/storage"
 	"github.com/DxChainNetwork/godx/storage/storageclient/erasurecode"
@@ -110,7 +111,13 @@ func TestMetadata_EncodeRLP_DecodeRLP(t *testing.T) {
 		MinSectors:          10,
 		NumSectors:          30,
 		ECExtra:             []byte{},
-		Version:             "1.0.0",
+		Version:             "1.0.6",
+		Priority:            3,
+		PinnedHosts:         []enode.ID{{1}, {2}},
+		Holes:               []SegmentRange{{Start: 1, End: 3}},
+		Checksum:            randomBytes(32),
+		UploadPriority:      UploadPriorityHigh,
+		Public:              true,
 	}
 	b, err := rlp.EncodeToBytes(meta)
 	if err != nil {
@@ -125,3 +132,58 @@ func TestMetadata_EncodeRLP_DecodeRLP(t *testing.T) {
 		t.Errorf("not Equal\n\texpect %+v\n\tgot %+v", meta, md)
 	}
 }
+
+// TestDecodeLegacyMetadata test decoding the metadata persisted before the trailing fields were added
+func TestDecodeLegacyMetadata(t *testing.T) {
+	tests := []struct {
+		version       string
+		missingFields int
+	}{
+		{"1.0.0", 6},
+		{"1.0.1", 5},
+		{"1.0.2", 4},
+		{"1.0.3", 3},
+		{"1.0.4", 2},
+		{"1.0.5", 1},
+	}
+	for _, test := range tests {
+		meta := Metadata{
+			HostTableOffset: PageSize,
+			SegmentOffset:   2 * PageSize,
+			FileSize:        randomUint64(),
+			CipherKeyCode:   crypto.GCMCipherCode,
+			CipherKey:       randomBytes(twofishgcm.GCMCipherKeyLength),
+			MinSectors:      10,
+			NumSectors:      30,
+			ECExtra:         []byte{},
+			Version:         test.version,
+			PinnedHosts:     []enode.ID{},
+			Holes:           []SegmentRange{},
+			Checksum:        []byte{},
+		}
+		b, err := rlp.EncodeToBytes(meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// remove the trailing fields to mock the legacy metadata
+		var fields []rlp.RawValue
+		if err = rlp.DecodeBytes(b, &fields); err != nil {
+			t.Fatal(err)
+		}
+		legacy, err := rlp.EncodeToBytes(fields[:len(fields)-test.missingFields])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var md *Metadata
+		if err = rlp.DecodeBytes(legacy, &md); err == nil {
+			t.Fatalf("legacy metadata of version %v should not be decoded directly", test.version)
+		}
+		if err = decodeLegacyMetadata(legacy, &md); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(meta, *md) {
+			t.Errorf("version %v not Equal\n\texpect %+v\n\tgot %+v", test.version, meta, *md)
+		}
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 
 	"github.com/DxChainNetwork/godx/common/writeaheadlog"
+	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/rlp"
 	"github.com/DxChainNetwork/godx/storage"
 )
@@ -17,6 +19,26 @@ import (
 // readDxFile create a new DxFile with a random ID, then open and read the dxfile from filepath
 // and load all params from the file.
 func readDxFile(filepath storage.SysPath, wal *writeaheadlog.Wal) (*DxFile, error) {
+	df, err := decodeDxFile(filepath, wal)
+	if err != nil {
+		return nil, err
+	}
+	// Apply the stuck flags not persisted in the previous run
+	if err = df.replayStuckJournal(); err != nil {
+		return nil, fmt.Errorf("cannot replay the stuck journal: %v", err)
+	}
+	return df, nil
+}
+
+// Check checks whether the DxFile persisted at the path could be loaded. The file is only
+// read, and the stuck journal is not applied
+func Check(filepath storage.SysPath) error {
+	_, err := decodeDxFile(filepath, nil)
+	return err
+}
+
+// decodeDxFile open and decode the dxfile from filepath without modifying the file
+func decodeDxFile(filepath storage.SysPath, wal *writeaheadlog.Wal) (*DxFile, error) {
 	df := &DxFile{
 		filePath: filepath,
 		wal:      wal,
@@ -52,10 +74,16 @@ func readDxFile(filepath storage.SysPath, wal *writeaheadlog.Wal) (*DxFile, erro
 
 // readMetadata load metadata from the file
 func (df *DxFile) loadMetadata(f io.Reader) error {
-	err := rlp.Decode(f, &df.metadata)
+	raw, err := rlp.NewStream(f, 0).Raw()
 	if err != nil {
 		return err
 	}
+	if err = rlp.DecodeBytes(raw, &df.metadata); err != nil {
+		// metadata of the legacy versions does not have the trailing fields
+		if err = decodeLegacyMetadata(raw, &df.metadata); err != nil {
+			return err
+		}
+	}
 	// sanity check
 	if err = df.metadata.validate(); err != nil {
 		return err
@@ -64,6 +92,36 @@ func (df *DxFile) loadMetadata(f io.Reader) error {
 	return nil
 }
 
+// legacyMetadataFields is the zero value of the trailing metadata fields added after
+// version 1.0.0, in the order of the fields: Priority, PinnedHosts, Holes, Checksum,
+// UploadPriority and Public
+var legacyMetadataFields = []interface{}{uint32(0), []enode.ID{}, []SegmentRange{}, []byte{}, UploadPriorityNormal, false}
+
+// decodeLegacyMetadata decodes the metadata persisted before the trailing fields were
+// added, by appending the zero value of the missing fields to the rlp list
+func decodeLegacyMetadata(raw []byte, md **Metadata) error {
+	var fields []rlp.RawValue
+	if err := rlp.DecodeBytes(raw, &fields); err != nil {
+		return err
+	}
+	missing := reflect.TypeOf(Metadata{}).NumField() - len(fields)
+	if missing <= 0 || missing > len(legacyMetadataFields) {
+		return fmt.Errorf("unknown metadata format with %v fields", len(fields))
+	}
+	for _, field := range legacyMetadataFields[len(legacyMetadataFields)-missing:] {
+		zero, err := rlp.EncodeToBytes(field)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, zero)
+	}
+	upgraded, err := rlp.EncodeToBytes(fields)
+	if err != nil {
+		return err
+	}
+	return rlp.DecodeBytes(upgraded, md)
+}
+
 // loadHostAddresses load DxFile.hostTable from the file f
 func (df *DxFile) loadHostAddresses(f io.ReadSeeker) error {
 	if df.metadata == nil {
@@ -0,0 +1,73 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dxfile
+
+import (
+	"fmt"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+)
+
+// ReEncode changes the number of total sectors of the DxFile. The erasure code type and the
+// min sectors cannot be changed, since they decide how the data is split into the sectors,
+// and changing them requires downloading and uploading the whole file again. The parity
+// sectors of the Reed-Solomon code depend on the min sectors only, thus the sectors uploaded
+// with the same index are still valid after the change. If the redundancy is raised, the
+// sector slots added to the segments are empty to be uploaded by the repair. If the redundancy
+// is lowered, the sectors beyond the number of total sectors are dropped and returned, and the
+// hosts no longer holding any sector of the file are marked as unused in the host table
+func (df *DxFile) ReEncode(numSectors uint32) (dropped []*Sector, err error) {
+	df.lock.Lock()
+	defer df.lock.Unlock()
+
+	if df.deleted {
+		return nil, fmt.Errorf("file already deleted")
+	}
+	if numSectors == df.metadata.NumSectors {
+		return nil, nil
+	}
+	md := *df.metadata
+	md.NumSectors = numSectors
+	ec, err := md.newErasureCode()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, seg := range df.segments {
+		if numSectors > df.metadata.NumSectors {
+			seg.Sectors = append(seg.Sectors, make([][]*Sector, numSectors-df.metadata.NumSectors)...)
+			continue
+		}
+		for _, sectors := range seg.Sectors[numSectors:] {
+			dropped = append(dropped, sectors...)
+		}
+		seg.Sectors = seg.Sectors[:numSectors]
+	}
+
+	// release the hosts of the dropped sectors which hold no other sector of the file
+	if len(dropped) != 0 {
+		holding := make(map[enode.ID]struct{})
+		for _, seg := range df.segments {
+			for _, sectors := range seg.Sectors {
+				for _, sector := range sectors {
+					holding[sector.HostID] = struct{}{}
+				}
+			}
+		}
+		for _, sector := range dropped {
+			if _, exists := holding[sector.HostID]; !exists {
+				df.hostTable[sector.HostID] = false
+			}
+		}
+	}
+
+	df.erasureCode = ec
+	df.metadata.NumSectors = numSectors
+	df.metadata.TimeModify = unixNow()
+	if err = df.saveAll(); err != nil {
+		return nil, err
+	}
+	return dropped, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dxfile
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/erasurecode"
+)
+
+// TestReEncode test DxFile.ReEncode raising and lowering the redundancy
+func TestReEncode(t *testing.T) {
+	tests := []uint32{40, 20, 30}
+	for i, numSectors := range tests {
+		df, err := newTestDxFileWithSegments(t, sectorSize*64, 10, 30, erasurecode.ECTypeStandard)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := make([]Segment, len(df.segments))
+		for j := range before {
+			before[j] = copySegment(df.segments[j])
+		}
+
+		dropped, err := df.ReEncode(numSectors)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		path, err := storage.NewDxPath(t.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		recoveredDF, err := readDxFile(testDir.Join(path), df.wal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = checkDxFileEqual(df, recoveredDF); err != nil {
+			t.Errorf("test %d: %v", i, err)
+		}
+		if recoveredDF.metadata.NumSectors != numSectors {
+			t.Errorf("test %d: expect num sectors %d, got %d", i, numSectors, recoveredDF.metadata.NumSectors)
+		}
+		var expectDropped int
+		for j, seg := range before {
+			sectors := copySectors(recoveredDF.segments[j])
+			if uint32(len(sectors)) != numSectors {
+				t.Fatalf("test %d: segment %d has %d sector slots, expect %d", i, j, len(sectors), numSectors)
+			}
+			kept := seg.Sectors
+			if numSectors < uint32(len(kept)) {
+				kept = kept[:numSectors]
+			}
+			for _, slot := range seg.Sectors[len(kept):] {
+				expectDropped += len(slot)
+			}
+			if !reflect.DeepEqual(sectors[:len(kept)], kept) {
+				t.Errorf("test %d: the sectors of segment %d are changed", i, j)
+			}
+			for _, added := range sectors[len(kept):] {
+				if len(added) != 0 {
+					t.Errorf("test %d: the sector slot added to segment %d is not empty", i, j)
+				}
+			}
+		}
+		if len(dropped) != expectDropped {
+			t.Errorf("test %d: expect %d sectors dropped, got %d", i, expectDropped, len(dropped))
+		}
+	}
+}
+
+// TestReEncodeBelowMinSectors test DxFile.ReEncode with the number of total sectors smaller
+// than the min sectors
+func TestReEncodeBelowMinSectors(t *testing.T) {
+	df, err := newTestDxFileWithSegments(t, sectorSize*64, 10, 30, erasurecode.ECTypeStandard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := df.ReEncode(9); err == nil {
+		t.Error("expect error re-encoding the file with less sectors than the min sectors")
+	}
+	if df.metadata.NumSectors != 30 {
+		t.Errorf("expect num sectors unchanged, got %d", df.metadata.NumSectors)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/DxChainNetwork/godx/common"
 	"github.com/DxChainNetwork/godx/crypto"
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage"
@@ -24,8 +25,11 @@ type Snapshot struct {
 	cipherKey   crypto.CipherKey
 	fileMode    os.FileMode
 	segments    []Segment
+	holes       []SegmentRange
+	checksum    []byte
 	hostTable   map[enode.ID]bool
 	dxPath      storage.DxPath
+	public      bool
 }
 
 // SnapshotReader is the structure that allow reading the raw DxFile content
@@ -100,8 +104,11 @@ func (df *DxFile) Snapshot() (*Snapshot, error) {
 		cipherKey:   ck,
 		fileMode:    df.metadata.FileMode,
 		segments:    segments,
+		holes:       append([]SegmentRange{}, df.metadata.Holes...),
+		checksum:    common.CopyBytes(df.metadata.Checksum),
 		hostTable:   hostTable,
 		dxPath:      df.metadata.DxPath,
+		public:      df.metadata.Public,
 	}, nil
 }
 
@@ -142,6 +149,16 @@ func (s *Snapshot) Sectors(segmentIndex uint64) ([][]*Sector, error) {
 	return copySectors(&s.segments[segmentIndex]), nil
 }
 
+// IsHole checks whether the segment of the segment index is a hole
+func (s *Snapshot) IsHole(segmentIndex uint64) bool {
+	return segmentRangesContain(s.holes, segmentIndex)
+}
+
+// Checksum return the hash of the whole file computed at upload
+func (s *Snapshot) Checksum() []byte {
+	return s.checksum
+}
+
 // SectorSize return the sectorSize
 func (s *Snapshot) SectorSize() uint64 {
 	return s.sectorSize
@@ -152,6 +169,11 @@ func (s *Snapshot) DxPath() storage.DxPath {
 	return s.dxPath
 }
 
+// Public return whether the file is uploaded as the public content
+func (s *Snapshot) Public() bool {
+	return s.public
+}
+
 // FileSize return the file size
 func (s *Snapshot) FileSize() uint64 {
 	return uint64(s.fileSize)
@@ -0,0 +1,172 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dxfile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	// stuckJournalExt is the extension of the stuck journal stored alongside the DxFile
+	stuckJournalExt = ".stuck"
+
+	// stuckRecordSize is the size of a stuck journal record, which is the segment index
+	// followed by the stuck flag
+	stuckRecordSize = 9
+)
+
+var (
+	// StuckFlushDelay is the maximum time a stuck flag change stays in the stuck journal
+	// before it is persisted in the DxFile
+	StuckFlushDelay = 30 * time.Second
+
+	// StuckFlushBatchSize is the number of journaled stuck flag changes that triggers the
+	// stuck flags to be persisted in the DxFile immediately
+	StuckFlushBatchSize = 64
+)
+
+// SetStuckByIndex set a Segment of Index to the value of Stuck. The change is appended to
+// the stuck journal, and persisted in the DxFile along with the other changes in a batch,
+// which is triggered by StuckFlushBatchSize or StuckFlushDelay, whichever comes first
+func (df *DxFile) SetStuckByIndex(index int, stuck bool) (err error) {
+	df.lock.Lock()
+	defer df.lock.Unlock()
+
+	if df.deleted {
+		return fmt.Errorf("file %v is deleted", df.metadata.DxPath)
+	}
+
+	if stuck == df.segments[index].Stuck {
+		return nil
+	}
+	if err = df.journalStuck(index, stuck); err != nil {
+		return fmt.Errorf("cannot journal the stuck flag: %v", err)
+	}
+	df.setStuck(index, stuck)
+
+	if len(df.dirtyStuck) >= StuckFlushBatchSize {
+		return df.flushStuck()
+	}
+	if df.stuckFlushTimer == nil {
+		df.stuckFlushTimer = time.AfterFunc(StuckFlushDelay, func() {
+			df.FlushStuck()
+		})
+	}
+	return nil
+}
+
+// FlushStuck persists the journaled stuck flag changes in the DxFile. It must be called
+// before the DxFile is released, so that the pending flush will not overwrite the DxFile
+// opened later
+func (df *DxFile) FlushStuck() error {
+	df.lock.Lock()
+	defer df.lock.Unlock()
+	return df.flushStuck()
+}
+
+// flushStuck persists the journaled stuck flag changes in the DxFile. If error happens,
+// the changes stay in the stuck journal, and will be replayed once the DxFile is read
+func (df *DxFile) flushStuck() error {
+	if df.stuckFlushTimer != nil {
+		df.stuckFlushTimer.Stop()
+		df.stuckFlushTimer = nil
+	}
+	if len(df.dirtyStuck) == 0 || df.deleted {
+		return nil
+	}
+	return df.saveSegments(nil)
+}
+
+// setStuck sets the stuck flag of the segment in memory, and marks the segment as dirty
+func (df *DxFile) setStuck(index int, stuck bool) {
+	df.segments[index].Stuck = stuck
+	if stuck {
+		df.metadata.NumStuckSegments++
+	} else {
+		df.metadata.NumStuckSegments--
+	}
+	if df.dirtyStuck == nil {
+		df.dirtyStuck = make(map[int]struct{})
+	}
+	df.dirtyStuck[index] = struct{}{}
+}
+
+// dirtyStuckIndexes returns the sorted indexes of the segments with the stuck flag not persisted
+func (df *DxFile) dirtyStuckIndexes() []int {
+	indexes := make([]int, 0, len(df.dirtyStuck))
+	for index := range df.dirtyStuck {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// journalStuck appends the stuck flag change to the stuck journal. The record is written
+// without fsync, and a record lost in the crash only reverts the stuck flag, which will be
+// corrected by the next health check
+func (df *DxFile) journalStuck(index int, stuck bool) error {
+	f, err := os.OpenFile(df.stuckJournalPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	record := make([]byte, stuckRecordSize)
+	binary.LittleEndian.PutUint64(record, uint64(index))
+	if stuck {
+		record[stuckRecordSize-1] = 1
+	}
+	if _, err = f.Write(record); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// replayStuckJournal applies the stuck flag changes in the stuck journal left by the
+// previous run, and persists them in the DxFile. The incomplete trailing record is ignored
+func (df *DxFile) replayStuckJournal() error {
+	journal, err := ioutil.ReadFile(df.stuckJournalPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for len(journal) >= stuckRecordSize {
+		index := binary.LittleEndian.Uint64(journal)
+		stuck := journal[stuckRecordSize-1] == 1
+		journal = journal[stuckRecordSize:]
+		if index >= uint64(len(df.segments)) || df.segments[index] == nil || df.segments[index].Stuck == stuck {
+			continue
+		}
+		df.setStuck(int(index), stuck)
+	}
+	if len(df.dirtyStuck) == 0 {
+		return df.clearStuckJournal()
+	}
+	return df.saveSegments(nil)
+}
+
+// clearStuckJournal removes the stuck journal once all stuck flags are persisted
+func (df *DxFile) clearStuckJournal() error {
+	if df.stuckFlushTimer != nil {
+		df.stuckFlushTimer.Stop()
+		df.stuckFlushTimer = nil
+	}
+	if err := os.Remove(df.stuckJournalPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	df.dirtyStuck = nil
+	return nil
+}
+
+// stuckJournalPath returns the path of the stuck journal of the DxFile
+func (df *DxFile) stuckJournalPath() string {
+	return string(df.filePath) + stuckJournalExt
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dxfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UploadPriority is the user set priority class of the file upload. The segments of the
+// files in the higher class are uploaded and repaired before the segments in the lower
+// class, regardless of the repair priority and the health
+type UploadPriority uint8
+
+// The upload priority classes. The normal class is the zero value, so that the files
+// persisted before the priority classes were introduced are uploaded as normal
+const (
+	UploadPriorityNormal UploadPriority = iota
+	UploadPriorityHigh
+	UploadPriorityBackground
+)
+
+// uploadPriorityNames is the mapping from the upload priority class to its name
+var uploadPriorityNames = map[UploadPriority]string{
+	UploadPriorityHigh:       "high",
+	UploadPriorityNormal:     "normal",
+	UploadPriorityBackground: "background",
+}
+
+// uploadPriorityRanks is the mapping from the upload priority class to its rank, the
+// higher the rank, the earlier the upload
+var uploadPriorityRanks = map[UploadPriority]int{
+	UploadPriorityHigh:       2,
+	UploadPriorityNormal:     1,
+	UploadPriorityBackground: 0,
+}
+
+// ParseUploadPriority parses the upload priority class from its name, case insensitive
+func ParseUploadPriority(s string) (UploadPriority, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for priority, n := range uploadPriorityNames {
+		if n == name {
+			return priority, nil
+		}
+	}
+	return UploadPriorityNormal, fmt.Errorf("unknown upload priority %q, expect high, normal or background", s)
+}
+
+// String returns the name of the upload priority class
+func (p UploadPriority) String() string {
+	if name, exist := uploadPriorityNames[p]; exist {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(p))
+}
+
+// IsValid checks whether the upload priority is a known class
+func (p UploadPriority) IsValid() bool {
+	_, exist := uploadPriorityNames[p]
+	return exist
+}
+
+// Before checks whether the upload of the class p shall be scheduled before the class q
+func (p UploadPriority) Before(q UploadPriority) bool {
+	return uploadPriorityRanks[p] > uploadPriorityRanks[q]
+}
@@ -53,8 +53,11 @@ func (df *DxFile) saveAll() error {
 	}
 	updates = append(updates, up)
 
-	// save all updates
-	return storage.ApplyUpdates(df.wal, updates)
+	// save all updates. All segments are persisted, thus the stuck journal is obsolete
+	if err = storage.ApplyMirroredUpdates(df.wal, updates); err != nil {
+		return err
+	}
+	return df.clearStuckJournal()
 }
 
 // rename create a series of transactions to rename the file to a new file
@@ -63,6 +66,8 @@ func (df *DxFile) rename(dxPath storage.DxPath, newFilePath storage.SysPath) err
 		return errors.New("cannot rename the file: file already deleted")
 	}
 	var updates []storage.FileUpdate
+	// the stuck flags are all persisted in the renamed file
+	prevJournal := df.stuckJournalPath()
 	// create updates for delete
 	du, err := df.createDeleteUpdate()
 	if err != nil {
@@ -102,7 +107,13 @@ func (df *DxFile) rename(dxPath storage.DxPath, newFilePath storage.SysPath) err
 	}
 	updates = append(updates, up)
 	// apply updates
-	return storage.ApplyUpdates(df.wal, updates)
+	if err = storage.ApplyMirroredUpdates(df.wal, updates); err != nil {
+		return err
+	}
+	if err = os.Remove(prevJournal); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return df.clearStuckJournal()
 }
 
 // delete create and apply the deletion update
@@ -114,14 +125,20 @@ func (df *DxFile) delete() error {
 	if err != nil {
 		return fmt.Errorf("cannot create delete update: %v", err)
 	}
-	return storage.ApplyUpdates(df.wal, []storage.FileUpdate{du})
+	return storage.ApplyMirroredUpdates(df.wal, []storage.FileUpdate{du})
 }
 
-// saveSegment save the Segment with the segmentIndex, and write to file
+// saveSegment save the Segment with the segmentIndex, and write to file. The segments with the
+// journaled stuck flag are saved along with them, and the stuck journal is cleared
 func (df *DxFile) saveSegments(indexes []int) error {
 	if df.deleted {
 		return errors.New("cannot save the Segment: file already deleted")
 	}
+	for _, index := range df.dirtyStuckIndexes() {
+		if !containsIndex(indexes, index) {
+			indexes = append(indexes, index)
+		}
+	}
 	// create updates for hostTable
 	updates, err := df.createMetadataHostTableUpdate()
 	if err != nil {
@@ -147,7 +164,23 @@ func (df *DxFile) saveSegments(indexes []int) error {
 	}
 	updates = append(updates, up)
 	// apply the updates
-	return storage.ApplyUpdates(df.wal, updates)
+	if err = storage.ApplyMirroredUpdates(df.wal, updates); err != nil {
+		return err
+	}
+	if df.dirtyStuck == nil {
+		return nil
+	}
+	return df.clearStuckJournal()
+}
+
+// containsIndex checks whether the index is in the indexes
+func containsIndex(indexes []int, index int) bool {
+	for _, i := range indexes {
+		if i == index {
+			return true
+		}
+	}
+	return false
 }
 
 // saveHostTableUpdate save the host table as well as the metadata
@@ -159,7 +192,7 @@ func (df *DxFile) saveHostTableUpdate() error {
 	if err != nil {
 		return err
 	}
-	return storage.ApplyUpdates(df.wal, updates)
+	return storage.ApplyMirroredUpdates(df.wal, updates)
 }
 
 // saveMetadata only save the metadata
@@ -171,7 +204,7 @@ func (df *DxFile) saveMetadata() error {
 	if err != nil {
 		return err
 	}
-	return storage.ApplyUpdates(df.wal, []storage.FileUpdate{up})
+	return storage.ApplyMirroredUpdates(df.wal, []storage.FileUpdate{up})
 }
 
 // createMetadataHostTableUpdate creates the update for metadata and hostTable
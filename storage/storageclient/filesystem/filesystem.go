@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/common/filelock"
 	"github.com/DxChainNetwork/godx/common/threadmanager"
 	"github.com/DxChainNetwork/godx/common/writeaheadlog"
 	"github.com/DxChainNetwork/godx/crypto"
@@ -31,6 +32,13 @@ import (
 // ErrNoRepairNeeded is the error that no repair is needed
 var ErrNoRepairNeeded = errors.New("no repair needed")
 
+// ErrReadOnly is the error returned when modifying the files of the read-only file system
+var ErrReadOnly = errors.New("the file system is read-only")
+
+// ErrTooManyFiles is the error returned when creating a file while the number of files
+// reaches the limit of the file system
+var ErrTooManyFiles = errors.New("the number of files reaches the limit of the file system")
+
 // fileSystem is the structure for a file system that include a fileSet and a dirSet
 type fileSystem struct {
 	// fileRootDir is the root directory where the files locates
@@ -55,6 +63,9 @@ type fileSystem struct {
 	// updateWal is the wal responsible for
 	updateWal *writeaheadlog.Wal
 
+	// fileLock is the lock on the persist directory held while the file system is running
+	fileLock *filelock.Lock
+
 	// tm is the thread manager for manage the threads in fileSystem
 	tm *threadmanager.ThreadManager
 
@@ -77,6 +88,12 @@ type fileSystem struct {
 
 	// stuckFound is the channel to signal a stuck segment is found
 	stuckFound chan struct{}
+
+	// readOnly is set to 1 when the files cannot be created, renamed, or deleted
+	readOnly uint32
+
+	// maxFiles is the maximum number of files in the file system, 0 means unlimited
+	maxFiles uint64
 }
 
 // newFileSystem creates a new file system with the standardDisrupter
@@ -97,13 +114,28 @@ func newFileSystem(persistDir string, contractor contractManager, disrupter disr
 
 // Start is the function that is called for starting the file system service.
 // It open the wals, apply all transactions, and start the thread loopRepairUnfinishedDirMetadataUpdate
-func (fs *fileSystem) Start() error {
+func (fs *fileSystem) Start() (err error) {
+	// lock the persist directory, so that the files are not opened by another process
+	if err = fs.acquireLock(); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			fs.fileLock.Release()
+		}
+	}()
 	// open the fileWal
 	if err := fs.loadFileWal(); err != nil {
 		return fmt.Errorf("cannot start the file system: %v", err)
 	}
+	// repair the corrupted metadata from the mirrors before any file is opened
+	if report, err := fs.repairMirrors(); err != nil {
+		fs.logger.Warn("cannot repair the file system metadata from the mirrors", "err", err)
+	} else if report.Restored != 0 || report.Mirrored != 0 || len(report.Unrecoverable) != 0 {
+		fs.logger.Info("repaired the file system metadata", "checked", report.Checked, "restored", report.Restored,
+			"mirrored", report.Mirrored, "unrecoverable", len(report.Unrecoverable))
+	}
 	// load fs.dirSet
-	var err error
 	if fs.dirSet, err = dxdir.NewDirSet(fs.fileRootDir, fs.fileWal); err != nil {
 		return fmt.Errorf("cannot start the file system dirSet: %v", err)
 	}
@@ -131,7 +163,8 @@ func (fs *fileSystem) Close() error {
 		fullErr = common.ErrCompose(fullErr, err)
 	}
 	fs.lock.Unlock()
-	return common.ErrCompose(fullErr, fs.tm.Stop())
+	fullErr = common.ErrCompose(fullErr, fs.tm.Stop())
+	return common.ErrCompose(fullErr, fs.fileLock.Release())
 }
 
 // RootDir returns the root directory for the files
@@ -144,11 +177,53 @@ func (fs *fileSystem) PersistDir() storage.SysPath {
 	return fs.persistDir
 }
 
+// SetReadOnly sets whether the file system is read-only. The files in the read-only
+// file system can only be opened, but not created, renamed, or deleted
+func (fs *fileSystem) SetReadOnly(readOnly bool) {
+	var val uint32
+	if readOnly {
+		val = 1
+	}
+	atomic.StoreUint32(&fs.readOnly, val)
+}
+
+// SetFileLimits sets the maximum number of files in the file system, and the maximum number
+// of dxfile metadata kept in memory. Zero maxFiles means unlimited, and zero maxCachedFiles
+// loads the dxfile metadata on demand only. It must be called after Start
+func (fs *fileSystem) SetFileLimits(maxFiles uint64, maxCachedFiles int) {
+	atomic.StoreUint64(&fs.maxFiles, maxFiles)
+	fs.fileSet.SetCacheSize(maxCachedFiles)
+}
+
 // NewDxFile creates a new dxfile in the file system
 func (fs *fileSystem) NewDxFile(dxPath storage.DxPath, sourcePath storage.SysPath, force bool, erasureCode erasurecode.ErasureCoder, cipherKey crypto.CipherKey, fileSize uint64, fileMode os.FileMode) (*dxfile.FileSetEntryWithID, error) {
+	if atomic.LoadUint32(&fs.readOnly) == 1 {
+		return nil, ErrReadOnly
+	}
+	if maxFiles := atomic.LoadUint64(&fs.maxFiles); maxFiles != 0 && !(force && fs.fileSet.Exists(dxPath)) {
+		numFiles, err := fs.numFiles()
+		if err != nil {
+			return nil, err
+		}
+		if numFiles >= maxFiles {
+			return nil, ErrTooManyFiles
+		}
+	}
 	return fs.fileSet.NewDxFile(dxPath, sourcePath, force, erasureCode, cipherKey, fileSize, fileMode)
 }
 
+// numFiles returns the number of files in the file system aggregated in the root directory.
+// Since the directory metadata is updated asynchronously, the files just created may not be
+// counted yet
+func (fs *fileSystem) numFiles() (uint64, error) {
+	root, err := fs.dirSet.Open(storage.RootDxPath())
+	if err != nil {
+		return 0, err
+	}
+	defer root.Close()
+	return root.Metadata().NumFiles, nil
+}
+
 // OpenDxFile opens the DxFile specified by the path
 func (fs *fileSystem) OpenDxFile(path storage.DxPath) (*dxfile.FileSetEntryWithID, error) {
 	return fs.fileSet.Open(path)
@@ -156,11 +231,17 @@ func (fs *fileSystem) OpenDxFile(path storage.DxPath) (*dxfile.FileSetEntryWithI
 
 // Delete delete the dxfile from the file system
 func (fs *fileSystem) DeleteDxFile(dxPath storage.DxPath) error {
+	if atomic.LoadUint32(&fs.readOnly) == 1 {
+		return ErrReadOnly
+	}
 	return fs.fileSet.Delete(dxPath)
 }
 
 // RenameDxFile rename the dxfile from prevPath to newPath
 func (fs *fileSystem) RenameDxFile(prevPath, newPath storage.DxPath) error {
+	if atomic.LoadUint32(&fs.readOnly) == 1 {
+		return ErrReadOnly
+	}
 	return fs.fileSet.Rename(prevPath, newPath)
 }
 
@@ -400,6 +481,25 @@ func (fs *fileSystem) dirsAndFiles(path storage.DxPath) (map[storage.DxPath]stru
 	return dirs, files, nil
 }
 
+// acquireLock acquires the lock on the persist directory of the file system
+func (fs *fileSystem) acquireLock() error {
+	if err := os.MkdirAll(string(fs.persistDir), 0700); err != nil {
+		return fmt.Errorf("cannot create the file system directory: %v", err)
+	}
+	fileLock, err := filelock.Acquire(filepath.Join(string(fs.persistDir), lockName))
+	if err != nil {
+		return err
+	}
+	fs.fileLock = fileLock
+	return nil
+}
+
+// TakeoverLock removes the lock of the file system left by another process, which must
+// not be running any more
+func TakeoverLock(persistDir string) error {
+	return filelock.Takeover(filepath.Join(persistDir, lockName))
+}
+
 // loadFileWal read the fileWal
 func (fs *fileSystem) loadFileWal() error {
 	fileWalPath := filepath.Join(string(fs.persistDir), fileWalName)
@@ -571,6 +671,12 @@ func (fs *fileSystem) fileDetailedInfo(path storage.DxPath, table storage.HostHe
 		Redundancy:     redundancy,
 		StoredOnDisk:   onDisk,
 		UploadProgress: file.UploadProgress(),
+		PinViolations:  file.PinViolations(),
+		UploadPriority: file.UploadPriority().String(),
+		Public:         file.Public(),
+	}
+	for _, host := range file.PinnedHosts() {
+		info.PinnedHosts = append(info.PinnedHosts, host.String())
 	}
 	return info, nil
 }
@@ -143,6 +143,46 @@ func TestFileSystem_RandomStuckDirectory(t *testing.T) {
 	}
 }
 
+// TestFileSystem_SetReadOnly test the files in the read-only file system can be opened, but
+// not created, renamed, or deleted
+func TestFileSystem_SetReadOnly(t *testing.T) {
+	fs := newEmptyTestFileSystem(t, "", &AlwaysSuccessContractManager{}, newStandardDisrupter())
+	defer fs.Close()
+
+	ck, err := crypto.GenerateCipherKey(crypto.GCMCipherCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := randomDxPath(t, 2)
+	entry, err := fs.fileSet.NewRandomDxFile(path, 10, 30, erasurecode.ECTypeStandard, ck, 1<<22, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = entry.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fs.SetReadOnly(true)
+	if _, err = fs.NewDxFile(randomDxPath(t, 2), "", false, nil, ck, 1<<22, 0777); err != ErrReadOnly {
+		t.Errorf("creating file in read-only file system should return %v, got %v", ErrReadOnly, err)
+	}
+	if err = fs.RenameDxFile(path, randomDxPath(t, 2)); err != ErrReadOnly {
+		t.Errorf("renaming file in read-only file system should return %v, got %v", ErrReadOnly, err)
+	}
+	if err = fs.DeleteDxFile(path); err != ErrReadOnly {
+		t.Errorf("deleting file in read-only file system should return %v, got %v", ErrReadOnly, err)
+	}
+	if entry, err = fs.OpenDxFile(path); err != nil {
+		t.Fatalf("opening file in read-only file system: %v", err)
+	}
+	entry.Close()
+
+	fs.SetReadOnly(false)
+	if err = fs.DeleteDxFile(path); err != nil {
+		t.Errorf("failed to delete file after read-only disabled: %v", err)
+	}
+}
+
 // randomDxPath create a random DxPath for testing with a certain depth
 func randomDxPath(t *testing.T, depth int) storage.DxPath {
 	var s string
@@ -28,6 +28,8 @@ type FileSystem interface {
 	// Properties
 	RootDir() storage.SysPath
 	PersistDir() storage.SysPath
+	SetReadOnly(readOnly bool)
+	SetFileLimits(maxFiles uint64, maxCachedFiles int)
 
 	// DxFile related methods, including New, Open, Rename and Delete
 	NewDxFile(dxPath storage.DxPath, sourcePath storage.SysPath, force bool, erasureCode erasurecode.ErasureCoder, cipherKey crypto.CipherKey, fileSize uint64, fileMode os.FileMode) (*dxfile.FileSetEntryWithID, error)
@@ -0,0 +1,213 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package filesystem
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxdir"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
+)
+
+// mirrorRepairReport is the result of checking the DxFiles and DxDirs against their mirrors
+type mirrorRepairReport struct {
+	// Checked is the number of DxFiles and DxDirs checked
+	Checked int
+
+	// Restored is the number of DxFiles and DxDirs corrupted or lost, which are restored
+	// from the mirrors
+	Restored int
+
+	// Mirrored is the number of mirrors corrupted, lost or outdated, which are written again
+	Mirrored int
+
+	// Unrecoverable is the paths of the DxFiles and DxDirs corrupted along with their mirrors
+	Unrecoverable []string
+}
+
+// repairMirrors goes through the file root directory and makes sure each DxFile and DxDir is
+// the same as its mirror. The file corrupted or lost is restored from the mirror, and the
+// mirror is written again from the file otherwise. The file is not touched if both the file
+// and the mirror are corrupted. It is run at startup after the file wal is applied, before
+// any of the files is opened
+func (fs *fileSystem) repairMirrors() (mirrorRepairReport, error) {
+	var report mirrorRepairReport
+	err := filepath.Walk(string(fs.fileRootDir), func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		// the mirror is checked along with the file, unless the file is lost
+		if strings.HasSuffix(path, storage.MirrorExt) {
+			primary := strings.TrimSuffix(path, storage.MirrorExt)
+			if _, err := os.Stat(primary); !os.IsNotExist(err) {
+				return nil
+			}
+			path = primary
+		}
+		check := metadataChecker(path)
+		if check == nil {
+			return nil
+		}
+		report.Checked++
+		return fs.repairMirror(path, check, &report)
+	})
+	return report, err
+}
+
+// repairMirror checks the file with the path and its mirror, and repairs the one corrupted. The
+// copy matching its checksum is valid, and the copy without the checksum recorded, persisted
+// before the checksums were added, is valid as long as it could be loaded. If both copies are
+// valid but differ, the one verified by the checksum is trusted, and the file otherwise
+func (fs *fileSystem) repairMirror(path string, check func(storage.SysPath) error, report *mirrorRepairReport) error {
+	mirror := storage.MirrorPath(path)
+	fileValid, fileVerified, fileErr := verifyMetadataFile(path, check)
+	mirrorValid, mirrorVerified, mirrorErr := verifyMetadataFile(mirror, check)
+
+	switch {
+	case fileValid && mirrorValid:
+		same, err := sameContent(path, mirror)
+		if err != nil {
+			return err
+		}
+		if same {
+			if fileVerified && mirrorVerified {
+				return nil
+			}
+			return writeMetadataChecksums(path)
+		}
+		if mirrorVerified && !fileVerified {
+			return fs.restoreFromMirror(path, fmt.Errorf("the metadata differs from the mirror verified by checksum"), report)
+		}
+		// both could be loaded but differ, e.g. the mirror of the file persisted before
+		// the mirrors were added, which only got the later updates. The file is trusted
+		fallthrough
+	case fileValid:
+		if err := copyMetadataFile(path, mirror); err != nil {
+			return err
+		}
+		report.Mirrored++
+		return writeMetadataChecksums(path)
+	case mirrorValid:
+		return fs.restoreFromMirror(path, fileErr, report)
+	default:
+		fs.logger.Error("the metadata and its mirror are both corrupted", "path", path, "err", fileErr, "mirrorErr", mirrorErr)
+		report.Unrecoverable = append(report.Unrecoverable, path)
+	}
+	return nil
+}
+
+// restoreFromMirror restores the corrupted file with the path from its mirror
+func (fs *fileSystem) restoreFromMirror(path string, fileErr error, report *mirrorRepairReport) error {
+	fs.logger.Warn("restoring the corrupted metadata from the mirror", "path", path, "err", fileErr)
+	if err := copyMetadataFile(storage.MirrorPath(path), path); err != nil {
+		return err
+	}
+	report.Restored++
+	return writeMetadataChecksums(path)
+}
+
+// verifyMetadataFile checks the DxFile or DxDir with the path could be loaded and matches its
+// checksum. valid is true if the file could be loaded and does not mismatch the checksum, and
+// verified is true if the checksum is recorded and matched
+func verifyMetadataFile(path string, check func(storage.SysPath) error) (valid, verified bool, err error) {
+	if err = check(storage.SysPath(path)); err != nil {
+		return false, false, err
+	}
+	switch err = storage.VerifyChecksum(path); err {
+	case nil:
+		return true, true, nil
+	case storage.ErrNoChecksum:
+		return true, false, nil
+	default:
+		return false, false, err
+	}
+}
+
+// writeMetadataChecksums records the checksum of the file with the path, which is the same as
+// its mirror, for both the file and the mirror
+func writeMetadataChecksums(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	checksum := storage.Checksum(content)
+	for _, p := range []string{path, storage.MirrorPath(path)} {
+		if err = writeMetadataFile(bytes.NewReader(checksum), storage.ChecksumPath(p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// metadataChecker returns the function checking the persisted DxFile or DxDir with the path.
+// nil is returned if the path is neither a DxFile nor a DxDir
+func metadataChecker(path string) func(storage.SysPath) error {
+	switch {
+	case filepath.Ext(path) == storage.DxFileExt:
+		return dxfile.Check
+	case filepath.Base(path) == dxdir.DirFileName:
+		return dxdir.Check
+	default:
+		return nil
+	}
+}
+
+// sameContent checks whether the two files have the same content
+func sameContent(path1, path2 string) (bool, error) {
+	content1, err := ioutil.ReadFile(path1)
+	if err != nil {
+		return false, err
+	}
+	content2, err := ioutil.ReadFile(path2)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(content1, content2), nil
+}
+
+// copyMetadataFile copies the file from src to dst
+func copyMetadataFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return writeMetadataFile(in, dst)
+}
+
+// writeMetadataFile writes the content read from in to dst. The content is written to a
+// temporary file and then renamed, so that dst is never partially written
+func writeMetadataFile(in io.Reader, dst string) error {
+	tmp := dst + repairTempSuffix
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
@@ -0,0 +1,132 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package filesystem
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/erasurecode"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxdir"
+)
+
+// TestFileSystem_RepairMirrors test the corrupted DxFile is restored from the mirror, and the
+// lost mirror of the DxDir is written again at startup
+func TestFileSystem_RepairMirrors(t *testing.T) {
+	fs := newEmptyTestFileSystem(t, "", &AlwaysSuccessContractManager{}, newStandardDisrupter())
+	ck, err := crypto.GenerateCipherKey(crypto.GCMCipherCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := randomDxPath(t, 2)
+	entry, err := fs.fileSet.NewRandomDxFile(path, 10, 30, erasurecode.ECTypeStandard, ck, 1<<22, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = entry.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = fs.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	filePath := string(fs.fileRootDir.Join(path)) + storage.DxFileExt
+	expect, err := ioutil.ReadFile(storage.MirrorPath(filePath))
+	if err != nil {
+		t.Fatalf("the mirror of the file is not written: %v", err)
+	}
+	if err = ioutil.WriteFile(filePath, make([]byte, 64), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dirPath := string(fs.fileRootDir.Join(storage.RootDxPath(), dxdir.DirFileName))
+	if err = os.Remove(storage.MirrorPath(dirPath)); err != nil {
+		t.Fatal(err)
+	}
+
+	// restart the file system
+	fs = newFileSystem(string(fs.persistDir), &AlwaysSuccessContractManager{}, newStandardDisrupter())
+	report, err := fs.repairMirrors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Restored != 1 || report.Mirrored != 1 || len(report.Unrecoverable) != 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, expect) {
+		t.Errorf("the file is not restored from the mirror")
+	}
+	if same, err := sameContent(dirPath, storage.MirrorPath(dirPath)); err != nil || !same {
+		t.Errorf("the mirror of the dir is not written again: %v", err)
+	}
+
+	if err = fs.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+	if entry, err = fs.OpenDxFile(path); err != nil {
+		t.Fatalf("cannot open the restored file: %v", err)
+	}
+	entry.Close()
+}
+
+// TestFileSystem_RepairMirrorsUnrecoverable test the DxFile is reported as unrecoverable and
+// left untouched, if neither the file nor the mirror matches the checksum
+func TestFileSystem_RepairMirrorsUnrecoverable(t *testing.T) {
+	fs := newEmptyTestFileSystem(t, "", &AlwaysSuccessContractManager{}, newStandardDisrupter())
+	ck, err := crypto.GenerateCipherKey(crypto.GCMCipherCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := randomDxPath(t, 2)
+	entry, err := fs.fileSet.NewRandomDxFile(path, 10, 30, erasurecode.ECTypeStandard, ck, 1<<22, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = entry.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = fs.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	filePath := string(fs.fileRootDir.Join(path)) + storage.DxFileExt
+	for _, p := range []string{filePath, storage.MirrorPath(filePath)} {
+		if err := storage.VerifyChecksum(p); err != nil {
+			t.Fatalf("the checksum of %v is not verified: %v", p, err)
+		}
+	}
+	// the file could not be loaded, and the mirror does not match its checksum
+	corrupted := make([]byte, 64)
+	if err = ioutil.WriteFile(filePath, corrupted, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(storage.ChecksumPath(storage.MirrorPath(filePath)), make([]byte, 32), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fs = newFileSystem(string(fs.persistDir), &AlwaysSuccessContractManager{}, newStandardDisrupter())
+	report, err := fs.repairMirrors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Restored != 0 || len(report.Unrecoverable) != 1 || report.Unrecoverable[0] != filePath {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, corrupted) {
+		t.Errorf("the unrecoverable file is overwritten")
+	}
+}
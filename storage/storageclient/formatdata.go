@@ -6,10 +6,12 @@ package storageclient
 
 import (
 	"fmt"
+	"github.com/DxChainNetwork/godx/common"
 	"github.com/DxChainNetwork/godx/common/unit"
 	"github.com/DxChainNetwork/godx/core/types"
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/contractmanager"
 )
 
 // ContractMetaDataAPIDisplay is the data structure used for console
@@ -33,6 +35,7 @@ type ContractMetaDataAPIDisplay struct {
 	UploadAbility string
 	RenewAbility  string
 	Canceled      string
+	Confirmation  string
 }
 
 // formatContractMetaData will format the contract meta data into a format of contract
@@ -56,6 +59,14 @@ func formatContractMetaData(data storage.ContractMetaData) (formatted ContractMe
 	return
 }
 
+// formatConfirmation will format the confirmation status of the contract create transaction
+func formatConfirmation(pc contractmanager.PendingContract, pending bool, height uint64) string {
+	if !pending {
+		return "the contract create transaction is confirmed"
+	}
+	return fmt.Sprintf("pending: the contract create transaction %v has %v confirmations", pc.TxHash.Hex(), pc.Confirmations(height))
+}
+
 // formatStatus will format the storage contract status into human understandable format
 func formatStatus(upload, renew, canceled bool) (formatUpload, formatRenew, formatCanceled string) {
 	if upload {
@@ -85,6 +96,7 @@ func formatClientSetting(setting storage.ClientSetting) (formatted storage.Clien
 	formatted.MaxUploadSpeed = unit.FormatSpeed(setting.MaxUploadSpeed)
 	formatted.MaxDownloadSpeed = unit.FormatSpeed(setting.MaxDownloadSpeed)
 	formatted.RentPayment = formatRentPayment(setting.RentPayment)
+	formatted.FeatureFlags = setting.FeatureFlags.String()
 	return
 }
 
@@ -109,9 +121,18 @@ func formatRentPayment(rent storage.RentPayment) (formatted storage.RentPaymentA
 	formatted.ExpectedUpload = unit.FormatStorage(rent.ExpectedUpload, false)
 	formatted.ExpectedDownload = unit.FormatStorage(rent.ExpectedDownload, false)
 	formatted.ExpectedRedundancy = formatRedundancy(rent.ExpectedRedundancy)
+	formatted.DownloadBudget = formatDownloadBudget(rent.DownloadBudget)
 	return
 }
 
+// formatDownloadBudget is used to format the download budget for displaying purpose
+func formatDownloadBudget(budget common.BigInt) (formatted string) {
+	if budget.Sign() == 0 {
+		return "Unlimited"
+	}
+	return unit.FormatCurrency(budget)
+}
+
 // formatHosts is used to format the rentPayment.StorageHosts field for displaying purpose
 func formatHosts(hosts uint64) (formatted string) {
 	return fmt.Sprintf("%v Hosts", hosts)
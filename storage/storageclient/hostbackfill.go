@@ -0,0 +1,217 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/DxChainNetwork/godx/storage/storageclient/contractset"
+)
+
+var (
+	errHostBackfillRunning    = errors.New("the host announcement backfill is already running")
+	errHostBackfillNotRunning = errors.New("the host announcement backfill is not running")
+)
+
+// HostBackfillProgress is the progress of scanning the historical blocks for the
+// storage host announcements
+type HostBackfillProgress struct {
+	Running       bool    `json:"running"`
+	StartHeight   uint64  `json:"startheight"`
+	NextHeight    uint64  `json:"nextheight"`
+	TargetHeight  uint64  `json:"targetheight"`
+	Announcements uint64  `json:"announcements"`
+	Progress      float64 `json:"progress"`
+	Error         string  `json:"error"`
+}
+
+// hostBackfill keeps the state of the host announcement backfill
+type hostBackfill struct {
+	running     bool
+	stop        chan struct{}
+	startHeight uint64
+	checkpoint  contractset.HostBackfillCheckpoint
+	err         error
+	lock        sync.Mutex
+}
+
+// StartHostBackfill will start to scan the historical blocks for the storage host
+// announcements, which were made before the storage client is enabled. The scan
+// continues from the last checkpoint stored in the client database
+func (client *StorageClient) StartHostBackfill() (progress HostBackfillProgress, err error) {
+	scs := client.contractManager.GetStorageContractSet()
+	cp, _, err := scs.FetchHostBackfillCheckpoint()
+	if err != nil {
+		return
+	}
+
+	// scan up to the current block, the blocks after are handled by the storage
+	// host manager through the chain change event subscription
+	cp.TargetHeight = client.ethBackend.GetCurrentBlockHeight()
+	if err = client.startHostBackfill(cp); err != nil {
+		return
+	}
+	return client.HostBackfillProgress(), nil
+}
+
+// StopHostBackfill will stop the running host announcement backfill. The progress is
+// saved as checkpoint, the scan can be resumed by StartHostBackfill
+func (client *StorageClient) StopHostBackfill() (err error) {
+	bf := client.hostBackfill
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+
+	if !bf.running {
+		return errHostBackfillNotRunning
+	}
+
+	// the running flag is cleared by the scanning go routine once the checkpoint is stored
+	select {
+	case <-bf.stop:
+		return errHostBackfillNotRunning
+	default:
+		close(bf.stop)
+	}
+	return
+}
+
+// HostBackfillProgress will return the progress of the host announcement backfill
+func (client *StorageClient) HostBackfillProgress() (progress HostBackfillProgress) {
+	bf := client.hostBackfill
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+
+	progress = HostBackfillProgress{
+		Running:       bf.running,
+		StartHeight:   bf.startHeight,
+		NextHeight:    bf.checkpoint.NextHeight,
+		TargetHeight:  bf.checkpoint.TargetHeight,
+		Announcements: bf.checkpoint.Announcements,
+		Progress:      backfillPercentage(bf.startHeight, bf.checkpoint),
+	}
+	if bf.err != nil {
+		progress.Error = bf.err.Error()
+	}
+	return
+}
+
+// resumeHostBackfill will resume the host announcement backfill which was interrupted
+// before the storage client stopped
+func (client *StorageClient) resumeHostBackfill() (err error) {
+	cp, exist, err := client.contractManager.GetStorageContractSet().FetchHostBackfillCheckpoint()
+	if err != nil || !exist || cp.Finished() {
+		return
+	}
+	client.log.Info("Resuming the host announcement backfill", "next", cp.NextHeight, "target", cp.TargetHeight)
+	return client.startHostBackfill(cp)
+}
+
+// startHostBackfill will start the go routine scanning the blocks from the checkpoint
+func (client *StorageClient) startHostBackfill(cp contractset.HostBackfillCheckpoint) (err error) {
+	bf := client.hostBackfill
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+
+	if bf.running {
+		return errHostBackfillRunning
+	}
+
+	bf.running = !cp.Finished()
+	bf.stop = make(chan struct{})
+	bf.startHeight = cp.NextHeight
+	bf.checkpoint = cp
+	bf.err = nil
+
+	if !bf.running {
+		return
+	}
+	go client.hostBackfillLoop(bf.stop)
+	return
+}
+
+// hostBackfillLoop scans the blocks one by one, and inserts the storage hosts from the
+// announcements found into the storage host manager. The checkpoint is stored in the
+// client database every HostBackfillCheckpointInterval blocks
+func (client *StorageClient) hostBackfillLoop(stop chan struct{}) {
+	if err := client.tm.Add(); err != nil {
+		client.finishHostBackfill(stop, err)
+		return
+	}
+	defer client.tm.Done()
+
+	bf := client.hostBackfill
+	bf.lock.Lock()
+	cp := bf.checkpoint
+	bf.lock.Unlock()
+
+	scs := client.contractManager.GetStorageContractSet()
+	for scanned := uint64(1); !cp.Finished(); scanned++ {
+		select {
+		case <-stop:
+			client.finishHostBackfill(stop, scs.StoreHostBackfillCheckpoint(cp))
+			return
+		case <-client.tm.StopChan():
+			client.finishHostBackfill(stop, scs.StoreHostBackfillCheckpoint(cp))
+			return
+		default:
+		}
+
+		block, err := client.ethBackend.GetBlockByNumber(cp.NextHeight)
+		if err != nil {
+			client.log.Warn("failed to get the block for host announcement backfill", "height", cp.NextHeight, "err", err)
+			client.finishHostBackfill(stop, err)
+			return
+		}
+
+		announcements, revocations := client.hostTxsOfBlock(block)
+		client.storageHostManager.InsertHostAnnouncements(announcements)
+		client.storageHostManager.RemoveRevokedHosts(revocations)
+		cp.NextHeight++
+		cp.Announcements += uint64(len(announcements))
+
+		bf.lock.Lock()
+		bf.checkpoint = cp
+		bf.lock.Unlock()
+
+		if scanned%HostBackfillCheckpointInterval != 0 {
+			continue
+		}
+		if err := scs.StoreHostBackfillCheckpoint(cp); err != nil {
+			client.log.Warn("failed to store the host announcement backfill checkpoint", "err", err)
+		}
+	}
+
+	err := scs.StoreHostBackfillCheckpoint(cp)
+	client.finishHostBackfill(stop, err)
+	client.log.Info("Host announcement backfill finished", "target", cp.TargetHeight, "announcements", cp.Announcements)
+}
+
+// finishHostBackfill marks the host announcement backfill started with the stop channel
+// as not running, and records the error that terminated the scan
+func (client *StorageClient) finishHostBackfill(stop chan struct{}, err error) {
+	bf := client.hostBackfill
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+
+	// the backfill has been restarted
+	if bf.stop != stop {
+		return
+	}
+	bf.running = false
+	bf.err = err
+}
+
+// backfillPercentage calculates the percentage of the blocks scanned since the scan started
+func backfillPercentage(startHeight uint64, cp contractset.HostBackfillCheckpoint) float64 {
+	if cp.Finished() {
+		return 100
+	}
+	total := cp.TargetHeight + 1 - startHeight
+	if total == 0 || cp.NextHeight < startHeight {
+		return 0
+	}
+	return float64(cp.NextHeight-startHeight) / float64(total) * 100
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// errSpeedTestIncomplete is the error that the storage host did not receive all the probe data
+var errSpeedTestIncomplete = errors.New("the storage host did not receive all the probe data")
+
+// probeUpload measures the upload speed of the storage host with a speed test before the
+// first sector is uploaded by the worker. The sector upload time estimated from the probe is
+// seeded into the upload timings of the storage host, and the worker is marked slow if the
+// estimate exceeds HostProbeSlowFactor times the median sector upload time of all storage
+// hosts. A failed speed test is not retried, and the worker uploads as if it is not probed
+func (w *worker) probeUpload(sp storage.Peer) {
+	w.mu.Lock()
+	probed := w.uploadProbed
+	w.uploadProbed = true
+	w.mu.Unlock()
+	if probed {
+		return
+	}
+
+	elapsed, err := w.client.speedTest(sp, HostProbeSize)
+	if err != nil {
+		w.client.log.Debug("failed to probe the upload speed of the storage host", "hostID", w.hostID, "err", err)
+		return
+	}
+	estimate := estimateSectorTime(HostProbeSize, elapsed)
+
+	median := w.client.uploadTimings.sectorLatency().P50
+	slow := median > 0 && float64(estimate) > HostProbeSlowFactor*float64(median)
+	w.client.uploadTimings.seedSector(w.hostID, estimate)
+
+	w.mu.Lock()
+	w.uploadProbeSlow = slow
+	w.mu.Unlock()
+	w.client.log.Debug("probed the upload speed of the storage host", "hostID", w.hostID, "sectorTime", estimate, "slow", slow)
+}
+
+// speedTest sends the probe data of the size to the storage host, and returns the time used
+// until the storage host acknowledged all the data received
+func (client *StorageClient) speedTest(sp storage.Peer, size uint64) (time.Duration, error) {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return 0, err
+	}
+
+	start := client.clock.Now()
+	if err := sp.RequestSpeedTest(storage.SpeedTestRequest{Data: data}); err != nil {
+		return 0, err
+	}
+	msg, err := sp.ClientWaitContractResp()
+	if err != nil {
+		return 0, err
+	}
+	elapsed := client.clock.Since(start)
+
+	switch msg.Code {
+	case storage.HostBusyHandleReqMsg:
+		return 0, storage.ErrHostBusyHandleReq
+	case storage.HostNegotiateErrorMsg:
+		return 0, errors.New("the storage host failed to handle the speed test")
+	case storage.SpeedTestRespMsg:
+	default:
+		return 0, fmt.Errorf("unexpected message code %v for the speed test", msg.Code)
+	}
+
+	var resp storage.SpeedTestResponse
+	if err := msg.Decode(&resp); err != nil {
+		return 0, err
+	}
+	if resp.Received != size {
+		return 0, errSpeedTestIncomplete
+	}
+	return elapsed, nil
+}
+
+// estimateSectorTime scales the time used to upload the probe data of the size up to the
+// time used to upload a sector
+func estimateSectorTime(size uint64, elapsed time.Duration) time.Duration {
+	if size == 0 {
+		return 0
+	}
+	return time.Duration(float64(elapsed) * float64(storage.SectorSize) / float64(size))
+}
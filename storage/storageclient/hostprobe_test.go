@@ -0,0 +1,57 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestEstimateSectorTime(t *testing.T) {
+	tests := []struct {
+		size     uint64
+		elapsed  time.Duration
+		estimate time.Duration
+	}{
+		{storage.SectorSize, time.Second, time.Second},
+		{storage.SectorSize / 16, time.Second, 16 * time.Second},
+		{storage.SectorSize / 4, 250 * time.Millisecond, time.Second},
+		{0, time.Second, 0},
+	}
+	for _, test := range tests {
+		if estimate := estimateSectorTime(test.size, test.elapsed); estimate != test.estimate {
+			t.Errorf("size %v elapsed %v: expected %v, got %v", test.size, test.elapsed, test.estimate, estimate)
+		}
+	}
+}
+
+func TestUploadTimings_SeedSector(t *testing.T) {
+	ut := newUploadTimings()
+	probed, uploaded := enode.ID{1}, enode.ID{2}
+
+	ut.recordSector(uploaded, time.Second)
+	ut.seedSector(probed, 10*time.Second)
+	ut.seedSector(uploaded, 10*time.Second)
+
+	if latency := ut.hostUploadLatency(probed); latency.Sectors != 1 || latency.Sector.P50 != 10*time.Second {
+		t.Errorf("the probe estimate is not seeded: %+v", latency)
+	}
+	if latency := ut.hostUploadLatency(uploaded); latency.Sectors != 1 || latency.Sector.P50 != time.Second {
+		t.Errorf("the measured sector time should not be overridden by the seed: %+v", latency)
+	}
+
+	// the seed is not counted towards rescoring
+	for i := 0; i < SlowHostRescoreInterval-1; i++ {
+		if ut.recordSector(probed, time.Second) {
+			t.Fatal("the storage host rescored before enough sectors uploaded")
+		}
+	}
+	if !ut.recordSector(probed, time.Second) {
+		t.Error("expected the storage host to be rescored")
+	}
+}
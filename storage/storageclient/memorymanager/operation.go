@@ -16,6 +16,13 @@ func (mm *MemoryManager) MemoryAvailable() uint64 {
 	return mm.available
 }
 
+// Saturated returns whether there are memory requests waiting for the memory to be returned
+func (mm *MemoryManager) Saturated() bool {
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+	return len(mm.waitlist)+len(mm.priorityWaitlist) > 0
+}
+
 // SetMemoryLimit allows user to expand or shrink the current memory limit
 func (mm *MemoryManager) SetMemoryLimit(amount uint64) string {
 	if amount < mm.limit {
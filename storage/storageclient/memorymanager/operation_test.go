@@ -58,3 +58,28 @@ func TestMemoryManager_SetMemoryLimit_Shrink(t *testing.T) {
 		t.Errorf("error: memory shrunk, memory left should be 5000, instead got: %d", mm.available)
 	}
 }
+
+func TestMemoryManager_Saturated(t *testing.T) {
+	done := make(chan struct{})
+	mm := New(10000, stopChan)
+
+	mm.Request(10000, false)
+	if mm.Saturated() {
+		t.Fatalf("the memory manager without waiting requests should not be saturated")
+	}
+
+	go func() {
+		mm.Request(5000, false)
+		close(done)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if !mm.Saturated() {
+		t.Errorf("the memory manager with waiting requests should be saturated")
+	}
+
+	mm.Return(10000)
+	<-done
+	if mm.Saturated() {
+		t.Errorf("the memory manager should not be saturated once the waiting request is served")
+	}
+}
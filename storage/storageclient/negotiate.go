@@ -20,14 +20,14 @@ func NewRevision(current types.StorageContractRevision, cost *big.Int) types.Sto
 	for i, v := range current.NewValidProofOutputs {
 		rev.NewValidProofOutputs[i] = types.DxcoinCharge{
 			Address: v.Address,
-			Value:   big.NewInt(v.Value.Int64()),
+			Value:   new(big.Int).Set(v.Value),
 		}
 	}
 
 	for i, v := range current.NewMissedProofOutputs {
 		rev.NewMissedProofOutputs[i] = types.DxcoinCharge{
 			Address: v.Address,
-			Value:   big.NewInt(v.Value.Int64()),
+			Value:   new(big.Int).Set(v.Value),
 		}
 	}
 
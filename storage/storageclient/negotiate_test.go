@@ -52,3 +52,34 @@ func TestNewRevision(t *testing.T) {
 		t.Errorf("wrong new host missed output,wanted %d,getted %d", 1000000, newRev.NewMissedProofOutputs[1].Value.Int64())
 	}
 }
+
+func TestNewRevisionLargeDenominations(t *testing.T) {
+	// the outputs of 10^24, far beyond int64
+	dx := new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil)
+	currentRev := types.StorageContractRevision{
+		NewValidProofOutputs: []types.DxcoinCharge{
+			{Address: clientAddress, Value: new(big.Int).Mul(dx, big.NewInt(3))},
+			{Address: hostAddress, Value: new(big.Int).Set(dx)},
+		},
+		NewMissedProofOutputs: []types.DxcoinCharge{
+			{Address: clientAddress, Value: new(big.Int).Mul(dx, big.NewInt(3))},
+			{Address: hostAddress, Value: new(big.Int).Set(dx)},
+		},
+	}
+	newRev := NewRevision(currentRev, dx)
+
+	expected := []*big.Int{new(big.Int).Mul(dx, big.NewInt(2)), new(big.Int).Mul(dx, big.NewInt(2)),
+		new(big.Int).Mul(dx, big.NewInt(2)), dx}
+	got := []*big.Int{newRev.NewValidProofOutputs[0].Value, newRev.NewValidProofOutputs[1].Value,
+		newRev.NewMissedProofOutputs[0].Value, newRev.NewMissedProofOutputs[1].Value}
+	for i := range expected {
+		if got[i].Cmp(expected[i]) != 0 {
+			t.Errorf("output %d: expect %v, got %v", i, expected[i], got[i])
+		}
+	}
+
+	// the current revision is not changed
+	if currentRev.NewValidProofOutputs[0].Value.Cmp(new(big.Int).Mul(dx, big.NewInt(3))) != 0 {
+		t.Errorf("the current revision is changed, got %v", currentRev.NewValidProofOutputs[0].Value)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/params"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+var (
+	errOnboardMainnet = errors.New("onboarding with the faucet is only available on the test networks")
+	errNoFaucet       = errors.New("no faucet is configured")
+)
+
+// OnboardResult is the result of the onboarding
+type OnboardResult struct {
+	PaymentAddress common.Address      `json:"paymentaddress"`
+	Funded         bool                `json:"funded"`
+	Balance        common.BigInt       `json:"balance"`
+	RentPayment    storage.RentPayment `json:"rentpayment"`
+}
+
+// faucetRequest is the request body posted to the faucet
+type faucetRequest struct {
+	Address common.Address `json:"address"`
+}
+
+// SetFaucet sets the faucet endpoint the funds are requested from on the test networks. The
+// empty url removes the faucet
+func (client *StorageClient) SetFaucet(faucet string) error {
+	if faucet != "" {
+		if err := validateFaucet(faucet); err != nil {
+			return err
+		}
+	}
+	return client.settings.update(func(s *Settings) error {
+		s.Faucet = faucet
+		return nil
+	})
+}
+
+// Onboard sets up the storage client for the first use on the test networks in one call. If the
+// payment address cannot afford the rent payment fund, the funds are requested from the faucet
+// and waited to be confirmed. Then the rent payment is set with the defaults filling the fields
+// not set, which triggers the contract formation
+func (client *StorageClient) Onboard(ctx context.Context) (result OnboardResult, err error) {
+	if err = client.tm.Add(); err != nil {
+		return
+	}
+	defer client.tm.Done()
+
+	if !isTestNetwork(client.ethBackend.ChainConfig()) {
+		return result, errOnboardMainnet
+	}
+	faucet := client.settings.get().Faucet
+	if faucet == "" {
+		return result, errNoFaucet
+	}
+	if result.PaymentAddress, err = client.GetPaymentAddress(); err != nil {
+		return
+	}
+
+	setting := client.RetrieveClientSetting()
+	setting = clientSettingGetDefault(setting)
+
+	balance, err := client.balance(result.PaymentAddress)
+	if err != nil {
+		return
+	}
+	if balance.Cmp(setting.RentPayment.Fund.BigIntPtr()) < 0 {
+		if err = requestFaucet(ctx, faucet, result.PaymentAddress); err != nil {
+			return result, fmt.Errorf("failed to request the funds from the faucet: %s", err.Error())
+		}
+		client.log.Info("funds requested from the faucet", "faucet", faucet, "address", result.PaymentAddress)
+		if balance, err = client.waitFunds(ctx, result.PaymentAddress, balance); err != nil {
+			return
+		}
+		result.Funded = true
+	}
+	result.Balance = common.PtrBigInt(balance)
+
+	if err = client.SetClientSetting(setting); err != nil {
+		return result, fmt.Errorf("failed to set the rent payment: %s", err.Error())
+	}
+	result.RentPayment = setting.RentPayment
+	return
+}
+
+// balance returns the balance of the address in the current state
+func (client *StorageClient) balance(addr common.Address) (*big.Int, error) {
+	state, err := client.ethBackend.GetBlockChain().State()
+	if err != nil {
+		return nil, err
+	}
+	return state.GetBalance(addr), nil
+}
+
+// waitFunds waits until the balance of the address increases from the balance before the funds
+// requested, which means the faucet transaction is confirmed
+func (client *StorageClient) waitFunds(ctx context.Context, addr common.Address, before *big.Int) (*big.Int, error) {
+	timeout := time.NewTimer(OnboardFundTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(OnboardCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-timeout.C:
+			return nil, fmt.Errorf("the faucet funds are not confirmed in %v", OnboardFundTimeout)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-client.tm.StopChan():
+			return nil, errors.New("storage client is shutdown")
+		}
+		balance, err := client.balance(addr)
+		if err != nil {
+			return nil, err
+		}
+		if balance.Cmp(before) > 0 {
+			return balance, nil
+		}
+	}
+}
+
+// requestFaucet posts the address to the faucet, which is expected to answer with the 2xx
+// status once the funding transaction is sent
+func requestFaucet(ctx context.Context, faucet string, addr common.Address) error {
+	body, err := json.Marshal(faucetRequest{Address: addr})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, faucet, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	hc := &http.Client{Timeout: FaucetRequestTimeout}
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("POST %v failed: %v", faucet, resp.Status)
+	}
+	return nil
+}
+
+// validateFaucet checks the faucet is a http or https url
+func validateFaucet(faucet string) error {
+	u, err := url.Parse(faucet)
+	if err != nil {
+		return fmt.Errorf("invalid faucet url: %s", err.Error())
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid faucet url %v, must be a http or https url", faucet)
+	}
+	return nil
+}
+
+// isTestNetwork checks whether the chain is not the main network, where the faucet is never used
+func isTestNetwork(config *params.ChainConfig) bool {
+	return config != nil && config.ChainID != nil && config.ChainID.Cmp(params.MainnetChainConfig.ChainID) != 0
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/params"
+)
+
+func TestValidateFaucet(t *testing.T) {
+	tests := []struct {
+		faucet string
+		valid  bool
+	}{
+		{"http://localhost:8080/fund", true},
+		{"https://faucet.example.com", true},
+		{"ftp://faucet.example.com", false},
+		{"faucet.example.com", false},
+		{"http://", false},
+	}
+	for _, test := range tests {
+		if err := validateFaucet(test.faucet); (err == nil) != test.valid {
+			t.Errorf("faucet %v: expect valid %v, got error %v", test.faucet, test.valid, err)
+		}
+	}
+}
+
+func TestIsTestNetwork(t *testing.T) {
+	if isTestNetwork(params.MainnetChainConfig) {
+		t.Error("the main network shall not be a test network")
+	}
+	if !isTestNetwork(params.TestnetChainConfig) {
+		t.Error("the testnet shall be a test network")
+	}
+	if isTestNetwork(&params.ChainConfig{ChainID: big.NewInt(1)}) || isTestNetwork(nil) {
+		t.Error("the chain with the main network id shall not be a test network")
+	}
+}
+
+func TestRequestFaucet(t *testing.T) {
+	addr := common.HexToAddress("0x1")
+	var received faucetRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if received.Address != addr {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	if err := requestFaucet(context.Background(), server.URL, addr); err != nil {
+		t.Fatal(err)
+	}
+	if received.Address != addr {
+		t.Errorf("expect address %v requested, got %v", addr, received.Address)
+	}
+	if err := requestFaucet(context.Background(), server.URL, common.HexToAddress("0x2")); err == nil {
+		t.Error("the request rejected by the faucet shall fail")
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Operation types and states
+const (
+	OperationUpload   = "upload"
+	OperationDownload = "download"
+
+	OperationRunning   = "running"
+	OperationCompleted = "completed"
+	OperationCancelled = "cancelled"
+	OperationFailed    = "failed"
+)
+
+var (
+	errOperationCancelled = errors.New("the operation is cancelled")
+	errUnknownOperation   = errors.New("unknown operation")
+)
+
+// OperationStatus is the status of an upload or download operation. For an upload, the
+// bytes completed and sectors committed are the sector data committed to the storage hosts.
+// For a download, they are the file data recovered and the sectors received, and the
+// verification is the result of verifying the downloaded file against the checksum at upload
+type OperationStatus struct {
+	ID               string    `json:"id"`
+	Type             string    `json:"type"`
+	DxPath           string    `json:"dxpath"`
+	State            string    `json:"state"`
+	StartTime        time.Time `json:"starttime"`
+	EndTime          time.Time `json:"endtime"`
+	BytesCompleted   uint64    `json:"bytescompleted"`
+	SectorsCommitted uint64    `json:"sectorscommitted"`
+	Verification     string    `json:"verification"`
+	Error            string    `json:"error"`
+}
+
+// operation is an upload or download in flight. All methods can be called on a nil
+// operation, which is the case for the segments repaired by the background loops
+type operation struct {
+	status OperationStatus
+
+	// upload segments not released from the upload heap and workers yet, and whether
+	// all segments of the upload have been created
+	segments        map[uploadSegmentID]struct{}
+	segmentsCreated bool
+
+	// onCancel stops the work of the operation when it is cancelled
+	onCancel  func()
+	cancelled chan struct{}
+
+	// onComplete is called with the final status once the operation is completed
+	onComplete func(OperationStatus)
+	lock       sync.Mutex
+}
+
+// operationSet keeps the operations in flight and the most recent finished ones
+type operationSet struct {
+	operations map[string]*operation
+	lock       sync.Mutex
+}
+
+// newOperationSet creates an empty operation set
+func newOperationSet() *operationSet {
+	return &operationSet{
+		operations: make(map[string]*operation),
+	}
+}
+
+// Operations returns the status of the operations in flight and the most recent
+// finished ones, ordered by the start time
+func (client *StorageClient) Operations() []OperationStatus {
+	return client.operations.list()
+}
+
+// CancelOperation cancels the upload or download in flight with the operation id, and
+// returns its final status. The segments queued in the workers are dropped so that the
+// memory is returned promptly. The file of a cancelled upload is deleted, so that the
+// repair loop does not resume the upload
+func (client *StorageClient) CancelOperation(id string) (OperationStatus, error) {
+	op := client.operations.get(id)
+	if op == nil {
+		return OperationStatus{}, errUnknownOperation
+	}
+
+	op.lock.Lock()
+	if status := op.status; status.State != OperationRunning {
+		op.lock.Unlock()
+		return status, fmt.Errorf("the operation is already %v", status.State)
+	}
+	close(op.cancelled)
+	onCancel := op.onCancel
+	op.lock.Unlock()
+
+	if onCancel != nil {
+		onCancel()
+	}
+	op.finish(errOperationCancelled)
+	client.dropCancelledSegments()
+	return op.snapshot(), nil
+}
+
+// dropCancelledSegments drops the segments of the cancelled operations queued in the workers
+func (client *StorageClient) dropCancelledSegments() {
+	client.lock.Lock()
+	workers := make([]*worker, 0, len(client.workerPool))
+	for _, w := range client.workerPool {
+		workers = append(workers, w)
+	}
+	client.lock.Unlock()
+
+	for _, w := range workers {
+		w.dropCancelledSegments()
+	}
+}
+
+// add creates and registers a new running operation. The finished operations exceeding
+// MaxOperationHistory are removed
+func (ops *operationSet) add(opType, dxPath string) *operation {
+	var b [8]byte
+	rand.Read(b[:])
+	op := &operation{
+		status: OperationStatus{
+			ID:        hex.EncodeToString(b[:]),
+			Type:      opType,
+			DxPath:    dxPath,
+			State:     OperationRunning,
+			StartTime: time.Now(),
+		},
+		segments:  make(map[uploadSegmentID]struct{}),
+		cancelled: make(chan struct{}),
+	}
+
+	ops.lock.Lock()
+	defer ops.lock.Unlock()
+	ops.operations[op.status.ID] = op
+
+	var finished []OperationStatus
+	for _, existing := range ops.operations {
+		if status := existing.snapshot(); status.State != OperationRunning {
+			finished = append(finished, status)
+		}
+	}
+	if len(finished) > MaxOperationHistory {
+		sort.Slice(finished, func(i, j int) bool { return finished[i].EndTime.Before(finished[j].EndTime) })
+		for _, status := range finished[:len(finished)-MaxOperationHistory] {
+			delete(ops.operations, status.ID)
+		}
+	}
+	return op
+}
+
+// get returns the operation with the id, or nil if not found
+func (ops *operationSet) get(id string) *operation {
+	ops.lock.Lock()
+	defer ops.lock.Unlock()
+	return ops.operations[id]
+}
+
+// runningUpload returns the running upload operation of the file, or nil if not found
+func (ops *operationSet) runningUpload(dxPath string) *operation {
+	ops.lock.Lock()
+	defer ops.lock.Unlock()
+	for _, op := range ops.operations {
+		if status := op.snapshot(); status.Type == OperationUpload && status.DxPath == dxPath && status.State == OperationRunning {
+			return op
+		}
+	}
+	return nil
+}
+
+// list returns the status of all operations ordered by the start time
+func (ops *operationSet) list() []OperationStatus {
+	ops.lock.Lock()
+	defer ops.lock.Unlock()
+	statuses := make([]OperationStatus, 0, len(ops.operations))
+	for _, op := range ops.operations {
+		statuses = append(statuses, op.snapshot())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].StartTime.Before(statuses[j].StartTime) })
+	return statuses
+}
+
+// setCancel sets the function stopping the work of the operation. It is called at once
+// if the operation has been cancelled already
+func (op *operation) setCancel(f func()) {
+	op.lock.Lock()
+	op.onCancel = f
+	op.lock.Unlock()
+	if op.isCancelled() {
+		f()
+	}
+}
+
+// setComplete sets the function called with the final status once the operation is
+// completed successfully
+func (op *operation) setComplete(f func(OperationStatus)) {
+	op.lock.Lock()
+	op.onComplete = f
+	op.lock.Unlock()
+}
+
+// isCancelled returns whether the operation is cancelled
+func (op *operation) isCancelled() bool {
+	if op == nil {
+		return false
+	}
+	select {
+	case <-op.cancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// addProgress adds the bytes and sectors completed to the running operation
+func (op *operation) addProgress(bytes, sectors uint64) {
+	if op == nil {
+		return
+	}
+	op.lock.Lock()
+	defer op.lock.Unlock()
+	if op.status.State == OperationRunning {
+		op.status.BytesCompleted += bytes
+		op.status.SectorsCommitted += sectors
+	}
+}
+
+// setVerification records the checksum verification result of the download
+func (op *operation) setVerification(result string) {
+	if op == nil {
+		return
+	}
+	op.lock.Lock()
+	op.status.Verification = result
+	op.lock.Unlock()
+}
+
+// addSegment records an upload segment pushed to the upload heap
+func (op *operation) addSegment(id uploadSegmentID) {
+	if op == nil {
+		return
+	}
+	op.lock.Lock()
+	op.segments[id] = struct{}{}
+	op.lock.Unlock()
+}
+
+// segmentDone records an upload segment released from the upload heap or the workers
+func (op *operation) segmentDone(id uploadSegmentID) {
+	if op == nil {
+		return
+	}
+	op.lock.Lock()
+	delete(op.segments, id)
+	op.lock.Unlock()
+	op.finishIfUploaded()
+}
+
+// markSegmentsCreated records all segments of the upload have been pushed to the upload heap
+func (op *operation) markSegmentsCreated() {
+	op.lock.Lock()
+	op.segmentsCreated = true
+	op.lock.Unlock()
+	op.finishIfUploaded()
+}
+
+// finishIfUploaded completes the upload once all segments are created and released
+func (op *operation) finishIfUploaded() {
+	op.lock.Lock()
+	done := op.segmentsCreated && len(op.segments) == 0
+	op.lock.Unlock()
+	if done {
+		op.finish(nil)
+	}
+}
+
+// finish marks the running operation as finished with the error
+func (op *operation) finish(err error) {
+	if op == nil {
+		return
+	}
+	op.lock.Lock()
+	if op.status.State != OperationRunning {
+		op.lock.Unlock()
+		return
+	}
+	op.status.EndTime = time.Now()
+	var onComplete func(OperationStatus)
+	switch {
+	case op.isCancelled():
+		op.status.State = OperationCancelled
+		op.status.Error = errOperationCancelled.Error()
+	case err == nil:
+		op.status.State = OperationCompleted
+		onComplete = op.onComplete
+	default:
+		op.status.State = OperationFailed
+		op.status.Error = err.Error()
+	}
+	status := op.status
+	op.lock.Unlock()
+
+	if onComplete != nil {
+		onComplete(status)
+	}
+}
+
+// snapshot returns a copy of the operation status
+func (op *operation) snapshot() OperationStatus {
+	op.lock.Lock()
+	defer op.lock.Unlock()
+	return op.status
+}
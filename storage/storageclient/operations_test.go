@@ -0,0 +1,108 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"testing"
+
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestOperation_UploadSegments(t *testing.T) {
+	ops := newOperationSet()
+	op := ops.add(OperationUpload, "file")
+	if ops.runningUpload("file") != op {
+		t.Fatalf("the running upload should be found by the dx path")
+	}
+
+	var completed []OperationStatus
+	op.setComplete(func(status OperationStatus) { completed = append(completed, status) })
+
+	first, second := uploadSegmentID{index: 0}, uploadSegmentID{index: 1}
+	op.addSegment(first)
+	op.addSegment(second)
+	op.addSegment(first)
+	op.segmentDone(first)
+	op.addProgress(storage.SectorSize, 1)
+	op.markSegmentsCreated()
+	if status := op.snapshot(); status.State != OperationRunning {
+		t.Fatalf("upload with pending segments should be running, got %v", status.State)
+	}
+
+	op.segmentDone(second)
+	status := op.snapshot()
+	if status.State != OperationCompleted || status.SectorsCommitted != 1 || status.BytesCompleted != storage.SectorSize {
+		t.Errorf("final status not expected: %+v", status)
+	}
+	if ops.runningUpload("file") != nil {
+		t.Errorf("the completed upload should not be running")
+	}
+	if len(completed) != 1 || completed[0].State != OperationCompleted {
+		t.Errorf("the completion callback should be called once with the final status, got %+v", completed)
+	}
+
+	// the progress after finished is not recorded
+	op.addProgress(storage.SectorSize, 1)
+	if op.snapshot().SectorsCommitted != 1 {
+		t.Errorf("progress recorded after the operation finished")
+	}
+}
+
+func TestStorageClient_CancelOperation(t *testing.T) {
+	client := &StorageClient{
+		operations: newOperationSet(),
+		workerPool: make(map[storage.ContractID]*worker),
+	}
+	op := client.operations.add(OperationDownload, "file")
+	var cancelled int
+	op.setCancel(func() { cancelled++ })
+	op.addProgress(100, 2)
+
+	status, err := client.CancelOperation(op.status.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled != 1 || !op.isCancelled() {
+		t.Errorf("the cancel function should be called once, got %v", cancelled)
+	}
+	if status.State != OperationCancelled || status.BytesCompleted != 100 || status.SectorsCommitted != 2 {
+		t.Errorf("final status not expected: %+v", status)
+	}
+
+	if _, err := client.CancelOperation(op.status.ID); err == nil {
+		t.Errorf("cancelling a finished operation should fail")
+	}
+	if _, err := client.CancelOperation("unknown"); err != errUnknownOperation {
+		t.Errorf("error not expected. Expect %v, Got %v", errUnknownOperation, err)
+	}
+
+	// the cancel function set after cancelled is called at once
+	op.setCancel(func() { cancelled++ })
+	if cancelled != 2 {
+		t.Errorf("the cancel function set after cancelled should be called at once")
+	}
+}
+
+func TestOperationSet_History(t *testing.T) {
+	ops := newOperationSet()
+	running := ops.add(OperationUpload, "running")
+	for i := 0; i < MaxOperationHistory+10; i++ {
+		ops.add(OperationDownload, "file").finish(nil)
+	}
+	ops.add(OperationDownload, "file")
+
+	statuses := ops.list()
+	if len(statuses) != MaxOperationHistory+2 {
+		t.Fatalf("number of operations not expected. Expect %v, Got %v", MaxOperationHistory+2, len(statuses))
+	}
+	if ops.get(running.status.ID) != running {
+		t.Errorf("the running operation should not be removed")
+	}
+	for i := 1; i < len(statuses); i++ {
+		if statuses[i].StartTime.Before(statuses[i-1].StartTime) {
+			t.Fatalf("operations not ordered by the start time")
+		}
+	}
+}
@@ -6,22 +6,10 @@ package storageclient
 
 import (
 	"os"
-	"path/filepath"
 
-	"github.com/DxChainNetwork/godx/common"
 	"github.com/DxChainNetwork/godx/log"
 )
 
-var settingsMetadata = common.Metadata{
-	Header:  "storage client Settings",
-	Version: PersistStorageClientVersion,
-}
-
-type persistence struct {
-	MaxDownloadSpeed int64
-	MaxUploadSpeed   int64
-}
-
 func (client *StorageClient) loadPersist() error {
 	// make directory
 	err := os.MkdirAll(client.staticFilesDir, 0700)
@@ -35,24 +23,14 @@ func (client *StorageClient) loadPersist() error {
 	return client.loadSettings()
 }
 
-// save StorageClient settings into storageclient.json file
-func (client *StorageClient) saveSettings() error {
-	return common.SaveDxJSON(settingsMetadata, filepath.Join(client.persistDir, PersistFilename), client.persist)
-}
-
 // load prior StorageClient settings
 func (client *StorageClient) loadSettings() error {
-	client.persist = persistence{}
-	err := common.LoadDxJSON(settingsMetadata, filepath.Join(client.persistDir, PersistFilename), &client.persist)
-	if os.IsNotExist(err) {
-		client.persist.MaxDownloadSpeed = DefaultMaxDownloadSpeed
-		client.persist.MaxUploadSpeed = DefaultMaxUploadSpeed
-		err = client.saveSettings()
-		if err != nil {
-			return err
-		}
-	} else if err != nil {
+	if err := client.settings.load(); err != nil {
+		return err
+	}
+	if err := client.migrateBackupPassphrase(); err != nil {
 		return err
 	}
-	return client.setBandwidthLimits(client.persist.MaxUploadSpeed, client.persist.MaxUploadSpeed)
+	settings := client.settings.get()
+	return client.setBandwidthLimits(settings.MaxDownloadSpeed, settings.MaxUploadSpeed)
 }
@@ -0,0 +1,76 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// PinFile constrains the sectors of the file to the hosts specified. Each id could be either
+// a storage host id or the id of a storage contract, which is resolved to the host of the
+// contract. The constraint is enforced when assigning sectors to the workers, and the sectors
+// stored on other hosts are moved to the pinned hosts during repair
+func (client *StorageClient) PinFile(dxPath storage.DxPath, ids []string) ([]enode.ID, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no hosts provided to pin the file %v to", dxPath.Path)
+	}
+	hosts, err := resolvePinnedHosts(ids, client.contractHost)
+	if err != nil {
+		return nil, err
+	}
+	if err = client.setPinnedHosts(dxPath, hosts); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
+// UnpinFile removes the host constraint of the file
+func (client *StorageClient) UnpinFile(dxPath storage.DxPath) error {
+	return client.setPinnedHosts(dxPath, nil)
+}
+
+// setPinnedHosts sets the pinned hosts of the file
+func (client *StorageClient) setPinnedHosts(dxPath storage.DxPath, hosts []enode.ID) error {
+	entry, err := client.fileSystem.OpenDxFile(dxPath)
+	if err != nil {
+		return err
+	}
+	defer entry.Close()
+	return entry.SetPinnedHosts(hosts)
+}
+
+// contractHost returns the host of the active contract
+func (client *StorageClient) contractHost(id storage.ContractID) (enode.ID, bool) {
+	meta, exist := client.contractManager.GetStorageContractSet().RetrieveContractMetaData(id)
+	return meta.EnodeID, exist
+}
+
+// resolvePinnedHosts parses the hex encoded ids to the host ids, with or without the 0x
+// prefix. The id of an active contract is resolved to the host of the contract using the
+// lookup function
+func resolvePinnedHosts(ids []string, lookup func(storage.ContractID) (enode.ID, bool)) ([]enode.ID, error) {
+	hosts := make([]enode.ID, 0, len(ids))
+	for _, id := range ids {
+		b, err := hex.DecodeString(strings.TrimPrefix(id, "0x"))
+		if err != nil || len(b) != len(enode.ID{}) {
+			return nil, fmt.Errorf("the id %v is neither a valid host id nor a contract id", id)
+		}
+		var contractID storage.ContractID
+		copy(contractID[:], b)
+		if host, exist := lookup(contractID); exist {
+			hosts = append(hosts, host)
+			continue
+		}
+		var host enode.ID
+		copy(host[:], b)
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
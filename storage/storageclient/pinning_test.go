@@ -0,0 +1,36 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestResolvePinnedHosts(t *testing.T) {
+	contractID := storage.ContractID(common.HexToHash("0x1"))
+	contractHost := enode.ID{2}
+	lookup := func(id storage.ContractID) (enode.ID, bool) {
+		return contractHost, id == contractID
+	}
+
+	host := enode.ID{3}
+	hosts, err := resolvePinnedHosts([]string{contractID.String(), host.String()}, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 || hosts[0] != contractHost || hosts[1] != host {
+		t.Errorf("resolved hosts not expected. Got %v", hosts)
+	}
+
+	for _, invalid := range []string{"not hex", "0x0102"} {
+		if _, err := resolvePinnedHosts([]string{invalid}, lookup); err == nil {
+			t.Errorf("invalid id %v should return error", invalid)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/DxChainNetwork/godx/accounts"
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/common/hexutil"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/storagehostmanager"
+)
+
+// profileMetadata contains the header and version of the exported client profile file
+var profileMetadata = common.Metadata{
+	Header:  ProfileHeader,
+	Version: ProfileVersion,
+}
+
+// ClientProfile is the signed document of the complete storage client configuration,
+// including the rent payment, the ip violation policy, the bandwidth limits, and the host
+// filter list. The keys and the payment address are not included, so that a fleet of
+// nodes can be provisioned consistently from a single profile
+type ClientProfile struct {
+	CreatedAt     time.Time             `json:"createdAt"`
+	Setting       storage.ClientSetting `json:"setting"`
+	FilterMode    string                `json:"filterMode"`
+	FilteredHosts []enode.ID            `json:"filteredHosts"`
+	Signer        common.Address        `json:"signer"`
+	Signature     hexutil.Bytes         `json:"signature"`
+}
+
+// ProfileDrift is a field of the local storage client configuration that differs from
+// the client profile
+type ProfileDrift struct {
+	Field   string `json:"field"`
+	Local   string `json:"local"`
+	Profile string `json:"profile"`
+}
+
+// Hash returns the hash of the client profile signed by the signer. The signature itself is excluded
+func (p ClientProfile) Hash() (common.Hash, error) {
+	p.Signature = nil
+	blob, err := json.Marshal(p)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(blob), nil
+}
+
+// Verify checks whether the client profile is signed by the trusted signer
+func (p ClientProfile) Verify(trusted common.Address) error {
+	if p.Signer != trusted {
+		return fmt.Errorf("profile signer %v is not trusted", p.Signer.String())
+	}
+	hash, err := p.Hash()
+	if err != nil {
+		return err
+	}
+	pk, err := crypto.SigToPub(hash.Bytes(), p.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to recover the profile signer: %s", err.Error())
+	}
+	if crypto.PubkeyToAddress(*pk) != p.Signer {
+		return errors.New("profile signature does not match the signer")
+	}
+	return nil
+}
+
+// Drift compares the client profile with the other one, and returns the fields that differ.
+// The creation time and the signature are not compared
+func (p ClientProfile) Drift(other ClientProfile) (drifts []ProfileDrift) {
+	compare := func(field string, local, profile interface{}) {
+		if !reflect.DeepEqual(local, profile) {
+			drifts = append(drifts, ProfileDrift{
+				Field:   field,
+				Local:   fmt.Sprintf("%v", local),
+				Profile: fmt.Sprintf("%v", profile),
+			})
+		}
+	}
+
+	rent, otherRent := p.Setting.RentPayment, other.Setting.RentPayment
+	compare("fund", rent.Fund.String(), otherRent.Fund.String())
+	compare("storagehosts", rent.StorageHosts, otherRent.StorageHosts)
+	compare("period", rent.Period, otherRent.Period)
+	compare("renewwindow", rent.RenewWindow, otherRent.RenewWindow)
+	compare("expectedstorage", rent.ExpectedStorage, otherRent.ExpectedStorage)
+	compare("expectedupload", rent.ExpectedUpload, otherRent.ExpectedUpload)
+	compare("expecteddownload", rent.ExpectedDownload, otherRent.ExpectedDownload)
+	compare("expectedredundancy", rent.ExpectedRedundancy, otherRent.ExpectedRedundancy)
+	compare("enableipviolation", p.Setting.EnableIPViolation, other.Setting.EnableIPViolation)
+	compare("maxuploadspeed", p.Setting.MaxUploadSpeed, other.Setting.MaxUploadSpeed)
+	compare("maxdownloadspeed", p.Setting.MaxDownloadSpeed, other.Setting.MaxDownloadSpeed)
+	compare("filtermode", p.FilterMode, other.FilterMode)
+	compare("filteredhosts", fmt.Sprint(p.FilteredHosts), fmt.Sprint(other.FilteredHosts))
+	return
+}
+
+// CreateProfile creates the client profile from the current storage client configuration,
+// signed by the payment address of the storage client
+func (client *StorageClient) CreateProfile() (profile ClientProfile, err error) {
+	signer, err := client.GetPaymentAddress()
+	if err != nil {
+		return
+	}
+
+	profile = client.localProfile()
+	profile.CreatedAt = time.Now()
+	profile.Signer = signer
+	hash, err := profile.Hash()
+	if err != nil {
+		return
+	}
+
+	account := accounts.Account{Address: signer}
+	wallet, err := client.ethBackend.AccountManager().Find(account)
+	if err != nil {
+		return
+	}
+	profile.Signature, err = wallet.SignHash(account, hash.Bytes())
+	return
+}
+
+// ExportProfile exports the signed client profile to the file
+func (client *StorageClient) ExportProfile(path string) error {
+	profile, err := client.CreateProfile()
+	if err != nil {
+		return err
+	}
+	return common.SaveDxJSON(profileMetadata, path, profile)
+}
+
+// ImportProfileFile loads the client profile from the file, and applies it if signed by
+// the trusted signer
+func (client *StorageClient) ImportProfileFile(path string, trusted common.Address) error {
+	var profile ClientProfile
+	if err := common.LoadDxJSON(profileMetadata, path, &profile); err != nil {
+		return err
+	}
+	return client.ImportProfile(profile, trusted)
+}
+
+// ImportProfile verifies the client profile, and applies the client setting and the host
+// filter list in the profile to the storage client
+func (client *StorageClient) ImportProfile(profile ClientProfile, trusted common.Address) error {
+	if err := profile.Verify(trusted); err != nil {
+		return err
+	}
+	fm, err := storagehostmanager.ToFilterMode(profile.FilterMode)
+	if err != nil {
+		return err
+	}
+	if err := client.SetClientSetting(profile.Setting); err != nil {
+		return fmt.Errorf("failed to set the client settings: %s", err.Error())
+	}
+	if err := client.storageHostManager.SetFilterMode(fm, profile.FilteredHosts); err != nil {
+		return fmt.Errorf("failed to set the host filter mode: %s", err.Error())
+	}
+	return nil
+}
+
+// ProfileDriftFile loads the client profile from the file, and returns the fields of the
+// local configuration that drift from the profile. The profile must be signed by the
+// trusted signer
+func (client *StorageClient) ProfileDriftFile(path string, trusted common.Address) ([]ProfileDrift, error) {
+	var profile ClientProfile
+	if err := common.LoadDxJSON(profileMetadata, path, &profile); err != nil {
+		return nil, err
+	}
+	if err := profile.Verify(trusted); err != nil {
+		return nil, err
+	}
+	return client.localProfile().Drift(profile), nil
+}
+
+// localProfile returns the unsigned client profile of the current storage client configuration
+func (client *StorageClient) localProfile() ClientProfile {
+	fm, hosts := client.storageHostManager.RetrieveFilteredHosts()
+	return ClientProfile{
+		Setting:       client.RetrieveClientSetting(),
+		FilterMode:    fm.String(),
+		FilteredHosts: hosts,
+	}
+}
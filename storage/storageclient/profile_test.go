@@ -0,0 +1,95 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// signedProfile returns the client profile signed by a newly generated key
+func signedProfile(t *testing.T) ClientProfile {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile := ClientProfile{
+		CreatedAt: time.Now(),
+		Setting: storage.ClientSetting{
+			RentPayment: storage.RentPayment{
+				Fund:         common.NewBigIntUint64(1000),
+				StorageHosts: 3,
+				Period:       100,
+				RenewWindow:  10,
+			},
+			EnableIPViolation: true,
+			MaxUploadSpeed:    100,
+		},
+		FilterMode:    "Whitelist",
+		FilteredHosts: []enode.ID{{0x01}, {0x02}},
+		Signer:        crypto.PubkeyToAddress(key.PublicKey),
+	}
+	hash, err := profile.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Signature, err = crypto.Sign(hash.Bytes(), key); err != nil {
+		t.Fatal(err)
+	}
+	return profile
+}
+
+func TestClientProfile_Verify(t *testing.T) {
+	profile := signedProfile(t)
+	if err := profile.Verify(profile.Signer); err != nil {
+		t.Fatalf("failed to verify the profile: %v", err)
+	}
+
+	// untrusted signer
+	untrusted := signedProfile(t)
+	if err := profile.Verify(untrusted.Signer); err == nil {
+		t.Fatal("profile signed by untrusted signer should not pass the verification")
+	}
+
+	// tampered profile
+	profile.Setting.MaxDownloadSpeed = 1
+	if err := profile.Verify(profile.Signer); err == nil {
+		t.Fatal("tampered profile should not pass the verification")
+	}
+}
+
+func TestClientProfile_Drift(t *testing.T) {
+	profile := signedProfile(t)
+	local := profile
+	local.Signature = nil
+	if drifts := local.Drift(profile); len(drifts) != 0 {
+		t.Fatalf("no drift expected, got %v", drifts)
+	}
+
+	local.Setting.RentPayment.Fund = common.NewBigIntUint64(2000)
+	local.Setting.MaxUploadSpeed = 0
+	local.FilteredHosts = []enode.ID{{0x01}}
+	drifts := local.Drift(profile)
+	expected := []string{"fund", "maxuploadspeed", "filteredhosts"}
+	if len(drifts) != len(expected) {
+		t.Fatalf("drift size not expected. Expect %v, Got %v", len(expected), drifts)
+	}
+	for i, drift := range drifts {
+		if drift.Field != expected[i] {
+			t.Errorf("drift field not expected. Expect %v, Got %v", expected[i], drift.Field)
+		}
+	}
+
+	// empty and nil host lists are not considered as drift
+	local, other := ClientProfile{FilteredHosts: []enode.ID{}}, ClientProfile{}
+	if drifts := local.Drift(other); len(drifts) != 0 {
+		t.Fatalf("no drift expected between empty host lists, got %v", drifts)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DxChainNetwork/godx/accounts"
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// The public file is uploaded unencrypted, and each sector uploaded is published to the storage
+// host negotiated the public read feature. The published sectors are served by the storage host
+// to anyone without a storage contract or payment, and the download of the public file skips the
+// payment revision as well as the decryption.
+
+// publishSectors publishes the sectors stored in the contract with the storage host, signed by
+// the storage client of the contract
+func (client *StorageClient) publishSectors(sp storage.Peer, roots []common.Hash, hostInfo *storage.HostInfo) error {
+	if !storage.HasFeature(client.sessionFeatures(hostInfo), storage.FeaturePublicRead) {
+		return storage.ErrFeatureNotNegotiated
+	}
+
+	// find the contract formed with the host, whose client address signs the request
+	scs := client.contractManager.GetStorageContractSet()
+	contractID := scs.GetContractIDByHostID(hostInfo.EnodeID)
+	contract, exist := scs.Acquire(contractID)
+	if !exist {
+		return fmt.Errorf("not exist this contract: %s", contractID.String())
+	}
+	lastRevision := contract.Header().LatestContractRevision
+	scs.Return(contract)
+
+	req := storage.PublishSectorsRequest{
+		StorageContractID: common.Hash(contractID),
+		Roots:             roots,
+	}
+	am := client.ethBackend.AccountManager()
+	account := accounts.Account{Address: lastRevision.NewValidProofOutputs[0].Address}
+	wallet, err := am.Find(account)
+	if err != nil {
+		return err
+	}
+	if req.Signature, err = wallet.SignHash(account, req.SigHash().Bytes()); err != nil {
+		return err
+	}
+
+	if err := sp.RequestPublishSectors(req); err != nil {
+		return err
+	}
+	msg, err := sp.ClientWaitContractResp()
+	if err != nil {
+		return err
+	}
+	switch msg.Code {
+	case storage.HostBusyHandleReqMsg:
+		return storage.ErrHostBusyHandleReq
+	case storage.HostNegotiateErrorMsg:
+		return errors.New("the storage host failed to publish the sectors")
+	case storage.HostAckMsg:
+		return nil
+	default:
+		return fmt.Errorf("unexpected message code %v for publishing the sectors", msg.Code)
+	}
+}
+
+// PublicDownload requests for a section of the public sector and returns the requested data. A
+// Merkle proof is always requested. No payment revision is negotiated with the storage host
+func (client *StorageClient) PublicDownload(sp storage.Peer, root common.Hash, offset, length uint32, cancel <-chan struct{}, hostInfo *storage.HostInfo) ([]byte, error) {
+	if !storage.HasFeature(client.sessionFeatures(hostInfo), storage.FeaturePublicRead) {
+		return nil, storage.ErrFeatureNotNegotiated
+	}
+	if uint64(offset)+uint64(length) > storage.SectorSize {
+		return nil, errors.New("download out boundary of sector")
+	}
+	if offset%merkle.LeafSize != 0 || length%merkle.LeafSize != 0 {
+		return nil, errors.New("offset and length must be multiples of SegmentSize when requesting a Merkle proof")
+	}
+
+	// stop before the request is sent if the download is cancelled
+	select {
+	case <-cancel:
+		return nil, errOperationCancelled
+	default:
+	}
+
+	// wait for the download speed limit shared by all workers
+	if err := client.bandwidth.waitDownload(hostInfo.EnodeID, uint64(length), cancel); err != nil {
+		return nil, err
+	}
+
+	req := storage.PublicDownloadRequest{
+		Sector: storage.DownloadRequestSector{
+			MerkleRoot: root,
+			Offset:     offset,
+			Length:     length,
+		},
+		MerkleProof: true,
+	}
+	if err := sp.RequestPublicDownload(req); err != nil {
+		return nil, err
+	}
+	msg, err := sp.ClientWaitContractResp()
+	if err != nil {
+		return nil, err
+	}
+	switch msg.Code {
+	case storage.HostBusyHandleReqMsg:
+		return nil, storage.ErrHostBusyHandleReq
+	case storage.HostNegotiateErrorMsg:
+		return nil, storage.ErrHostNegotiate
+	case storage.PublicDownloadRespMsg:
+	default:
+		return nil, fmt.Errorf("unexpected message code %v for the public download", msg.Code)
+	}
+
+	var resp storage.DownloadResponse
+	if err := msg.Decode(&resp); err != nil {
+		return nil, err
+	}
+
+	// the data is verified against the merkle root since nothing is signed by the host
+	proofStart := int(offset) / merkle.LeafSize
+	proofEnd := int(offset+length) / merkle.LeafSize
+	if len(resp.Data) != int(length) {
+		err = errors.New("host did not send enough sector data")
+	} else if verified, verifyErr := merkle.Sha256VerifyRangeProof(resp.Data, resp.MerkleProof, proofStart, proofEnd, root); !verified || verifyErr != nil {
+		err = errors.New("host provided incorrect sector data or Merkle proof")
+	}
+	if err != nil {
+		client.storageHostManager.IncrementFailedInteractions(hostInfo.EnodeID)
+		return nil, err
+	}
+	client.storageHostManager.IncrementSuccessfulInteractions(hostInfo.EnodeID)
+	return resp.Data, nil
+}
+
+// ReadPublicSector reads a section of the public sector from the storage host, no storage
+// contract with the host is needed
+func (client *StorageClient) ReadPublicSector(ctx context.Context, hostID enode.ID, root common.Hash, offset, length uint32) ([]byte, error) {
+	if err := client.tm.Add(); err != nil {
+		return nil, err
+	}
+	defer client.tm.Done()
+
+	hostInfo, exist := client.storageHostManager.RetrieveHostInfo(hostID)
+	if !exist {
+		return nil, errors.New("the storage host cannot be found")
+	}
+	if !storage.HasFeature(client.sessionFeatures(&hostInfo), storage.FeaturePublicRead) {
+		return nil, fmt.Errorf("the storage host does not serve the public reads: %v", storage.ErrFeatureNotNegotiated)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sp, err := client.SetupConnection(hostInfo.EnodeURL)
+	if err != nil {
+		return nil, err
+	}
+	if !sp.TryToRenewOrRevise() {
+		return nil, errors.New("the connection is currently renewing or revising")
+	}
+	defer sp.RevisionOrRenewingDone()
+
+	return client.PublicDownload(sp, root, offset, length, ctx.Done(), &hostInfo)
+}
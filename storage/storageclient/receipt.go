@@ -0,0 +1,257 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DxChainNetwork/godx/accounts"
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/common/hexutil"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
+)
+
+// receiptMetadata contains the header and version of the upload receipt file
+var receiptMetadata = common.Metadata{
+	Header:  ReceiptHeader,
+	Version: ReceiptVersion,
+}
+
+type (
+	// UploadReceipt is the signed manifest of the file uploaded, recording what was stored
+	// and where. The file root is the merkle root of the segment roots, and each segment
+	// root is the merkle root of the sector roots of the segment
+	UploadReceipt struct {
+		DxPath      string               `json:"dxpath"`
+		FileSize    uint64               `json:"filesize"`
+		FileRoot    common.Hash          `json:"fileroot"`
+		Segments    []SegmentReceipt     `json:"segments"`
+		Contracts   []storage.ContractID `json:"contracts"`
+		UploadStart time.Time            `json:"uploadstart"`
+		UploadEnd   time.Time            `json:"uploadend"`
+		CreatedAt   time.Time            `json:"createdAt"`
+		Signer      common.Address       `json:"signer"`
+		Signature   hexutil.Bytes        `json:"signature"`
+	}
+
+	// SegmentReceipt is the record of an uploaded segment in the upload receipt. The sector
+	// root not uploaded yet is the empty hash
+	SegmentReceipt struct {
+		Index       uint64          `json:"index"`
+		Root        common.Hash     `json:"root"`
+		SectorRoots []common.Hash   `json:"sectorroots"`
+		Sectors     []SectorReceipt `json:"sectors"`
+	}
+
+	// SectorReceipt is the record of a sector stored in a storage host
+	SectorReceipt struct {
+		Index      uint64             `json:"index"`
+		Root       common.Hash        `json:"root"`
+		HostID     enode.ID           `json:"hostid"`
+		ContractID storage.ContractID `json:"contractid"`
+	}
+)
+
+// Hash returns the hash of the upload receipt signed by the signer. The signature itself is excluded
+func (r UploadReceipt) Hash() (common.Hash, error) {
+	r.Signature = nil
+	blob, err := json.Marshal(r)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(blob), nil
+}
+
+// Verify checks whether the upload receipt is consistent and signed by the signer
+func (r UploadReceipt) Verify() error {
+	segmentRoots := make([]common.Hash, 0, len(r.Segments))
+	for _, segment := range r.Segments {
+		for _, sector := range segment.Sectors {
+			if sector.Index >= uint64(len(segment.SectorRoots)) || segment.SectorRoots[sector.Index] != sector.Root {
+				return fmt.Errorf("segment %v sector %v root does not match", segment.Index, sector.Index)
+			}
+		}
+		if receiptRoot(segment.SectorRoots) != segment.Root {
+			return fmt.Errorf("segment %v root does not match its sectors", segment.Index)
+		}
+		segmentRoots = append(segmentRoots, segment.Root)
+	}
+	if receiptRoot(segmentRoots) != r.FileRoot {
+		return errors.New("file root does not match the segments")
+	}
+
+	hash, err := r.Hash()
+	if err != nil {
+		return err
+	}
+	pk, err := crypto.SigToPub(hash.Bytes(), r.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to recover the receipt signer: %s", err.Error())
+	}
+	if crypto.PubkeyToAddress(*pk) != r.Signer {
+		return errors.New("receipt signature does not match the signer")
+	}
+	return nil
+}
+
+// receiptRoot returns the merkle root of the roots
+func receiptRoot(roots []common.Hash) common.Hash {
+	tree := merkle.NewTree(sha256.New())
+	for _, root := range roots {
+		tree.PushLeaf(root.Bytes())
+	}
+	return common.BytesToHash(tree.Root())
+}
+
+// receiptPath returns the path of the upload receipt stored alongside the dxfile
+func receiptPath(dxFilePath string) string {
+	return strings.TrimSuffix(dxFilePath, storage.DxFileExt) + receiptExt
+}
+
+// issueUploadReceipt creates the upload receipt of the file, signed by the payment address,
+// and stores it alongside the dxfile
+func (client *StorageClient) issueUploadReceipt(dxPath storage.DxPath, status OperationStatus) (receipt UploadReceipt, err error) {
+	if err = client.tm.Add(); err != nil {
+		return
+	}
+	defer client.tm.Done()
+
+	entry, err := client.fileSystem.OpenDxFile(dxPath)
+	if err != nil {
+		return
+	}
+	defer entry.Close()
+	snap, err := entry.Snapshot()
+	if err != nil {
+		return
+	}
+	if receipt, err = client.newUploadReceipt(snap); err != nil {
+		return
+	}
+	receipt.UploadStart, receipt.UploadEnd = status.StartTime, status.EndTime
+	if err = client.signUploadReceipt(&receipt); err != nil {
+		return
+	}
+	err = common.SaveDxJSON(receiptMetadata, receiptPath(entry.FilePath()), receipt)
+	return
+}
+
+// newUploadReceipt creates the unsigned upload receipt from the snapshot of the file
+func (client *StorageClient) newUploadReceipt(snap *dxfile.Snapshot) (receipt UploadReceipt, err error) {
+	receipt = UploadReceipt{
+		DxPath:   snap.DxPath().Path,
+		FileSize: snap.FileSize(),
+	}
+	scs := client.contractManager.GetStorageContractSet()
+	contracts := make(map[storage.ContractID]struct{})
+	segmentRoots := make([]common.Hash, 0, snap.NumSegments())
+	for i := uint64(0); i < snap.NumSegments(); i++ {
+		sectors, err := snap.Sectors(i)
+		if err != nil {
+			return UploadReceipt{}, err
+		}
+		segment := SegmentReceipt{Index: i, SectorRoots: make([]common.Hash, len(sectors))}
+		for sectorIndex, sectorSet := range sectors {
+			for _, sector := range sectorSet {
+				contractID := scs.GetContractIDByHostID(sector.HostID)
+				segment.Sectors = append(segment.Sectors, SectorReceipt{
+					Index:      uint64(sectorIndex),
+					Root:       sector.MerkleRoot,
+					HostID:     sector.HostID,
+					ContractID: contractID,
+				})
+				segment.SectorRoots[sectorIndex] = sector.MerkleRoot
+				if contractID != (storage.ContractID{}) {
+					contracts[contractID] = struct{}{}
+				}
+			}
+		}
+		segment.Root = receiptRoot(segment.SectorRoots)
+		segmentRoots = append(segmentRoots, segment.Root)
+		receipt.Segments = append(receipt.Segments, segment)
+	}
+	receipt.FileRoot = receiptRoot(segmentRoots)
+	for id := range contracts {
+		receipt.Contracts = append(receipt.Contracts, id)
+	}
+	return
+}
+
+// signUploadReceipt signs the upload receipt with the payment address
+func (client *StorageClient) signUploadReceipt(receipt *UploadReceipt) error {
+	signer, err := client.GetPaymentAddress()
+	if err != nil {
+		return err
+	}
+	receipt.CreatedAt = time.Now()
+	receipt.Signer = signer
+	hash, err := receipt.Hash()
+	if err != nil {
+		return err
+	}
+
+	account := accounts.Account{Address: signer}
+	wallet, err := client.ethBackend.AccountManager().Find(account)
+	if err != nil {
+		return err
+	}
+	receipt.Signature, err = wallet.SignHash(account, hash.Bytes())
+	return err
+}
+
+// UploadReceipt returns the upload receipt of the file
+func (client *StorageClient) UploadReceipt(dxPath storage.DxPath) (receipt UploadReceipt, err error) {
+	entry, err := client.fileSystem.OpenDxFile(dxPath)
+	if err != nil {
+		return
+	}
+	path := receiptPath(entry.FilePath())
+	entry.Close()
+
+	if _, err = os.Stat(path); os.IsNotExist(err) {
+		return UploadReceipt{}, fmt.Errorf("no upload receipt for %v, the upload may be still in progress", dxPath.Path)
+	}
+	err = common.LoadDxJSON(receiptMetadata, path, &receipt)
+	return
+}
+
+// ExportUploadReceipt exports the upload receipt of the file to the path
+func (client *StorageClient) ExportUploadReceipt(dxPath storage.DxPath, path string) error {
+	receipt, err := client.UploadReceipt(dxPath)
+	if err != nil {
+		return err
+	}
+	return common.SaveDxJSON(receiptMetadata, path, receipt)
+}
+
+// VerifyUploadReceiptFile loads the upload receipt from the file and verifies it
+func VerifyUploadReceiptFile(path string) (receipt UploadReceipt, err error) {
+	if err = common.LoadDxJSON(receiptMetadata, path, &receipt); err != nil {
+		return
+	}
+	err = receipt.Verify()
+	return
+}
+
+// removeUploadReceipt removes the upload receipt stored alongside the dxfile if exists
+func removeUploadReceipt(dxFilePath string) error {
+	path := receiptPath(dxFilePath)
+	for _, p := range []string{path, path + "_temp"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// signedReceipt returns the upload receipt of two segments signed by a newly generated key
+func signedReceipt(t *testing.T) UploadReceipt {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := UploadReceipt{
+		DxPath:      "file",
+		FileSize:    100,
+		Contracts:   []storage.ContractID{{0x01}, {0x02}},
+		UploadStart: time.Now().Add(-time.Minute),
+		UploadEnd:   time.Now(),
+		CreatedAt:   time.Now(),
+		Signer:      crypto.PubkeyToAddress(key.PublicKey),
+	}
+	var segmentRoots []common.Hash
+	for i := uint64(0); i != 2; i++ {
+		segment := SegmentReceipt{
+			Index:       i,
+			SectorRoots: []common.Hash{{byte(i), 0x01}, {byte(i), 0x02}, {}},
+		}
+		for sectorIndex, root := range segment.SectorRoots[:2] {
+			segment.Sectors = append(segment.Sectors, SectorReceipt{
+				Index:      uint64(sectorIndex),
+				Root:       root,
+				HostID:     enode.ID{byte(sectorIndex)},
+				ContractID: receipt.Contracts[sectorIndex],
+			})
+		}
+		segment.Root = receiptRoot(segment.SectorRoots)
+		segmentRoots = append(segmentRoots, segment.Root)
+		receipt.Segments = append(receipt.Segments, segment)
+	}
+	receipt.FileRoot = receiptRoot(segmentRoots)
+	hash, err := receipt.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Signature, err = crypto.Sign(hash.Bytes(), key); err != nil {
+		t.Fatal(err)
+	}
+	return receipt
+}
+
+func TestUploadReceipt_Verify(t *testing.T) {
+	if err := signedReceipt(t).Verify(); err != nil {
+		t.Fatalf("the signed receipt should be valid: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *UploadReceipt)
+	}{
+		{"sector root", func(r *UploadReceipt) { r.Segments[1].Sectors[0].Root = common.Hash{0xff} }},
+		{"segment root", func(r *UploadReceipt) { r.Segments[0].SectorRoots[2] = common.Hash{0xff} }},
+		{"file root", func(r *UploadReceipt) { r.FileRoot = common.Hash{0xff} }},
+		{"file size", func(r *UploadReceipt) { r.FileSize++ }},
+		{"signer", func(r *UploadReceipt) { r.Signer = common.Address{0xff} }},
+	}
+	for _, test := range tests {
+		receipt := signedReceipt(t)
+		test.modify(&receipt)
+		if err := receipt.Verify(); err == nil {
+			t.Errorf("receipt with the %v modified should be invalid", test.name)
+		}
+	}
+}
+
+func TestReceiptPath(t *testing.T) {
+	if path := receiptPath("/dx/files/movie" + storage.DxFileExt); path != "/dx/files/movie"+receiptExt {
+		t.Errorf("receipt path not expected, got %v", path)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
+)
+
+var errReEncodeUploading = errors.New("the file is being uploaded or repaired, change the redundancy after it is finished")
+
+// SetFileRedundancy changes the number of total sectors of the uploaded file. The min sectors
+// of the file cannot be changed, since it decides how the data is split into the sectors. If
+// the redundancy is raised, the segments of the file are pushed to the repair loop, which
+// uploads the sectors added as the hosts allow. If the redundancy is lowered, the sectors
+// beyond the number of total sectors are dropped from the file, and the hosts no longer
+// holding any sector of the file are released from it. The storage of the dropped sectors
+// on the hosts is still paid until the contracts end, since the sectors can only be appended
+// to the contracts
+func (client *StorageClient) SetFileRedundancy(dxPath storage.DxPath, numSectors uint32) error {
+	if err := client.tm.Add(); err != nil {
+		return err
+	}
+	defer client.tm.Done()
+
+	entry, err := client.fileSystem.OpenDxFile(dxPath)
+	if err != nil {
+		return err
+	}
+	defer entry.Close()
+
+	prev, err := entry.ErasureCode()
+	if err != nil {
+		return err
+	}
+	if numSectors < prev.MinSectors() {
+		return fmt.Errorf("the number of total sectors %v is smaller than the min sectors %v", numSectors, prev.MinSectors())
+	}
+	if numSectors > prev.NumSectors() {
+		numContracts := uint64(len(client.contractManager.GetStorageContractSet().Contracts()))
+		requiredContracts := math.Ceil(float64(numSectors+prev.MinSectors()) / 2)
+		if numContracts < uint64(requiredContracts) {
+			return fmt.Errorf("not enough contracts to raise the redundancy: got %v, needed %v", numContracts, requiredContracts)
+		}
+	}
+
+	// the segments being uploaded hold the sector slots of the erasure code in use. The upload
+	// heap is locked during the re-encoding, so that no segment of the file is pushed meanwhile
+	client.uploadHeap.mu.Lock()
+	if client.operations.runningUpload(dxPath.Path) != nil || client.uploadHeap.hasFile(entry.UID()) {
+		client.uploadHeap.mu.Unlock()
+		return errReEncodeUploading
+	}
+	dropped, err := entry.ReEncode(numSectors)
+	client.uploadHeap.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("could not re-encode the dx file, error: %v", err)
+	}
+	client.log.Info("changed the file redundancy", "dxpath", dxPath.Path, "from", prev.NumSectors(), "to", numSectors,
+		"dropped", len(dropped))
+	go client.fileSystem.InitAndUpdateDirMetadata(dxPath)
+
+	if numSectors <= prev.NumSectors() {
+		return nil
+	}
+	hosts := client.refreshHostsAndWorkers()
+	if err := client.createAndPushSegments([]*dxfile.FileSetEntryWithID{entry}, hosts, targetUnstuckSegments, make(storage.HostHealthInfoTable)); err != nil {
+		return err
+	}
+	select {
+	case client.uploadHeap.segmentComing <- struct{}{}:
+	default:
+	}
+	return nil
+}
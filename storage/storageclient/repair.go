@@ -91,6 +91,11 @@ func (client *StorageClient) stuckLoop() {
 			return
 		}
 
+		// Wait until the triage recovers the at-risk files
+		if !client.waitTriage() {
+			return
+		}
+
 		// Randomly get directory with stuck files
 		dir, err := client.fileSystem.RandomStuckDirectory()
 		if err != nil && err != filesystem.ErrNoRepairNeeded {
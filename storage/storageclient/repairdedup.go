@@ -0,0 +1,107 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/metrics"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
+)
+
+var (
+	repairDownloadMeter       = metrics.NewRegisteredMeter("storage/client/repair/download", nil)
+	repairDownloadSharedMeter = metrics.NewRegisteredMeter("storage/client/repair/download/shared", nil)
+)
+
+// errRepairDownloadInterrupted is the error that the storage client is stopped while waiting
+// for the repair download shared with another segment
+var errRepairDownloadInterrupted = errors.New("shared repair download interrupted by stop call")
+
+// repairDownloads deduplicates the downloads fetching the logical segment data for repair.
+// The segments of the same remote data repaired at the same time share a single download,
+// which is issued by the first segment and waited on by the others
+type repairDownloads struct {
+	inflight map[common.Hash]*repairDownload
+	lock     sync.Mutex
+}
+
+// repairDownload is a repair download in flight. The data is read only once shared, as the
+// segments waiting on the download take the same buffers as their logical data
+type repairDownload struct {
+	done    chan struct{}
+	data    [][]byte
+	err     error
+	waiters int
+}
+
+// newRepairDownloads creates a new repairDownloads object
+func newRepairDownloads() *repairDownloads {
+	return &repairDownloads{
+		inflight: make(map[common.Hash]*repairDownload),
+	}
+}
+
+// do returns the data of the repair download of the key. If the same download is in flight,
+// the result of it is waited for and shared, otherwise the data is fetched. The shared flag
+// tells whether the data was fetched by another segment
+func (rd *repairDownloads) do(key common.Hash, stopChan <-chan struct{}, fetch func() ([][]byte, error)) (data [][]byte, shared bool, err error) {
+	rd.lock.Lock()
+	if d, exists := rd.inflight[key]; exists {
+		d.waiters++
+		rd.lock.Unlock()
+		repairDownloadSharedMeter.Mark(1)
+		select {
+		case <-d.done:
+			return d.data, true, d.err
+		case <-stopChan:
+			return nil, true, errRepairDownloadInterrupted
+		}
+	}
+	d := &repairDownload{done: make(chan struct{})}
+	rd.inflight[key] = d
+	rd.lock.Unlock()
+
+	repairDownloadMeter.Mark(1)
+	d.data, d.err = fetch()
+
+	// the download is removed before waking up the waiters, so that the segments repaired
+	// later fetch the latest data from the storage hosts
+	rd.lock.Lock()
+	delete(rd.inflight, key)
+	rd.lock.Unlock()
+	close(d.done)
+	return d.data, false, d.err
+}
+
+// repairDownloadKey identifies the remote data of the segment downloaded for repair, by the
+// cipher key, the merkle roots of the sectors and the length of the download. The sector not
+// uploaded to any storage host takes the empty root
+func repairDownloadKey(snap *dxfile.Snapshot, segmentIndex uint64, length uint64) (common.Hash, error) {
+	sectors, err := snap.Sectors(segmentIndex)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	key := snap.CipherKey()
+
+	blob := make([]byte, 0, 8+len(key.CodeName())+len(key.Key())+len(sectors)*common.HashLength)
+	blob = append(blob, []byte(key.CodeName())...)
+	blob = append(blob, key.Key()...)
+	for _, hostSectors := range sectors {
+		var root common.Hash
+		if len(hostSectors) != 0 {
+			root = hostSectors[0].MerkleRoot
+		}
+		blob = append(blob, root.Bytes()...)
+	}
+	var lengthBytes [8]byte
+	binary.BigEndian.PutUint64(lengthBytes[:], length)
+	blob = append(blob, lengthBytes[:]...)
+	return crypto.Keccak256Hash(blob), nil
+}
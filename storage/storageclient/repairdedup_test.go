@@ -0,0 +1,95 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+)
+
+func TestRepairDownloads_Shared(t *testing.T) {
+	rd := newRepairDownloads()
+	stop := make(chan struct{})
+	key := common.HexToHash("0x01")
+
+	var fetches int32
+	started, release := make(chan struct{}), make(chan struct{})
+	fetch := func() ([][]byte, error) {
+		atomic.AddInt32(&fetches, 1)
+		close(started)
+		<-release
+		return [][]byte{[]byte("segment data")}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]bool, 4)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		data, shared, err := rd.do(key, stop, fetch)
+		if err != nil || string(data[0]) != "segment data" {
+			t.Errorf("unexpected result %s, %v", data, err)
+		}
+		results[0] = shared
+	}()
+	<-started
+
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data, shared, err := rd.do(key, stop, fetch)
+			if err != nil || string(data[0]) != "segment data" {
+				t.Errorf("unexpected result %s, %v", data, err)
+			}
+			results[i] = shared
+		}(i)
+	}
+	// wait for the other segments waiting on the download
+	for waiters := 0; waiters < len(results)-1; runtime.Gosched() {
+		rd.lock.Lock()
+		waiters = rd.inflight[key].waiters
+		rd.lock.Unlock()
+	}
+	close(release)
+	wg.Wait()
+
+	if fetches != 1 {
+		t.Errorf("expected the download fetched once, got %v", fetches)
+	}
+	if results[0] {
+		t.Error("the first segment should fetch the data itself")
+	}
+	for i := 1; i < len(results); i++ {
+		if !results[i] {
+			t.Errorf("segment %v should share the download", i)
+		}
+	}
+	if len(rd.inflight) != 0 {
+		t.Error("the finished download should be removed")
+	}
+}
+
+func TestRepairDownloads_Error(t *testing.T) {
+	rd := newRepairDownloads()
+	stop := make(chan struct{})
+	key := common.HexToHash("0x01")
+
+	errFetch := errors.New("fetch failed")
+	if _, _, err := rd.do(key, stop, func() ([][]byte, error) { return nil, errFetch }); err != errFetch {
+		t.Fatalf("expected error %v, got %v", errFetch, err)
+	}
+
+	// the failed download is not cached, the next repair fetches again
+	data, shared, err := rd.do(key, stop, func() ([][]byte, error) { return [][]byte{{1}}, nil })
+	if err != nil || shared || len(data) != 1 {
+		t.Errorf("unexpected result %v, %v, %v", data, shared, err)
+	}
+}
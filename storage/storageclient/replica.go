@@ -0,0 +1,254 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage/storageclient/storagehostmanager"
+)
+
+var errReplicaDisabled = errors.New("the storage client read-only replica mode is not enabled")
+
+// replicaMetadata contains the header and version of the replica snapshot file
+var replicaMetadata = common.Metadata{
+	Header:  ReplicaSnapshotHeader,
+	Version: ReplicaSnapshotVersion,
+}
+
+// ReplicaSnapshot is the snapshot of the primary storage client read by the read-only
+// replicas. Besides the file metadata copied along with it, it contains the storage hosts
+// the primary has contracts with. The contracts themselves are not shared, the replica
+// downloads through its own contracts with the same storage hosts, so that it never
+// signs revisions conflicting with the primary's
+type ReplicaSnapshot struct {
+	CreatedAt time.Time  `json:"createdAt"`
+	Hosts     []enode.ID `json:"hosts"`
+}
+
+// ReplicaStatus is the sync status of the read-only replica
+type ReplicaStatus struct {
+	Source       string    `json:"source"`
+	SnapshotTime time.Time `json:"snapshottime"`
+	LastSync     time.Time `json:"lastsync"`
+	Hosts        int       `json:"hosts"`
+	Error        string    `json:"error"`
+}
+
+// replica keeps the state of the read-only replica mode
+type replica struct {
+	dir    string
+	status ReplicaStatus
+	lock   sync.Mutex
+}
+
+// EnableReplica enables the read-only replica mode, where the storage client serves the
+// downloads of the files uploaded by the primary storage client only. The replica snapshot
+// exported by the primary to the directory is synced periodically, and the file system is
+// read-only. It must be called before Start
+func (client *StorageClient) EnableReplica(dir string) error {
+	if client.coordinationBackend != nil {
+		return errors.New("the read-only replica mode cannot be enabled along with the coordination mode")
+	}
+	client.replica = &replica{
+		dir:    dir,
+		status: ReplicaStatus{Source: dir},
+	}
+	client.fileSystem.SetReadOnly(true)
+	return nil
+}
+
+// ReplicaStatus returns the sync status of the read-only replica
+func (client *StorageClient) ReplicaStatus() (ReplicaStatus, error) {
+	if client.replica == nil {
+		return ReplicaStatus{}, errReplicaDisabled
+	}
+	client.replica.lock.Lock()
+	defer client.replica.lock.Unlock()
+	return client.replica.status, nil
+}
+
+// ExportReplicaSnapshot exports the replica snapshot and the file metadata of the storage
+// client to the directory, which is mounted by the read-only replicas
+func (client *StorageClient) ExportReplicaSnapshot(dir string) error {
+	snapshot := ReplicaSnapshot{CreatedAt: time.Now()}
+	hosts := make(map[enode.ID]struct{})
+	for _, contract := range client.contractManager.RetrieveActiveContracts() {
+		if _, exists := hosts[contract.EnodeID]; !exists {
+			hosts[contract.EnodeID] = struct{}{}
+			snapshot.Hosts = append(snapshot.Hosts, contract.EnodeID)
+		}
+	}
+
+	// the file metadata is copied first, so that the snapshot file always refers to the
+	// hosts of the file metadata copied
+	if err := mirrorDir(string(client.fileSystem.RootDir()), filepath.Join(dir, replicaFilesDir)); err != nil {
+		return err
+	}
+	return common.SaveDxJSON(replicaMetadata, filepath.Join(dir, ReplicaSnapshotFilename), snapshot)
+}
+
+// replicaLoop syncs the replica snapshot every ReplicaSyncInterval
+func (client *StorageClient) replicaLoop() {
+	if err := client.tm.Add(); err != nil {
+		return
+	}
+	defer client.tm.Done()
+
+	ticker := time.NewTicker(ReplicaSyncInterval)
+	defer ticker.Stop()
+	for {
+		if err := client.syncReplica(); err != nil {
+			client.log.Warn("failed to sync the replica snapshot", "dir", client.replica.dir, "err", err)
+		}
+		select {
+		case <-client.tm.StopChan():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// syncReplica loads the replica snapshot, restricts the contracts to the storage hosts of
+// the primary, and mirrors the file metadata of the primary into the local file system
+func (client *StorageClient) syncReplica() (err error) {
+	r := client.replica
+	defer func() {
+		r.lock.Lock()
+		r.status.Error = ""
+		if err != nil {
+			r.status.Error = err.Error()
+		}
+		r.lock.Unlock()
+	}()
+
+	var snapshot ReplicaSnapshot
+	if err = common.LoadDxJSON(replicaMetadata, filepath.Join(r.dir, ReplicaSnapshotFilename), &snapshot); err != nil {
+		return
+	}
+
+	if fm, whitelist := client.storageHostManager.RetrieveFilteredHosts(); len(snapshot.Hosts) != 0 &&
+		(fm != storagehostmanager.WhitelistFilter || !sameHosts(whitelist, snapshot.Hosts)) {
+		if err = client.storageHostManager.SetFilterMode(storagehostmanager.WhitelistFilter, snapshot.Hosts); err != nil {
+			return
+		}
+	}
+	if err = mirrorDir(filepath.Join(r.dir, replicaFilesDir), string(client.fileSystem.RootDir())); err != nil {
+		return
+	}
+
+	r.lock.Lock()
+	r.status.SnapshotTime = snapshot.CreatedAt
+	r.status.LastSync = time.Now()
+	r.status.Hosts = len(snapshot.Hosts)
+	r.lock.Unlock()
+	return
+}
+
+// sameHosts checks whether the two lists contain the same storage hosts
+func sameHosts(a, b []enode.ID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[enode.ID]struct{})
+	for _, id := range a {
+		set[id] = struct{}{}
+	}
+	for _, id := range b {
+		if _, exists := set[id]; !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// mirrorDir makes the destination directory the same as the source directory. The files
+// changed are copied to a temporary file and then renamed, so that the readers of the
+// destination directory never see partially written files
+func mirrorDir(src, dst string) error {
+	mirrored := make(map[string]struct{})
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, mirrorTempSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		mirrored[rel] = struct{}{}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+		if existing, err := os.Stat(target); err == nil && existing.Size() == info.Size() && existing.ModTime().Equal(info.ModTime()) {
+			return nil
+		}
+		return copyFile(path, target, info)
+	})
+	if err != nil {
+		return err
+	}
+
+	// remove the files not in the source directory
+	return filepath.Walk(dst, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(dst, path)
+		if err != nil {
+			return err
+		}
+		if _, exists := mirrored[rel]; exists {
+			return nil
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// copyFile copies the file to the target path, keeping the modification time
+func copyFile(src, target string, info os.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := target + mirrorTempSuffix
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	if err = os.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Rename(tmp, target)
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+)
+
+func TestMirrorDir(t *testing.T) {
+	root, err := ioutil.TempDir("", "replica")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	src, dst := filepath.Join(root, "src"), filepath.Join(root, "dst")
+
+	write := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(path, expected string) {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read the mirrored file %v: %v", path, err)
+		}
+		if string(content) != expected {
+			t.Errorf("mirrored file %v content not expected. Expect %v, Got %v", path, expected, string(content))
+		}
+	}
+
+	write(filepath.Join(src, "a.dxfile"), "a")
+	write(filepath.Join(src, "dir", "b.dxfile"), "b")
+	write(filepath.Join(src, "dir", "c.dxfile"+mirrorTempSuffix), "partial")
+	write(filepath.Join(dst, "stale", "d.dxfile"), "d")
+	if err := mirrorDir(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	check(filepath.Join(dst, "a.dxfile"), "a")
+	check(filepath.Join(dst, "dir", "b.dxfile"), "b")
+	for _, path := range []string{filepath.Join(dst, "stale"), filepath.Join(dst, "dir", "c.dxfile"+mirrorTempSuffix)} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%v should not exist in the mirrored directory", path)
+		}
+	}
+
+	// the changed files are mirrored, and the removed files are deleted
+	write(filepath.Join(src, "a.dxfile"), "aa")
+	if err := os.Remove(filepath.Join(src, "dir", "b.dxfile")); err != nil {
+		t.Fatal(err)
+	}
+	if err := mirrorDir(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	check(filepath.Join(dst, "a.dxfile"), "aa")
+	if _, err := os.Stat(filepath.Join(dst, "dir", "b.dxfile")); !os.IsNotExist(err) {
+		t.Errorf("removed file should be deleted from the mirrored directory")
+	}
+}
+
+func TestSameHosts(t *testing.T) {
+	a, b, c := enode.ID{0x01}, enode.ID{0x02}, enode.ID{0x03}
+	if !sameHosts([]enode.ID{a, b}, []enode.ID{b, a}) {
+		t.Errorf("hosts in different order should be the same")
+	}
+	if sameHosts([]enode.ID{a, b}, []enode.ID{a, c}) || sameHosts([]enode.ID{a}, []enode.ID{a, b}) {
+		t.Errorf("different hosts should not be the same")
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage/chrono"
+)
+
+var (
+	// ErrRevisionRateExceeded is returned when the client is asked to sign revisions
+	// with a host faster than the allowed rate
+	ErrRevisionRateExceeded = errors.New("revision signing rate with the host exceeded")
+
+	// ErrHostUploadPaused is returned when the uploads to the host are paused because
+	// of the abnormal revisions detected
+	ErrHostUploadPaused = errors.New("uploads to the host are paused due to abnormal revisions")
+)
+
+// revisionMonitor watches the contract revisions signed by the storage client with each
+// storage host. It limits the revision signing rate, and detects abnormal revision patterns
+// including rapid spend increase, revision number jumps and repeated identical revisions.
+// Once detected, uploads to the host will be paused for a while, protecting the client
+// from buggy or malicious hosts draining the allowance
+type revisionMonitor struct {
+	hosts map[enode.ID]*hostRevisionRecord
+	clock chrono.Clock
+	lock  sync.Mutex
+}
+
+// hostRevisionRecord is the revision signing history with a single storage host
+type hostRevisionRecord struct {
+	samples []revisionSample
+
+	lastRevisionNumber uint64
+	lastRevisionHash   common.Hash
+	identicalCount     int
+
+	pausedUntil time.Time
+	pauseReason string
+}
+
+// revisionSample records the signing time and the fund spent by a revision
+type revisionSample struct {
+	time  time.Time
+	hash  common.Hash
+	spent *big.Int
+}
+
+// PausedHost is the information of the storage host that uploads are paused
+type PausedHost struct {
+	HostID      enode.ID  `json:"hostid"`
+	PausedUntil time.Time `json:"pauseduntil"`
+	Reason      string    `json:"reason"`
+}
+
+// newRevisionMonitor creates a new revisionMonitor object
+func newRevisionMonitor(clock chrono.Clock) *revisionMonitor {
+	return &revisionMonitor{
+		hosts: make(map[enode.ID]*hostRevisionRecord),
+		clock: clock,
+	}
+}
+
+// checkRevision is called before the client signs the new revision with the host. If the
+// revision is abnormal, the uploads to the host will be paused and error will be returned.
+// Otherwise, the revision will be recorded as signed
+func (rm *revisionMonitor) checkRevision(hostID enode.ID, current, newRev types.StorageContractRevision) error {
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+
+	now := rm.clock.Now()
+	record, exists := rm.hosts[hostID]
+	if !exists {
+		record = &hostRevisionRecord{}
+		rm.hosts[hostID] = record
+	}
+
+	// remove the samples out of the monitor window
+	var samples []revisionSample
+	for _, sample := range record.samples {
+		if now.Sub(sample.time) <= RevisionMonitorWindow {
+			samples = append(samples, sample)
+		}
+	}
+	record.samples = samples
+
+	// rate limit, the revision will not be signed, but uploads will not be paused
+	if len(record.samples) >= MaxRevisionsPerWindow {
+		return ErrRevisionRateExceeded
+	}
+
+	// revision number must be increased by one, based on the last revision signed
+	if record.lastRevisionNumber != 0 && newRev.NewRevisionNumber > record.lastRevisionNumber+1 {
+		return rm.pause(hostID, record, now, fmt.Sprintf("revision number jumped from %v to %v",
+			record.lastRevisionNumber, newRev.NewRevisionNumber))
+	}
+
+	// repeated identical revisions
+	revHash := newRev.RLPHash()
+	if revHash == record.lastRevisionHash {
+		record.identicalCount++
+	} else {
+		record.identicalCount = 0
+	}
+	if record.identicalCount >= MaxIdenticalRevisions {
+		return rm.pause(hostID, record, now, fmt.Sprintf("identical revision repeated %v times", record.identicalCount+1))
+	}
+
+	// rapid spend increase within the monitor window, compared with the contract fund
+	spent := revisionSpent(current, newRev)
+	windowSpent := new(big.Int).Set(spent)
+	for _, sample := range record.samples {
+		windowSpent.Add(windowSpent, sample.spent)
+	}
+	if fund := contractFund(current); fund.Sign() > 0 {
+		spentRatio, _ := new(big.Float).Quo(new(big.Float).SetInt(windowSpent), new(big.Float).SetInt(fund)).Float64()
+		if spentRatio > MaxSpendRatioPerWindow {
+			return rm.pause(hostID, record, now, fmt.Sprintf("%.2f%% of the contract fund spent within %v",
+				spentRatio*100, RevisionMonitorWindow))
+		}
+	}
+
+	// the revision is normal, record it
+	record.samples = append(record.samples, revisionSample{time: now, hash: revHash, spent: spent})
+	record.lastRevisionNumber = newRev.NewRevisionNumber
+	record.lastRevisionHash = revHash
+	return nil
+}
+
+// revisionFailed is called when the negotiation of the revision checked failed before
+// the host countersigned it. The revision is not counted as an identical revision or
+// as fund spent, so that retrying the same revision will not pause the uploads. It is
+// still counted by the rate limit
+func (rm *revisionMonitor) revisionFailed(hostID enode.ID, newRev types.StorageContractRevision) {
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+
+	record, exists := rm.hosts[hostID]
+	if !exists {
+		return
+	}
+
+	revHash := newRev.RLPHash()
+	for i := range record.samples {
+		if record.samples[i].hash == revHash {
+			record.samples[i].spent = new(big.Int)
+		}
+	}
+	if record.lastRevisionHash == revHash {
+		record.lastRevisionHash = common.Hash{}
+		record.identicalCount = 0
+	}
+}
+
+// pause will pause the uploads to the host and raise the alert
+func (rm *revisionMonitor) pause(hostID enode.ID, record *hostRevisionRecord, now time.Time, reason string) error {
+	record.pausedUntil = now.Add(AbnormalRevisionPauseDuration)
+	record.pauseReason = reason
+	log.Warn("Abnormal revision detected, uploads to the host are paused", "hostID", hostID,
+		"reason", reason, "pausedUntil", record.pausedUntil)
+	return fmt.Errorf("%s: %s", ErrHostUploadPaused.Error(), reason)
+}
+
+// isPaused checks if the uploads to the host are paused
+func (rm *revisionMonitor) isPaused(hostID enode.ID) bool {
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+
+	record, exists := rm.hosts[hostID]
+	return exists && rm.clock.Now().Before(record.pausedUntil)
+}
+
+// resume will resume the uploads to the host, and clear the revision history
+func (rm *revisionMonitor) resume(hostID enode.ID) {
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+	delete(rm.hosts, hostID)
+}
+
+// pausedHosts returns all hosts that uploads are currently paused
+func (rm *revisionMonitor) pausedHosts() (hosts []PausedHost) {
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+
+	now := rm.clock.Now()
+	for id, record := range rm.hosts {
+		if now.Before(record.pausedUntil) {
+			hosts = append(hosts, PausedHost{
+				HostID:      id,
+				PausedUntil: record.pausedUntil,
+				Reason:      record.pauseReason,
+			})
+		}
+	}
+	return
+}
+
+// revisionSpent calculates the client fund spent by the new revision
+func revisionSpent(current, newRev types.StorageContractRevision) *big.Int {
+	if len(current.NewValidProofOutputs) == 0 || len(newRev.NewValidProofOutputs) == 0 {
+		return new(big.Int)
+	}
+	spent := new(big.Int).Sub(current.NewValidProofOutputs[0].Value, newRev.NewValidProofOutputs[0].Value)
+	if spent.Sign() < 0 {
+		return new(big.Int)
+	}
+	return spent
+}
+
+// contractFund returns the total fund of the contract, which is the sum of the
+// valid proof outputs
+func contractFund(rev types.StorageContractRevision) *big.Int {
+	fund := new(big.Int)
+	for _, output := range rev.NewValidProofOutputs {
+		fund.Add(fund, output.Value)
+	}
+	return fund
+}
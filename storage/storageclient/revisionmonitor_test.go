@@ -0,0 +1,173 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage/chrono"
+)
+
+func newMonitorTestRevision(clientFund, hostFund int64, revNumber uint64) types.StorageContractRevision {
+	return types.StorageContractRevision{
+		NewRevisionNumber: revNumber,
+		NewValidProofOutputs: []types.DxcoinCharge{
+			{Value: big.NewInt(clientFund)},
+			{Value: big.NewInt(hostFund)},
+		},
+		NewMissedProofOutputs: []types.DxcoinCharge{
+			{Value: big.NewInt(clientFund)},
+			{Value: big.NewInt(hostFund)},
+		},
+	}
+}
+
+func TestRevisionMonitor_Normal(t *testing.T) {
+	rm := newRevisionMonitor(chrono.System)
+	var hostID enode.ID
+
+	current := newMonitorTestRevision(1000000, 0, 1)
+	for i := 0; i < 100; i++ {
+		newRev := NewRevision(current, big.NewInt(10))
+		if err := rm.checkRevision(hostID, current, newRev); err != nil {
+			t.Fatalf("normal revision %v detected as abnormal: %s", i, err.Error())
+		}
+		current = newRev
+	}
+	if rm.isPaused(hostID) {
+		t.Fatal("uploads should not be paused for normal revisions")
+	}
+}
+
+func TestRevisionMonitor_RapidSpend(t *testing.T) {
+	rm := newRevisionMonitor(chrono.System)
+	var hostID enode.ID
+
+	current := newMonitorTestRevision(1000, 0, 1)
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		newRev := NewRevision(current, big.NewInt(50))
+		err = rm.checkRevision(hostID, current, newRev)
+		current = newRev
+	}
+	if err == nil {
+		t.Fatal("rapid spend increase is not detected")
+	}
+	if !rm.isPaused(hostID) {
+		t.Fatal("uploads should be paused after rapid spend increase")
+	}
+	if len(rm.pausedHosts()) != 1 {
+		t.Fatalf("expected 1 paused host, got %v", len(rm.pausedHosts()))
+	}
+
+	rm.resume(hostID)
+	if rm.isPaused(hostID) {
+		t.Fatal("uploads should be resumed")
+	}
+}
+
+func TestRevisionMonitor_RevisionNumberJump(t *testing.T) {
+	rm := newRevisionMonitor(chrono.System)
+	var hostID enode.ID
+
+	current := newMonitorTestRevision(1000000, 0, 1)
+	newRev := NewRevision(current, big.NewInt(1))
+	if err := rm.checkRevision(hostID, current, newRev); err != nil {
+		t.Fatalf("failed to check the revision: %s", err.Error())
+	}
+
+	jumped := newRev
+	jumped.NewRevisionNumber += 100
+	if err := rm.checkRevision(hostID, newRev, jumped); err == nil {
+		t.Fatal("revision number jump is not detected")
+	}
+	if !rm.isPaused(hostID) {
+		t.Fatal("uploads should be paused after revision number jump")
+	}
+}
+
+func TestRevisionMonitor_IdenticalRevisions(t *testing.T) {
+	rm := newRevisionMonitor(chrono.System)
+	var hostID enode.ID
+
+	current := newMonitorTestRevision(1000000, 0, 1)
+	newRev := NewRevision(current, big.NewInt(1))
+	var err error
+	for i := 0; i <= MaxIdenticalRevisions && err == nil; i++ {
+		err = rm.checkRevision(hostID, current, newRev)
+	}
+	if err == nil {
+		t.Fatal("repeated identical revisions are not detected")
+	}
+	if !rm.isPaused(hostID) {
+		t.Fatal("uploads should be paused after repeated identical revisions")
+	}
+}
+
+func TestRevisionMonitor_FailedRevisionRetry(t *testing.T) {
+	rm := newRevisionMonitor(chrono.System)
+	var hostID enode.ID
+
+	// retrying the revision that the host failed to countersign should not pause the uploads
+	current := newMonitorTestRevision(1000000, 0, 1)
+	newRev := NewRevision(current, big.NewInt(1))
+	for i := 0; i <= MaxIdenticalRevisions; i++ {
+		if err := rm.checkRevision(hostID, current, newRev); err != nil {
+			t.Fatalf("retry %v of the failed revision detected as abnormal: %s", i, err.Error())
+		}
+		rm.revisionFailed(hostID, newRev)
+	}
+	if rm.isPaused(hostID) {
+		t.Fatal("uploads should not be paused by retrying the failed revision")
+	}
+}
+
+func TestRevisionMonitor_RateLimit(t *testing.T) {
+	rm := newRevisionMonitor(chrono.System)
+	var hostID enode.ID
+
+	current := newMonitorTestRevision(1<<62, 0, 1)
+	var err error
+	for i := 0; i <= MaxRevisionsPerWindow && err == nil; i++ {
+		newRev := NewRevision(current, big.NewInt(1))
+		err = rm.checkRevision(hostID, current, newRev)
+		current = newRev
+	}
+	if err != ErrRevisionRateExceeded {
+		t.Fatalf("expected error %v, got %v", ErrRevisionRateExceeded, err)
+	}
+	if rm.isPaused(hostID) {
+		t.Fatal("uploads should not be paused when rate limit exceeded")
+	}
+}
+
+func TestRevisionMonitor_PauseExpire(t *testing.T) {
+	clock := chrono.NewFakeClock(time.Unix(1000, 0))
+	rm := newRevisionMonitor(clock)
+	var hostID enode.ID
+
+	current := newMonitorTestRevision(1000000, 0, 1)
+	jumped := NewRevision(current, big.NewInt(1))
+	jumped.NewRevisionNumber += 100
+	if err := rm.checkRevision(hostID, current, NewRevision(current, big.NewInt(1))); err != nil {
+		t.Fatalf("failed to check the revision: %s", err.Error())
+	}
+	if err := rm.checkRevision(hostID, current, jumped); err == nil {
+		t.Fatal("revision number jump is not detected")
+	}
+
+	clock.Advance(AbnormalRevisionPauseDuration - time.Second)
+	if !rm.isPaused(hostID) {
+		t.Fatal("uploads should be paused within the pause duration")
+	}
+	clock.Advance(time.Second)
+	if rm.isPaused(hostID) || len(rm.pausedHosts()) != 0 {
+		t.Fatal("uploads should be resumed after the pause duration")
+	}
+}
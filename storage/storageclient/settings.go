@@ -0,0 +1,215 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/event"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// settingsVersion is the schema version of the storage client settings. The settings persisted
+// before the schema version was introduced have the version 0
+const settingsVersion = 2
+
+var settingsMetadata = common.Metadata{
+	Header:  "storage client Settings",
+	Version: PersistStorageClientVersion,
+}
+
+// settingsMigrations upgrades the settings of the schema version of the index to the next version
+var settingsMigrations = []func(*Settings){
+	// the settings of version 0 share the layout of version 1
+	func(*Settings) {},
+	// the feature flags are introduced in version 2
+	func(s *Settings) { s.FeatureFlags = storage.DefaultFeatureFlags().String() },
+}
+
+// Settings is the storage client settings kept by the settings store
+type Settings struct {
+	SchemaVersion    uint32
+	MaxDownloadSpeed int64
+	MaxUploadSpeed   int64
+	MaxFiles         uint64
+	MaxCachedFiles   int
+	Backup           backupConfig
+	Faucet           string
+
+	// FeatureFlags is the protocol features enabled in the format of storage.ParseFeatureFlags
+	FeatureFlags string
+}
+
+// SettingsChangeEvent is sent to the subscribers once the settings are changed
+type SettingsChangeEvent struct {
+	Old Settings
+	New Settings
+}
+
+// settingsStore is the concurrency safe store of the storage client settings. An update is
+// validated and persisted before it takes effect, then the modules are reconfigured by the
+// apply function and the subscribers are notified
+type settingsStore struct {
+	settings Settings
+	path     string
+
+	// apply reconfigures the modules with the settings changed
+	apply func(old, new Settings)
+	feed  event.Feed
+
+	// updateLock serializes the updates, so that the changes are applied in order
+	updateLock sync.Mutex
+	lock       sync.RWMutex
+}
+
+// newSettingsStore creates the settings store persisted to the path with the default settings
+func newSettingsStore(path string, apply func(old, new Settings)) *settingsStore {
+	return &settingsStore{
+		settings: defaultSettings(),
+		path:     path,
+		apply:    apply,
+	}
+}
+
+// defaultSettings returns the settings of a new storage client
+func defaultSettings() Settings {
+	return Settings{
+		SchemaVersion:    settingsVersion,
+		MaxDownloadSpeed: DefaultMaxDownloadSpeed,
+		MaxUploadSpeed:   DefaultMaxUploadSpeed,
+		MaxFiles:         DefaultMaxFiles,
+		MaxCachedFiles:   DefaultMaxCachedFiles,
+		FeatureFlags:     storage.DefaultFeatureFlags().String(),
+	}
+}
+
+// load loads the settings persisted, and migrates the settings of the earlier schema versions.
+// The default settings are persisted if no settings are found
+func (ss *settingsStore) load() error {
+	ss.updateLock.Lock()
+	defer ss.updateLock.Unlock()
+
+	var settings Settings
+	err := common.LoadDxJSON(settingsMetadata, ss.path, &settings)
+	if os.IsNotExist(err) {
+		settings = defaultSettings()
+		if err = common.SaveDxJSON(settingsMetadata, ss.path, settings); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if settings.SchemaVersion > settingsVersion {
+		return fmt.Errorf("the storage client settings of schema version %v is not supported", settings.SchemaVersion)
+	}
+	if settings.SchemaVersion < settingsVersion {
+		for ; settings.SchemaVersion < settingsVersion; settings.SchemaVersion++ {
+			settingsMigrations[settings.SchemaVersion](&settings)
+		}
+		if err = common.SaveDxJSON(settingsMetadata, ss.path, settings); err != nil {
+			return err
+		}
+	}
+	if err = settings.validate(); err != nil {
+		return fmt.Errorf("invalid storage client settings: %v", err)
+	}
+
+	ss.lock.Lock()
+	ss.settings = settings
+	ss.lock.Unlock()
+	return nil
+}
+
+// get returns a copy of the current settings
+func (ss *settingsStore) get() Settings {
+	ss.lock.RLock()
+	defer ss.lock.RUnlock()
+	return ss.settings
+}
+
+// update applies the change to a copy of the current settings. The settings changed are
+// validated and persisted before they replace the current settings, otherwise the current
+// settings are kept
+func (ss *settingsStore) update(change func(*Settings) error) error {
+	ss.updateLock.Lock()
+	defer ss.updateLock.Unlock()
+
+	old := ss.get()
+	settings := old
+	if err := change(&settings); err != nil {
+		return err
+	}
+	if settings == old {
+		return nil
+	}
+	if err := settings.validate(); err != nil {
+		return err
+	}
+	if err := common.SaveDxJSON(settingsMetadata, ss.path, settings); err != nil {
+		return fmt.Errorf("failed to save the storage client settings: %s", err.Error())
+	}
+
+	ss.lock.Lock()
+	ss.settings = settings
+	ss.lock.Unlock()
+
+	if ss.apply != nil {
+		ss.apply(old, settings)
+	}
+	ss.feed.Send(SettingsChangeEvent{Old: old, New: settings})
+	return nil
+}
+
+// subscribe registers the channel receiving the settings changes
+func (ss *settingsStore) subscribe(ch chan<- SettingsChangeEvent) event.Subscription {
+	return ss.feed.Subscribe(ch)
+}
+
+// validate checks the settings are consistent
+func (s Settings) validate() error {
+	if s.MaxUploadSpeed < 0 || s.MaxDownloadSpeed < 0 {
+		return fmt.Errorf("both upload speed %v and download speed %v cannot be smaller than 0",
+			s.MaxUploadSpeed, s.MaxDownloadSpeed)
+	}
+	if s.MaxCachedFiles < 0 {
+		return fmt.Errorf("max cached files %v cannot be smaller than 0", s.MaxCachedFiles)
+	}
+	if _, err := storage.ParseFeatureFlags(s.FeatureFlags); err != nil {
+		return fmt.Errorf("invalid feature flags: %v", err)
+	}
+	if s.Faucet != "" {
+		if err := validateFaucet(s.Faucet); err != nil {
+			return err
+		}
+	}
+	if s.Backup.Target != "" {
+		if s.Backup.Interval < MinBackupInterval {
+			return fmt.Errorf("backup interval %v is smaller than %v", s.Backup.Interval, MinBackupInterval)
+		}
+	}
+	return nil
+}
+
+// SubscribeSettingsChange registers the channel receiving the storage client settings changes,
+// so that the settings changed are picked up live
+func (client *StorageClient) SubscribeSettingsChange(ch chan<- SettingsChangeEvent) event.Subscription {
+	return client.settings.subscribe(ch)
+}
+
+// applySettings reconfigures the modules with the settings changed
+func (client *StorageClient) applySettings(old, new Settings) {
+	if old.MaxDownloadSpeed != new.MaxDownloadSpeed || old.MaxUploadSpeed != new.MaxUploadSpeed {
+		if err := client.setBandwidthLimits(new.MaxDownloadSpeed, new.MaxUploadSpeed); err != nil {
+			client.log.Warn("failed to set the bandwidth limits", "err", err)
+		}
+	}
+	if old.MaxFiles != new.MaxFiles || old.MaxCachedFiles != new.MaxCachedFiles {
+		client.applyFileLimits()
+	}
+}
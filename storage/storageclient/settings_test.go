@@ -0,0 +1,140 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func newTestSettingsStore(t *testing.T, apply func(old, new Settings)) (*settingsStore, func()) {
+	dir, err := ioutil.TempDir("", "settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := newSettingsStore(filepath.Join(dir, PersistFilename), apply)
+	if err = ss.load(); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return ss, func() { os.RemoveAll(dir) }
+}
+
+func TestSettingsStoreUpdate(t *testing.T) {
+	var applied []Settings
+	ss, cleanup := newTestSettingsStore(t, func(old, new Settings) { applied = append(applied, new) })
+	defer cleanup()
+	if ss.get() != defaultSettings() {
+		t.Fatalf("expect the default settings, got %+v", ss.get())
+	}
+
+	changeErr := errors.New("change failed")
+	tests := []struct {
+		change func(*Settings) error
+		err    bool
+	}{
+		{func(s *Settings) error { s.MaxUploadSpeed = -1; return nil }, true},
+		{func(s *Settings) error { s.MaxCachedFiles = -1; return nil }, true},
+		{func(s *Settings) error {
+			s.Backup = backupConfig{Target: "file:///tmp", Interval: time.Second}
+			return nil
+		}, true},
+		{func(s *Settings) error { s.MaxFiles = 10; return changeErr }, true},
+		{func(s *Settings) error { s.MaxUploadSpeed = 100; return nil }, false},
+	}
+	for i, test := range tests {
+		err := ss.update(test.change)
+		if test.err != (err != nil) {
+			t.Errorf("test %d: expect error %v, got %v", i, test.err, err)
+		}
+	}
+	if ss.get().MaxUploadSpeed != 100 || ss.get().MaxFiles != 0 {
+		t.Errorf("only the valid update shall take effect, got %+v", ss.get())
+	}
+	if len(applied) != 1 || applied[0].MaxUploadSpeed != 100 {
+		t.Errorf("expect the valid update applied once, got %+v", applied)
+	}
+
+	// the settings not changed are not applied again
+	if err := ss.update(func(s *Settings) error { s.MaxUploadSpeed = 100; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 {
+		t.Errorf("the unchanged settings shall not be applied, got %d applies", len(applied))
+	}
+
+	// the settings are persisted
+	reloaded := newSettingsStore(ss.path, nil)
+	if err := reloaded.load(); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.get() != ss.get() {
+		t.Errorf("expect the persisted settings %+v, got %+v", ss.get(), reloaded.get())
+	}
+}
+
+func TestSettingsStoreSubscribe(t *testing.T) {
+	ss, cleanup := newTestSettingsStore(t, nil)
+	defer cleanup()
+
+	ch := make(chan SettingsChangeEvent, 1)
+	sub := ss.subscribe(ch)
+	defer sub.Unsubscribe()
+
+	if err := ss.update(func(s *Settings) error { s.MaxFiles = 10; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-ch:
+		if ev.Old.MaxFiles != 0 || ev.New.MaxFiles != 10 {
+			t.Errorf("unexpected settings change event %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the settings change event is not received")
+	}
+}
+
+func TestSettingsStoreSchemaVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, PersistFilename)
+
+	// the settings persisted before the schema version was introduced
+	legacy := defaultSettings()
+	legacy.SchemaVersion = 0
+	legacy.MaxFiles = 10
+	legacy.FeatureFlags = ""
+	if err = common.SaveDxJSON(settingsMetadata, path, legacy); err != nil {
+		t.Fatal(err)
+	}
+	ss := newSettingsStore(path, nil)
+	if err = ss.load(); err != nil {
+		t.Fatal(err)
+	}
+	if ss.get().SchemaVersion != settingsVersion || ss.get().MaxFiles != 10 ||
+		ss.get().FeatureFlags != storage.DefaultFeatureFlags().String() {
+		t.Errorf("expect the legacy settings migrated, got %+v", ss.get())
+	}
+
+	// the settings of a newer schema version are rejected
+	newer := defaultSettings()
+	newer.SchemaVersion = settingsVersion + 1
+	if err = common.SaveDxJSON(settingsMetadata, path, newer); err != nil {
+		t.Fatal(err)
+	}
+	if err = newSettingsStore(path, nil).load(); err == nil {
+		t.Error("the settings of a newer schema version shall be rejected")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"bytes"
+
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// zeroSector is the sector of zeros written to the download destination for the holes
+var zeroSector = make([]byte, storage.SectorSize)
+
+// isZeroSegment checks whether the logical data of the segment is all zeros, which is a hole
+// of the sparse file such as the VM images and the database files
+func isZeroSegment(data [][]byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	for _, b := range data {
+		for len(b) > 0 {
+			n := len(b)
+			if n > len(zeroSector) {
+				n = len(zeroSector)
+			}
+			if !bytes.Equal(b[:n], zeroSector[:n]) {
+				return false
+			}
+			b = b[n:]
+		}
+	}
+	return true
+}
+
+// markHole marks the segment full of zeros as a hole of the file instead of uploading it. The
+// hole is regarded as complete, so that no worker is assigned to the segment
+func (client *StorageClient) markHole(segment *unfinishedUploadSegment) error {
+	if err := segment.fileEntry.MarkHole(int(segment.index)); err != nil {
+		return err
+	}
+	segment.logicalSegmentData = nil
+	segment.workersRemain = 0
+	segment.sectorsCompletedNum = segment.sectorsAllNeedNum
+	client.log.Debug("segment marked as a hole", "dxpath", segment.fileEntry.DxPath().Path, "segment", segment.index)
+	return nil
+}
+
+// writeHole fills the range of the download destination with zeros
+func writeHole(dst writeDestination, offset int64, length uint64) error {
+	for length > 0 {
+		n := length
+		if n > uint64(len(zeroSector)) {
+			n = uint64(len(zeroSector))
+		}
+		if _, err := dst.WriteAt(zeroSector[:n], offset); err != nil {
+			return err
+		}
+		offset += int64(n)
+		length -= n
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestIsZeroSegment(t *testing.T) {
+	buf := newDownloadBuffer(2*storage.SectorSize, storage.SectorSize)
+	if !isZeroSegment(buf.buf) {
+		t.Error("the segment of zeros shall be a hole")
+	}
+	buf.buf[1][storage.SectorSize-1] = 1
+	if isZeroSegment(buf.buf) {
+		t.Error("the segment with data shall not be a hole")
+	}
+	if isZeroSegment(nil) {
+		t.Error("the segment without data shall not be a hole")
+	}
+}
+
+func TestWriteHole(t *testing.T) {
+	length := 3*storage.SectorSize + 10
+	buf := newDownloadBuffer(length+20, storage.SectorSize)
+	for _, b := range buf.buf {
+		for i := range b {
+			b[i] = 0xff
+		}
+	}
+	if err := writeHole(buf, 10, length); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Join(buf.buf, nil)[:length+20]
+	if !bytes.Equal(data[10:10+length], make([]byte, length)) {
+		t.Error("the hole is not filled with zeros")
+	}
+	if !bytes.Equal(data[:10], bytes.Repeat([]byte{0xff}, 10)) || !bytes.Equal(data[10+length:], bytes.Repeat([]byte{0xff}, 10)) {
+		t.Error("the data out of the hole is changed")
+	}
+}
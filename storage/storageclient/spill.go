@@ -0,0 +1,122 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/edsrzf/mmap-go"
+)
+
+// segmentSpill is the physical data of an upload segment spilled to disk. The sectors
+// are read back through the read-only memory map of the spill file
+type segmentSpill struct {
+	file   *os.File
+	mem    mmap.MMap
+	closed bool
+}
+
+// EnableSpill enables spilling the encoded data of the upload segments to the directory
+// when the memory manager is saturated, so that more segments can be queued for the
+// workers on machines with low memory. The spill files left by the previous run are
+// removed. It must be called before Start
+func (client *StorageClient) EnableSpill(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"+spillFileSuffix))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	client.spillDir = dir
+	return nil
+}
+
+// spillSegment writes the physical data of the segment to a spill file, and replaces the
+// sectors with the slices of the memory map. The memory of the segment is returned to the
+// memory manager at once. The segment is not spilled if the spill is disabled or the
+// memory manager is not saturated
+func (client *StorageClient) spillSegment(uc *unfinishedUploadSegment) error {
+	if client.spillDir == "" || !client.memoryManager.Saturated() {
+		return nil
+	}
+
+	file, err := ioutil.TempFile(client.spillDir, "segment-*"+spillFileSuffix)
+	if err != nil {
+		return err
+	}
+	offsets := make([]int, len(uc.physicalSegmentData)+1)
+	for i, sector := range uc.physicalSegmentData {
+		if _, err = file.Write(sector); err != nil {
+			break
+		}
+		offsets[i+1] = offsets[i] + len(sector)
+	}
+	var mem mmap.MMap
+	if err == nil && offsets[len(offsets)-1] > 0 {
+		mem, err = mmap.Map(file, mmap.RDONLY, 0)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	uc.mu.Lock()
+	for i, sector := range uc.physicalSegmentData {
+		if sector != nil {
+			uc.physicalSegmentData[i] = mem[offsets[i]:offsets[i+1]:offsets[i+1]]
+		}
+	}
+	uc.spill = &segmentSpill{file: file, mem: mem}
+	memoryReturned := uc.memoryNeeded - uc.memoryReleased
+	uc.memoryReleased = uc.memoryNeeded
+	uc.mu.Unlock()
+
+	client.memoryManager.Return(memoryReturned)
+	return nil
+}
+
+// releaseMemory records the memory of the segment released, and returns the amount to be
+// returned to the memory manager. The memory of the spilled segment has been returned
+// when spilled. The caller must hold the lock of the segment
+func (uc *unfinishedUploadSegment) releaseMemory(amount uint64) uint64 {
+	if uc.spill != nil {
+		return 0
+	}
+	uc.memoryReleased += amount
+	return amount
+}
+
+// closeSpill unmaps and removes the spill file of the released segment. The caller must
+// hold the lock of the segment
+func (uc *unfinishedUploadSegment) closeSpill() error {
+	if uc.spill == nil || uc.spill.closed {
+		return nil
+	}
+	uc.spill.closed = true
+	for i := range uc.physicalSegmentData {
+		uc.physicalSegmentData[i] = nil
+	}
+
+	var errs []error
+	if uc.spill.mem != nil {
+		errs = append(errs, uc.spill.mem.Unmap())
+	}
+	errs = append(errs, uc.spill.file.Close(), os.Remove(uc.spill.file.Name()))
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
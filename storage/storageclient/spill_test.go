@@ -0,0 +1,84 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/storage/storageclient/memorymanager"
+)
+
+func TestStorageClient_SpillSegment(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	client := &StorageClient{memoryManager: memorymanager.New(100, stop)}
+	if err := client.EnableSpill(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	sectors := [][]byte{[]byte("sector0"), nil, []byte("sector2")}
+	uc := &unfinishedUploadSegment{
+		memoryNeeded:        100,
+		memoryReleased:      30,
+		physicalSegmentData: [][]byte{sectors[0], sectors[1], sectors[2]},
+	}
+	client.memoryManager.Request(70, false)
+
+	// the segment is kept in memory if the memory manager is not saturated
+	if err := client.spillSegment(uc); err != nil {
+		t.Fatal(err)
+	}
+	if uc.spill != nil {
+		t.Fatalf("the segment should not be spilled without memory pressure")
+	}
+
+	waiting := make(chan struct{})
+	go func() {
+		client.memoryManager.Request(100, false)
+		close(waiting)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := client.spillSegment(uc); err != nil {
+		t.Fatal(err)
+	}
+	if uc.spill == nil {
+		t.Fatalf("the segment should be spilled under memory pressure")
+	}
+	for i, sector := range sectors {
+		if !bytes.Equal(uc.physicalSegmentData[i], sector) {
+			t.Errorf("spilled sector %v not expected. Expect %s, Got %s", i, sector, uc.physicalSegmentData[i])
+		}
+	}
+	select {
+	case <-waiting:
+	case <-time.After(time.Second):
+		t.Fatalf("the memory of the spilled segment should be returned")
+	}
+	if uc.memoryReleased != uc.memoryNeeded || uc.releaseMemory(10) != 0 {
+		t.Errorf("all memory of the spilled segment should be released once")
+	}
+
+	if err := uc.closeSpill(); err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"+spillFileSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 || uc.physicalSegmentData[0] != nil {
+		t.Errorf("the spill file should be removed once closed")
+	}
+}
@@ -6,6 +6,7 @@ package storageclient
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -29,7 +30,10 @@ import (
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/rlp"
 	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/chrono"
 	"github.com/DxChainNetwork/godx/storage/storageclient/contractmanager"
+	"github.com/DxChainNetwork/godx/storage/storageclient/contractset"
+	"github.com/DxChainNetwork/godx/storage/storageclient/coordinator"
 	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem"
 	"github.com/DxChainNetwork/godx/storage/storageclient/memorymanager"
 	"github.com/DxChainNetwork/godx/storage/storageclient/storagehostmanager"
@@ -54,11 +58,67 @@ type StorageClient struct {
 	// Upload management
 	uploadHeap uploadHeap
 
+	// uploads and downloads in flight, which can be cancelled by the operation id
+	operations *operationSet
+
+	// revisionMonitor detects abnormal revisions signed with the storage hosts
+	revisionMonitor *revisionMonitor
+
+	// uploadTimings records the upload time broken down by the storage hosts
+	uploadTimings *uploadTimings
+
+	// bandwidth limits the upload and the download speed shared by all workers
+	bandwidth *bandwidthLimiter
+
+	// downloadBudget keeps the download spending within the download budget of the period
+	downloadBudget downloadBudget
+
+	// repairDownloads deduplicates the downloads of the same remote data for repair
+	repairDownloads *repairDownloads
+
+	// hostBackfill scans the historical blocks for the storage host announcements
+	hostBackfill *hostBackfill
+
+	// coordination mode, where multiple nodes act as one logical storage client
+	coordinationBackend coordinator.Backend
+	coordinator         *coordinator.Coordinator
+
+	// read-only replica mode, where the storage client serves the downloads of the
+	// files uploaded by the primary storage client only
+	replica *replica
+
+	// backup ships the encrypted contract set and file metadata off the node
+	backup *backupState
+
+	// triage pauses the repairs to recover the at-risk files first after the mass host loss
+	triage *triageState
+
+	// uploadStreams are the upload streams being written
+	uploadStreams *uploadStreamSet
+
+	// localVerifications are the local copies verified to be safe to delete
+	localVerifications map[storage.DxPath]LocalCopyVerification
+
+	// fileHistoryLock serializes the updates of the operation history of the files
+	fileHistoryLock sync.Mutex
+
+	// directory to spill the encoded segment data to under memory pressure, empty if disabled
+	spillDir string
+
+	// listening address of the HTTP stream endpoint, empty if disabled, along with the
+	// access tokens and the virtual hosts allowed
+	httpStreamAddr   string
+	httpStreamTokens []string
+	httpStreamVHosts map[string]struct{}
+
 	// List of workers that can be used for uploading and/or downloading.
 	workerPool map[storage.ContractID]*worker
 
+	// workerPoolTuner limits the number of workers transferring sectors at the same time
+	workerPoolTuner *workerPoolTuner
+
 	// Directories and File related
-	persist        persistence
+	settings       *settingsStore
 	persistDir     string
 	staticFilesDir string
 
@@ -66,9 +126,10 @@ type StorageClient struct {
 	PaymentAddress common.Address
 
 	// Utilities
-	log  log.Logger
-	lock sync.Mutex
-	tm   threadmanager.ThreadManager
+	log   log.Logger
+	lock  sync.Mutex
+	tm    threadmanager.ThreadManager
+	clock chrono.Clock
 
 	// information on network, block chain, and etc.
 	info       storage.ParsedAPI
@@ -84,6 +145,7 @@ func New(persistDir string) (*StorageClient, error) {
 		persistDir:     persistDir,
 		staticFilesDir: filepath.Join(persistDir, DxPathRoot),
 		log:            log.New(),
+		clock:          chrono.System,
 		newDownloads:   make(chan struct{}, 1),
 		downloadHeap:   new(downloadSegmentHeap),
 		uploadHeap: uploadHeap{
@@ -91,10 +153,23 @@ func New(persistDir string) (*StorageClient, error) {
 			segmentComing:       make(chan struct{}, 1),
 			stuckSegmentSuccess: make(chan storage.DxPath, 1),
 		},
-		workerPool: make(map[storage.ContractID]*worker),
+		operations:      newOperationSet(),
+		workerPool:      make(map[storage.ContractID]*worker),
+		backup:          new(backupState),
+		uploadStreams:   newUploadStreamSet(),
+		revisionMonitor: newRevisionMonitor(chrono.System),
+		uploadTimings:   newUploadTimings(),
+		bandwidth:       newBandwidthLimiter(chrono.System),
+		repairDownloads: newRepairDownloads(),
+		hostBackfill:    &hostBackfill{},
+
+		localVerifications: make(map[storage.DxPath]LocalCopyVerification),
+		triage:             new(triageState),
 	}
 
 	sc.memoryManager = memorymanager.New(DefaultMaxMemory, sc.tm.StopChan())
+	sc.workerPoolTuner = newWorkerPoolTuner(sc.tm.StopChan())
+	sc.settings = newSettingsStore(filepath.Join(persistDir, PersistFilename), sc.applySettings)
 
 	// initialize storageHostManager
 	sc.storageHostManager = storagehostmanager.New(sc.persistDir)
@@ -111,6 +186,16 @@ func New(persistDir string) (*StorageClient, error) {
 	return sc, nil
 }
 
+// TakeoverLocks removes the locks on the contract set and the file system in the persist
+// directory, which are left stale by another storage client instance. The instance must not
+// be running any more, otherwise the data will be corrupted by the two instances
+func TakeoverLocks(persistDir string) error {
+	if err := contractset.TakeoverLock(persistDir); err != nil {
+		return err
+	}
+	return filesystem.TakeoverLock(persistDir)
+}
+
 // Start controls go routine checking and updating process
 func (client *StorageClient) Start(b storage.EthBackend, apiBackend ethapi.Backend) (err error) {
 	// get the eth backend
@@ -136,20 +221,43 @@ func (client *StorageClient) Start(b storage.EthBackend, apiBackend ethapi.Backe
 	if err := client.loadPersist(); err != nil {
 		return err
 	}
+	client.removeStaleUploadStreams()
 
 	if err = client.fileSystem.Start(); err != nil {
 		return err
 	}
+	client.applyFileLimits()
 
 	// active the work pool to get a worker for a upload/download task.
 	client.activateWorkerPool()
+	go client.workerPoolTuneLoop()
 
-	// loop to download, upload, stuck and health check
+	// loop to download, upload, stuck and health check. The read-only replica only
+	// serves the downloads, and syncs the file metadata from the primary instead
 	go client.downloadLoop()
-	go client.uploadLoop()
-	go client.stuckLoop()
-	go client.uploadOrRepair()
-	go client.healthCheckLoop()
+	if client.replica == nil {
+		go client.uploadLoop()
+		go client.stuckLoop()
+		go client.uploadOrRepair()
+		go client.healthCheckLoop()
+		go client.backupLoop()
+	} else {
+		go client.replicaLoop()
+	}
+
+	// resume the unfinished host announcement backfill
+	if err = client.resumeHostBackfill(); err != nil {
+		client.log.Warn("failed to resume the host announcement backfill", "err", err)
+		err = nil
+	}
+
+	// start to coordinate with the other nodes if the coordination mode is enabled
+	client.startCoordination()
+
+	// serve the files over HTTP if the HTTP stream endpoint is enabled
+	if err = client.startHTTPStream(); err != nil {
+		return err
+	}
 
 	// kill workers on shutdown.
 	client.tm.OnStop(func() error {
@@ -168,11 +276,15 @@ func (client *StorageClient) Start(b storage.EthBackend, apiBackend ethapi.Backe
 
 // Close method will be used to send storage
 func (client *StorageClient) Close() error {
+	var fullErr error
+
+	// Stop the coordination before the contract manager, handing over the leader lease
+	client.log.Info("Closing the storage client coordinator")
+	fullErr = common.ErrCompose(fullErr, client.stopCoordination())
+
 	client.log.Info("Closing The Contract Manager")
 	client.contractManager.Stop()
 
-	var fullErr error
-
 	// Closing the host manager
 	client.log.Info("Closing the storage client host manager")
 	err := client.storageHostManager.Close()
@@ -197,7 +309,20 @@ func (client *StorageClient) DeleteFile(path storage.DxPath) error {
 		return err
 	}
 	defer client.tm.Done()
-	return client.fileSystem.DeleteDxFile(path)
+
+	entry, err := client.fileSystem.OpenDxFile(path)
+	if err != nil {
+		return err
+	}
+	dxFilePath := entry.FilePath()
+	entry.Close()
+	if err = client.fileSystem.DeleteDxFile(path); err != nil {
+		return err
+	}
+	if err = removeUploadReceipt(dxFilePath); err != nil {
+		return err
+	}
+	return removeFileHistory(dxFilePath)
 }
 
 // ContractDetail will return the detailed contract information
@@ -217,6 +342,7 @@ func (client *StorageClient) ActiveContracts() (activeContracts []ActiveContract
 			AbleToRenew:  contract.Status.RenewAbility,
 			Canceled:     contract.Status.Canceled,
 		}
+		_, activeContract.Pending = client.contractManager.RetrievePendingContract(contract.ID)
 		activeContracts = append(activeContracts, activeContract)
 	}
 
@@ -255,16 +381,18 @@ func (client *StorageClient) SetClientSetting(setting storage.ClientSetting) (er
 	// set the ip violation check
 	client.storageHostManager.SetIPViolationCheck(setting.EnableIPViolation)
 
-	// update and save the persist
-	client.lock.Lock()
-	client.persist.MaxDownloadSpeed = setting.MaxDownloadSpeed
-	client.persist.MaxUploadSpeed = setting.MaxUploadSpeed
-	if err = client.saveSettings(); err != nil {
-		err = fmt.Errorf("failed to save the storage client settings: %s", err.Error())
-		client.lock.Unlock()
+	// update and save the settings
+	err = client.settings.update(func(s *Settings) error {
+		s.MaxDownloadSpeed = setting.MaxDownloadSpeed
+		s.MaxUploadSpeed = setting.MaxUploadSpeed
+		if setting.FeatureFlags != nil {
+			s.FeatureFlags = setting.FeatureFlags.String()
+		}
+		return nil
+	})
+	if err != nil {
 		return
 	}
-	client.lock.Unlock()
 
 	// active the worker pool
 	client.activateWorkerPool()
@@ -280,10 +408,37 @@ func (client *StorageClient) RetrieveClientSetting() (setting storage.ClientSett
 		EnableIPViolation: client.storageHostManager.RetrieveIPViolationCheckSetting(),
 		MaxUploadSpeed:    maxUploadSpeed,
 		MaxDownloadSpeed:  maxDownloadSpeed,
+		FeatureFlags:      client.featureFlags(),
 	}
 	return
 }
 
+// SetFileLimits sets the maximum number of files stored by the storage client, and the maximum
+// number of file metadata kept in memory. Zero maxFiles means unlimited, and zero maxCachedFiles
+// loads the file metadata on demand only
+func (client *StorageClient) SetFileLimits(maxFiles uint64, maxCachedFiles int) (err error) {
+	if maxCachedFiles < 0 {
+		return fmt.Errorf("max cached files %v cannot be smaller than 0", maxCachedFiles)
+	}
+
+	return client.settings.update(func(s *Settings) error {
+		s.MaxFiles = maxFiles
+		s.MaxCachedFiles = maxCachedFiles
+		return nil
+	})
+}
+
+// applyFileLimits applies the persisted file limits to the file system. The read-only replica
+// always loads the file metadata on demand, as the files are replaced by the replica sync
+func (client *StorageClient) applyFileLimits() {
+	settings := client.settings.get()
+	maxCachedFiles := settings.MaxCachedFiles
+	if client.replica != nil {
+		maxCachedFiles = 0
+	}
+	client.fileSystem.SetFileLimits(settings.MaxFiles, maxCachedFiles)
+}
+
 // setBandwidthLimits specifies the data upload and downloading speed limit
 func (client *StorageClient) setBandwidthLimits(downloadSpeedLimit, uploadSpeedLimit int64) (err error) {
 	// validation
@@ -297,6 +452,7 @@ func (client *StorageClient) setBandwidthLimits(downloadSpeedLimit, uploadSpeedL
 	} else {
 		client.contractManager.SetRateLimits(downloadSpeedLimit, uploadSpeedLimit, DefaultPacketSize)
 	}
+	client.bandwidth.setLimits(downloadSpeedLimit, uploadSpeedLimit)
 
 	return nil
 }
@@ -308,6 +464,13 @@ func (client *StorageClient) Append(sp storage.Peer, data []byte, hostInfo *stor
 }
 
 func (client *StorageClient) Write(sp storage.Peer, actions []storage.UploadAction, hostInfo *storage.HostInfo) (err error) {
+	// more than one sector could be uploaded in a request only if the batch upload is
+	// negotiated with the storage host
+	features := client.sessionFeatures(hostInfo)
+	if len(actions) > 1 && !storage.HasFeature(features, storage.FeatureBatchUpload) {
+		return storage.ErrFeatureNotNegotiated
+	}
+
 	// Retrieve the last contract revision
 	scs := client.contractManager.GetStorageContractSet()
 
@@ -399,6 +562,15 @@ func (client *StorageClient) Write(sp storage.Peer, actions []storage.UploadActi
 		}
 	}()
 
+	// wait for the upload speed limit shared by all workers
+	var uploadSize uint64
+	for _, action := range actions {
+		uploadSize += uint64(len(action.Data))
+	}
+	if err := client.bandwidth.waitUpload(hostInfo.EnodeID, uploadSize, client.tm.StopChan()); err != nil {
+		return err
+	}
+
 	// send contract upload request
 	if err := sp.RequestContractUpload(req); err != nil {
 		return err
@@ -459,6 +631,19 @@ func (client *StorageClient) Write(sp storage.Peer, actions []storage.UploadActi
 		clientNegotiateErr = err
 		return err
 	}
+
+	// check the revision pattern before signing it
+	if err = client.revisionMonitor.checkRevision(hostInfo.EnodeID, contractRevision, rev); err != nil {
+		clientNegotiateErr = err
+		return err
+	}
+	revHostSigned := false
+	defer func() {
+		if !revHostSigned {
+			client.revisionMonitor.revisionFailed(hostInfo.EnodeID, rev)
+		}
+	}()
+
 	// client sign the new revision
 	clientRevisionSign, err := clientWallet.SignHash(clientAccount, rev.RLPHash().Bytes())
 	if err != nil {
@@ -490,6 +675,7 @@ func (client *StorageClient) Write(sp storage.Peer, actions []storage.UploadActi
 	}
 
 	rev.Signatures = [][]byte{clientRevisionSign, hostRevisionSig}
+	revHostSigned = true
 
 	// commit upload revision
 	err = contract.CommitRevision(rev, storagePrice, bandwidthPrice)
@@ -580,9 +766,23 @@ func (client *StorageClient) Read(sp storage.Peer, w io.Writer, req storage.Down
 		return errors.New("client funds not enough to support download")
 	}
 
+	// reserve the price from the download budget, the downloads are stopped once exhausted
+	releaseBudget, err := client.reserveDownload(price)
+	if err != nil {
+		return err
+	}
+	defer releaseBudget()
+
 	// increase the price fluctuation by 0.2% to mitigate small errors, like different block height
 	price = price.MultFloat64(1 + extraRatio)
 
+	// stop before the revision is signed and sent if the download is cancelled
+	select {
+	case <-cancel:
+		return errOperationCancelled
+	default:
+	}
+
 	// create the download revision and sign it
 	newRevision := NewRevision(lastRevision, price.BigIntPtr())
 
@@ -594,6 +794,17 @@ func (client *StorageClient) Read(sp storage.Peer, w io.Writer, req storage.Down
 		return err
 	}
 
+	// check the revision pattern before signing it
+	if err := client.revisionMonitor.checkRevision(hostInfo.EnodeID, lastRevision, newRevision); err != nil {
+		return err
+	}
+	revHostSigned := false
+	defer func() {
+		if !revHostSigned {
+			client.revisionMonitor.revisionFailed(hostInfo.EnodeID, newRevision)
+		}
+	}()
+
 	clientSig, err := wallet.SignHash(account, newRevision.RLPHash().Bytes())
 	if err != nil {
 		return err
@@ -635,6 +846,11 @@ func (client *StorageClient) Read(sp storage.Peer, w io.Writer, req storage.Down
 		}
 	}()
 
+	// wait for the download speed limit shared by all workers
+	if err = client.bandwidth.waitDownload(hostInfo.EnodeID, estBandwidth, cancel); err != nil {
+		return err
+	}
+
 	// send download request
 	err = sp.RequestContractDownload(req)
 	if err != nil {
@@ -689,6 +905,7 @@ func (client *StorageClient) Read(sp storage.Peer, w io.Writer, req storage.Down
 
 		if len(resp.Signature) > 0 {
 			hostSig = resp.Signature
+			revHostSigned = true
 		} else {
 			err = errors.New("host lost response data signature")
 			hostNegotiateErr = err
@@ -746,7 +963,8 @@ func (client *StorageClient) Read(sp storage.Peer, w io.Writer, req storage.Down
 }
 
 // Download requests for a single section and returns the requested data. A Merkle proof is always requested.
-func (client *StorageClient) Download(sp storage.Peer, root common.Hash, offset, length uint32, hostInfo *storage.HostInfo) ([]byte, error) {
+// The request is not sent to the host once the cancel channel is closed
+func (client *StorageClient) Download(sp storage.Peer, root common.Hash, offset, length uint32, cancel <-chan struct{}, hostInfo *storage.HostInfo) ([]byte, error) {
 	client.lock.Lock()
 	defer client.lock.Unlock()
 
@@ -759,7 +977,7 @@ func (client *StorageClient) Download(sp storage.Peer, root common.Hash, offset,
 		MerkleProof: true,
 	}
 	var buf bytes.Buffer
-	err := client.Read(sp, &buf, req, nil, hostInfo)
+	err := client.Read(sp, &buf, req, cancel, hostInfo)
 	time.Sleep(1 * time.Second)
 
 	return buf.Bytes(), err
@@ -795,6 +1013,7 @@ func (client *StorageClient) newDownload(params downloadParams) (*download, erro
 		overdrive:         params.overdrive,
 		dxFile:            params.file,
 		priority:          params.priority,
+		operation:         params.operation,
 		log:               client.log,
 		memoryManager:     client.memoryManager,
 	}
@@ -890,6 +1109,22 @@ func (client *StorageClient) newDownload(params downloadParams) (*download, erro
 
 		uds.overdrive = uint32(params.overdrive)
 
+		// the hole is filled with zeros instead of downloaded from the hosts
+		if params.file.IsHole(i) {
+			if err := writeHole(params.destination, uds.writeOffset, uds.fetchLength); err != nil {
+				return nil, err
+			}
+			d.mu.Lock()
+			d.dataReceived += uds.fetchLength
+			d.operation.addProgress(uds.fetchLength, 0)
+			d.segmentsRemaining--
+			if d.segmentsRemaining == 0 {
+				d.markComplete()
+			}
+			d.mu.Unlock()
+			continue
+		}
+
 		// add this segment to the segment heap, and notify the download loop a new task
 		client.addSegmentToDownloadHeap(uds)
 		select {
@@ -900,6 +1135,17 @@ func (client *StorageClient) newDownload(params downloadParams) (*download, erro
 	return d, nil
 }
 
+// validateDownloadOptions checks whether the download options are valid
+func validateDownloadOptions(options storage.DownloadOptions) error {
+	if options.LatencyTarget <= 0 {
+		return errors.New("the latency target must be positive")
+	}
+	if options.Overdrive < 0 {
+		return errors.New("the overdrive cannot be negative")
+	}
+	return nil
+}
+
 // createDownload performs a file download and returns the download object
 func (client *StorageClient) createDownload(p storage.DownloadParameters) (*download, error) {
 	dxPath, err := storage.NewDxPath(p.RemoteFilePath)
@@ -918,6 +1164,23 @@ func (client *StorageClient) createDownload(p storage.DownloadParameters) (*down
 	if p.WriteToLocalPath == "" {
 		return nil, errors.New("not specified local path")
 	}
+	offset, length := p.Offset, p.Length
+	if offset > entry.FileSize() {
+		return nil, fmt.Errorf("offset %v exceeds the file size %v", offset, entry.FileSize())
+	}
+	if length == 0 {
+		length = entry.FileSize() - offset
+	}
+	if offset+length > entry.FileSize() {
+		return nil, fmt.Errorf("range [%v, %v) exceeds the file size %v", offset, offset+length, entry.FileSize())
+	}
+	options := DefaultDownloadOptions
+	if p.Options != nil {
+		options = *p.Options
+	}
+	if err := validateDownloadOptions(options); err != nil {
+		return nil, err
+	}
 
 	// if the parameter WriteToLocalPath is not a absolute path, set default file name
 	if p.WriteToLocalPath != "" && !filepath.IsAbs(p.WriteToLocalPath) {
@@ -951,22 +1214,25 @@ func (client *StorageClient) createDownload(p storage.DownloadParameters) (*down
 	if err != nil {
 		return nil, fmt.Errorf("cannot create snapshot: %v", err)
 	}
+	op := client.operations.add(OperationDownload, dxPath.Path)
 	d, err := client.newDownload(downloadParams{
 		destination:       dw,
 		destinationType:   destinationType,
 		destinationString: p.WriteToLocalPath,
 		file:              snap,
-		latencyTarget:     25e3 * time.Millisecond,
+		latencyTarget:     options.LatencyTarget,
 
-		// always download the whole file
-		length:      entry.FileSize(),
+		// only the segments covering the range are downloaded
+		length:      length,
 		needsMemory: true,
-
-		// always download from 0
-		offset:    0,
-		overdrive: 3,
-		priority:  5,
+		offset:      offset,
+		overdrive:   options.Overdrive,
+		priority:    options.Priority,
+		operation:   op,
 	})
+	if err != nil {
+		op.finish(err)
+	}
 	if closer, ok := dw.(io.Closer); err != nil && ok {
 		closeErr := closer.Close()
 		if closeErr != nil {
@@ -985,6 +1251,17 @@ func (client *StorageClient) createDownload(p storage.DownloadParameters) (*down
 		}
 		return nil
 	})
+	wholeFile := offset == 0 && length == snap.FileSize()
+	d.onComplete(func(err error) error {
+		if err == nil {
+			err = verifyDownloadChecksum(op, p.WriteToLocalPath, snap.Checksum(), wholeFile)
+		}
+		op.finish(err)
+		return nil
+	})
+	op.setCancel(func() {
+		d.fail(errOperationCancelled)
+	})
 
 	return d, nil
 }
@@ -992,8 +1269,9 @@ func (client *StorageClient) createDownload(p storage.DownloadParameters) (*down
 // NOTE: DownloadSync can directly be accessed to outer request via RPC or IPC ...
 // but can not async download to http response, so DownloadAsync should not open to out.
 
-// DownloadSync performs a file download and blocks until the download is finished.
-func (client *StorageClient) DownloadSync(p storage.DownloadParameters) error {
+// DownloadSync performs a file download and blocks until the download is finished. The
+// download fails with the context error once the context is cancelled or its deadline exceeded
+func (client *StorageClient) DownloadSync(ctx context.Context, p storage.DownloadParameters) error {
 	if err := client.tm.Add(); err != nil {
 		return err
 	}
@@ -1010,7 +1288,7 @@ func (client *StorageClient) DownloadSync(p storage.DownloadParameters) error {
 		for {
 			time.Sleep(time.Millisecond * 500)
 			fmt.Printf(">")
-			if d.segmentsRemaining == 0 {
+			if d.isComplete() {
 				break
 			}
 		}
@@ -1019,7 +1297,17 @@ func (client *StorageClient) DownloadSync(p storage.DownloadParameters) error {
 	// block until the download has completed
 	select {
 	case <-d.completeChan:
-		return d.Err()
+		if err := d.Err(); err != nil {
+			return err
+		}
+		// the downloaded file not matching the checksum fails the download operation
+		if status := d.operation.snapshot(); status.Verification == ChecksumMismatch {
+			return errChecksumMismatch
+		}
+		return nil
+	case <-ctx.Done():
+		d.fail(ctx.Err())
+		return ctx.Err()
 	case <-client.tm.StopChan():
 		return errors.New("download is shutdown")
 	}
@@ -1038,16 +1326,35 @@ func (client *StorageClient) DownloadAsync(p storage.DownloadParameters) error {
 
 // GetHostAnnouncementWithBlockHash will get the HostAnnouncements and block height through the hash of the block
 func (client *StorageClient) GetHostAnnouncementWithBlockHash(blockHash common.Hash) (hostAnnouncements []types.HostAnnouncement, number uint64, errGet error) {
-	precompiled := vm.PrecompiledEVMFileContracts
 	block, err := client.ethBackend.GetBlockByHash(blockHash)
 
 	if err != nil {
 		errGet = err
 		return
 	}
-	number = block.NumberU64()
+	hostAnnouncements, _ = client.hostTxsOfBlock(block)
+	return hostAnnouncements, block.NumberU64(), nil
+}
+
+// GetHostRevocationWithBlockHash will get the HostRevocations through the hash of the block
+func (client *StorageClient) GetHostRevocationWithBlockHash(blockHash common.Hash) ([]types.HostRevocation, error) {
+	block, err := client.ethBackend.GetBlockByHash(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	_, hostRevocations := client.hostTxsOfBlock(block)
+	return hostRevocations, nil
+}
+
+// hostTxsOfBlock will extract the HostAnnouncements and HostRevocations from the transactions of the block
+func (client *StorageClient) hostTxsOfBlock(block *types.Block) (hostAnnouncements []types.HostAnnouncement, hostRevocations []types.HostRevocation) {
+	precompiled := vm.PrecompiledEVMFileContracts
 	txs := block.Transactions()
 	for _, tx := range txs {
+		// skip the contract creation transactions
+		if tx.To() == nil {
+			continue
+		}
 		p, ok := precompiled[*tx.To()]
 		if !ok {
 			continue
@@ -1061,6 +1368,13 @@ func (client *StorageClient) GetHostAnnouncementWithBlockHash(blockHash common.H
 				continue
 			}
 			hostAnnouncements = append(hostAnnouncements, hac)
+		case vm.HostRevokeTransaction:
+			var hr types.HostRevocation
+			if err := rlp.DecodeBytes(tx.Data(), &hr); err != nil {
+				client.log.Warn("Rlp decoding error as hostRevocations", "err", err)
+				continue
+			}
+			hostRevocations = append(hostRevocations, hr)
 		default:
 			continue
 		}
@@ -1114,6 +1428,12 @@ func (client *StorageClient) CheckAndUpdateConnection(peerNode *enode.Node) {
 	client.ethBackend.CheckAndUpdateConnection(peerNode)
 }
 
+// UpdateHostRecord records the host record advertised in the node record of the peer, with
+// which the storage hosts not accepting new contracts are filtered before dialing
+func (client *StorageClient) UpdateHostRecord(node *enode.Node) {
+	client.storageHostManager.UpdateHostRecord(node)
+}
+
 // IsContractSignedWithHost is used to check if the client has signed any contract
 // with the storage host provided by the user
 func (client *StorageClient) IsContractSignedWithHost(hostNode *enode.Node) bool {
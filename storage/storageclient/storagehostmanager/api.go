@@ -79,11 +79,43 @@ func (api *PublicStorageHostManagerAPI) FilterMode() (fm string) {
 	return api.shm.RetrieveFilterMode()
 }
 
+// DiversityShare returns the maximum share of the storage hosts that can be in the same
+// ip network or geographic location, 0 if the diversity constraint is disabled
+func (api *PublicStorageHostManagerAPI) DiversityShare() float64 {
+	return api.shm.RetrieveDiversityShare()
+}
+
+// SybilClusters returns the storage host clusters suspected to be run by a single operator,
+// which share the payment address, the node key or the ip address
+func (api *PublicStorageHostManagerAPI) SybilClusters() []SybilCluster {
+	return api.shm.SybilClusters()
+}
+
 // FilteredHosts will return hosts stored in the filtered host tree
 func (api *PublicStorageHostManagerAPI) FilteredHosts() (allFiltered []storage.HostInfo) {
 	return api.shm.filteredTree.All()
 }
 
+// InteractionHistory will return the timestamped interaction records of the storage host,
+// with the successful and failed interactions weighted by exponential decay
+func (api *PublicStorageHostManagerAPI) InteractionHistory(id string) (history InteractionHistory, err error) {
+	var enodeid enode.ID
+
+	// convert the hex string back to the enode.ID type
+	idSlice, err := hex.DecodeString(id)
+	if err != nil {
+		err = fmt.Errorf("the hostID provided is not valid: %s", err.Error())
+		return
+	}
+	copy(enodeid[:], idSlice)
+
+	if _, exist := api.shm.storageHostTree.RetrieveHostInfo(enodeid); !exist {
+		err = fmt.Errorf("the host with %v does not exist", id)
+		return
+	}
+	return api.shm.interactionHistory(enodeid), nil
+}
+
 // PrivateStorageHostManagerAPI defines the object used to call eligible APIs
 // that are used to configure settings
 type PrivateStorageHostManagerAPI struct {
@@ -116,6 +148,19 @@ func (api *PrivateStorageHostManagerAPI) SetFilterMode(fm string, hostInfos []en
 	return
 }
 
+// SetDiversityShare sets the maximum share of the storage hosts, as well as the maximum share
+// of the sectors of a segment, that can be in the same ip network or geographic location.
+// Share 0 disables the diversity constraint
+func (api *PrivateStorageHostManagerAPI) SetDiversityShare(share float64) (resp string, err error) {
+	if err = api.shm.SetDiversityShare(share); err != nil {
+		err = fmt.Errorf("failed to set the diversity share: %s", err.Error())
+		return
+	}
+
+	resp = fmt.Sprintf("the diversity share has been successfully set to %v", share)
+	return
+}
+
 // PublicHostManagerDebugAPI defines the object used to call eligible APIs
 // that are used to perform testing
 type PublicHostManagerDebugAPI struct {
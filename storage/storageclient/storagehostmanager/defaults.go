@@ -16,6 +16,8 @@ const (
 	priceExponentiationSmall  = 0.75
 	priceExponentiationLarge  = 5
 	minStorage                = uint64(20e9)
+	latencyExponentiation     = 2
+	minLatencyFactor          = 0.01
 )
 
 // StorageHostManager related constant
@@ -26,6 +28,20 @@ const (
 	PersistFilename                  = "storagehostmanager.json"
 )
 
+// Snapshot related constant
+const (
+	SnapshotHeader   = "Storage Host Manager Snapshot"
+	SnapshotVersion  = "1.0"
+	snapshotRPCCall  = "sclient_hostSnapshot"
+	snapshotFetchTTL = time.Minute
+)
+
+// Scoring state related constant
+const (
+	ScoringHeader  = "Storage Host Manager Scoring State"
+	ScoringVersion = "1.0"
+)
+
 // Scan related constants
 const (
 	scanOnlineCheckDuration = 30 * time.Second
@@ -46,3 +62,19 @@ const (
 	historicInteractionDecayLimit = 500
 	recentInteractionWeightLimit  = 0.01
 )
+
+// interaction records related constants
+const (
+	// interactionHalfLife is the time for the weight of an interaction record to decay by half
+	interactionHalfLife = 3 * 24 * time.Hour
+
+	// interactionRecordExpiry is the time an interaction record will be kept
+	interactionRecordExpiry = 30 * 24 * time.Hour
+
+	// maxInteractionRecords is the maximum number of interaction records kept for a storage host
+	maxInteractionRecords = 500
+
+	// decayedInteractionBlendWeight is the sum of the decayed interactions at which they weigh
+	// the same as the historical interactions in the evaluation
+	decayedInteractionBlendWeight = 50
+)
@@ -0,0 +1,97 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehostmanager
+
+import (
+	"fmt"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/storagehosttree"
+)
+
+// GeoLocator resolves the geographic location of the storage host ip address, such as the
+// country or the data center region. The location is used as a diversity group along with
+// the ip network, false is returned if the location is unknown
+type GeoLocator interface {
+	Locate(ip string) (location string, ok bool)
+}
+
+// SetDiversityShare sets the maximum share of the storage hosts, as well as the maximum share
+// of the sectors of a segment, that can be in the same ip network or geographic location,
+// so that a single operator or data center outage cannot take out more than the share of
+// the sectors of a file. Share 0 disables the diversity constraint
+func (shm *StorageHostManager) SetDiversityShare(share float64) error {
+	if share < 0 || share > 1 {
+		return fmt.Errorf("diversity share must be within 0 and 1, got %v", share)
+	}
+	shm.lock.Lock()
+	defer shm.lock.Unlock()
+	shm.diversityShare = share
+	return nil
+}
+
+// RetrieveDiversityShare returns the maximum share of the storage hosts that can be in the
+// same diversity group, 0 if the diversity constraint is disabled
+func (shm *StorageHostManager) RetrieveDiversityShare() float64 {
+	shm.lock.RLock()
+	defer shm.lock.RUnlock()
+	return shm.diversityShare
+}
+
+// SetGeoLocator sets the geographic locator of the storage hosts. With the locator set, the
+// storage hosts in the same geographic location are also limited by the diversity share
+func (shm *StorageHostManager) SetGeoLocator(locator GeoLocator) {
+	shm.lock.Lock()
+	defer shm.lock.Unlock()
+	shm.geoLocator = locator
+}
+
+// MaxGroupSectors returns the maximum number of sectors of a segment that can be stored on
+// the storage hosts in the same diversity group, 0 if the diversity constraint is disabled
+func (shm *StorageHostManager) MaxGroupSectors(numSectors int) int {
+	return maxGroupMembers(shm.RetrieveDiversityShare(), numSectors)
+}
+
+// HostDiversityGroups returns the diversity groups of the storage host, nil if the storage
+// host is unknown
+func (shm *StorageHostManager) HostDiversityGroups(id enode.ID) []string {
+	shm.lock.RLock()
+	defer shm.lock.RUnlock()
+	hi, exists := shm.storageHostTree.RetrieveHostInfo(id)
+	if !exists {
+		return nil
+	}
+	return diversityGroups(shm.geoLocator)(hi)
+}
+
+// diversityGroups returns the function to get the ip network, and the geographic location
+// if the geographic locator is set, of the storage host
+func diversityGroups(locator GeoLocator) storagehosttree.GroupFunc {
+	return func(hi storage.HostInfo) []string {
+		groups := storagehosttree.IPNetworkGroups(hi)
+		if locator == nil {
+			return groups
+		}
+		if location, ok := locator.Locate(hi.IP); ok {
+			groups = append(groups, "geo:"+location)
+		}
+		return groups
+	}
+}
+
+// maxGroupMembers returns the maximum number of members out of total that can be in the same
+// diversity group with the diversity share, which is at least 1. 0 is returned if the
+// diversity constraint is disabled
+func maxGroupMembers(share float64, total int) int {
+	if share <= 0 {
+		return 0
+	}
+	max := int(share * float64(total))
+	if max < 1 {
+		max = 1
+	}
+	return max
+}
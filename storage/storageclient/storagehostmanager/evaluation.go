@@ -23,6 +23,8 @@ func (shm *StorageHostManager) calculateEvaluationFunc(rent storage.RentPayment)
 			ContractPriceFactor:    shm.contractPriceFactorCalc(info, rent),
 			StorageRemainingFactor: shm.storageRemainingFactorCalc(info),
 			UptimeFactor:           shm.uptimeFactorCalc(info),
+			LatencyFactor:          shm.latencyFactorCalc(info),
+			SybilFactor:            shm.sybilFactorCalc(info),
 		}
 	}
 }
@@ -97,14 +99,30 @@ func (shm *StorageHostManager) depositFactorCalc(info storage.HostInfo, rent sto
 }
 
 // interactionFactorCalc calculates the factor value based on the historical success interactions
-// and failed interactions. More success interactions will cause higher evaluation
+// and failed interactions. More success interactions will cause higher evaluation. If there are
+// timestamped interaction records, the decayed interactions are blended with the historical ones,
+// and the weight of the decayed interactions grows with the number of the recent records
 func (shm *StorageHostManager) interactionFactorCalc(info storage.HostInfo) float64 {
 	hs := info.HistoricSuccessfulInteractions + 30
 	hf := info.HistoricFailedInteractions + 1
 	ratio := hs / (hs + hf)
+	if success, failed, exists := shm.decayedHostInteractions(info.EnodeID); exists {
+		ratio = blendInteractionRatio(ratio, success, failed)
+	}
 	return math.Pow(ratio, interactionExponentiation)
 }
 
+// blendInteractionRatio blends the historical success ratio with the success ratio of the
+// decayed interactions. The decayed interactions weigh half when they add up to
+// decayedInteractionBlendWeight, so that a few recent failures do not wipe out a long history
+func blendInteractionRatio(historicRatio, success, failed float64) float64 {
+	ds := success + 30
+	df := failed + 1
+	decayedRatio := ds / (ds + df)
+	weight := (success + failed) / (success + failed + decayedInteractionBlendWeight)
+	return weight*decayedRatio + (1-weight)*historicRatio
+}
+
 // contractPriceFactorCalc calculates the factor value based on the contract price that storage host requested
 // the lower the price is, the higher the storage host evaluation will be
 func (shm *StorageHostManager) contractPriceFactorCalc(info storage.HostInfo, rent storage.RentPayment) float64 {
@@ -250,6 +268,24 @@ func (shm *StorageHostManager) uptimeEvaluation(info storage.HostInfo) float64 {
 	return math.Pow(uptimeRatio, exp)
 }
 
+// latencyFactorCalc will punish the storage host responsible for the tail latency of the
+// uploads. The factor drops with the upload slowdown of the storage host reported by the
+// storage client, and the storage host not slower than the others is not punished
+func (shm *StorageHostManager) latencyFactorCalc(info storage.HostInfo) float64 {
+	slowdown := shm.uploadSlowdown(info.EnodeID)
+	if slowdown <= 1 {
+		return 1
+	}
+	return math.Max(math.Pow(slowdown, -latencyExponentiation), minLatencyFactor)
+}
+
+// sybilFactorCalc will punish the storage hosts suspected to be run by a single operator. The
+// evaluation is shared by the storage hosts in the same cluster, so that the cluster weighs
+// no more than a single storage host in the selection
+func (shm *StorageHostManager) sybilFactorCalc(info storage.HostInfo) float64 {
+	return 1 / float64(shm.sybilClusterSize(info.EnodeID))
+}
+
 // rentPaymentValidation will validate the rent payment provided by the storage client
 // eliminate any zero values by changing them to one
 func rentPaymentValidation(rent storage.RentPayment) {
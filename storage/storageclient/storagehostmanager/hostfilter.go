@@ -5,8 +5,10 @@
 package storagehostmanager
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage/storageclient/storagehosttree"
@@ -33,6 +35,21 @@ func (shm *StorageHostManager) RetrieveFilterMode() (fm string) {
 	return shm.filterMode.String()
 }
 
+// RetrieveFilteredHosts will return the filter mode and the storage hosts in the
+// whitelist or blacklist, sorted by the enode ID
+func (shm *StorageHostManager) RetrieveFilteredHosts() (fm FilterMode, hosts []enode.ID) {
+	shm.lock.RLock()
+	defer shm.lock.RUnlock()
+
+	for id := range shm.filteredHosts {
+		hosts = append(hosts, id)
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		return bytes.Compare(hosts[i][:], hosts[j][:]) < 0
+	})
+	return shm.filterMode, hosts
+}
+
 // SetFilterMode will be used to set the host ip filter mode. Actions are required only
 // when the mode is set to be whitelist, meaning that only the storage host in both whitelist
 // and hostPool can be inserted into the filteredTree
@@ -0,0 +1,49 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehostmanager
+
+import (
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// UpdateHostRecord records the host record advertised in the node record. The record of the
+// storage host is dropped once its node record stops advertising it, so that the storage
+// host is not filtered by the stale record
+func (shm *StorageHostManager) UpdateHostRecord(node *enode.Node) {
+	if node == nil {
+		return
+	}
+	record, exists := storage.LoadHostRecord(node)
+
+	shm.recordLock.Lock()
+	defer shm.recordLock.Unlock()
+	if !exists {
+		delete(shm.hostRecords, node.ID())
+		return
+	}
+	shm.hostRecords[node.ID()] = record
+}
+
+// HostRecord returns the latest host record advertised by the storage host
+func (shm *StorageHostManager) HostRecord(id enode.ID) (record storage.HostRecord, exists bool) {
+	shm.recordLock.Lock()
+	defer shm.recordLock.Unlock()
+	record, exists = shm.hostRecords[id]
+	return
+}
+
+// rejectedByHostRecords returns the storage hosts advertising not to accept new contracts,
+// which are skipped when selecting the storage hosts to form the contracts with
+func (shm *StorageHostManager) rejectedByHostRecords() (ids []enode.ID) {
+	shm.recordLock.Lock()
+	defer shm.recordLock.Unlock()
+	for id, record := range shm.hostRecords {
+		if !record.Acceptable() {
+			ids = append(ids, id)
+		}
+	}
+	return
+}
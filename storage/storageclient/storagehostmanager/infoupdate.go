@@ -11,6 +11,7 @@ import (
 	"github.com/DxChainNetwork/godx/log"
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/chrono"
 )
 
 // hostInfoUpdate will update the storage host information based on the external settings
@@ -39,18 +40,19 @@ func (shm *StorageHostManager) hostInfoUpdate(hi storage.HostInfo, err error) {
 
 	// update scan record, make sure the scan record has at least two scans
 	if len(storedInfo.ScanRecords) < 2 {
-		earliestScanTime := time.Now().Add(time.Hour * 7 * 24 * -1)
-		suggestedScanTime := time.Now().Add(time.Minute * 10 * time.Duration(shm.blockHeight-hi.FirstSeen+1) * -1)
+		now := shm.clock.Now()
+		earliestScanTime := now.Add(time.Hour * 7 * 24 * -1)
+		suggestedScanTime := chrono.HeightTime(now, shm.blockHeight+1, hi.FirstSeen)
 		if suggestedScanTime.Before(earliestScanTime) {
 			suggestedScanTime = earliestScanTime
 		}
 
 		storedInfo.ScanRecords = storage.HostPoolScans{
 			{Timestamp: suggestedScanTime, Success: err == nil},
-			{Timestamp: time.Now(), Success: err == nil},
+			{Timestamp: now, Success: err == nil},
 		}
 	} else {
-		currentTimeStamp := time.Now()
+		currentTimeStamp := shm.clock.Now()
 		prevScanTime := storedInfo.ScanRecords[len(storedInfo.ScanRecords)-1].Timestamp
 		if prevScanTime.After(currentTimeStamp) {
 			currentTimeStamp = prevScanTime.Add(time.Second)
@@ -62,7 +64,7 @@ func (shm *StorageHostManager) hostInfoUpdate(hi storage.HostInfo, err error) {
 	// if the host is not upp and the exceed the max host downtime, then remove it and return
 	recentUp := err == nil
 	if !recentUp && len(storedInfo.ScanRecords) > minScans &&
-		time.Now().Sub(storedInfo.ScanRecords[0].Timestamp) > maxDowntime {
+		shm.clock.Since(storedInfo.ScanRecords[0].Timestamp) > maxDowntime {
 		err := shm.remove(storedInfo.EnodeID)
 		if err != nil {
 			log.Error("failed to remove the storage host from the tree", "hostID", storedInfo.EnodeID.String(), "err", err.Error())
@@ -74,7 +76,7 @@ func (shm *StorageHostManager) hostInfoUpdate(hi storage.HostInfo, err error) {
 	// update the historic uptime and historic downtime, and remove them from the
 	// scan records
 	for len(storedInfo.ScanRecords) > minScans &&
-		time.Now().Sub(storedInfo.ScanRecords[0].Timestamp) > maxDowntime {
+		shm.clock.Since(storedInfo.ScanRecords[0].Timestamp) > maxDowntime {
 		timePassed := storedInfo.ScanRecords[1].Timestamp.Sub(storedInfo.ScanRecords[0].Timestamp)
 		if storedInfo.ScanRecords[0].Success {
 			storedInfo.HistoricUptime += timePassed
@@ -166,7 +168,9 @@ func (shm *StorageHostManager) IncrementSuccessfulInteractions(id enode.ID) {
 	// update the historical interactions
 	hostHistoricInteractionsUpdate(&host, shm.blockHeight)
 
-	// update the recent successful interactions, recalculate the storage host evaluation
+	// record the interaction, update the recent successful interactions, and recalculate
+	// the storage host evaluation
+	shm.recordInteraction(id, true)
 	host.RecentSuccessfulInteractions++
 
 	if err := shm.storageHostTree.HostInfoUpdate(host); err != nil {
@@ -189,7 +193,9 @@ func (shm *StorageHostManager) IncrementFailedInteractions(id enode.ID) {
 	// update the historical interactions
 	hostHistoricInteractionsUpdate(&host, shm.blockHeight)
 
-	// update the recent failed interactions, recalculate the storage host evaluation
+	// record the interaction, update the recent failed interactions, and recalculate
+	// the storage host evaluation
+	shm.recordInteraction(id, false)
 	host.RecentFailedInteractions++
 	if err := shm.storageHostTree.HostInfoUpdate(host); err != nil {
 		shm.log.Error("failed to increment the failed interactions", "err", err.Error())
@@ -4,7 +4,11 @@
 
 package storagehostmanager
 
-import "testing"
+import (
+	"math"
+	"testing"
+	"time"
+)
 
 func TestStorageHostManager_IncrementSuccessfulInteractions(t *testing.T) {
 	shm := newHostManagerTestData()
@@ -46,3 +50,83 @@ func TestStorageHostManager_IncrementFailedInteractions(t *testing.T) {
 			hiUpdated.RecentFailedInteractions, hi.RecentFailedInteractions+1)
 	}
 }
+
+func TestStorageHostManager_InteractionHistory(t *testing.T) {
+	shm := newHostManagerTestData()
+	hi := hostInfoGenerator()
+
+	if err := shm.insert(hi); err != nil {
+		t.Fatalf("failed to insert data into the storage host tree")
+	}
+
+	shm.IncrementFailedInteractions(hi.EnodeID)
+	shm.IncrementSuccessfulInteractions(hi.EnodeID)
+	shm.IncrementSuccessfulInteractions(hi.EnodeID)
+
+	history := shm.interactionHistory(hi.EnodeID)
+	if len(history.Records) != 3 {
+		t.Fatalf("expected 3 interaction records, got %v", len(history.Records))
+	}
+	if history.Records[0].Success || !history.Records[1].Success || !history.Records[2].Success {
+		t.Errorf("interaction records are not recorded in order: %+v", history.Records)
+	}
+	if history.Success < 1.99 || history.Failed < 0.99 {
+		t.Errorf("recent interactions should barely decay, got success %v, failed %v", history.Success, history.Failed)
+	}
+}
+
+func TestDecayedInteractions(t *testing.T) {
+	now := time.Now()
+	records := []InteractionRecord{
+		{Timestamp: now.Add(-10 * interactionHalfLife), Success: false},
+		{Timestamp: now.Add(-interactionHalfLife), Success: false},
+		{Timestamp: now, Success: true},
+	}
+
+	success, failed := decayedInteractions(records, now)
+	if success != 1 {
+		t.Errorf("expected success weight 1, got %v", success)
+	}
+	expectedFailed := 0.5 + math.Pow(0.5, 10)
+	if math.Abs(failed-expectedFailed) > 1e-9 {
+		t.Errorf("expected failed weight %v, got %v", expectedFailed, failed)
+	}
+}
+
+func TestPruneInteractionRecords(t *testing.T) {
+	now := time.Now()
+	var records []InteractionRecord
+	records = append(records, InteractionRecord{Timestamp: now.Add(-2 * interactionRecordExpiry)})
+	for i := 0; i < maxInteractionRecords+10; i++ {
+		records = append(records, InteractionRecord{Timestamp: now, Success: true})
+	}
+
+	pruned := pruneInteractionRecords(records, now)
+	if len(pruned) != maxInteractionRecords {
+		t.Fatalf("expected %v records after pruning, got %v", maxInteractionRecords, len(pruned))
+	}
+	for _, record := range pruned {
+		if now.Sub(record.Timestamp) > interactionRecordExpiry {
+			t.Fatalf("expired record is not pruned")
+		}
+	}
+}
+
+func TestBlendInteractionRatio(t *testing.T) {
+	historicRatio := 1030.0 / 1031.0
+
+	// a single recent failure must not wipe out a long good history
+	ratio := blendInteractionRatio(historicRatio, 0, 1)
+	if ratio < historicRatio*0.99 {
+		t.Errorf("a single failure drops the ratio from %v to %v", historicRatio, ratio)
+	}
+
+	// a large number of recent failures dominates the history
+	ratio = blendInteractionRatio(historicRatio, 0, 1000)
+	if ratio > 0.2 {
+		t.Errorf("recent failures should dominate the history, got ratio %v", ratio)
+	}
+	if ratio >= blendInteractionRatio(historicRatio, 0, 10) {
+		t.Errorf("more recent failures should cause a lower ratio")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehostmanager
+
+import (
+	"math"
+	"time"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+)
+
+// InteractionRecord records a single interaction with the storage host
+type InteractionRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Success   bool      `json:"success"`
+}
+
+// InteractionHistory is the interaction history of the storage host, with the
+// successful and failed interactions weighted by exponential decay
+type InteractionHistory struct {
+	EnodeID  string              `json:"enodeid"`
+	Records  []InteractionRecord `json:"records"`
+	Success  float64             `json:"success"`
+	Failed   float64             `json:"failed"`
+	HalfLife time.Duration       `json:"halflife"`
+}
+
+// recordInteraction will append the interaction record of the storage host. Records
+// exceeding maxInteractionRecords or older than interactionRecordExpiry are dropped
+func (shm *StorageHostManager) recordInteraction(id enode.ID, success bool) {
+	shm.interactionLock.Lock()
+	defer shm.interactionLock.Unlock()
+
+	now := shm.clock.Now()
+	records := append(shm.interactionRecords[id], InteractionRecord{
+		Timestamp: now,
+		Success:   success,
+	})
+	shm.interactionRecords[id] = pruneInteractionRecords(records, now)
+}
+
+// interactionHistory will return the interaction history of the storage host
+func (shm *StorageHostManager) interactionHistory(id enode.ID) InteractionHistory {
+	shm.interactionLock.Lock()
+	defer shm.interactionLock.Unlock()
+
+	records := make([]InteractionRecord, len(shm.interactionRecords[id]))
+	copy(records, shm.interactionRecords[id])
+	success, failed := decayedInteractions(records, shm.clock.Now())
+
+	return InteractionHistory{
+		EnodeID:  id.String(),
+		Records:  records,
+		Success:  success,
+		Failed:   failed,
+		HalfLife: interactionHalfLife,
+	}
+}
+
+// decayedHostInteractions returns the decayed successful and failed interactions of
+// the storage host. If there are no interaction records, false will be returned
+func (shm *StorageHostManager) decayedHostInteractions(id enode.ID) (success, failed float64, exists bool) {
+	shm.interactionLock.Lock()
+	defer shm.interactionLock.Unlock()
+
+	records, exists := shm.interactionRecords[id]
+	if !exists || len(records) == 0 {
+		return 0, 0, false
+	}
+	success, failed = decayedInteractions(records, shm.clock.Now())
+	return success, failed, true
+}
+
+// removeInteractionRecords will remove the interaction records of the storage host
+func (shm *StorageHostManager) removeInteractionRecords(id enode.ID) {
+	shm.interactionLock.Lock()
+	defer shm.interactionLock.Unlock()
+	delete(shm.interactionRecords, id)
+}
+
+// decayedInteractions sums up the successful and failed interactions, each interaction is
+// weighted by exponential decay based on its age, so that the old failures stop penalizing
+// the recovered storage hosts
+func decayedInteractions(records []InteractionRecord, now time.Time) (success, failed float64) {
+	for _, record := range records {
+		age := now.Sub(record.Timestamp)
+		if age < 0 {
+			age = 0
+		}
+		weight := math.Pow(0.5, float64(age)/float64(interactionHalfLife))
+		if record.Success {
+			success += weight
+		} else {
+			failed += weight
+		}
+	}
+	return
+}
+
+// pruneInteractionRecords drops the expired records and the oldest records exceeding
+// the maxInteractionRecords
+func pruneInteractionRecords(records []InteractionRecord, now time.Time) []InteractionRecord {
+	start := 0
+	for start < len(records) && now.Sub(records[start].Timestamp) > interactionRecordExpiry {
+		start++
+	}
+	if len(records)-start > maxInteractionRecords {
+		start = len(records) - maxInteractionRecords
+	}
+	return records[start:]
+}
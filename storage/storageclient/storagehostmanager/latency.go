@@ -0,0 +1,54 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehostmanager
+
+import (
+	"github.com/DxChainNetwork/godx/p2p/enode"
+)
+
+// SetUploadSlowdown sets the upload slowdown of the storage host, which is the ratio of the
+// tail upload latency of the storage host to the tail upload latency of all storage hosts
+// measured by the storage client. The storage host evaluation will be recalculated
+func (shm *StorageHostManager) SetUploadSlowdown(id enode.ID, slowdown float64) {
+	shm.latencyLock.Lock()
+	previous := shm.uploadSlowdowns[id]
+	if slowdown > 1 {
+		shm.uploadSlowdowns[id] = slowdown
+	} else {
+		delete(shm.uploadSlowdowns, id)
+	}
+	shm.latencyLock.Unlock()
+
+	// neither the previous nor the current slowdown punishes the storage host
+	if previous <= 1 && slowdown <= 1 {
+		return
+	}
+
+	shm.lock.Lock()
+	defer shm.lock.Unlock()
+
+	host, exists := shm.storageHostTree.RetrieveHostInfo(id)
+	if !exists {
+		return
+	}
+	if err := shm.storageHostTree.HostInfoUpdate(host); err != nil {
+		shm.log.Error("failed to update the upload slowdown", "err", err.Error())
+	}
+}
+
+// uploadSlowdown returns the upload slowdown of the storage host, 0 will be returned if the
+// storage host is not slower than the others
+func (shm *StorageHostManager) uploadSlowdown(id enode.ID) float64 {
+	shm.latencyLock.Lock()
+	defer shm.latencyLock.Unlock()
+	return shm.uploadSlowdowns[id]
+}
+
+// removeUploadSlowdown will remove the upload slowdown of the storage host
+func (shm *StorageHostManager) removeUploadSlowdown(id enode.ID) {
+	shm.latencyLock.Lock()
+	defer shm.latencyLock.Unlock()
+	delete(shm.uploadSlowdowns, id)
+}
@@ -0,0 +1,38 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehostmanager
+
+import (
+	"testing"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestStorageHostManager_LatencyFactorCalc(t *testing.T) {
+	shm := New("test")
+	info := storage.HostInfo{EnodeID: enode.ID{1}}
+
+	tables := []struct {
+		slowdown float64
+		factor   float64
+	}{
+		{0, 1},
+		{0.5, 1},
+		{2, 0.25},
+		{1000, minLatencyFactor},
+	}
+	for _, table := range tables {
+		shm.SetUploadSlowdown(info.EnodeID, table.slowdown)
+		if factor := shm.latencyFactorCalc(info); factor != table.factor {
+			t.Errorf("slowdown %v: expected latency factor %v, got %v", table.slowdown, table.factor, factor)
+		}
+	}
+
+	shm.removeUploadSlowdown(info.EnodeID)
+	if factor := shm.latencyFactorCalc(info); factor != 1 {
+		t.Errorf("expected latency factor 1 after removal, got %v", factor)
+	}
+}
@@ -7,7 +7,6 @@ package storagehostmanager
 import (
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/DxChainNetwork/godx/common"
 	"github.com/DxChainNetwork/godx/log"
@@ -28,8 +27,11 @@ type persistence struct {
 	StorageHostsInfo []storage.HostInfo
 	BlockHeight      uint64
 	IPViolationCheck bool
+	DiversityShare   float64
 	FilteredHosts    map[enode.ID]struct{}
 	FilterMode       FilterMode
+
+	InteractionRecords map[enode.ID][]InteractionRecord
 }
 
 // saveSettings will save the storage host configurations into the JSON file
@@ -41,12 +43,22 @@ func (shm *StorageHostManager) saveSettings() error {
 // persistUpdate contains the information that needs to be written into the
 // json file
 func (shm *StorageHostManager) persistUpdate() (persist persistence) {
+	shm.interactionLock.Lock()
+	defer shm.interactionLock.Unlock()
+
+	interactionRecords := make(map[enode.ID][]InteractionRecord)
+	for id, records := range shm.interactionRecords {
+		interactionRecords[id] = pruneInteractionRecords(records, shm.clock.Now())
+	}
+
 	return persistence{
-		StorageHostsInfo: shm.storageHostTree.All(),
-		BlockHeight:      shm.blockHeight,
-		IPViolationCheck: shm.ipViolationCheck,
-		FilteredHosts:    shm.filteredHosts,
-		FilterMode:       shm.filterMode,
+		StorageHostsInfo:   shm.storageHostTree.All(),
+		BlockHeight:        shm.blockHeight,
+		IPViolationCheck:   shm.ipViolationCheck,
+		DiversityShare:     shm.diversityShare,
+		FilteredHosts:      shm.filteredHosts,
+		FilterMode:         shm.filterMode,
+		InteractionRecords: interactionRecords,
 	}
 }
 
@@ -64,7 +76,7 @@ func (shm *StorageHostManager) autoSaveSettings() {
 		select {
 		case <-shm.tm.StopChan():
 			return
-		case <-time.After(saveFrequency):
+		case <-shm.clock.After(saveFrequency):
 			shm.lock.Lock()
 			err := shm.saveSettings()
 			shm.lock.Unlock()
@@ -94,8 +106,14 @@ func (shm *StorageHostManager) loadSettings() error {
 	// assign those values to StorageHostManager
 	shm.blockHeight = persist.BlockHeight
 	shm.ipViolationCheck = persist.IPViolationCheck
+	shm.diversityShare = persist.DiversityShare
 	shm.filteredHosts = persist.FilteredHosts
 	shm.filterMode = persist.FilterMode
+	if persist.InteractionRecords != nil {
+		shm.interactionLock.Lock()
+		shm.interactionRecords = persist.InteractionRecords
+		shm.interactionLock.Unlock()
+	}
 
 	// update the storage host tree
 	for _, info := range persist.StorageHostsInfo {
@@ -52,6 +52,9 @@ func (shm *StorageHostManager) scan() {
 // into the scanning queue, prepare to be scanned
 func (shm *StorageHostManager) autoScan() {
 	for {
+		// detect the suspicious storage host clusters with the latest host announcements
+		shm.updateSybilClusters()
+
 		var onlineHosts, offlineHosts []storage.HostInfo
 		allStorageHosts := shm.storageHostTree.All()
 		for _, host := range allStorageHosts {
@@ -94,7 +97,7 @@ func (shm *StorageHostManager) autoScan() {
 		select {
 		case <-shm.tm.StopChan():
 			return
-		case <-time.After(randomSleepTime):
+		case <-shm.clock.After(randomSleepTime):
 		}
 	}
 }
@@ -208,7 +211,7 @@ func (shm *StorageHostManager) updateHostConfig(hi storage.HostInfo) {
 
 	if err == nil && ipnet.String() != hi.IPNetwork {
 		hi.IPNetwork = ipnet.String()
-		hi.LastIPNetWorkChange = time.Now()
+		hi.LastIPNetWorkChange = shm.clock.Now()
 	} else if err != nil {
 		shm.log.Error("failed to get the IP network information", "err", err.Error())
 	}
@@ -260,7 +263,7 @@ func (shm *StorageHostManager) waitOnline() error {
 		}
 
 		select {
-		case <-time.After(scanOnlineCheckDuration):
+		case <-shm.clock.After(scanOnlineCheckDuration):
 		case <-shm.tm.StopChan():
 			return fmt.Errorf("program terminated")
 		}
@@ -279,7 +282,7 @@ func (shm *StorageHostManager) waitSync() error {
 		select {
 		case <-shm.tm.StopChan():
 			return fmt.Errorf("program terminated")
-		case <-time.After(scanCheckDuration):
+		case <-shm.clock.After(scanCheckDuration):
 		}
 	}
 	return nil
@@ -300,7 +303,7 @@ func (shm *StorageHostManager) waitScanFinish() error {
 		select {
 		case <-shm.tm.StopChan():
 			return fmt.Errorf("program terminated")
-		case <-time.After(scanCheckDuration):
+		case <-shm.clock.After(scanCheckDuration):
 		}
 	}
 
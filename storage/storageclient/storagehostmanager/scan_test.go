@@ -19,6 +19,7 @@ import (
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/params"
 	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/chrono"
 	"github.com/DxChainNetwork/godx/storage/storageclient/storagehosttree"
 )
 
@@ -106,6 +107,10 @@ func newHostManagerTestData() *StorageHostManager {
 		rent:          storage.DefaultRentPayment,
 		scanLookup:    make(map[enode.ID]struct{}),
 		filteredHosts: make(map[enode.ID]struct{}),
+		clock:         chrono.System,
+
+		interactionRecords: make(map[enode.ID][]InteractionRecord),
+		uploadSlowdowns:    make(map[enode.ID]float64),
 	}
 
 	shm.evalFunc = shm.calculateEvaluationFunc(shm.rent)
@@ -182,10 +187,18 @@ func (st *storageClientBackendTestData) SendStorageContractCreateTx(clientAddr c
 	return common.Hash{}, nil
 }
 
+func (st *storageClientBackendTestData) BumpStorageContractTx(from common.Address, txHash common.Hash, priceBump uint64, maxGasPrice *big.Int) (common.Hash, error) {
+	return common.Hash{}, nil
+}
+
 func (st *storageClientBackendTestData) GetHostAnnouncementWithBlockHash(blockHash common.Hash) (hostAnnouncements []types.HostAnnouncement, number uint64, errGet error) {
 	return
 }
 
+func (st *storageClientBackendTestData) GetHostRevocationWithBlockHash(blockHash common.Hash) ([]types.HostRevocation, error) {
+	return nil, nil
+}
+
 func (st *storageClientBackendTestData) TryToRenewOrRevise(hostID enode.ID) bool {
 	return false
 }
@@ -0,0 +1,138 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package storagehostmanager
+
+import (
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/storagehosttree"
+)
+
+// scoringMetadata contains the header and version of the exported scoring state file
+var scoringMetadata = common.Metadata{
+	Header:  ScoringHeader,
+	Version: ScoringVersion,
+}
+
+// HostScoringState is the full scoring state of the storage host manager, which is exported by
+// the operator to move the storage host scores to another node of its own, or to analyze the
+// storage host selection offline. Unlike the snapshot, the scoring state is not signed, and the
+// scores imported replace the local ones
+type HostScoringState struct {
+	BlockHeight uint64      `json:"blockHeight"`
+	ExportedAt  time.Time   `json:"exportedAt"`
+	Hosts       []HostScore `json:"hosts"`
+}
+
+// HostScore is the scoring state of a storage host. The evaluation is calculated when exported
+// for the offline analysis, and is ignored when imported
+type HostScore struct {
+	storage.HostInfo
+
+	Evaluation         storagehosttree.EvaluationDetail `json:"evaluation"`
+	InteractionRecords []InteractionRecord              `json:"interactionRecords"`
+	UploadSlowdown     float64                          `json:"uploadSlowdown"`
+}
+
+// DumpScoringState returns the scoring state of all storage hosts known
+func (shm *StorageHostManager) DumpScoringState() HostScoringState {
+	shm.lock.RLock()
+	blockHeight := shm.blockHeight
+	shm.lock.RUnlock()
+
+	state := HostScoringState{
+		BlockHeight: blockHeight,
+		ExportedAt:  shm.clock.Now(),
+	}
+	for _, info := range shm.storageHostTree.All() {
+		eval := shm.evalFunc(info)
+
+		shm.interactionLock.Lock()
+		records := pruneInteractionRecords(shm.interactionRecords[info.EnodeID], shm.clock.Now())
+		shm.interactionLock.Unlock()
+
+		state.Hosts = append(state.Hosts, HostScore{
+			HostInfo:           info,
+			Evaluation:         eval.EvaluationDetail(eval.Evaluation(), false, false),
+			InteractionRecords: records,
+			UploadSlowdown:     shm.uploadSlowdown(info.EnodeID),
+		})
+	}
+	return state
+}
+
+// ExportScoringState exports the scoring state of all storage hosts known to the file
+func (shm *StorageHostManager) ExportScoringState(path string) error {
+	return common.SaveDxJSON(scoringMetadata, path, shm.DumpScoringState())
+}
+
+// ImportScoringStateFile loads the scoring state from the file and imports it
+func (shm *StorageHostManager) ImportScoringStateFile(path string) (imported int, err error) {
+	var state HostScoringState
+	if err = common.LoadDxJSON(scoringMetadata, path, &state); err != nil {
+		return
+	}
+	return shm.ImportScoringState(state)
+}
+
+// ImportScoringState imports the scoring state. The scores of the storage hosts already known
+// are replaced by the imported ones, while their host settings are kept. The storage hosts not
+// known are added and scanned. The block height is left untouched, which is only updated by the
+// chain changes
+func (shm *StorageHostManager) ImportScoringState(state HostScoringState) (imported int, err error) {
+	now := shm.clock.Now()
+	for _, score := range state.Hosts {
+		id := score.EnodeID
+		if stored, exists := shm.storageHostTree.RetrieveHostInfo(id); exists {
+			copyHostScore(&stored, score.HostInfo)
+			shm.lock.Lock()
+			err := shm.modify(stored)
+			shm.lock.Unlock()
+			if err != nil {
+				shm.log.Warn("failed to import the storage host score", "id", id, "err", err)
+				continue
+			}
+		} else {
+			if err := shm.insert(score.HostInfo); err != nil {
+				shm.log.Warn("failed to import the storage host score", "id", id, "err", err)
+				continue
+			}
+			// refresh the cached settings of the storage host
+			shm.scanValidation(score.HostInfo)
+		}
+		imported++
+
+		shm.interactionLock.Lock()
+		if records := pruneInteractionRecords(score.InteractionRecords, now); len(records) != 0 {
+			shm.interactionRecords[id] = records
+		} else {
+			delete(shm.interactionRecords, id)
+		}
+		shm.interactionLock.Unlock()
+
+		shm.SetUploadSlowdown(id, score.UploadSlowdown)
+	}
+
+	shm.lock.Lock()
+	defer shm.lock.Unlock()
+	err = shm.saveSettings()
+	return
+}
+
+// copyHostScore copies the fields of the storage host info the evaluation is based on, other
+// than the host settings
+func copyHostScore(dst *storage.HostInfo, src storage.HostInfo) {
+	dst.FirstSeen = src.FirstSeen
+	dst.HistoricDowntime = src.HistoricDowntime
+	dst.HistoricUptime = src.HistoricUptime
+	dst.ScanRecords = src.ScanRecords
+	dst.HistoricFailedInteractions = src.HistoricFailedInteractions
+	dst.HistoricSuccessfulInteractions = src.HistoricSuccessfulInteractions
+	dst.RecentFailedInteractions = src.RecentFailedInteractions
+	dst.RecentSuccessfulInteractions = src.RecentSuccessfulInteractions
+	dst.LastHistoricUpdate = src.LastHistoricUpdate
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package storagehostmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStorageHostManager_ImportScoringState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shmscoring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := newHostManagerTestData()
+	src.blockHeight = 100
+	scored, unknown := hostInfoGenerator(), hostInfoGenerator()
+	scored.HistoricUptime, scored.HistoricDowntime = 10*time.Hour, time.Hour
+	if err := src.insert(scored); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.insert(unknown); err != nil {
+		t.Fatal(err)
+	}
+	src.interactionRecords[scored.EnodeID] = []InteractionRecord{{Timestamp: time.Now(), Success: true}}
+	src.SetUploadSlowdown(scored.EnodeID, 2)
+
+	path := filepath.Join(dir, "scoring.json")
+	if err := src.ExportScoringState(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// the host settings of the storage host known are kept, while the scores are replaced
+	dst := newHostManagerTestData()
+	dst.persistDir = dir
+	dst.blockHeight = 10
+	local := scored
+	local.IP = "10.0.0.1"
+	local.HistoricUptime, local.HistoricDowntime = 0, 0
+	if err := dst.insert(local); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := dst.ImportScoringStateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 2 {
+		t.Errorf("imported hosts not expected. Expect 2, Got %v", imported)
+	}
+	if len(dst.storageHostTree.All()) != 2 {
+		t.Errorf("hosts count not expected. Expect 2, Got %v", len(dst.storageHostTree.All()))
+	}
+	info, exists := dst.storageHostTree.RetrieveHostInfo(scored.EnodeID)
+	if !exists {
+		t.Fatal("the storage host imported not found")
+	}
+	if info.IP != local.IP {
+		t.Errorf("host IP not expected. Expect %v, Got %v", local.IP, info.IP)
+	}
+	if info.HistoricUptime != scored.HistoricUptime || info.HistoricDowntime != scored.HistoricDowntime {
+		t.Errorf("host scores not imported. Got uptime %v, downtime %v", info.HistoricUptime, info.HistoricDowntime)
+	}
+	if len(dst.interactionRecords[scored.EnodeID]) != 1 {
+		t.Errorf("interaction records not imported. Got %v", dst.interactionRecords[scored.EnodeID])
+	}
+	if slowdown := dst.uploadSlowdown(scored.EnodeID); slowdown != 2 {
+		t.Errorf("upload slowdown not expected. Expect 2, Got %v", slowdown)
+	}
+	if dst.blockHeight != 10 {
+		t.Errorf("block height not expected. Expect 10, Got %v", dst.blockHeight)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package storagehostmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DxChainNetwork/godx/accounts"
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/common/hexutil"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/rpc"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// snapshotMetadata contains the header and version of the exported snapshot file
+var snapshotMetadata = common.Metadata{
+	Header:  SnapshotHeader,
+	Version: SnapshotVersion,
+}
+
+// HostDBSnapshot is the signed snapshot of the storage host manager database, including the
+// host announcements, the evaluation related records, and the cached host settings. A new
+// storage client can be bootstrapped from the snapshot exported by a trusted node, instead of
+// scanning the whole chain for the host announcements
+type HostDBSnapshot struct {
+	BlockHeight        uint64                           `json:"blockHeight"`
+	CreatedAt          time.Time                        `json:"createdAt"`
+	StorageHostsInfo   []storage.HostInfo               `json:"storageHostsInfo"`
+	InteractionRecords map[enode.ID][]InteractionRecord `json:"interactionRecords"`
+	Signer             common.Address                   `json:"signer"`
+	Signature          hexutil.Bytes                    `json:"signature"`
+}
+
+// Hash returns the hash of the snapshot signed by the signer. The signature itself is excluded
+func (s HostDBSnapshot) Hash() (common.Hash, error) {
+	s.Signature = nil
+	blob, err := json.Marshal(s)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(blob), nil
+}
+
+// Verify checks whether the snapshot is signed by the trusted signer
+func (s HostDBSnapshot) Verify(trusted common.Address) error {
+	if s.Signer != trusted {
+		return fmt.Errorf("snapshot signer %v is not trusted", s.Signer.String())
+	}
+	hash, err := s.Hash()
+	if err != nil {
+		return err
+	}
+	pk, err := crypto.SigToPub(hash.Bytes(), s.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to recover the snapshot signer: %s", err.Error())
+	}
+	if crypto.PubkeyToAddress(*pk) != s.Signer {
+		return errors.New("snapshot signature does not match the signer")
+	}
+	return nil
+}
+
+// CreateSnapshot creates the snapshot of the storage host manager database, signed by the
+// payment address of the storage client
+func (shm *StorageHostManager) CreateSnapshot() (snapshot HostDBSnapshot, err error) {
+	signer, err := shm.b.GetPaymentAddress()
+	if err != nil {
+		return
+	}
+
+	shm.lock.RLock()
+	persist := shm.persistUpdate()
+	shm.lock.RUnlock()
+
+	snapshot = HostDBSnapshot{
+		BlockHeight:        persist.BlockHeight,
+		CreatedAt:          shm.clock.Now(),
+		StorageHostsInfo:   persist.StorageHostsInfo,
+		InteractionRecords: persist.InteractionRecords,
+		Signer:             signer,
+	}
+	hash, err := snapshot.Hash()
+	if err != nil {
+		return
+	}
+
+	account := accounts.Account{Address: signer}
+	wallet, err := shm.b.AccountManager().Find(account)
+	if err != nil {
+		return
+	}
+	snapshot.Signature, err = wallet.SignHash(account, hash.Bytes())
+	return
+}
+
+// ExportSnapshot exports the signed snapshot of the storage host manager database to the file
+func (shm *StorageHostManager) ExportSnapshot(path string) error {
+	snapshot, err := shm.CreateSnapshot()
+	if err != nil {
+		return err
+	}
+	return common.SaveDxJSON(snapshotMetadata, path, snapshot)
+}
+
+// ImportSnapshotFile loads the snapshot from the file, and imports it if signed by the trusted signer
+func (shm *StorageHostManager) ImportSnapshotFile(path string, trusted common.Address) (imported int, err error) {
+	var snapshot HostDBSnapshot
+	if err = common.LoadDxJSON(snapshotMetadata, path, &snapshot); err != nil {
+		return
+	}
+	return shm.ImportSnapshot(snapshot, trusted)
+}
+
+// SyncSnapshot requests the snapshot from the trusted peer through its RPC endpoint, and imports
+// it if signed by the trusted signer. The request is bounded by both the context and snapshotFetchTTL
+func (shm *StorageHostManager) SyncSnapshot(ctx context.Context, rawurl string, trusted common.Address) (imported int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, snapshotFetchTTL)
+	defer cancel()
+
+	client, err := rpc.DialContext(ctx, rawurl)
+	if err != nil {
+		return
+	}
+	defer client.Close()
+
+	var snapshot HostDBSnapshot
+	if err = client.CallContext(ctx, &snapshot, snapshotRPCCall); err != nil {
+		return
+	}
+	return shm.ImportSnapshot(snapshot, trusted)
+}
+
+// ImportSnapshot verifies the snapshot and merges it into the storage host manager database.
+// The storage hosts already known are kept untouched. The block height of the snapshot is not
+// imported, it is driven by the chain events only
+func (shm *StorageHostManager) ImportSnapshot(snapshot HostDBSnapshot, trusted common.Address) (imported int, err error) {
+	if err = snapshot.Verify(trusted); err != nil {
+		return
+	}
+
+	for _, info := range snapshot.StorageHostsInfo {
+		if _, exists := shm.storageHostTree.RetrieveHostInfo(info.EnodeID); exists {
+			continue
+		}
+		if err := shm.insert(info); err != nil {
+			shm.log.Warn("failed to import the storage host from snapshot", "id", info.EnodeID, "err", err)
+			continue
+		}
+		imported++
+
+		shm.interactionLock.Lock()
+		if _, exists := shm.interactionRecords[info.EnodeID]; !exists && len(snapshot.InteractionRecords[info.EnodeID]) != 0 {
+			shm.interactionRecords[info.EnodeID] = pruneInteractionRecords(snapshot.InteractionRecords[info.EnodeID], shm.clock.Now())
+		}
+		shm.interactionLock.Unlock()
+
+		// refresh the cached settings of the storage host
+		shm.scanValidation(info)
+	}
+
+	shm.lock.Lock()
+	defer shm.lock.Unlock()
+	err = shm.saveSettings()
+	return
+}
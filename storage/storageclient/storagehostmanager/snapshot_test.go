@@ -0,0 +1,87 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package storagehostmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// signedSnapshot returns the snapshot signed by a newly generated key
+func signedSnapshot(t *testing.T, hosts []storage.HostInfo) HostDBSnapshot {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := HostDBSnapshot{
+		BlockHeight:      100,
+		CreatedAt:        time.Now(),
+		StorageHostsInfo: hosts,
+		Signer:           crypto.PubkeyToAddress(key.PublicKey),
+	}
+	hash, err := snapshot.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Signature, err = crypto.Sign(hash.Bytes(), key); err != nil {
+		t.Fatal(err)
+	}
+	return snapshot
+}
+
+func TestHostDBSnapshot_Verify(t *testing.T) {
+	snapshot := signedSnapshot(t, []storage.HostInfo{hostInfoGenerator()})
+	if err := snapshot.Verify(snapshot.Signer); err != nil {
+		t.Fatalf("failed to verify the snapshot: %v", err)
+	}
+
+	// untrusted signer
+	untrusted := signedSnapshot(t, nil)
+	if err := snapshot.Verify(untrusted.Signer); err == nil {
+		t.Fatal("snapshot signed by untrusted signer should not pass the verification")
+	}
+
+	// tampered snapshot
+	snapshot.BlockHeight++
+	if err := snapshot.Verify(snapshot.Signer); err == nil {
+		t.Fatal("tampered snapshot should not pass the verification")
+	}
+}
+
+func TestStorageHostManager_ImportSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shmsnapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	shm := newHostManagerTestData()
+	shm.persistDir = dir
+	shm.blockHeight = 10
+	existing := hostInfoGenerator()
+	if err := shm.insert(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := signedSnapshot(t, []storage.HostInfo{existing, hostInfoGenerator(), hostInfoGenerator()})
+	imported, err := shm.ImportSnapshot(snapshot, snapshot.Signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 2 {
+		t.Errorf("imported hosts not expected. Expect 2, Got %v", imported)
+	}
+	if len(shm.storageHostTree.All()) != 3 {
+		t.Errorf("hosts count not expected. Expect 3, Got %v", len(shm.storageHostTree.All()))
+	}
+	if shm.blockHeight != 10 {
+		t.Errorf("the block height should not be imported from the snapshot. Expect 10, Got %v", shm.blockHeight)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/DxChainNetwork/godx/log"
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/chrono"
 	"github.com/DxChainNetwork/godx/storage/storageclient/storagehosttree"
 )
 
@@ -33,6 +34,10 @@ type StorageHostManager struct {
 	// ip violation check
 	ipViolationCheck bool
 
+	// the maximum share of the storage hosts in the same ip network or geographic location
+	diversityShare float64
+	geoLocator     GeoLocator
+
 	// maintenance related
 	initialScan     bool
 	scanWaitList    []storage.HostInfo
@@ -44,9 +49,10 @@ type StorageHostManager struct {
 	persistDir string
 
 	// utils
-	log  log.Logger
-	lock sync.RWMutex
-	tm   threadmanager.ThreadManager
+	log   log.Logger
+	lock  sync.RWMutex
+	tm    threadmanager.ThreadManager
+	clock chrono.Clock
 
 	// filter mode related
 	filterMode    FilterMode
@@ -54,6 +60,23 @@ type StorageHostManager struct {
 	filteredTree  *storagehosttree.StorageHostTree
 
 	blockHeight uint64
+
+	// timestamped interaction records of the storage hosts
+	interactionRecords map[enode.ID][]InteractionRecord
+	interactionLock    sync.Mutex
+
+	// upload slowdown of the storage hosts reported by the storage client
+	uploadSlowdowns map[enode.ID]float64
+	latencyLock     sync.Mutex
+
+	// suspicious storage host clusters, and the index of the cluster of each storage host
+	sybilClusters []SybilCluster
+	sybilIndex    map[enode.ID]int
+	sybilLock     sync.Mutex
+
+	// the host records advertised in the node records of the storage hosts
+	hostRecords map[enode.ID]storage.HostRecord
+	recordLock  sync.Mutex
 }
 
 // New will initialize HostPoolManager, making the host pool stay updated
@@ -65,6 +88,12 @@ func New(persistDir string) *StorageHostManager {
 		scanLookup:    make(map[enode.ID]struct{}),
 		filterMode:    DisableFilter,
 		filteredHosts: make(map[enode.ID]struct{}),
+		clock:         chrono.System,
+
+		interactionRecords: make(map[enode.ID][]InteractionRecord),
+		uploadSlowdowns:    make(map[enode.ID]float64),
+		sybilIndex:         make(map[enode.ID]int),
+		hostRecords:        make(map[enode.ID]storage.HostRecord),
 	}
 
 	shm.evalFunc = shm.calculateEvaluationFunc(shm.rent)
@@ -261,6 +290,8 @@ func (shm *StorageHostManager) RetrieveRandomHosts(num int, blacklist, addrBlack
 	shm.lock.RLock()
 	initScan := shm.initialScan
 	ipCheck := shm.ipViolationCheck
+	maxPerGroup := maxGroupMembers(shm.diversityShare, int(shm.rent.StorageHosts))
+	groups := diversityGroups(shm.geoLocator)
 	shm.lock.RUnlock()
 
 	// if the initialize scan is not complete
@@ -269,13 +300,22 @@ func (shm *StorageHostManager) RetrieveRandomHosts(num int, blacklist, addrBlack
 		return
 	}
 
-	// select random
-	if ipCheck {
+	// the storage hosts advertising not to accept new contracts are not selected
+	blacklist = append(shm.rejectedByHostRecords(), blacklist...)
+
+	// select random. With the diversity constraint, the storage hosts in the addrBlacklist
+	// are counted in their diversity groups, along with the storage hosts selected
+	switch {
+	case ipCheck:
 		infos = shm.filteredTree.SelectRandom(num, blacklist, addrBlacklist)
-	} else {
+	case maxPerGroup != 0:
+		infos = shm.filteredTree.SelectRandomDiverse(num, blacklist, addrBlacklist, maxPerGroup, groups)
+	default:
 		infos = shm.filteredTree.SelectRandom(num, blacklist, nil)
 	}
 
+	// the storage hosts in the same suspicious cluster are collapsed into one logical host
+	infos = shm.collapseSybilClusters(infos, blacklist)
 	return
 }
 
@@ -354,6 +394,8 @@ func (shm *StorageHostManager) insert(hi storage.HostInfo) error {
 // remove will remove the host information from the storageHostTree
 func (shm *StorageHostManager) remove(enodeid enode.ID) error {
 	err := shm.storageHostTree.Remove(enodeid)
+	shm.removeInteractionRecords(enodeid)
+	shm.removeUploadSlowdown(enodeid)
 	_, exists := shm.filteredHosts[enodeid]
 
 	if exists && shm.filterMode == WhitelistFilter {
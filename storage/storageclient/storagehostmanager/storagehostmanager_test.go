@@ -9,9 +9,12 @@ import (
 	"github.com/DxChainNetwork/godx/common"
 	"github.com/DxChainNetwork/godx/storage"
 	"github.com/Pallinder/go-randomdata"
+	"net"
 	"testing"
 	"time"
 
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/crypto"
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage/storageclient/storagehosttree"
 )
@@ -71,6 +74,38 @@ func TestStorageHostManager_Remove(t *testing.T) {
 	}
 }
 
+func TestStorageHostManager_RemoveRevokedHosts(t *testing.T) {
+	shm := New("test")
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	netAddress := enode.NewV4(&key.PublicKey, net.IP{127, 0, 0, 1}, 8888, 8888).String()
+	info, err := parseHostAnnouncement(types.HostAnnouncement{NetAddress: netAddress})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shm.insert(info); err != nil {
+		t.Fatal(err)
+	}
+
+	// the invalid and unknown revocations are skipped
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherAddress := enode.NewV4(&otherKey.PublicKey, net.IP{127, 0, 0, 1}, 8888, 8888).String()
+	shm.RemoveRevokedHosts([]types.HostRevocation{{NetAddress: "invalid"}, {NetAddress: otherAddress}})
+	if _, exist := shm.storageHostTree.RetrieveHostInfo(info.EnodeID); !exist {
+		t.Fatal("the storage host shall not be removed by the revocations of others")
+	}
+
+	shm.RemoveRevokedHosts([]types.HostRevocation{{NetAddress: netAddress}})
+	if _, exist := shm.storageHostTree.RetrieveHostInfo(info.EnodeID); exist {
+		t.Error("the revoked storage host shall be removed")
+	}
+}
+
 func TestStorageHostManager_FilterIPViolationHosts(t *testing.T) {
 	unsavedHost := hostInfoGeneratorForIPViolation(randomdata.IpV4Address(), time.Now())
 	hostEarlierIP := hostInfoGeneratorForIPViolation("196.5.4.3", time.Now())
@@ -0,0 +1,222 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehostmanager
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// The storage hosts announced with the same payment address, the same node key, or the same
+// ip address are likely run by a single operator. They are collapsed into one logical storage
+// host: the evaluation of each of them is divided by the size of the cluster, and at most one
+// of them is selected for the contracts.
+
+// SybilCluster is a group of storage hosts suspected to be run by a single operator, along
+// with the reasons they are linked
+type SybilCluster struct {
+	Hosts   []enode.ID `json:"hosts"`
+	Reasons []string   `json:"reasons"`
+}
+
+// SybilClusters returns the suspicious storage host clusters detected
+func (shm *StorageHostManager) SybilClusters() []SybilCluster {
+	shm.sybilLock.Lock()
+	defer shm.sybilLock.Unlock()
+	clusters := make([]SybilCluster, len(shm.sybilClusters))
+	copy(clusters, shm.sybilClusters)
+	return clusters
+}
+
+// updateSybilClusters detects the suspicious storage host clusters among all storage hosts,
+// and recalculates the evaluation of the storage hosts whose cluster size changed
+func (shm *StorageHostManager) updateSybilClusters() {
+	clusters := detectSybilClusters(shm.storageHostTree.All())
+	index := make(map[enode.ID]int)
+	for i, cluster := range clusters {
+		for _, id := range cluster.Hosts {
+			index[id] = i
+		}
+	}
+
+	shm.sybilLock.Lock()
+	var changed []enode.ID
+	for id := range shm.sybilIndex {
+		if _, exists := index[id]; !exists {
+			changed = append(changed, id)
+		}
+	}
+	for id, i := range index {
+		if prev, exists := shm.sybilIndex[id]; !exists || len(shm.sybilClusters[prev].Hosts) != len(clusters[i].Hosts) {
+			changed = append(changed, id)
+		}
+	}
+	for _, cluster := range clusters {
+		if !shm.knownSybilCluster(cluster) {
+			shm.log.Warn("Suspicious storage host cluster detected", "hosts", len(cluster.Hosts), "reasons", cluster.Reasons)
+		}
+	}
+	shm.sybilClusters, shm.sybilIndex = clusters, index
+	shm.sybilLock.Unlock()
+
+	if len(changed) == 0 {
+		return
+	}
+	shm.lock.Lock()
+	defer shm.lock.Unlock()
+	for _, id := range changed {
+		host, exists := shm.storageHostTree.RetrieveHostInfo(id)
+		if !exists {
+			continue
+		}
+		if err := shm.modify(host); err != nil {
+			shm.log.Error("failed to update the sybil cluster of the storage host", "err", err.Error())
+		}
+	}
+}
+
+// knownSybilCluster checks whether the cluster was detected before with the same storage hosts
+//
+// NOTE: shm.sybilLock should be held when calling this function
+func (shm *StorageHostManager) knownSybilCluster(cluster SybilCluster) bool {
+	i, exists := shm.sybilIndex[cluster.Hosts[0]]
+	if !exists || len(shm.sybilClusters[i].Hosts) != len(cluster.Hosts) {
+		return false
+	}
+	for j, id := range cluster.Hosts {
+		if shm.sybilClusters[i].Hosts[j] != id {
+			return false
+		}
+	}
+	return true
+}
+
+// sybilClusterSize returns the number of storage hosts in the cluster of the storage host,
+// 1 if the storage host is not in a suspicious cluster
+func (shm *StorageHostManager) sybilClusterSize(id enode.ID) int {
+	shm.sybilLock.Lock()
+	defer shm.sybilLock.Unlock()
+	i, exists := shm.sybilIndex[id]
+	if !exists {
+		return 1
+	}
+	return len(shm.sybilClusters[i].Hosts)
+}
+
+// collapseSybilClusters keeps at most one storage host of each suspicious cluster in the
+// storage hosts selected, and drops the storage hosts whose cluster has a member in the
+// blacklist, which is usually the storage hosts already signed contracts with
+func (shm *StorageHostManager) collapseSybilClusters(infos []storage.HostInfo, blacklist []enode.ID) []storage.HostInfo {
+	shm.sybilLock.Lock()
+	defer shm.sybilLock.Unlock()
+
+	taken := make(map[int]struct{})
+	for _, id := range blacklist {
+		if i, exists := shm.sybilIndex[id]; exists {
+			taken[i] = struct{}{}
+		}
+	}
+	collapsed := infos[:0]
+	for _, info := range infos {
+		if i, exists := shm.sybilIndex[info.EnodeID]; exists {
+			if _, selected := taken[i]; selected {
+				continue
+			}
+			taken[i] = struct{}{}
+		}
+		collapsed = append(collapsed, info)
+	}
+	return collapsed
+}
+
+// detectSybilClusters groups the storage hosts sharing the payment address, the node key or
+// the ip address. The groups with more than one storage host are returned, with the storage
+// hosts sorted by the enode ID
+func detectSybilClusters(hosts []storage.HostInfo) []SybilCluster {
+	parent := make([]int, len(hosts))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	// link the storage hosts sharing the same key, and record the reasons
+	reasons := make(map[int]map[string]struct{})
+	link := func(kind string, keyOf func(hi storage.HostInfo) string) {
+		first := make(map[string]int)
+		for i, hi := range hosts {
+			key := keyOf(hi)
+			if key == "" {
+				continue
+			}
+			j, exists := first[key]
+			if !exists {
+				first[key] = i
+				continue
+			}
+			parent[find(i)] = find(j)
+			if reasons[j] == nil {
+				reasons[j] = make(map[string]struct{})
+			}
+			reasons[j][fmt.Sprintf("%s %s", kind, key)] = struct{}{}
+		}
+	}
+	link("payment address", func(hi storage.HostInfo) string {
+		if hi.PaymentAddress == (common.Address{}) {
+			return ""
+		}
+		return hi.PaymentAddress.String()
+	})
+	link("node key", func(hi storage.HostInfo) string {
+		if len(hi.NodePubKey) == 0 {
+			return ""
+		}
+		return common.Bytes2Hex(hi.NodePubKey)
+	})
+	link("ip address", func(hi storage.HostInfo) string {
+		return hi.IP
+	})
+
+	groups := make(map[int]*SybilCluster)
+	for i, hi := range hosts {
+		root := find(i)
+		if groups[root] == nil {
+			groups[root] = &SybilCluster{}
+		}
+		groups[root].Hosts = append(groups[root].Hosts, hi.EnodeID)
+	}
+	for j, rs := range reasons {
+		cluster := groups[find(j)]
+		for reason := range rs {
+			cluster.Reasons = append(cluster.Reasons, reason)
+		}
+	}
+
+	var clusters []SybilCluster
+	for _, cluster := range groups {
+		if len(cluster.Hosts) < 2 {
+			continue
+		}
+		sort.Slice(cluster.Hosts, func(i, j int) bool {
+			return bytes.Compare(cluster.Hosts[i][:], cluster.Hosts[j][:]) < 0
+		})
+		sort.Strings(cluster.Reasons)
+		clusters = append(clusters, *cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return bytes.Compare(clusters[i].Hosts[0][:], clusters[j].Hosts[0][:]) < 0
+	})
+	return clusters
+}
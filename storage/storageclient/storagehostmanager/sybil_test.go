@@ -0,0 +1,85 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehostmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestDetectSybilClusters(t *testing.T) {
+	host := func(id byte, ip string, payment byte) storage.HostInfo {
+		hi := storage.HostInfo{EnodeID: enode.ID{id}, IP: ip}
+		if payment != 0 {
+			hi.PaymentAddress = common.Address{payment}
+		}
+		return hi
+	}
+	hosts := []storage.HostInfo{
+		host(1, "10.0.0.1", 1),
+		host(2, "10.0.0.2", 1),
+		host(3, "10.0.0.2", 0),
+		host(4, "10.0.0.4", 0),
+		host(5, "10.0.0.5", 0),
+		host(6, "10.0.0.6", 2),
+		host(7, "10.0.0.7", 2),
+	}
+
+	clusters := detectSybilClusters(hosts)
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %+v", clusters)
+	}
+	// the hosts 1 and 3 are linked through the host 2 with the payment address and the ip address
+	expected := [][]enode.ID{{{1}, {2}, {3}}, {{6}, {7}}}
+	for i, cluster := range clusters {
+		if len(cluster.Hosts) != len(expected[i]) {
+			t.Fatalf("cluster %v: expected hosts %v, got %v", i, expected[i], cluster.Hosts)
+		}
+		for j, id := range cluster.Hosts {
+			if id != expected[i][j] {
+				t.Errorf("cluster %v: expected hosts %v, got %v", i, expected[i], cluster.Hosts)
+			}
+		}
+	}
+	if len(clusters[0].Reasons) != 2 || len(clusters[1].Reasons) != 1 {
+		t.Errorf("unexpected reasons %v, %v", clusters[0].Reasons, clusters[1].Reasons)
+	}
+}
+
+func TestStorageHostManager_CollapseSybilClusters(t *testing.T) {
+	shm := New("test")
+	ips := []string{"10.0.0.1", "10.0.0.1", "10.0.0.1", "10.0.0.4", "10.0.0.5"}
+	for i, ip := range ips {
+		hi := hostInfoGeneratorIPID(ip, enode.ID{byte(i + 1)}, time.Now())
+		if err := shm.insert(hi); err != nil {
+			t.Fatal(err)
+		}
+	}
+	shm.updateSybilClusters()
+
+	if clusters := shm.SybilClusters(); len(clusters) != 1 || len(clusters[0].Hosts) != 3 {
+		t.Fatalf("unexpected clusters %+v", clusters)
+	}
+	if factor := shm.sybilFactorCalc(storage.HostInfo{EnodeID: enode.ID{1}}); factor != 1.0/3 {
+		t.Errorf("expected sybil factor %v, got %v", 1.0/3, factor)
+	}
+	if factor := shm.sybilFactorCalc(storage.HostInfo{EnodeID: enode.ID{4}}); factor != 1 {
+		t.Errorf("expected sybil factor 1, got %v", factor)
+	}
+
+	infos := []storage.HostInfo{{EnodeID: enode.ID{1}}, {EnodeID: enode.ID{2}}, {EnodeID: enode.ID{4}}}
+	if collapsed := shm.collapseSybilClusters(infos, nil); len(collapsed) != 2 {
+		t.Errorf("expected 2 hosts after collapse, got %v", len(collapsed))
+	}
+	// the cluster with a member already contracted is not selected again
+	infos = []storage.HostInfo{{EnodeID: enode.ID{2}}, {EnodeID: enode.ID{5}}}
+	if collapsed := shm.collapseSybilClusters(infos, []enode.ID{{3}}); len(collapsed) != 1 || collapsed[0].EnodeID != (enode.ID{5}) {
+		t.Errorf("unexpected hosts after collapse %+v", collapsed)
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage"
 	"github.com/DxChainNetwork/godx/storage/storageclient/storagehosttree"
-	"time"
 )
 
 // subscribeChainChangeEvent will receive changes on the blockchain (blocks added / reverted)
@@ -66,7 +65,52 @@ func (shm *StorageHostManager) analyzeChainEventChange(change core.ChainChangeEv
 			continue
 		}
 		shm.analyzeHostAnnouncements(hostAnnouncements)
+
+		// the revocations are applied after the announcements of the same block
+		hostRevocations, err := shm.b.GetHostRevocationWithBlockHash(hash)
+		if err != nil {
+			shm.log.Error("error extracting host revocation", "block hash", hash, "err", err.Error())
+			continue
+		}
+		shm.RemoveRevokedHosts(hostRevocations)
+	}
+}
+
+// RemoveRevokedHosts will remove the storage hosts whose announcements are revoked. The
+// revoked host is inserted again once it announces again
+func (shm *StorageHostManager) RemoveRevokedHosts(hostRevocations []types.HostRevocation) {
+	for _, revocation := range hostRevocations {
+		node, err := enode.ParseV4(revocation.NetAddress)
+		if err != nil {
+			shm.log.Error("failed to parse the revocation information", "err", err.Error())
+			continue
+		}
+		if _, exists := shm.storageHostTree.RetrieveHostInfo(node.ID()); !exists {
+			continue
+		}
+		if err := shm.remove(node.ID()); err != nil {
+			shm.log.Error("failed to remove the revoked storage host", "id", node.ID(), "err", err.Error())
+			continue
+		}
+		shm.log.Info("Storage host revoked its announcement", "id", node.ID())
+	}
+}
+
+// InsertHostAnnouncements will insert the storage hosts from the host announcements found
+// in the historical blocks, which were missed by the chain change event subscription
+func (shm *StorageHostManager) InsertHostAnnouncements(hostAnnouncements []types.HostAnnouncement) {
+	shm.analyzeHostAnnouncements(hostAnnouncements)
+}
+
+// AddHost will add the storage host by its enode url, the same as it is announced on chain. It is
+// meant for the local testing against the hosts never announced, such as the mock hosts
+func (shm *StorageHostManager) AddHost(enodeURL string) error {
+	info, err := parseHostAnnouncement(types.HostAnnouncement{NetAddress: enodeURL})
+	if err != nil {
+		return err
 	}
+	shm.insertStorageHostInformation(info)
+	return nil
 }
 
 // analyzeHostAnnouncements will parse the storage host announcement and insert it into the storage host
@@ -123,7 +167,7 @@ func (shm *StorageHostManager) insertStorageHostInformation(info storage.HostInf
 		shm.log.Error("failed to extract the network address from the IP address", "err", err.Error())
 	} else if networkAddr.String() != oldInfo.IPNetwork {
 		oldInfo.IPNetwork = networkAddr.String()
-		oldInfo.LastIPNetWorkChange = time.Now()
+		oldInfo.LastIPNetWorkChange = shm.clock.Now()
 	}
 
 	// modify the old storage host information
@@ -30,6 +30,8 @@ type EvaluationDetail struct {
 	ContractPriceFactor    float64 `json:"contractpriceFactor"`
 	StorageRemainingFactor float64 `json:"storageremainingfactor"`
 	UptimeFactor           float64 `json:"uptimefactor"`
+	LatencyFactor          float64 `json:"latencyfactor"`
+	SybilFactor            float64 `json:"sybilfactor"`
 }
 
 // EvaluationCriteria contains statistics that used to calculate the storage host evaluation
@@ -40,12 +42,14 @@ type EvaluationCriteria struct {
 	ContractPriceFactor    float64
 	StorageRemainingFactor float64
 	UptimeFactor           float64
+	LatencyFactor          float64
+	SybilFactor            float64
 }
 
 // Evaluation will be used to calculate the storage host evaluation
 func (ec EvaluationCriteria) Evaluation() common.BigInt {
 	total := ec.PresenceFactor * ec.DepositFactor * ec.InteractionFactor *
-		ec.ContractPriceFactor * ec.StorageRemainingFactor * ec.UptimeFactor
+		ec.ContractPriceFactor * ec.StorageRemainingFactor * ec.UptimeFactor * ec.LatencyFactor * ec.SybilFactor
 
 	// making sure the total is at least 1
 	if total < 1 {
@@ -77,6 +81,8 @@ func (ec EvaluationCriteria) EvaluationDetail(evalAll common.BigInt, ignoreAge,
 		ContractPriceFactor:    ec.ContractPriceFactor,
 		StorageRemainingFactor: ec.StorageRemainingFactor,
 		UptimeFactor:           ec.UptimeFactor,
+		LatencyFactor:          ec.LatencyFactor,
+		SybilFactor:            ec.SybilFactor,
 	}
 
 }
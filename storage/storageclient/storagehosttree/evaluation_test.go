@@ -59,6 +59,8 @@ func randomCriteria() EvaluationCriteria {
 		ContractPriceFactor:    randFloat64(),
 		StorageRemainingFactor: randFloat64(),
 		UptimeFactor:           randFloat64(),
+		LatencyFactor:          1,
+		SybilFactor:            1,
 	}
 }
 
@@ -70,6 +72,8 @@ func partialRandomCriteria() EvaluationCriteria {
 		ContractPriceFactor:    100,
 		StorageRemainingFactor: randFloat64(),
 		UptimeFactor:           randFloat64(),
+		LatencyFactor:          1,
+		SybilFactor:            1,
 	}
 }
 
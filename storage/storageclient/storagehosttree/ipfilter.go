@@ -7,6 +7,8 @@ package storagehosttree
 import (
 	"fmt"
 	"net"
+
+	"github.com/DxChainNetwork/godx/storage"
 )
 
 // Filter defines IP filter map. For any IP addresses with same IP Network will be marked
@@ -53,6 +55,19 @@ func (f *Filter) Reset() {
 	f.filterPool = make(map[string]struct{})
 }
 
+// GroupFunc returns the diversity groups of the storage host, such as the ip network and the
+// geographic location. The storage hosts in the same group are likely to fail together
+type GroupFunc func(hi storage.HostInfo) []string
+
+// IPNetworkGroups returns the ip network of the storage host as its only diversity group
+func IPNetworkGroups(hi storage.HostInfo) []string {
+	ipnet, err := IPNetwork(hi.IP)
+	if err != nil {
+		return nil
+	}
+	return []string{ipnet.String()}
+}
+
 // IPNetwork will return the IP network used by an IP address
 func IPNetwork(ip string) (ipnet *net.IPNet, err error) {
 	cidr := fmt.Sprintf("%s/%d", ip, IPv4PrefixLength)
@@ -185,18 +185,29 @@ func (t *StorageHostTree) SetEvaluationFunc(ef EvaluationFunc) error {
 // the storage host cannot be selected. For any storage host's enode ID contained in the
 // addrBlacklist, the address's ip network will have to be added into the filter, meaning
 // the storage host with same ip network cannot be selected
+// NOTE: the number of storage hosts information got may not satisfy the number of storage host
+// information needed.
+func (t *StorageHostTree) SelectRandom(needed int, blacklist, addrBlacklist []enode.ID) []storage.HostInfo {
+	return t.SelectRandomDiverse(needed, blacklist, addrBlacklist, 1, IPNetworkGroups)
+}
+
+// SelectRandomDiverse will randomly select nodes from the storage host tree based on their
+// evaluation, with the diversity constraint that no more than maxPerGroup storage hosts,
+// including the ones in the addrBlacklist, are in the same diversity group. The storage
+// hosts selected are always in different ip networks. maxPerGroup 0 means no limit
 //  	1. handle addrBlacklist
 // 		2. handle blacklist
 //      3. get needed storage hosts
 //      4. restore storage host tree structure
 // NOTE: the number of storage hosts information got may not satisfy the number of storage host
 // information needed.
-func (t *StorageHostTree) SelectRandom(needed int, blacklist, addrBlacklist []enode.ID) []storage.HostInfo {
+func (t *StorageHostTree) SelectRandomDiverse(needed int, blacklist, addrBlacklist []enode.ID, maxPerGroup int, groups GroupFunc) []storage.HostInfo {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
 	var removedNodeEntries []*nodeEntry
 	filter := NewFilter()
+	occupied := make(map[string]int)
 
 	// 1. handle addrBlacklist
 	for _, enodeID := range addrBlacklist {
@@ -204,7 +215,20 @@ func (t *StorageHostTree) SelectRandom(needed int, blacklist, addrBlacklist []en
 		if !exists {
 			continue
 		}
-		filter.Add(node.entry.HostInfo.IP)
+		for _, group := range groups(node.entry.HostInfo) {
+			occupied[group]++
+		}
+	}
+	diverse := func(hi storage.HostInfo) bool {
+		if maxPerGroup <= 0 {
+			return true
+		}
+		for _, group := range groups(hi) {
+			if occupied[group] >= maxPerGroup {
+				return false
+			}
+		}
+		return true
 	}
 
 	// 2. handle blacklist
@@ -245,13 +269,18 @@ func (t *StorageHostTree) SelectRandom(needed int, blacklist, addrBlacklist []en
 		//   1. must accept contract
 		//   2. must be scanned at least once
 		//   3. the latest scan must be success
-		//   4. ip network should not be the same as once contained in the address blacklist
+		//   4. ip network should not be the same as the storage hosts selected
+		//   5. diversity groups should not be full
 		if node.entry.AcceptingContracts &&
 			len(node.entry.ScanRecords) > 0 &&
 			node.entry.ScanRecords[len(node.entry.ScanRecords)-1].Success &&
-			!filter.Filtered(node.entry.IP) {
+			!filter.Filtered(node.entry.IP) &&
+			diverse(node.entry.HostInfo) {
 			storageHosts = append(storageHosts, node.entry.HostInfo)
 			filter.Add(node.entry.IP)
+			for _, group := range groups(node.entry.HostInfo) {
+				occupied[group]++
+			}
 		}
 
 		// remove the node
@@ -6,6 +6,7 @@ package storagehosttree
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -131,6 +132,50 @@ func TestStorageHostTree_SelectRandom(t *testing.T) {
 	}
 }
 
+func TestStorageHostTree_SelectRandomDiverse(t *testing.T) {
+	successScans := storage.HostPoolScans{{Timestamp: time.Now(), Success: true}}
+	diverseTree := New(evalFunc)
+	groupOf := make(map[enode.ID]string)
+	var ids []enode.ID
+	for i := 0; i < 6; i++ {
+		id := enode.ID{byte(i + 1)}
+		ids = append(ids, id)
+		groupOf[id] = "a"
+		if i >= 4 {
+			groupOf[id] = "b"
+		}
+		if err := diverseTree.Insert(createHostInfo(fmt.Sprintf("10.0.%d.1", i), id, successScans, true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	groups := func(hi storage.HostInfo) []string {
+		return []string{groupOf[hi.EnodeID]}
+	}
+
+	// the host in the addrBlacklist takes a place in group a
+	for i := 0; i < 10; i++ {
+		infos := diverseTree.SelectRandomDiverse(10, ids[:1], ids[:1], 2, groups)
+		if len(infos) != 3 {
+			t.Fatalf("expect 3 hosts selected, got %v", len(infos))
+		}
+		count := make(map[string]int)
+		for _, info := range infos {
+			count[groupOf[info.EnodeID]]++
+		}
+		if count["a"] != 1 || count["b"] != 2 {
+			t.Fatalf("diversity constraint not satisfied: %v", count)
+		}
+	}
+
+	// no limit on the diversity groups
+	if infos := diverseTree.SelectRandomDiverse(10, nil, nil, 0, groups); len(infos) != 6 {
+		t.Fatalf("expect 6 hosts selected, got %v", len(infos))
+	}
+	if len(diverseTree.hostPool) != 6 {
+		t.Fatalf("the tree is not restored after the selection")
+	}
+}
+
 func createHostInfo(ip string, id enode.ID, scans storage.HostPoolScans, contract bool) storage.HostInfo {
 	return storage.HostInfo{
 		HostExtConfig: storage.HostExtConfig{
@@ -0,0 +1,224 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"crypto/subtle"
+	"errors"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DxChainNetwork/godx/rpc"
+)
+
+// The HTTP stream endpoint serves the files of the storage client by the dx path, under the
+// path prefix /files/. The Range header is honored, so that only the segments covering the
+// ranges requested are downloaded, which allows the video players to seek in the file. If the
+// access tokens are configured, the request must carry one of them as the bearer token in the
+// Authorization header. The Host header is checked against the virtual hosts allowed, and the
+// cross origin requests are rejected, to prevent the DNS rebinding and the web pages from
+// reading the files. Without the access tokens, the endpoint can only listen on the loopback
+// interface.
+
+// httpStreamPrefix is the url path prefix of the files served by the HTTP stream endpoint
+const httpStreamPrefix = "/files/"
+
+// EnableHTTPStream enables the HTTP stream endpoint listening on the address, which is
+// started along with the storage client. The tokens are the access tokens in the format of
+// rpc.ParseAccessTokens, of any tier, and the vhosts are the virtual hosts allowed in the
+// Host header
+func (client *StorageClient) EnableHTTPStream(addr string, tokens []string, vhosts []string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return errors.New("invalid listening address of the HTTP stream endpoint: " + err.Error())
+	}
+	parsed, err := rpc.ParseAccessTokens(tokens)
+	if err != nil {
+		return errors.New("invalid access token of the HTTP stream endpoint: " + err.Error())
+	}
+	if len(parsed) == 0 && !isLoopbackHost(host) {
+		return errors.New("the HTTP stream endpoint without the access tokens can only listen on the loopback interface")
+	}
+
+	client.httpStreamAddr = addr
+	client.httpStreamTokens = make([]string, 0, len(parsed))
+	for token := range parsed {
+		client.httpStreamTokens = append(client.httpStreamTokens, token)
+	}
+	client.httpStreamVHosts = make(map[string]struct{})
+	for _, vhost := range vhosts {
+		client.httpStreamVHosts[strings.ToLower(vhost)] = struct{}{}
+	}
+	return nil
+}
+
+// isLoopbackHost checks if the host of the listening address is the loopback interface.
+// The empty host listens on all interfaces
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// startHTTPStream starts the HTTP stream endpoint if enabled. The endpoint is shut down
+// once the storage client stops
+func (client *StorageClient) startHTTPStream() error {
+	if client.httpStreamAddr == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", client.httpStreamAddr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(httpStreamPrefix, http.StripPrefix(httpStreamPrefix, http.HandlerFunc(client.serveHTTPStream)))
+	server := &http.Server{
+		Handler:     mux,
+		ReadTimeout: HTTPStreamReadTimeout,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			client.log.Error("HTTP stream endpoint stopped", "err", err)
+		}
+	}()
+	client.tm.OnStop(func() error {
+		return server.Close()
+	})
+	client.log.Info("HTTP stream endpoint started", "addr", listener.Addr().String())
+	return nil
+}
+
+// serveHTTPStream serves the file of the dx path in the url path
+func (client *StorageClient) serveHTTPStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if status, err := client.authorizeHTTPStream(r); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if err := client.tm.Add(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer client.tm.Done()
+
+	reader, err := client.StreamDownload(r.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer reader.Close()
+
+	// only the segments covering the ranges requested are prefetched
+	reader.SetReadLimit(rangeLimit(r.Header.Get("Range"), reader.Size()))
+
+	// the content type is set ahead, so that the content is not read for sniffing
+	contentType := mime.TypeByExtension(path.Ext(r.URL.Path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, path.Base(r.URL.Path), time.Time{}, reader)
+}
+
+// authorizeHTTPStream checks the Host and Origin headers and the access token of the request,
+// and returns the HTTP status along with the error if the request is denied
+func (client *StorageClient) authorizeHTTPStream(r *http.Request) (int, error) {
+	if !allowedStreamHost(r.Host, client.httpStreamVHosts) {
+		return http.StatusForbidden, errors.New("invalid host specified")
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || !strings.EqualFold(u.Host, r.Host) {
+			return http.StatusForbidden, errors.New("cross origin request is not allowed")
+		}
+	}
+	if len(client.httpStreamTokens) == 0 {
+		return http.StatusOK, nil
+	}
+	token := bearerToken(r.Header.Get("Authorization"))
+	for _, t := range client.httpStreamTokens {
+		if token != "" && subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return http.StatusOK, nil
+		}
+	}
+	return http.StatusUnauthorized, errors.New("missing or invalid access token")
+}
+
+// allowedStreamHost checks the Host header against the virtual hosts allowed. Like the HTTP
+// RPC endpoint, the IP address is always allowed, and "*" allows all hosts
+func allowedStreamHost(hostHeader string, vhosts map[string]struct{}) bool {
+	if hostHeader == "" {
+		return true
+	}
+	host, _, err := net.SplitHostPort(hostHeader)
+	if err != nil {
+		host = hostHeader
+	}
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	if _, exists := vhosts["*"]; exists {
+		return true
+	}
+	_, exists := vhosts[strings.ToLower(host)]
+	return exists
+}
+
+// bearerToken extracts the token from the Authorization header value
+func bearerToken(header string) string {
+	const prefix = "bearer "
+	if len(header) > len(prefix) && strings.ToLower(header[:len(prefix)]) == prefix {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	return ""
+}
+
+// rangeLimit returns the end offset of the last byte of the ranges in the Range header, or the
+// size if the whole content is requested. The header is validated by http.ServeContent, the
+// size is returned for the header not parsed
+func rangeLimit(header string, size int64) int64 {
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return size
+	}
+	var limit int64
+	for _, spec := range strings.Split(header[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		i := strings.Index(spec, "-")
+		if i < 0 {
+			return size
+		}
+		start, end := strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+
+		// the suffix range and the open range end at the end of the content
+		if start == "" || end == "" {
+			return size
+		}
+		last, err := strconv.ParseInt(end, 10, 64)
+		if err != nil || last < 0 {
+			return size
+		}
+		if last+1 > limit {
+			limit = last + 1
+		}
+	}
+	if limit > size {
+		limit = size
+	}
+	return limit
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRangeLimit(t *testing.T) {
+	size := int64(1000)
+	tests := []struct {
+		header string
+		expect int64
+	}{
+		{"", size},
+		{"bytes=0-99", 100},
+		{"bytes=100-199, 500-599", 600},
+		{"bytes=500-599,100-199", 600},
+		{"bytes=900-", size},
+		{"bytes=-100", size},
+		{"bytes=0-5000", size},
+		{"bytes=abc", size},
+		{"items=0-99", size},
+	}
+	for _, test := range tests {
+		if limit := rangeLimit(test.header, size); limit != test.expect {
+			t.Errorf("range %q: expect limit %v, got %v", test.header, test.expect, limit)
+		}
+	}
+}
+
+func TestEnableHTTPStream(t *testing.T) {
+	client := &StorageClient{}
+	tests := []struct {
+		addr   string
+		tokens []string
+		err    bool
+	}{
+		{"127.0.0.1:8580", nil, false},
+		{"localhost:8580", nil, false},
+		{"[::1]:8580", nil, false},
+		{"0.0.0.0:8580", nil, true},
+		{":8580", nil, true},
+		{"192.168.1.2:8580", nil, true},
+		{"0.0.0.0:8580", []string{"secret:read"}, false},
+		{"0.0.0.0:8580", []string{"secret:unknown"}, true},
+		{"127.0.0.1", nil, true},
+	}
+	for _, test := range tests {
+		err := client.EnableHTTPStream(test.addr, test.tokens, nil)
+		if test.err != (err != nil) {
+			t.Errorf("enable %v with tokens %v: expect error %v, got %v", test.addr, test.tokens, test.err, err)
+		}
+	}
+}
+
+func TestAuthorizeHTTPStream(t *testing.T) {
+	client := &StorageClient{}
+	if err := client.EnableHTTPStream("0.0.0.0:8580", []string{"secret:read"}, []string{"localhost"}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		host   string
+		header map[string]string
+		status int
+	}{
+		{"localhost:8580", map[string]string{"Authorization": "Bearer secret"}, http.StatusOK},
+		{"127.0.0.1:8580", map[string]string{"Authorization": "bearer secret"}, http.StatusOK},
+		{"localhost:8580", nil, http.StatusUnauthorized},
+		{"localhost:8580", map[string]string{"Authorization": "Bearer wrong"}, http.StatusUnauthorized},
+		{"evil.example.com", map[string]string{"Authorization": "Bearer secret"}, http.StatusForbidden},
+		{"localhost:8580", map[string]string{"Authorization": "Bearer secret", "Origin": "http://evil.example.com"}, http.StatusForbidden},
+		{"localhost:8580", map[string]string{"Authorization": "Bearer secret", "Origin": "http://localhost:8580"}, http.StatusOK},
+	}
+	for i, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "http://"+test.host+httpStreamPrefix+"file", nil)
+		for key, value := range test.header {
+			r.Header.Set(key, value)
+		}
+		if status, _ := client.authorizeHTTPStream(r); status != test.status {
+			t.Errorf("test %d: expect status %v, got %v", i, test.status, status)
+		}
+	}
+}
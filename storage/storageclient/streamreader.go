@@ -0,0 +1,338 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file
+
+package storageclient
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
+)
+
+var errStreamReaderClosed = errors.New("stream reader has been closed")
+
+// DownloadReader is the seekable reader of a remote file. Only the segments covering the data
+// read are downloaded, so that a byte range could be read without downloading the whole file
+type DownloadReader interface {
+	io.ReadSeeker
+	io.Closer
+
+	// Size returns the size of the file
+	Size() int64
+
+	// SetReadLimit stops the segments beyond the offset from being prefetched. The data
+	// beyond the offset is still downloaded on demand once read
+	SetReadLimit(limit int64)
+}
+
+// streamReader reads a dx file segment by segment. Once sequential access is
+// observed, the next segments are prefetched concurrently so that the consumer
+// does not need to wait for the hosts for every segment
+type streamReader struct {
+	client *StorageClient
+	file   *dxfile.Snapshot
+	size   uint64
+	offset uint64
+
+	// limit is the end offset of the data expected to be read, beyond which the segments
+	// are not prefetched
+	limit uint64
+
+	// the segment index of the last read, and how many reads in a row
+	// are sequential
+	lastSegment     uint64
+	sequentialReads int
+
+	// window is the number of segments to be read ahead
+	window uint64
+
+	// segments that are being downloaded or waiting to be consumed
+	segments map[uint64]*streamSegment
+
+	closed bool
+	mu     sync.Mutex
+}
+
+// streamSegment is a segment downloaded into memory by the stream reader
+type streamSegment struct {
+	download *download
+	buffer   downloadBuffer
+	length   uint64
+}
+
+// newStreamReader creates a stream reader reading the file from the beginning
+func (client *StorageClient) newStreamReader(file *dxfile.Snapshot) *streamReader {
+	return &streamReader{
+		client:   client,
+		file:     file,
+		size:     file.FileSize(),
+		limit:    file.FileSize(),
+		window:   StreamPrefetchMinSegments,
+		segments: make(map[uint64]*streamSegment),
+	}
+}
+
+// Read reads the data from the current offset of the stream. Read blocks until
+// the segment which the offset lies in is downloaded
+func (sr *streamReader) Read(p []byte) (int, error) {
+	sr.mu.Lock()
+	if sr.closed {
+		sr.mu.Unlock()
+		return 0, errStreamReaderClosed
+	}
+	if sr.offset >= sr.size {
+		sr.mu.Unlock()
+		return 0, io.EOF
+	}
+
+	segmentIndex, segmentOffset := sr.file.SegmentIndexByOffset(sr.offset)
+	sr.trackAccess(segmentIndex)
+	segment, err := sr.fetchSegment(segmentIndex)
+	if err != nil {
+		sr.mu.Unlock()
+		return 0, err
+	}
+	if sr.sequentialReads >= StreamSequentialThreshold {
+		sr.prefetch(segmentIndex)
+	}
+	sr.mu.Unlock()
+
+	// adapt the read ahead window to the speed of the consumer before
+	// blocking on the segment
+	stalled := !segment.download.isComplete()
+	select {
+	case <-segment.download.completeChan:
+	case <-sr.client.tm.StopChan():
+		return 0, errors.New("storage client is shutdown")
+	}
+	if err := segment.download.Err(); err != nil {
+		sr.mu.Lock()
+		delete(sr.segments, segmentIndex)
+		sr.mu.Unlock()
+		return 0, err
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.adaptWindow(segment, stalled)
+
+	n := segment.readAt(p, segmentOffset)
+	sr.offset += uint64(n)
+
+	// the segment is fully consumed, drop it from memory
+	if segmentOffset+uint64(n) >= segment.length {
+		delete(sr.segments, segmentIndex)
+	}
+	return n, nil
+}
+
+// Seek sets the offset of the next read. The segments prefetched are dropped on the next read
+// if it is not sequential to the last read
+func (sr *streamReader) Seek(offset int64, whence int) (int64, error) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.closed {
+		return 0, errStreamReaderClosed
+	}
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += int64(sr.offset)
+	case io.SeekEnd:
+		offset += int64(sr.size)
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	sr.offset = uint64(offset)
+	return offset, nil
+}
+
+// Size returns the size of the file
+func (sr *streamReader) Size() int64 {
+	return int64(sr.size)
+}
+
+// SetReadLimit stops the segments beyond the limit from being prefetched
+func (sr *streamReader) SetReadLimit(limit int64) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if limit < 0 || uint64(limit) > sr.size {
+		limit = int64(sr.size)
+	}
+	sr.limit = uint64(limit)
+}
+
+// Close stops the stream reader and drops all prefetched segments
+func (sr *streamReader) Close() error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.closed {
+		return errStreamReaderClosed
+	}
+	sr.closed = true
+	for index, segment := range sr.segments {
+		if !segment.download.isComplete() {
+			segment.download.fail(errStreamReaderClosed)
+		}
+		delete(sr.segments, index)
+	}
+	return nil
+}
+
+// trackAccess records the segment being read, and resets the read ahead once
+// the access pattern is no longer sequential
+func (sr *streamReader) trackAccess(segmentIndex uint64) {
+	if segmentIndex == sr.lastSegment || segmentIndex == sr.lastSegment+1 {
+		sr.sequentialReads++
+	} else {
+		sr.sequentialReads = 0
+		sr.window = StreamPrefetchMinSegments
+		for index, segment := range sr.segments {
+			if index == segmentIndex {
+				continue
+			}
+			if !segment.download.isComplete() {
+				segment.download.fail(errors.New("prefetched segment is no longer needed"))
+			}
+			delete(sr.segments, index)
+		}
+	}
+	sr.lastSegment = segmentIndex
+}
+
+// prefetch starts the download of the segments following the given segment
+// within the read ahead window, and before the read limit
+func (sr *streamReader) prefetch(segmentIndex uint64) {
+	window := sr.window
+	if limit := sr.memoryWindowLimit(); window > limit {
+		window = limit
+	}
+	for i := segmentIndex + 1; i <= segmentIndex+window && i < sr.prefetchEnd(); i++ {
+		if _, err := sr.fetchSegment(i); err != nil {
+			sr.client.log.Debug("failed to prefetch segment", "segment", i, "err", err)
+			return
+		}
+	}
+}
+
+// prefetchEnd returns the index after the last segment to be prefetched, which is the
+// segment covering the end of the read limit
+func (sr *streamReader) prefetchEnd() uint64 {
+	if sr.limit == 0 {
+		return 0
+	}
+	end := (sr.limit-1)/sr.file.SegmentSize() + 1
+	if numSegments := sr.file.NumSegments(); end > numSegments {
+		end = numSegments
+	}
+	return end
+}
+
+// adaptWindow adjusts the read ahead window with the observed consumer speed. If
+// the consumer had to wait for the segment, the prefetching is too slow and
+// the window is doubled. If the segment has been idle in memory for long, the
+// consumer is slower than the download and the window is shrunk
+func (sr *streamReader) adaptWindow(segment *streamSegment, stalled bool) {
+	if stalled {
+		sr.window *= 2
+		if sr.window > StreamPrefetchMaxSegments {
+			sr.window = StreamPrefetchMaxSegments
+		}
+		return
+	}
+	if time.Since(segment.download.endTime) > StreamPrefetchIdleThreshold && sr.window > StreamPrefetchMinSegments {
+		sr.window--
+	}
+}
+
+// memoryWindowLimit returns the max number of segments that can be prefetched
+// with the memory currently available in the storage client
+func (sr *streamReader) memoryWindowLimit() uint64 {
+	ec := sr.file.ErasureCode()
+	segmentMemory := sr.file.SegmentSize() + uint64(ec.MinSectors()+streamDownloadOverdrive)*sr.file.SectorSize()
+	return sr.client.memoryManager.MemoryAvailable() / segmentMemory
+}
+
+// fetchSegment returns the segment with the given index, and creates the
+// download for it if it is not downloaded yet
+func (sr *streamReader) fetchSegment(segmentIndex uint64) (*streamSegment, error) {
+	if segment, exists := sr.segments[segmentIndex]; exists {
+		return segment, nil
+	}
+
+	offset := segmentIndex * sr.file.SegmentSize()
+	length := sr.file.SegmentSize()
+	if offset+length > sr.size {
+		length = sr.size - offset
+	}
+	buffer := newDownloadBuffer(length, sr.file.SectorSize())
+	d, err := sr.client.newDownload(downloadParams{
+		destination:       buffer,
+		destinationType:   "buffer",
+		destinationString: sr.file.DxPath().Path,
+		file:              sr.file,
+		latencyTarget:     25e3 * time.Millisecond,
+		length:            length,
+		needsMemory:       true,
+		offset:            offset,
+		overdrive:         streamDownloadOverdrive,
+		priority:          5,
+	})
+	if err != nil {
+		return nil, err
+	}
+	segment := &streamSegment{
+		download: d,
+		buffer:   buffer,
+		length:   length,
+	}
+	sr.segments[segmentIndex] = segment
+	return segment, nil
+}
+
+// readAt copies the segment data starting from offset to p
+func (ss *streamSegment) readAt(p []byte, offset uint64) int {
+	var n int
+	for offset < ss.length && n < len(p) {
+		sectorIndex := offset / ss.buffer.sectorSize
+		sectorOffset := offset % ss.buffer.sectorSize
+		end := ss.buffer.sectorSize
+		if remain := ss.length - sectorIndex*ss.buffer.sectorSize; remain < end {
+			end = remain
+		}
+		copied := copy(p[n:], ss.buffer.buf[sectorIndex][sectorOffset:end])
+		n += copied
+		offset += uint64(copied)
+	}
+	return n
+}
+
+// StreamDownload returns a seekable reader of the file with the given dx path. Sequential
+// reads are served from segments prefetched ahead of the consumer
+func (client *StorageClient) StreamDownload(path string) (DownloadReader, error) {
+	dxPath, err := storage.NewDxPath(path)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := client.fileSystem.OpenDxFile(dxPath)
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
+	defer entry.SetTimeAccess(time.Now())
+
+	snap, err := entry.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return client.newStreamReader(snap), nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestStreamSegment_ReadAt(t *testing.T) {
+	sectorSize := uint64(16)
+	data := make([]byte, 40)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	buffer := newDownloadBuffer(uint64(len(data)), sectorSize)
+	if _, err := buffer.WriteAt(data, 0); err != nil {
+		t.Fatal(err)
+	}
+	segment := &streamSegment{buffer: buffer, length: uint64(len(data))}
+
+	tests := []struct {
+		offset uint64
+		size   int
+	}{
+		{0, 40},
+		{0, 100},
+		{10, 12},
+		{16, 16},
+		{30, 5},
+		{39, 10},
+	}
+	for _, test := range tests {
+		p := make([]byte, test.size)
+		n := segment.readAt(p, test.offset)
+		expect := data[test.offset:]
+		if len(expect) > test.size {
+			expect = expect[:test.size]
+		}
+		if !bytes.Equal(p[:n], expect) {
+			t.Errorf("read at %v size %v: got %v, expect %v", test.offset, test.size, p[:n], expect)
+		}
+	}
+}
+
+func TestStreamReader_AdaptWindow(t *testing.T) {
+	sr := &streamReader{window: StreamPrefetchMinSegments}
+	segment := &streamSegment{download: &download{endTime: time.Now()}}
+
+	// the consumer keeps waiting for segments, window grows until the max
+	for i := 0; i < 10; i++ {
+		sr.adaptWindow(segment, true)
+	}
+	if sr.window != StreamPrefetchMaxSegments {
+		t.Fatalf("window should grow to %v, got %v", StreamPrefetchMaxSegments, sr.window)
+	}
+
+	// segments are consumed right after downloaded, window stays
+	sr.adaptWindow(segment, false)
+	if sr.window != StreamPrefetchMaxSegments {
+		t.Fatalf("window should stay at %v, got %v", StreamPrefetchMaxSegments, sr.window)
+	}
+
+	// segments are idle in memory, window shrinks
+	segment.download.endTime = time.Now().Add(-2 * StreamPrefetchIdleThreshold)
+	sr.adaptWindow(segment, false)
+	if sr.window != StreamPrefetchMaxSegments-1 {
+		t.Fatalf("window should shrink to %v, got %v", StreamPrefetchMaxSegments-1, sr.window)
+	}
+}
+
+func TestStreamReader_Seek(t *testing.T) {
+	sr := &streamReader{size: 100}
+
+	tests := []struct {
+		offset int64
+		whence int
+		expect int64
+		err    bool
+	}{
+		{10, io.SeekStart, 10, false},
+		{5, io.SeekCurrent, 15, false},
+		{-20, io.SeekEnd, 80, false},
+		{20, io.SeekEnd, 120, false},
+		{-200, io.SeekCurrent, 0, true},
+		{0, 3, 0, true},
+	}
+	for i, test := range tests {
+		pos, err := sr.Seek(test.offset, test.whence)
+		if (err != nil) != test.err {
+			t.Fatalf("test %d: expect error %v, got %v", i, test.err, err)
+		}
+		if err == nil && pos != test.expect {
+			t.Errorf("test %d: expect position %v, got %v", i, test.expect, pos)
+		}
+	}
+
+	// the read beyond the end of the file returns EOF without downloading
+	if _, err := sr.Read(make([]byte, 10)); err != io.EOF {
+		t.Errorf("read beyond the end: expect %v, got %v", io.EOF, err)
+	}
+	sr.closed = true
+	if _, err := sr.Seek(0, io.SeekStart); err != errStreamReaderClosed {
+		t.Errorf("seek after closed: expect %v, got %v", errStreamReaderClosed, err)
+	}
+}
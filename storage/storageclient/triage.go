@@ -0,0 +1,289 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
+)
+
+// After the mass loss of storage hosts, repairing the files one by one in the order of the upload
+// heap may spend the remaining bandwidth of the hosts on files that are healthy enough, while the
+// files close to unrecoverable lose more hosts meanwhile. In the triage mode the repairs are paused,
+// the files are classified by what is left on the hosts, and the at-risk files are downloaded to the
+// local disk first, lowest health first. The repairs are then resumed from the local copies.
+
+const (
+	// TriageRecoverable is the class of the file that is healthy enough, or has a local copy to be
+	// repaired from
+	TriageRecoverable = "recoverable"
+
+	// TriageAtRisk is the class of the file still recoverable from the storage hosts, but with the
+	// health below the repair threshold and no local copy
+	TriageAtRisk = "at-risk"
+
+	// TriageLost is the class of the file no longer recoverable from the storage hosts, and with
+	// no local copy
+	TriageLost = "lost"
+)
+
+var errTriageRunning = errors.New("the triage is already running")
+
+type (
+	// TriageFile is the triage result of a file
+	TriageFile struct {
+		DxPath    string `json:"dxpath"`
+		Health    uint32 `json:"health"`
+		Class     string `json:"class"`
+		Recovered bool   `json:"recovered"`
+		LocalPath string `json:"localpath,omitempty"`
+		Error     string `json:"error,omitempty"`
+	}
+
+	// TriageReport is the report of the triage, with the files grouped by class. The at-risk files
+	// are ordered by the recovery priority, and the lost files are the unrecoverable paths
+	TriageReport struct {
+		StartedAt   time.Time    `json:"startedat"`
+		FinishedAt  time.Time    `json:"finishedat"`
+		RecoveryDir string       `json:"recoverydir"`
+		Recoverable []TriageFile `json:"recoverable"`
+		AtRisk      []TriageFile `json:"atrisk"`
+		Lost        []TriageFile `json:"lost"`
+	}
+
+	// triageState keeps the last triage report, and the channel closed once the running triage
+	// finishes, which is nil if no triage is running
+	triageState struct {
+		last   *TriageReport
+		paused chan struct{}
+		lock   sync.Mutex
+	}
+)
+
+// Triage pauses the repairs, classifies all files into recoverable, at-risk and lost ones, and
+// downloads the at-risk files into the recovery directory, lowest health first. The recovered
+// copy is set as the local copy of the file, and the repairs are resumed afterwards
+func (client *StorageClient) Triage(ctx context.Context, recoveryDir string) (report TriageReport, err error) {
+	if !filepath.IsAbs(recoveryDir) {
+		return report, errors.New("the recovery directory must be an absolute path")
+	}
+	if err = client.tm.Add(); err != nil {
+		return
+	}
+	defer client.tm.Done()
+
+	if err = client.triage.pause(); err != nil {
+		return
+	}
+	defer func() {
+		client.triage.resume(report)
+		// bubble the health so that the repairs pick up the recovered copies
+		if err := client.fileSystem.InitAndUpdateDirMetadata(storage.RootDxPath()); err != nil {
+			client.log.Warn("failed to update the metadata after triage", "err", err)
+		}
+	}()
+
+	report = TriageReport{StartedAt: time.Now(), RecoveryDir: recoveryDir}
+	files, err := client.classifyFiles()
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		switch f.Class {
+		case TriageRecoverable:
+			report.Recoverable = append(report.Recoverable, f)
+		case TriageAtRisk:
+			report.AtRisk = append(report.AtRisk, f)
+		default:
+			report.Lost = append(report.Lost, f)
+		}
+	}
+	sortByHealth(report.AtRisk)
+
+	for i := range report.AtRisk {
+		if ctx.Err() != nil {
+			break
+		}
+		f := &report.AtRisk[i]
+		if f.LocalPath, err = client.recoverFile(ctx, f.DxPath, recoveryDir); err != nil {
+			client.log.Warn("failed to recover the at-risk file", "dxpath", f.DxPath, "err", err)
+			f.Error, f.LocalPath = err.Error(), ""
+			continue
+		}
+		f.Recovered = true
+	}
+	report.FinishedAt = time.Now()
+	return report, ctx.Err()
+}
+
+// LastTriage returns the report of the last triage
+func (client *StorageClient) LastTriage() (TriageReport, bool) {
+	client.triage.lock.Lock()
+	defer client.triage.lock.Unlock()
+	if client.triage.last == nil {
+		return TriageReport{}, false
+	}
+	return *client.triage.last, true
+}
+
+// classifyFiles goes through all files of the storage client and classifies them
+func (client *StorageClient) classifyFiles() ([]TriageFile, error) {
+	table := client.contractManager.HostHealthMap()
+	var files []TriageFile
+	err := filepath.Walk(client.staticFilesDir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != storage.DxFileExt {
+			return nil
+		}
+		dxPath, err := storage.NewDxPath(strings.TrimSuffix(strings.TrimPrefix(path, client.staticFilesDir), storage.DxFileExt))
+		if err != nil {
+			return err
+		}
+		entry, err := client.fileSystem.OpenDxFile(dxPath)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer entry.Close()
+
+		health, stuckHealth, _ := entry.Health(table)
+		if stuckHealth < health {
+			health = stuckHealth
+		}
+		// the empty file has nothing to lose
+		if entry.FileSize() == 0 {
+			health = dxfile.CompleteHealthThreshold
+		}
+		files = append(files, TriageFile{
+			DxPath: dxPath.Path,
+			Health: health,
+			Class:  triageClass(health, hasLocalCopy(string(entry.LocalPath()), entry.FileSize())),
+		})
+		return nil
+	})
+	return files, err
+}
+
+// recoverFile downloads the file into the recovery directory, and sets the recovered copy as
+// the local copy of the file
+func (client *StorageClient) recoverFile(ctx context.Context, dxPath, recoveryDir string) (string, error) {
+	path, err := storage.NewDxPath(dxPath)
+	if err != nil {
+		return "", err
+	}
+	localPath := filepath.Join(recoveryDir, filepath.FromSlash(dxPath))
+	if err := os.MkdirAll(filepath.Dir(localPath), 0700); err != nil {
+		return "", err
+	}
+
+	d, err := client.createDownload(storage.DownloadParameters{
+		RemoteFilePath:   dxPath,
+		WriteToLocalPath: localPath,
+	})
+	if err != nil {
+		return "", err
+	}
+	select {
+	case <-d.completeChan:
+	case <-ctx.Done():
+		d.fail(ctx.Err())
+		return "", ctx.Err()
+	case <-client.tm.StopChan():
+		d.fail(errors.New("storage client is shutdown"))
+		return "", errors.New("storage client is shutdown")
+	}
+	if err := d.Err(); err != nil {
+		return "", err
+	}
+
+	entry, err := client.fileSystem.OpenDxFile(path)
+	if err != nil {
+		return "", err
+	}
+	defer entry.Close()
+	return localPath, entry.SetLocalPath(storage.SysPath(localPath))
+}
+
+// waitTriage blocks the repairs until the running triage finishes. False is returned if the
+// storage client is shutdown meanwhile
+func (client *StorageClient) waitTriage() bool {
+	client.triage.lock.Lock()
+	paused := client.triage.paused
+	client.triage.lock.Unlock()
+	if paused == nil {
+		return true
+	}
+	select {
+	case <-paused:
+		return true
+	case <-client.tm.StopChan():
+		return false
+	}
+}
+
+// pause marks the triage running, which pauses the repairs
+func (ts *triageState) pause() error {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	if ts.paused != nil {
+		return errTriageRunning
+	}
+	ts.paused = make(chan struct{})
+	return nil
+}
+
+// resume records the triage report and resumes the repairs
+func (ts *triageState) resume(report TriageReport) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	ts.last = &report
+	close(ts.paused)
+	ts.paused = nil
+}
+
+// triageClass classifies the file by its health and whether it has a local copy to be repaired from
+func triageClass(health uint32, hasLocal bool) string {
+	switch {
+	case hasLocal || health >= dxfile.RepairHealthThreshold:
+		return TriageRecoverable
+	case health >= dxfile.StuckThreshold:
+		return TriageAtRisk
+	default:
+		return TriageLost
+	}
+}
+
+// hasLocalCopy checks whether the local copy exists with the file size
+func hasLocalCopy(localPath string, fileSize uint64) bool {
+	if localPath == "" {
+		return false
+	}
+	info, err := os.Stat(localPath)
+	return err == nil && !info.IsDir() && uint64(info.Size()) == fileSize
+}
+
+// sortByHealth sorts the files by health in ascending order, so that the file closest to be
+// unrecoverable is recovered first
+func sortByHealth(files []TriageFile) {
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Health < files[j].Health
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTriageClass(t *testing.T) {
+	tests := []struct {
+		health   uint32
+		hasLocal bool
+		class    string
+	}{
+		{200, false, TriageRecoverable},
+		{175, false, TriageRecoverable},
+		{174, false, TriageAtRisk},
+		{100, false, TriageAtRisk},
+		{99, false, TriageLost},
+		{0, false, TriageLost},
+		{0, true, TriageRecoverable},
+		{150, true, TriageRecoverable},
+	}
+	for _, test := range tests {
+		if class := triageClass(test.health, test.hasLocal); class != test.class {
+			t.Errorf("health %v with local copy %v: expect class %v, got %v", test.health, test.hasLocal, test.class, class)
+		}
+	}
+}
+
+func TestHasLocalCopy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "triage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "local")
+	if err := ioutil.WriteFile(path, make([]byte, 10), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if !hasLocalCopy(path, 10) {
+		t.Error("the local copy with the file size shall be found")
+	}
+	if hasLocalCopy(path, 11) {
+		t.Error("the local copy with a different size shall not be used")
+	}
+	if hasLocalCopy(filepath.Join(dir, "missing"), 10) || hasLocalCopy("", 0) {
+		t.Error("the missing local copy shall not be found")
+	}
+}
+
+func TestTriageState(t *testing.T) {
+	client := &StorageClient{triage: new(triageState)}
+	if !client.waitTriage() {
+		t.Fatal("the repairs shall not wait without triage running")
+	}
+	if err := client.triage.pause(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.triage.pause(); err != errTriageRunning {
+		t.Errorf("expect error %v, got %v", errTriageRunning, err)
+	}
+
+	waited := make(chan bool)
+	go func() { waited <- client.waitTriage() }()
+	select {
+	case <-waited:
+		t.Fatal("the repairs shall wait for the running triage")
+	default:
+	}
+	client.triage.resume(TriageReport{Lost: []TriageFile{{DxPath: "lost"}}})
+	if !<-waited {
+		t.Error("the repairs shall resume once the triage finishes")
+	}
+	report, exist := client.LastTriage()
+	if !exist || len(report.Lost) != 1 {
+		t.Errorf("the last triage report shall be kept, got %+v", report)
+	}
+}
+
+func TestSortByHealth(t *testing.T) {
+	files := []TriageFile{{DxPath: "a", Health: 150}, {DxPath: "b", Health: 100}, {DxPath: "c", Health: 174}, {DxPath: "d", Health: 100}}
+	sortByHealth(files)
+	expect := []string{"b", "d", "a", "c"}
+	for i, f := range files {
+		if f.DxPath != expect[i] {
+			t.Fatalf("expect order %v, got %+v", expect, files)
+		}
+	}
+}
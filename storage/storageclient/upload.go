@@ -5,6 +5,7 @@
 package storageclient
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"os"
@@ -18,27 +19,39 @@ import (
 
 // Upload instructs the storage client to start tracking a file. The storage client will
 // automatically upload and repair tracked files using a background loop.
-func (client *StorageClient) Upload(up storage.FileUploadParams) error {
+func (client *StorageClient) Upload(ctx context.Context, up storage.FileUploadParams) error {
+	_, err := client.startUpload(ctx, up)
+	return err
+}
+
+// startUpload starts tracking the file to upload, and returns the id of the upload operation.
+// The context only bounds the setup of the upload: once the segments are pushed to the
+// workers, the upload continues in the background and is cancelled by the operation id
+func (client *StorageClient) startUpload(ctx context.Context, up storage.FileUploadParams) (string, error) {
 	if err := client.tm.Add(); err != nil {
-		return err
+		return "", err
 	}
 	defer client.tm.Done()
 
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
 	// Check whether file is a directory
 	sourceInfo, err := os.Stat(up.Source)
 	if err != nil {
-		return fmt.Errorf("unable to stat input file, error: %v", err)
+		return "", fmt.Errorf("unable to stat input file, error: %v", err)
 	}
 	if sourceInfo.IsDir() {
-		return dxdir.ErrUploadDirectory
+		return "", dxdir.ErrUploadDirectory
 	}
 
 	file, err := os.Open(up.Source)
 	if err != nil {
-		return fmt.Errorf("unable to open the source file, error: %v", err)
+		return "", fmt.Errorf("unable to open the source file, error: %v", err)
 	}
 	if err := file.Close(); err != nil {
-		return err
+		return "", err
 	}
 
 	// Delete existing file if Override mode
@@ -58,7 +71,7 @@ func (client *StorageClient) Upload(up storage.FileUploadParams) error {
 	// requiredContracts = ceil(min + redundant/2)
 	requiredContracts := math.Ceil(float64(up.ErasureCode.NumSectors()+up.ErasureCode.MinSectors()) / 2)
 	if numContracts < uint64(requiredContracts) {
-		return fmt.Errorf("not enough contracts to upload file: got %v, needed %v", numContracts, (up.ErasureCode.NumSectors()+up.ErasureCode.MinSectors())/2)
+		return "", fmt.Errorf("not enough contracts to upload file: got %v, needed %v", numContracts, (up.ErasureCode.NumSectors()+up.ErasureCode.MinSectors())/2)
 	}
 
 	dirDxPath := up.DxPath
@@ -67,44 +80,87 @@ func (client *StorageClient) Upload(up storage.FileUploadParams) error {
 	dxDirEntry, err := client.fileSystem.NewDxDir(dirDxPath)
 
 	if err != os.ErrExist && err != nil {
-		return fmt.Errorf("unable to create dx directory for new file, error: %v", err)
+		return "", fmt.Errorf("unable to create dx directory for new file, error: %v", err)
 	} else if err == nil {
 		if err := dxDirEntry.Close(); err != nil {
-			return err
+			return "", err
 		}
 	}
 	//client.log.Error("test error for NewDxDir in upload", "error", err)
 
-	cipherKey, err := crypto.GenerateCipherKey(crypto.GCMCipherCode)
+	// the dx file is not created if the caller gave up already
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	// the public file is not encrypted, so that anyone could read it from the hosts
+	cipherCode := crypto.GCMCipherCode
+	if up.Public {
+		cipherCode = crypto.PlainCipherCode
+	}
+	cipherKey, err := crypto.GenerateCipherKey(cipherCode)
 	if err != nil {
-		return fmt.Errorf("generate cipher key error: %v", err)
+		return "", fmt.Errorf("generate cipher key error: %v", err)
 	}
 
 	// Create the DxFile and add to client
 	entry, err := client.fileSystem.NewDxFile(up.DxPath, storage.SysPath(up.Source), false, up.ErasureCode, cipherKey, uint64(sourceInfo.Size()), sourceInfo.Mode())
 
 	if err != nil {
-		return fmt.Errorf("could not create a new dx file, error: %v", err)
+		return "", fmt.Errorf("could not create a new dx file, error: %v", err)
 	}
 	if sourceInfo.Size() == 0 {
-		return fmt.Errorf("source file size is 0, fileName: %s", sourceInfo.Name())
+		return "", fmt.Errorf("source file size is 0, fileName: %s", sourceInfo.Name())
+	}
+	if up.Public {
+		if err := entry.SetPublic(true); err != nil {
+			return "", fmt.Errorf("could not mark the dx file as public, error: %v", err)
+		}
 	}
 
+	// Store the checksum of the source to verify the downloads
+	client.setFileChecksum(entry, up.Source)
+
 	// Update the health of the DxFile directory recursively to ensure the health is updated with the new file
 	go client.fileSystem.InitAndUpdateDirMetadata(dirDxPath)
 
 	nilHostHealthInfoTable := make(storage.HostHealthInfoTable)
 
-	// Send the upload to the repair loop
+	// Register the upload operation, which deletes the file once cancelled
+	op := client.operations.add(OperationUpload, up.DxPath.Path)
+	op.setCancel(func() {
+		if err := client.DeleteFile(up.DxPath); err != nil {
+			client.log.Warn("failed to delete the file of the cancelled upload", "dxpath", up.DxPath.Path, "err", err)
+		}
+	})
+
+	// Issue the signed upload receipt once all segments are uploaded
+	op.setComplete(func(status OperationStatus) {
+		go func() {
+			if _, err := client.issueUploadReceipt(up.DxPath, status); err != nil {
+				client.log.Warn("failed to issue the upload receipt", "dxpath", up.DxPath.Path, "err", err)
+			}
+			client.recordFileEvent(up.DxPath, FileEvent{Type: FileEventUpload})
+		}()
+	})
+
+	// Send the upload to the repair loop. The file just created is deleted if the caller
+	// gave up while the hosts were refreshed
 	hosts := client.refreshHostsAndWorkers()
+	if err := ctx.Err(); err != nil {
+		client.CancelOperation(op.status.ID)
+		return "", err
+	}
 
 	if err := client.createAndPushSegments([]*dxfile.FileSetEntryWithID{entry}, hosts, targetUnstuckSegments, nilHostHealthInfoTable); err != nil {
-		return err
+		op.finish(err)
+		return "", err
 	}
+	op.markSegmentsCreated()
 
 	select {
 	case client.uploadHeap.segmentComing <- struct{}{}:
 	default:
 	}
-	return nil
+	return op.status.ID, nil
 }
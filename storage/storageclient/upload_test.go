@@ -5,6 +5,8 @@
 package storageclient
 
 import (
+	"container/heap"
+	"context"
 	"encoding/binary"
 	"io"
 	"io/ioutil"
@@ -60,13 +62,25 @@ func testUploadDirectory(t *testing.T) {
 		ErasureCode: ec,
 	}
 
-	err = rt.Client.Upload(params)
+	err = rt.Client.Upload(context.Background(), params)
 	if err == nil {
 		t.Fatal("expected Upload to fail with empty directory as source")
 	}
 }
 
 /***************** Upload Business Logic Test Case For Each Critical Function ***********************/
+// TestUploadCancelledContext checks that the upload is not set up once the caller gave up
+func TestUploadCancelledContext(t *testing.T) {
+	client := &StorageClient{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.startUpload(ctx, storage.FileUploadParams{Source: "nonexistent"})
+	if err != context.Canceled {
+		t.Errorf("expect %v, got %v", context.Canceled, err)
+	}
+}
+
 func TestDirMetadata(t *testing.T) {
 	storage.ENV = storage.EnvTest
 
@@ -423,3 +437,82 @@ func getDxPathFromPath(root string, path string) storage.DxPath {
 	}
 	return storage.RootDxPath()
 }
+
+func TestUploadSegmentHeap_Less(t *testing.T) {
+	now := time.Now()
+	cold := now.Add(-2 * RepairHotFileWindow)
+	segments := []*unfinishedUploadSegment{
+		{sectorsCompletedNum: 1, sectorsAllNeedNum: 10, timeAccess: cold},
+		{sectorsCompletedNum: 5, sectorsAllNeedNum: 10, timeAccess: now},
+		{sectorsCompletedNum: 2, sectorsAllNeedNum: 10, timeAccess: now},
+		{sectorsCompletedNum: 9, sectorsAllNeedNum: 10, timeAccess: cold, priority: 1},
+		{sectorsCompletedNum: 9, sectorsAllNeedNum: 10, timeAccess: cold, stuck: true},
+		{sectorsCompletedNum: 9, sectorsAllNeedNum: 10, timeAccess: cold, uploadPriority: dxfile.UploadPriorityHigh},
+		{sectorsCompletedNum: 0, sectorsAllNeedNum: 10, timeAccess: now, stuck: true, uploadPriority: dxfile.UploadPriorityBackground},
+	}
+
+	var uh uploadSegmentHeap
+	for _, segment := range segments {
+		heap.Push(&uh, segment)
+	}
+
+	// high class, stuck, high priority, hot with low completion, hot with high completion, cold,
+	// background class
+	expected := []int{5, 4, 3, 2, 1, 0, 6}
+	for _, index := range expected {
+		segment := heap.Pop(&uh).(*unfinishedUploadSegment)
+		if segment != segments[index] {
+			t.Fatalf("expect segment %v to be popped, got %+v", index, segment)
+		}
+	}
+}
+
+func TestWorker_QueueUploadSegment(t *testing.T) {
+	w := &worker{}
+	segments := []*unfinishedUploadSegment{
+		{uploadPriority: dxfile.UploadPriorityNormal},
+		{uploadPriority: dxfile.UploadPriorityBackground},
+		{uploadPriority: dxfile.UploadPriorityHigh},
+		{uploadPriority: dxfile.UploadPriorityNormal},
+		{uploadPriority: dxfile.UploadPriorityHigh},
+	}
+	for _, segment := range segments {
+		w.queueUploadSegment(segment)
+	}
+
+	// ordered by the class, and in the queued order within the same class
+	expected := []int{2, 4, 0, 3, 1}
+	for i, index := range expected {
+		if w.pendingSegments[i] != segments[index] {
+			t.Fatalf("expect segment %v at position %v, got %+v", index, i, w.pendingSegments[i])
+		}
+	}
+}
+
+func TestUnfinishedUploadSegment_Diverse(t *testing.T) {
+	uc := &unfinishedUploadSegment{
+		sectorGroups:    make([][]string, 4),
+		maxGroupSectors: 2,
+	}
+	uc.sectorGroups[0] = []string{"10.0.0.0/24", "geo:eu"}
+	if !uc.diverse([]string{"10.0.0.0/24", "geo:eu"}) {
+		t.Fatal("the second sector in the group shall be allowed")
+	}
+	uc.sectorGroups[1] = []string{"10.0.1.0/24", "geo:eu"}
+	if uc.diverse([]string{"10.0.2.0/24", "geo:eu"}) {
+		t.Fatal("the third sector in the geographic location shall not be allowed")
+	}
+	if !uc.diverse([]string{"10.0.0.0/24", "geo:us"}) {
+		t.Fatal("the second sector in the ip network shall be allowed")
+	}
+	// the sector failed to upload leaves the group
+	uc.sectorGroups[1] = nil
+	if !uc.diverse([]string{"10.0.2.0/24", "geo:eu"}) {
+		t.Fatal("the sector shall be allowed after the failed sector leaves the group")
+	}
+	uc.maxGroupSectors = 0
+	uc.sectorGroups[1] = []string{"10.0.1.0/24", "geo:eu"}
+	if !uc.diverse([]string{"10.0.2.0/24", "geo:eu"}) {
+		t.Fatal("the diversity constraint shall be disabled")
+	}
+}
@@ -33,21 +33,40 @@ const (
 
 // uploadSegmentHeap is a min-heap of priority-sorted segments that need to be either uploaded or repaired
 // The rules of priority:
-//   1) stuck first
-//   2) the lower completion percentage, the more forward when they have the same stuck status
+//   1) the higher user set upload priority class, the more forward
+//   2) stuck first
+//   3) the higher user set file priority, the more forward
+//   4) files accessed recently are more forward than cold files
+//   5) the lower completion percentage, the more forward
+//   6) the more recently the file is accessed, the more forward
 type uploadSegmentHeap []*unfinishedUploadSegment
 
 func (uch uploadSegmentHeap) Len() int { return len(uch) }
 func (uch uploadSegmentHeap) Less(i, j int) bool {
-	if uch[i].stuck == uch[j].stuck {
-		return float64(uch[i].sectorsCompletedNum)/float64(uch[i].sectorsAllNeedNum) < float64(uch[j].sectorsCompletedNum)/float64(uch[j].sectorsAllNeedNum)
+	if uch[i].uploadPriority != uch[j].uploadPriority {
+		return uch[i].uploadPriority.Before(uch[j].uploadPriority)
 	}
 
-	if uch[i].stuck {
-		return true
+	if uch[i].stuck != uch[j].stuck {
+		return uch[i].stuck
 	}
 
-	return false
+	if uch[i].priority != uch[j].priority {
+		return uch[i].priority > uch[j].priority
+	}
+
+	iHot, jHot := uch[i].isHot(), uch[j].isHot()
+	if iHot != jHot {
+		return iHot
+	}
+
+	iCompletion := float64(uch[i].sectorsCompletedNum) / float64(uch[i].sectorsAllNeedNum)
+	jCompletion := float64(uch[j].sectorsCompletedNum) / float64(uch[j].sectorsAllNeedNum)
+	if iCompletion != jCompletion {
+		return iCompletion < jCompletion
+	}
+
+	return uch[i].timeAccess.After(uch[j].timeAccess)
 }
 func (uch uploadSegmentHeap) Swap(i, j int)       { uch[i], uch[j] = uch[j], uch[i] }
 func (uch *uploadSegmentHeap) Push(x interface{}) { *uch = append(*uch, x.(*unfinishedUploadSegment)) }
@@ -88,6 +107,7 @@ func (uh *uploadHeap) push(uuc *unfinishedUploadSegment) bool {
 	if !exists {
 		uh.pendingSegments[uuc.id] = struct{}{}
 		heap.Push(&uh.heap, uuc)
+		uuc.operation.addSegment(uuc.id)
 		added = true
 	}
 	uh.mu.Unlock()
@@ -104,6 +124,35 @@ func (uh *uploadHeap) pop() (uc *unfinishedUploadSegment) {
 	return uc
 }
 
+// hasFile checks whether any segment of the file is queued in the heap, or assigned to the
+// workers to be uploaded. The upload heap must be locked by the caller
+func (uh *uploadHeap) hasFile(fid dxfile.FileID) bool {
+	for id := range uh.pendingSegments {
+		if id.fid == fid {
+			return true
+		}
+	}
+	return false
+}
+
+// setUploadPriority updates the upload priority class of the queued segments of the file, and
+// reorders the heap accordingly
+func (uh *uploadHeap) setUploadPriority(fid dxfile.FileID, priority dxfile.UploadPriority) {
+	uh.mu.Lock()
+	defer uh.mu.Unlock()
+
+	var updated bool
+	for _, uc := range uh.heap {
+		if uc.id.fid == fid && uc.uploadPriority != priority {
+			uc.uploadPriority = priority
+			updated = true
+		}
+	}
+	if updated {
+		heap.Init(&uh.heap)
+	}
+}
+
 func (client *StorageClient) createUnfinishedSegments(entry *dxfile.FileSetEntryWithID, hosts map[string]struct{}, target uploadTarget, hostHealthInfoTable storage.HostHealthInfoTable) ([]*unfinishedUploadSegment, error) {
 	ec, err := entry.ErasureCode()
 	if err != nil {
@@ -125,6 +174,10 @@ func (client *StorageClient) createUnfinishedSegments(entry *dxfile.FileSetEntry
 	// the repair loop should only be adding unstuck segments
 	var segmentIndexes []int
 	for i := 0; i < entry.NumSegments(); i++ {
+		// the hole has nothing to upload
+		if entry.IsHole(i) {
+			continue
+		}
 		if (target == targetStuckSegments) == entry.GetStuckByIndex(i) {
 			segmentIndexes = append(segmentIndexes, i)
 		}
@@ -136,6 +189,12 @@ func (client *StorageClient) createUnfinishedSegments(entry *dxfile.FileSetEntry
 		return nil, nil
 	}
 
+	// The file pinned to specific hosts could only store its sectors on the pinned hosts
+	pinnedHosts := make(map[string]struct{})
+	for _, host := range entry.PinnedHosts() {
+		pinnedHosts[host.String()] = struct{}{}
+	}
+
 	// Assemble the set of segments
 	newUnfinishedSegments := make([]*unfinishedUploadSegment, len(segmentIndexes))
 	for i, index := range segmentIndexes {
@@ -156,6 +215,7 @@ func (client *StorageClient) createUnfinishedSegments(entry *dxfile.FileSetEntry
 		}
 		newUnfinishedSegments[i] = &unfinishedUploadSegment{
 			fileEntry: entry.CopyEntry(),
+			operation: client.operations.runningUpload(entry.DxPath().Path),
 
 			id: uploadSegmentID{
 				fid:   fid,
@@ -170,15 +230,24 @@ func (client *StorageClient) createUnfinishedSegments(entry *dxfile.FileSetEntry
 			sectorsMinNeedNum: int(ec.MinSectors()),
 			sectorsAllNeedNum: int(ec.NumSectors()),
 			stuck:             entry.GetStuckByIndex(index),
+			priority:          entry.Priority(),
+			uploadPriority:    entry.UploadPriority(),
+			timeAccess:        entry.TimeAccess(),
 
 			physicalSegmentData: make([][]byte, ec.NumSectors()),
 
 			sectorSlotsStatus: make([]bool, ec.NumSectors()),
 			unusedHosts:       make(map[string]struct{}),
+
+			sectorGroups:    make([][]string, ec.NumSectors()),
+			maxGroupSectors: client.storageHostManager.MaxGroupSectors(int(ec.NumSectors())),
 		}
 
 		// Every Segment can have a different set of unused hosts.
 		for host := range hosts {
+			if _, pinned := pinnedHosts[host]; len(pinnedHosts) != 0 && !pinned {
+				continue
+			}
 			newUnfinishedSegments[i].unusedHosts[host] = struct{}{}
 		}
 	}
@@ -194,8 +263,15 @@ func (client *StorageClient) createUnfinishedSegments(entry *dxfile.FileSetEntry
 		}
 		for sectorIndex, sectorSet := range sectors {
 			for _, sector := range sectorSet {
+				// The sector stored outside the pinned hosts is not counted, and will be
+				// uploaded to a pinned host during repair
+				if !entry.IsPinnedHost(sector.HostID) {
+					newUnfinishedSegments[i].addReplacedHost(sector.HostID)
+					continue
+				}
 				contractID := client.contractManager.GetStorageContractSet().GetContractIDByHostID(sector.HostID)
 				if meta, ok := client.contractManager.GetStorageContractSet().RetrieveContractMetaData(contractID); !ok || !meta.Status.RenewAbility {
+					newUnfinishedSegments[i].addReplacedHost(sector.HostID)
 					continue
 				}
 
@@ -205,6 +281,7 @@ func (client *StorageClient) createUnfinishedSegments(entry *dxfile.FileSetEntry
 				if exists && !redundantSector {
 					newUnfinishedSegments[i].sectorSlotsStatus[sectorIndex] = true
 					newUnfinishedSegments[i].sectorsCompletedNum++
+					newUnfinishedSegments[i].sectorGroups[sectorIndex] = client.storageHostManager.HostDiversityGroups(sector.HostID)
 					delete(newUnfinishedSegments[i].unusedHosts, sector.HostID.String())
 				} else if exists {
 					delete(newUnfinishedSegments[i].unusedHosts, sector.HostID.String())
@@ -412,8 +489,9 @@ func (client *StorageClient) openDxFile(path storage.DxPath, target uploadTarget
 
 // doProcessNextSegment takes the next segment from the segment heap and prepares it for upload
 func (client *StorageClient) doProcessNextSegment(uuc *unfinishedUploadSegment) error {
-	// Block until there is enough memory, and then upload segment asynchronously
-	if !client.memoryManager.Request(uuc.memoryNeeded, false) {
+	// Block until there is enough memory, and then upload segment asynchronously. The
+	// segments in the high upload priority class are served before the waiting segments
+	if !client.memoryManager.Request(uuc.memoryNeeded, uuc.uploadPriority == dxfile.UploadPriorityHigh) {
 		return errors.New("can't obtain enough memory")
 	}
 
@@ -463,6 +541,12 @@ func (client *StorageClient) uploadOrRepair() {
 			continue
 		}
 
+		// Drop the segment of the cancelled upload before any memory is requested
+		if nextSegment.operation.isCancelled() {
+			nextSegment.operation.segmentDone(nextSegment.id)
+			goto LOOP
+		}
+
 		// If the num of workers in worker pool is not enough to cover the tasks, we will
 		// mark the segment as stuck
 		client.lock.Lock()
@@ -474,6 +558,7 @@ func (client *StorageClient) uploadOrRepair() {
 			if err != nil {
 				client.log.Error("Unable to mark segment as stuck and close", "err", err)
 			}
+			nextSegment.operation.segmentDone(nextSegment.id)
 			goto LOOP
 		}
 
@@ -485,6 +570,7 @@ func (client *StorageClient) uploadOrRepair() {
 			if err != nil {
 				client.log.Error("Unable to mark segment as stuck and close", "err", err)
 			}
+			nextSegment.operation.segmentDone(nextSegment.id)
 			goto LOOP
 		}
 		consecutiveSegmentUploads++
@@ -566,6 +652,11 @@ func (client *StorageClient) uploadLoop() {
 			return
 		}
 
+		// Wait until the triage recovers the at-risk files
+		if !client.waitTriage() {
+			return
+		}
+
 		// Check whether a repair is needed of root dir. If the root dir health is more than
 		// RepairHealthThreshold, it is not necessary to upload any sectors
 		rootMetadata, err := client.dirMetadata(storage.RootDxPath())
@@ -0,0 +1,28 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
+)
+
+// SetFilePriority sets the upload priority class of the file. The segments of the files in the
+// high class are uploaded and repaired ahead of the others, and the segments of the files in
+// the background class only after all the others. The segments already queued in the upload
+// heap are reordered immediately
+func (client *StorageClient) SetFilePriority(dxPath storage.DxPath, priority dxfile.UploadPriority) error {
+	entry, err := client.fileSystem.OpenDxFile(dxPath)
+	if err != nil {
+		return err
+	}
+	defer entry.Close()
+
+	if err = entry.SetUploadPriority(priority); err != nil {
+		return err
+	}
+	client.uploadHeap.setUploadPriority(entry.UID(), priority)
+	return nil
+}
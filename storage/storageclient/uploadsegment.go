@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage"
 	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
 )
@@ -34,6 +35,12 @@ type unfinishedUploadSegment struct {
 	fileEntry *dxfile.FileSetEntryWithID
 	threadUID int
 
+	// the upload operation of the segment, nil if the segment is being repaired
+	operation *operation
+
+	// the spill file of the physical data, nil if the data is kept in memory
+	spill *segmentSpill
+
 	// Information about the segment within the file
 	// 	+ index: 	array index within dxfile that represents local file in memory
 	// 	+ offset: 	actual location in byte unit offset of the segment within the file
@@ -52,6 +59,16 @@ type unfinishedUploadSegment struct {
 	stuck       bool // flag whether the segment was stuck during upload
 	stuckRepair bool // flag if the segment was set 'true' for repair by the stuck loop
 
+	priority       uint32                // user set repair priority of the file
+	uploadPriority dxfile.UploadPriority // user set upload priority class of the file
+	timeAccess     time.Time             // last access time of the file
+
+	dispatchTime   time.Time // the time the segment was first dispatched to the workers
+	lastSectorHost enode.ID  // the storage host uploaded the last completed sector
+
+	uploadedHosts []enode.ID // the storage hosts the sectors were uploaded to, recorded in the file history
+	replacedHosts []enode.ID // the storage hosts whose sectors are not counted any more
+
 	// The logical data is the data read from file of user
 	// The physical data is all the sectors encrypted and stored on disk across the network
 	logicalSegmentData  [][]byte
@@ -65,6 +82,50 @@ type unfinishedUploadSegment struct {
 	unusedHosts         map[string]struct{} // hosts that aren't yet storing any sectors or performing any work
 	workersRemain       int                 // number of inactive workers still able to upload a sector
 	workerBackups       []*worker           // workers that can be used if other workers fail
+
+	// the diversity groups of the storage host of each sector uploaded or being uploaded, and
+	// the maximum number of sectors in a diversity group, 0 means no limit
+	sectorGroups    [][]string
+	maxGroupSectors int
+}
+
+// diverse returns whether a sector can be placed on the storage host in the diversity groups
+// without exceeding the maximum number of sectors in a diversity group
+//
+// NOTE: uc.mu should be held when calling this function
+func (uc *unfinishedUploadSegment) diverse(groups []string) bool {
+	if uc.maxGroupSectors <= 0 {
+		return true
+	}
+	for _, group := range groups {
+		count := 0
+		for _, sectorGroups := range uc.sectorGroups {
+			for _, g := range sectorGroups {
+				if g == group {
+					count++
+				}
+			}
+		}
+		if count >= uc.maxGroupSectors {
+			return false
+		}
+	}
+	return true
+}
+
+// addReplacedHost adds the storage host whose sectors are not counted to the replaced hosts
+func (uc *unfinishedUploadSegment) addReplacedHost(hostID enode.ID) {
+	for _, id := range uc.replacedHosts {
+		if id == hostID {
+			return
+		}
+	}
+	uc.replacedHosts = append(uc.replacedHosts, hostID)
+}
+
+// isHot indicates whether the file of the segment is accessed recently
+func (uc *unfinishedUploadSegment) isHot() bool {
+	return time.Since(uc.timeAccess) < RepairHotFileWindow
 }
 
 // notifyBackupWorkers is called when a worker fails to upload a sector, meaning
@@ -112,6 +173,12 @@ func (client *StorageClient) dispatchSegment(uc *unfinishedUploadSegment) {
 	}
 	client.uploadHeap.mu.Unlock()
 
+	uc.mu.Lock()
+	if uc.dispatchTime.IsZero() {
+		uc.dispatchTime = client.clock.Now()
+	}
+	uc.mu.Unlock()
+
 	// Distribute the segment to each worker in the work pool, marking the number of workers that have received the segment
 	client.lock.Lock()
 	uc.workersRemain += len(client.workerPool)
@@ -128,7 +195,7 @@ func (client *StorageClient) dispatchSegment(uc *unfinishedUploadSegment) {
 func (client *StorageClient) assignSectorTaskToWorker(workers []*worker, uc *unfinishedUploadSegment) {
 	for _, w := range workers {
 		if w.isReady(uc) {
-			w.pendingSegments = append(w.pendingSegments, uc)
+			w.queueUploadSegment(uc)
 			select {
 			case w.uploadChan <- struct{}{}:
 			default:
@@ -138,26 +205,50 @@ func (client *StorageClient) assignSectorTaskToWorker(workers []*worker, uc *unf
 }
 
 // downloadLogicalSegmentData will fetch the logical segment data by sending a
-// download to the storage client's downloader, and then assign the data to the field
+// download to the storage client's downloader, and then assign the data to the field.
+// The segments of the same remote data repaired at the same time share the download
 func (client *StorageClient) downloadLogicalSegmentData(segment *unfinishedUploadSegment) error {
 	downloadLength := segment.length
 	if segment.index == uint64(segment.fileEntry.NumSegments()-1) && segment.fileEntry.FileSize()%segment.length != 0 {
 		downloadLength = segment.fileEntry.FileSize() % segment.length
 	}
 
-	// Create the download
-	buf := newDownloadBuffer(segment.length, segment.fileEntry.SectorSize())
 	snap, err := segment.fileEntry.Snapshot()
 	if err != nil {
 		return fmt.Errorf("cannot create the snapshot: %v", err)
 	}
+	key, err := repairDownloadKey(snap, segment.index, downloadLength)
+	if err != nil {
+		return err
+	}
 
+	data, shared, err := client.repairDownloads.do(key, client.tm.StopChan(), func() ([][]byte, error) {
+		return client.fetchLogicalSegmentData(segment, snap, downloadLength)
+	})
+	if err != nil {
+		return err
+	}
+	if shared {
+		client.log.Debug("repair download shared with another segment", "segmentID", segment.id)
+		if err := segment.fileEntry.DxFile.SetTimeAccess(time.Now()); err != nil {
+			client.log.Warn("failed to update the access time", "segmentID", segment.id, "err", err)
+		}
+	}
+	segment.logicalSegmentData = data
+	return nil
+}
+
+// fetchLogicalSegmentData downloads the logical data of the segment from the snapshot of
+// the file, and waits for the download to complete
+func (client *StorageClient) fetchLogicalSegmentData(segment *unfinishedUploadSegment, snap *dxfile.Snapshot, downloadLength uint64) ([][]byte, error) {
+	// Create the download
+	buf := newDownloadBuffer(segment.length, segment.fileEntry.SectorSize())
 	d, err := client.newDownload(downloadParams{
 		destination:     buf,
 		destinationType: "buffer",
 		file:            snap,
 
-		latencyTarget: 200e3, // No need to rush latency on repair downloads.
+		latencyTarget: RepairDownloadLatencyTarget, // No need to rush latency on repair downloads.
 		length:        downloadLength,
 		needsMemory:   false, // We already requested memory, the download memory fits inside of that.
 		offset:        uint64(segment.offset),
@@ -165,7 +256,7 @@ func (client *StorageClient) downloadLogicalSegmentData(segment *unfinishedUploa
 		priority:      0, // Repair downloads are completely de-prioritized.
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Register some cleanup for when the download is done.
@@ -183,14 +274,13 @@ func (client *StorageClient) downloadLogicalSegmentData(segment *unfinishedUploa
 	select {
 	case <-d.completeChan:
 	case <-client.tm.StopChan():
-		return errors.New("repair download interrupted by stop call")
+		return nil, errors.New("repair download interrupted by stop call")
 	}
 	if d.Err() != nil {
 		buf.buf = nil
-		return d.Err()
+		return nil, d.Err()
 	}
-	segment.logicalSegmentData = [][]byte(buf.buf)
-	return nil
+	return [][]byte(buf.buf), nil
 }
 
 // retrieveDataAndDispatchSegment will fetch the logical data for a segment, encode
@@ -236,6 +326,19 @@ func (client *StorageClient) retrieveDataAndDispatchSegment(segment *unfinishedU
 		return
 	}
 
+	// The segment full of zeros of the sparse file is marked as a hole instead of uploaded
+	if isZeroSegment(segment.logicalSegmentData) {
+		err = client.markHole(segment)
+		if err == nil {
+			client.memoryManager.Return(erasureCodingMemory + sectorCompletedMemory)
+			segment.memoryReleased += erasureCodingMemory + sectorCompletedMemory
+			return
+		}
+		if err != dxfile.ErrTooManyHoles {
+			client.log.Warn("failed to mark the segment as a hole", "segmentID", segment.id, "err", err)
+		}
+	}
+
 	// Encode the physical sectors from content bytes of file
 	var segmentBytes []byte
 	for _, b := range segment.logicalSegmentData {
@@ -286,6 +389,18 @@ func (client *StorageClient) retrieveDataAndDispatchSegment(segment *unfinishedU
 		client.memoryManager.Return(sectorCompletedMemory)
 		segment.memoryReleased += sectorCompletedMemory
 	}
+
+	// The upload is cancelled while the data is encoded. No worker is assigned, so the
+	// deferred cleanup returns the memory of all sectors
+	if segment.operation.isCancelled() {
+		return
+	}
+
+	// Spill the physical data to disk if the memory is saturated, so that the memory
+	// is available for the next segment while this one waits for the workers
+	if err := client.spillSegment(segment); err != nil {
+		client.log.Warn("failed to spill the segment data to disk", "segmentID", segment.id, "err", err)
+	}
 	client.dispatchSegment(segment)
 }
 
@@ -355,22 +470,41 @@ func (client *StorageClient) cleanupUploadSegment(uc *unfinishedUploadSegment) {
 	released := uc.released
 
 	// If required, remove the segment from the set of repairing segments.
+	repaired := false
 	if segmentComplete && !released {
 		uc.released = true
-		client.updateUploadSegmentStuckStatus(uc)
+		repaired = uc.operation == nil
+		// the file of the cancelled upload is deleted
+		if !uc.operation.isCancelled() {
+			client.updateUploadSegmentStuckStatus(uc)
+		}
+		if uc.sectorsCompletedNum >= uc.sectorsAllNeedNum && !uc.dispatchTime.IsZero() {
+			client.uploadTimings.recordSegment(uc.lastSectorHost, client.clock.Since(uc.dispatchTime))
+		}
 		client.uploadHeap.mu.Lock()
 		delete(client.uploadHeap.pendingSegments, uc.id)
 		client.uploadHeap.mu.Unlock()
+		uc.operation.segmentDone(uc.id)
 	}
 
-	uc.memoryReleased += uint64(memoryReleased)
+	memoryReleased = uc.releaseMemory(memoryReleased)
 	totalMemoryReleased := uc.memoryReleased
+	if segmentComplete {
+		if err := uc.closeSpill(); err != nil {
+			client.log.Warn("failed to remove the spill file of the segment", "segmentID", uc.id, "err", err)
+		}
+	}
 	uc.mu.Unlock()
 
 	if sectorsAvailable > 0 {
 		uc.notifyBackupWorkers()
 	}
 
+	// The segment repaired by the repair loops is recorded in the file history
+	if repaired {
+		go client.recordSegmentRepair(uc)
+	}
+
 	// If required, return the memory to the storage client.
 	if memoryReleased > 0 {
 		client.memoryManager.Return(memoryReleased)
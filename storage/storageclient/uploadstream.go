@@ -0,0 +1,254 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// The upload stream uploads the data of unknown size, such as the data piped to the gdx
+// command. The data is written to the stream chunk by chunk, and staged in a file under the
+// persist directory. The staged file is uploaded once the stream is finished, and kept as the
+// local copy of the file for the repair, the same as the source file of the ordinary upload.
+
+var (
+	errUnknownUploadStream = errors.New("unknown upload stream")
+	errEmptyUploadStream   = errors.New("no data written to the upload stream")
+)
+
+type (
+	// UploadStreamReport is the report of the finished upload stream
+	UploadStreamReport struct {
+		ID          string      `json:"id"`
+		DxPath      string      `json:"dxpath"`
+		Size        uint64      `json:"size"`
+		Chunks      uint64      `json:"chunks"`
+		SHA256      string      `json:"sha256"`
+		MerkleRoot  common.Hash `json:"merkleRoot"`
+		OperationID string      `json:"operationID"`
+	}
+
+	// uploadStream is the stream being written
+	uploadStream struct {
+		id     string
+		dxPath storage.DxPath
+		file   *os.File
+		size   uint64
+		chunks uint64
+
+		// the sha256 of the data, and the merkle tree of the data in leaves of
+		// UploadStreamLeafSize, with the data not filling a leaf yet
+		checksum hash.Hash
+		tree     *merkle.Tree
+		leaf     []byte
+
+		lock sync.Mutex
+	}
+
+	// uploadStreamSet is the upload streams being written
+	uploadStreamSet struct {
+		streams map[string]*uploadStream
+		lock    sync.Mutex
+	}
+)
+
+// newUploadStreamSet creates an empty upload stream set
+func newUploadStreamSet() *uploadStreamSet {
+	return &uploadStreamSet{
+		streams: make(map[string]*uploadStream),
+	}
+}
+
+// StartUploadStream starts the upload stream to the dxPath, and returns the stream id
+func (client *StorageClient) StartUploadStream(dxPath storage.DxPath) (string, error) {
+	if err := client.tm.Add(); err != nil {
+		return "", err
+	}
+	defer client.tm.Done()
+
+	dir := client.uploadStreamDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(b)
+	file, err := os.OpenFile(filepath.Join(dir, id+uploadStreamPartSuffix), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", err
+	}
+
+	client.uploadStreams.lock.Lock()
+	client.uploadStreams.streams[id] = &uploadStream{
+		id:       id,
+		dxPath:   dxPath,
+		file:     file,
+		checksum: sha256.New(),
+		tree:     merkle.NewTree(sha256.New()),
+	}
+	client.uploadStreams.lock.Unlock()
+	return id, nil
+}
+
+// WriteUploadStream appends the chunk to the upload stream, and returns the size written so far
+func (client *StorageClient) WriteUploadStream(id string, chunk []byte) (uint64, error) {
+	stream, err := client.uploadStream(id)
+	if err != nil {
+		return 0, err
+	}
+	stream.lock.Lock()
+	defer stream.lock.Unlock()
+	if stream.file == nil {
+		return 0, errUnknownUploadStream
+	}
+	if _, err := stream.file.Write(chunk); err != nil {
+		return 0, err
+	}
+	stream.checksum.Write(chunk)
+	stream.leaf = append(stream.leaf, chunk...)
+	for len(stream.leaf) >= UploadStreamLeafSize {
+		stream.tree.PushLeaf(stream.leaf[:UploadStreamLeafSize])
+		stream.leaf = stream.leaf[UploadStreamLeafSize:]
+	}
+	stream.size += uint64(len(chunk))
+	stream.chunks++
+	return stream.size, nil
+}
+
+// FinishUploadStream finishes the upload stream, and starts to upload the data staged. The
+// size, sha256 and merkle root of the data are reported, so that the writer can verify the
+// data received by the storage client
+func (client *StorageClient) FinishUploadStream(ctx context.Context, id string) (report UploadStreamReport, err error) {
+	stream, err := client.removeUploadStream(id)
+	if err != nil {
+		return
+	}
+	stream.lock.Lock()
+	defer stream.lock.Unlock()
+
+	partPath := stream.file.Name()
+	source := strings.TrimSuffix(partPath, uploadStreamPartSuffix)
+	defer func() {
+		if err != nil {
+			os.Remove(partPath)
+			os.Remove(source)
+		}
+	}()
+	if err = stream.file.Close(); err != nil {
+		return
+	}
+	stream.file = nil
+	if stream.size == 0 {
+		err = errEmptyUploadStream
+		return
+	}
+	if err = os.Rename(partPath, source); err != nil {
+		return
+	}
+
+	opID, err := client.startUpload(ctx, storage.FileUploadParams{
+		Source: source,
+		DxPath: stream.dxPath,
+		Mode:   storage.Override,
+	})
+	if err != nil {
+		return
+	}
+	return stream.report(opID), nil
+}
+
+// AbortUploadStream aborts the upload stream, and removes the data staged
+func (client *StorageClient) AbortUploadStream(id string) error {
+	stream, err := client.removeUploadStream(id)
+	if err != nil {
+		return err
+	}
+	stream.lock.Lock()
+	defer stream.lock.Unlock()
+	stream.file.Close()
+	err = os.Remove(stream.file.Name())
+	stream.file = nil
+	return err
+}
+
+// report returns the report of the finished stream, with the leaf not filled pushed to the
+// merkle tree
+func (stream *uploadStream) report(opID string) UploadStreamReport {
+	if len(stream.leaf) > 0 {
+		stream.tree.PushLeaf(stream.leaf)
+		stream.leaf = nil
+	}
+	return UploadStreamReport{
+		ID:          stream.id,
+		DxPath:      stream.dxPath.Path,
+		Size:        stream.size,
+		Chunks:      stream.chunks,
+		SHA256:      hex.EncodeToString(stream.checksum.Sum(nil)),
+		MerkleRoot:  common.BytesToHash(stream.tree.Root()),
+		OperationID: opID,
+	}
+}
+
+// uploadStream returns the upload stream with the id
+func (client *StorageClient) uploadStream(id string) (*uploadStream, error) {
+	client.uploadStreams.lock.Lock()
+	defer client.uploadStreams.lock.Unlock()
+	stream, exists := client.uploadStreams.streams[id]
+	if !exists {
+		return nil, errUnknownUploadStream
+	}
+	return stream, nil
+}
+
+// removeUploadStream removes the upload stream with the id from the set, so that no more
+// chunk can be written to it
+func (client *StorageClient) removeUploadStream(id string) (*uploadStream, error) {
+	client.uploadStreams.lock.Lock()
+	defer client.uploadStreams.lock.Unlock()
+	stream, exists := client.uploadStreams.streams[id]
+	if !exists {
+		return nil, errUnknownUploadStream
+	}
+	delete(client.uploadStreams.streams, id)
+	return stream, nil
+}
+
+// uploadStreamDir returns the directory the upload streams are staged in
+func (client *StorageClient) uploadStreamDir() string {
+	return filepath.Join(client.persistDir, UploadStreamDir)
+}
+
+// removeStaleUploadStreams removes the data of the upload streams not finished before the
+// storage client was closed
+func (client *StorageClient) removeStaleUploadStreams() {
+	infos, err := ioutil.ReadDir(client.uploadStreamDir())
+	if err != nil {
+		return
+	}
+	for _, info := range infos {
+		if !strings.HasSuffix(info.Name(), uploadStreamPartSuffix) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(client.uploadStreamDir(), info.Name())); err != nil {
+			client.log.Warn("failed to remove the stale upload stream", "name", info.Name(), "err", err)
+		}
+	}
+}
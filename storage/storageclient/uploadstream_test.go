@@ -0,0 +1,116 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestUploadStream(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploadstream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	client := &StorageClient{
+		persistDir:    dir,
+		uploadStreams: newUploadStreamSet(),
+		log:           log.New(),
+	}
+	dxPath, err := storage.NewDxPath("backup/db.dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := client.StartUploadStream(dxPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("0123456789"), UploadStreamLeafSize/4)
+	for offset := 0; offset < len(data); offset += 7000 {
+		end := offset + 7000
+		if end > len(data) {
+			end = len(data)
+		}
+		size, err := client.WriteUploadStream(id, data[offset:end])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != uint64(end) {
+			t.Fatalf("expect %v bytes written, got %v", end, size)
+		}
+	}
+
+	stream, err := client.uploadStream(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := stream.report("")
+	checksum := sha256.Sum256(data)
+	tree := merkle.NewTree(sha256.New())
+	for offset := 0; offset < len(data); offset += UploadStreamLeafSize {
+		end := offset + UploadStreamLeafSize
+		if end > len(data) {
+			end = len(data)
+		}
+		tree.PushLeaf(data[offset:end])
+	}
+	if report.Size != uint64(len(data)) || report.SHA256 != hex.EncodeToString(checksum[:]) ||
+		report.MerkleRoot != common.BytesToHash(tree.Root()) || report.DxPath != dxPath.Path {
+		t.Errorf("unexpected upload stream report %+v", report)
+	}
+	staged, err := ioutil.ReadFile(filepath.Join(dir, UploadStreamDir, id+uploadStreamPartSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(staged, data) {
+		t.Error("the staged data does not match the data written")
+	}
+
+	// the aborted stream is removed along with its data
+	if err := client.AbortUploadStream(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.WriteUploadStream(id, data); err != errUnknownUploadStream {
+		t.Errorf("expect %v writing the aborted stream, got %v", errUnknownUploadStream, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, UploadStreamDir, id+uploadStreamPartSuffix)); !os.IsNotExist(err) {
+		t.Error("the data of the aborted stream should be removed")
+	}
+
+	// the empty stream cannot be finished
+	id, err = client.StartUploadStream(dxPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FinishUploadStream(context.Background(), id); err != errEmptyUploadStream {
+		t.Errorf("expect %v finishing the empty stream, got %v", errEmptyUploadStream, err)
+	}
+
+	// the streams not finished are removed on start
+	if _, err = client.StartUploadStream(dxPath); err != nil {
+		t.Fatal(err)
+	}
+	client.removeStaleUploadStreams()
+	infos, err := ioutil.ReadDir(filepath.Join(dir, UploadStreamDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 0 {
+		t.Errorf("expect the stale upload streams removed, got %v files", len(infos))
+	}
+}
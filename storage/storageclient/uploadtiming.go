@@ -0,0 +1,257 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DxChainNetwork/godx/metrics"
+	"github.com/DxChainNetwork/godx/p2p/enode"
+)
+
+var (
+	uploadSegmentTimer = metrics.NewRegisteredTimer("storage/client/upload/segment", nil)
+	uploadSectorTimer  = metrics.NewRegisteredTimer("storage/client/upload/sector", nil)
+)
+
+// uploadTimings records the time used to upload the sectors and the segments, broken down
+// by the storage hosts. The time of a segment is attributed to the storage host uploading
+// the last sector of the segment, which is the host the segment upload was waiting on
+type uploadTimings struct {
+	hosts    map[enode.ID]*hostUploadTimings
+	segments []segmentTiming
+	lock     sync.Mutex
+}
+
+// hostUploadTimings is the recent sector upload time of a single storage host
+type hostUploadTimings struct {
+	sectors []time.Duration
+
+	// the number of sectors uploaded since the storage host was last scored
+	unscored int
+}
+
+// segmentTiming is the time used to upload a segment, and the storage host uploaded the
+// last sector of the segment
+type segmentTiming struct {
+	hostID   enode.ID
+	duration time.Duration
+}
+
+// UploadLatency is the percentiles of the upload time
+type UploadLatency struct {
+	P50 time.Duration `json:"p50"`
+	P95 time.Duration `json:"p95"`
+	P99 time.Duration `json:"p99"`
+}
+
+// HostUploadLatency is the upload time statistics of a storage host. The tail sectors and
+// the tail segments are the sectors uploaded to the storage host and the segments waiting on
+// the storage host, slower than the p95 of the other storage hosts. The slowdown is the ratio
+// of the sector p95 of the storage host to the sector p95 of the other storage hosts
+type HostUploadLatency struct {
+	HostID       enode.ID      `json:"hostid"`
+	Sectors      int           `json:"sectors"`
+	Sector       UploadLatency `json:"sector"`
+	TailSectors  int           `json:"tailsectors"`
+	TailSegments int           `json:"tailsegments"`
+	Slowdown     float64       `json:"slowdown"`
+	Slow         bool          `json:"slow"`
+}
+
+// SlowHostReport is the upload time statistics of all storage hosts, with the storage hosts
+// responsible for the tail latency sorted first
+type SlowHostReport struct {
+	Sector  UploadLatency       `json:"sector"`
+	Segment UploadLatency       `json:"segment"`
+	Hosts   []HostUploadLatency `json:"hosts"`
+}
+
+// newUploadTimings creates a new uploadTimings object
+func newUploadTimings() *uploadTimings {
+	return &uploadTimings{
+		hosts: make(map[enode.ID]*hostUploadTimings),
+	}
+}
+
+// recordSector records the time used to upload a sector to the storage host. True will be
+// returned if enough sectors were uploaded to the storage host since it was last scored
+func (ut *uploadTimings) recordSector(hostID enode.ID, duration time.Duration) (rescore bool) {
+	uploadSectorTimer.Update(duration)
+
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+
+	host, exists := ut.hosts[hostID]
+	if !exists {
+		host = &hostUploadTimings{}
+		ut.hosts[hostID] = host
+	}
+	host.sectors = append(host.sectors, duration)
+	if len(host.sectors) > UploadTimingSamples {
+		host.sectors = host.sectors[len(host.sectors)-UploadTimingSamples:]
+	}
+	host.unscored++
+	if host.unscored < SlowHostRescoreInterval {
+		return false
+	}
+	host.unscored = 0
+	return true
+}
+
+// seedSector seeds the sector upload time estimated by the probe of the storage host, before
+// any sector is uploaded to it. The seed ages out with the measured time like any other sample,
+// and is not counted towards rescoring the storage host
+func (ut *uploadTimings) seedSector(hostID enode.ID, estimate time.Duration) {
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+
+	if _, exists := ut.hosts[hostID]; exists {
+		return
+	}
+	ut.hosts[hostID] = &hostUploadTimings{sectors: []time.Duration{estimate}}
+}
+
+// recordSegment records the time used to upload a segment, and the storage host uploaded
+// the last sector of the segment
+func (ut *uploadTimings) recordSegment(hostID enode.ID, duration time.Duration) {
+	uploadSegmentTimer.Update(duration)
+
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+
+	ut.segments = append(ut.segments, segmentTiming{hostID: hostID, duration: duration})
+	if len(ut.segments) > UploadTimingSamples {
+		ut.segments = ut.segments[len(ut.segments)-UploadTimingSamples:]
+	}
+}
+
+// remove will remove the upload time records of the storage host
+func (ut *uploadTimings) remove(hostID enode.ID) {
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+	delete(ut.hosts, hostID)
+}
+
+// slowHostReport calculates the upload time statistics of all storage hosts. A storage host
+// with enough samples is considered slow if its share of the tail sectors exceeds the
+// SlowHostTailShare
+func (ut *uploadTimings) slowHostReport() (report SlowHostReport) {
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+
+	var sectors, segments []time.Duration
+	for _, host := range ut.hosts {
+		sectors = append(sectors, host.sectors...)
+	}
+	for _, segment := range ut.segments {
+		segments = append(segments, segment.duration)
+	}
+	report.Sector = uploadLatency(sectors)
+	report.Segment = uploadLatency(segments)
+
+	for id := range ut.hosts {
+		report.Hosts = append(report.Hosts, ut.hostUploadLatency(id))
+	}
+	sort.Slice(report.Hosts, func(i, j int) bool {
+		if report.Hosts[i].Slow != report.Hosts[j].Slow {
+			return report.Hosts[i].Slow
+		}
+		return report.Hosts[i].Slowdown > report.Hosts[j].Slowdown
+	})
+	return
+}
+
+// sectorLatency calculates the percentiles of the sector upload time of all storage hosts
+func (ut *uploadTimings) sectorLatency() UploadLatency {
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+
+	var sectors []time.Duration
+	for _, host := range ut.hosts {
+		sectors = append(sectors, host.sectors...)
+	}
+	return uploadLatency(sectors)
+}
+
+// hostSlowdown returns the slowdown of the storage host, which is fed into the storage
+// host evaluation. The storage host not considered slow has no slowdown
+func (ut *uploadTimings) hostSlowdown(hostID enode.ID) float64 {
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+
+	if latency := ut.hostUploadLatency(hostID); latency.Slow {
+		return latency.Slowdown
+	}
+	return 0
+}
+
+// hostUploadLatency calculates the upload time statistics of the storage host against the
+// other storage hosts, so that a storage host uploading a large share of the sectors cannot
+// hide its tail latency by moving the p95 of all storage hosts
+//
+// NOTE: ut.lock should be held when calling this function
+func (ut *uploadTimings) hostUploadLatency(hostID enode.ID) HostUploadLatency {
+	var sectors, segments, otherSectors, otherSegments []time.Duration
+	if host, exists := ut.hosts[hostID]; exists {
+		sectors = host.sectors
+	}
+	for id, host := range ut.hosts {
+		if id != hostID {
+			otherSectors = append(otherSectors, host.sectors...)
+		}
+	}
+	for _, segment := range ut.segments {
+		if segment.hostID == hostID {
+			segments = append(segments, segment.duration)
+		} else {
+			otherSegments = append(otherSegments, segment.duration)
+		}
+	}
+
+	latency := HostUploadLatency{
+		HostID:  hostID,
+		Sectors: len(sectors),
+		Sector:  uploadLatency(sectors),
+	}
+	otherSector, otherSegment := uploadLatency(otherSectors), uploadLatency(otherSegments)
+	if len(otherSectors) == 0 {
+		return latency
+	}
+	for _, duration := range sectors {
+		if duration > otherSector.P95 {
+			latency.TailSectors++
+		}
+	}
+	if len(otherSegments) != 0 {
+		for _, duration := range segments {
+			if duration > otherSegment.P95 {
+				latency.TailSegments++
+			}
+		}
+	}
+	if otherSector.P95 > 0 {
+		latency.Slowdown = float64(latency.Sector.P95) / float64(otherSector.P95)
+	}
+	latency.Slow = latency.Sectors >= SlowHostMinSamples &&
+		float64(latency.TailSectors) > SlowHostTailShare*float64(latency.Sectors)
+	return latency
+}
+
+// uploadLatency calculates the p50, p95 and p99 of the upload time
+func uploadLatency(durations []time.Duration) UploadLatency {
+	values := make([]int64, len(durations))
+	for i, duration := range durations {
+		values[i] = int64(duration)
+	}
+	ps := metrics.SamplePercentiles(values, []float64{0.5, 0.95, 0.99})
+	return UploadLatency{
+		P50: time.Duration(ps[0]),
+		P95: time.Duration(ps[1]),
+		P99: time.Duration(ps[2]),
+	}
+}
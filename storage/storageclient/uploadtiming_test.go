@@ -0,0 +1,89 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/p2p/enode"
+)
+
+func TestUploadLatency(t *testing.T) {
+	var durations []time.Duration
+	for i := 1; i <= 100; i++ {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	latency := uploadLatency(durations)
+	if latency.P50 < 50*time.Millisecond || latency.P50 > 51*time.Millisecond {
+		t.Errorf("unexpected p50 %v", latency.P50)
+	}
+	if latency.P95 < 95*time.Millisecond || latency.P95 > 96*time.Millisecond {
+		t.Errorf("unexpected p95 %v", latency.P95)
+	}
+	if latency.P99 < 99*time.Millisecond || latency.P99 > 100*time.Millisecond {
+		t.Errorf("unexpected p99 %v", latency.P99)
+	}
+	if empty := uploadLatency(nil); empty != (UploadLatency{}) {
+		t.Errorf("expected zero latency without samples, got %+v", empty)
+	}
+}
+
+func TestUploadTimings_SlowHostReport(t *testing.T) {
+	ut := newUploadTimings()
+	fast, slow, few := enode.ID{1}, enode.ID{2}, enode.ID{3}
+
+	for i := 0; i < 100; i++ {
+		ut.recordSector(fast, time.Second)
+		ut.recordSegment(fast, 2*time.Second)
+	}
+	for i := 0; i < 30; i++ {
+		ut.recordSector(slow, 10*time.Second)
+		ut.recordSegment(slow, 20*time.Second)
+	}
+	// too few samples to be considered slow
+	for i := 0; i < 3; i++ {
+		ut.recordSector(few, 30*time.Second)
+	}
+
+	report := ut.slowHostReport()
+	if len(report.Hosts) != 3 {
+		t.Fatalf("expected 3 hosts in the report, got %v", len(report.Hosts))
+	}
+	if report.Hosts[0].HostID != slow || !report.Hosts[0].Slow {
+		t.Fatalf("expected the slow host reported first, got %+v", report.Hosts[0])
+	}
+	if report.Hosts[0].TailSegments == 0 {
+		t.Error("the tail segments should be attributed to the slow host")
+	}
+	for _, host := range report.Hosts[1:] {
+		if host.Slow {
+			t.Errorf("host %v should not be considered slow", host.HostID)
+		}
+	}
+
+	if slowdown := ut.hostSlowdown(slow); slowdown <= 1 {
+		t.Errorf("expected the slowdown of the slow host above 1, got %v", slowdown)
+	}
+	if slowdown := ut.hostSlowdown(fast); slowdown != 0 {
+		t.Errorf("expected no slowdown of the fast host, got %v", slowdown)
+	}
+
+	ut.remove(slow)
+	if len(ut.slowHostReport().Hosts) != 2 {
+		t.Error("the removed host should not be reported")
+	}
+}
+
+func TestUploadTimings_Rescore(t *testing.T) {
+	ut := newUploadTimings()
+	var hostID enode.ID
+	for i := 1; i <= 2*SlowHostRescoreInterval; i++ {
+		rescore := ut.recordSector(hostID, time.Second)
+		if expected := i%SlowHostRescoreInterval == 0; rescore != expected {
+			t.Fatalf("sector %v: expected rescore %v, got %v", i, expected, rescore)
+		}
+	}
+}
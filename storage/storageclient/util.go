@@ -193,6 +193,11 @@ func (client *StorageClient) SendStorageContractCreateTx(clientAddr common.Addre
 	return client.info.StorageTx.SendContractCreateTX(clientAddr, input)
 }
 
+// BumpStorageContractTx is used to replace the pending storage contract transaction with a higher fee
+func (client *StorageClient) BumpStorageContractTx(from common.Address, txHash common.Hash, priceBump uint64, maxGasPrice *big.Int) (common.Hash, error) {
+	return client.info.StorageTx.BumpStorageContractTX(from, txHash, priceBump, maxGasPrice)
+}
+
 // SelfEnodeURL retrieves the local node's enodeURL, used to avoid storing
 // self information inf the storage host manager
 func (client *StorageClient) SelfEnodeURL() string {
@@ -0,0 +1,238 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/storageclient/filesystem/dxfile"
+)
+
+var (
+	errNoLocalCopy          = errors.New("the file has no local copy")
+	errLocalCopyNotVerified = errors.New("the local copy is not verified to be reconstructable from the storage hosts")
+	errLocalCopyChanged     = errors.New("the local copy has changed since verified")
+)
+
+// LocalCopyVerification is the result of verifying the remote reconstructability of the file
+// before deleting its local copy. The local copy is safe to delete only if the file health is
+// no less than the threshold and the segment downloaded from the storage hosts matches the
+// local copy
+type LocalCopyVerification struct {
+	DxPath    string          `json:"dxpath"`
+	LocalPath storage.SysPath `json:"localpath"`
+	Health    uint32          `json:"health"`
+	Threshold uint32          `json:"threshold"`
+
+	// the segment randomly picked, downloaded and compared with the local copy
+	Segment uint64 `json:"segment"`
+
+	SafeToDelete bool      `json:"safetodelete"`
+	VerifiedAt   time.Time `json:"verifiedat"`
+
+	// the size and modification time of the local copy when verified. The verification is
+	// no longer valid once the local copy changed
+	localSize    int64
+	localModTime time.Time
+}
+
+// matches checks whether the local copy stays the same as verified
+func (v LocalCopyVerification) matches(info os.FileInfo) bool {
+	return info.Size() == v.localSize && info.ModTime().Equal(v.localModTime)
+}
+
+// VerifyLocalCopy verifies that the file could be fully reconstructed from the storage hosts,
+// and marks the local copy safe to delete if so. The file health must be no less than the
+// threshold, and a randomly picked segment is downloaded and compared with the local copy
+func (client *StorageClient) VerifyLocalCopy(ctx context.Context, dxPath storage.DxPath, threshold uint32) (v LocalCopyVerification, err error) {
+	if threshold < dxfile.StuckThreshold || threshold > dxfile.CompleteHealthThreshold {
+		return v, fmt.Errorf("the health threshold must be within [%v, %v]", dxfile.StuckThreshold, dxfile.CompleteHealthThreshold)
+	}
+	if err = client.tm.Add(); err != nil {
+		return
+	}
+	defer client.tm.Done()
+
+	entry, err := client.fileSystem.OpenDxFile(dxPath)
+	if err != nil {
+		return
+	}
+	defer entry.Close()
+
+	v = LocalCopyVerification{
+		DxPath:    dxPath.Path,
+		LocalPath: entry.LocalPath(),
+		Threshold: threshold,
+	}
+	if v.LocalPath == "" {
+		return v, errNoLocalCopy
+	}
+	info, err := os.Stat(string(v.LocalPath))
+	if err != nil {
+		return v, err
+	}
+	if uint64(info.Size()) != entry.FileSize() {
+		return v, fmt.Errorf("the local copy has %v bytes, different from the file size %v", info.Size(), entry.FileSize())
+	}
+	v.localSize, v.localModTime = info.Size(), info.ModTime()
+
+	health, stuckHealth, _ := entry.Health(client.contractManager.HostHealthMap())
+	if stuckHealth < health {
+		health = stuckHealth
+	}
+	v.Health = health
+	if v.Health < threshold {
+		return v, nil
+	}
+
+	snap, err := entry.Snapshot()
+	if err != nil {
+		return
+	}
+	if v.Segment, err = randomSegment(snap.NumSegments()); err != nil {
+		return
+	}
+	remote, err := client.downloadSegment(ctx, snap, v.Segment)
+	if err != nil {
+		return v, fmt.Errorf("failed to download segment %v: %v", v.Segment, err)
+	}
+	local, err := readLocalRange(string(v.LocalPath), v.Segment*snap.SegmentSize(), uint64(len(remote)))
+	if err != nil {
+		return
+	}
+	if !bytes.Equal(remote, local) {
+		return v, fmt.Errorf("segment %v downloaded from the storage hosts does not match the local copy", v.Segment)
+	}
+
+	v.SafeToDelete = true
+	v.VerifiedAt = time.Now()
+	client.lock.Lock()
+	client.localVerifications[dxPath] = v
+	client.lock.Unlock()
+	return v, nil
+}
+
+// DeleteLocalCopy deletes the local copy of the file, which must be verified to be safe to
+// delete and not changed since. Afterwards the file is repaired from the storage hosts only
+func (client *StorageClient) DeleteLocalCopy(dxPath storage.DxPath) error {
+	client.lock.Lock()
+	v, exist := client.localVerifications[dxPath]
+	client.lock.Unlock()
+	if !exist || !v.SafeToDelete {
+		return errLocalCopyNotVerified
+	}
+
+	entry, err := client.fileSystem.OpenDxFile(dxPath)
+	if err != nil {
+		return err
+	}
+	defer entry.Close()
+	if entry.LocalPath() != v.LocalPath {
+		return errLocalCopyChanged
+	}
+	info, err := os.Stat(string(v.LocalPath))
+	if err != nil {
+		return err
+	}
+	if !v.matches(info) {
+		return errLocalCopyChanged
+	}
+
+	if err = os.Remove(string(v.LocalPath)); err != nil {
+		return err
+	}
+	client.lock.Lock()
+	delete(client.localVerifications, dxPath)
+	client.lock.Unlock()
+	return entry.SetLocalPath("")
+}
+
+// VerifyAndDeleteLocalCopy verifies the remote reconstructability of the file, and deletes the
+// local copy only if verified to be safe to delete
+func (client *StorageClient) VerifyAndDeleteLocalCopy(ctx context.Context, dxPath storage.DxPath, threshold uint32) (LocalCopyVerification, error) {
+	v, err := client.VerifyLocalCopy(ctx, dxPath, threshold)
+	if err != nil {
+		return v, err
+	}
+	if !v.SafeToDelete {
+		return v, fmt.Errorf("the file health %v is below the threshold %v, the local copy is kept", v.Health, threshold)
+	}
+	return v, client.DeleteLocalCopy(dxPath)
+}
+
+// downloadSegment downloads the segment of the file from the storage hosts into memory
+func (client *StorageClient) downloadSegment(ctx context.Context, snap *dxfile.Snapshot, index uint64) ([]byte, error) {
+	offset := index * snap.SegmentSize()
+	length := snap.SegmentSize()
+	if offset+length > snap.FileSize() {
+		length = snap.FileSize() - offset
+	}
+	buffer := newDownloadBuffer(length, snap.SectorSize())
+	d, err := client.newDownload(downloadParams{
+		destination:       buffer,
+		destinationType:   "buffer",
+		destinationString: snap.DxPath().Path,
+		file:              snap,
+		latencyTarget:     25e3 * time.Millisecond,
+		length:            length,
+		needsMemory:       true,
+		offset:            offset,
+		priority:          5,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-d.completeChan:
+	case <-ctx.Done():
+		d.fail(ctx.Err())
+		return nil, ctx.Err()
+	case <-client.tm.StopChan():
+		d.fail(errors.New("storage client is shutdown"))
+		return nil, errors.New("storage client is shutdown")
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	segment := &streamSegment{download: d, buffer: buffer, length: length}
+	data := make([]byte, length)
+	segment.readAt(data, 0)
+	return data, nil
+}
+
+// randomSegment picks a segment index in [0, numSegments) at random
+func randomSegment(numSegments uint64) (uint64, error) {
+	if numSegments == 0 {
+		return 0, errors.New("the file has no segments")
+	}
+	n, err := rand.Int(rand.Reader, new(big.Int).SetUint64(numSegments))
+	if err != nil {
+		return 0, err
+	}
+	return n.Uint64(), nil
+}
+
+// readLocalRange reads the byte range of the local file
+func readLocalRange(path string, offset, length uint64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data := make([]byte, length)
+	if _, err := f.ReadAt(data, int64(offset)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
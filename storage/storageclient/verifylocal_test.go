@@ -0,0 +1,86 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestLocalCopyVerificationMatches(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verifylocal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "local")
+	data := []byte("0123456789abcdef")
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readLocalRange(path, 4, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data[4:10]) {
+		t.Errorf("expect the range %s, got %s", data[4:10], got)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := LocalCopyVerification{localSize: info.Size(), localModTime: info.ModTime()}
+	if !v.matches(info) {
+		t.Fatal("the verification shall match the local copy not changed")
+	}
+	later := info.ModTime().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if info, err = os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+	if v.matches(info) {
+		t.Error("the verification shall not match the local copy modified since")
+	}
+}
+
+func TestRandomSegment(t *testing.T) {
+	if _, err := randomSegment(0); err == nil {
+		t.Error("expect error picking the segment of the empty file")
+	}
+	for i := 0; i < 100; i++ {
+		index, err := randomSegment(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index >= 3 {
+			t.Fatalf("segment index %v out of range", index)
+		}
+	}
+}
+
+func TestDeleteLocalCopyNotVerified(t *testing.T) {
+	client := &StorageClient{localVerifications: make(map[storage.DxPath]LocalCopyVerification)}
+	dxPath, err := storage.NewDxPath("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteLocalCopy(dxPath); err != errLocalCopyNotVerified {
+		t.Errorf("expect error %v, got %v", errLocalCopyNotVerified, err)
+	}
+	if _, err := client.VerifyLocalCopy(context.Background(), dxPath, 50); err == nil {
+		t.Error("expect error verifying with the threshold of the unrecoverable file")
+	}
+}
@@ -42,6 +42,9 @@ type worker struct {
 	// the time that last failure
 	ownedDownloadRecentFailure time.Time
 
+	// the average time used to download a sector from the host
+	ownedDownloadLatency time.Duration
+
 	// Notifications of new download work. Takes priority over uploads.
 	downloadChan chan struct{}
 
@@ -58,6 +61,8 @@ type worker struct {
 	uploadConsecutiveFailures int           // How many times in a row uploading has failed.
 	uploadRecentFailure       time.Time     // How recent was the last failure?
 	uploadTerminated          bool          // Have we stopped uploading?
+	uploadProbed              bool          // Has the upload speed of the host been probed?
+	uploadProbeSlow           bool          // Was the host probed slower than the others?
 
 	// Worker will shut down if a signal is sent down this channel.
 	killChan chan struct{}
@@ -107,6 +112,7 @@ func (client *StorageClient) activateWorkerPool() {
 		if !exists {
 			delete(client.workerPool, id)
 			close(worker.killChan)
+			client.uploadTimings.remove(worker.hostID)
 		}
 	}
 	client.lock.Unlock()
@@ -118,9 +124,14 @@ func (w *worker) workLoop() {
 	defer w.killDownloading()
 
 	for {
+		// wait for a transfer slot in the worker pool before taking a task
+		if !w.client.workerPoolTuner.acquire(w.killChan) {
+			return
+		}
 		downloadSegment := w.nextDownloadSegment()
 		if downloadSegment != nil {
 			err := w.download(downloadSegment)
+			w.client.workerPoolTuner.release()
 			if err == ErrNoContractsWithHost || err == ErrUnableRetrieveHostInfo {
 				break
 			}
@@ -137,6 +148,7 @@ func (w *worker) workLoop() {
 		segment, sectorIndex := w.nextUploadSegment()
 		if segment != nil {
 			err := w.upload(segment, sectorIndex)
+			w.client.workerPoolTuner.release()
 			if err == ErrNoContractsWithHost || err == ErrUnableRetrieveHostInfo {
 				break
 			}
@@ -151,6 +163,8 @@ func (w *worker) workLoop() {
 			continue
 		}
 
+		w.client.workerPoolTuner.release()
+
 		// keep listening for a new upload/download task, or a stop signal
 		select {
 		case <-w.downloadChan:
@@ -182,6 +196,43 @@ func (w *worker) killDownloading() {
 	}
 }
 
+// dropCancelledSegments drops the queued download segments of the cancelled or failed
+// downloads, and the queued upload segments of the cancelled uploads
+func (w *worker) dropCancelledSegments() {
+	var droppedDownloads []*unfinishedDownloadSegment
+	w.downloadMu.Lock()
+	downloadSegments := w.downloadSegments[:0]
+	for _, uds := range w.downloadSegments {
+		if uds.download.isComplete() {
+			droppedDownloads = append(droppedDownloads, uds)
+		} else {
+			downloadSegments = append(downloadSegments, uds)
+		}
+	}
+	w.downloadSegments = downloadSegments
+	w.downloadMu.Unlock()
+
+	var droppedUploads []*unfinishedUploadSegment
+	w.mu.Lock()
+	pendingSegments := w.pendingSegments[:0]
+	for _, uc := range w.pendingSegments {
+		if uc.operation.isCancelled() {
+			droppedUploads = append(droppedUploads, uc)
+		} else {
+			pendingSegments = append(pendingSegments, uc)
+		}
+	}
+	w.pendingSegments = pendingSegments
+	w.mu.Unlock()
+
+	for _, uds := range droppedDownloads {
+		uds.removeWorker()
+	}
+	for _, uc := range droppedUploads {
+		w.dropSegment(uc)
+	}
+}
+
 // Add a segment to the worker's queue.
 func (w *worker) queueDownloadSegment(uds *unfinishedDownloadSegment) {
 	w.downloadMu.Lock()
@@ -261,21 +312,37 @@ func (w *worker) download(uds *unfinishedDownloadSegment) error {
 	fetchOffset, fetchLength := 0, storage.SectorSize
 	root := uds.segmentMap[w.hostID.String()].root
 
-	// call rpc request the data from host, if get error, unregister the worker.
-	sectorData, err := w.client.Download(sp, root, uint32(fetchOffset), uint32(fetchLength), hostInfo)
+	// call rpc request the data from host, if get error, unregister the worker. The request
+	// is dropped once the download is completed, failed or cancelled by the caller. The public
+	// sector is read without payment if the host serves the public reads
+	public := uds.clientFile.Public() && storage.HasFeature(w.client.sessionFeatures(hostInfo), storage.FeaturePublicRead)
+	start := w.client.clock.Now()
+	var sectorData []byte
+	if public {
+		sectorData, err = w.client.PublicDownload(sp, root, uint32(fetchOffset), uint32(fetchLength), uds.download.completeChan, hostInfo)
+	} else {
+		sectorData, err = w.client.Download(sp, root, uint32(fetchOffset), uint32(fetchLength), uds.download.completeChan, hostInfo)
+	}
 	if err != nil {
 		w.client.log.Error("worker failed to download sector", "error", err)
+		// the storage host is not put on cooldown for the download budget exhausted
+		if err != storage.ErrDownloadBudgetExhausted {
+			w.recordDownloadFailure()
+		}
 		uds.unregisterWorker(w)
 		return err
 	}
-
-	// decrypt the sector
-	key := uds.clientFile.CipherKey()
-	decryptedSector, err := key.DecryptInPlace(sectorData)
-	if err != nil {
-		w.client.log.Error("worker failed to decrypt sector", "error", err)
-		uds.unregisterWorker(w)
-		return err
+	w.recordDownloadSuccess(w.client.clock.Since(start))
+
+	// decrypt the sector, the public sector is not encrypted
+	decryptedSector := sectorData
+	if !uds.clientFile.Public() {
+		key := uds.clientFile.CipherKey()
+		if decryptedSector, err = key.DecryptInPlace(sectorData); err != nil {
+			w.client.log.Error("worker failed to decrypt sector", "error", err)
+			uds.unregisterWorker(w)
+			return err
+		}
 	}
 
 	// mark the sector as completed
@@ -283,6 +350,7 @@ func (w *worker) download(uds *unfinishedDownloadSegment) error {
 	uds.mu.Lock()
 	uds.markSectorCompleted(sectorIndex)
 	uds.sectorsRegistered--
+	uds.download.operation.addProgress(0, 1)
 
 	// if the num of sectorsCompleted has not reached the required min sector num,
 	// go on keeping the decrypted sector.
@@ -309,6 +377,11 @@ func (w *worker) processDownloadSegment(uds *unfinishedDownloadSegment) *unfinis
 	segmentFailed := uds.sectorsCompleted+uds.workersRemaining < uds.erasureCode.MinSectors()
 	sectorData, workerHasSector := uds.segmentMap[w.hostID.String()]
 
+	// the worker is invited to download if it is among the preferred download sources
+	_, invited := uds.preferredWorkers[w.hostID.String()]
+	delete(uds.preferredWorkers, w.hostID.String())
+	invited = invited || uds.preferredWorkers == nil
+
 	sectorCompleted := uds.completedSectors[sectorData.index]
 
 	// if the given segment downloading complete/fail, or no sector associated with host for downloading,
@@ -325,7 +398,7 @@ func (w *worker) processDownloadSegment(uds *unfinishedDownloadSegment) *unfinis
 	sectorTaken := uds.sectorUsage[sectorData.index]
 	sectorsInProgress := uds.sectorsRegistered + uds.sectorsCompleted
 	desiredSectorsInProgress := uds.erasureCode.MinSectors() + uds.overdrive
-	workersDesired := sectorsInProgress < desiredSectorsInProgress && !sectorTaken
+	workersDesired := invited && sectorsInProgress < desiredSectorsInProgress && !sectorTaken
 	if workersDesired {
 		uds.sectorsRegistered++
 		uds.sectorUsage[sectorData.index] = true
@@ -343,7 +416,24 @@ func (w *worker) onDownloadCooldown() bool {
 	for i := 0; i < w.ownedDownloadConsecutiveFailures && i < MaxConsecutivePenalty; i++ {
 		requiredCooldown *= 2
 	}
-	return time.Now().Before(w.ownedDownloadRecentFailure.Add(requiredCooldown))
+	return w.client.clock.Now().Before(w.ownedDownloadRecentFailure.Add(requiredCooldown))
+}
+
+// recordDownloadSuccess resets the consecutive failures of the worker, and records
+// the latency of downloading a sector
+func (w *worker) recordDownloadSuccess(latency time.Duration) {
+	w.mu.Lock()
+	w.ownedDownloadConsecutiveFailures = 0
+	w.mu.Unlock()
+	w.updateDownloadLatency(latency)
+}
+
+// recordDownloadFailure records a download failure of the worker
+func (w *worker) recordDownloadFailure() {
+	w.mu.Lock()
+	w.ownedDownloadConsecutiveFailures++
+	w.ownedDownloadRecentFailure = w.client.clock.Now()
+	w.mu.Unlock()
 }
 
 // Remove the worker from an unfinished download segment,
@@ -0,0 +1,275 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common/unit"
+	"github.com/DxChainNetwork/godx/metrics"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+var (
+	workerPoolSizeGauge   = metrics.NewRegisteredGauge("storage/client/workerpool/size", nil)
+	workerPoolActiveGauge = metrics.NewRegisteredGauge("storage/client/workerpool/active", nil)
+)
+
+// workerPoolTuner limits the number of workers transferring sectors at the same time. There
+// is one worker for each contract, and the pool size is the number of the workers allowed
+// to upload or download a sector concurrently, which is tuned by the memory, the CPUs and
+// the measured throughput of the storage hosts
+type workerPoolTuner struct {
+	size   int
+	active int
+	status WorkerPoolStatus
+
+	// wake is closed and replaced once a transfer slot is released or the pool size is
+	// changed, to wake up the workers waiting for a transfer slot
+	wake chan struct{}
+
+	stopChan <-chan struct{}
+	lock     sync.Mutex
+}
+
+// WorkerPoolStatus is the current size of the worker pool, along with the resources it
+// was tuned by. The limit of 0 means the resource does not limit the pool size
+type WorkerPoolStatus struct {
+	Workers int `json:"workers"`
+	Size    int `json:"size"`
+	Active  int `json:"active"`
+
+	CPUs            int    `json:"cpus"`
+	CPULimit        int    `json:"cpulimit"`
+	MemoryLimit     int    `json:"memorylimit"`
+	ThroughputLimit int    `json:"throughputlimit"`
+	Memory          uint64 `json:"memory"`
+
+	// the measured throughput of a single storage host in bytes per second
+	UploadThroughput   float64 `json:"uploadthroughput"`
+	DownloadThroughput float64 `json:"downloadthroughput"`
+
+	Rationale string    `json:"rationale"`
+	Updated   time.Time `json:"updated"`
+}
+
+// workerPoolResources is the resources the worker pool size is tuned by
+type workerPoolResources struct {
+	workers int
+	cpus    int
+	memory  uint64
+
+	// the bandwidth limits in bytes per second, 0 means unlimited
+	maxUploadSpeed   int64
+	maxDownloadSpeed int64
+
+	// the median time used to transfer a sector with a storage host, 0 if not measured
+	uploadSectorTime   time.Duration
+	downloadSectorTime time.Duration
+}
+
+// newWorkerPoolTuner creates a new workerPoolTuner. Before the pool is tuned, the size is
+// not limited
+func newWorkerPoolTuner(stopChan <-chan struct{}) *workerPoolTuner {
+	return &workerPoolTuner{
+		wake:     make(chan struct{}),
+		stopChan: stopChan,
+	}
+}
+
+// acquire blocks until a transfer slot is available, and takes the slot. False will be
+// returned if the worker is killed or the storage client is stopped before that
+func (wp *workerPoolTuner) acquire(killChan <-chan struct{}) bool {
+	for {
+		wp.lock.Lock()
+		if wp.size == 0 || wp.active < wp.size {
+			wp.active++
+			workerPoolActiveGauge.Update(int64(wp.active))
+			wp.lock.Unlock()
+			return true
+		}
+		wake := wp.wake
+		wp.lock.Unlock()
+
+		select {
+		case <-wake:
+		case <-killChan:
+			return false
+		case <-wp.stopChan:
+			return false
+		}
+	}
+}
+
+// release returns the transfer slot taken by acquire
+func (wp *workerPoolTuner) release() {
+	wp.lock.Lock()
+	defer wp.lock.Unlock()
+	wp.active--
+	workerPoolActiveGauge.Update(int64(wp.active))
+	wp.wakeUp()
+}
+
+// setStatus updates the pool size to the tuned status, and wakes up the waiting workers
+func (wp *workerPoolTuner) setStatus(status WorkerPoolStatus) {
+	wp.lock.Lock()
+	defer wp.lock.Unlock()
+	wp.size = status.Size
+	wp.status = status
+	workerPoolSizeGauge.Update(int64(wp.size))
+	wp.wakeUp()
+}
+
+// poolStatus returns the status of the last tuning, along with the number of active workers
+func (wp *workerPoolTuner) poolStatus() WorkerPoolStatus {
+	wp.lock.Lock()
+	defer wp.lock.Unlock()
+	status := wp.status
+	status.Active = wp.active
+	return status
+}
+
+// wakeUp wakes up all workers waiting for a transfer slot
+//
+// NOTE: wp.lock should be held when calling this function
+func (wp *workerPoolTuner) wakeUp() {
+	close(wp.wake)
+	wp.wake = make(chan struct{})
+}
+
+// workerPoolTuneLoop tunes the worker pool size periodically until the storage client stops
+func (client *StorageClient) workerPoolTuneLoop() {
+	if err := client.tm.Add(); err != nil {
+		return
+	}
+	defer client.tm.Done()
+
+	for {
+		client.tuneWorkerPool()
+		select {
+		case <-client.tm.StopChan():
+			return
+		case <-time.After(WorkerPoolTuneInterval):
+		}
+	}
+}
+
+// tuneWorkerPool collects the resources and updates the worker pool size
+func (client *StorageClient) tuneWorkerPool() {
+	res := workerPoolResources{
+		cpus:             runtime.NumCPU(),
+		memory:           client.memoryManager.MemoryLimit(),
+		uploadSectorTime: client.uploadTimings.sectorLatency().P50,
+	}
+
+	settings := client.settings.get()
+	res.maxUploadSpeed = settings.MaxUploadSpeed
+	res.maxDownloadSpeed = settings.MaxDownloadSpeed
+
+	client.lock.Lock()
+	res.workers = len(client.workerPool)
+	workers := make([]*worker, 0, len(client.workerPool))
+	for _, w := range client.workerPool {
+		workers = append(workers, w)
+	}
+	client.lock.Unlock()
+
+	var latencies []time.Duration
+	for _, w := range workers {
+		if latency := w.downloadLatency(); latency != 0 {
+			latencies = append(latencies, latency)
+		}
+	}
+	if len(latencies) != 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		res.downloadSectorTime = latencies[len(latencies)/2]
+	}
+
+	status := res.tune()
+	status.Updated = client.clock.Now()
+	prev := client.workerPoolTuner.poolStatus()
+	client.workerPoolTuner.setStatus(status)
+	if prev.Size != status.Size {
+		client.log.Info("worker pool resized", "size", status.Size, "workers", status.Workers, "rationale", status.Rationale)
+	}
+}
+
+// tune calculates the worker pool size. Each transfer holds a sector in memory and keeps
+// the CPUs busy with the encryption and the erasure coding, so the pool size is bounded by
+// the memory and the CPUs. With bandwidth limited, the pool size is also bounded by the
+// number of storage hosts needed to saturate the link with the measured throughput of a
+// single storage host. The pool size is at least MinWorkerPoolSize, and no more than the
+// number of workers
+func (res workerPoolResources) tune() WorkerPoolStatus {
+	status := WorkerPoolStatus{
+		Workers:            res.workers,
+		CPUs:               res.cpus,
+		Memory:             res.memory,
+		CPULimit:           res.cpus * WorkerPoolTransfersPerCPU,
+		MemoryLimit:        int(res.memory / storage.SectorSize),
+		UploadThroughput:   sectorThroughput(res.uploadSectorTime),
+		DownloadThroughput: sectorThroughput(res.downloadSectorTime),
+	}
+	uploadLimit := linkLimit(res.maxUploadSpeed, status.UploadThroughput)
+	downloadLimit := linkLimit(res.maxDownloadSpeed, status.DownloadThroughput)
+	if uploadLimit != 0 && downloadLimit != 0 {
+		status.ThroughputLimit = uploadLimit
+		if downloadLimit > uploadLimit {
+			status.ThroughputLimit = downloadLimit
+		}
+	}
+
+	status.Size = res.workers
+	var reasons []string
+	bound := func(limit int, reason string) {
+		if limit == 0 || limit > status.Size {
+			return
+		}
+		if limit < status.Size {
+			reasons = reasons[:0]
+		}
+		status.Size = limit
+		reasons = append(reasons, reason)
+	}
+	bound(status.CPULimit, fmt.Sprintf("%v transfers for %v CPUs", status.CPULimit, res.cpus))
+	bound(status.MemoryLimit, fmt.Sprintf("%v sectors fit in %v memory", status.MemoryLimit, unit.FormatStorage(res.memory, false)))
+	bound(status.ThroughputLimit, fmt.Sprintf("%v storage hosts saturate the bandwidth limit", status.ThroughputLimit))
+
+	switch {
+	case status.Size < MinWorkerPoolSize:
+		status.Size = MinWorkerPoolSize
+		status.Rationale = fmt.Sprintf("minimum pool size %v", MinWorkerPoolSize)
+	case len(reasons) == 0:
+		status.Rationale = fmt.Sprintf("one transfer for each of %v contracts", res.workers)
+	default:
+		status.Rationale = "limited by " + strings.Join(reasons, ", ")
+	}
+	return status
+}
+
+// sectorThroughput returns the throughput of a single storage host in bytes per second,
+// with the time used to transfer a sector. 0 is returned if not measured
+func sectorThroughput(sectorTime time.Duration) float64 {
+	if sectorTime <= 0 {
+		return 0
+	}
+	return float64(storage.SectorSize) / sectorTime.Seconds()
+}
+
+// linkLimit returns the number of storage hosts needed to saturate the bandwidth limit
+// with the throughput of a single storage host. 0 is returned if the bandwidth is not
+// limited or the throughput is not measured
+func linkLimit(maxSpeed int64, throughput float64) int {
+	if maxSpeed <= 0 || throughput <= 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(maxSpeed) / throughput * WorkerPoolLinkHeadroom))
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storageclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestWorkerPoolResources_Tune(t *testing.T) {
+	tests := []struct {
+		name string
+		res  workerPoolResources
+		size int
+	}{
+		{
+			name: "contracts",
+			res:  workerPoolResources{workers: 10, cpus: 8, memory: 100 * storage.SectorSize},
+			size: 10,
+		},
+		{
+			name: "cpu",
+			res:  workerPoolResources{workers: 50, cpus: 2, memory: 100 * storage.SectorSize},
+			size: 2 * WorkerPoolTransfersPerCPU,
+		},
+		{
+			name: "memory",
+			res:  workerPoolResources{workers: 50, cpus: 8, memory: 6 * storage.SectorSize},
+			size: 6,
+		},
+		{
+			name: "minimum",
+			res:  workerPoolResources{workers: 50, cpus: 8, memory: storage.SectorSize},
+			size: MinWorkerPoolSize,
+		},
+		{
+			name: "bandwidth",
+			res: workerPoolResources{
+				workers:            50,
+				cpus:               8,
+				memory:             100 * storage.SectorSize,
+				maxUploadSpeed:     4 * int64(storage.SectorSize),
+				maxDownloadSpeed:   2 * int64(storage.SectorSize),
+				uploadSectorTime:   time.Second,
+				downloadSectorTime: time.Second,
+			},
+			size: 6,
+		},
+		{
+			name: "download bandwidth not limited",
+			res: workerPoolResources{
+				workers:            50,
+				cpus:               8,
+				memory:             100 * storage.SectorSize,
+				maxUploadSpeed:     4 * int64(storage.SectorSize),
+				uploadSectorTime:   time.Second,
+				downloadSectorTime: time.Second,
+			},
+			size: 50,
+		},
+		{
+			name: "throughput not measured",
+			res: workerPoolResources{
+				workers:          50,
+				cpus:             8,
+				memory:           100 * storage.SectorSize,
+				maxUploadSpeed:   4 * int64(storage.SectorSize),
+				maxDownloadSpeed: 4 * int64(storage.SectorSize),
+			},
+			size: 50,
+		},
+	}
+	for _, test := range tests {
+		status := test.res.tune()
+		if status.Size != test.size {
+			t.Errorf("%v: pool size %v, expected %v", test.name, status.Size, test.size)
+		}
+		if status.Rationale == "" {
+			t.Errorf("%v: empty rationale", test.name)
+		}
+	}
+}
+
+func TestWorkerPoolTuner_Acquire(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	wp := newWorkerPoolTuner(stop)
+	wp.setStatus(WorkerPoolStatus{Size: 1})
+
+	kill := make(chan struct{})
+	if !wp.acquire(kill) {
+		t.Fatal("failed to acquire the free slot")
+	}
+	acquired := make(chan bool)
+	go func() {
+		acquired <- wp.acquire(kill)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired the slot beyond the pool size")
+	case <-time.After(50 * time.Millisecond):
+	}
+	wp.release()
+	if !<-acquired {
+		t.Fatal("failed to acquire the released slot")
+	}
+	if status := wp.poolStatus(); status.Active != 1 {
+		t.Fatalf("active workers %v, expected 1", status.Active)
+	}
+
+	// the waiting worker gives up once killed
+	go func() {
+		acquired <- wp.acquire(kill)
+	}()
+	close(kill)
+	if <-acquired {
+		t.Fatal("acquired the slot after the worker is killed")
+	}
+}
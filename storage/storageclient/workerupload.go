@@ -5,8 +5,7 @@
 package storageclient
 
 import (
-	"time"
-
+	"github.com/DxChainNetwork/godx/common"
 	"github.com/DxChainNetwork/godx/storage"
 )
 
@@ -46,6 +45,21 @@ func (w *worker) killUploading() {
 	w.dropUploadSegments()
 }
 
+// queueUploadSegment adds the segment to the worker's upload task list. The segment is queued
+// ahead of the pending segments in the lower upload priority class, and behind the others
+func (w *worker) queueUploadSegment(uc *unfinishedUploadSegment) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	index := len(w.pendingSegments)
+	for index > 0 && uc.uploadPriority.Before(w.pendingSegments[index-1].uploadPriority) {
+		index--
+	}
+	w.pendingSegments = append(w.pendingSegments, nil)
+	copy(w.pendingSegments[index+1:], w.pendingSegments[index:])
+	w.pendingSegments[index] = uc
+}
+
 // nextUploadSegment pull the next segment task from the worker's upload task list
 func (w *worker) nextUploadSegment() (nextSegment *unfinishedUploadSegment, sectorIndex uint64) {
 	// Loop through the unprocessed segments and find some work to do
@@ -86,11 +100,12 @@ func (w *worker) isReady(uc *unfinishedUploadSegment) bool {
 
 	onCoolDown := w.onUploadCoolDown()
 	uploadTerminated := w.uploadTerminated
+	uploadPaused := w.client.revisionMonitor.isPaused(w.hostID)
 
-	if !uploadAbility || uploadTerminated || onCoolDown {
+	if !uploadAbility || uploadTerminated || onCoolDown || uploadPaused {
 		// drop segment when work is not ready
 		w.dropSegment(uc)
-		w.client.log.Info("Append worker unfinished segments failed due to it is not ready", "uploadAbility", !uploadAbility, "uploadTerminated", uploadTerminated, "onCoolDown", onCoolDown, "contractID", w.contract.ID.String())
+		w.client.log.Info("Append worker unfinished segments failed due to it is not ready", "uploadAbility", !uploadAbility, "uploadTerminated", uploadTerminated, "onCoolDown", onCoolDown, "uploadPaused", uploadPaused, "contractID", w.contract.ID.String())
 		return false
 	}
 	return true
@@ -115,33 +130,61 @@ func (w *worker) upload(uc *unfinishedUploadSegment, sectorIndex uint64) error {
 		return err
 	}
 
+	// probe the upload speed of the host before its first sector
+	w.probeUpload(sp)
+
 	// upload segment to host
+	start := w.client.clock.Now()
 	root, err := w.client.Append(sp, uc.physicalSegmentData[sectorIndex], hostInfo)
 	if err != nil {
 		w.client.log.Error("Worker failed to upload", "err", err)
 		w.uploadFailed(uc, sectorIndex)
 		return err
 	}
+	if w.client.uploadTimings.recordSector(w.hostID, w.client.clock.Since(start)) {
+		w.client.storageHostManager.SetUploadSlowdown(w.hostID, w.client.uploadTimings.hostSlowdown(w.hostID))
+	}
 	w.mu.Lock()
 	w.uploadConsecutiveFailures = 0
 	w.mu.Unlock()
-	// Add sector to storage clientFile
-	err = uc.fileEntry.AddSector(w.contract.EnodeID, root, int(uc.index), int(sectorIndex))
-	if err != nil {
-		w.client.log.Error("Worker failed to add new sector in dxfile", "err", err)
-		w.uploadFailed(uc, sectorIndex)
-		return err
+
+	// The file of the cancelled upload is deleted, so the sector is not added
+	cancelled := uc.operation.isCancelled()
+	if !cancelled {
+		// Add sector to storage clientFile
+		err = uc.fileEntry.AddSector(w.contract.EnodeID, root, int(uc.index), int(sectorIndex))
+		if err != nil {
+			w.client.log.Error("Worker failed to add new sector in dxfile", "err", err)
+			w.uploadFailed(uc, sectorIndex)
+			return err
+		}
+		// The sector of the public file is published if the host serves the public reads. The
+		// failure is only logged, the sector could still be downloaded with the contract
+		if uc.fileEntry.Public() && storage.HasFeature(w.client.sessionFeatures(hostInfo), storage.FeaturePublicRead) {
+			if err := w.client.publishSectors(sp, []common.Hash{root}, hostInfo); err != nil {
+				w.client.log.Warn("Worker failed to publish the sector", "hostID", w.hostID, "err", err)
+			}
+		}
 	}
 	// Upload is complete. Update the state of the Segment and the storage client's memory
 	// available to reflect the completed upload.
 	uc.mu.Lock()
 	releaseSize := len(uc.physicalSegmentData[sectorIndex])
 	uc.sectorsUploadingNum--
-	uc.sectorsCompletedNum++
+	if !cancelled {
+		uc.sectorsCompletedNum++
+		uc.lastSectorHost = w.hostID
+		uc.uploadedHosts = append(uc.uploadedHosts, w.hostID)
+	}
 	uc.physicalSegmentData[sectorIndex] = nil
-	uc.memoryReleased += uint64(releaseSize)
+	memoryReleased := uc.releaseMemory(uint64(releaseSize))
 	uc.mu.Unlock()
-	w.client.memoryManager.Return(uint64(releaseSize))
+	if !cancelled {
+		uc.operation.addProgress(uint64(releaseSize), 1)
+	}
+	if memoryReleased > 0 {
+		w.client.memoryManager.Return(memoryReleased)
+	}
 	w.client.cleanupUploadSegment(uc)
 
 	return nil
@@ -153,7 +196,7 @@ func (w *worker) onUploadCoolDown() bool {
 	for i := 0; i < w.uploadConsecutiveFailures && i < MaxConsecutivePenalty; i++ {
 		requiredCoolDown *= 2
 	}
-	return time.Now().Before(w.uploadRecentFailure.Add(requiredCoolDown))
+	return w.client.clock.Now().Before(w.uploadRecentFailure.Add(requiredCoolDown))
 }
 
 // preProcessUploadSegment will pre-process a segment from the worker segment queue
@@ -166,21 +209,30 @@ func (w *worker) preProcessUploadSegment(uc *unfinishedUploadSegment) (*unfinish
 
 	w.mu.Lock()
 	onCoolDown := w.onUploadCoolDown()
+	probeSlow := w.uploadProbeSlow
 	w.mu.Unlock()
+	groups := w.client.storageHostManager.HostDiversityGroups(w.hostID)
 
 	// Determine what sort of help this segment needs
 	// uc.mu condition race, low performance
 	uc.mu.Lock()
 	_, candidateHost := uc.unusedHosts[w.contract.EnodeID.String()]
+	diverse := uc.diverse(groups)
 	isComplete := uc.sectorsAllNeedNum <= uc.sectorsCompletedNum
 	isNeedUpload := uc.sectorsAllNeedNum > uc.sectorsCompletedNum+uc.sectorsUploadingNum
 
+	// the worker probed slow leaves the sector to the faster workers, unless the other
+	// workers remaining are not enough for the sectors still needed
+	if probeSlow && isNeedUpload && uc.workersRemain-1 >= uc.sectorsAllNeedNum-uc.sectorsCompletedNum-uc.sectorsUploadingNum {
+		isNeedUpload = false
+	}
+
 	// If the segment does not need help from this worker, release the segment
-	if isComplete || !candidateHost || !uploadAbility || onCoolDown {
+	if isComplete || !candidateHost || !diverse || !uploadAbility || onCoolDown || uc.operation.isCancelled() {
 		// This worker no longer needs to track this segment
 		uc.mu.Unlock()
 		w.dropSegment(uc)
-		w.client.log.Info("Worker will drop a segment due to it's status: complete/notCandidate/uploadInAbility/onCoolDown")
+		w.client.log.Info("Worker will drop a segment due to it's status: complete/notCandidate/notDiverse/uploadInAbility/onCoolDown/cancelled")
 		return nil, 0
 	}
 
@@ -210,6 +262,7 @@ func (w *worker) preProcessUploadSegment(uc *unfinishedUploadSegment) (*unfinish
 	}
 
 	delete(uc.unusedHosts, w.contract.EnodeID.String())
+	uc.sectorGroups[index] = groups
 	uc.sectorsUploadingNum++
 	uc.workersRemain--
 	uc.mu.Unlock()
@@ -222,7 +275,7 @@ func (w *worker) uploadFailed(uc *unfinishedUploadSegment, sectorIndex uint64) {
 	// not the worker's fault if we are offline
 	if w.client.Online() {
 		w.mu.Lock()
-		w.uploadRecentFailure = time.Now()
+		w.uploadRecentFailure = w.client.clock.Now()
 		w.uploadConsecutiveFailures++
 		w.mu.Unlock()
 	}
@@ -232,6 +285,7 @@ func (w *worker) uploadFailed(uc *unfinishedUploadSegment, sectorIndex uint64) {
 	uc.workersRemain--
 	uc.sectorsUploadingNum--
 	uc.sectorSlotsStatus[sectorIndex] = false
+	uc.sectorGroups[sectorIndex] = nil
 	uc.mu.Unlock()
 
 	// Clean up this segment, we may notify backup workers of segment to help upload
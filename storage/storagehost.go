@@ -11,6 +11,7 @@ import (
 	"github.com/DxChainNetwork/godx/core/types"
 	"github.com/DxChainNetwork/godx/event"
 	"github.com/DxChainNetwork/godx/p2p/enode"
+	"github.com/DxChainNetwork/godx/p2p/enr"
 	"github.com/DxChainNetwork/godx/rpc"
 )
 
@@ -24,6 +25,8 @@ type HostBackend interface {
 	AccountManager() *accounts.Manager
 	SetStatic(node *enode.Node)
 	CheckAndUpdateConnection(peerNode *enode.Node)
+	SignWithNodeSk(hash []byte) ([]byte, error)
+	SetNodeRecordEntry(e enr.Entry)
 }
 
 // AccountManager is the interface for account.Manager to be used in storage host module
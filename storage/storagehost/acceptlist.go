@@ -0,0 +1,67 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// isClientAccepted checks whether the host accepts contracts from the storage client
+func (h *StorageHost) isClientAccepted(addr common.Address) bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return clientAccepted(h.config, addr)
+}
+
+// addAcceptedClient adds the client address into the accept-list of the host
+func (h *StorageHost) addAcceptedClient(addr common.Address) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if acceptListIndex(h.config.AcceptedClients, addr) >= 0 {
+		return nil
+	}
+	// copy on write, the accept-list may be shared with the copies of the config
+	clients := make([]common.Address, 0, len(h.config.AcceptedClients)+1)
+	clients = append(clients, h.config.AcceptedClients...)
+	h.config.AcceptedClients = append(clients, addr)
+	return h.syncConfig()
+}
+
+// removeAcceptedClient removes the client address from the accept-list of the host
+func (h *StorageHost) removeAcceptedClient(addr common.Address) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	index := acceptListIndex(h.config.AcceptedClients, addr)
+	if index < 0 {
+		return errClientNotInAcceptList
+	}
+	clients := make([]common.Address, 0, len(h.config.AcceptedClients)-1)
+	clients = append(clients, h.config.AcceptedClients[:index]...)
+	h.config.AcceptedClients = append(clients, h.config.AcceptedClients[index+1:]...)
+	return h.syncConfig()
+}
+
+// clientAccepted checks whether the client address is accepted by the config. If private
+// hosting is not enabled, all clients are accepted
+func clientAccepted(config storage.HostIntConfig, addr common.Address) bool {
+	if !config.PrivateHosting {
+		return true
+	}
+	return acceptListIndex(config.AcceptedClients, addr) >= 0
+}
+
+// acceptListIndex returns the index of the address in the accept-list, -1 if not found
+func acceptListIndex(clients []common.Address, addr common.Address) int {
+	for i, client := range clients {
+		if client == addr {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,50 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+)
+
+func TestStorageHost_AcceptedClients(t *testing.T) {
+	h := &StorageHost{persistDir: tempDir(t.Name())}
+	alice := common.HexToAddress("0x1")
+	bob := common.HexToAddress("0x2")
+
+	// all clients are accepted if private hosting is not enabled
+	if !h.isClientAccepted(alice) || !h.isClientAccepted(bob) {
+		t.Fatal("clients should be accepted when private hosting is disabled")
+	}
+
+	h.config.PrivateHosting = true
+	if err := h.addAcceptedClient(alice); err != nil {
+		t.Fatal(err)
+	}
+	// adding the same client twice should not duplicate the entry
+	if err := h.addAcceptedClient(alice); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.config.AcceptedClients) != 1 {
+		t.Fatalf("accept-list size not expected. Expect 1, Got %v", len(h.config.AcceptedClients))
+	}
+	if !h.isClientAccepted(alice) {
+		t.Fatal("client in the accept-list should be accepted")
+	}
+	if h.isClientAccepted(bob) {
+		t.Fatal("client not in the accept-list should not be accepted")
+	}
+
+	if err := h.removeAcceptedClient(bob); err != errClientNotInAcceptList {
+		t.Fatalf("removing unknown client. Expect %v, Got %v", errClientNotInAcceptList, err)
+	}
+	if err := h.removeAcceptedClient(alice); err != nil {
+		t.Fatal(err)
+	}
+	if h.isClientAccepted(alice) {
+		t.Fatal("removed client should not be accepted")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/chrono"
+)
+
+// accountingAmount is an income or expense of the storage responsibility at the block height
+type accountingAmount struct {
+	category string
+	height   uint64
+	amount   common.BigInt
+}
+
+// exportAccounting writes the revenue and the expenses of the storage responsibilities to the
+// file path in CSV or OFX, grouped by the period provided
+func (h *StorageHost) exportAccounting(path, format, period string) error {
+	blocks, err := storage.ParseAccountingPeriod(period)
+	if err != nil {
+		return err
+	}
+	var account string
+	if address, err := h.getPaymentAddress(); err == nil {
+		account = address.String()
+	}
+
+	h.lock.RLock()
+	sos := h.storageResponsibilities()
+	height := h.blockHeight
+	h.lock.RUnlock()
+
+	entries := revenueEntries(sos, height, h.clock.Now())
+	return storage.ExportAccounting(path, format, account, entries, blocks)
+}
+
+// revenueEntries converts the storage responsibilities into the accounting entries. The
+// transaction fees are expenses once the contract is formed. The contract compensation and
+// the storage and bandwidth revenue are income only after the storage proof succeeded, and
+// the risked deposit is lost if the storage proof failed. The revenue not settled yet is not
+// exported
+func revenueEntries(sos []StorageResponsibility, height uint64, now time.Time) (entries []storage.AccountingEntry) {
+	for _, so := range sos {
+		if so.ResponsibilityStatus == responsibilityRejected {
+			continue
+		}
+		settled := so.proofDeadline()
+		if settled > height {
+			settled = height
+		}
+		amounts := []accountingAmount{
+			{"transaction fee", so.NegotiationBlockNumber, common.BigInt0.Sub(so.TransactionFeeExpenses)},
+		}
+		switch so.ResponsibilityStatus {
+		case responsibilitySucceeded:
+			amounts = append(amounts,
+				accountingAmount{"contract compensation", settled, so.ContractCost},
+				accountingAmount{"storage revenue", settled, so.PotentialStorageRevenue},
+				accountingAmount{"upload bandwidth revenue", settled, so.PotentialUploadRevenue},
+				accountingAmount{"download bandwidth revenue", settled, so.PotentialDownloadRevenue})
+		case responsibilityFailed:
+			amounts = append(amounts, accountingAmount{"lost deposit", settled, common.BigInt0.Sub(so.RiskedStorageDeposit)})
+		}
+
+		var client string
+		if so.OriginStorageContract.ClientCollateral.Address != (common.Address{}) {
+			client = so.OriginStorageContract.ClientCollateral.Address.String()
+		}
+		for _, a := range amounts {
+			if a.amount.Sign() == 0 {
+				continue
+			}
+			entries = append(entries, storage.AccountingEntry{
+				BlockHeight:  a.height,
+				Time:         chrono.HeightTime(now, height, a.height),
+				ContractID:   so.id(),
+				Counterparty: client,
+				Category:     a.category,
+				Amount:       a.amount,
+			})
+		}
+	}
+	return
+}
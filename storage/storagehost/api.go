@@ -7,11 +7,14 @@ package storagehost
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/DxChainNetwork/godx/accounts"
 	"github.com/DxChainNetwork/godx/common"
 	"github.com/DxChainNetwork/godx/common/unit"
 	"github.com/DxChainNetwork/godx/storage"
+	sm "github.com/DxChainNetwork/godx/storage/storagehost/storagemanager"
 )
 
 // HostPrivateAPI is the api for private usage
@@ -58,6 +61,24 @@ func (h *HostPrivateAPI) Announce() string {
 	return fmt.Sprintf("Announcement transaction: %v", hash.Hex())
 }
 
+// Revoke set accepting contracts to false, and then send the revocation transaction
+// retracting the host announcement. The storage clients drop the host once the
+// revocation is on chain
+func (h *HostPrivateAPI) Revoke() string {
+	if err := h.storageHost.setAcceptContracts(false); err != nil {
+		return fmt.Sprintf("cannot set AcceptingContracts: %v", err)
+	}
+	address, err := h.storageHost.getPaymentAddress()
+	if err != nil {
+		return fmt.Sprintf("cannot get the payment address: %v", err)
+	}
+	hash, err := h.storageHost.parseAPI.StorageTx.SendHostRevokeTX(address)
+	if err != nil {
+		return fmt.Sprintf("cannot send the revoke transaction: %v", err)
+	}
+	return fmt.Sprintf("Revocation transaction: %v", hash.Hex())
+}
+
 // Folders return all the folders
 func (h *HostPrivateAPI) Folders() []storage.HostFolder {
 	return h.storageHost.StorageManager.Folders()
@@ -89,6 +110,20 @@ func (h *HostPrivateAPI) GetHostConfig() storage.HostIntConfigForDisplay {
 		SectorAccessPrice:      unit.FormatCurrency(config.SectorAccessPrice, "/sector"),
 		StoragePrice:           unit.FormatCurrency(config.StoragePrice, "/byte/block"),
 		UploadBandwidthPrice:   unit.FormatCurrency(config.UploadBandwidthPrice, "/byte"),
+		DynamicPricing:         unit.FormatBool(config.DynamicPricing),
+		MinStoragePrice:        unit.FormatCurrency(config.MinStoragePrice, "/byte/block"),
+		MaxStoragePrice:        unit.FormatCurrency(config.MaxStoragePrice, "/byte/block"),
+		PrivateHosting:         unit.FormatBool(config.PrivateHosting),
+		ProofConfirmBlocks:     unit.FormatTime(config.ProofConfirmBlocks),
+		MaxTxGasPrice:          unit.FormatCurrency(config.MaxTxGasPrice),
+
+		ResponsibilityRetention:        unit.FormatTime(config.ResponsibilityRetention),
+		DiscardSettledResponsibilities: unit.FormatBool(config.DiscardSettledResponsibilities),
+
+		FeatureFlags: configFeatureFlags(config).String(),
+	}
+	for _, addr := range config.AcceptedClients {
+		display.AcceptedClients = append(display.AcceptedClients, addr.String())
 	}
 
 	return display
@@ -160,6 +195,248 @@ func (h *HostPrivateAPI) DeleteFolder(folderPath string) (string, error) {
 	return "successfully delete the storage folder", nil
 }
 
+// SectorStore returns the config of the sector store backend persisting the sector data
+func (h *HostPrivateAPI) SectorStore() sm.SectorStoreConfig {
+	return h.storageHost.StorageManager.SectorStore()
+}
+
+// MigrateSectorStore migrates the sector data of all storage folders to the sector store
+// backend specified by the key value pairs: backend (file, db or object), and the endpoint,
+// region, bucket, accesskey and secretkey of the object store
+func (h *HostPrivateAPI) MigrateSectorStore(config map[string]string) (string, error) {
+	var storeConfig sm.SectorStoreConfig
+	for key, value := range config {
+		switch key {
+		case "backend":
+			storeConfig.Backend = value
+		case "endpoint":
+			storeConfig.Endpoint = value
+		case "region":
+			storeConfig.Region = value
+		case "bucket":
+			storeConfig.Bucket = value
+		case "accesskey":
+			storeConfig.AccessKey = value
+		case "secretkey":
+			storeConfig.SecretKey = value
+		default:
+			return "", fmt.Errorf("unknown sector store config variable: %v", key)
+		}
+	}
+	if err := h.storageHost.StorageManager.MigrateSectorStore(storeConfig); err != nil {
+		return "", err
+	}
+	return "successfully migrated the sector store", nil
+}
+
+// SetSectorCompression enables or disables the compression of the sectors added afterwards
+func (h *HostPrivateAPI) SetSectorCompression(valStr string) (string, error) {
+	val, err := unit.ParseBool(valStr)
+	if err != nil {
+		return "", fmt.Errorf("invalid bool string: %v", err)
+	}
+	if err = h.storageHost.StorageManager.SetSectorCompression(val); err != nil {
+		return "", err
+	}
+	if val {
+		return "successfully enabled the sector compression", nil
+	}
+	return "successfully disabled the sector compression", nil
+}
+
+// CompressionStats returns the compression statistics of the sectors stored in the host
+func (h *HostPrivateAPI) CompressionStats() (sm.SectorCompressionStats, error) {
+	return h.storageHost.StorageManager.CompressionStats()
+}
+
+// SectorCompression returns the compression record of the sector with the root
+func (h *HostPrivateAPI) SectorCompression(rootStr string) (sm.SectorCompression, error) {
+	return h.storageHost.StorageManager.SectorCompression(common.HexToHash(rootStr))
+}
+
+// ProofAlerts returns the recent alerts of the storage proof tx not mined within the proof
+// confirm blocks, and the action taken for each of them
+func (h *HostPrivateAPI) ProofAlerts() []ProofAlert {
+	return h.storageHost.getProofAlerts()
+}
+
+// PendingPayouts returns the valid proof payouts whose storage proof tx has not got enough
+// confirmations to be counted as revenue
+func (h *HostPrivateAPI) PendingPayouts() []PendingPayout {
+	return h.storageHost.pendingPayouts()
+}
+
+// ScheduleMaintenance schedules the maintenance starting after the delay for the duration,
+// both in the time format such as "2h" or "100b". The uploads and the new contracts are
+// rejected during the maintenance, and accepted again automatically once it ends. The
+// storage responsibilities whose proof window conflicts with the maintenance are returned
+func (h *HostPrivateAPI) ScheduleMaintenance(start string, duration string) (MaintenanceSchedule, error) {
+	delay, err := unit.ParseTime(start)
+	if err != nil {
+		return MaintenanceSchedule{}, fmt.Errorf("invalid start: %v", err)
+	}
+	blocks, err := unit.ParseTime(duration)
+	if err != nil {
+		return MaintenanceSchedule{}, fmt.Errorf("invalid duration: %v", err)
+	}
+	return h.storageHost.scheduleMaintenance(h.storageHost.GetCurrentBlockHeight()+delay, blocks)
+}
+
+// CancelMaintenance cancels the maintenance scheduled, and the uploads are accepted at once
+func (h *HostPrivateAPI) CancelMaintenance() (string, error) {
+	if err := h.storageHost.cancelMaintenance(); err != nil {
+		return "", err
+	}
+	return "maintenance cancelled", nil
+}
+
+// Maintenance returns the maintenance scheduled, along with the storage responsibilities
+// whose proof window conflicts with the maintenance
+func (h *HostPrivateAPI) Maintenance() MaintenanceSchedule {
+	return h.storageHost.getMaintenanceSchedule()
+}
+
+// PendingTxs returns the revision txs sent and not mined yet, along with the number of times
+// the fee is bumped
+func (h *HostPrivateAPI) PendingTxs() []storage.PendingStorageTx {
+	return h.storageHost.txMonitor.PendingTxs()
+}
+
+// AddAcceptedClient add the client address into the accept-list. In private hosting mode,
+// the host only accepts contracts from the clients in the accept-list
+func (h *HostPrivateAPI) AddAcceptedClient(addrStr string) (string, error) {
+	if !common.IsHexAddress(addrStr) {
+		return "", errors.New("invalid client address")
+	}
+	if err := h.storageHost.addAcceptedClient(common.HexToAddress(addrStr)); err != nil {
+		return "", err
+	}
+	return "successfully added the client into the accept-list", nil
+}
+
+// RemoveAcceptedClient remove the client address from the accept-list
+func (h *HostPrivateAPI) RemoveAcceptedClient(addrStr string) (string, error) {
+	if !common.IsHexAddress(addrStr) {
+		return "", errors.New("invalid client address")
+	}
+	if err := h.storageHost.removeAcceptedClient(common.HexToAddress(addrStr)); err != nil {
+		return "", err
+	}
+	return "successfully removed the client from the accept-list", nil
+}
+
+// SetIngressPolicy updates the ingress policy enforced on the storage requests before the
+// negotiation begins. The options are allow and deny, the comma separated CIDRs or IPs with
+// the empty value clearing the list, ratelimit, the number of requests allowed per IP per
+// minute, burst, the number of requests allowed per IP at once, maxsessions and
+// maxsessionsperip, the caps of the concurrent sessions. The zero limits are not enforced,
+// and the options not specified are left unchanged
+func (h *HostPrivateAPI) SetIngressPolicy(options map[string]string) (string, error) {
+	policy := h.storageHost.ingress.getPolicy()
+	for key, value := range options {
+		var err error
+		switch strings.ToLower(key) {
+		case "allow":
+			policy.AllowCIDRs = splitCIDRs(value)
+		case "deny":
+			policy.DenyCIDRs = splitCIDRs(value)
+		case "ratelimit":
+			policy.RateLimit, err = strconv.ParseFloat(value, 64)
+		case "burst":
+			policy.RateBurst, err = strconv.Atoi(value)
+		case "maxsessions":
+			policy.MaxSessions, err = strconv.Atoi(value)
+		case "maxsessionsperip":
+			policy.MaxSessionsPerIP, err = strconv.Atoi(value)
+		default:
+			err = errors.New("unknown option")
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid ingress option %v: %v", key, err)
+		}
+	}
+	if err := h.storageHost.setIngressPolicy(policy); err != nil {
+		return "", err
+	}
+	return "successfully updated the ingress policy", nil
+}
+
+// IngressPolicy returns the ingress policy, along with the sessions in progress and the
+// number of requests rejected
+func (h *HostPrivateAPI) IngressPolicy() IngressStatus {
+	return h.storageHost.getIngressStatus()
+}
+
+// splitCIDRs splits the comma separated CIDRs, with the empty value as the empty list
+func splitCIDRs(value string) []string {
+	var cidrs []string
+	for _, cidr := range strings.Split(value, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+	return cidrs
+}
+
+// ContractAuditState return the host's view of the contract, including the latest revision,
+// the remaining funds, and the merkle roots
+func (h *HostPrivateAPI) ContractAuditState(contractID string) (storage.ContractAuditState, error) {
+	id, err := storage.StringToContractID(contractID)
+	if err != nil {
+		return storage.ContractAuditState{}, fmt.Errorf("the contract id provided is invalid: %s", err.Error())
+	}
+	state, _, err := h.storageHost.contractAuditState(common.Hash(id))
+	return state, err
+}
+
+// AuditContract compare the host's view of the contract with the client's view provided,
+// and return the structured difference
+func (h *HostPrivateAPI) AuditContract(contractID string, clientState storage.ContractAuditState) (storage.ContractAuditDiff, error) {
+	id, err := storage.StringToContractID(contractID)
+	if err != nil {
+		return storage.ContractAuditDiff{}, fmt.Errorf("the contract id provided is invalid: %s", err.Error())
+	}
+	return h.storageHost.auditContract(common.Hash(id), clientState)
+}
+
+// ExportDisputeEvidence export the signed evidence bundle of the contract to the file path,
+// including the signed revisions, the merkle roots, and the chain receipts of the contract
+func (h *HostPrivateAPI) ExportDisputeEvidence(contractID string, path string) (string, error) {
+	id, err := storage.StringToContractID(contractID)
+	if err != nil {
+		return "", fmt.Errorf("the contract id provided is invalid: %s", err.Error())
+	}
+	if err = h.storageHost.exportDisputeEvidence(common.Hash(id), path); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("successfully exported the dispute evidence to %v", path), nil
+}
+
+// ExportAccounting export the revenue and the expenses of the storage responsibilities to the
+// file path in the format csv or ofx. The entries of the same category are summed up within
+// each period, which is one of none, day, week, month and year
+func (h *HostPrivateAPI) ExportAccounting(path string, format string, period string) (string, error) {
+	if err := h.storageHost.exportAccounting(path, format, period); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("successfully exported the accounting to %v", path), nil
+}
+
+// PruneResponsibilities archives or deletes the settled storage responsibilities whose proof
+// deadline is older than the retention, and compacts the database to reclaim the disk space
+func (h *HostPrivateAPI) PruneResponsibilities() (PruneReport, error) {
+	return h.storageHost.compactResponsibilities()
+}
+
+// ArchivedResponsibility returns the archive record of the pruned storage responsibility
+func (h *HostPrivateAPI) ArchivedResponsibility(contractID string) (ArchivedResponsibility, error) {
+	id, err := storage.StringToContractID(contractID)
+	if err != nil {
+		return ArchivedResponsibility{}, fmt.Errorf("the contract id provided is invalid: %s", err.Error())
+	}
+	return getArchivedResponsibility(h.storageHost.db, common.Hash(id))
+}
+
 // hostSetterCallbacks is the mapping from the field name to the setter function
 var hostSetterCallbacks = map[string]func(*HostPrivateAPI, string) error{
 	"acceptingContracts":     (*HostPrivateAPI).setAcceptingContracts,
@@ -177,6 +454,17 @@ var hostSetterCallbacks = map[string]func(*HostPrivateAPI, string) error{
 	"sectorAccessPrice":      (*HostPrivateAPI).setSectorAccessPrice,
 	"storagePrice":           (*HostPrivateAPI).setStoragePrice,
 	"uploadBandwidthPrice":   (*HostPrivateAPI).setUploadBandwidthPrice,
+	"dynamicPricing":         (*HostPrivateAPI).setDynamicPricing,
+	"minStoragePrice":        (*HostPrivateAPI).setMinStoragePrice,
+	"maxStoragePrice":        (*HostPrivateAPI).setMaxStoragePrice,
+	"privateHosting":         (*HostPrivateAPI).setPrivateHosting,
+	"proofConfirmBlocks":     (*HostPrivateAPI).setProofConfirmBlocks,
+	"maxTxGasPrice":          (*HostPrivateAPI).setMaxTxGasPrice,
+
+	"responsibilityRetention":        (*HostPrivateAPI).setResponsibilityRetention,
+	"discardSettledResponsibilities": (*HostPrivateAPI).setDiscardSettledResponsibilities,
+
+	"featureFlags": (*HostPrivateAPI).setFeatureFlags,
 }
 
 // SetConfig set the config specified by a mapping of key value pair
@@ -204,6 +492,10 @@ func (h *HostPrivateAPI) SetConfig(config map[string]string) (string, error) {
 			return "", err
 		}
 	}
+	// the dynamic storage price bounds could be set in any order
+	if err = validateStoragePriceBounds(h.storageHost.config); err != nil {
+		return "", err
+	}
 	// sync the config
 	if err = h.storageHost.syncConfig(); err != nil {
 		return "", err
@@ -366,3 +658,103 @@ func (h *HostPrivateAPI) setUploadBandwidthPrice(str string) error {
 	h.storageHost.config.UploadBandwidthPrice = wei
 	return nil
 }
+
+// setDynamicPricing set host DynamicPricing to val specified by valStr
+func (h *HostPrivateAPI) setDynamicPricing(valStr string) error {
+	val, err := unit.ParseBool(valStr)
+	if err != nil {
+		return fmt.Errorf("invalid bool string: %v", err)
+	}
+	h.storageHost.config.DynamicPricing = val
+	return nil
+}
+
+// setMinStoragePrice set host MinStoragePrice to value
+func (h *HostPrivateAPI) setMinStoragePrice(str string) error {
+	wei, err := unit.ParseCurrency(str)
+	if err != nil {
+		return fmt.Errorf("invalid currency expression: %v", err)
+	}
+	h.storageHost.config.MinStoragePrice = wei
+	return nil
+}
+
+// setMaxStoragePrice set host MaxStoragePrice to value
+func (h *HostPrivateAPI) setMaxStoragePrice(str string) error {
+	wei, err := unit.ParseCurrency(str)
+	if err != nil {
+		return fmt.Errorf("invalid currency expression: %v", err)
+	}
+	h.storageHost.config.MaxStoragePrice = wei
+	return nil
+}
+
+// setPrivateHosting set host PrivateHosting to val specified by valStr
+func (h *HostPrivateAPI) setPrivateHosting(valStr string) error {
+	val, err := unit.ParseBool(valStr)
+	if err != nil {
+		return fmt.Errorf("invalid bool string: %v", err)
+	}
+	h.storageHost.config.PrivateHosting = val
+	return nil
+}
+
+// setProofConfirmBlocks set host ProofConfirmBlocks to value
+func (h *HostPrivateAPI) setProofConfirmBlocks(str string) error {
+	val, err := unit.ParseTime(str)
+	if err != nil {
+		return fmt.Errorf("invalid time duration string: %v", err)
+	}
+	if val == 0 {
+		return errors.New("the proof confirm blocks must be positive")
+	}
+	h.storageHost.config.ProofConfirmBlocks = val
+	return nil
+}
+
+// setMaxTxGasPrice set host MaxTxGasPrice to value
+func (h *HostPrivateAPI) setMaxTxGasPrice(str string) error {
+	val, err := unit.ParseCurrency(str)
+	if err != nil {
+		return fmt.Errorf("invalid currency expression: %v", err)
+	}
+	if val.Sign() <= 0 {
+		return errors.New("the max tx gas price must be positive")
+	}
+	h.storageHost.config.MaxTxGasPrice = val
+	return nil
+}
+
+// setResponsibilityRetention set host ResponsibilityRetention to value
+func (h *HostPrivateAPI) setResponsibilityRetention(str string) error {
+	val, err := unit.ParseTime(str)
+	if err != nil {
+		return fmt.Errorf("invalid time duration string: %v", err)
+	}
+	if val == 0 {
+		return errors.New("the responsibility retention must be positive")
+	}
+	h.storageHost.config.ResponsibilityRetention = val
+	return nil
+}
+
+// setDiscardSettledResponsibilities set host DiscardSettledResponsibilities to val specified by valStr
+func (h *HostPrivateAPI) setDiscardSettledResponsibilities(valStr string) error {
+	val, err := unit.ParseBool(valStr)
+	if err != nil {
+		return fmt.Errorf("invalid bool string: %v", err)
+	}
+	h.storageHost.config.DiscardSettledResponsibilities = val
+	return nil
+}
+
+// setFeatureFlags set host FeatureFlags to the comma separated features, each optionally
+// followed by @height the feature is active from
+func (h *HostPrivateAPI) setFeatureFlags(str string) error {
+	val, err := storage.ParseFeatureFlags(str)
+	if err != nil {
+		return fmt.Errorf("invalid feature flags: %v", err)
+	}
+	h.storageHost.config.FeatureFlags = val
+	return nil
+}
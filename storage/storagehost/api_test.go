@@ -97,6 +97,17 @@ func TestHostPrivateAPI_SetConfig(t *testing.T) {
 			storage.HostIntConfig{UploadBandwidthPrice: mustParseCurrency("1camel")},
 			nil,
 		},
+		"dynamicPricing": {
+			map[string]string{"dynamicPricing": "true", "minStoragePrice": "1camel", "maxStoragePrice": "10camel"},
+			storage.HostIntConfig{DynamicPricing: true, MinStoragePrice: mustParseCurrency("1camel"),
+				MaxStoragePrice: mustParseCurrency("10camel")},
+			nil,
+		},
+		"storage price bounds error": {
+			map[string]string{"minStoragePrice": "10camel", "maxStoragePrice": "1camel"},
+			storage.HostIntConfig{},
+			errors.New("invalid bounds"),
+		},
 		"currency parse error": {
 			map[string]string{"baseRPCPrice": "1234", "acceptingContracts": "true"},
 			storage.HostIntConfig{},
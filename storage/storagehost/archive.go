@@ -0,0 +1,252 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/rlp"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// The settled storage responsibilities, either succeeded, failed or rejected, are kept in the
+// database after the sector roots are cleared. Once the proof deadline is older than the
+// retention, the storage responsibility is pruned: it is replaced by a compact archive record
+// keeping the financial values, or discarded completely if configured so. The dispute evidence
+// snapshot of the failed storage responsibility is not pruned.
+
+// pruneLockTimeout is the time waiting for the storage responsibility lock during the pruning.
+// The storage responsibility locked by others is left to the next pruning
+const pruneLockTimeout = 100 * time.Millisecond
+
+type (
+	// ArchivedResponsibility is the compact record of the pruned storage responsibility, which
+	// keeps the values needed by the financial metrics of the host
+	ArchivedResponsibility struct {
+		ContractID    common.Hash                 `json:"contractID"`
+		Status        storageResponsibilityStatus `json:"status"`
+		ProofDeadline uint64                      `json:"proofDeadline"`
+		ArchiveHeight uint64                      `json:"archiveHeight"`
+
+		ContractCost             common.BigInt `json:"contractCost"`
+		PotentialDownloadRevenue common.BigInt `json:"potentialDownloadRevenue"`
+		PotentialStorageRevenue  common.BigInt `json:"potentialStorageRevenue"`
+		PotentialUploadRevenue   common.BigInt `json:"potentialUploadRevenue"`
+		RiskedStorageDeposit     common.BigInt `json:"riskedStorageDeposit"`
+		TransactionFeeExpenses   common.BigInt `json:"transactionFeeExpenses"`
+	}
+
+	// PruneReport is the result of pruning the settled storage responsibilities
+	PruneReport struct {
+		BlockHeight uint64 `json:"blockHeight"`
+		Retention   uint64 `json:"retention"`
+		Archived    int    `json:"archived"`
+		Deleted     int    `json:"deleted"`
+		Remaining   int    `json:"remaining"`
+	}
+)
+
+// newArchivedResponsibility creates the archive record of the settled storage responsibility
+func newArchivedResponsibility(so StorageResponsibility, height uint64) ArchivedResponsibility {
+	return ArchivedResponsibility{
+		ContractID:               so.id(),
+		Status:                   so.ResponsibilityStatus,
+		ProofDeadline:            so.proofDeadline(),
+		ArchiveHeight:            height,
+		ContractCost:             so.ContractCost,
+		PotentialDownloadRevenue: so.PotentialDownloadRevenue,
+		PotentialStorageRevenue:  so.PotentialStorageRevenue,
+		PotentialUploadRevenue:   so.PotentialUploadRevenue,
+		RiskedStorageDeposit:     so.RiskedStorageDeposit,
+		TransactionFeeExpenses:   so.TransactionFeeExpenses,
+	}
+}
+
+// responsibility returns the storage responsibility with the financial values of the archive
+// record, which is used to reset the financial metrics
+func (ar ArchivedResponsibility) responsibility() StorageResponsibility {
+	return StorageResponsibility{
+		ContractCost:             ar.ContractCost,
+		PotentialDownloadRevenue: ar.PotentialDownloadRevenue,
+		PotentialStorageRevenue:  ar.PotentialStorageRevenue,
+		PotentialUploadRevenue:   ar.PotentialUploadRevenue,
+		RiskedStorageDeposit:     ar.RiskedStorageDeposit,
+		TransactionFeeExpenses:   ar.TransactionFeeExpenses,
+		ResponsibilityStatus:     ar.Status,
+	}
+}
+
+// putArchivedResponsibility stores the archive record of the pruned storage responsibility
+func putArchivedResponsibility(db ethdb.Database, ar ArchivedResponsibility) error {
+	scdb := ethdb.StorageContractDB{db}
+	data, err := rlp.EncodeToBytes(ar)
+	if err != nil {
+		return err
+	}
+	return scdb.StoreWithPrefix(ar.ContractID, data, prefixArchivedResponsibility)
+}
+
+// getArchivedResponsibility retrieves the archive record of the pruned storage responsibility
+func getArchivedResponsibility(db ethdb.Database, storageContractID common.Hash) (ArchivedResponsibility, error) {
+	scdb := ethdb.StorageContractDB{db}
+	valueBytes, err := scdb.GetWithPrefix(storageContractID, prefixArchivedResponsibility)
+	if err != nil {
+		return ArchivedResponsibility{}, err
+	}
+	var ar ArchivedResponsibility
+	if err = rlp.DecodeBytes(valueBytes, &ar); err != nil {
+		return ArchivedResponsibility{}, err
+	}
+	return ar, nil
+}
+
+// archivedResponsibilities returns all archive records in the database
+func archivedResponsibilities(db *ethdb.LDBDatabase) ([]ArchivedResponsibility, error) {
+	var ars []ArchivedResponsibility
+	err := iterateWithPrefix(db, prefixArchivedResponsibility, func(value []byte) error {
+		var ar ArchivedResponsibility
+		if err := rlp.DecodeBytes(value, &ar); err != nil {
+			return err
+		}
+		ars = append(ars, ar)
+		return nil
+	})
+	return ars, err
+}
+
+// persistedStorageResponsibilities returns all storage responsibilities in the database,
+// including the ones not loaded since the host started
+func persistedStorageResponsibilities(db *ethdb.LDBDatabase) ([]StorageResponsibility, error) {
+	var sos []StorageResponsibility
+	err := iterateWithPrefix(db, prefixStorageResponsibility, func(value []byte) error {
+		var so StorageResponsibility
+		if err := rlp.DecodeBytes(value, &so); err != nil {
+			return err
+		}
+		sos = append(sos, so)
+		return nil
+	})
+	return sos, err
+}
+
+// iterateWithPrefix calls the function with the value of each key with the prefix
+func iterateWithPrefix(db *ethdb.LDBDatabase, prefix string, fn func(value []byte) error) error {
+	iter := db.NewIteratorWithPrefix([]byte(prefix))
+	defer iter.Release()
+	for iter.Next() {
+		if err := fn(iter.Value()); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
+// responsibilityRetention returns the number of blocks the settled storage responsibility is
+// kept after its proof deadline
+//
+// NOTE: h.lock should be held when calling this function
+func (h *StorageHost) responsibilityRetention() uint64 {
+	if h.config.ResponsibilityRetention == 0 {
+		return defaultResponsibilityRetention
+	}
+	return h.config.ResponsibilityRetention
+}
+
+// prunable returns whether the storage responsibility is settled, with the proof deadline
+// older than the retention
+func (so *StorageResponsibility) prunable(height, retention uint64) bool {
+	return so.ResponsibilityStatus != responsibilityUnresolved && so.proofDeadline()+retention < height
+}
+
+// pruneStorageResponsibilities archives or deletes the settled storage responsibilities whose
+// proof deadline is older than the retention
+func (h *StorageHost) pruneStorageResponsibilities() (PruneReport, error) {
+	h.lock.RLock()
+	report := PruneReport{
+		BlockHeight: h.blockHeight,
+		Retention:   h.responsibilityRetention(),
+	}
+	discard := h.config.DiscardSettledResponsibilities
+	h.lock.RUnlock()
+
+	sos, err := persistedStorageResponsibilities(h.db)
+	if err != nil {
+		return PruneReport{}, err
+	}
+	for _, so := range sos {
+		if !so.prunable(report.BlockHeight, report.Retention) {
+			report.Remaining++
+			continue
+		}
+		id := so.id()
+		if err := h.checkAndTryLockStorageResponsibility(id, pruneLockTimeout); err != nil {
+			report.Remaining++
+			continue
+		}
+		if err := h.pruneStorageResponsibility(so, report.BlockHeight, discard); err != nil {
+			h.checkAndUnlockStorageResponsibility(id)
+			return report, err
+		}
+		if discard {
+			report.Deleted++
+		} else {
+			report.Archived++
+		}
+	}
+	if report.Archived+report.Deleted > 0 {
+		h.log.Info("Pruned settled storage responsibilities", "archived", report.Archived,
+			"deleted", report.Deleted, "remaining", report.Remaining, "retention", report.Retention)
+	}
+	return report, nil
+}
+
+// pruneStorageResponsibility replaces the storage responsibility with the archive record, or
+// deletes it if discard is set. The lock of the storage responsibility is dropped along with it
+func (h *StorageHost) pruneStorageResponsibility(so StorageResponsibility, height uint64, discard bool) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if !discard {
+		if err := putArchivedResponsibility(h.db, newArchivedResponsibility(so, height)); err != nil {
+			return err
+		}
+	}
+	if err := deleteStorageResponsibility(h.db, so.id()); err != nil {
+		return err
+	}
+	delete(h.lockedStorageResponsibility, so.id())
+	return nil
+}
+
+// compactResponsibilities prunes the settled storage responsibilities, and compacts the
+// database to reclaim the disk space at once
+func (h *StorageHost) compactResponsibilities() (PruneReport, error) {
+	report, err := h.pruneStorageResponsibilities()
+	if err != nil {
+		return report, err
+	}
+	if err = h.db.LDB().CompactRange(util.Range{}); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// updateResponsibilityPruning prunes the settled storage responsibilities once every
+// responsibilityPruneInterval blocks. It is called on block height change
+func (h *StorageHost) updateResponsibilityPruning() {
+	h.lock.Lock()
+	if h.blockHeight < h.prunedHeight+responsibilityPruneInterval {
+		h.lock.Unlock()
+		return
+	}
+	h.prunedHeight = h.blockHeight
+	h.lock.Unlock()
+
+	if _, err := h.pruneStorageResponsibilities(); err != nil {
+		h.log.Warn("Failed to prune the storage responsibilities", "err", err)
+	}
+}
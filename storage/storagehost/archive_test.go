@@ -0,0 +1,96 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/log"
+)
+
+func TestStorageHost_PruneStorageResponsibilities(t *testing.T) {
+	db, err := ethdb.NewLDBDatabase(filepath.Join(tempDir(t.Name()), "db"), 16, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	h := &StorageHost{
+		db:                          db,
+		log:                         log.New(),
+		blockHeight:                 1000,
+		lockedStorageResponsibility: make(map[common.Hash]*TryMutex),
+	}
+	h.config.ResponsibilityRetention = 100
+
+	newSo := func(windowEnd uint64, status storageResponsibilityStatus) StorageResponsibility {
+		return StorageResponsibility{
+			OriginStorageContract: types.StorageContract{
+				WindowStart: windowEnd - 10,
+				WindowEnd:   windowEnd,
+			},
+			ContractCost:            common.NewBigIntUint64(10),
+			PotentialStorageRevenue: common.NewBigIntUint64(20),
+			ResponsibilityStatus:    status,
+		}
+	}
+	expired := newSo(800, responsibilitySucceeded)
+	retained := newSo(950, responsibilitySucceeded)
+	unresolved := newSo(800, responsibilityUnresolved)
+	for _, so := range []StorageResponsibility{expired, retained, unresolved} {
+		if err := putStorageResponsibility(db, so.id(), so); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := h.pruneStorageResponsibilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Archived != 1 || report.Deleted != 0 || report.Remaining != 2 {
+		t.Fatalf("prune report not expected. Got %+v", report)
+	}
+	if _, err := getStorageResponsibility(db, expired.id()); err == nil {
+		t.Fatal("the expired storage responsibility shall be pruned")
+	}
+	for _, so := range []StorageResponsibility{retained, unresolved} {
+		if _, err := getStorageResponsibility(db, so.id()); err != nil {
+			t.Fatalf("the storage responsibility shall be retained: %v", err)
+		}
+	}
+	ar, err := getArchivedResponsibility(db, expired.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ar.Status != responsibilitySucceeded || ar.ProofDeadline != 800 || ar.ArchiveHeight != 1000 {
+		t.Fatalf("archive record not expected. Got %+v", ar)
+	}
+
+	// the archived revenue is still counted in the financial metrics
+	if err := h.resetFinancialMetrics(); err != nil {
+		t.Fatal(err)
+	}
+	if h.financialMetrics.StorageRevenue.Cmp(common.NewBigIntUint64(20)) != 0 {
+		t.Fatalf("storage revenue not expected. Got %v", h.financialMetrics.StorageRevenue)
+	}
+
+	// the settled storage responsibility is deleted without archive record if discarded
+	h.config.DiscardSettledResponsibilities = true
+	h.blockHeight = 1100
+	report, err = h.compactResponsibilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Archived != 0 || report.Deleted != 1 || report.Remaining != 1 {
+		t.Fatalf("prune report not expected. Got %+v", report)
+	}
+	if _, err := getArchivedResponsibility(db, retained.id()); err == nil {
+		t.Fatal("the discarded storage responsibility shall not be archived")
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/p2p"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// ContractAuditHandler handles the contract audit request sent by the storage client. The host
+// responds with its view of the contract, which will be compared with the client's. The request
+// must be signed by the storage client of the contract
+func ContractAuditHandler(h *StorageHost, sp storage.Peer, auditReqMsg p2p.Msg) {
+	var hostNegotiateErr error
+	defer func() {
+		if hostNegotiateErr != nil {
+			log.Debug("storage host failed to handle contract audit", "err", hostNegotiateErr)
+			_ = sp.SendHostNegotiateErrorMsg()
+		}
+	}()
+
+	var req storage.ContractAuditRequest
+	if err := auditReqMsg.Decode(&req); err != nil {
+		hostNegotiateErr = fmt.Errorf("failed to decode the contract audit request message: %s", err.Error())
+		return
+	}
+
+	state, client, err := h.contractAuditState(req.StorageContractID)
+	if err != nil {
+		hostNegotiateErr = err
+		return
+	}
+
+	// only the storage client of the contract could read the host's view of the contract
+	if err := storage.VerifyContractAuditRequest(req, client); err != nil {
+		hostNegotiateErr = err
+		return
+	}
+
+	if err := sp.SendContractAuditState(state); err != nil {
+		log.Error("storage host failed to send contract audit state", "err", err)
+	}
+}
+
+// contractAuditState returns the host's view of the contract, based on the storage responsibility,
+// along with the client address of the contract
+func (h *StorageHost) contractAuditState(id common.Hash) (state storage.ContractAuditState, client common.Address, err error) {
+	h.lock.RLock()
+	so, err := getStorageResponsibility(h.db, id)
+	h.lock.RUnlock()
+	if err != nil {
+		return
+	}
+
+	if len(so.StorageContractRevisions) == 0 {
+		return state, client, errors.New("no contract revision found")
+	}
+	rev := so.StorageContractRevisions[len(so.StorageContractRevisions)-1]
+	return storage.NewContractAuditState(id, rev, so.SectorRoots), rev.NewValidProofOutputs[0].Address, nil
+}
+
+// auditContract compares the host's view of the contract with the client's
+func (h *StorageHost) auditContract(id common.Hash, clientState storage.ContractAuditState) (diff storage.ContractAuditDiff, err error) {
+	state, _, err := h.contractAuditState(id)
+	if err != nil {
+		return
+	}
+	return storage.DiffContractAuditStates(state, clientState), nil
+}
@@ -50,6 +50,12 @@ func ContractCreateHandler(h *StorageHost, sp storage.Peer, contractCreateReqMsg
 		return
 	}
 
+	// private host only negotiates with the clients in its accept-list
+	if !h.isClientAccepted(crypto.PubkeyToAddress(*clientPK)) {
+		hostNegotiateErr = errClientNotAccepted
+		return
+	}
+
 	// Check host balance >= storage contract cost
 	hostAddress := sc.ValidProofOutputs[1].Address
 	stateDB, err := h.ethBackend.GetBlockChain().State()
@@ -167,7 +173,7 @@ func ContractCreateHandler(h *StorageHost, sp storage.Peer, contractCreateReqMsg
 	so := StorageResponsibility{
 		SectorRoots:              nil,
 		ContractCost:             h.externalConfig().ContractPrice,
-		LockedStorageDeposit:     common.NewBigInt(sc.ValidProofOutputs[1].Value.Int64()).Sub(h.externalConfig().ContractPrice),
+		LockedStorageDeposit:     common.PtrBigInt(sc.ValidProofOutputs[1].Value).Sub(h.externalConfig().ContractPrice),
 		PotentialStorageRevenue:  common.BigInt0,
 		RiskedStorageDeposit:     common.BigInt0,
 		NegotiationBlockNumber:   height,
@@ -194,7 +200,7 @@ func ContractCreateHandler(h *StorageHost, sp storage.Peer, contractCreateReqMsg
 			}
 
 			renewRevenue := renewBasePrice(so, h.externalConfig(), req.StorageContract)
-			so.ContractCost = common.NewBigInt(req.StorageContract.ValidProofOutputs[1].Value.Int64()).Sub(h.externalConfig().ContractPrice).Sub(renewRevenue)
+			so.ContractCost = common.PtrBigInt(req.StorageContract.ValidProofOutputs[1].Value).Sub(h.externalConfig().ContractPrice).Sub(renewRevenue)
 			so.PotentialStorageRevenue = renewRevenue
 			so.RiskedStorageDeposit = renewBaseDeposit(so, h.externalConfig(), req.StorageContract)
 		}
@@ -255,6 +261,10 @@ func verifyStorageContract(h *StorageHost, sc *types.StorageContract, clientPK *
 
 	externalConfig := h.externalConfig()
 
+	if !clientAccepted(config, crypto.PubkeyToAddress(*clientPK)) {
+		return errClientNotAccepted
+	}
+
 	// A new file contract should have a file size of zero
 	if sc.FileSize != 0 {
 		return errBadFileSize
@@ -302,7 +312,7 @@ func verifyStorageContract(h *StorageHost, sc *types.StorageContract, clientPK *
 	}
 	// Check that the collateral does not exceed the maximum amount of
 	// collateral allowed.
-	depositMinusContractPrice := common.NewBigInt(sc.ValidProofOutputs[1].Value.Int64()).Sub(externalConfig.ContractPrice)
+	depositMinusContractPrice := common.PtrBigInt(sc.ValidProofOutputs[1].Value).Sub(externalConfig.ContractPrice)
 	if depositMinusContractPrice.Cmp(config.MaxDeposit) > 0 {
 		return errMaxCollateralReached
 	}
@@ -395,6 +405,10 @@ func verifyRenewedContract(h *StorageHost, sc *types.StorageContract, clientPK *
 
 	externalConfig := h.externalConfig()
 
+	if !clientAccepted(config, crypto.PubkeyToAddress(*clientPK)) {
+		return errClientNotAccepted
+	}
+
 	// check that the file size and merkle root whether match the previous.
 	if sc.FileSize != so.fileSize() {
 		return errBadFileSize
@@ -432,7 +446,7 @@ func verifyRenewedContract(h *StorageHost, sc *types.StorageContract, clientPK *
 	// Check that the collateral does not exceed the maximum amount of
 	// collateral allowed.
 	basePrice := renewBasePrice(so, externalConfig, *sc)
-	expectedCollateral := common.NewBigInt(sc.ValidProofOutputs[1].Value.Int64()).Sub(externalConfig.ContractPrice).Sub(basePrice)
+	expectedCollateral := common.PtrBigInt(sc.ValidProofOutputs[1].Value).Sub(externalConfig.ContractPrice).Sub(basePrice)
 	if expectedCollateral.Cmp(externalConfig.MaxDeposit) > 0 {
 		return errMaxCollateralReached
 	}
@@ -449,7 +463,7 @@ func verifyRenewedContract(h *StorageHost, sc *types.StorageContract, clientPK *
 	if sc.ValidProofOutputs[1].Value.Cmp(totalPayout.BigIntPtr()) < 0 {
 		return errLowHostValidOutput
 	}
-	expectedHostMissedOutput := common.NewBigInt(sc.ValidProofOutputs[1].Value.Int64()).Sub(basePrice).Sub(baseCollateral)
+	expectedHostMissedOutput := common.PtrBigInt(sc.ValidProofOutputs[1].Value).Sub(basePrice).Sub(baseCollateral)
 	if sc.MissedProofOutputs[1].Value.Cmp(expectedHostMissedOutput.BigIntPtr()) < 0 {
 		return errLowHostMissedOutput
 	}
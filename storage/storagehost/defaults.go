@@ -33,10 +33,42 @@ const (
 	postponedExecution    = 3  //Total length of time to start a test task
 	confirmedBufferHeight = 40 //signing transaction not confirmed maximum time
 
+	// proofFeeBumpPercent is the percentage the gas price of the overdue proof tx is raised by,
+	// which shall be no less than the price bump required by the tx pool to replace a tx
+	proofFeeBumpPercent = 10
+	// maxProofAlerts is the maximum number of recent proof alerts kept in memory
+	maxProofAlerts = 100
+	// storageProofBatchSize is the maximum number of storage proofs submitted in one batch
+	// storage proof tx, no more than vm.MaxStorageProofBatchSize
+	storageProofBatchSize = 64
+	// payoutConfirmations is the number of confirmations the storage proof tx needs before the
+	// revenue of the storage responsibility is counted as final
+	payoutConfirmations = uint64(12)
+
 	//prefixStorageResponsibility db prefix for StorageResponsibility
 	prefixStorageResponsibility = "StorageResponsibility-"
 	//prefixHeight db prefix for task
 	prefixHeight = "height-"
+	//prefixEvidenceSnapshot db prefix for the storage responsibility snapshot of the missed proof
+	prefixEvidenceSnapshot = "EvidenceSnapshot-"
+	//prefixProofCache db prefix for the chunk roots of the sectors used in the storage proof
+	prefixProofCache = "ProofCache-"
+	//prefixProofSubmission db prefix for the storage proof tx submitted and not confirmed yet
+	prefixProofSubmission = "ProofSubmission-"
+	//prefixProofPayout db prefix for the block the storage proof tx is mined in
+	prefixProofPayout = "ProofPayout-"
+	//prefixArchivedResponsibility db prefix for the archive record of the pruned StorageResponsibility
+	prefixArchivedResponsibility = "ArchivedResponsibility-"
+	//prefixPublicSector db prefix for the storage contract of the sector published by the client
+	prefixPublicSector = "PublicSector-"
+
+	// maxIngressBuckets is the number of the rate limit buckets of the remote IPs, beyond
+	// which the buckets refilled completely are dropped
+	maxIngressBuckets = 4096
+
+	// ProofCacheChunkSize is the size of the sector chunk whose merkle root is cached for the
+	// storage proof. Only one chunk is read from the disk when building the storage proof
+	ProofCacheChunkSize = 1 << 16
 )
 
 var (
@@ -53,6 +85,15 @@ var (
 	defaultMaxDownloadBatchSize = 17 * (1 << 20)            // 17 MB
 	defaultMaxReviseBatchSize   = 17 * (1 << 20)            // 17 MB
 	defaultWindowSize           = 5 * storage.BlockPerHour  // 5 hours
+	defaultProofConfirmBlocks   = uint64(10)                // 10 blocks
+
+	// defaultResponsibilityRetention is the number of blocks the settled storage responsibility
+	// is kept after the proof deadline before it is pruned
+	defaultResponsibilityRetention = storage.BlocksPerMonth // 30 days
+
+	// responsibilityPruneInterval is the number of blocks between the pruning of the settled
+	// storage responsibilities
+	responsibilityPruneInterval = storage.BlockPerHour
 
 	// deposit defaults value
 	defaultDeposit       = common.PtrBigInt(math.BigPow(10, 3))  // 173 dx per TB per month
@@ -67,11 +108,23 @@ var (
 	defaultStoragePrice           = common.PtrBigInt(math.BigPow(10, 3))                                    // Same as deposit
 	defaultUploadBandwidthPrice   = common.PtrBigInt(math.BigPow(10, 7))                                    // 10 DX per TB
 
+	// dynamic storage price bounds
+	defaultMinStoragePrice = common.PtrBigInt(new(big.Int).Mul(math.BigPow(10, 2), big.NewInt(5))) // Half of storage price
+	defaultMaxStoragePrice = common.PtrBigInt(new(big.Int).Mul(math.BigPow(10, 3), big.NewInt(4))) // Four times of storage price
+
 	//Storage contract should not be empty
 	emptyStorageContract = types.StorageContract{}
 
 	//Total time to sign the contract
 	postponedExecutionBuffer = storage.BlocksPerDay
+
+	// scarceCapacityThreshold is the ratio of free storage below which the storage price
+	// is raised towards the MaxStoragePrice when dynamic pricing is enabled
+	scarceCapacityThreshold = 0.2
+
+	// plentifulCapacityThreshold is the ratio of free storage above which the storage price
+	// is lowered towards the MinStoragePrice when dynamic pricing is enabled
+	plentifulCapacityThreshold = 0.8
 )
 
 // init set the initial value for sector height
@@ -88,6 +141,12 @@ func calculateSectorHeight() uint64 {
 	return height
 }
 
+// DefaultConfig returns the default internal config of the storage host, which is used by
+// the tools mimicking the storage host as well, such as the mock host
+func DefaultConfig() storage.HostIntConfig {
+	return defaultConfig()
+}
+
 // defaultConfig loads the default setting when
 // it is the first time use the host service, or cannot find the setting file
 func defaultConfig() storage.HostIntConfig {
@@ -107,6 +166,16 @@ func defaultConfig() storage.HostIntConfig {
 		SectorAccessPrice:      defaultSectorAccessPrice,
 		StoragePrice:           defaultStoragePrice,
 		UploadBandwidthPrice:   defaultUploadBandwidthPrice,
+
+		MinStoragePrice: defaultMinStoragePrice,
+		MaxStoragePrice: defaultMaxStoragePrice,
+
+		ProofConfirmBlocks: defaultProofConfirmBlocks,
+		MaxTxGasPrice:      storage.DefaultMaxTxGasPrice,
+
+		ResponsibilityRetention: defaultResponsibilityRetention,
+
+		FeatureFlags: storage.DefaultFeatureFlags(),
 	}
 }
 
@@ -135,7 +135,7 @@ func DownloadHandler(h *StorageHost, sp storage.Peer, downloadReqMsg p2p.Msg) {
 	newRevision.Signatures = [][]byte{req.Signature, hostSig}
 
 	// update the storage responsibility.
-	paymentTransfer := common.NewBigInt(currentRevision.NewValidProofOutputs[0].Value.Int64()).Sub(common.NewBigInt(newRevision.NewValidProofOutputs[0].Value.Int64()))
+	paymentTransfer := common.PtrBigInt(currentRevision.NewValidProofOutputs[0].Value).Sub(common.PtrBigInt(newRevision.NewValidProofOutputs[0].Value))
 	so.PotentialDownloadRevenue = so.PotentialDownloadRevenue.Add(paymentTransfer)
 	so.StorageContractRevisions = append(so.StorageContractRevisions, newRevision)
 
@@ -255,7 +255,7 @@ func verifyPaymentRevision(existingRevision, paymentRevision types.StorageContra
 	}
 
 	// Verify that enough money was transferred.
-	fromClient := common.NewBigInt(existingRevision.NewValidProofOutputs[0].Value.Int64()).Sub(common.NewBigInt(paymentRevision.NewValidProofOutputs[0].Value.Int64()))
+	fromClient := common.PtrBigInt(existingRevision.NewValidProofOutputs[0].Value).Sub(common.PtrBigInt(paymentRevision.NewValidProofOutputs[0].Value))
 	if fromClient.BigIntPtr().Cmp(expectedTransfer) < 0 {
 		s := fmt.Sprintf("expected at least %v to be exchanged, but %v was exchanged during downloading: ", expectedTransfer, fromClient)
 		return ExtendErr(s, errHighClientValidOutput)
@@ -267,7 +267,7 @@ func verifyPaymentRevision(existingRevision, paymentRevision types.StorageContra
 	}
 
 	// Verify that enough money was transferred.
-	toHost := common.NewBigInt(paymentRevision.NewValidProofOutputs[1].Value.Int64()).Sub(common.NewBigInt(existingRevision.NewValidProofOutputs[1].Value.Int64()))
+	toHost := common.PtrBigInt(paymentRevision.NewValidProofOutputs[1].Value).Sub(common.PtrBigInt(existingRevision.NewValidProofOutputs[1].Value))
 	if toHost.Cmp(fromClient) != 0 {
 		s := fmt.Sprintf("expected exactly %v to be transferred to the host, but %v was transferred during downloading: ", fromClient, toHost)
 		return ExtendErr(s, errLowHostValidOutput)
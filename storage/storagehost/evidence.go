@@ -0,0 +1,227 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/common/hexutil"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/core/vm"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/rlp"
+)
+
+type (
+	// DisputeEvidence is the portable evidence bundle of a storage contract, which can be
+	// used for the off-chain arbitration when the host missed a proof or the client disputes
+	// the data loss. The bundle is signed by the node key of the storage host
+	DisputeEvidence struct {
+		ContractID            common.Hash                     `json:"contractID"`
+		Status                string                          `json:"status"`
+		ExportedAt            time.Time                       `json:"exportedAt"`
+		BlockHeight           uint64                          `json:"blockHeight"`
+		OriginStorageContract types.StorageContract           `json:"originStorageContract"`
+		Revisions             []types.StorageContractRevision `json:"revisions"`
+		MerkleRoots           []common.Hash                   `json:"merkleRoots"`
+		Transactions          []EvidenceTransaction           `json:"transactions"`
+		Signature             hexutil.Bytes                   `json:"signature"`
+	}
+
+	// EvidenceTransaction is the transaction of the storage contract found on chain,
+	// along with its receipt
+	EvidenceTransaction struct {
+		Type        string         `json:"type"`
+		TxHash      common.Hash    `json:"txHash"`
+		BlockHash   common.Hash    `json:"blockHash"`
+		BlockNumber uint64         `json:"blockNumber"`
+		Receipt     *types.Receipt `json:"receipt"`
+	}
+)
+
+// Hash returns the hash of the evidence bundle that is signed by the storage host.
+// The signature itself is excluded
+func (de DisputeEvidence) Hash() (common.Hash, error) {
+	de.Signature = nil
+	blob, err := json.Marshal(de)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(blob), nil
+}
+
+// exportDisputeEvidence writes the signed evidence bundle of the contract to the file path as JSON
+func (h *StorageHost) exportDisputeEvidence(id common.Hash, path string) error {
+	evidence, err := h.disputeEvidence(id)
+	if err != nil {
+		return err
+	}
+	blob, err := json.MarshalIndent(evidence, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, blob, 0600)
+}
+
+// disputeEvidence collects the signed revisions, the merkle roots, and the chain receipts of the
+// contract. If the host missed the storage proof, the snapshot saved before the merkle roots were
+// cleared will be used
+func (h *StorageHost) disputeEvidence(id common.Hash) (evidence DisputeEvidence, err error) {
+	h.lock.RLock()
+	blockHeight := h.blockHeight
+	so, err := getEvidenceSnapshot(h.db, id)
+	if err != nil {
+		so, err = getStorageResponsibility(h.db, id)
+	}
+	h.lock.RUnlock()
+	if err != nil {
+		return
+	}
+
+	evidence = DisputeEvidence{
+		ContractID:            id,
+		Status:                so.ResponsibilityStatus.String(),
+		ExportedAt:            h.clock.Now(),
+		BlockHeight:           blockHeight,
+		OriginStorageContract: so.OriginStorageContract,
+		Revisions:             so.StorageContractRevisions,
+		MerkleRoots:           so.SectorRoots,
+	}
+
+	// search the contract related transactions within the lifetime of the contract
+	end := so.proofDeadline() + confirmedBufferHeight
+	if end > blockHeight {
+		end = blockHeight
+	}
+	if evidence.Transactions, err = h.contractTransactions(id, so.NegotiationBlockNumber, end); err != nil {
+		return
+	}
+
+	hash, err := evidence.Hash()
+	if err != nil {
+		return
+	}
+	evidence.Signature, err = h.ethBackend.SignWithNodeSk(hash.Bytes())
+	return
+}
+
+// contractTransactions searches the blocks within the range for the contract create, revision,
+// and storage proof transactions of the contract, along with their receipts
+func (h *StorageHost) contractTransactions(id common.Hash, start, end uint64) (txs []EvidenceTransaction, err error) {
+	precompiled := vm.PrecompiledEVMFileContracts
+	for number := start; number <= end; number++ {
+		block, err := h.ethBackend.GetBlockByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+
+		var receipts types.Receipts
+		for i, tx := range block.Transactions() {
+			if tx.To() == nil {
+				continue
+			}
+			p, ok := precompiled[*tx.To()]
+			if !ok || !containsContractID(contractIDsOfTx(p, tx.Data()), id) {
+				continue
+			}
+			if receipts == nil {
+				receipts = h.ethBackend.GetBlockChain().GetReceiptsByHash(block.Hash())
+			}
+			evidenceTx := EvidenceTransaction{
+				Type:        p,
+				TxHash:      tx.Hash(),
+				BlockHash:   block.Hash(),
+				BlockNumber: number,
+			}
+			if i < len(receipts) {
+				evidenceTx.Receipt = receipts[i]
+			}
+			txs = append(txs, evidenceTx)
+		}
+	}
+	return txs, nil
+}
+
+// contractIDsOfTx decodes the storage contract ids from the precompiled contract transaction data.
+// The batch storage proof transaction has the ids of all contracts proofed, and the contract renew
+// transaction has the ids of both the old and new contracts. Nil is returned if the data cannot
+// be decoded
+func contractIDsOfTx(p string, data []byte) []common.Hash {
+	switch p {
+	case vm.ContractCreateTransaction:
+		var sc types.StorageContract
+		if err := rlp.DecodeBytes(data, &sc); err == nil {
+			return []common.Hash{sc.RLPHash()}
+		}
+	case vm.CommitRevisionTransaction:
+		var scr types.StorageContractRevision
+		if err := rlp.DecodeBytes(data, &scr); err == nil {
+			return []common.Hash{scr.ParentID}
+		}
+	case vm.StorageProofTransaction:
+		var sp types.StorageProof
+		if err := rlp.DecodeBytes(data, &sp); err == nil {
+			return []common.Hash{sp.ParentID}
+		}
+	case vm.BatchStorageProofTransaction:
+		var batch types.StorageProofBatch
+		if err := rlp.DecodeBytes(data, &batch); err == nil {
+			ids := make([]common.Hash, 0, len(batch.Proofs))
+			for _, sp := range batch.Proofs {
+				ids = append(ids, sp.ParentID)
+			}
+			return ids
+		}
+	case vm.ContractRenewTransaction:
+		var renewal types.StorageContractRenewal
+		if err := rlp.DecodeBytes(data, &renewal); err == nil {
+			return []common.Hash{renewal.OldContractID, renewal.NewContract.RLPHash()}
+		}
+	}
+	return nil
+}
+
+// containsContractID checks whether the contract id is in the ids
+func containsContractID(ids []common.Hash, id common.Hash) bool {
+	for _, contractID := range ids {
+		if contractID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// putEvidenceSnapshot stores the storage responsibility along with its merkle roots, before the
+// responsibility is removed for the missed storage proof
+func putEvidenceSnapshot(db ethdb.Database, so StorageResponsibility) error {
+	scdb := ethdb.StorageContractDB{db}
+	data, err := rlp.EncodeToBytes(so)
+	if err != nil {
+		return err
+	}
+	return scdb.StoreWithPrefix(so.id(), data, prefixEvidenceSnapshot)
+}
+
+// getEvidenceSnapshot retrieves the storage responsibility snapshot saved for the dispute evidence
+func getEvidenceSnapshot(db ethdb.Database, storageContractID common.Hash) (StorageResponsibility, error) {
+	scdb := ethdb.StorageContractDB{db}
+	valueBytes, err := scdb.GetWithPrefix(storageContractID, prefixEvidenceSnapshot)
+	if err != nil {
+		return StorageResponsibility{}, err
+	}
+	var so StorageResponsibility
+	if err = rlp.DecodeBytes(valueBytes, &so); err != nil {
+		return StorageResponsibility{}, err
+	}
+	if so.id() != storageContractID {
+		return StorageResponsibility{}, errors.New("evidence snapshot does not match the contract")
+	}
+	return so, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/core/vm"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/rlp"
+)
+
+func TestEvidenceSnapshot(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	so := StorageResponsibility{
+		SectorRoots: []common.Hash{common.HexToHash("0x1"), common.HexToHash("0x2")},
+		OriginStorageContract: types.StorageContract{
+			WindowStart: 100,
+			WindowEnd:   200,
+		},
+		ResponsibilityStatus: responsibilityFailed,
+	}
+	if err := putEvidenceSnapshot(db, so); err != nil {
+		t.Fatal(err)
+	}
+	got, err := getEvidenceSnapshot(db, so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.SectorRoots, so.SectorRoots) || got.ResponsibilityStatus != responsibilityFailed {
+		t.Fatalf("evidence snapshot not expected. Got %+v", got)
+	}
+	if _, err := getEvidenceSnapshot(db, common.HexToHash("0x3")); err == nil {
+		t.Fatal("getting the snapshot of unknown contract should return error")
+	}
+}
+
+func TestDisputeEvidence_Hash(t *testing.T) {
+	evidence := DisputeEvidence{
+		ContractID:  common.HexToHash("0x1"),
+		MerkleRoots: []common.Hash{common.HexToHash("0x2")},
+	}
+	hash, err := evidence.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the signature should not affect the hash
+	evidence.Signature = []byte{1, 2, 3}
+	if signed, _ := evidence.Hash(); signed != hash {
+		t.Fatalf("hash changed after signing. Expect %v, Got %v", hash, signed)
+	}
+	evidence.MerkleRoots = nil
+	if changed, _ := evidence.Hash(); changed == hash {
+		t.Fatal("hash should change with the evidence content")
+	}
+}
+
+func TestContractIDsOfTx(t *testing.T) {
+	id := common.HexToHash("0x1")
+	scr := types.StorageContractRevision{ParentID: id}
+	data, err := rlp.EncodeToBytes(scr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := contractIDsOfTx(vm.CommitRevisionTransaction, data); len(got) != 1 || got[0] != id {
+		t.Fatalf("contract id not expected. Expect %v, Got %v", id, got)
+	}
+	if got := contractIDsOfTx(vm.StorageProofTransaction, []byte{0x1}); got != nil {
+		t.Fatalf("invalid data should return no ids, Got %v", got)
+	}
+
+	other := common.HexToHash("0x2")
+	batch := types.StorageProofBatch{Proofs: []types.StorageProof{{ParentID: other}, {ParentID: id}}}
+	if data, err = rlp.EncodeToBytes(batch); err != nil {
+		t.Fatal(err)
+	}
+	ids := contractIDsOfTx(vm.BatchStorageProofTransaction, data)
+	if !containsContractID(ids, id) || !containsContractID(ids, other) {
+		t.Fatalf("contract ids of the batch not expected, Got %v", ids)
+	}
+	if containsContractID(ids, common.HexToHash("0x3")) {
+		t.Fatal("the contract not in the batch shall not be found")
+	}
+}
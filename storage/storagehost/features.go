@@ -0,0 +1,50 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"errors"
+
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// errBatchUploadNotEnabled is returned if more than one sector is uploaded in a request while
+// the batch upload is not active on the storage host
+var errBatchUploadNotEnabled = errors.New("batch upload is not enabled on the host")
+
+// featureFlags returns the feature flags of the storage host
+//
+// NOTE: h.lock should be held when calling this function
+func (h *StorageHost) featureFlags() storage.FeatureFlags {
+	return configFeatureFlags(h.config)
+}
+
+// configFeatureFlags returns the feature flags of the host config. The default feature flags
+// are used for the config persisted before the feature flags were introduced
+func configFeatureFlags(config storage.HostIntConfig) storage.FeatureFlags {
+	if config.FeatureFlags == nil {
+		return storage.DefaultFeatureFlags()
+	}
+	return config.FeatureFlags
+}
+
+// featureEnabled returns whether the feature is active on the storage host at the current
+// block height
+//
+// NOTE: h.lock should be held when calling this function
+func (h *StorageHost) featureEnabled(feature storage.Feature) bool {
+	return h.featureFlags().Enabled(feature, h.blockHeight)
+}
+
+// proofBatchSize returns the maximum number of storage proofs submitted in one tx. The storage
+// proofs are submitted one by one if the batch proof is not active
+//
+// NOTE: h.lock should be held when calling this function
+func (h *StorageHost) proofBatchSize() int {
+	if !h.featureEnabled(storage.FeatureBatchProof) {
+		return 1
+	}
+	return storageProofBatchSize
+}
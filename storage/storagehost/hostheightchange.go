@@ -45,9 +45,26 @@ func (h *StorageHost) hostBlockHeightChange(cce core.ChainChangeEvent) {
 		h.handleTaskItem(taskItems[i])
 	}
 
+	// submit the storage proofs built by the task items in batches
+	h.submitStorageProofs()
+
+	// bump the fee of the revision txs not mined in time
+	h.bumpPendingTxs()
+
+	// resume accepting the uploads once the maintenance ends
+	h.lock.Lock()
+	h.updateMaintenance()
+	h.lock.Unlock()
+
+	// prune the settled storage responsibilities beyond the retention
+	h.updateResponsibilityPruning()
+
 	// update the contractToClientID
 	h.UpdateContractToClientNodeMappingAndConnection()
 
+	// the advertised host record follows the remaining storage and the maintenance
+	h.advertiseHostRecord()
+
 	// sync the configuration
 	err := h.syncConfig()
 	if err != nil {
@@ -119,6 +136,8 @@ func (h *StorageHost) applyBlockHashesStorageResponsibility(blocks []common.Hash
 				h.log.Error("Failed to put storage responsibility", "err", errPut)
 				continue
 			}
+			//The payout is not final until the proof tx gets enough confirmations
+			h.recordProofPayout(so, blockApply, number)
 		}
 
 		if number != 0 {
@@ -198,12 +217,18 @@ func (h *StorageHost) revertedBlockHashesStorageResponsibility(blocks []common.H
 			if errGet != nil {
 				continue
 			}
+			//The payout already counted as final cannot be reverted
+			if so.ResponsibilityStatus != responsibilityUnresolved {
+				continue
+			}
 			so.StorageProofConfirmed = false
 			errPut := putStorageResponsibility(h.db, so.id(), so)
 			if errPut != nil {
 				h.log.Error("Failed to put storage responsibility", "err", errPut)
 				continue
 			}
+			//The proof tx is removed by reorg, send the proof again
+			h.revertProofPayout(so)
 		}
 
 		if number != 0 && h.blockHeight > 1 {
@@ -253,6 +278,16 @@ func (h *StorageHost) getAllStorageContractIDsWithBlockHash(blockHash common.Has
 				continue
 			}
 			storageProofIDs = append(storageProofIDs, sp.ParentID)
+		case vm.BatchStorageProofTransaction:
+			var batch types.StorageProofBatch
+			err := rlp.DecodeBytes(tx.Data(), &batch)
+			if err != nil {
+				h.log.Error("Error when serializing proof batch:", "err", err)
+				continue
+			}
+			for _, sp := range batch.Proofs {
+				storageProofIDs = append(storageProofIDs, sp.ParentID)
+			}
 		default:
 			continue
 		}
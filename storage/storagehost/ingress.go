@@ -0,0 +1,303 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DxChainNetwork/godx/storage/chrono"
+)
+
+// The ingress policy is enforced on each storage request from the peers before the negotiation
+// begins, so that the scraping and the flooding of the storage protocol are cut off before any
+// resource is spent on them. The requests from the addresses in the deny-list, or out of the
+// allow-list if it is not empty, are rejected. The requests from each IP are rate limited with
+// a token bucket, and the number of concurrent sessions is capped both in total and per IP.
+// The zero policy imposes no limit.
+
+var (
+	// errIngressDenied is returned if the remote address is denied by the CIDR lists
+	errIngressDenied = errors.New("the remote address is denied by the host ingress policy")
+
+	// errIngressRateLimited is returned if the remote address sent too many requests recently
+	errIngressRateLimited = errors.New("too many storage requests from the remote address")
+
+	// errIngressTooManySessions is returned if the concurrent sessions reached the cap
+	errIngressTooManySessions = errors.New("too many concurrent storage sessions")
+)
+
+type (
+	// IngressPolicy is the connection policy of the host on the storage protocol. The CIDR
+	// lists also take the plain IP addresses. The RateLimit is the number of requests allowed
+	// per IP per minute, with the bursts of up to RateBurst requests. The zero limits and caps
+	// are not enforced
+	IngressPolicy struct {
+		AllowCIDRs       []string `json:"allowCIDRs"`
+		DenyCIDRs        []string `json:"denyCIDRs"`
+		RateLimit        float64  `json:"rateLimit"`
+		RateBurst        int      `json:"rateBurst"`
+		MaxSessions      int      `json:"maxSessions"`
+		MaxSessionsPerIP int      `json:"maxSessionsPerIP"`
+	}
+
+	// IngressStatus is the ingress policy along with the sessions in progress and the number
+	// of requests rejected since the host started
+	IngressStatus struct {
+		Policy        IngressPolicy `json:"policy"`
+		Sessions      int           `json:"sessions"`
+		Denied        uint64        `json:"denied"`
+		RateLimited   uint64        `json:"rateLimited"`
+		SessionCapped uint64        `json:"sessionCapped"`
+	}
+
+	// ingressGuard enforces the ingress policy, and keeps track of the sessions and the
+	// request rate of each IP
+	ingressGuard struct {
+		policy      IngressPolicy
+		allow, deny []*net.IPNet
+
+		buckets    map[string]*ingressBucket
+		sessions   int
+		ipSessions map[string]int
+
+		denied, rateLimited, sessionCapped uint64
+
+		clock chrono.Clock
+		lock  sync.Mutex
+	}
+
+	// ingressBucket is the token bucket of an IP, refilled at the rate limit
+	ingressBucket struct {
+		tokens float64
+		last   time.Time
+	}
+)
+
+// newIngressGuard creates the ingress guard with the zero policy
+func newIngressGuard(clock chrono.Clock) *ingressGuard {
+	return &ingressGuard{
+		buckets:    make(map[string]*ingressBucket),
+		ipSessions: make(map[string]int),
+		clock:      clock,
+	}
+}
+
+// AdmitSession checks the storage request from the remote address against the ingress policy.
+// If admitted, the session is counted until the release function returned is called
+func (h *StorageHost) AdmitSession(addr net.Addr) (release func(), err error) {
+	return h.ingress.admit(remoteIP(addr))
+}
+
+// setIngressPolicy validates and applies the ingress policy, and saves it with the host config
+func (h *StorageHost) setIngressPolicy(policy IngressPolicy) error {
+	if err := h.ingress.setPolicy(policy); err != nil {
+		return err
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.syncConfig()
+}
+
+// getIngressStatus returns the ingress policy, with the sessions and the rejected requests
+func (h *StorageHost) getIngressStatus() IngressStatus {
+	return h.ingress.status()
+}
+
+// setPolicy applies the policy, which fails if any of the CIDRs is invalid. The rate limit
+// buckets are reset
+func (g *ingressGuard) setPolicy(policy IngressPolicy) error {
+	if policy.RateLimit < 0 || policy.RateBurst < 0 || policy.MaxSessions < 0 || policy.MaxSessionsPerIP < 0 {
+		return errors.New("the limits of the ingress policy cannot be negative")
+	}
+	allow, err := parseCIDRs(policy.AllowCIDRs)
+	if err != nil {
+		return err
+	}
+	deny, err := parseCIDRs(policy.DenyCIDRs)
+	if err != nil {
+		return err
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.policy, g.allow, g.deny = policy, allow, deny
+	g.buckets = make(map[string]*ingressBucket)
+	return nil
+}
+
+// getPolicy returns the policy enforced, the zero policy if the guard is not created
+func (g *ingressGuard) getPolicy() IngressPolicy {
+	if g == nil {
+		return IngressPolicy{}
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.policy
+}
+
+// status returns the status of the ingress guard
+func (g *ingressGuard) status() IngressStatus {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return IngressStatus{
+		Policy:        g.policy,
+		Sessions:      g.sessions,
+		Denied:        g.denied,
+		RateLimited:   g.rateLimited,
+		SessionCapped: g.sessionCapped,
+	}
+}
+
+// admit checks the request from the ip against the policy. The request rejected by the
+// session caps does not consume the token of the rate limit
+func (g *ingressGuard) admit(ip net.IP) (func(), error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if !g.ipAllowed(ip) {
+		g.denied++
+		return nil, errIngressDenied
+	}
+	key := ip.String()
+	if (g.policy.MaxSessions > 0 && g.sessions >= g.policy.MaxSessions) ||
+		(g.policy.MaxSessionsPerIP > 0 && g.ipSessions[key] >= g.policy.MaxSessionsPerIP) {
+		g.sessionCapped++
+		return nil, errIngressTooManySessions
+	}
+	if !g.takeToken(key) {
+		g.rateLimited++
+		return nil, errIngressRateLimited
+	}
+
+	g.sessions++
+	g.ipSessions[key]++
+	var once sync.Once
+	return func() {
+		once.Do(func() { g.release(key) })
+	}, nil
+}
+
+// release ends the session of the ip
+func (g *ingressGuard) release(key string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.sessions--
+	if g.ipSessions[key]--; g.ipSessions[key] <= 0 {
+		delete(g.ipSessions, key)
+	}
+}
+
+// ipAllowed checks the ip against the CIDR lists. The unknown address is only allowed if the
+// allow-list is empty
+func (g *ingressGuard) ipAllowed(ip net.IP) bool {
+	if ip == nil {
+		return len(g.allow) == 0
+	}
+	if containsIP(g.deny, ip) {
+		return false
+	}
+	return len(g.allow) == 0 || containsIP(g.allow, ip)
+}
+
+// takeToken takes a token from the bucket of the ip, refilled at the rate limit since it was
+// last taken. The buckets refilled completely are dropped once there are too many of them
+func (g *ingressGuard) takeToken(key string) bool {
+	if g.policy.RateLimit <= 0 {
+		return true
+	}
+	burst := float64(g.policy.RateBurst)
+	if burst < 1 {
+		burst = 1
+	}
+	now := g.clock.Now()
+	if len(g.buckets) >= maxIngressBuckets {
+		for k, b := range g.buckets {
+			if b.refill(now, g.policy.RateLimit, burst) >= burst {
+				delete(g.buckets, k)
+			}
+		}
+	}
+
+	bucket, exists := g.buckets[key]
+	if !exists {
+		bucket = &ingressBucket{tokens: burst, last: now}
+		g.buckets[key] = bucket
+	}
+	if bucket.refill(now, g.policy.RateLimit, burst) < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// refill refills the bucket at the rate per minute up to the burst, and returns the tokens
+func (b *ingressBucket) refill(now time.Time, rate, burst float64) float64 {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Minutes() * rate
+		b.last = now
+	}
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	return b.tokens
+}
+
+// parseCIDRs parses the CIDRs, with the plain IP address taken as the network of itself
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %v", cidr)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %v: %v", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// containsIP checks whether the ip is in any of the networks
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the IP of the remote address, nil if unknown
+func remoteIP(addr net.Addr) net.IP {
+	switch addr := addr.(type) {
+	case nil:
+		return nil
+	case *net.TCPAddr:
+		return addr.IP
+	case *net.UDPAddr:
+		return addr.IP
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		host = addr.String()
+	}
+	return net.ParseIP(host)
+}
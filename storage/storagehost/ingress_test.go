@@ -0,0 +1,147 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/storage/chrono"
+)
+
+func TestIngressGuard_CIDR(t *testing.T) {
+	g := newIngressGuard(chrono.System)
+	if err := g.setPolicy(IngressPolicy{AllowCIDRs: []string{"10.0.0.0/8"}, DenyCIDRs: []string{"10.1.0.0/16", "10.2.3.4"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		ip  string
+		err error
+	}{
+		{"10.0.0.1", nil},
+		{"10.2.3.5", nil},
+		{"10.1.2.3", errIngressDenied},
+		{"10.2.3.4", errIngressDenied},
+		{"192.168.0.1", errIngressDenied},
+		{"", errIngressDenied},
+	} {
+		release, err := g.admit(net.ParseIP(test.ip))
+		if err != test.err {
+			t.Errorf("ip %v: expect error %v, got %v", test.ip, test.err, err)
+		}
+		if release != nil {
+			release()
+		}
+	}
+	if status := g.status(); status.Denied != 4 || status.Sessions != 0 {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	if err := g.setPolicy(IngressPolicy{DenyCIDRs: []string{"10.0.0.0/33"}}); err == nil {
+		t.Error("expect error for the invalid CIDR")
+	}
+}
+
+func TestIngressGuard_RateLimit(t *testing.T) {
+	clock := chrono.NewFakeClock(time.Unix(0, 0))
+	g := newIngressGuard(clock)
+	if err := g.setPolicy(IngressPolicy{RateLimit: 6, RateBurst: 2}); err != nil {
+		t.Fatal(err)
+	}
+	ip, other := net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")
+
+	// the burst is allowed at once, and the bucket is refilled at 6 requests per minute
+	for i, expected := range []error{nil, nil, errIngressRateLimited} {
+		release, err := g.admit(ip)
+		if err != expected {
+			t.Fatalf("request %v: expect error %v, got %v", i, expected, err)
+		}
+		if release != nil {
+			release()
+		}
+	}
+	if _, err := g.admit(other); err != nil {
+		t.Fatalf("the requests of the other ip shall not be limited: %v", err)
+	}
+	clock.Advance(10 * time.Second)
+	if _, err := g.admit(ip); err != nil {
+		t.Fatalf("expect the token refilled, got %v", err)
+	}
+	if _, err := g.admit(ip); err != errIngressRateLimited {
+		t.Fatalf("expect error %v, got %v", errIngressRateLimited, err)
+	}
+}
+
+func TestIngressGuard_Sessions(t *testing.T) {
+	g := newIngressGuard(chrono.System)
+	if err := g.setPolicy(IngressPolicy{MaxSessions: 3, MaxSessionsPerIP: 2}); err != nil {
+		t.Fatal(err)
+	}
+	ip := net.ParseIP("10.0.0.1")
+	release1, err := g.admit(ip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = g.admit(ip); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = g.admit(ip); err != errIngressTooManySessions {
+		t.Fatalf("expect the sessions per ip capped, got %v", err)
+	}
+	if _, err = g.admit(net.ParseIP("10.0.0.2")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = g.admit(net.ParseIP("10.0.0.3")); err != errIngressTooManySessions {
+		t.Fatalf("expect the sessions capped, got %v", err)
+	}
+
+	// the session released twice is only counted once
+	release1()
+	release1()
+	if status := g.status(); status.Sessions != 2 || status.SessionCapped != 2 {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if _, err = g.admit(ip); err != nil {
+		t.Fatalf("expect the session admitted once released, got %v", err)
+	}
+}
+
+func TestStorageHost_IngressPolicyPersist(t *testing.T) {
+	dir := tempDir(t.Name())
+	h := &StorageHost{persistDir: dir, ingress: newIngressGuard(chrono.System)}
+	api := NewHostPrivateAPI(h)
+	_, err := api.SetIngressPolicy(map[string]string{
+		"allow":       "10.0.0.0/8, 192.168.1.1",
+		"ratelimit":   "30",
+		"burst":       "5",
+		"maxSessions": "100",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.SetIngressPolicy(map[string]string{"burst": "-1"}); err == nil {
+		t.Error("expect error for the negative burst")
+	}
+	expected := IngressPolicy{
+		AllowCIDRs:  []string{"10.0.0.0/8", "192.168.1.1"},
+		RateLimit:   30,
+		RateBurst:   5,
+		MaxSessions: 100,
+	}
+
+	loaded := &StorageHost{persistDir: dir, log: log.New(), ingress: newIngressGuard(chrono.System)}
+	if err := loaded.loadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if policy := loaded.ingress.getPolicy(); !reflect.DeepEqual(policy, expected) {
+		t.Fatalf("persisted ingress policy %+v, expected %+v", policy, expected)
+	}
+	if _, err := loaded.AdmitSession(&net.TCPAddr{IP: net.ParseIP("172.16.0.1"), Port: 36000}); err != errIngressDenied {
+		t.Errorf("expect error %v, got %v", errIngressDenied, err)
+	}
+}
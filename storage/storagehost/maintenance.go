@@ -0,0 +1,164 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/DxChainNetwork/godx/common"
+)
+
+// The host maintenance window is a range of blocks within which the host is expected to be
+// down. The uploads and the new contracts are rejected during the window, and accepted
+// again automatically once the block height reaches the end of the window. The storage
+// responsibilities whose proof window overlaps the maintenance window are reported when the
+// maintenance is scheduled, since the storage proof may not be submitted in time.
+
+var (
+	// errHostInMaintenance is returned if the upload is requested during the maintenance
+	errHostInMaintenance = errors.New("host is in maintenance and not accepting uploads")
+
+	// errEmptyMaintenance is returned if the maintenance is scheduled with zero duration
+	errEmptyMaintenance = errors.New("maintenance duration cannot be zero")
+)
+
+type (
+	// maintenanceWindow is the persisted maintenance window, with the end block excluded.
+	// The zero value means no maintenance is scheduled
+	maintenanceWindow struct {
+		Start uint64 `json:"start"`
+		End   uint64 `json:"end"`
+	}
+
+	// MaintenanceSchedule is the maintenance window scheduled, along with the storage
+	// responsibilities whose proof window conflicts with the maintenance
+	MaintenanceSchedule struct {
+		Start       uint64                `json:"start"`
+		End         uint64                `json:"end"`
+		BlockHeight uint64                `json:"blockHeight"`
+		Active      bool                  `json:"active"`
+		Conflicts   []MaintenanceConflict `json:"conflicts"`
+	}
+
+	// MaintenanceConflict is a storage responsibility whose proof window overlaps the
+	// maintenance window
+	MaintenanceConflict struct {
+		ContractID    common.Hash `json:"contractID"`
+		WindowStart   uint64      `json:"windowStart"`
+		ProofDeadline uint64      `json:"proofDeadline"`
+	}
+)
+
+// scheduleMaintenance schedules the maintenance from the start block height for the number of
+// blocks of duration, which replaces the maintenance scheduled before. The storage
+// responsibilities with proof window conflicting with the maintenance are logged and returned
+func (h *StorageHost) scheduleMaintenance(start, duration uint64) (MaintenanceSchedule, error) {
+	if duration == 0 {
+		return MaintenanceSchedule{}, errEmptyMaintenance
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if start < h.blockHeight {
+		start = h.blockHeight
+	}
+	h.maintenance = maintenanceWindow{Start: start, End: start + duration}
+	if err := h.syncConfig(); err != nil {
+		return MaintenanceSchedule{}, err
+	}
+	schedule := h.maintenanceSchedule()
+	for _, conflict := range schedule.Conflicts {
+		h.log.Warn("Maintenance conflicts with the storage proof window", "id", conflict.ContractID,
+			"windowStart", conflict.WindowStart, "deadline", conflict.ProofDeadline,
+			"start", schedule.Start, "end", schedule.End)
+	}
+	h.log.Info("Host maintenance scheduled", "start", schedule.Start, "end", schedule.End,
+		"conflicts", len(schedule.Conflicts))
+	return schedule, nil
+}
+
+// cancelMaintenance cancels the maintenance scheduled, and the uploads are accepted at once
+func (h *StorageHost) cancelMaintenance() error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.maintenance == (maintenanceWindow{}) {
+		return nil
+	}
+	h.maintenance = maintenanceWindow{}
+	return h.syncConfig()
+}
+
+// getMaintenanceSchedule returns the maintenance scheduled, along with the conflicts
+func (h *StorageHost) getMaintenanceSchedule() MaintenanceSchedule {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.maintenanceSchedule()
+}
+
+// inMaintenance returns whether the host is in the maintenance window
+func (h *StorageHost) inMaintenance() bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.maintenance.contains(h.blockHeight)
+}
+
+// updateMaintenance clears the maintenance window once it ends, so that the host resumes
+// accepting the uploads. It is called on block height change, and the config is synced by
+// the caller
+//
+// NOTE: h.lock should be held when calling this function
+func (h *StorageHost) updateMaintenance() {
+	window := h.maintenance
+	if window == (maintenanceWindow{}) || h.blockHeight < window.End {
+		return
+	}
+	h.maintenance = maintenanceWindow{}
+	h.log.Info("Host maintenance ended, resume accepting uploads", "start", window.Start, "end", window.End)
+}
+
+// maintenanceSchedule returns the maintenance scheduled, along with the storage
+// responsibilities with proof window conflicting with the maintenance
+//
+// NOTE: h.lock should be held when calling this function
+func (h *StorageHost) maintenanceSchedule() MaintenanceSchedule {
+	window := h.maintenance
+	schedule := MaintenanceSchedule{
+		BlockHeight: h.blockHeight,
+		Conflicts:   []MaintenanceConflict{},
+	}
+	if window == (maintenanceWindow{}) {
+		return schedule
+	}
+	schedule.Start, schedule.End = window.Start, window.End
+	schedule.Active = window.contains(h.blockHeight)
+
+	for _, so := range h.storageResponsibilities() {
+		if so.StorageProofConfirmed || !window.overlaps(so.expiration(), so.proofDeadline()) {
+			continue
+		}
+		schedule.Conflicts = append(schedule.Conflicts, MaintenanceConflict{
+			ContractID:    so.id(),
+			WindowStart:   so.expiration(),
+			ProofDeadline: so.proofDeadline(),
+		})
+	}
+	sort.Slice(schedule.Conflicts, func(i, j int) bool {
+		return schedule.Conflicts[i].ProofDeadline < schedule.Conflicts[j].ProofDeadline
+	})
+	return schedule
+}
+
+// contains returns whether the block height is within the maintenance window
+func (mw maintenanceWindow) contains(height uint64) bool {
+	return height >= mw.Start && height < mw.End
+}
+
+// overlaps returns whether the proof window from the window start to the proof deadline,
+// both included, overlaps the maintenance window
+func (mw maintenanceWindow) overlaps(windowStart, proofDeadline uint64) bool {
+	return windowStart < mw.End && proofDeadline >= mw.Start
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/log"
+)
+
+func TestStorageHost_ScheduleMaintenance(t *testing.T) {
+	dir := tempDir(t.Name())
+	db, err := ethdb.NewLDBDatabase(filepath.Join(dir, "db"), 16, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	h := &StorageHost{
+		db:                          db,
+		log:                         log.New(),
+		persistDir:                  dir,
+		blockHeight:                 100,
+		lockedStorageResponsibility: make(map[common.Hash]*TryMutex),
+	}
+	windows := [][2]uint64{{90, 110}, {150, 160}, {200, 220}, {100, 130}}
+	for i, window := range windows {
+		so := StorageResponsibility{
+			OriginStorageContract: types.StorageContract{
+				WindowStart: window[0],
+				WindowEnd:   window[1],
+			},
+			StorageProofConfirmed: i == 3,
+		}
+		if err := putStorageResponsibility(db, so.id(), so); err != nil {
+			t.Fatal(err)
+		}
+		h.lockedStorageResponsibility[so.id()] = new(TryMutex)
+	}
+
+	if _, err := h.scheduleMaintenance(120, 0); err != errEmptyMaintenance {
+		t.Fatalf("expect error %v, got %v", errEmptyMaintenance, err)
+	}
+	schedule, err := h.scheduleMaintenance(120, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.Start != 120 || schedule.End != 160 || schedule.Active {
+		t.Fatalf("unexpected schedule %+v", schedule)
+	}
+	// only the unconfirmed proof window from 150 to 160 overlaps the maintenance
+	if len(schedule.Conflicts) != 1 || schedule.Conflicts[0].WindowStart != 150 {
+		t.Fatalf("unexpected conflicts %+v", schedule.Conflicts)
+	}
+
+	// the maintenance window is persisted along with the config
+	loaded := &StorageHost{persistDir: dir}
+	if err := loaded.loadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.maintenance != h.maintenance {
+		t.Fatalf("persisted maintenance %+v, expected %+v", loaded.maintenance, h.maintenance)
+	}
+
+	// the uploads are paused within the maintenance window, and resumed once it ends
+	for _, test := range []struct {
+		height   uint64
+		paused   bool
+		schedule bool
+	}{
+		{119, false, true},
+		{120, true, true},
+		{159, true, true},
+		{160, false, false},
+	} {
+		h.blockHeight = test.height
+		h.updateMaintenance()
+		if paused := h.inMaintenance(); paused != test.paused {
+			t.Errorf("height %v: paused %v, expected %v", test.height, paused, test.paused)
+		}
+		if scheduled := h.getMaintenanceSchedule().End != 0; scheduled != test.schedule {
+			t.Errorf("height %v: scheduled %v, expected %v", test.height, scheduled, test.schedule)
+		}
+	}
+
+	// the maintenance scheduled in the past starts at the current block height
+	if schedule, err = h.scheduleMaintenance(10, 5); err != nil {
+		t.Fatal(err)
+	}
+	if schedule.Start != 160 || !schedule.Active {
+		t.Fatalf("unexpected schedule %+v", schedule)
+	}
+	if err = h.cancelMaintenance(); err != nil {
+		t.Fatal(err)
+	}
+	if h.inMaintenance() {
+		t.Fatalf("the maintenance shall be cancelled")
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/rlp"
+)
+
+// The valid proof payout is counted as revenue only after the storage proof tx gets enough
+// confirmations. If a reorg removes the block containing the proof tx before that, the payout
+// record is dropped and the proof is sent again as long as the proof window is still open.
+
+type (
+	// proofPayout is the record of the block the storage proof tx is mined in
+	proofPayout struct {
+		BlockHash   common.Hash
+		BlockHeight uint64
+	}
+
+	// PendingPayout is the valid proof payout whose storage proof tx has not got enough
+	// confirmations yet
+	PendingPayout struct {
+		ContractID    common.Hash   `json:"contractID"`
+		BlockHash     common.Hash   `json:"blockHash"`
+		BlockHeight   uint64        `json:"blockHeight"`
+		Confirmations uint64        `json:"confirmations"`
+		Revenue       common.BigInt `json:"revenue"`
+	}
+)
+
+// putProofPayout stores the proof payout of the storage responsibility
+func putProofPayout(db ethdb.Database, storageContractID common.Hash, pp proofPayout) error {
+	scdb := ethdb.StorageContractDB{db}
+	data, err := rlp.EncodeToBytes(pp)
+	if err != nil {
+		return err
+	}
+	return scdb.StoreWithPrefix(storageContractID, data, prefixProofPayout)
+}
+
+// getProofPayout retrieves the proof payout of the storage responsibility
+func getProofPayout(db ethdb.Database, storageContractID common.Hash) (proofPayout, error) {
+	scdb := ethdb.StorageContractDB{db}
+	valueBytes, err := scdb.GetWithPrefix(storageContractID, prefixProofPayout)
+	if err != nil {
+		return proofPayout{}, err
+	}
+	var pp proofPayout
+	if err = rlp.DecodeBytes(valueBytes, &pp); err != nil {
+		return proofPayout{}, err
+	}
+	return pp, nil
+}
+
+// deleteProofPayout deletes the proof payout of the storage responsibility
+func deleteProofPayout(db ethdb.Database, storageContractID common.Hash) error {
+	scdb := ethdb.StorageContractDB{db}
+	return scdb.DeleteWithPrefix(storageContractID, prefixProofPayout)
+}
+
+// recordProofPayout records the block the storage proof tx of the storage responsibility is
+// mined in
+func (h *StorageHost) recordProofPayout(so StorageResponsibility, blockHash common.Hash, number uint64) {
+	pp := proofPayout{
+		BlockHash:   blockHash,
+		BlockHeight: number,
+	}
+	if err := putProofPayout(h.db, so.id(), pp); err != nil {
+		h.log.Warn("Failed to save the proof payout", "id", so.id(), "err", err)
+	}
+	// the proof tx is mined, no need to monitor the submission any more
+	if err := deleteProofSubmission(h.db, so.id()); err != nil {
+		h.log.Warn("Failed to delete the proof submission", "id", so.id(), "err", err)
+	}
+}
+
+// revertProofPayout handles the storage proof tx removed from the canonical chain by a reorg.
+// The payout record is dropped, and the task is queued to send the proof again
+func (h *StorageHost) revertProofPayout(so StorageResponsibility) {
+	h.log.Warn("Storage proof tx reverted by reorg, the proof will be resubmitted", "id", so.id(), "deadline", so.proofDeadline())
+	if err := deleteProofPayout(h.db, so.id()); err != nil {
+		h.log.Warn("Failed to delete the proof payout", "id", so.id(), "err", err)
+	}
+	if err := deleteProofSubmission(h.db, so.id()); err != nil {
+		h.log.Warn("Failed to delete the proof submission", "id", so.id(), "err", err)
+	}
+	if err := h.queueTaskItem(h.blockHeight+postponedExecution, so.id()); err != nil {
+		h.log.Warn("Error queuing task item", "err", err)
+	}
+}
+
+// proofPayoutFinality checks if the storage proof tx of the storage responsibility has got
+// enough confirmations, and returns the block height at which the payout becomes final. The
+// proof confirmed before the payout record was introduced is regarded as final
+func (h *StorageHost) proofPayoutFinality(id common.Hash) (finalHeight uint64, final bool) {
+	pp, err := getProofPayout(h.db, id)
+	if err != nil {
+		return h.blockHeight, true
+	}
+	finalHeight = pp.BlockHeight + payoutConfirmations - 1
+	return finalHeight, h.blockHeight >= finalHeight
+}
+
+// pendingPayouts returns the valid proof payouts not final yet
+func (h *StorageHost) pendingPayouts() (pps []PendingPayout) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	for _, so := range h.storageResponsibilities() {
+		if !so.StorageProofConfirmed {
+			continue
+		}
+		pp, err := getProofPayout(h.db, so.id())
+		if err != nil {
+			continue
+		}
+		var confirmations uint64
+		if h.blockHeight >= pp.BlockHeight {
+			confirmations = h.blockHeight - pp.BlockHeight + 1
+		}
+		pps = append(pps, PendingPayout{
+			ContractID:    so.id(),
+			BlockHash:     pp.BlockHash,
+			BlockHeight:   pp.BlockHeight,
+			Confirmations: confirmations,
+			Revenue:       so.ContractCost.Add(so.PotentialStorageRevenue).Add(so.PotentialDownloadRevenue).Add(so.PotentialUploadRevenue),
+		})
+	}
+	return
+}
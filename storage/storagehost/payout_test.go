@@ -0,0 +1,69 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/log"
+)
+
+func TestStorageHost_ProofPayout(t *testing.T) {
+	db, err := ethdb.NewLDBDatabase(filepath.Join(tempDir(t.Name()), "db"), 16, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	h := &StorageHost{db: db, log: log.New(), blockHeight: 150}
+	so := StorageResponsibility{
+		OriginStorageContract: types.StorageContract{
+			WindowStart: 100,
+			WindowEnd:   200,
+		},
+	}
+	// the proof confirmed before the payout record is regarded as final
+	if _, final := h.proofPayoutFinality(so.id()); !final {
+		t.Fatalf("the proof without payout record shall be final")
+	}
+
+	h.recordProofSubmission(so, common.HexToHash("0x1"))
+	h.recordProofPayout(so, common.HexToHash("0x2"), 151)
+	if _, err := getProofSubmission(db, so.id()); err == nil {
+		t.Fatalf("the proof submission shall be deleted once the proof tx is mined")
+	}
+	pp, err := getProofPayout(db, so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pp.BlockHash != common.HexToHash("0x2") || pp.BlockHeight != 151 {
+		t.Fatalf("proof payout not expected. Got %+v", pp)
+	}
+
+	// the payout is final only after enough confirmations
+	h.blockHeight = 151
+	finalHeight, final := h.proofPayoutFinality(so.id())
+	if final || finalHeight != 151+payoutConfirmations-1 {
+		t.Fatalf("finality not expected. Got final %v at %v", final, finalHeight)
+	}
+	h.blockHeight = finalHeight
+	if _, final := h.proofPayoutFinality(so.id()); !final {
+		t.Fatalf("the payout shall be final with enough confirmations")
+	}
+
+	// the reverted payout is dropped, and the proof is scheduled to be sent again
+	h.blockHeight = 155
+	h.revertProofPayout(so)
+	if _, err := getProofPayout(db, so.id()); err == nil {
+		t.Fatalf("the proof payout shall be deleted after the revert")
+	}
+	if _, err := getHeight(db, 155+postponedExecution); err != nil {
+		t.Fatalf("the proof resubmission task shall be queued: %v", err)
+	}
+}
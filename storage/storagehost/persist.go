@@ -16,6 +16,8 @@ type persistence struct {
 	FinancialMetrics HostFinancialMetrics   `json:"financialmetrics"`
 	Config           storage.HostIntConfig  `json:"config"`
 	Contracts        map[string]common.Hash `json:"contracts"`
+	Maintenance      maintenanceWindow      `json:"maintenance"`
+	Ingress          IngressPolicy          `json:"ingress"`
 }
 
 // save the host config: the filed as persistence shown, to the json file
@@ -49,6 +51,8 @@ func (h *StorageHost) extractPersistence() *persistence {
 		FinancialMetrics: h.financialMetrics,
 		Config:           h.config,
 		Contracts:        h.clientToContract,
+		Maintenance:      h.maintenance,
+		Ingress:          h.ingress.getPolicy(),
 	}
 }
 
@@ -59,4 +63,10 @@ func (h *StorageHost) loadPersistence(persist *persistence) {
 	h.financialMetrics = persist.FinancialMetrics
 	h.config = persist.Config
 	h.clientToContract = persist.Contracts
+	h.maintenance = persist.Maintenance
+	if h.ingress != nil {
+		if err := h.ingress.setPolicy(persist.Ingress); err != nil {
+			h.log.Warn("failed to apply the ingress policy loaded", "err", err)
+		}
+	}
 }
@@ -0,0 +1,70 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"errors"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+var errInvalidStoragePriceBounds = errors.New("minStoragePrice cannot be larger than maxStoragePrice")
+
+// dynamicStoragePrice returns the storage price to be advertised to the storage clients. If
+// dynamic pricing is enabled, the storage price is raised towards MaxStoragePrice once the
+// ratio of the free storage falls below scarceCapacityThreshold, and is lowered towards
+// MinStoragePrice once the ratio exceeds plentifulCapacityThreshold
+func dynamicStoragePrice(config storage.HostIntConfig, remaining, total uint64) common.BigInt {
+	base := config.StoragePrice
+	if !config.DynamicPricing || total == 0 {
+		return base
+	}
+	minPrice, maxPrice := storagePriceBounds(config)
+	free := float64(remaining) / float64(total)
+
+	price := base
+	switch {
+	case free < scarceCapacityThreshold:
+		scarcity := (scarceCapacityThreshold - free) / scarceCapacityThreshold
+		price = base.Add(maxPrice.Sub(base).MultFloat64(scarcity))
+	case free > plentifulCapacityThreshold:
+		plenty := (free - plentifulCapacityThreshold) / (1 - plentifulCapacityThreshold)
+		price = base.Sub(base.Sub(minPrice).MultFloat64(plenty))
+	}
+
+	// the adjusted price must be within the bounds set by the host
+	if price.Cmp(minPrice) < 0 {
+		price = minPrice
+	}
+	if price.Cmp(maxPrice) > 0 {
+		price = maxPrice
+	}
+	return price
+}
+
+// storagePriceBounds returns the lower and upper bound of the dynamic storage price. The
+// bound not set by the host is regarded as the StoragePrice
+func storagePriceBounds(config storage.HostIntConfig) (minPrice, maxPrice common.BigInt) {
+	minPrice, maxPrice = config.MinStoragePrice, config.MaxStoragePrice
+	if minPrice.Sign() == 0 {
+		minPrice = config.StoragePrice
+	}
+	if maxPrice.Sign() == 0 {
+		maxPrice = config.StoragePrice
+	}
+	return
+}
+
+// validateStoragePriceBounds checks whether the dynamic storage price bounds are valid
+func validateStoragePriceBounds(config storage.HostIntConfig) error {
+	if config.MinStoragePrice.Sign() == 0 || config.MaxStoragePrice.Sign() == 0 {
+		return nil
+	}
+	if config.MinStoragePrice.Cmp(config.MaxStoragePrice) > 0 {
+		return errInvalidStoragePriceBounds
+	}
+	return nil
+}
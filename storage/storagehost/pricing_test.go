@@ -0,0 +1,55 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestDynamicStoragePrice(t *testing.T) {
+	config := storage.HostIntConfig{
+		DynamicPricing:  true,
+		StoragePrice:    common.NewBigInt(1000),
+		MinStoragePrice: common.NewBigInt(500),
+		MaxStoragePrice: common.NewBigInt(3000),
+	}
+	tests := []struct {
+		remaining uint64
+		total     uint64
+		expect    common.BigInt
+	}{
+		{50, 100, common.NewBigInt(1000)},
+		{0, 100, common.NewBigInt(3000)},
+		{10, 100, common.NewBigInt(2000)},
+		{100, 100, common.NewBigInt(500)},
+		{0, 0, common.NewBigInt(1000)},
+	}
+	for i, test := range tests {
+		price := dynamicStoragePrice(config, test.remaining, test.total)
+		if !price.IsEqual(test.expect) {
+			t.Errorf("test %d: price not expected. Expect %v, Got %v", i, test.expect, price)
+		}
+	}
+
+	// the price should be lowered gradually when the free capacity is plentiful
+	if price := dynamicStoragePrice(config, 90, 100); price.Cmp(config.MinStoragePrice) <= 0 || price.Cmp(config.StoragePrice) >= 0 {
+		t.Errorf("plentiful price %v not within (%v, %v)", price, config.MinStoragePrice, config.StoragePrice)
+	}
+
+	// static pricing should always return the storage price
+	config.DynamicPricing = false
+	if price := dynamicStoragePrice(config, 0, 100); !price.IsEqual(config.StoragePrice) {
+		t.Errorf("static price not expected. Expect %v, Got %v", config.StoragePrice, price)
+	}
+
+	// the bounds not set should be regarded as the storage price
+	config = storage.HostIntConfig{DynamicPricing: true, StoragePrice: common.NewBigInt(1000)}
+	if price := dynamicStoragePrice(config, 0, 100); !price.IsEqual(config.StoragePrice) {
+		t.Errorf("unbounded price not expected. Expect %v, Got %v", config.StoragePrice, price)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/rlp"
+)
+
+// The storage proofs built while handling the task items of a block are not sent right away.
+// They are queued by the host address paying for the tx, and submitted once all task items are
+// handled, in batch storage proof txs of up to storageProofBatchSize proofs each, or one by one
+// if the batch proof feature is not active. Since all
+// responsibilities in a batch share the same proof tx, the tx bumped for one responsibility
+// is taken as bumped for the others as well.
+
+type (
+	// pendingProof is the signed storage proof waiting to be submitted
+	pendingProof struct {
+		so    StorageResponsibility
+		proof types.StorageProof
+	}
+
+	// proofBatch keeps the storage proofs waiting to be submitted, and the proof txs replaced
+	// by the fee bump in the current round
+	proofBatch struct {
+		pending  map[common.Address][]pendingProof
+		replaced map[common.Hash]common.Hash
+	}
+)
+
+// add queues the storage proof to be submitted by the address
+func (pb *proofBatch) add(from common.Address, so StorageResponsibility, sp types.StorageProof) {
+	if pb.pending == nil {
+		pb.pending = make(map[common.Address][]pendingProof)
+	}
+	pb.pending[from] = append(pb.pending[from], pendingProof{so: so, proof: sp})
+}
+
+// take returns and clears the storage proofs queued, and resets the replaced proof txs
+func (pb *proofBatch) take() map[common.Address][]pendingProof {
+	pending := pb.pending
+	pb.pending, pb.replaced = nil, nil
+	return pending
+}
+
+// replace records the proof tx replaced by the fee bump
+func (pb *proofBatch) replace(old, txHash common.Hash) {
+	if pb.replaced == nil {
+		pb.replaced = make(map[common.Hash]common.Hash)
+	}
+	pb.replaced[old] = txHash
+}
+
+// replacement returns the tx replacing the proof tx in the current round, if any
+func (pb *proofBatch) replacement(old common.Hash) (common.Hash, bool) {
+	txHash, exists := pb.replaced[old]
+	return txHash, exists
+}
+
+// splitProofBatches splits the storage proofs into batches of at most size proofs
+func splitProofBatches(proofs []pendingProof, size int) (batches [][]pendingProof) {
+	for len(proofs) > size {
+		batches = append(batches, proofs[:size])
+		proofs = proofs[size:]
+	}
+	if len(proofs) > 0 {
+		batches = append(batches, proofs)
+	}
+	return
+}
+
+// submitStorageProofs sends the storage proofs queued while handling the task items
+func (h *StorageHost) submitStorageProofs() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for from, proofs := range h.proofBatch.take() {
+		for _, batch := range splitProofBatches(proofs, h.proofBatchSize()) {
+			h.submitProofBatch(from, batch)
+		}
+	}
+}
+
+// submitProofBatch sends the storage proofs in one tx. The single proof is sent in the storage
+// proof tx. If failed to send, the proofs are built and sent again later
+func (h *StorageHost) submitProofBatch(from common.Address, batch []pendingProof) {
+	txHash, err := h.sendProofBatch(from, batch)
+	if err != nil {
+		h.log.Warn("Error sending the storage proof transaction", "proofs", len(batch), "err", err)
+		for _, p := range batch {
+			if err := h.queueTaskItem(h.blockHeight+postponedExecution, p.so.id()); err != nil {
+				h.log.Warn("Error queuing task item", "err", err)
+			}
+		}
+		return
+	}
+
+	//Monitor whether the proof tx is mined within the proof confirm blocks
+	for _, p := range batch {
+		h.recordProofSubmission(p.so, txHash)
+	}
+}
+
+// sendProofBatch encodes the storage proofs and sends them in one tx
+func (h *StorageHost) sendProofBatch(from common.Address, batch []pendingProof) (common.Hash, error) {
+	if len(batch) == 1 {
+		spBytes, err := rlp.EncodeToBytes(batch[0].proof)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		return h.sendStorageProofTx(from, spBytes)
+	}
+
+	proofs := types.StorageProofBatch{Proofs: make([]types.StorageProof, 0, len(batch))}
+	for _, p := range batch {
+		proofs.Proofs = append(proofs.Proofs, p.proof)
+	}
+	batchBytes, err := rlp.EncodeToBytes(proofs)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return h.parseAPI.StorageTx.SendBatchStorageProofTX(from, batchBytes)
+}
@@ -0,0 +1,110 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestSplitProofBatches(t *testing.T) {
+	tests := []struct {
+		proofs  int
+		size    int
+		batches []int
+	}{
+		{0, 3, nil},
+		{1, 3, []int{1}},
+		{3, 3, []int{3}},
+		{7, 3, []int{3, 3, 1}},
+	}
+	for i, test := range tests {
+		batches := splitProofBatches(make([]pendingProof, test.proofs), test.size)
+		if len(batches) != len(test.batches) {
+			t.Errorf("test %d: expect %v batches, got %v", i, len(test.batches), len(batches))
+			continue
+		}
+		for j, batch := range batches {
+			if len(batch) != test.batches[j] {
+				t.Errorf("test %d: expect %v proofs in batch %d, got %v", i, test.batches[j], j, len(batch))
+			}
+		}
+	}
+}
+
+func TestStorageHost_ProofBatchSize(t *testing.T) {
+	h := &StorageHost{blockHeight: 1000}
+
+	// the config persisted before the feature flags uses the default feature flags
+	if size := h.proofBatchSize(); size != storageProofBatchSize {
+		t.Errorf("expect batch size %v, got %v", storageProofBatchSize, size)
+	}
+	h.config.FeatureFlags = storage.FeatureFlags{storage.FeatureBatchProof: 1001}
+	if size := h.proofBatchSize(); size != 1 {
+		t.Errorf("the proofs shall be submitted one by one before the batch proof activated, got %v", size)
+	}
+	h.blockHeight = 1001
+	if size := h.proofBatchSize(); size != storageProofBatchSize {
+		t.Errorf("expect batch size %v, got %v", storageProofBatchSize, size)
+	}
+}
+
+func TestProofBatch(t *testing.T) {
+	var pb proofBatch
+	host1, host2 := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+	pb.add(host1, StorageResponsibility{}, types.StorageProof{ParentID: common.HexToHash("0x1")})
+	pb.add(host1, StorageResponsibility{}, types.StorageProof{ParentID: common.HexToHash("0x2")})
+	pb.add(host2, StorageResponsibility{}, types.StorageProof{ParentID: common.HexToHash("0x3")})
+	pb.replace(common.HexToHash("0xa"), common.HexToHash("0xb"))
+
+	pending := pb.take()
+	if len(pending[host1]) != 2 || len(pending[host2]) != 1 {
+		t.Fatalf("the proofs shall be queued by the host address, got %v and %v", len(pending[host1]), len(pending[host2]))
+	}
+	if len(pb.take()) != 0 {
+		t.Error("the proofs shall be cleared once taken")
+	}
+	if _, exists := pb.replacement(common.HexToHash("0xa")); exists {
+		t.Error("the replaced proof txs shall be reset once the proofs are taken")
+	}
+}
+
+func TestStorageHost_ProofSubmissionBatchBumped(t *testing.T) {
+	db, err := ethdb.NewLDBDatabase(filepath.Join(tempDir(t.Name()), "db"), 16, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	h := &StorageHost{db: db, log: log.New(), blockHeight: 100}
+	so := StorageResponsibility{
+		OriginStorageContract: types.StorageContract{
+			WindowStart: 100,
+			WindowEnd:   200,
+		},
+	}
+	batchTx, bumpedTx := common.HexToHash("0x1"), common.HexToHash("0x2")
+	h.recordProofSubmission(so, batchTx)
+
+	// the batch proof tx is bumped for another responsibility in the same round
+	h.blockHeight += defaultProofConfirmBlocks
+	h.proofBatch.replace(batchTx, bumpedTx)
+	if !h.checkProofSubmission(so) {
+		t.Fatal("the proof tx bumped shall be waited")
+	}
+	ps, err := getProofSubmission(db, so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ps.TxHash != bumpedTx || ps.Resubmissions != 1 {
+		t.Errorf("the bumped proof tx shall be recorded, got %+v", ps)
+	}
+}
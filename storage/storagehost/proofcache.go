@@ -0,0 +1,152 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/rlp"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// The proof cache keeps the merkle roots of the chunks of each sector of the storage
+// responsibility. With the chunk roots, the storage proof only needs to read the chunk
+// containing the proof segment instead of the whole sector. The chunk roots of the sectors
+// gained are computed from the sector data received in the revision, so no extra read
+// is needed to maintain the cache.
+
+// chunkHeight is the height of the merkle subtree of a proof cache chunk
+var chunkHeight = calculateChunkHeight()
+
+// calculateChunkHeight calculate the merkle subtree height of the proof cache chunk
+func calculateChunkHeight() uint64 {
+	height := uint64(0)
+	for 1<<height < (ProofCacheChunkSize / merkle.LeafSize) {
+		height++
+	}
+	return height
+}
+
+// chunkRoots calculates the merkle roots of the chunks of the sector data
+func chunkRoots(sectorData []byte) []common.Hash {
+	roots := make([]common.Hash, 0, len(sectorData)/ProofCacheChunkSize)
+	for offset := 0; offset < len(sectorData); offset += ProofCacheChunkSize {
+		end := offset + ProofCacheChunkSize
+		if end > len(sectorData) {
+			end = len(sectorData)
+		}
+		roots = append(roots, merkle.Sha256MerkleTreeRoot(sectorData[offset:end]))
+	}
+	return roots
+}
+
+// proofCacheKey returns the db key of the chunk roots of the sector in the storage responsibility
+func proofCacheKey(storageContractID common.Hash, sectorRoot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(storageContractID[:], sectorRoot[:])
+}
+
+// putProofCache stores the chunk roots of the sector in the storage responsibility
+func putProofCache(db ethdb.Database, storageContractID common.Hash, sectorRoot common.Hash, roots []common.Hash) error {
+	scdb := ethdb.StorageContractDB{db}
+	data, err := rlp.EncodeToBytes(roots)
+	if err != nil {
+		return err
+	}
+	return scdb.StoreWithPrefix(proofCacheKey(storageContractID, sectorRoot), data, prefixProofCache)
+}
+
+// getProofCache retrieves the chunk roots of the sector in the storage responsibility
+func getProofCache(db ethdb.Database, storageContractID common.Hash, sectorRoot common.Hash) ([]common.Hash, error) {
+	scdb := ethdb.StorageContractDB{db}
+	valueBytes, err := scdb.GetWithPrefix(proofCacheKey(storageContractID, sectorRoot), prefixProofCache)
+	if err != nil {
+		return nil, err
+	}
+	var roots []common.Hash
+	if err = rlp.DecodeBytes(valueBytes, &roots); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
+// deleteProofCache deletes the chunk roots of the sector in the storage responsibility
+func deleteProofCache(db ethdb.Database, storageContractID common.Hash, sectorRoot common.Hash) error {
+	scdb := ethdb.StorageContractDB{db}
+	return scdb.DeleteWithPrefix(proofCacheKey(storageContractID, sectorRoot), prefixProofCache)
+}
+
+// updateProofCache adds the chunk roots of the sectors gained, and deletes the chunk roots
+// of the sectors no longer in the storage responsibility. Errors are only logged since the
+// missing chunk roots are recomputed when the storage proof is built
+func (h *StorageHost) updateProofCache(so StorageResponsibility, sectorsRemoved []common.Hash, sectorsGained []common.Hash, gainedSectorData [][]byte) {
+	remaining := make(map[common.Hash]struct{}, len(so.SectorRoots))
+	for _, root := range so.SectorRoots {
+		remaining[root] = struct{}{}
+	}
+	for _, root := range sectorsRemoved {
+		if _, exist := remaining[root]; exist {
+			continue
+		}
+		if err := deleteProofCache(h.db, so.id(), root); err != nil {
+			h.log.Warn("Failed to delete the proof cache", "id", so.id(), "sector", root, "err", err)
+		}
+	}
+	for i, root := range sectorsGained {
+		if _, exist := remaining[root]; !exist {
+			continue
+		}
+		if err := putProofCache(h.db, so.id(), root, chunkRoots(gainedSectorData[i])); err != nil {
+			h.log.Warn("Failed to save the proof cache", "id", so.id(), "sector", root, "err", err)
+		}
+	}
+}
+
+// clearProofCache deletes the chunk roots of all sectors in the storage responsibility
+func (h *StorageHost) clearProofCache(so StorageResponsibility) {
+	for _, root := range so.SectorRoots {
+		if err := deleteProofCache(h.db, so.id(), root); err != nil {
+			h.log.Warn("Failed to delete the proof cache", "id", so.id(), "sector", root, "err", err)
+		}
+	}
+}
+
+// sectorStorageProof builds the storage proof of the segment within the sector. Only the
+// chunk containing the segment is read if the chunk roots of the sector are cached. Otherwise
+// the whole sector is read, and the chunk roots are cached for the next proof
+func (h *StorageHost) sectorStorageProof(soid common.Hash, sectorRoot common.Hash, sectorSegment uint64) (base []byte, hashSet []common.Hash, err error) {
+	roots, err := getProofCache(h.db, soid, sectorRoot)
+	if err != nil || uint64(len(roots)) != storage.SectorSize/ProofCacheChunkSize {
+		sectorBytes, err := h.ReadSector(sectorRoot)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := putProofCache(h.db, soid, sectorRoot, chunkRoots(sectorBytes)); err != nil {
+			h.log.Warn("Failed to save the proof cache", "id", soid, "sector", sectorRoot, "err", err)
+		}
+		base, hashSet = merkleProof(sectorBytes, sectorSegment)
+		return base, hashSet, nil
+	}
+
+	// Read the chunk containing the segment, and prove the segment within the chunk
+	chunkLeaves := uint64(ProofCacheChunkSize / merkle.LeafSize)
+	chunkIndex := sectorSegment / chunkLeaves
+	chunk, err := h.ReadSectorRange(sectorRoot, chunkIndex*ProofCacheChunkSize, ProofCacheChunkSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	base, chunkHashSet := merkleProof(chunk, sectorSegment%chunkLeaves)
+
+	// Extend the proof to the sector root with the cached chunk roots
+	ct := merkle.NewSha256CachedTree(chunkHeight)
+	if err = ct.SetStorageProofIndex(sectorSegment); err != nil {
+		return nil, nil, err
+	}
+	for _, root := range roots {
+		ct.Push(root)
+	}
+	return base, ct.Prove(base, chunkHashSet), nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"bytes"
+	"crypto/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/storage"
+	sm "github.com/DxChainNetwork/godx/storage/storagehost/storagemanager"
+)
+
+// sectorReader is the storage manager serving the sector data from memory, and counting
+// the bytes read
+type sectorReader struct {
+	sm.StorageManager
+	sectors   map[common.Hash][]byte
+	bytesRead uint64
+}
+
+func (sr *sectorReader) ReadSector(root common.Hash) ([]byte, error) {
+	return sr.ReadSectorRange(root, 0, storage.SectorSize)
+}
+
+func (sr *sectorReader) ReadSectorRange(root common.Hash, offset, length uint64) ([]byte, error) {
+	data, exist := sr.sectors[root]
+	if !exist {
+		return nil, sm.ErrNotFound
+	}
+	sr.bytesRead += length
+	return data[offset : offset+length], nil
+}
+
+func TestStorageHost_SectorStorageProof(t *testing.T) {
+	db, err := ethdb.NewLDBDatabase(filepath.Join(tempDir(t.Name()), "db"), 16, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	data := make([]byte, storage.SectorSize)
+	rand.Read(data)
+	root := merkle.Sha256MerkleTreeRoot(data)
+	reader := &sectorReader{sectors: map[common.Hash][]byte{root: data}}
+	h := &StorageHost{StorageManager: reader, db: db, log: log.New()}
+
+	so := StorageResponsibility{SectorRoots: []common.Hash{root}}
+	h.updateProofCache(so, nil, []common.Hash{root}, [][]byte{data})
+	if reader.bytesRead != 0 {
+		t.Fatalf("updating the proof cache should not read the sector")
+	}
+
+	segments := []uint64{0, 1, ProofCacheChunkSize/merkle.LeafSize + 5, storage.SectorSize/merkle.LeafSize - 1}
+	for _, segment := range segments {
+		reader.bytesRead = 0
+		base, hashSet, err := h.sectorStorageProof(so.id(), root, segment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectBase, expectHashSet := merkleProof(data, segment)
+		if !bytes.Equal(base, expectBase) || !reflect.DeepEqual(hashSet, expectHashSet) {
+			t.Errorf("storage proof of segment %v not expected", segment)
+		}
+		if reader.bytesRead != ProofCacheChunkSize {
+			t.Errorf("bytes read not expected. Expect %v, Got %v", ProofCacheChunkSize, reader.bytesRead)
+		}
+	}
+
+	// the whole sector is read after the cache is cleared, and the cache is populated again
+	h.clearProofCache(so)
+	for _, expect := range []uint64{storage.SectorSize, ProofCacheChunkSize} {
+		reader.bytesRead = 0
+		if _, _, err := h.sectorStorageProof(so.id(), root, 3); err != nil {
+			t.Fatal(err)
+		}
+		if reader.bytesRead != expect {
+			t.Errorf("bytes read not expected. Expect %v, Got %v", expect, reader.bytesRead)
+		}
+	}
+
+	// the cache of the sector removed from the responsibility is deleted
+	h.updateProofCache(StorageResponsibility{}, []common.Hash{root}, nil, nil)
+	if _, err := getProofCache(db, so.id(), root); err == nil {
+		t.Errorf("the proof cache of the removed sector should be deleted")
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/internal/ethapi"
+	"github.com/DxChainNetwork/godx/rlp"
+)
+
+// The proof monitor keeps track of the storage proof tx submitted in the proof window. If
+// the proof tx is not mined within the proof confirm blocks, the host raises an alert and
+// replaces the pending tx with one paying a higher fee. If the tx is no longer in the tx pool
+// and the proof is still not confirmed, the proof is built and sent again.
+
+const (
+	// proofActionBump is the alert action that the proof tx is resubmitted with a higher fee
+	proofActionBump = "bump"
+	// proofActionResend is the alert action that the proof tx is dropped and will be resent
+	proofActionResend = "resend"
+	// proofActionFailed is the alert action that the resubmission of the proof tx failed
+	proofActionFailed = "failed"
+)
+
+type (
+	// proofSubmission is the record of the storage proof tx submitted and not confirmed yet
+	proofSubmission struct {
+		TxHash        common.Hash
+		SubmitHeight  uint64
+		Resubmissions uint64
+	}
+
+	// ProofAlert is the alert raised when the storage proof tx is not mined within the
+	// proof confirm blocks
+	ProofAlert struct {
+		ContractID    common.Hash `json:"contractID"`
+		TxHash        common.Hash `json:"txHash"`
+		SubmitHeight  uint64      `json:"submitHeight"`
+		BlockHeight   uint64      `json:"blockHeight"`
+		ProofDeadline uint64      `json:"proofDeadline"`
+		Resubmissions uint64      `json:"resubmissions"`
+		Action        string      `json:"action"`
+		Error         string      `json:"error,omitempty"`
+		Time          time.Time   `json:"time"`
+	}
+)
+
+// putProofSubmission stores the proof submission of the storage responsibility
+func putProofSubmission(db ethdb.Database, storageContractID common.Hash, ps proofSubmission) error {
+	scdb := ethdb.StorageContractDB{db}
+	data, err := rlp.EncodeToBytes(ps)
+	if err != nil {
+		return err
+	}
+	return scdb.StoreWithPrefix(storageContractID, data, prefixProofSubmission)
+}
+
+// getProofSubmission retrieves the proof submission of the storage responsibility
+func getProofSubmission(db ethdb.Database, storageContractID common.Hash) (proofSubmission, error) {
+	scdb := ethdb.StorageContractDB{db}
+	valueBytes, err := scdb.GetWithPrefix(storageContractID, prefixProofSubmission)
+	if err != nil {
+		return proofSubmission{}, err
+	}
+	var ps proofSubmission
+	if err = rlp.DecodeBytes(valueBytes, &ps); err != nil {
+		return proofSubmission{}, err
+	}
+	return ps, nil
+}
+
+// deleteProofSubmission deletes the proof submission of the storage responsibility
+func deleteProofSubmission(db ethdb.Database, storageContractID common.Hash) error {
+	scdb := ethdb.StorageContractDB{db}
+	return scdb.DeleteWithPrefix(storageContractID, prefixProofSubmission)
+}
+
+// proofConfirmBlocks returns the number of blocks the proof tx is expected to be mined within.
+// The config persisted before the field was introduced falls back to the default value
+func (h *StorageHost) proofConfirmBlocks() uint64 {
+	if h.config.ProofConfirmBlocks == 0 {
+		return defaultProofConfirmBlocks
+	}
+	return h.config.ProofConfirmBlocks
+}
+
+// recordProofSubmission records the proof tx just sent, and queues the task to check whether
+// it is mined within the proof confirm blocks. The proof tx replacing the one submitted before
+// is counted as a resubmission
+func (h *StorageHost) recordProofSubmission(so StorageResponsibility, txHash common.Hash) {
+	ps := proofSubmission{
+		TxHash:       txHash,
+		SubmitHeight: h.blockHeight,
+	}
+	if prev, err := getProofSubmission(h.db, so.id()); err == nil {
+		ps.Resubmissions = prev.Resubmissions + 1
+	}
+	if err := putProofSubmission(h.db, so.id(), ps); err != nil {
+		h.log.Warn("Failed to save the proof submission", "id", so.id(), "err", err)
+	}
+	if err := h.queueTaskItem(h.blockHeight+h.proofConfirmBlocks(), so.id()); err != nil {
+		h.log.Warn("Error queuing task item", "err", err)
+	}
+}
+
+// checkProofSubmission checks the proof tx submitted before for the storage responsibility.
+// It returns true if the proof tx is still expected to be mined, thus no new proof shall be
+// sent. The overdue proof tx is resubmitted with a higher fee, and false is returned only if
+// the proof tx is no longer pending and the proof need to be sent again
+func (h *StorageHost) checkProofSubmission(so StorageResponsibility) bool {
+	ps, err := getProofSubmission(h.db, so.id())
+	if err != nil {
+		return false
+	}
+	if h.blockHeight < ps.SubmitHeight+h.proofConfirmBlocks() {
+		return true
+	}
+
+	alert := ProofAlert{
+		ContractID:    so.id(),
+		TxHash:        ps.TxHash,
+		SubmitHeight:  ps.SubmitHeight,
+		BlockHeight:   h.blockHeight,
+		ProofDeadline: so.proofDeadline(),
+		Resubmissions: ps.Resubmissions,
+		Time:          h.clock.Now(),
+	}
+	// the batch proof tx shared with other responsibilities may be bumped already
+	if txHash, exists := h.proofBatch.replacement(ps.TxHash); exists {
+		h.recordProofSubmission(so, txHash)
+		return true
+	}
+
+	from := so.OriginStorageContract.ValidProofOutputs[1].Address
+	txHash, err := h.parseAPI.StorageTx.BumpStorageContractTX(from, ps.TxHash, proofFeeBumpPercent, h.maxTxGasPrice())
+	switch {
+	case err == nil:
+		alert.Action = proofActionBump
+		h.addProofAlert(alert)
+		h.proofBatch.replace(ps.TxHash, txHash)
+		h.recordProofSubmission(so, txHash)
+		return true
+	case err == ethapi.ErrStorageTxNotPending:
+		alert.Action = proofActionResend
+		h.addProofAlert(alert)
+		return false
+	default:
+		// retry later, sending a new proof while the old one is pending would waste the fee
+		alert.Action, alert.Error = proofActionFailed, err.Error()
+		h.addProofAlert(alert)
+		if err := h.queueTaskItem(h.blockHeight+postponedExecution, so.id()); err != nil {
+			h.log.Warn("Error queuing task item", "err", err)
+		}
+		return true
+	}
+}
+
+// addProofAlert logs the proof alert and keeps it in the recent alert list
+func (h *StorageHost) addProofAlert(alert ProofAlert) {
+	h.log.Warn("Storage proof tx not mined within the proof confirm blocks", "id", alert.ContractID,
+		"tx", alert.TxHash, "submitHeight", alert.SubmitHeight, "deadline", alert.ProofDeadline,
+		"action", alert.Action, "err", alert.Error)
+	h.proofAlerts = append(h.proofAlerts, alert)
+	if len(h.proofAlerts) > maxProofAlerts {
+		h.proofAlerts = h.proofAlerts[len(h.proofAlerts)-maxProofAlerts:]
+	}
+}
+
+// getProofAlerts returns the recent proof alerts
+func (h *StorageHost) getProofAlerts() []ProofAlert {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	alerts := make([]ProofAlert, len(h.proofAlerts))
+	copy(alerts, h.proofAlerts)
+	return alerts
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/log"
+)
+
+func TestStorageHost_ProofSubmission(t *testing.T) {
+	db, err := ethdb.NewLDBDatabase(filepath.Join(tempDir(t.Name()), "db"), 16, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	h := &StorageHost{db: db, log: log.New(), blockHeight: 100}
+	so := StorageResponsibility{
+		OriginStorageContract: types.StorageContract{
+			WindowStart: 100,
+			WindowEnd:   200,
+		},
+	}
+	// no proof submitted, the proof shall be sent
+	if h.checkProofSubmission(so) {
+		t.Fatalf("the proof shall be sent if no proof tx submitted")
+	}
+
+	txHash := common.HexToHash("0x1")
+	h.recordProofSubmission(so, txHash)
+	ps, err := getProofSubmission(db, so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ps.TxHash != txHash || ps.SubmitHeight != 100 || ps.Resubmissions != 0 {
+		t.Fatalf("proof submission not expected. Got %+v", ps)
+	}
+	// the check task is queued after the proof confirm blocks
+	if _, err := getHeight(db, 100+defaultProofConfirmBlocks); err != nil {
+		t.Fatalf("the proof submission check task shall be queued: %v", err)
+	}
+
+	// the proof tx within the proof confirm blocks is waited
+	h.blockHeight += defaultProofConfirmBlocks - 1
+	if !h.checkProofSubmission(so) {
+		t.Fatalf("the proof tx shall be waited within the proof confirm blocks")
+	}
+	if len(h.getProofAlerts()) != 0 {
+		t.Fatalf("no proof alert shall be raised within the proof confirm blocks")
+	}
+
+	// the replacement is counted as a resubmission
+	h.recordProofSubmission(so, common.HexToHash("0x2"))
+	if ps, err = getProofSubmission(db, so.id()); err != nil {
+		t.Fatal(err)
+	}
+	if ps.Resubmissions != 1 {
+		t.Fatalf("resubmissions not expected. Expect 1, Got %v", ps.Resubmissions)
+	}
+
+	if err = deleteProofSubmission(db, so.id()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = getProofSubmission(db, so.id()); err == nil {
+		t.Fatalf("the proof submission shall be deleted")
+	}
+}
+
+func TestStorageHost_ProofConfirmBlocks(t *testing.T) {
+	h := &StorageHost{}
+	if h.proofConfirmBlocks() != defaultProofConfirmBlocks {
+		t.Errorf("the config without proof confirm blocks shall use the default value")
+	}
+	h.config.ProofConfirmBlocks = 20
+	if h.proofConfirmBlocks() != 20 {
+		t.Errorf("proof confirm blocks not expected. Expect 20, Got %v", h.proofConfirmBlocks())
+	}
+}
+
+func TestStorageHost_AddProofAlert(t *testing.T) {
+	h := &StorageHost{log: log.New()}
+	for i := 0; i < maxProofAlerts+10; i++ {
+		h.addProofAlert(ProofAlert{BlockHeight: uint64(i), Action: proofActionBump})
+	}
+	alerts := h.getProofAlerts()
+	if len(alerts) != maxProofAlerts {
+		t.Fatalf("number of proof alerts not expected. Expect %v, Got %v", maxProofAlerts, len(alerts))
+	}
+	if alerts[0].BlockHeight != 10 || alerts[len(alerts)-1].BlockHeight != maxProofAlerts+9 {
+		t.Errorf("the most recent proof alerts shall be kept")
+	}
+}
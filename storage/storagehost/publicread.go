@@ -0,0 +1,219 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/p2p"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// The storage host with the public read feature serves the public sectors to anyone, without
+// a storage contract or payment. A sector becomes public once the storage client of the contract
+// holding the sector publishes it. The public sector record is kept as the index of the public
+// sectors, which is dropped once the storage responsibility removes the sector.
+
+var (
+	// errPublicReadDisabled is the error that the public read feature is not active on the host
+	errPublicReadDisabled = errors.New("public read is not enabled on the storage host")
+
+	// errSectorNotPublic is the error that the sector requested is not published by any client
+	errSectorNotPublic = errors.New("the sector is not public")
+)
+
+// PublishSectorsHandler handles the publish sectors request sent by the storage client. The
+// sectors published must be held by the storage contract, and the request must be signed by
+// the storage client of the contract
+func PublishSectorsHandler(h *StorageHost, sp storage.Peer, publishReqMsg p2p.Msg) {
+	var hostNegotiateErr error
+	defer func() {
+		if hostNegotiateErr != nil {
+			log.Debug("storage host failed to handle publish sectors", "err", hostNegotiateErr)
+			_ = sp.SendHostNegotiateErrorMsg()
+		}
+	}()
+
+	var req storage.PublishSectorsRequest
+	if err := publishReqMsg.Decode(&req); err != nil {
+		hostNegotiateErr = fmt.Errorf("failed to decode the publish sectors request message: %s", err.Error())
+		return
+	}
+
+	if err := h.publishSectors(req); err != nil {
+		hostNegotiateErr = err
+		return
+	}
+
+	if err := sp.SendHostAckMsg(); err != nil {
+		log.Error("storage host failed to send host ack msg", "err", err)
+	}
+}
+
+// PublicDownloadHandler handles the download request of the public sector. Unlike the contract
+// download, no payment revision is negotiated, the data is sent right away
+func PublicDownloadHandler(h *StorageHost, sp storage.Peer, downloadReqMsg p2p.Msg) {
+	var hostNegotiateErr error
+	defer func() {
+		if hostNegotiateErr != nil {
+			log.Debug("storage host failed to handle public download", "err", hostNegotiateErr)
+			_ = sp.SendHostNegotiateErrorMsg()
+		}
+	}()
+
+	var req storage.PublicDownloadRequest
+	if err := downloadReqMsg.Decode(&req); err != nil {
+		hostNegotiateErr = fmt.Errorf("failed to decode the public download request message: %s", err.Error())
+		return
+	}
+
+	resp, err := h.publicDownload(req)
+	if err != nil {
+		hostNegotiateErr = err
+		return
+	}
+
+	if err := sp.SendPublicDownloadData(resp); err != nil {
+		log.Error("storage host failed to send the public download data", "err", err)
+	}
+}
+
+// publishSectors validates the publish sectors request, and marks the sectors as public
+func (h *StorageHost) publishSectors(req storage.PublishSectorsRequest) error {
+	h.lock.RLock()
+	enabled := h.featureEnabled(storage.FeaturePublicRead)
+	so, err := getStorageResponsibility(h.db, req.StorageContractID)
+	h.lock.RUnlock()
+
+	if !enabled {
+		return errPublicReadDisabled
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(req.Roots) == 0:
+		return errors.New("no sector to publish")
+	case len(req.Roots) > storage.MaxPublishSectors:
+		return fmt.Errorf("publishing %v sectors exceeds the limit %v", len(req.Roots), storage.MaxPublishSectors)
+	case len(so.StorageContractRevisions) == 0:
+		return errors.New("no contract revision found")
+	}
+
+	// only the storage client of the contract could publish its sectors
+	currentRevision := so.StorageContractRevisions[len(so.StorageContractRevisions)-1]
+	if err = storage.VerifyPublishSectorsRequest(req, currentRevision.NewValidProofOutputs[0].Address); err != nil {
+		return err
+	}
+
+	held := make(map[common.Hash]struct{}, len(so.SectorRoots))
+	for _, root := range so.SectorRoots {
+		held[root] = struct{}{}
+	}
+	for _, root := range req.Roots {
+		if _, exist := held[root]; !exist {
+			return fmt.Errorf("sector %v is not held by the contract", root.String())
+		}
+	}
+
+	for _, root := range req.Roots {
+		if err = putPublicSector(h.db, root, req.StorageContractID); err != nil {
+			return fmt.Errorf("failed to save the public sector: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// publicDownload validates the public download request, and reads the data requested
+func (h *StorageHost) publicDownload(req storage.PublicDownloadRequest) (resp storage.DownloadResponse, err error) {
+	sec := req.Sector
+	switch {
+	case uint64(sec.Offset)+uint64(sec.Length) > storage.SectorSize:
+		err = errors.New("download out boundary of sector")
+	case sec.Length == 0:
+		err = errors.New("length cannot be 0")
+	case req.MerkleProof && (sec.Offset%storage.SegmentSize != 0 || sec.Length%storage.SegmentSize != 0):
+		err = errors.New("offset and length must be multiples of SegmentSize when requesting a Merkle proof")
+	}
+	if err != nil {
+		return resp, fmt.Errorf("public download request validation failed: %s", err.Error())
+	}
+
+	h.lock.RLock()
+	enabled := h.featureEnabled(storage.FeaturePublicRead)
+	h.lock.RUnlock()
+	if !enabled {
+		return resp, errPublicReadDisabled
+	}
+	if !h.isPublicSector(sec.MerkleRoot) {
+		return resp, errSectorNotPublic
+	}
+
+	sectorData, err := h.ReadSector(sec.MerkleRoot)
+	if err != nil {
+		return resp, fmt.Errorf("host failed read sector: %s", err.Error())
+	}
+	resp.Data = sectorData[sec.Offset : sec.Offset+sec.Length]
+
+	if req.MerkleProof {
+		proofStart := int(sec.Offset) / merkle.LeafSize
+		proofEnd := int(sec.Offset+sec.Length) / merkle.LeafSize
+		resp.MerkleProof, err = merkle.Sha256RangeProof(sectorData, proofStart, proofEnd)
+		if err != nil {
+			return resp, fmt.Errorf("host failed to generate the merkle proof: %s", err.Error())
+		}
+	}
+	return resp, nil
+}
+
+// isPublicSector checks whether the sector is in the public sector index. The sector is
+// dropped from the index once the storage responsibility no longer holds it
+func (h *StorageHost) isPublicSector(root common.Hash) bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	_, err := getPublicSector(h.db, root)
+	return err == nil
+}
+
+// dropPublicSectors deletes the public sector records of the sectors removed from the storage
+// responsibility. The record of the sector published with another storage responsibility is
+// kept. The caller should hold the lock of the storage host
+func (h *StorageHost) dropPublicSectors(id common.Hash, roots []common.Hash) {
+	for _, root := range roots {
+		if published, err := getPublicSector(h.db, root); err != nil || published != id {
+			continue
+		}
+		if err := deletePublicSector(h.db, root); err != nil {
+			h.log.Warn("Failed to delete the public sector", "sector", root, "err", err)
+		}
+	}
+}
+
+// putPublicSector stores the storage contract the public sector is published with
+func putPublicSector(db ethdb.Database, sectorRoot common.Hash, storageContractID common.Hash) error {
+	scdb := ethdb.StorageContractDB{db}
+	return scdb.StoreWithPrefix(sectorRoot, storageContractID.Bytes(), prefixPublicSector)
+}
+
+// getPublicSector retrieves the storage contract the public sector is published with
+func getPublicSector(db ethdb.Database, sectorRoot common.Hash) (common.Hash, error) {
+	scdb := ethdb.StorageContractDB{db}
+	valueBytes, err := scdb.GetWithPrefix(sectorRoot, prefixPublicSector)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(valueBytes), nil
+}
+
+// deletePublicSector deletes the public sector record
+func deletePublicSector(db ethdb.Database, sectorRoot common.Hash) error {
+	scdb := ethdb.StorageContractDB{db}
+	return scdb.DeleteWithPrefix(sectorRoot, prefixPublicSector)
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"bytes"
+	"crypto/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/core/types"
+	"github.com/DxChainNetwork/godx/crypto"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/ethdb"
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+func TestStorageHost_PublicDownload(t *testing.T) {
+	db, err := ethdb.NewLDBDatabase(filepath.Join(tempDir(t.Name()), "db"), 16, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	sk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, storage.SectorSize)
+	rand.Read(data)
+	root := merkle.Sha256MerkleTreeRoot(data)
+
+	h := &StorageHost{
+		StorageManager: &sectorReader{sectors: map[common.Hash][]byte{root: data}},
+		db:             db,
+		log:            log.New(),
+	}
+	h.config.FeatureFlags = storage.FeatureFlags{storage.FeaturePublicRead: 0}
+
+	so := StorageResponsibility{
+		OriginStorageContract: types.StorageContract{WindowStart: 100},
+		StorageContractRevisions: []types.StorageContractRevision{{
+			NewValidProofOutputs: []types.DxcoinCharge{{Address: crypto.PubkeyToAddress(sk.PublicKey)}},
+		}},
+		SectorRoots: []common.Hash{root},
+	}
+	if err = putStorageResponsibility(db, so.id(), so); err != nil {
+		t.Fatal(err)
+	}
+
+	req := storage.PublicDownloadRequest{
+		Sector:      storage.DownloadRequestSector{MerkleRoot: root, Offset: storage.SegmentSize, Length: 2 * storage.SegmentSize},
+		MerkleProof: true,
+	}
+	if _, err = h.publicDownload(req); err != errSectorNotPublic {
+		t.Fatalf("download before publishing: expect error %v, got %v", errSectorNotPublic, err)
+	}
+
+	// the sectors could only be published by the storage client of the contract
+	publish := storage.PublishSectorsRequest{StorageContractID: so.id(), Roots: []common.Hash{root}}
+	if publish.Signature, err = crypto.Sign(publish.SigHash().Bytes(), sk); err != nil {
+		t.Fatal(err)
+	}
+	unknown := publish
+	unknown.Roots = []common.Hash{common.HexToHash("0x1")}
+	if err = h.publishSectors(unknown); err == nil {
+		t.Fatal("the sector not held by the contract should not be published")
+	}
+	if err = h.publishSectors(publish); err != nil {
+		t.Fatalf("failed to publish the sector: %v", err)
+	}
+
+	resp, err := h.publicDownload(req)
+	if err != nil {
+		t.Fatalf("failed to download the public sector: %v", err)
+	}
+	if !bytes.Equal(resp.Data, data[storage.SegmentSize:3*storage.SegmentSize]) {
+		t.Errorf("public download data not expected")
+	}
+	proofStart, proofEnd := storage.SegmentSize/merkle.LeafSize, 3*storage.SegmentSize/merkle.LeafSize
+	if verified, err := merkle.Sha256VerifyRangeProof(resp.Data, resp.MerkleProof, proofStart, proofEnd, root); err != nil || !verified {
+		t.Errorf("public download merkle proof not valid: %v", err)
+	}
+
+	// the sector removed by another storage responsibility is still public
+	h.dropPublicSectors(common.HexToHash("0x2"), so.SectorRoots)
+	if _, err = h.publicDownload(req); err != nil {
+		t.Fatalf("failed to download the public sector: %v", err)
+	}
+
+	// the sector is no longer public once removed from the storage responsibility
+	h.dropPublicSectors(so.id(), so.SectorRoots)
+	if _, err = h.publicDownload(req); err != errSectorNotPublic {
+		t.Fatalf("download after the sector dropped: expect error %v, got %v", errSectorNotPublic, err)
+	}
+	if _, err = getPublicSector(db, root); err == nil {
+		t.Errorf("the public sector record should be deleted")
+	}
+
+	// nothing is served once the public read is disabled
+	h.config.FeatureFlags = storage.FeatureFlags{}
+	if err = h.publishSectors(publish); err != errPublicReadDisabled {
+		t.Fatalf("publish with public read disabled: expect error %v, got %v", errPublicReadDisabled, err)
+	}
+}
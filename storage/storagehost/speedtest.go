@@ -0,0 +1,42 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"fmt"
+
+	"github.com/DxChainNetwork/godx/log"
+	"github.com/DxChainNetwork/godx/p2p"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// SpeedTestHandler handles the speed test request sent by the storage client. The probe data
+// is discarded, and the host responds with the number of bytes received, from which the client
+// measures the upload speed of the host before assigning sectors to it
+func SpeedTestHandler(h *StorageHost, sp storage.Peer, speedTestReqMsg p2p.Msg) {
+	var hostNegotiateErr error
+	defer func() {
+		if hostNegotiateErr != nil {
+			log.Debug("storage host failed to handle speed test", "err", hostNegotiateErr)
+			_ = sp.SendHostNegotiateErrorMsg()
+		}
+	}()
+
+	if speedTestReqMsg.Size > storage.MaxSpeedTestSize+64 {
+		hostNegotiateErr = fmt.Errorf("speed test request of %v bytes exceeds the limit", speedTestReqMsg.Size)
+		_ = speedTestReqMsg.Discard()
+		return
+	}
+
+	var req storage.SpeedTestRequest
+	if err := speedTestReqMsg.Decode(&req); err != nil {
+		hostNegotiateErr = fmt.Errorf("failed to decode the speed test request message: %s", err.Error())
+		return
+	}
+
+	if err := sp.SendSpeedTestResp(storage.SpeedTestResponse{Received: uint64(len(req.Data))}); err != nil {
+		log.Error("storage host failed to send speed test response", "err", err)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/DxChainNetwork/godx/log"
 	"github.com/DxChainNetwork/godx/p2p/enode"
 	"github.com/DxChainNetwork/godx/storage"
+	"github.com/DxChainNetwork/godx/storage/chrono"
 	sm "github.com/DxChainNetwork/godx/storage/storagehost/storagemanager"
 )
 
@@ -40,11 +41,18 @@ type StorageHost struct {
 
 	lockedStorageResponsibility map[common.Hash]*TryMutex
 	clientToContract            map[string]common.Hash
+	proofAlerts                 []ProofAlert
+	proofBatch                  proofBatch
+	maintenance                 maintenanceWindow
+	prunedHeight                uint64
+	ingress                     *ingressGuard
+	txMonitor                   *storage.StorageTxMonitor
 
 	// things for log and persistence
 	db         *ethdb.LDBDatabase
 	persistDir string
 	log        log.Logger
+	clock      chrono.Clock
 
 	// things for thread safety
 	lock sync.RWMutex
@@ -87,9 +95,13 @@ func (h *StorageHost) UpdateContractToClientNodeMappingAndConnection() {
 }
 
 // RetrieveExternalConfig is used to get the storage host's external
-// configuration
+// configuration, signed by the host's node key
 func (h *StorageHost) RetrieveExternalConfig() storage.HostExtConfig {
-	return h.externalConfig()
+	config := h.externalConfig()
+	if err := storage.SignHostExtConfig(&config, h.ethBackend.SignWithNodeSk); err != nil {
+		h.log.Warn("failed to sign the host external config", "err", err)
+	}
+	return config
 }
 
 // GetCurrentBlockHeight is used to retrieve the current
@@ -106,9 +118,12 @@ func New(persistDir string) (*StorageHost, error) {
 	// do a host creation, but incomplete config
 	h := StorageHost{
 		log:                         log.New(),
+		clock:                       chrono.System,
 		persistDir:                  persistDir,
 		lockedStorageResponsibility: make(map[common.Hash]*TryMutex),
 		clientToContract:            make(map[string]common.Hash),
+		txMonitor:                   storage.NewStorageTxMonitor(storage.StorageTxConfirmBlocks),
+		ingress:                     newIngressGuard(chrono.System),
 	}
 
 	var err error
@@ -153,6 +168,9 @@ func (h *StorageHost) Start(eth storage.HostBackend) (err error) {
 	if err = h.pruneStaleStorageResponsibilities(); err != nil {
 		return err
 	}
+	// advertise the digest of the host config in the node record
+	h.advertiseHostRecord()
+
 	// subscribe block chain change event
 	go h.subscribeChainChangEvent()
 	return nil
@@ -424,7 +442,7 @@ func (h *StorageHost) externalConfig() storage.HostExtConfig {
 	totalStorageSpace = storage.SectorSize * hs.TotalSectors
 	remainingStorageSpace = storage.SectorSize * hs.FreeSectors
 
-	acceptingContracts := h.config.AcceptingContracts
+	acceptingContracts := h.config.AcceptingContracts && !h.maintenance.contains(h.blockHeight)
 	MaxDeposit := h.config.MaxDeposit
 	paymentAddress := h.config.PaymentAddress
 
@@ -473,8 +491,16 @@ func (h *StorageHost) externalConfig() storage.HostExtConfig {
 		ContractPrice:          h.config.ContractPrice,
 		DownloadBandwidthPrice: h.config.DownloadBandwidthPrice,
 		SectorAccessPrice:      h.config.SectorAccessPrice,
-		StoragePrice:           h.config.StoragePrice,
+		StoragePrice:           dynamicStoragePrice(h.config, remainingStorageSpace, totalStorageSpace),
 		UploadBandwidthPrice:   h.config.UploadBandwidthPrice,
 		Version:                storage.ConfigVersion,
+		Features:               h.featureFlags().Active(h.blockHeight),
 	}
 }
+
+// advertiseHostRecord sets the digest of the host config in the node record, so that the
+// storage clients could pre-filter the storage hosts before dialing. The node record is only
+// updated when the digest changes
+func (h *StorageHost) advertiseHostRecord() {
+	h.ethBackend.SetNodeRecordEntry(storage.NewHostRecord(h.externalConfig()))
+}
@@ -247,6 +247,7 @@ func (update *addSectorUpdate) release(manager *storageManager, upErr *updateErr
 		// Need to delete the sector and folder id to sector mapping
 		if update.sector != nil {
 			batch.Delete(makeSectorKey(update.sector.id))
+			batch.Delete(makeSectorCompressionKey(update.sector.id))
 		}
 		// Need to delete the mapping from folder id to sector id
 		if update.folder != nil {
@@ -397,8 +398,18 @@ func (update *addSectorUpdate) processNormal(manager *storageManager) (err error
 		return
 	}
 	if update.physical {
-		_, err = update.folder.dataFile.WriteAt(update.data, int64(update.sector.index*storage.SectorSize))
-		if err != nil {
+		data, compressed := update.data, false
+		if manager.compression {
+			if data, compressed, err = compressSector(update.data); err != nil {
+				return
+			}
+		}
+		if !compressed {
+			data = update.data
+		} else if update.batch, err = manager.db.saveSectorCompressionToBatch(update.batch, update.sector.id, uint64(len(data))); err != nil {
+			return
+		}
+		if err = update.folder.dataFile.writeSector(update.sector.index, data); err != nil {
 			return
 		}
 	}
@@ -448,6 +448,18 @@ func checkSectorExist(root common.Hash, sm *storageManager, data []byte, count u
 	if !bytes.Equal(data, b) {
 		return fmt.Errorf("data bytes not equal")
 	}
+	// Read part of the sector with ReadSectorRange
+	offset, length := storage.SectorSize/4, storage.SectorSize/2
+	b, err = sm.ReadSectorRange(root, offset, length)
+	if err != nil {
+		return
+	}
+	if !bytes.Equal(data[offset:offset+length], b) {
+		return fmt.Errorf("sector range bytes not equal")
+	}
+	if _, err = sm.ReadSectorRange(root, offset, storage.SectorSize); err == nil {
+		return fmt.Errorf("range out of sector bound should return an error")
+	}
 	return nil
 }
 
@@ -256,8 +256,10 @@ func (update *addStorageFolderUpdate) release(manager *storageManager, upErr *up
 	}
 	// Close the folder datafile
 	if update.folder != nil {
-		if newErr := update.folder.dataFile.Close(); newErr != nil {
-			err = common.ErrCompose(err, newErr)
+		if update.folder.dataFile != nil {
+			if newErr := update.folder.dataFile.Close(); newErr != nil {
+				err = common.ErrCompose(err, newErr)
+			}
 		}
 		// Delete the entry in database
 		if newErr := manager.db.deleteStorageFolder(update.folder); newErr != nil {
@@ -269,7 +271,11 @@ func (update *addStorageFolderUpdate) release(manager *storageManager, upErr *up
 	// file, which might be useful to other programs. So delete the file only if the processErr
 	// is not os.ErrExist
 	if upErr.processErr != os.ErrExist {
-		if newErr := os.Remove(filepath.Join(update.path, dataFileName)); newErr != nil {
+		var id folderID
+		if update.folder != nil {
+			id = update.folder.id
+		}
+		if newErr := removeSectorStore(manager.storeConfig, update.path, id); newErr != nil {
 			err = common.ErrCompose(err, newErr)
 		}
 	}
@@ -335,8 +341,8 @@ func (update *addStorageFolderUpdate) processNormal(manager *storageManager) (er
 	if err = os.MkdirAll(update.path, 0700); err != nil {
 		return err
 	}
-	// create the data file
-	update.folder.dataFile, err = os.Create(filepath.Join(update.path, dataFileName))
+	// create the data file in the sector store
+	update.folder.dataFile, err = createSectorStore(manager.storeConfig, update.path, update.folder.id)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,209 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagemanager
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/rlp"
+	"github.com/DxChainNetwork/godx/storage"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// The sector compression is opt-in and transparent to the upper module. If enabled, the
+// physical sector is stored compressed when the compression saves at least minCompressionSaving
+// bytes, and is decompressed on read. The sector root is always verified against the
+// decompressed original bytes. The compressed size of each compressed sector is recorded in
+// database, so the space saved could be reported to the host operator. With the space saved,
+// the host could oversubscribe the storage folders for compressible workloads.
+
+// minCompressionSaving is the minimum bytes saved by compression to store the sector compressed
+const minCompressionSaving = storage.SectorSize / 8
+
+type (
+	// SectorCompressionStats is the compression statistics of the stored sectors
+	SectorCompressionStats struct {
+		Enabled           bool    `json:"enabled"`
+		CompressedSectors uint64  `json:"compressedSectors"`
+		OriginalBytes     uint64  `json:"originalBytes"`
+		StoredBytes       uint64  `json:"storedBytes"`
+		SavedBytes        uint64  `json:"savedBytes"`
+		Ratio             float64 `json:"ratio"`
+	}
+
+	// SectorCompression is the compression record of a single sector
+	SectorCompression struct {
+		Compressed     bool   `json:"compressed"`
+		OriginalSize   uint64 `json:"originalSize"`
+		CompressedSize uint64 `json:"compressedSize"`
+	}
+
+	// sectorCompressionPersist is the compression record of the compressed sector stored
+	// in database
+	sectorCompressionPersist struct {
+		CompressedSize uint64
+	}
+)
+
+// SetSectorCompression enables or disables the sector compression. The setting only applies
+// to the sectors added afterwards. The sectors already stored are not changed
+func (sm *storageManager) SetSectorCompression(enabled bool) (err error) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	if err = sm.db.saveSectorCompression(enabled); err != nil {
+		return
+	}
+	sm.compression = enabled
+	return
+}
+
+// CompressionStats returns the compression statistics of all sectors stored
+func (sm *storageManager) CompressionStats() (stats SectorCompressionStats, err error) {
+	sm.lock.RLock()
+	stats.Enabled = sm.compression
+	sm.lock.RUnlock()
+
+	iter := sm.db.lvl.NewIterator(util.BytesPrefix([]byte(prefixSectorCompression+"_")), nil)
+	defer iter.Release()
+	for iter.Next() {
+		var scp sectorCompressionPersist
+		if err = rlp.DecodeBytes(iter.Value(), &scp); err != nil {
+			return SectorCompressionStats{}, err
+		}
+		stats.CompressedSectors++
+		stats.OriginalBytes += storage.SectorSize
+		stats.StoredBytes += scp.CompressedSize
+	}
+	if err = iter.Error(); err != nil {
+		return SectorCompressionStats{}, err
+	}
+	stats.SavedBytes = stats.OriginalBytes - stats.StoredBytes
+	if stats.StoredBytes != 0 {
+		stats.Ratio = float64(stats.OriginalBytes) / float64(stats.StoredBytes)
+	}
+	return
+}
+
+// SectorCompression returns the compression record of the sector with the root
+func (sm *storageManager) SectorCompression(root common.Hash) (sc SectorCompression, err error) {
+	id := sm.calculateSectorID(root)
+	sm.sectorLocks.lockSector(id)
+	defer sm.sectorLocks.unlockSector(id)
+
+	if exist, err := sm.db.hasSector(id); err != nil || !exist {
+		return SectorCompression{}, ErrNotFound
+	}
+	compressedSize, compressed, err := sm.db.getSectorCompression(id)
+	if err != nil {
+		return SectorCompression{}, err
+	}
+	sc = SectorCompression{
+		Compressed:     compressed,
+		OriginalSize:   storage.SectorSize,
+		CompressedSize: storage.SectorSize,
+	}
+	if compressed {
+		sc.CompressedSize = compressedSize
+	}
+	return
+}
+
+// compressSector compresses the sector data. If the compression does not save enough space,
+// compressed is false and the original data should be stored
+func compressSector(data []byte) (compressedData []byte, compressed bool, err error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestSpeed)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, false, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, false, err
+	}
+	if uint64(buf.Len())+minCompressionSaving > uint64(len(data)) {
+		return nil, false, nil
+	}
+	return buf.Bytes(), true, nil
+}
+
+// decompressSector decompresses the compressed sector data, and verifies the sector root
+// against the original bytes
+func decompressSector(compressedData []byte, root common.Hash) (data []byte, err error) {
+	r := flate.NewReader(bytes.NewReader(compressedData))
+	defer r.Close()
+
+	data = make([]byte, storage.SectorSize)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("cannot decompress the sector: %v", err)
+	}
+	if merkle.Sha256MerkleTreeRoot(data) != root {
+		return nil, fmt.Errorf("decompressed sector not match the sector root %v", root.String())
+	}
+	return data, nil
+}
+
+// makeSectorCompressionKey makes the key of the compression record of the sector
+func makeSectorCompressionKey(sectorID sectorID) (key []byte) {
+	key = makeKey(prefixSectorCompression, common.Bytes2Hex(sectorID[:]))
+	return
+}
+
+// getSectorCompression returns the compressed size of the sector. If the sector is not
+// compressed, compressed is false
+func (db *database) getSectorCompression(id sectorID) (compressedSize uint64, compressed bool, err error) {
+	b, err := db.lvl.Get(makeSectorCompressionKey(id), nil)
+	if err == leveldb.ErrNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return
+	}
+	var scp sectorCompressionPersist
+	if err = rlp.DecodeBytes(b, &scp); err != nil {
+		return
+	}
+	return scp.CompressedSize, true, nil
+}
+
+// saveSectorCompressionToBatch appends the save compression record operation to the batch
+func (db *database) saveSectorCompressionToBatch(batch *leveldb.Batch, id sectorID, compressedSize uint64) (newBatch *leveldb.Batch, err error) {
+	b, err := rlp.EncodeToBytes(sectorCompressionPersist{CompressedSize: compressedSize})
+	if err != nil {
+		return nil, err
+	}
+	batch.Put(makeSectorCompressionKey(id), b)
+	return batch, nil
+}
+
+// getSectorCompressionEnabled returns whether the sector compression is enabled
+func (db *database) getSectorCompressionEnabled() (enabled bool, err error) {
+	b, err := db.lvl.Get(makeKey(sectorCompressionKey), nil)
+	if err == leveldb.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return
+	}
+	return len(b) == 1 && b[0] == 1, nil
+}
+
+// saveSectorCompression saves whether the sector compression is enabled
+func (db *database) saveSectorCompression(enabled bool) (err error) {
+	b := []byte{0}
+	if enabled {
+		b[0] = 1
+	}
+	return db.lvl.Put(makeKey(sectorCompressionKey), b, nil)
+}
@@ -0,0 +1,127 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagemanager
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// TestSectorCompression test adding, reading and deleting the sectors with the sector
+// compression enabled
+func TestSectorCompression(t *testing.T) {
+	sm := newTestStorageManager(t, "", newDisruptor())
+	path := randomFolderPath(t, "")
+	if err := sm.AddStorageFolder(path, uint64(1<<25)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.SetSectorCompression(true); err != nil {
+		t.Fatal(err)
+	}
+	// the compressible sector is stored compressed
+	compressible := bytes.Repeat([]byte("dxchain"), int(storage.SectorSize/7+1))[:storage.SectorSize]
+	compressibleRoot := merkle.Sha256MerkleTreeRoot(compressible)
+	if err := sm.AddSector(compressibleRoot, compressible); err != nil {
+		t.Fatal(err)
+	}
+	// the random sector is stored as is
+	random := randomBytes(storage.SectorSize)
+	randomRoot := merkle.Sha256MerkleTreeRoot(random)
+	if err := sm.AddSector(randomRoot, random); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSectorExist(randomRoot, sm, random, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := sm.ReadSector(compressibleRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, compressible) {
+		t.Errorf("the compressed sector read not expected")
+	}
+	if data, err = sm.ReadSectorRange(compressibleRoot, 100, 200); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, compressible[100:300]) {
+		t.Errorf("the range of the compressed sector read not expected")
+	}
+	sc, err := sm.SectorCompression(compressibleRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sc.Compressed || sc.CompressedSize+minCompressionSaving > storage.SectorSize {
+		t.Errorf("the sector shall be compressed: %+v", sc)
+	}
+	if sc, err = sm.SectorCompression(randomRoot); err != nil {
+		t.Fatal(err)
+	}
+	if sc.Compressed {
+		t.Errorf("the random sector shall not be compressed")
+	}
+	stats, err := sm.CompressionStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Enabled || stats.CompressedSectors != 1 || stats.OriginalBytes != storage.SectorSize {
+		t.Errorf("compression stats not expected: %+v", stats)
+	}
+	if stats.SavedBytes+stats.StoredBytes != stats.OriginalBytes || stats.Ratio <= 1 {
+		t.Errorf("compression stats not expected: %+v", stats)
+	}
+
+	// the compression record is removed with the sector
+	if err = sm.DeleteSector(compressibleRoot); err != nil {
+		t.Fatal(err)
+	}
+	if _, compressed, err := sm.db.getSectorCompression(sm.calculateSectorID(compressibleRoot)); err != nil || compressed {
+		t.Errorf("the compression record shall be removed with the sector")
+	}
+	if stats, err = sm.CompressionStats(); err != nil {
+		t.Fatal(err)
+	}
+	if stats.CompressedSectors != 0 {
+		t.Errorf("compressed sectors not expected. Expect 0, Got %v", stats.CompressedSectors)
+	}
+
+	// the setting is persisted
+	sm.shutdown(t, 100*time.Millisecond)
+	newsm, err := New(sm.persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm = newsm.(*storageManager)
+	if err = sm.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if !sm.compression {
+		t.Errorf("the sector compression setting shall be persisted")
+	}
+	sm.shutdown(t, 100*time.Millisecond)
+}
+
+// TestDecompressSector test that the decompressed sector is verified against the sector root
+func TestDecompressSector(t *testing.T) {
+	data := make([]byte, storage.SectorSize)
+	copy(data, "dxchain")
+	compressed, ok, err := compressSector(data)
+	if err != nil || !ok {
+		t.Fatalf("the sector shall be compressed: %v", err)
+	}
+	if _, err = decompressSector(compressed, merkle.Sha256MerkleTreeRoot(data)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = decompressSector(compressed, merkle.Sha256MerkleTreeRoot(randomBytes(storage.SectorSize))); err == nil {
+		t.Errorf("decompressing with the wrong sector root shall return error")
+	}
+	if _, _, err = compressSector(randomBytes(storage.SectorSize)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -93,6 +93,29 @@ func (db *database) getOrCreateSectorSalt() (salt sectorSalt, err error) {
 	return
 }
 
+// getSectorStoreConfig returns the sector store config. If the config is not stored before,
+// the default flat file sector store is returned
+func (db *database) getSectorStoreConfig() (config SectorStoreConfig, err error) {
+	b, err := db.lvl.Get(makeKey(sectorStoreConfigKey), nil)
+	if err == leveldb.ErrNotFound {
+		return SectorStoreConfig{Backend: SectorStoreFile}, nil
+	}
+	if err != nil {
+		return
+	}
+	err = rlp.DecodeBytes(b, &config)
+	return
+}
+
+// saveSectorStoreConfig saves the sector store config to the database
+func (db *database) saveSectorStoreConfig(config SectorStoreConfig) (err error) {
+	b, err := rlp.EncodeToBytes(config)
+	if err != nil {
+		return
+	}
+	return db.lvl.Put(makeKey(sectorStoreConfigKey), b, nil)
+}
+
 // randomFolderID create a random folder id that does not exist in database.
 // After the function execution, the folderID is already stored in database to avoid other
 // randomFolderID calls to use the same id
@@ -338,6 +361,7 @@ func (db *database) saveSectorToBatch(batch *leveldb.Batch, sector *sector, fold
 // deleteSectorToBatch add the delete sector to the batch
 func (db *database) deleteSectorToBatch(batch *leveldb.Batch, id sectorID) (newBatch *leveldb.Batch) {
 	batch.Delete(makeSectorKey(id))
+	batch.Delete(makeSectorCompressionKey(id))
 	return batch
 }
 
@@ -10,7 +10,11 @@ const (
 	prefixFolderSector   = "folderToSector"
 	prefixFolderIDToPath = "folderIDToPath"
 	sectorSaltKey        = "sectorSalt"
+	sectorStoreConfigKey = "sectorStoreConfig"
+	sectorCompressionKey = "sectorCompressionEnabled"
 	prefixSector         = "sector"
+
+	prefixSectorCompression = "sectorCompression"
 )
 
 const (
@@ -36,6 +40,7 @@ const (
 	databaseFileName = "storagemanager.db"
 	walFileName      = "storagemanager.wal"
 	dataFileName     = "dxstorage.dat"
+	dataDBName       = "dxstorage.ldb"
 )
 
 const (
@@ -20,7 +20,8 @@ type folderManager struct {
 }
 
 // loadFolderManager creates a new storage folders from database and open the data files
-func loadFolderManager(db *database) (fm *folderManager, err error) {
+// from the sector store specified by the config
+func loadFolderManager(db *database, config SectorStoreConfig) (fm *folderManager, err error) {
 	// load the folders from database
 	folders, err := db.loadAllStorageFolders()
 	if err != nil {
@@ -28,7 +29,7 @@ func loadFolderManager(db *database) (fm *folderManager, err error) {
 	}
 	for _, sf := range folders {
 		// load the folder data file
-		if err = sf.load(); err != nil {
+		if err = sf.load(config); err != nil {
 			err = fmt.Errorf("load folder %v: %v", sf.path, err)
 			return
 		}
@@ -0,0 +1,128 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagemanager
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// SectorStore returns the config of the sector store backend currently used
+func (sm *storageManager) SectorStore() SectorStoreConfig {
+	sm.lock.RLock()
+	defer sm.lock.RUnlock()
+	return sm.storeConfig
+}
+
+// MigrateSectorStore migrates the sector data of all storage folders to the sector store
+// backend specified by the config. The sectors are copied to the new sector stores first,
+// and the config is persisted only after all sectors are copied. So if the migration is
+// interrupted, the sector data is still served from the previous sector stores, and the
+// migration could be simply retried
+func (sm *storageManager) MigrateSectorStore(config SectorStoreConfig) (err error) {
+	if config.Backend == "" {
+		config.Backend = SectorStoreFile
+	}
+	if err = config.validate(); err != nil {
+		return
+	}
+	// Register in the thread manager
+	if err = sm.tm.Add(); err != nil {
+		return errStopped
+	}
+	defer sm.tm.Done()
+
+	// Block all updates and lock all folders during migration
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+	sm.folders.lock.Lock()
+	defer sm.folders.lock.Unlock()
+	for _, sf := range sm.folders.sfs {
+		sf.lock.Lock()
+		defer sf.lock.Unlock()
+	}
+
+	prevConfig := sm.storeConfig
+	if prevConfig.sameLocation(config) {
+		return errors.New("the sector store is already used")
+	}
+
+	// Copy the sectors of each folder to the new sector store
+	stores := make(map[string]sectorStore)
+	for path, sf := range sm.folders.sfs {
+		var store sectorStore
+		if store, err = migrateFolder(sf, config); err != nil {
+			err = fmt.Errorf("cannot migrate folder %v: %v", path, err)
+			break
+		}
+		stores[path] = store
+	}
+	if err == nil {
+		err = sm.db.saveSectorStoreConfig(config)
+	}
+	if err != nil {
+		// remove the new sector stores. The previous sector stores are still in use
+		for path, store := range stores {
+			sf := sm.folders.sfs[path]
+			err = common.ErrCompose(err, store.Close())
+			err = common.ErrCompose(err, removeSectorStore(config, sf.path, sf.id))
+		}
+		return
+	}
+
+	// Switch to the new sector stores, and remove the previous ones
+	sm.storeConfig = config
+	for path, store := range stores {
+		sf := sm.folders.sfs[path]
+		prevStore := sf.dataFile
+		sf.dataFile = store
+		if newErr := prevStore.Close(); newErr != nil {
+			sm.log.Warn("Cannot close the previous sector store", "folder", path, "err", newErr)
+		}
+		if newErr := removeSectorStore(prevConfig, sf.path, sf.id); newErr != nil {
+			sm.log.Warn("Cannot remove the previous sector store", "folder", path, "err", newErr)
+		}
+	}
+	return nil
+}
+
+// migrateFolder copies the sectors stored in the folder to a new sector store created
+// with the config. The folder should be locked before calling this function
+func migrateFolder(sf *storageFolder, config SectorStoreConfig) (sectorStore, error) {
+	store, err := createSectorStore(config, sf.path, sf.id)
+	if err != nil {
+		return nil, err
+	}
+	if err = copySectors(sf, store); err != nil {
+		store.Close()
+		removeSectorStore(config, sf.path, sf.id)
+		return nil, err
+	}
+	return store, nil
+}
+
+// copySectors copies the sectors stored in the folder to the sector store
+func copySectors(sf *storageFolder, store sectorStore) error {
+	if err := store.Truncate(int64(numSectorsToSize(sf.numSectors))); err != nil {
+		return err
+	}
+	b := make([]byte, storage.SectorSize)
+	for index := uint64(0); index < sf.numSectors; index++ {
+		if sf.usage[index/bitVectorGranularity].isFree(index % bitVectorGranularity) {
+			continue
+		}
+		offset := int64(index * storage.SectorSize)
+		if n, err := sf.dataFile.ReadAt(b, offset); err != nil || uint64(n) != storage.SectorSize {
+			return fmt.Errorf("cannot read sector %v: %v", index, err)
+		}
+		if _, err := store.WriteAt(b, offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -15,6 +15,20 @@ import (
 
 //ReadSector read the sector data
 func (sm *storageManager) ReadSector(root common.Hash) (data []byte, err error) {
+	return sm.readSectorRange(root, 0, storage.SectorSize)
+}
+
+// ReadSectorRange read the data of the length at the offset within the sector. It is used
+// to read part of the sector without loading the whole sector into memory
+func (sm *storageManager) ReadSectorRange(root common.Hash, offset, length uint64) (data []byte, err error) {
+	if offset+length > storage.SectorSize || offset+length < offset {
+		return nil, fmt.Errorf("range [%v, %v) out of sector bound", offset, offset+length)
+	}
+	return sm.readSectorRange(root, offset, length)
+}
+
+// readSectorRange read the data of the length at the offset within the sector
+func (sm *storageManager) readSectorRange(root common.Hash, offset, length uint64) (data []byte, err error) {
 	// calculate the sector id
 	id := sm.calculateSectorID(root)
 	// lock the sector
@@ -47,11 +61,27 @@ func (sm *storageManager) ReadSector(root common.Hash) (data []byte, err error)
 		return nil, fmt.Errorf("folder status unavailable")
 	}
 
+	// The compressed sector is read and decompressed as a whole
+	compressedSize, compressed, err := sm.db.getSectorCompression(id)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the sector compression: %v", err)
+	}
+	if compressed {
+		compressedData := make([]byte, compressedSize)
+		if n, err := folder.dataFile.ReadAt(compressedData, int64(index*storage.SectorSize)); uint64(n) != compressedSize {
+			return nil, fmt.Errorf("cannot read the compressed sector: read %v bytes, expect %v bytes: %v", n, compressedSize, err)
+		}
+		if data, err = decompressSector(compressedData, root); err != nil {
+			return nil, err
+		}
+		return data[offset : offset+length], nil
+	}
+
 	// Read the data from folder
-	data = make([]byte, storage.SectorSize)
-	n, err := folder.dataFile.ReadAt(data, int64(index*storage.SectorSize))
-	if uint64(n) != storage.SectorSize {
-		return nil, fmt.Errorf("cannot read the sector: read %v bytes, expect %v bytes", n, storage.SectorSize)
+	data = make([]byte, length)
+	n, err := folder.dataFile.ReadAt(data, int64(index*storage.SectorSize+offset))
+	if uint64(n) != length {
+		return nil, fmt.Errorf("cannot read the sector: read %v bytes, expect %v bytes", n, length)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("cannot read the sector: %v", err)
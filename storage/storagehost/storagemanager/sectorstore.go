@@ -0,0 +1,182 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagemanager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// The sector store backends persisting the sector data of the storage folders
+const (
+	// SectorStoreFile stores the sector data of a folder in a flat data file
+	SectorStoreFile = "file"
+
+	// SectorStoreDB stores the sector data of a folder in a key-value database
+	SectorStoreDB = "db"
+
+	// SectorStoreObject stores the sector data of a folder in an S3 compatible object store,
+	// for example the local MinIO server
+	SectorStoreObject = "object"
+)
+
+type (
+	// SectorStoreConfig is the configuration of the backend persisting the sector data
+	// of the storage folders
+	SectorStoreConfig struct {
+		Backend string `json:"backend"`
+
+		// object store backend related fields
+		Endpoint  string `json:"endpoint"`
+		Region    string `json:"region"`
+		Bucket    string `json:"bucket"`
+		AccessKey string `json:"accesskey"`
+		SecretKey string `json:"-"`
+	}
+
+	// sectorStore is the persistence of the sector data of a storage folder. The sector
+	// with the index is located at the offset index * SectorSize of the store
+	sectorStore interface {
+		ReadAt(b []byte, off int64) (n int, err error)
+		WriteAt(b []byte, off int64) (n int, err error)
+		Truncate(size int64) error
+		Size() (int64, error)
+		Close() error
+
+		// writeSector writes the sector data with the index. The data could be shorter
+		// than the sector size, and the backend only needs to keep the data written
+		writeSector(index uint64, data []byte) error
+	}
+)
+
+var (
+	// errUnknownSectorStore is the error of the unknown sector store backend
+	errUnknownSectorStore = errors.New("unknown sector store backend")
+
+	// errSectorStoreNotExist is the error that the sector store of the folder does not exist
+	errSectorStoreNotExist = errors.New("data file not exist")
+)
+
+// backend returns the sector store backend of the config. The flat file is used by default
+func (config SectorStoreConfig) backend() string {
+	if config.Backend == "" {
+		return SectorStoreFile
+	}
+	return config.Backend
+}
+
+// validate checks whether the sector store config is valid
+func (config SectorStoreConfig) validate() error {
+	switch config.backend() {
+	case SectorStoreFile, SectorStoreDB:
+		return nil
+	case SectorStoreObject:
+		if config.Endpoint == "" || config.Bucket == "" {
+			return errors.New("the endpoint and bucket of the object store must be specified")
+		}
+		return nil
+	default:
+		return errUnknownSectorStore
+	}
+}
+
+// sameLocation checks whether the sector stores of the two configs are at the same location
+func (config SectorStoreConfig) sameLocation(other SectorStoreConfig) bool {
+	if config.backend() != other.backend() {
+		return false
+	}
+	if config.backend() == SectorStoreObject {
+		return config.Endpoint == other.Endpoint && config.Bucket == other.Bucket
+	}
+	return true
+}
+
+// createSectorStore creates an empty sector store of the folder with the config
+func createSectorStore(config SectorStoreConfig, path string, id folderID) (sectorStore, error) {
+	switch config.backend() {
+	case SectorStoreFile:
+		f, err := os.Create(filepath.Join(path, dataFileName))
+		if err != nil {
+			return nil, err
+		}
+		return &fileSectorStore{f}, nil
+	case SectorStoreDB:
+		return createDBSectorStore(filepath.Join(path, dataDBName))
+	case SectorStoreObject:
+		return createObjectSectorStore(newObjectClient(config), objectPrefix(id))
+	default:
+		return nil, errUnknownSectorStore
+	}
+}
+
+// openSectorStore opens the existing sector store of the folder with the config
+func openSectorStore(config SectorStoreConfig, path string, id folderID) (sectorStore, error) {
+	switch config.backend() {
+	case SectorStoreFile:
+		datafilePath := filepath.Join(path, dataFileName)
+		if _, err := os.Stat(datafilePath); os.IsNotExist(err) {
+			return nil, errSectorStoreNotExist
+		}
+		return newFileSectorStore(datafilePath)
+	case SectorStoreDB:
+		return openDBSectorStore(filepath.Join(path, dataDBName))
+	case SectorStoreObject:
+		return openObjectSectorStore(newObjectClient(config), objectPrefix(id))
+	default:
+		return nil, errUnknownSectorStore
+	}
+}
+
+// removeSectorStore removes the sector store of the folder with the config
+func removeSectorStore(config SectorStoreConfig, path string, id folderID) error {
+	switch config.backend() {
+	case SectorStoreFile:
+		return os.Remove(filepath.Join(path, dataFileName))
+	case SectorStoreDB:
+		return os.RemoveAll(filepath.Join(path, dataDBName))
+	case SectorStoreObject:
+		return removeObjectSectorStore(newObjectClient(config), objectPrefix(id))
+	default:
+		return errUnknownSectorStore
+	}
+}
+
+// fileSectorStore is the sector store of the flat data file
+type fileSectorStore struct {
+	*os.File
+}
+
+// newFileSectorStore opens the flat data file as the sector store
+func newFileSectorStore(path string) (*fileSectorStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSectorStore{f}, nil
+}
+
+// Size returns the size of the data file
+func (fs *fileSectorStore) Size() (int64, error) {
+	info, err := fs.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// writeSector writes the sector data at the location of the sector
+func (fs *fileSectorStore) writeSector(index uint64, data []byte) error {
+	_, err := fs.WriteAt(data, int64(index*storage.SectorSize))
+	return err
+}
+
+// objectPrefix returns the object key prefix of the sector data of the folder
+func objectPrefix(id folderID) string {
+	return fmt.Sprintf("dxstorage-%08x", uint32(id))
+}
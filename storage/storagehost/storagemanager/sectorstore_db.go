@@ -0,0 +1,184 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagemanager
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/DxChainNetwork/godx/storage"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	// keys of the db sector store
+	dbStoreSizeKey      = "size"
+	dbStoreSectorPrefix = "sector"
+)
+
+// dbSectorStore is the sector store keeping each sector as an entry of the level db.
+// The sectors never written are read as zeros, like the sparse data file
+type dbSectorStore struct {
+	lvl  *leveldb.DB
+	size int64
+}
+
+// createDBSectorStore creates an empty db sector store at the path
+func createDBSectorStore(path string) (*dbSectorStore, error) {
+	if err := os.RemoveAll(path); err != nil {
+		return nil, err
+	}
+	lvl, err := leveldb.OpenFile(path, &opt.Options{})
+	if err != nil {
+		return nil, err
+	}
+	ds := &dbSectorStore{lvl: lvl}
+	if err = ds.saveSize(0); err != nil {
+		lvl.Close()
+		return nil, err
+	}
+	return ds, nil
+}
+
+// openDBSectorStore opens the existing db sector store at the path
+func openDBSectorStore(path string) (*dbSectorStore, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, errSectorStoreNotExist
+	}
+	lvl, err := leveldb.OpenFile(path, &opt.Options{ErrorIfMissing: true})
+	if err != nil {
+		return nil, err
+	}
+	b, err := lvl.Get([]byte(dbStoreSizeKey), nil)
+	if err != nil {
+		lvl.Close()
+		return nil, err
+	}
+	return &dbSectorStore{lvl: lvl, size: int64(binary.BigEndian.Uint64(b))}, nil
+}
+
+// makeDBStoreSectorKey makes the key of the sector with the index
+func makeDBStoreSectorKey(index uint64) []byte {
+	key := make([]byte, len(dbStoreSectorPrefix)+8)
+	copy(key, dbStoreSectorPrefix)
+	binary.BigEndian.PutUint64(key[len(dbStoreSectorPrefix):], index)
+	return key
+}
+
+// saveSize saves the size of the store
+func (ds *dbSectorStore) saveSize(size int64) error {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(size))
+	if err := ds.lvl.Put([]byte(dbStoreSizeKey), b, nil); err != nil {
+		return err
+	}
+	ds.size = size
+	return nil
+}
+
+// readSector reads the sector with the index. The sector not written is read as zeros, and
+// the sector written shorter than the sector size is padded with zeros
+func (ds *dbSectorStore) readSector(index uint64) ([]byte, error) {
+	data, err := ds.lvl.Get(makeDBStoreSectorKey(index), nil)
+	if err == leveldb.ErrNotFound {
+		return make([]byte, storage.SectorSize), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)) < storage.SectorSize {
+		data = append(data, make([]byte, storage.SectorSize-uint64(len(data)))...)
+	}
+	return data, nil
+}
+
+// writeSector writes the sector data with the index as is
+func (ds *dbSectorStore) writeSector(index uint64, data []byte) error {
+	if err := ds.lvl.Put(makeDBStoreSectorKey(index), data, nil); err != nil {
+		return err
+	}
+	if end := int64((index + 1) * storage.SectorSize); end > ds.size {
+		return ds.saveSize(end)
+	}
+	return nil
+}
+
+// ReadAt reads the data at the offset of the store
+func (ds *dbSectorStore) ReadAt(b []byte, off int64) (n int, err error) {
+	if off >= ds.size {
+		return 0, io.EOF
+	}
+	if off+int64(len(b)) > ds.size {
+		b, err = b[:ds.size-off], io.EOF
+	}
+	for n < len(b) {
+		pos := uint64(off) + uint64(n)
+		data, readErr := ds.readSector(pos / storage.SectorSize)
+		if readErr != nil {
+			return n, readErr
+		}
+		n += copy(b[n:], data[pos%storage.SectorSize:])
+	}
+	return n, err
+}
+
+// WriteAt writes the data at the offset of the store. The sectors partially written are
+// read and merged with the written data
+func (ds *dbSectorStore) WriteAt(b []byte, off int64) (n int, err error) {
+	batch := new(leveldb.Batch)
+	for n < len(b) {
+		pos := uint64(off) + uint64(n)
+		index, sectorOffset := pos/storage.SectorSize, pos%storage.SectorSize
+		var data []byte
+		if sectorOffset == 0 && uint64(len(b)-n) >= storage.SectorSize {
+			data = b[n : n+int(storage.SectorSize)]
+		} else if data, err = ds.readSector(index); err != nil {
+			return 0, err
+		}
+		written := copy(data[sectorOffset:], b[n:])
+		batch.Put(makeDBStoreSectorKey(index), data)
+		n += written
+	}
+	if err = ds.lvl.Write(batch, nil); err != nil {
+		return 0, err
+	}
+	if end := off + int64(n); end > ds.size {
+		if err = ds.saveSize(end); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// Truncate changes the size of the store. The sectors beyond the size are deleted
+func (ds *dbSectorStore) Truncate(size int64) error {
+	batch := new(leveldb.Batch)
+	firstRemoved := (uint64(size) + storage.SectorSize - 1) / storage.SectorSize
+	iter := ds.lvl.NewIterator(&util.Range{Start: makeDBStoreSectorKey(firstRemoved), Limit: util.BytesPrefix([]byte(dbStoreSectorPrefix)).Limit}, nil)
+	for iter.Next() {
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	if err := ds.lvl.Write(batch, nil); err != nil {
+		return err
+	}
+	return ds.saveSize(size)
+}
+
+// Size returns the size of the store
+func (ds *dbSectorStore) Size() (int64, error) {
+	return ds.size, nil
+}
+
+// Close closes the underlying level db
+func (ds *dbSectorStore) Close() error {
+	return ds.lvl.Close()
+}
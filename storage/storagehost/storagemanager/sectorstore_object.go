@@ -0,0 +1,396 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagemanager
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+const (
+	// objectStoreSizeKey is the object key of the size of the object sector store
+	objectStoreSizeKey = "size"
+
+	// objectStoreTimeout is the timeout of a request to the object store
+	objectStoreTimeout = time.Minute
+
+	// defaultObjectStoreRegion is the region used to sign the requests if not specified
+	defaultObjectStoreRegion = "us-east-1"
+)
+
+var (
+	// errObjectNotFound is the error that the object does not exist in the object store
+	errObjectNotFound = errors.New("object not found")
+
+	// errObjectRangeNotSatisfiable is the error that the range requested is beyond the object
+	errObjectRangeNotSatisfiable = errors.New("object range not satisfiable")
+)
+
+// objectClient is the client of the S3 compatible object store. The requests are signed
+// with the AWS signature version 4 if the access key is specified
+type objectClient struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+// newObjectClient creates the object store client with the sector store config
+func newObjectClient(config SectorStoreConfig) *objectClient {
+	region := config.Region
+	if region == "" {
+		region = defaultObjectStoreRegion
+	}
+	endpoint := strings.TrimSuffix(config.Endpoint, "/")
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		endpoint = "http://" + endpoint
+	}
+	return &objectClient{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    config.Bucket,
+		accessKey: config.AccessKey,
+		secretKey: config.SecretKey,
+		client:    &http.Client{Timeout: objectStoreTimeout},
+	}
+}
+
+// get gets the object with the key. If length is positive, only the range of the object
+// starting from offset is returned
+func (oc *objectClient) get(key string, offset, length int64) ([]byte, error) {
+	header := make(http.Header)
+	if length > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	}
+	resp, err := oc.do(http.MethodGet, key, nil, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+// list lists the keys of all objects with the key prefix
+func (oc *objectClient) list(prefix string) (keys []string, err error) {
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		resp, err := oc.do(http.MethodGet, "", query, make(http.Header), nil)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Contents []struct {
+				Key string
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, content := range result.Contents {
+			keys = append(keys, content.Key)
+		}
+		if !result.IsTruncated {
+			return keys, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// put puts the object with the key
+func (oc *objectClient) put(key string, data []byte) error {
+	resp, err := oc.do(http.MethodPut, key, nil, make(http.Header), data)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// delete deletes the object with the key. Deleting the object not exist is not an error
+func (oc *objectClient) delete(key string) error {
+	resp, err := oc.do(http.MethodDelete, key, nil, make(http.Header), nil)
+	if err == errObjectNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// do sends the signed request of the object with the key, and checks the response status.
+// The request with the empty key is sent to the bucket
+func (oc *objectClient) do(method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	path := "/" + oc.bucket
+	if key != "" {
+		path += "/" + key
+	}
+	rawQuery := strings.Replace(query.Encode(), "+", "%20", -1)
+	req, err := http.NewRequest(method, oc.endpoint+(&url.URL{Path: path, RawQuery: rawQuery}).RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	req.ContentLength = int64(len(body))
+	if oc.accessKey != "" {
+		oc.sign(req, path, rawQuery, body, time.Now().UTC())
+	}
+
+	resp, err := oc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, errObjectNotFound
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		return nil, errObjectRangeNotSatisfiable
+	case resp.StatusCode >= 300:
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("object store %s %s: %s %s", method, key, resp.Status, msg)
+	}
+	return resp, nil
+}
+
+// sign signs the request with the AWS signature version 4
+func (oc *objectClient) sign(req *http.Request, path string, rawQuery string, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		(&url.URL{Path: path}).EscapedPath(),
+		rawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + hex.EncodeToString(payloadHash[:]),
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := strings.Join([]string{date, oc.region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+oc.secretKey), date)
+	signingKey = hmacSHA256(signingKey, oc.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", oc.accessKey, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of the data with the key
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// objectSectorStore is the sector store keeping each sector as an object in the object store.
+// The sectors never written are read as zeros, like the sparse data file
+type objectSectorStore struct {
+	client *objectClient
+	prefix string
+	size   int64
+}
+
+// createObjectSectorStore creates an empty object sector store with the key prefix. The
+// sectors left by the previous store with the same prefix are deleted
+func createObjectSectorStore(client *objectClient, prefix string) (*objectSectorStore, error) {
+	if prev, err := openObjectSectorStore(client, prefix); err == nil {
+		if err = prev.Truncate(0); err != nil {
+			return nil, err
+		}
+	}
+	obs := &objectSectorStore{client: client, prefix: prefix}
+	if err := obs.saveSize(0); err != nil {
+		return nil, err
+	}
+	return obs, nil
+}
+
+// openObjectSectorStore opens the existing object sector store with the key prefix
+func openObjectSectorStore(client *objectClient, prefix string) (*objectSectorStore, error) {
+	b, err := client.get(prefix+"/"+objectStoreSizeKey, 0, 0)
+	if err == errObjectNotFound {
+		return nil, errSectorStoreNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 8 {
+		return nil, errors.New("invalid size of the object sector store")
+	}
+	return &objectSectorStore{client: client, prefix: prefix, size: int64(binary.BigEndian.Uint64(b))}, nil
+}
+
+// removeObjectSectorStore removes all objects of the object sector store with the key prefix
+func removeObjectSectorStore(client *objectClient, prefix string) error {
+	obs, err := openObjectSectorStore(client, prefix)
+	if err == errSectorStoreNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = obs.Truncate(0); err != nil {
+		return err
+	}
+	return client.delete(prefix + "/" + objectStoreSizeKey)
+}
+
+// sectorKey returns the object key of the sector with the index
+func (obs *objectSectorStore) sectorKey(index uint64) string {
+	return fmt.Sprintf("%s/%d", obs.prefix, index)
+}
+
+// saveSize saves the size of the store
+func (obs *objectSectorStore) saveSize(size int64) error {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(size))
+	if err := obs.client.put(obs.prefix+"/"+objectStoreSizeKey, b); err != nil {
+		return err
+	}
+	obs.size = size
+	return nil
+}
+
+// readSector reads the range of the sector with the index. The sector not written is
+// read as zeros, and the range beyond the sector written shorter than the sector size is
+// padded with zeros
+func (obs *objectSectorStore) readSector(index uint64, offset, length uint64) ([]byte, error) {
+	data, err := obs.client.get(obs.sectorKey(index), int64(offset), int64(length))
+	if err == errObjectNotFound || err == errObjectRangeNotSatisfiable {
+		return make([]byte, length), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)) > length {
+		return nil, fmt.Errorf("object store returned %v bytes, expect %v bytes", len(data), length)
+	}
+	return append(data, make([]byte, length-uint64(len(data)))...), nil
+}
+
+// writeSector writes the sector data with the index as is
+func (obs *objectSectorStore) writeSector(index uint64, data []byte) error {
+	if err := obs.client.put(obs.sectorKey(index), data); err != nil {
+		return err
+	}
+	if end := int64((index + 1) * storage.SectorSize); end > obs.size {
+		return obs.saveSize(end)
+	}
+	return nil
+}
+
+// ReadAt reads the data at the offset of the store. Only the ranges requested are read
+// from the objects
+func (obs *objectSectorStore) ReadAt(b []byte, off int64) (n int, err error) {
+	if off >= obs.size {
+		return 0, io.EOF
+	}
+	if off+int64(len(b)) > obs.size {
+		b, err = b[:obs.size-off], io.EOF
+	}
+	for n < len(b) {
+		pos := uint64(off) + uint64(n)
+		sectorOffset := pos % storage.SectorSize
+		length := storage.SectorSize - sectorOffset
+		if remain := uint64(len(b) - n); remain < length {
+			length = remain
+		}
+		data, readErr := obs.readSector(pos/storage.SectorSize, sectorOffset, length)
+		if readErr != nil {
+			return n, readErr
+		}
+		n += copy(b[n:], data)
+	}
+	return n, err
+}
+
+// WriteAt writes the data at the offset of the store. The sectors partially written are
+// read and merged with the written data
+func (obs *objectSectorStore) WriteAt(b []byte, off int64) (n int, err error) {
+	for n < len(b) {
+		pos := uint64(off) + uint64(n)
+		index, sectorOffset := pos/storage.SectorSize, pos%storage.SectorSize
+		var data []byte
+		if sectorOffset == 0 && uint64(len(b)-n) >= storage.SectorSize {
+			data = b[n : n+int(storage.SectorSize)]
+		} else if data, err = obs.readSector(index, 0, storage.SectorSize); err != nil {
+			return n, err
+		}
+		written := copy(data[sectorOffset:], b[n:])
+		if err = obs.client.put(obs.sectorKey(index), data); err != nil {
+			return n, err
+		}
+		n += written
+	}
+	if end := off + int64(n); end > obs.size {
+		if err = obs.saveSize(end); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Truncate changes the size of the store. The sectors beyond the size are deleted
+func (obs *objectSectorStore) Truncate(size int64) error {
+	firstRemoved := (uint64(size) + storage.SectorSize - 1) / storage.SectorSize
+	keys, err := obs.client.list(obs.prefix + "/")
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		index, err := strconv.ParseUint(strings.TrimPrefix(key, obs.prefix+"/"), 10, 64)
+		if err != nil || index < firstRemoved {
+			continue
+		}
+		if err = obs.client.delete(key); err != nil {
+			return err
+		}
+	}
+	return obs.saveSize(size)
+}
+
+// Size returns the size of the store
+func (obs *objectSectorStore) Size() (int64, error) {
+	return obs.size, nil
+}
+
+// Close closes the store. No resource is held by the object sector store
+func (obs *objectSectorStore) Close() error {
+	return nil
+}
@@ -0,0 +1,249 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagemanager
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/crypto/merkle"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// fakeObjectStore is the in-memory S3 compatible object store used for testing
+type fakeObjectStore struct {
+	bucket  string
+	objects map[string][]byte
+	lock    sync.Mutex
+}
+
+// newFakeObjectStore starts the fake object store server with the bucket
+func newFakeObjectStore(bucket string) (*fakeObjectStore, *httptest.Server) {
+	fs := &fakeObjectStore{bucket: bucket, objects: make(map[string][]byte)}
+	return fs, httptest.NewServer(fs)
+}
+
+func (fs *fakeObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if r.URL.Path == "/"+fs.bucket {
+		// list objects
+		prefix := r.URL.Query().Get("prefix")
+		var keys []string
+		for key := range fs.objects {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		type content struct{ Key string }
+		result := struct {
+			XMLName  xml.Name `xml:"ListBucketResult"`
+			Contents []content
+		}{}
+		for _, key := range keys {
+			result.Contents = append(result.Contents, content{key})
+		}
+		xml.NewEncoder(w).Encode(result)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/"+fs.bucket+"/")
+	switch r.Method {
+	case http.MethodGet:
+		data, exist := fs.objects[key]
+		if !exist {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err == nil {
+			data = data[start : end+1]
+		}
+		w.Write(data)
+	case http.MethodPut:
+		data, _ := ioutil.ReadAll(r.Body)
+		fs.objects[key] = data
+	case http.MethodDelete:
+		delete(fs.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// numObjects returns the number of objects in the fake object store
+func (fs *fakeObjectStore) numObjects() int {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	return len(fs.objects)
+}
+
+// TestSectorStores test the read, write and truncate of the db and object sector stores
+func TestSectorStores(t *testing.T) {
+	_, server := newFakeObjectStore("dxstorage")
+	defer server.Close()
+	objectConfig := SectorStoreConfig{Backend: SectorStoreObject, Endpoint: server.URL, Bucket: "dxstorage", AccessKey: "key", SecretKey: "secret"}
+
+	tests := []SectorStoreConfig{
+		{Backend: SectorStoreFile},
+		{Backend: SectorStoreDB},
+		objectConfig,
+	}
+	for _, config := range tests {
+		path := tempDir(t.Name(), config.Backend)
+		store, err := createSectorStore(config, path, folderID(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = store.Truncate(int64(numSectorsToSize(4))); err != nil {
+			t.Fatal(err)
+		}
+		data := randomBytes(storage.SectorSize)
+		if n, err := store.WriteAt(data, int64(2*storage.SectorSize)); err != nil || uint64(n) != storage.SectorSize {
+			t.Fatalf("%v: cannot write the sector: %v", config.Backend, err)
+		}
+		if err = store.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		// reopen the store and read the sector
+		if store, err = openSectorStore(config, path, folderID(1)); err != nil {
+			t.Fatal(err)
+		}
+		if size, err := store.Size(); err != nil || size != int64(numSectorsToSize(4)) {
+			t.Fatalf("%v: size not expected. Expect %v, Got %v", config.Backend, numSectorsToSize(4), size)
+		}
+		b := make([]byte, 100)
+		if _, err = store.ReadAt(b, int64(2*storage.SectorSize+10)); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, data[10:110]) {
+			t.Errorf("%v: the data read not expected", config.Backend)
+		}
+		// the sector not written is read as zeros
+		if _, err = store.ReadAt(b, int64(storage.SectorSize)); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, make([]byte, 100)) {
+			t.Errorf("%v: the sector not written should be read as zeros", config.Backend)
+		}
+
+		// truncate the store, and the sector beyond the size is removed
+		if err = store.Truncate(int64(numSectorsToSize(2))); err != nil {
+			t.Fatal(err)
+		}
+		if err = store.Truncate(int64(numSectorsToSize(4))); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = store.ReadAt(b, int64(2*storage.SectorSize)); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, make([]byte, 100)) {
+			t.Errorf("%v: the sector truncated should be removed", config.Backend)
+		}
+		if err = store.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err = removeSectorStore(config, path, folderID(1)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = openSectorStore(config, path, folderID(1)); err != errSectorStoreNotExist {
+			t.Errorf("%v: the sector store should be removed, got %v", config.Backend, err)
+		}
+	}
+}
+
+// TestMigrateSectorStore test migrating the sectors between the sector store backends
+func TestMigrateSectorStore(t *testing.T) {
+	objects, server := newFakeObjectStore("dxstorage")
+	defer server.Close()
+
+	sm := newTestStorageManager(t, "", newDisruptor())
+	path := randomFolderPath(t, "")
+	if err := sm.AddStorageFolder(path, uint64(1<<25)); err != nil {
+		t.Fatal(err)
+	}
+	sectors := make(map[common.Hash][]byte)
+	for i := 0; i != 3; i++ {
+		data := randomBytes(storage.SectorSize)
+		root := merkle.Sha256MerkleTreeRoot(data)
+		if err := sm.AddSector(root, data); err != nil {
+			t.Fatal(err)
+		}
+		sectors[root] = data
+	}
+	checkSectors := func() {
+		for root, data := range sectors {
+			if err := checkSectorExist(root, sm, data, 1); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// migrate to the db sector store
+	if err := sm.MigrateSectorStore(SectorStoreConfig{Backend: SectorStoreDB}); err != nil {
+		t.Fatal(err)
+	}
+	checkSectors()
+	if _, err := os.Stat(filepath.Join(path, dataFileName)); !os.IsNotExist(err) {
+		t.Errorf("the previous data file should be removed")
+	}
+	if err := sm.MigrateSectorStore(SectorStoreConfig{Backend: SectorStoreDB}); err == nil {
+		t.Errorf("migrating to the sector store in use should return error")
+	}
+
+	// migrate to the object sector store
+	objectConfig := SectorStoreConfig{Backend: SectorStoreObject, Endpoint: server.URL, Bucket: "dxstorage", AccessKey: "key", SecretKey: "secret"}
+	if err := sm.MigrateSectorStore(objectConfig); err != nil {
+		t.Fatal(err)
+	}
+	checkSectors()
+	if objects.numObjects() != len(sectors)+1 {
+		t.Errorf("number of objects not expected. Expect %v, Got %v", len(sectors)+1, objects.numObjects())
+	}
+	if _, err := os.Stat(filepath.Join(path, dataDBName)); !os.IsNotExist(err) {
+		t.Errorf("the previous db sector store should be removed")
+	}
+
+	// the sector store config is persisted
+	sm.shutdown(t, 100*time.Millisecond)
+	newsm, err := New(sm.persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm = newsm.(*storageManager)
+	if err = sm.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if config := sm.SectorStore(); config != objectConfig {
+		t.Errorf("sector store config not expected. Expect %+v, Got %+v", objectConfig, config)
+	}
+	checkSectors()
+
+	// migrate back to the flat data file
+	if err = sm.MigrateSectorStore(SectorStoreConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	checkSectors()
+	if objects.numObjects() != 0 {
+		t.Errorf("the previous object sector store should be removed")
+	}
+	sm.shutdown(t, 100*time.Millisecond)
+}
@@ -398,9 +398,9 @@ func (update *shrinkFolderUpdate) release(manager *storageManager, upErr *update
 		return
 	}
 	// Check whether the file has been truncated
-	info, newErr := update.targetFolder.dataFile.Stat()
+	size, newErr := update.targetFolder.dataFile.Size()
 	err = common.ErrCompose(err, newErr)
-	if newErr == nil && info.Size() != int64(numSectorsToSize(update.prevNumSectors)) {
+	if newErr == nil && size != int64(numSectorsToSize(update.prevNumSectors)) {
 		// the folder has been truncated. Only truncate the file to previous size, and
 		// revert the folder db info. The sectors can reside in new locations
 		update.targetFolder.numSectors = update.prevNumSectors
@@ -10,8 +10,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
 
 	"github.com/DxChainNetwork/godx/common"
 	"github.com/DxChainNetwork/godx/common/math"
@@ -45,8 +43,8 @@ type (
 		// folderLock locked the storage folder to prevent racing
 		lock common.TryLock
 
-		// dataFile is the file where all the data sectors locates
-		dataFile *os.File
+		// dataFile is the sector store where all the data sectors locates
+		dataFile sectorStore
 	}
 
 	// storageFolderPersist defines the persist data to be stored in database
@@ -86,22 +84,22 @@ func (sf *storageFolder) DecodeRLP(st *rlp.Stream) (err error) {
 	return
 }
 
-// load load the storage folder data file.
-func (sf *storageFolder) load() (err error) {
-	datafilePath := filepath.Join(sf.path, dataFileName)
-	fileInfo, err := os.Stat(datafilePath)
-	if os.IsNotExist(err) {
+// load load the storage folder data file from the sector store specified by the config.
+func (sf *storageFolder) load(config SectorStoreConfig) (err error) {
+	if sf.dataFile, err = openSectorStore(config, sf.path, sf.id); err != nil {
 		sf.status = folderUnavailable
-		err = errors.New("data file not exist")
 		return
 	}
-	if fileInfo.Size() < int64(sf.numSectors)*int64(storage.SectorSize) {
+	size, err := sf.dataFile.Size()
+	if err != nil {
 		sf.status = folderUnavailable
-		err = errors.New("file size too small")
+		sf.dataFile.Close()
 		return
 	}
-	if sf.dataFile, err = os.OpenFile(datafilePath, os.O_RDWR, 0600); err != nil {
+	if size < int64(sf.numSectors)*int64(storage.SectorSize) {
 		sf.status = folderUnavailable
+		sf.dataFile.Close()
+		err = errors.New("file size too small")
 		return
 	}
 	return
@@ -6,7 +6,6 @@ package storagemanager
 
 import (
 	"fmt"
-	"os"
 	"os/user"
 	"path/filepath"
 	"strings"
@@ -30,13 +29,20 @@ type (
 		DeleteSector(sectorRoot common.Hash) error
 		DeleteSectorBatch(sectorRoots []common.Hash) error
 		ReadSector(sectorRoot common.Hash) ([]byte, error)
+		ReadSectorRange(sectorRoot common.Hash, offset, length uint64) ([]byte, error)
 		// Functions from user calls
 		AddStorageFolder(path string, size uint64) error
 		DeleteFolder(folderPath string) error
 		ResizeFolder(folderPath string, size uint64) error
+		MigrateSectorStore(config SectorStoreConfig) error
 		// Status check
 		Folders() []storage.HostFolder
 		AvailableSpace() storage.HostSpace
+		SectorStore() SectorStoreConfig
+		// Sector compression
+		SetSectorCompression(enabled bool) error
+		CompressionStats() (SectorCompressionStats, error)
+		SectorCompression(sectorRoot common.Hash) (SectorCompression, error)
 	}
 
 	storageManager struct {
@@ -50,6 +56,13 @@ type (
 		// folders is a in-memory map of the folder
 		folders *folderManager
 
+		// storeConfig is the config of the sector store backend persisting the sector
+		// data of the folders
+		storeConfig SectorStoreConfig
+
+		// compression is the flag whether the physical sectors added are compressed
+		compression bool
+
 		// sectorLocks is the map from sector id to the sectorLock
 		sectorLocks *sectorLocks
 
@@ -99,8 +112,14 @@ func (sm *storageManager) Start() (err error) {
 	if err != nil {
 		return fmt.Errorf("cannot get or create the sector salt: %v", err)
 	}
-	// load folders metadata from the db
-	if sm.folders, err = loadFolderManager(sm.db); err != nil {
+	// load the sector store config, and folders metadata from the db
+	if sm.storeConfig, err = sm.db.getSectorStoreConfig(); err != nil {
+		return fmt.Errorf("cannot load the sector store config: %v", err)
+	}
+	if sm.compression, err = sm.db.getSectorCompressionEnabled(); err != nil {
+		return fmt.Errorf("cannot load the sector compression setting: %v", err)
+	}
+	if sm.folders, err = loadFolderManager(sm.db, sm.storeConfig); err != nil {
 		return fmt.Errorf("cannot load folder manager: %v", err)
 	}
 
@@ -240,7 +259,7 @@ func (sm *storageManager) DeleteFolder(folderPath string) (err error) {
 	if err = sf.dataFile.Close(); err != nil {
 		return err
 	}
-	if err = os.Remove(filepath.Join(sf.path, dataFileName)); err != nil {
+	if err = removeSectorStore(sm.storeConfig, sf.path, sf.id); err != nil {
 		return err
 	}
 	return nil
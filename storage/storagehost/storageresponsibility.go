@@ -295,11 +295,13 @@ func (h *StorageHost) modifyStorageResponsibility(so StorageResponsibility, sect
 		}
 		return errDBso
 	}
+	h.updateProofCache(so, sectorsRemoved, sectorsGained, gainedSectorData)
 	//Delete the deleted sector
 	for k := range sectorsRemoved {
 		//The error of restoring a sector doesn't make any sense to us.
 		h.DeleteSector(sectorsRemoved[k])
 	}
+	h.dropPublicSectors(so.id(), sectorsRemoved)
 
 	// Update the financial information for the storage responsibility - apply the cost
 	h.financialMetrics.PotentialContractCompensation = h.financialMetrics.PotentialContractCompensation.Add(so.ContractCost)
@@ -376,6 +378,7 @@ func (h *StorageHost) rollbackStorageResponsibility(oldSo StorageResponsibility,
 		}
 		return errDB
 	}
+	h.updateProofCache(oldSo, sectorsGained, sectorsRemoved, removedSectorData)
 
 	// revert oldSo financialMetrics
 	h.financialMetrics.PotentialContractCompensation = h.financialMetrics.PotentialContractCompensation.Add(oldSo.ContractCost)
@@ -430,6 +433,7 @@ func (h *StorageHost) removeStorageResponsibility(so StorageResponsibility, sos
 	if err := h.DeleteSectorBatch(so.SectorRoots); err != nil {
 		h.log.Error("delete sector batch", "err", err)
 	}
+	h.dropPublicSectors(so.id(), so.SectorRoots)
 
 	switch sos {
 	case responsibilityUnresolved:
@@ -469,6 +473,13 @@ func (h *StorageHost) removeStorageResponsibility(so StorageResponsibility, sos
 		h.financialMetrics.UploadBandwidthRevenue = h.financialMetrics.UploadBandwidthRevenue.Add(so.PotentialUploadRevenue)
 
 	case responsibilityFailed:
+		// Keep the merkle roots for the dispute evidence before they are cleared
+		snapshot := so
+		snapshot.ResponsibilityStatus = responsibilityFailed
+		if err := putEvidenceSnapshot(h.db, snapshot); err != nil {
+			h.log.Warn("Failed to save the dispute evidence snapshot", "err", err)
+		}
+
 		// Remove the responsibility statistics as potential risk and income.
 		h.log.Info("Missed storage proof.", "Revenue", so.ContractCost.Add(so.PotentialStorageRevenue).Add(so.PotentialDownloadRevenue).Add(so.PotentialUploadRevenue))
 
@@ -485,6 +496,14 @@ func (h *StorageHost) removeStorageResponsibility(so StorageResponsibility, sos
 
 	}
 
+	h.clearProofCache(so)
+	if err := deleteProofSubmission(h.db, so.id()); err != nil {
+		h.log.Warn("Failed to delete the proof submission", "id", so.id(), "err", err)
+	}
+	if err := deleteProofPayout(h.db, so.id()); err != nil {
+		h.log.Warn("Failed to delete the proof payout", "id", so.id(), "err", err)
+	}
+
 	h.financialMetrics.ContractCount--
 	so.ResponsibilityStatus = sos
 	so.SectorRoots = []common.Hash{}
@@ -497,6 +516,14 @@ func (h *StorageHost) resetFinancialMetrics() error {
 
 	fm := HostFinancialMetrics{}
 	sos := h.storageResponsibilities()
+	// the pruned storage responsibilities are counted with the archive records
+	ars, err := archivedResponsibilities(h.db)
+	if err != nil {
+		return err
+	}
+	for _, ar := range ars {
+		sos = append(sos, ar.responsibility())
+	}
 	for _, so := range sos {
 		// Submit transaction fee first
 		fm.TransactionFeeExpenses = fm.TransactionFeeExpenses.Add(so.TransactionFeeExpenses)
@@ -619,6 +646,11 @@ func (h *StorageHost) handleTaskItem(soid common.Hash) {
 			return
 		}
 
+		//Skip if the proof tx submitted is still expected to be mined, or has been resubmitted with a higher fee
+		if h.checkProofSubmission(so) {
+			return
+		}
+
 		//The storage host side gets the index of the data containing the segment
 		scrv := so.StorageContractRevisions[len(so.StorageContractRevisions)-1]
 		segmentIndex, err := h.storageProofSegment(scrv)
@@ -629,16 +661,15 @@ func (h *StorageHost) handleTaskItem(soid common.Hash) {
 
 		sectorIndex := segmentIndex / (storage.SectorSize / merkle.LeafSize)
 		sectorRoot := so.SectorRoots[sectorIndex]
-		sectorBytes, err := h.ReadSector(sectorRoot)
+
+		//Build a storage certificate for this storage contract
+		sectorSegment := segmentIndex % (storage.SectorSize / merkle.LeafSize)
+		base, cachedHashSet, err := h.sectorStorageProof(so.id(), sectorRoot, sectorSegment)
 		//No content can be read from the memory, indicating that the storage host is not storing.
 		if err != nil {
 			h.log.Warn("the storage host is not storing", "err", err)
 			return
 		}
-
-		//Build a storage certificate for this storage contract
-		sectorSegment := segmentIndex % (storage.SectorSize / merkle.LeafSize)
-		base, cachedHashSet := merkleProof(sectorBytes, sectorSegment)
 		// Using the sector, build a cached root.
 		log2SectorSize := uint64(0)
 		for 1<<log2SectorSize < (storage.SectorSize / merkle.LeafSize) {
@@ -674,17 +705,8 @@ func (h *StorageHost) handleTaskItem(soid common.Hash) {
 		}
 		sp.Signature = spSign
 
-		spBytes, err := rlp.EncodeToBytes(sp)
-		if err != nil {
-			h.log.Warn("Error when serializing proof", "err", err)
-			return
-		}
-
-		//The host sends a storage proof transaction to the transaction pool.
-		if _, err := h.sendStorageProofTx(fromAddress, spBytes); err != nil {
-			h.log.Warn("Error sending a storage proof transaction", "err", err)
-			return
-		}
+		//The storage proof is submitted along with the other proofs built for the block
+		h.proofBatch.add(fromAddress, so, sp)
 
 		//Insert the check proof task in the task queue.
 		err = h.queueTaskItem(so.proofDeadline(), so.id())
@@ -701,6 +723,13 @@ func (h *StorageHost) handleTaskItem(soid common.Hash) {
 
 	//If the submission of the storage certificate is successful during the non-expiration period, this deletes the storage responsibility
 	if so.StorageProofConfirmed && h.blockHeight >= so.proofDeadline() {
+		//The revenue is counted only after the proof tx is deep enough to survive a reorg
+		if finalHeight, final := h.proofPayoutFinality(soid); !final {
+			if err := h.queueTaskItem(finalHeight, soid); err != nil {
+				h.log.Warn("Error queuing task item", "err", err)
+			}
+			return
+		}
 		err := h.removeStorageResponsibility(so, responsibilitySucceeded)
 		if err != nil {
 			h.log.Warn("responsibilityFailed to delete storage responsibility", "err", err)
@@ -765,7 +794,12 @@ func calculateLeaves(dataSize uint64) uint64 {
 
 // sendStorageContractRevisionTx send revision contract tx
 func (h *StorageHost) sendStorageContractRevisionTx(from common.Address, input []byte) (common.Hash, error) {
-	return h.parseAPI.StorageTx.SendContractRevisionTX(from, input)
+	txHash, err := h.parseAPI.StorageTx.SendContractRevisionTX(from, input)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	h.txMonitor.Track(storage.StorageTxContractRevision, from, txHash, h.blockHeight)
+	return txHash, nil
 }
 
 // SendStorageProofTx send storage proof tx
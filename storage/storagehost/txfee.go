@@ -0,0 +1,36 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storagehost
+
+import (
+	"math/big"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/storage"
+)
+
+// maxTxGasPrice returns the gas price ceiling of the tx resubmitted with a higher fee. The config
+// persisted before the field was introduced falls back to the default value
+func (h *StorageHost) maxTxGasPrice() *big.Int {
+	if h.config.MaxTxGasPrice.Sign() <= 0 {
+		return storage.DefaultMaxTxGasPrice.BigIntPtr()
+	}
+	return h.config.MaxTxGasPrice.BigIntPtr()
+}
+
+// bumpPendingTxs replaces the revision txs not mined within the confirm blocks with a higher fee.
+// The storage proof tx is monitored along with the proof window in the task item instead
+func (h *StorageHost) bumpPendingTxs() {
+	h.lock.RLock()
+	height, maxGasPrice := h.blockHeight, h.maxTxGasPrice()
+	h.lock.RUnlock()
+
+	errs := h.txMonitor.BumpOverdue(height, func(tx storage.PendingStorageTx) (common.Hash, error) {
+		return h.parseAPI.StorageTx.BumpStorageContractTX(tx.From, tx.TxHash, storage.StorageTxPriceBump, maxGasPrice)
+	})
+	for _, err := range errs {
+		h.log.Warn("Revision tx not mined within the confirm blocks", "err", err)
+	}
+}
@@ -112,6 +112,14 @@ var (
 	// per file contract.
 	errMaxCollateralReached = errors.New("file contract proposal expects the host to pay more than the maximum allowed collateral")
 
+	// errClientNotAccepted is returned if the host is in private hosting mode and
+	// the storage client is not in the accept-list of the host
+	errClientNotAccepted = errors.New("host only accepts contracts from the clients in its accept-list")
+
+	// errClientNotInAcceptList is returned when removing a client address that
+	// is not in the accept-list of the host
+	errClientNotInAcceptList = errors.New("client is not in the accept-list")
+
 	errEmptyOriginStorageContract = errors.New("storage contract has no storage responsibility")
 	errEmptyRevisionSet           = errors.New("take the last revision ")
 	errInsaneRevision             = errors.New("revision is not necessary")
@@ -31,6 +31,12 @@ func UploadHandler(h *StorageHost, sp storage.Peer, uploadReqMsg p2p.Msg) {
 		}
 	}()
 
+	// the uploads are paused during the maintenance
+	if h.inMaintenance() {
+		hostNegotiateErr = errHostInMaintenance
+		return
+	}
+
 	// Read upload request
 	var uploadRequest storage.UploadRequest
 	if err := uploadReqMsg.Decode(&uploadRequest); err != nil {
@@ -52,6 +58,12 @@ func UploadHandler(h *StorageHost, sp storage.Peer, uploadReqMsg p2p.Msg) {
 
 	settings := h.externalConfig()
 	currentBlockHeight := h.blockHeight
+
+	// more than one sector could be uploaded in a request only if the batch upload is active
+	if len(uploadRequest.Actions) > 1 && !storage.HasFeature(settings.Features, storage.FeatureBatchUpload) {
+		hostNegotiateErr = errBatchUploadNotEnabled
+		return
+	}
 	currentRevision := so.StorageContractRevisions[len(so.StorageContractRevisions)-1]
 
 	// Process each action
@@ -316,7 +328,7 @@ func VerifyRevision(so *StorageResponsibility, revision *types.StorageContractRe
 	if revision.NewValidProofOutputs[0].Value.Cmp(oldFCR.NewValidProofOutputs[0].Value) > 0 {
 		return fmt.Errorf("client increased its valid proof output: %v", errHighClientValidOutput)
 	}
-	fromClient := common.NewBigInt(oldFCR.NewValidProofOutputs[0].Value.Int64()).Sub(common.NewBigInt(revision.NewValidProofOutputs[0].Value.Int64()))
+	fromClient := common.PtrBigInt(oldFCR.NewValidProofOutputs[0].Value).Sub(common.PtrBigInt(revision.NewValidProofOutputs[0].Value))
 	// Verify that enough money was transferred.
 	if fromClient.Cmp(expectedExchange) < 0 {
 		s := fmt.Sprintf("expected at least %v to be exchanged, but %v was exchanged: ", expectedExchange, fromClient)
@@ -327,7 +339,7 @@ func VerifyRevision(so *StorageResponsibility, revision *types.StorageContractRe
 	if oldFCR.NewValidProofOutputs[1].Value.Cmp(revision.NewValidProofOutputs[1].Value) > 0 {
 		return ExtendErr("host valid proof output was decreased: ", errLowHostValidOutput)
 	}
-	toHost := common.NewBigInt(revision.NewValidProofOutputs[1].Value.Int64()).Sub(common.NewBigInt(oldFCR.NewValidProofOutputs[1].Value.Int64()))
+	toHost := common.PtrBigInt(revision.NewValidProofOutputs[1].Value).Sub(common.PtrBigInt(oldFCR.NewValidProofOutputs[1].Value))
 
 	// Verify that enough money was transferred.
 	if toHost.Cmp(fromClient) != 0 {
@@ -346,7 +358,7 @@ func VerifyRevision(so *StorageResponsibility, revision *types.StorageContractRe
 	// expected. If the new misesd output is greater than the old one, the host
 	// is actually posting negative collateral, which is fine.
 	//if revision.NewMissedProofOutputs[1].Value.Cmp(oldFCR.NewMissedProofOutputs[1].Value) <= 0 {
-	//	collateral := common.NewBigInt(oldFCR.NewMissedProofOutputs[1].Value.Int64()).Sub(common.NewBigInt(revision.NewMissedProofOutputs[1].Value.Int64()))
+	//	collateral := common.PtrBigInt(oldFCR.NewMissedProofOutputs[1].Value).Sub(common.PtrBigInt(revision.NewMissedProofOutputs[1].Value))
 	//	if collateral.Cmp(expectedCollateral) > 0 {
 	//		s := fmt.Sprintf("host expected to post at most %v collateral, but contract has host posting %v: ", expectedCollateral, collateral)
 	//		return ExtendErr(s, errLowHostMissedOutput)
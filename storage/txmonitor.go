@@ -0,0 +1,114 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/internal/ethapi"
+)
+
+// storage contract tx types monitored by the StorageTxMonitor
+const (
+	StorageTxContractCreate   = "contractCreate"
+	StorageTxContractRevision = "contractRevision"
+	StorageTxStorageProof     = "storageProof"
+)
+
+// PendingStorageTx is the storage contract tx sent to the tx pool and not mined yet
+type PendingStorageTx struct {
+	Type         string         `json:"type"`
+	From         common.Address `json:"from"`
+	TxHash       common.Hash    `json:"txHash"`
+	OriginTxHash common.Hash    `json:"originTxHash"`
+	SubmitHeight uint64         `json:"submitHeight"`
+	Bumps        uint64         `json:"bumps"`
+}
+
+// StorageTxBumpFunc replaces the pending storage contract tx with a higher fee, and returns the
+// hash of the replacement tx
+type StorageTxBumpFunc func(tx PendingStorageTx) (common.Hash, error)
+
+// StorageTxMonitor keeps track of the storage contract txs sent to the tx pool. The tx not mined
+// within the confirm blocks is replaced with one paying a higher fee, until it is mined, dropped
+// from the tx pool, or the fee reaches the ceiling
+type StorageTxMonitor struct {
+	confirmBlocks uint64
+	txs           map[common.Hash]*PendingStorageTx
+	lock          sync.Mutex
+}
+
+// NewStorageTxMonitor creates the StorageTxMonitor expecting the storage contract txs to be
+// mined within the confirm blocks
+func NewStorageTxMonitor(confirmBlocks uint64) *StorageTxMonitor {
+	return &StorageTxMonitor{
+		confirmBlocks: confirmBlocks,
+		txs:           make(map[common.Hash]*PendingStorageTx),
+	}
+}
+
+// Track starts monitoring the storage contract tx sent at the block height
+func (m *StorageTxMonitor) Track(txType string, from common.Address, txHash common.Hash, height uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.txs[txHash] = &PendingStorageTx{
+		Type:         txType,
+		From:         from,
+		TxHash:       txHash,
+		OriginTxHash: txHash,
+		SubmitHeight: height,
+	}
+}
+
+// PendingTxs returns the storage contract txs being monitored
+func (m *StorageTxMonitor) PendingTxs() []PendingStorageTx {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	txs := make([]PendingStorageTx, 0, len(m.txs))
+	for _, tx := range m.txs {
+		txs = append(txs, *tx)
+	}
+	return txs
+}
+
+// BumpOverdue replaces the storage contract txs not mined within the confirm blocks using the
+// bump function. The tx no longer pending in the tx pool is no longer monitored, and the tx whose
+// fee reaches the ceiling is checked again after another confirm blocks. The errors other than
+// the tx not pending are returned
+func (m *StorageTxMonitor) BumpOverdue(height uint64, bump StorageTxBumpFunc) (errs []error) {
+	m.lock.Lock()
+	var overdue []PendingStorageTx
+	for _, tx := range m.txs {
+		if height >= tx.SubmitHeight+m.confirmBlocks {
+			overdue = append(overdue, *tx)
+		}
+	}
+	m.lock.Unlock()
+
+	// bump the txs without holding the lock, the bump function may take a while
+	for _, tx := range overdue {
+		txHash, err := bump(tx)
+
+		m.lock.Lock()
+		switch {
+		case err == nil:
+			delete(m.txs, tx.TxHash)
+			tx.TxHash, tx.SubmitHeight = txHash, height
+			tx.Bumps++
+			m.txs[txHash] = &tx
+		case err == ethapi.ErrStorageTxNotPending:
+			delete(m.txs, tx.TxHash)
+		default:
+			if pending, exist := m.txs[tx.TxHash]; exist {
+				pending.SubmitHeight = height
+			}
+			errs = append(errs, fmt.Errorf("failed to bump the %v tx %v: %v", tx.Type, tx.TxHash.Hex(), err))
+		}
+		m.lock.Unlock()
+	}
+	return
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 DxChain, All rights reserved.
+// Use of this source code is governed by an Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DxChainNetwork/godx/common"
+	"github.com/DxChainNetwork/godx/internal/ethapi"
+)
+
+func TestStorageTxMonitor_BumpOverdue(t *testing.T) {
+	m := NewStorageTxMonitor(10)
+	from := common.HexToAddress("0x1")
+	bumped, dropped, capped := common.HexToHash("0x1"), common.HexToHash("0x2"), common.HexToHash("0x3")
+	m.Track(StorageTxContractCreate, from, bumped, 100)
+	m.Track(StorageTxContractRevision, from, dropped, 100)
+	m.Track(StorageTxStorageProof, from, capped, 100)
+	m.Track(StorageTxContractCreate, from, common.HexToHash("0x4"), 105)
+
+	replacement := common.HexToHash("0x5")
+	var calls int
+	errs := m.BumpOverdue(110, func(tx PendingStorageTx) (common.Hash, error) {
+		calls++
+		switch tx.TxHash {
+		case bumped:
+			return replacement, nil
+		case dropped:
+			return common.Hash{}, ethapi.ErrStorageTxNotPending
+		case capped:
+			return common.Hash{}, ethapi.ErrStorageTxFeeCeiling
+		}
+		return common.Hash{}, errors.New("the tx within the confirm blocks shall not be bumped")
+	})
+	if calls != 3 {
+		t.Fatalf("number of txs bumped not expected. Expect 3, Got %v", calls)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("only the fee ceiling error shall be returned. Got %v", errs)
+	}
+
+	pending := make(map[common.Hash]PendingStorageTx)
+	for _, tx := range m.PendingTxs() {
+		pending[tx.TxHash] = tx
+	}
+	if len(pending) != 3 {
+		t.Fatalf("number of pending txs not expected. Expect 3, Got %v", len(pending))
+	}
+	if _, exist := pending[dropped]; exist {
+		t.Errorf("the tx no longer pending shall not be monitored")
+	}
+	if tx, exist := pending[replacement]; !exist || tx.OriginTxHash != bumped || tx.Bumps != 1 || tx.SubmitHeight != 110 {
+		t.Errorf("the replacement tx not expected: %+v", tx)
+	}
+	if tx := pending[capped]; tx.SubmitHeight != 110 || tx.Bumps != 0 {
+		t.Errorf("the tx reaching the fee ceiling shall be checked after another confirm blocks: %+v", tx)
+	}
+}
@@ -47,8 +47,16 @@ const (
 	// DxFileExt is the extension of DxFile
 	DxFileExt = ".dxfile"
 
+	// MirrorExt is the extension of the mirror of the DxFile or DxDir, which is persisted
+	// next to it with the same content
+	MirrorExt = ".mirror"
+
+	// ChecksumExt is the extension of the file recording the checksum of the DxFile, the DxDir
+	// or their mirrors, which tells the valid copy once they differ
+	ChecksumExt = ".sum"
+
 	// ConfigVersion is the version of host config
-	ConfigVersion = "1.0.1"
+	ConfigVersion = "1.0.2"
 )
 
 type (
@@ -71,6 +79,31 @@ type (
 		SectorAccessPrice      common.BigInt `json:"sectorAccessPrice"`
 		StoragePrice           common.BigInt `json:"storagePrice"`
 		UploadBandwidthPrice   common.BigInt `json:"uploadBandwidthPrice"`
+
+		DynamicPricing  bool          `json:"dynamicPricing"`
+		MinStoragePrice common.BigInt `json:"minStoragePrice"`
+		MaxStoragePrice common.BigInt `json:"maxStoragePrice"`
+
+		PrivateHosting  bool             `json:"privateHosting"`
+		AcceptedClients []common.Address `json:"acceptedClients"`
+
+		// ProofConfirmBlocks is the number of blocks the storage proof tx is expected to be
+		// mined within. Otherwise the host alerts and resubmits the proof with a higher fee
+		ProofConfirmBlocks uint64 `json:"proofConfirmBlocks"`
+
+		// MaxTxGasPrice is the gas price ceiling of the revision and storage proof tx resubmitted
+		// with a higher fee
+		MaxTxGasPrice common.BigInt `json:"maxTxGasPrice"`
+
+		// ResponsibilityRetention is the number of blocks the settled storage responsibility is
+		// kept after the proof deadline. After that it is replaced by a compact archive record,
+		// or deleted if DiscardSettledResponsibilities is set
+		ResponsibilityRetention        uint64 `json:"responsibilityRetention"`
+		DiscardSettledResponsibilities bool   `json:"discardSettledResponsibilities"`
+
+		// FeatureFlags is the protocol features enabled and the block heights they are active
+		// from. DefaultFeatureFlags is used if not set
+		FeatureFlags FeatureFlags `json:"featureFlags"`
 	}
 
 	// HostIntConfigForDisplay is the host internal config for displayed
@@ -92,6 +125,21 @@ type (
 		SectorAccessPrice      string `json:"sectorAccessPrice"`
 		StoragePrice           string `json:"storagePrice"`
 		UploadBandwidthPrice   string `json:"uploadBandwidthPrice"`
+
+		DynamicPricing  string `json:"dynamicPricing"`
+		MinStoragePrice string `json:"minStoragePrice"`
+		MaxStoragePrice string `json:"maxStoragePrice"`
+
+		PrivateHosting  string   `json:"privateHosting"`
+		AcceptedClients []string `json:"acceptedClients"`
+
+		ProofConfirmBlocks string `json:"proofConfirmBlocks"`
+		MaxTxGasPrice      string `json:"maxTxGasPrice"`
+
+		ResponsibilityRetention        string `json:"responsibilityRetention"`
+		DiscardSettledResponsibilities string `json:"discardSettledResponsibilities"`
+
+		FeatureFlags string `json:"featureFlags"`
 	}
 
 	// HostExtConfig make group of host setting to broadcast as object
@@ -118,6 +166,14 @@ type (
 		UploadBandwidthPrice   common.BigInt `json:"uploadBandwidthPrice"`
 
 		Version string `json:"version"`
+
+		// Features is the protocol features active on the storage host
+		Features []Feature `json:"features"`
+
+		// Timestamp is the unix time when the config is signed by the host, and
+		// Signature is the host's node key signature of the config
+		Timestamp uint64 `json:"timestamp"`
+		Signature []byte `json:"signature"`
 	}
 
 	// HostInfo storage storage host information
@@ -187,6 +243,10 @@ type RentPayment struct {
 	ExpectedDownload uint64 `json:"expecteddownload"`
 	// ExpectedRedundancy is the average redundancy of files uploaded
 	ExpectedRedundancy float64 `json:"expectedredundancy"`
+
+	// DownloadBudget is the part of the fund the downloads could spend within a period, so that the
+	// rest of the fund is kept for the storage and the renewal. Zero means unlimited
+	DownloadBudget common.BigInt `json:"downloadbudget"`
 }
 
 // ClientSetting defines the settings that client used to create contract with other peers,
@@ -197,6 +257,10 @@ type ClientSetting struct {
 	EnableIPViolation bool        `json:"enableipviolation"`
 	MaxUploadSpeed    int64       `json:"maxuploadspeed"`
 	MaxDownloadSpeed  int64       `json:"maxdownloadspeed"`
+
+	// FeatureFlags is the protocol features enabled and the block heights they are active
+	// from. DefaultFeatureFlags is used if not set
+	FeatureFlags FeatureFlags `json:"featureflags"`
 }
 
 type (
@@ -215,6 +279,8 @@ type (
 		ExpectedDownload string `json:"Expected Download"`
 		// ExpectedRedundancy is the average redundancy of files uploaded
 		ExpectedRedundancy string `json:"Expected Redundancy"`
+		// DownloadBudget is the part of the fund the downloads could spend within a period
+		DownloadBudget string `json:"Download Budget"`
 	}
 
 	// ClientSettingAPIDisplay is used for API Configurations Display
@@ -223,6 +289,7 @@ type (
 		EnableIPViolation string                `json:"IP Violation Check Status"`
 		MaxUploadSpeed    string                `json:"Max Upload Speed"`
 		MaxDownloadSpeed  string                `json:"Max Download Speed"`
+		FeatureFlags      string                `json:"Feature Flags"`
 	}
 )
 
@@ -305,6 +372,10 @@ type (
 		DxPath      DxPath
 		ErasureCode erasurecode.ErasureCoder
 		Mode        int
+
+		// Public uploads the file unencrypted, and publishes the sectors to the hosts
+		// serving the public reads
+		Public bool
 	}
 
 	// UploadFileInfo provides information about a file
@@ -368,13 +439,17 @@ type (
 
 	// FileInfo is the structure containing file info to be displayed
 	FileInfo struct {
-		DxPath         string  `json:"dxpath"`
-		Status         string  `json:"status"`
-		SourcePath     string  `json:"sourcepath"`
-		FileSize       uint64  `json:"filesize"`
-		Redundancy     uint32  `json:"redundancy"`
-		StoredOnDisk   bool    `json:"storedondisk"`
-		UploadProgress float64 `json:"uploadprogress"`
+		DxPath         string   `json:"dxpath"`
+		Status         string   `json:"status"`
+		SourcePath     string   `json:"sourcepath"`
+		FileSize       uint64   `json:"filesize"`
+		Redundancy     uint32   `json:"redundancy"`
+		StoredOnDisk   bool     `json:"storedondisk"`
+		UploadProgress float64  `json:"uploadprogress"`
+		PinnedHosts    []string `json:"pinnedhosts,omitempty"`
+		PinViolations  uint32   `json:"pinviolations,omitempty"`
+		UploadPriority string   `json:"uploadpriority"`
+		Public         bool     `json:"public,omitempty"`
 	}
 
 	// FileBriefInfo is the brief info about a DxFile